@@ -6,30 +6,59 @@ import (
 	"net/http"
 
 	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	"github.com/RHEnVision/provisioning-backend/internal/version"
 )
 
 //go:embed openapi.gen.json
 var embeddedJSONSpec []byte
 
-var etag *middleware.ETag
+//go:embed openapi.v2.gen.json
+var embeddedJSONSpecV2 []byte
+
+// specs are embedded documents by API version
+var specs = map[string][]byte{
+	version.APIPathVersion:   embeddedJSONSpec,
+	version.APIPathVersionV2: embeddedJSONSpecV2,
+}
+
+var etags = make(map[string]*middleware.ETag, len(specs))
 
 func init() {
-	var err error
-	etag, err = middleware.GenerateETagFromBuffer("json-spec", middleware.OpenAPIExpiration, embeddedJSONSpec)
-	if err != nil {
-		panic(err)
+	for apiVersion, spec := range specs {
+		name := "json-spec"
+		if apiVersion != version.APIPathVersion {
+			name += "-" + apiVersion
+		}
+		etag, err := middleware.GenerateETagFromBuffer(name, middleware.OpenAPIExpiration, spec)
+		if err != nil {
+			panic(err)
+		}
+		etags[apiVersion] = etag
 	}
 }
 
-// ETagValue returns etag generated from the input file content with 30 minute expiration.
+// ETagValue returns etag generated from the v1 document content with 30 minute expiration.
 func ETagValue() *middleware.ETag {
-	return etag
+	return etags[version.APIPathVersion]
 }
 
+// ETagValueFor returns etag function of the document of the API version.
+func ETagValueFor(apiVersion string) middleware.ETagValueFunc {
+	return func() *middleware.ETag {
+		return etags[apiVersion]
+	}
+}
+
+// ServeOpenAPISpec writes the document of the API version of the request.
 func ServeOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	spec, ok := specs[version.APIVersion(r.Context())]
+	if !ok {
+		spec = embeddedJSONSpec
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, err := w.Write(embeddedJSONSpec)
+	_, err := w.Write(spec)
 	if err != nil {
 		w.WriteHeader(508)
 		_, _ = w.Write([]byte(fmt.Sprintf(`{"msg": "%s"}`, err.Error())))
@@ -13,17 +13,22 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
 	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	m "github.com/RHEnVision/provisioning-backend/internal/middleware"
 	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
+	"github.com/RHEnVision/provisioning-backend/internal/ratelimit"
 	"github.com/RHEnVision/provisioning-backend/internal/routes"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	s "github.com/RHEnVision/provisioning-backend/internal/services"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/RHEnVision/provisioning-backend/internal/version"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 	"go.uber.org/automaxprocs/maxprocs"
@@ -38,12 +43,17 @@ func api() {
 	defer closeFunc()
 	logging.DumpConfigForDevelopment()
 
+	// resolve secrets from vault or secrets manager
+	if err := secrets.Initialize(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Error initializing secrets")
+	}
+
 	// initialize feature flags
-	err := config.InitializeFeatureFlags(ctx)
+	err := featureflags.Initialize(ctx)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing feature flags")
 	}
-	defer config.StopFeatureFlags(ctx)
+	defer featureflags.Stop(ctx)
 
 	// set GOMAXPROCs for Kubernetes
 	_, _ = maxprocs.Set(maxprocs.Logger(func(format string, args ...interface{}) {
@@ -56,7 +66,7 @@ func api() {
 	metrics.RegisterApiMetrics()
 
 	// initialize the rest
-	err = db.Initialize(ctx, "public")
+	err = db.Initialize(ctx, "public", config.Database.ReplicaURL)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing database")
 	}
@@ -64,12 +74,13 @@ func api() {
 
 	// initialize cache
 	cache.Initialize()
+	ratelimit.Initialize()
 
 	// initialize platform kafka and notifications
 	if config.Kafka.Enabled {
-		err = kafka.InitializeKafkaBroker(ctx)
+		err = kafka.InitializeBroker(ctx)
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Unable to initialize the platform kafka")
+			logger.Fatal().Err(err).Msg("Unable to initialize the message broker")
 		}
 
 		if config.Application.Notifications.Enabled {
@@ -92,21 +103,34 @@ func api() {
 		jq.StartDequeueLoop(ctx)
 	}
 
-	// Setup routes
+	// Setup routes, payloads are rendered in the shape of the API version
+	render.Respond = payloads.Respond
 	rootRouter := chi.NewRouter()
-	apiRouter := chi.NewRouter()
+	routes.MountRoot(rootRouter)
 
-	apiRouter.Use(m.NewPatternMiddleware(version.PrometheusLabelName))
-	apiRouter.Use(telemetry.Middleware(apiRouter))
-	apiRouter.Use(m.VersionMiddleware)
-	apiRouter.Use(m.CorrelationID)
-	apiRouter.Use(m.TraceID)
-	apiRouter.Use(m.LoggerMiddleware(&log.Logger))
+	// metrics are registered once, the path label contains the version prefix
+	patternMiddleware := m.NewPatternMiddleware(version.PrometheusLabelName)
+	if config.Logging.Bodies {
+		log.Warn().Msg("Logging of request and response bodies is enabled, do not use in production")
+	}
+	for _, apiVersion := range version.APIVersions {
+		apiRouter := chi.NewRouter()
+
+		apiRouter.Use(patternMiddleware)
+		apiRouter.Use(telemetry.Middleware(apiRouter))
+		apiRouter.Use(m.VersionMiddleware)
+		apiRouter.Use(m.CorrelationID)
+		apiRouter.Use(m.TraceID)
+		apiRouter.Use(m.Language)
+		apiRouter.Use(m.LoggerMiddleware(&log.Logger))
+		if config.Logging.Bodies {
+			apiRouter.Use(m.BodyLogger)
+		}
 
-	// Mount paths
-	routes.MountRoot(rootRouter)
-	routes.MountAPI(apiRouter)
-	rootRouter.Mount(routes.PathPrefix(), apiRouter)
+		// Mount paths
+		routes.MountAPI(apiRouter, apiVersion)
+		rootRouter.Mount(routes.PathPrefixFor(apiVersion), apiRouter)
+	}
 
 	// Routes for metrics
 	metricsRouter := chi.NewRouter()
@@ -13,6 +13,8 @@ import (
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/ec2"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/gcp"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/image_builder"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/keyhost"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/rbac"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
 
 	"github.com/RHEnVision/provisioning-backend/internal/random"
@@ -39,6 +41,8 @@ func main() {
 		statuser()
 	case "stats":
 		stats()
+	case "replay":
+		replay()
 	case "version":
 		ver()
 	default:
@@ -47,7 +51,7 @@ func main() {
 }
 
 func usage() {
-	fmt.Println("Usage: pbackend [migrate|api|worker|statuser|stats|version]")
+	fmt.Println("Usage: pbackend [migrate|api|worker|statuser|stats|replay|version]")
 	os.Exit(1)
 }
 
@@ -2,31 +2,64 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/migrations"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"github.com/rs/zerolog/log"
 )
 
 func migrate() {
 	ctx := context.Background()
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: pbackend migrate [-target VERSION] [-dry-run] [status|purgedb]")
+		flags.PrintDefaults()
+	}
+	target := flags.Int("target", int(migrations.LatestVersion), "migrate up or down to the version, -1 for the latest")
+	dryRun := flags.Bool("dry-run", false, "print SQL of pending migrations without executing them")
+	_ = flags.Parse(os.Args[2:])
+	command := flags.Arg(0)
+	if command != "" && command != "status" && command != "purgedb" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
 	config.Initialize("config/api.env", "config/migrate.env")
 
 	// initialize stdout logging and AWS clients first (cloudwatch is not available in init containers)
 	logging.InitializeStdout()
 	logging.DumpConfigForDevelopment()
+
+	// resolve secrets from vault or secrets manager
+	if err := secrets.Initialize(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Error initializing secrets")
+	}
+
 	logger := log.Logger
 
-	err := db.Initialize(ctx, "public")
+	err := db.Initialize(ctx, "public", "")
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing database")
 	}
 	defer db.Close()
 
-	if len(os.Args[2:]) > 0 && os.Args[2] == "purgedb" {
+	if command == "status" {
+		printMigrationStatus(ctx)
+		return
+	}
+
+	if *dryRun {
+		printPendingMigrations(ctx, int32(*target))
+		return
+	}
+
+	if command == "purgedb" {
 		logger.Warn().Msg("Database purge: all data is being dropped")
 		err = migrations.Seed(ctx, "drop_all")
 		if err != nil {
@@ -36,13 +69,14 @@ func migrate() {
 		logger.Info().Msgf("Database %s has been purged to blank state", config.Database.Name)
 	}
 
-	err = migrations.Migrate(ctx, "public")
+	err = migrations.MigrateTo(ctx, "public", int32(*target))
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Error running migration")
 		return
 	}
 
-	if config.Database.SeedScript != "" {
+	// seed scripts expect the latest schema
+	if config.Database.SeedScript != "" && *target == int(migrations.LatestVersion) {
 		err = migrations.Seed(ctx, config.Database.SeedScript)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("Error running migration")
@@ -50,3 +84,40 @@ func migrate() {
 		}
 	}
 }
+
+func printMigrationStatus(ctx context.Context) {
+	current, list, err := migrations.Status(ctx, "public")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error reading migration status")
+		return
+	}
+
+	fmt.Printf("Current version: %d\n", current)
+	for _, m := range list {
+		state := "pending"
+		if m.Applied {
+			state = "applied"
+		}
+		reversible := ""
+		if !m.Reversible {
+			reversible = " (irreversible)"
+		}
+		fmt.Printf("%03d %-8s %s%s\n", m.Sequence, state, m.Name, reversible)
+	}
+}
+
+func printPendingMigrations(ctx context.Context, target int32) {
+	pending, err := migrations.Pending(ctx, "public", target)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error reading pending migrations")
+		return
+	}
+
+	if len(pending) == 0 {
+		fmt.Println("-- No pending migrations")
+		return
+	}
+	for _, m := range pending {
+		fmt.Printf("-- Migration %s %s\n%s\n", m.Name, m.Direction, m.SQL)
+	}
+}
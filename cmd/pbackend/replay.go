@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// replayTopic is a topic which can be replayed through its consumer handler
+type replayTopic struct {
+	topic   *string
+	handler func(ctx context.Context, message *kafka.GenericMessage)
+	start   func(ctx context.Context)
+	stop    func()
+}
+
+// replayTopics are consumed topics by name. Availability status requests are the only
+// topic consumed by the application.
+var replayTopics = map[string]replayTopic{
+	"availability-check": {
+		topic:   &kafka.AvailabilityStatusRequestTopic,
+		handler: processMessage,
+		start: func(ctx context.Context) {
+			startProcessing(ctx, ctx)
+		},
+		stop: stopProcessing,
+	},
+}
+
+func replayTopicNames() string {
+	names := make([]string, 0, len(replayTopics))
+	for name := range replayTopics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// replay reads messages of a topic again and processes them through the same handler
+// as the consumer, e.g. after messages were consumed but processing failed.
+func replay() {
+	ctx := context.Background()
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	topicName := flags.String("topic", "availability-check", "topic to replay ("+replayTopicNames()+")")
+	since := flags.String("since", "", "replay messages since the time (RFC3339)")
+	offset := flags.Int64("offset", -1, "replay messages since the offset, overrides -since")
+	partition := flags.Int("partition", 0, "partition of the topic")
+	dryRun := flags.Bool("dry-run", false, "only log messages which would be replayed")
+	_ = flags.Parse(os.Args[2:])
+
+	config.Initialize("config/api.env", "config/statuser.env")
+	logging.InitializeStdout()
+	logging.DumpConfigForDevelopment()
+
+	// resolve secrets from vault or secrets manager
+	if err := secrets.Initialize(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Error initializing secrets")
+	}
+
+	logger := log.Logger
+
+	rt, ok := replayTopics[*topicName]
+	if !ok {
+		logger.Fatal().Msgf("Unknown topic %s, available topics: %s", *topicName, replayTopicNames())
+	}
+	opts := kafka.ReplayOptions{Partition: *partition, Offset: *offset}
+	if *offset < 0 {
+		if *since == "" {
+			logger.Fatal().Msg("One of -since or -offset must be provided")
+		}
+		var err error
+		opts.Since, err = time.Parse(time.RFC3339, *since)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Unable to parse -since")
+		}
+	}
+
+	if !config.Kafka.Enabled {
+		logger.Fatal().Msg("Kafka is not enabled")
+	}
+	err := kafka.InitializeBroker(ctx)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Unable to initialize the message broker")
+	}
+	opts.Topic = *rt.topic
+
+	err = db.Initialize(ctx, "public", "")
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error initializing database")
+	}
+	defer db.Close()
+
+	cancelCtx, cancel := signal.NotifyContext(logger.WithContext(ctx), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	handler := rt.handler
+	if *dryRun {
+		handler = func(ctx context.Context, message *kafka.GenericMessage) {
+			zerolog.Ctx(ctx).Info().Bytes("payload", message.Value).Msg("Message would be replayed")
+		}
+	} else {
+		// processing is not cancelled by signals so replayed messages are not lost
+		rt.start(logger.WithContext(ctx))
+	}
+
+	count, err := kafka.Replay(cancelCtx, opts, handler)
+	if !*dryRun {
+		rt.stop()
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("Replay failed after %d messages", count)
+	}
+	logger.Info().Msgf("Replayed %d messages of topic %s", count, opts.Topic)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -30,15 +31,22 @@ func stats() {
 	defer closeFunc()
 	logging.DumpConfigForDevelopment()
 
+	// resolve secrets from vault or secrets manager
+	if err := secrets.Initialize(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Error initializing secrets")
+	}
+
 	// initialize telemetry
 	tel := telemetry.Initialize(&log.Logger)
 	defer tel.Close(ctx)
 
-	// initialize the job queue but don't register any workers
+	// initialize the job queue, jobs are registered for enqueueing (argument types and priorities)
+	// but no dequeue loop is started
 	err := jq.Initialize(ctx, &logger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing job queue")
 	}
+	jq.RegisterJobs(&logger)
 
 	// metrics
 	logger.Info().Msgf("Starting new instance on port %d with prometheus on %d", config.Application.Port, config.Prometheus.Port)
@@ -75,7 +83,7 @@ func stats() {
 
 	// initialize the database
 	logger.Debug().Msg("Initializing database connection")
-	err = db.Initialize(ctx, "public")
+	err = db.Initialize(ctx, "public", config.Database.ReplicaURL)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing database")
 	}
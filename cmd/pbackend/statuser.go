@@ -11,7 +11,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -24,13 +26,14 @@ import (
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/ec2"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/gcp"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/image_builder"
-	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -86,6 +89,12 @@ func processMessage(origCtx context.Context, message *kafka.GenericMessage) {
 		return
 	}
 
+	// Sources requests a check when a source changes, cached authentication may be outdated
+	err = sources.InvalidateAuthentication(ctx, sourceId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not invalidate cached authentication")
+	}
+
 	// Fetch authentication from Sources
 	authentication, err := sourcesClient.GetAuthentication(ctx, sourceId)
 	if err != nil {
@@ -130,10 +139,24 @@ func checkSourceAvailabilityAzure(ctx context.Context) {
 				Identity:     s.Identity,
 				ResourceType: "Application",
 			}
-			// TODO: check if source is avavliable - WIP
-			sr.Status = kafka.StatusAvaliable
-			chSend <- sr
-			metrics.IncTotalSentAvailabilityCheckReqs(models.ProviderTypeAzure.String(), sr.Status.String(), nil)
+			azureClient, err := clients.GetAzureClient(ctx, &s.Authentication)
+			if err == nil {
+				err = azureClient.Status(ctx)
+			}
+			if errors.Is(err, httpClients.AzureSecretExpiredErr) || errors.Is(err, httpClients.AzureCredentialsInvalidErr) {
+				// credentials of the service are broken, the source itself is not at fault
+				logger.Error().Err(err).Msg("Azure application credentials cannot be used, not reporting source status")
+				return fmt.Errorf("error during check: %w", err)
+			} else if err != nil {
+				sr.Status = kafka.StatusUnavailable
+				sr.Err = err
+				logger.Warn().Err(err).Msg("Could not read azure subscription")
+				chSend <- sr
+			} else {
+				sr.Status = kafka.StatusAvaliable
+				chSend <- sr
+			}
+			metrics.IncTotalSentAvailabilityCheckReqs(models.ProviderTypeAzure.String(), sr.Status.String(), err)
 
 			return fmt.Errorf("error during check: %w", err)
 		})
@@ -215,7 +238,19 @@ func sendResults(ctx context.Context, batchSize int, tickDuration time.Duration)
 	for {
 		select {
 
-		case sr := <-chSend:
+		case sr, ok := <-chSend:
+			if !ok {
+				ticker.Stop()
+				length := len(messages)
+				if length > 0 {
+					logger.Trace().Int("messages", length).Msgf("Sending %d source availability status messages (closed)", length)
+					err := kafka.Send(ctx, messages...)
+					if err != nil {
+						logger.Warn().Err(err).Msg("Could not send source availability status messages (closed)")
+					}
+				}
+				return
+			}
 			ctx = identity.WithIdentity(ctx, sr.Identity)
 			msg, err := sr.GenericMessage(ctx)
 			if err != nil {
@@ -260,6 +295,31 @@ func sendResults(ctx context.Context, batchSize int, tickDuration time.Duration)
 	}
 }
 
+// startProcessing starts availability check goroutines and the sender of results.
+func startProcessing(checkCtx, sendCtx context.Context) {
+	processingWG.Add(3)
+
+	go checkSourceAvailabilityAWS(checkCtx)
+	go checkSourceAvailabilityGCP(checkCtx)
+	go checkSourceAvailabilityAzure(checkCtx)
+
+	senderWG.Add(1)
+	go sendResults(sendCtx, 1024, 5*time.Second)
+}
+
+// stopProcessing finishes all pending availability checks and sends their results.
+func stopProcessing() {
+	// close all processors and wait until it exits the range loop
+	close(chAws)
+	close(chAzure)
+	close(chGcp)
+	processingWG.Wait()
+
+	// close the sending channel and wait until it exits the range loop
+	close(chSend)
+	senderWG.Wait()
+}
+
 func statuser() {
 	ctx := context.Background()
 	config.Initialize("config/api.env", "config/statuser.env")
@@ -269,15 +329,23 @@ func statuser() {
 	defer closeFunc()
 	logging.DumpConfigForDevelopment()
 
+	// resolve secrets from vault or secrets manager
+	if err := secrets.Initialize(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Error initializing secrets")
+	}
+
 	// initialize telemetry
 	tel := telemetry.Initialize(&log.Logger)
 	defer tel.Close(ctx)
 
+	// initialize cache (authentications are invalidated on availability checks)
+	cache.Initialize()
+
 	// initialize platform kafka and notifications
 	if config.Kafka.Enabled {
-		err := kafka.InitializeKafkaBroker(ctx)
+		err := kafka.InitializeBroker(ctx)
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Unable to initialize the platform kafka")
+			logger.Fatal().Err(err).Msg("Unable to initialize the message broker")
 		}
 
 		if config.Application.Notifications.Enabled {
@@ -330,21 +398,14 @@ func statuser() {
 
 	// initialize the database
 	logger.Debug().Msg("Initializing database connection")
-	err := db.Initialize(ctx, "public")
+	err := db.Initialize(ctx, "public", "")
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing database")
 	}
 	defer db.Close()
 
 	// start processing goroutines
-	processingWG.Add(3)
-
-	go checkSourceAvailabilityAWS(cancelCtx)
-	go checkSourceAvailabilityGCP(cancelCtx)
-	go checkSourceAvailabilityAzure(cancelCtx)
-
-	senderWG.Add(1)
-	go sendResults(cancelCtx, 1024, 5*time.Second)
+	startProcessing(cancelCtx, cancelCtx)
 
 	logger.Info().Msg("Statuser process started")
 	select {
@@ -358,15 +419,7 @@ func statuser() {
 	consumerCancelFunc()
 	receiverWG.Wait()
 
-	// close all processors and wait until it exits the range loop
-	close(chAws)
-	close(chAzure)
-	close(chGcp)
-	processingWG.Wait()
-
-	// close the sending channel and wait until it exits the range loop
-	close(chSend)
-	senderWG.Wait()
+	stopProcessing()
 
 	logger.Info().Msg("Consumer shutdown initiated")
 	consumerCancelFunc()
@@ -13,11 +13,13 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
 	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -36,19 +38,34 @@ func worker() {
 	defer closeFunc()
 	logging.DumpConfigForDevelopment()
 
+	// resolve secrets from vault or secrets manager
+	if err := secrets.Initialize(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Error initializing secrets")
+	}
+
 	logger.Info().Msg("Worker starting")
 
+	// initialize feature flags
+	err := featureflags.Initialize(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error initializing feature flags")
+	}
+	defer featureflags.Stop(ctx)
+
 	// initialize telemetry
 	tel := telemetry.Initialize(&log.Logger)
 	defer tel.Close(ctx)
 	metrics.RegisterWorkerMetrics()
+	if config.Scheduler.Enabled {
+		metrics.RegisterSchedulerMetrics()
+	}
 
 	// initialize cache
 	cache.Initialize()
 
 	// initialize the database
 	logger.Debug().Msg("Initializing database connection")
-	err := db.Initialize(ctx, "public")
+	err = db.Initialize(ctx, "public", "")
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing database")
 	}
@@ -56,9 +73,9 @@ func worker() {
 
 	// initialize platform kafka and notifications
 	if config.Kafka.Enabled {
-		err = kafka.InitializeKafkaBroker(ctx)
+		err = kafka.InitializeBroker(ctx)
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Unable to initialize the platform kafka")
+			logger.Fatal().Err(err).Msg("Unable to initialize the message broker")
 		}
 
 		if config.Application.Notifications.Enabled {
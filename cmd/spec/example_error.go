@@ -21,6 +21,9 @@ var ResponseBadRequestErrorExample = payloads.ResponseError{
 	Error:     "error: bad request: details can be long",
 	Version:   "df8a489",
 	BuildTime: "2023-04-14_17:15:02",
+	Fields: []payloads.FieldViolation{
+		{Field: "pubkey_id", Constraint: payloads.ConstraintRequired, Message: "pubkey_id is required"},
+	},
 }
 
 var ResponseErrorUserFriendlyExample = payloads.ResponseError{
@@ -30,3 +33,9 @@ var ResponseErrorUserFriendlyExample = payloads.ResponseError{
 	Version:   "df8a489",
 	BuildTime: "2023-04-14_17:15:02",
 }
+
+// problemExample returns the error example in the shape of the API version
+func problemExample(example payloads.ResponseError, status int, apiVersion string) any {
+	example.HTTPStatusCode = status
+	return example.ForVersion(apiVersion)
+}
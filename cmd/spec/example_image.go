@@ -0,0 +1,23 @@
+package main
+
+import (
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+)
+
+var ImageListResponse = []payloads.ImageResponse{{
+	ID:           "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+	Name:         "My RHEL 9 image",
+	Distribution: "rhel-92",
+	Architecture: "x86_64",
+	Provider:     "aws",
+	CreatedAt:    time.Date(2023, 9, 14, 12, 30, 0, 0, time.UTC),
+}, {
+	ID:           "871fa36d-0b5b-4001-8c95-a11f751a4d66",
+	Name:         "My RHEL 9 ARM image",
+	Distribution: "rhel-92",
+	Architecture: "arm64",
+	Provider:     "aws",
+	CreatedAt:    time.Date(2023, 9, 15, 8, 0, 0, 0, time.UTC),
+}}
@@ -5,3 +5,16 @@ import "github.com/RHEnVision/provisioning-backend/internal/payloads"
 var AvailabilityStatusRequest = payloads.AvailabilityStatusRequest{
 	SourceID: "463243",
 }
+
+// listEnvelope is an example of a list response, see payloads.ListResponse
+type listEnvelope[T any] struct {
+	Data []T               `json:"data" yaml:"data"`
+	Meta payloads.ListMeta `json:"meta" yaml:"meta"`
+}
+
+func listExample[T any](data []T) listEnvelope[T] {
+	return listEnvelope[T]{
+		Data: data,
+		Meta: payloads.ListMeta{Count: len(data), Total: int64(len(data))},
+	}
+}
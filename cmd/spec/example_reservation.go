@@ -198,3 +198,49 @@ var GCPReservationResponsePayloadDoneExample = payloads.GCPReservationResponsePa
 var NoopReservationResponsePayloadExample = payloads.NoopReservationResponsePayload{
 	ID: 1310,
 }
+
+var ReservationStatsResponseExample = payloads.ReservationStatsResponse{
+	Days: 28,
+	Providers: []*payloads.ReservationProviderStatResponse{{
+		Provider:    "aws",
+		Total:       12,
+		Success:     9,
+		Failure:     1,
+		Cancelled:   1,
+		Pending:     1,
+		Instances:   15,
+		SuccessRate: 0.9,
+	}, {
+		Provider:    "gcp",
+		Total:       2,
+		Success:     2,
+		Instances:   2,
+		SuccessRate: 1,
+	}},
+	Daily: []*payloads.ReservationDailyStatResponse{{
+		Day:      time.Date(2023, 9, 14, 0, 0, 0, 0, time.UTC),
+		Provider: "aws",
+		Result:   "success",
+		Count:    9,
+	}, {
+		Day:      time.Date(2023, 9, 14, 0, 0, 0, 0, time.UTC),
+		Provider: "aws",
+		Result:   "failure",
+		Count:    1,
+	}, {
+		Day:      time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC),
+		Provider: "aws",
+		Result:   "cancelled",
+		Count:    1,
+	}, {
+		Day:      time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC),
+		Provider: "aws",
+		Result:   "pending",
+		Count:    1,
+	}, {
+		Day:      time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC),
+		Provider: "gcp",
+		Result:   "success",
+		Count:    2,
+	}},
+}
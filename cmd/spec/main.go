@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"reflect"
 
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/version"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3gen"
 	"gopkg.in/yaml.v3"
@@ -15,12 +17,29 @@ import (
 
 // addPayloads - MAKE SURE THE TYPE HAS JSON/YAML Go STRUCT TAGS (or "map key XXX not found" error occurs)
 func addPayloads(gen *APISchemaGen) {
+	gen.addSchema("v1.ListMeta", &payloads.ListMeta{})
 	gen.addSchema("v1.PubkeyRequest", &payloads.PubkeyRequest{})
 	gen.addSchema("v1.PubkeyResponse", &payloads.PubkeyResponse{})
+	gen.addSchema("v1.PubkeyDeleteResponse", &payloads.PubkeyDeleteResponse{})
+	gen.addSchema("v1.PubkeyImportRequest", &payloads.PubkeyImportRequest{})
+	gen.addSchema("v1.PubkeyImportResponse", &payloads.PubkeyImportResponse{})
+	gen.addSchema("v1.PubkeyUsageResponse", &payloads.PubkeyUsageResponse{})
 	gen.addSchema("v1.SourceResponse", &payloads.SourceResponse{})
+	gen.addSchema("v1.ImageResponse", &payloads.ImageResponse{})
 	gen.addSchema("v1.InstanceTypeResponse", &payloads.InstanceTypeResponse{})
+	gen.addSchema("v1.AccountSettingsRequest", &payloads.AccountSettingsRequest{})
+	gen.addSchema("v1.AccountSettingsResponse", &payloads.AccountSettingsResponse{})
+	gen.addSchema("v1.WebhookRequest", &payloads.WebhookRequest{})
+	gen.addSchema("v1.WebhookResponse", &payloads.WebhookResponse{})
+	gen.addSchema("v1.WebhookDeliveryResponse", &payloads.WebhookDeliveryResponse{})
+	gen.addSchema("v1.ReservationTemplateRequest", &payloads.ReservationTemplateRequest{})
+	gen.addSchema("v1.ReservationTemplateResponse", &payloads.ReservationTemplateResponse{})
+	gen.addSchema("v1.TemplateReservationRequest", &payloads.TemplateReservationRequestPayload{})
 	gen.addSchema("v1.GenericReservationResponsePayload", &payloads.GenericReservationResponsePayload{})
 	gen.addSchema("v1.NoopReservationResponse", &payloads.NoopReservationResponsePayload{})
+	gen.addSchema("v1.ReservationStatsResponse", &payloads.ReservationStatsResponse{})
+	gen.addSchema("v1.InstanceStateResponse", &payloads.InstanceStateResponse{})
+	gen.addSchema("v1.SSHConnectionResponse", &payloads.SSHConnectionResponse{})
 	gen.addSchema("v1.AWSReservationRequest", &payloads.AWSReservationRequestPayload{})
 	gen.addSchema("v1.AWSReservationResponse", &payloads.AWSReservationResponsePayload{})
 	gen.addSchema("v1.AzureReservationRequest", &payloads.AzureReservationRequestPayload{})
@@ -31,21 +50,28 @@ func addPayloads(gen *APISchemaGen) {
 	gen.addSchema("v1.AccountIDTypeResponse", &payloads.AccountIdentityResponse{})
 	gen.addSchema("v1.SourceUploadInfoResponse", &payloads.SourceUploadInfoResponse{})
 	gen.addSchema("v1.LaunchTemplatesResponse", &payloads.LaunchTemplateResponse{})
+	gen.addSchema("v1.PermissionReportResponse", &payloads.PermissionReportResponse{})
+	gen.addSchema("v1.DryRunResponse", &payloads.DryRunResponse{})
+	gen.addSchema("v1.InstanceConsoleResponse", &payloads.InstanceConsoleResponse{})
+	gen.addSchema("v1.InstanceActionRequest", &payloads.InstanceActionRequest{})
+	gen.addSchema("v1.InstanceActionResponse", &payloads.InstanceActionResponse{})
+	gen.addSchema("v1.SharingRequest", &payloads.SharingRequest{})
 }
 
 func addExamples(gen *APISchemaGen) {
 	gen.addExample("v1.PubkeyRequestExample", PubkeyRequest)
 	gen.addExample("v1.PubkeyResponseExample", PubkeyResponse)
-	gen.addExample("v1.PubkeyListResponseExample", PubkeyListResponse)
-	gen.addExample("v1.SourceListResponseExample", SourceListResponse)
+	gen.addExample("v1.PubkeyListResponseExample", listExample(PubkeyListResponse))
+	gen.addExample("v1.SourceListResponseExample", listExample(SourceListResponse))
+	gen.addExample("v1.ImageListResponseExample", listExample(ImageListResponse))
 	gen.addExample("v1.SourceUploadInfoAWSResponse", SourceUploadInfoAWSResponse)
 	gen.addExample("v1.SourceUploadInfoAzureResponse", SourceUploadInfoAzureResponse)
-	gen.addExample("v1.LaunchTemplateListResponse", LaunchTemplateListResponse)
+	gen.addExample("v1.LaunchTemplateListResponse", listExample(LaunchTemplateListResponse))
 	gen.addExample("v1.AvailabilityStatusRequest", AvailabilityStatusRequest)
 	gen.addExample("v1.GenericReservationResponsePayloadSuccessExample", GenericReservationResponsePayloadSuccessExample)
 	gen.addExample("v1.GenericReservationResponsePayloadPendingExample", GenericReservationResponsePayloadPendingExample)
 	gen.addExample("v1.GenericReservationResponsePayloadFailureExample", GenericReservationResponsePayloadFailureExample)
-	gen.addExample("v1.GenericReservationResponsePayloadListExample", GenericReservationResponsePayloadListExample)
+	gen.addExample("v1.GenericReservationResponsePayloadListExample", listExample(GenericReservationResponsePayloadListExample))
 	gen.addExample("v1.AwsReservationRequestPayloadExample", AwsReservationRequestPayloadExample)
 	gen.addExample("v1.AwsReservationResponsePayloadPendingExample", AwsReservationResponsePayloadPendingExample)
 	gen.addExample("v1.AwsReservationResponsePayloadDoneExample", AwsReservationResponsePayloadDoneExample)
@@ -56,20 +82,35 @@ func addExamples(gen *APISchemaGen) {
 	gen.addExample("v1.GCPReservationResponsePayloadPendingExample", GCPReservationResponsePayloadPendingExample)
 	gen.addExample("v1.GCPReservationResponsePayloadDoneExample", GCPReservationResponsePayloadDoneExample)
 	gen.addExample("v1.NoopReservationResponsePayloadExample", NoopReservationResponsePayloadExample)
-	gen.addExample("v1.InstanceTypesAWSResponse", InstanceTypesAWSResponse)
-	gen.addExample("v1.InstanceTypesAzureResponse", InstanceTypesAzureResponse)
-	gen.addExample("v1.InstanceTypesGCPResponse", InstanceTypesGCPResponse)
+	gen.addExample("v1.ReservationStatsResponseExample", ReservationStatsResponseExample)
+	gen.addExample("v1.InstanceTypesAWSResponse", listExample(InstanceTypesAWSResponse))
+	gen.addExample("v1.InstanceTypesAzureResponse", listExample(InstanceTypesAzureResponse))
+	gen.addExample("v1.InstanceTypesGCPResponse", listExample(InstanceTypesGCPResponse))
 }
 
-// addErrorSchemas all generic errors, that can be returned.
-func addErrorSchemas(gen *APISchemaGen) {
+// addErrorSchemas all generic errors, that can be returned. Errors are ProblemDetails since v2.
+func addErrorSchemas(gen *APISchemaGen, apiVersion string) {
+	if apiVersion == version.APIPathVersion {
+		// error payloads
+		gen.addSchema("v1.ResponseError", &payloads.ResponseError{})
+
+		// errors
+		gen.addResponse("NotFound", "The requested resource was not found", "application/json", "#/components/schemas/v1.ResponseError", ResponseNotFoundErrorExample)
+		gen.addResponse("InternalError", "The server encountered an internal error", "application/json", "#/components/schemas/v1.ResponseError", ResponseErrorGenericExample)
+		gen.addResponse("BadRequest", "The request's parameters are not valid", "application/json", "#/components/schemas/v1.ResponseError", ResponseBadRequestErrorExample)
+		return
+	}
+
 	// error payloads
-	gen.addSchema("v1.ResponseError", &payloads.ResponseError{})
+	gen.addSchema("v2.ProblemDetails", &payloads.ProblemDetails{})
 
 	// errors
-	gen.addResponse("NotFound", "The requested resource was not found", "#/components/schemas/v1.ResponseError", ResponseNotFoundErrorExample)
-	gen.addResponse("InternalError", "The server encountered an internal error", "#/components/schemas/v1.ResponseError", ResponseErrorGenericExample)
-	gen.addResponse("BadRequest", "The request's parameters are not valid", "#/components/schemas/v1.ResponseError", ResponseBadRequestErrorExample)
+	gen.addResponse("NotFound", "The requested resource was not found", payloads.ProblemContentType, "#/components/schemas/v2.ProblemDetails",
+		problemExample(ResponseNotFoundErrorExample, http.StatusNotFound, apiVersion))
+	gen.addResponse("InternalError", "The server encountered an internal error", payloads.ProblemContentType, "#/components/schemas/v2.ProblemDetails",
+		problemExample(ResponseErrorGenericExample, http.StatusInternalServerError, apiVersion))
+	gen.addResponse("BadRequest", "The request's parameters are not valid", payloads.ProblemContentType, "#/components/schemas/v2.ProblemDetails",
+		problemExample(ResponseBadRequestErrorExample, http.StatusBadRequest, apiVersion))
 }
 
 type APISchemaGen struct {
@@ -77,12 +118,18 @@ type APISchemaGen struct {
 	Servers    openapi3.Servers    `json:"servers,omitempty" yaml:"servers,omitempty"`
 }
 
-func NewSchemaGenerator() *APISchemaGen {
+func NewSchemaGenerator(apiVersion string) *APISchemaGen {
+	// v1 paths are relative to the application prefix for backward compatibility
+	url := "http://0.0.0.0:{port}/api/{applicationName}"
+	if apiVersion != version.APIPathVersion {
+		url += "/" + apiVersion
+	}
+
 	s := &APISchemaGen{}
 	s.Servers = openapi3.Servers{
 		&openapi3.Server{
 			Description: "Local development",
-			URL:         "http://0.0.0.0:{port}/api/{applicationName}",
+			URL:         url,
 			Variables: map[string]*openapi3.ServerVariable{
 				"applicationName": {Default: "provisioning"},
 				"port":            {Default: "8000"},
@@ -97,12 +144,16 @@ func NewSchemaGenerator() *APISchemaGen {
 	return s
 }
 
-// Schema customizer allowing tagging with nullable to work
+// Schema customizer allowing tagging with nullable to work. It also drops additionalProperties
+// of maps which cannot be marshalled into YAML, maps are generated as free-form objects.
 var enableNullableOpt = openapi3gen.SchemaCustomizer(
-	func(_name string, _t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	func(_name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
 		if tag.Get("nullable") == "true" {
 			schema.Nullable = true
 		}
+		if t.Kind() == reflect.Map {
+			schema.AdditionalProperties = openapi3.AdditionalProperties{}
+		}
 		return nil
 	},
 )
@@ -115,10 +166,11 @@ func (s *APISchemaGen) addSchema(name string, model interface{}) {
 	s.Components.Schemas[name] = schema
 }
 
-func (s *APISchemaGen) addResponse(name string, description string, ref string, example interface{}) {
-	response := openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(&openapi3.SchemaRef{Ref: ref})
-	response.Content.Get("application/json").Examples = make(map[string]*openapi3.ExampleRef)
-	response.Content.Get("application/json").Examples["error"] = &openapi3.ExampleRef{Value: openapi3.NewExample(example)}
+func (s *APISchemaGen) addResponse(name string, description string, contentType string, ref string, example interface{}) {
+	content := openapi3.NewContentWithSchemaRef(&openapi3.SchemaRef{Ref: ref}, []string{contentType})
+	response := openapi3.NewResponse().WithDescription(description).WithContent(content)
+	response.Content.Get(contentType).Examples = make(map[string]*openapi3.ExampleRef)
+	response.Content.Get(contentType).Examples["error"] = &openapi3.ExampleRef{Value: openapi3.NewExample(example)}
 	s.Components.Responses[name] = &openapi3.ResponseRef{Value: response}
 }
 
@@ -153,9 +205,17 @@ func checkTags(rval reflect.Type) {
 }
 
 func main() {
+	for _, apiVersion := range version.APIVersions {
+		generate(apiVersion)
+	}
+}
+
+// generate writes the document of the API version, documents of all versions share paths
+// and payloads and differ in shapes of versioned payloads (e.g. errors).
+func generate(apiVersion string) {
 	// build schema part
-	gen := NewSchemaGenerator()
-	addErrorSchemas(gen)
+	gen := NewSchemaGenerator(apiVersion)
+	addErrorSchemas(gen, apiVersion)
 	addPayloads(gen)
 	addExamples(gen)
 
@@ -201,12 +261,17 @@ func main() {
 		panic(err)
 	}
 
-	err = os.WriteFile("./api/openapi.gen.json", bufferJSON, 0o644) // #nosec G306
+	name := "./api/openapi"
+	if apiVersion != version.APIPathVersion {
+		name += "." + apiVersion
+	}
+
+	err = os.WriteFile(name+".gen.json", bufferJSON, 0o644) // #nosec G306
 	if err != nil {
 		panic(err)
 	}
 
-	err = os.WriteFile("./api/openapi.gen.yaml", bufferYAML, 0o644) // #nosec G306
+	err = os.WriteFile(name+".gen.yaml", bufferYAML, 0o644) // #nosec G306
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,58 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/rs/zerolog"
+)
+
+// archiveBatchSize is the maximum number of reservations archived in a single transaction.
+const archiveBatchSize = 1000
+
+// archiveReservationsLoop periodically moves reservations finished before the archive age into
+// the archive table. It must only run in a single process (stats).
+func archiveReservationsLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started reservation archival routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Reservation archival routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	for {
+		select {
+		case <-ticker.C:
+			archiveReservationsTick(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// archiveReservationsTick archives reservations in batches until there is nothing left to archive.
+func archiveReservationsTick(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	before := time.Now().Add(-config.Application.ArchiveAge)
+
+	var total int64
+	for ctx.Err() == nil {
+		count, err := dao.GetReservationDao(ctx).UnscopedArchive(ctx, before, archiveBatchSize)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to archive reservations")
+			break
+		}
+		total += count
+		if count < archiveBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info().Msgf("Archived %d reservations finished before %s", total, before.Format(time.RFC3339))
+	}
+}
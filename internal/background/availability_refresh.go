@@ -0,0 +1,129 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/preload"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+var refreshedProviders = []models.ProviderType{
+	models.ProviderTypeAWS,
+	models.ProviderTypeAzure,
+	models.ProviderTypeGCP,
+}
+
+// availabilityRefreshLoop periodically enqueues jobs which refresh instance type availability
+// from provider APIs into the database. It must only run in a single process (stats).
+func availabilityRefreshLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started availability refresh routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Availability refresh routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	for {
+		select {
+		case <-ticker.C:
+			availabilityRefreshTick(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// availabilityRefreshTick enqueues availability refresh jobs for all providers.
+func availabilityRefreshTick(ctx context.Context) {
+	for _, provider := range refreshedProviders {
+		job := worker.Job{
+			Type: jobs.TypeRefreshAvailability,
+			Args: jobs.RefreshAvailabilityTaskArgs{
+				Provider: provider,
+			},
+		}
+		err := queue.GetEnqueuer(ctx).Enqueue(ctx, &job)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("Unable to enqueue availability refresh job for %s", provider.String())
+		}
+	}
+}
+
+// availabilityReloadLoop periodically loads refreshed instance type availability from the
+// database into the preloaded (embedded) instance type information.
+func availabilityReloadLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started availability reload routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Availability reload routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	reloadAll := func() {
+		for _, provider := range refreshedProviders {
+			err := availabilityReloadTick(ctx, provider)
+			if err != nil {
+				logger.Error().Err(err).Msgf("Unable to reload instance type availability for %s", provider.String())
+			}
+		}
+	}
+	reloadAll()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloadAll()
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func availabilityReloadTick(ctx context.Context, provider models.ProviderType) error {
+	list, err := dao.GetInstanceTypeAvailabilityDao(ctx).UnscopedList(ctx, provider)
+	if err != nil {
+		return fmt.Errorf("unable to list availability: %w", err)
+	}
+	if len(list) == 0 {
+		// nothing was refreshed yet, keep embedded data
+		return nil
+	}
+
+	rit := clients.NewRegionalInstanceTypes()
+	for _, a := range list {
+		names := make([]clients.InstanceTypeName, len(a.InstanceTypes))
+		for i, name := range a.InstanceTypes {
+			names[i] = clients.InstanceTypeName(name)
+		}
+		rit.Set(a.Region, a.Zone, names)
+	}
+
+	switch provider {
+	case models.ProviderTypeAWS:
+		err = preload.EC2InstanceType.UpdateAvailability(rit)
+	case models.ProviderTypeAzure:
+		err = preload.AzureInstanceType.UpdateAvailability(rit)
+	case models.ProviderTypeGCP:
+		err = preload.GCPInstanceType.UpdateAvailability(rit)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to update availability: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Debug().Msgf("Reloaded %s instance type availability for %d regions and zones", provider.String(), len(list))
+	return nil
+}
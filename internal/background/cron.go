@@ -0,0 +1,153 @@
+package background
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// cronSchedule is a parsed standard five field cron expression (minute, hour, day of month,
+// month, day of week). Fields support wildcards, values, ranges, lists and steps (e.g.
+// "*/15 8-18 * * 1-5"), names of months and days are not supported. Macros @hourly, @daily,
+// @midnight, @weekly, @monthly, @yearly and @annually are supported.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// when both day fields are restricted, a day matches when either of them matches
+	domStar, dowStar bool
+}
+
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields in '%s'", ErrInvalidCron, expr)
+	}
+
+	var err error
+	s := &cronSchedule{
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+
+	// both 0 and 7 stand for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+// parseCronField returns a bit set of values matching a comma-separated list of items.
+func parseCronField(field string, lowest, highest int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("%w: invalid step in '%s'", ErrInvalidCron, item)
+			}
+		}
+
+		low, high := lowest, highest
+		if rng != "*" {
+			lowStr, highStr, isRange := strings.Cut(rng, "-")
+			var err error
+			low, err = strconv.Atoi(lowStr)
+			if err != nil {
+				return 0, fmt.Errorf("%w: invalid value in '%s'", ErrInvalidCron, item)
+			}
+			high = low
+			if isRange {
+				high, err = strconv.Atoi(highStr)
+				if err != nil {
+					return 0, fmt.Errorf("%w: invalid range in '%s'", ErrInvalidCron, item)
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the maximum
+				high = highest
+			}
+		}
+
+		if low < lowest || high > highest || low > high {
+			return 0, fmt.Errorf("%w: '%s' out of range %d-%d", ErrInvalidCron, item, lowest, highest)
+		}
+		for i := low; i <= high; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first matching time after t with minute precision. Returns zero time when
+// there is no match within five years (e.g. February 30).
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
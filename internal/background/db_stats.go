@@ -21,12 +21,7 @@ func dbStatsLoop(ctx context.Context, sleep time.Duration) {
 	for {
 		select {
 		case <-ticker.C:
-			metrics.ObserveDbStatsDuration(func() {
-				err := dbStatsTick(ctx)
-				if err != nil {
-					logger.Error().Err(err).Msg("Error while performing database statistics query")
-				}
-			})
+			dbStatsObservedTick(ctx)
 
 		case <-ctx.Done():
 			ticker.Stop()
@@ -35,6 +30,16 @@ func dbStatsLoop(ctx context.Context, sleep time.Duration) {
 	}
 }
 
+// dbStatsObservedTick updates reservation statistics and observes the query duration.
+func dbStatsObservedTick(ctx context.Context) {
+	metrics.ObserveDbStatsDuration(func() {
+		err := dbStatsTick(ctx)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Error while performing database statistics query")
+		}
+	})
+}
+
 func dbStatsTick(ctx context.Context) error {
 	logger := zerolog.Ctx(ctx)
 	sdao := dao.GetStatDao(ctx)
@@ -0,0 +1,48 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// expiryDigestLoop periodically enqueues a job which sends a digest of expiring resources to
+// every organization. It must only run in a single process (stats).
+func expiryDigestLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started expiry digest routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Expiry digest routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	for {
+		select {
+		case <-ticker.C:
+			expiryDigestTick(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// expiryDigestTick enqueues an expiry digest notification job.
+func expiryDigestTick(ctx context.Context) {
+	job := worker.Job{
+		Type: jobs.TypeNotifyExpiryDigest,
+		Args: jobs.NotifyExpiryDigestTaskArgs{
+			Days: config.Application.ExpiryDigestDays,
+		},
+	}
+	err := queue.GetEnqueuer(ctx).Enqueue(ctx, &job)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to enqueue expiry digest notification job")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"github.com/rs/zerolog"
 )
 
@@ -20,14 +21,65 @@ func InitializeApi(ctx context.Context) {
 	logger := zerolog.Ctx(ctx).With().Bool("background", true).Logger()
 	ctx = logger.WithContext(ctx)
 
+	// start reload of runtime settings and secrets
+	config.WatchChanges(ctx)
+	secrets.StartRefresh(ctx)
+
 	// start availability request batch sender
 	go sendAvailabilityRequestMessages(ctx, availabilityStatusBatchSize, 5*time.Second)
+
+	// start instance type availability reloader
+	if config.Application.AvailabilityReload > 0 {
+		go availabilityReloadLoop(ctx, config.Application.AvailabilityReload)
+	}
+
+	// start outbox relay and webhook deliveries for jobs of the in-memory worker
+	if config.Worker.Queue == "memory" {
+		startOutboxRelay(ctx)
+		startWebhookDelivery(ctx)
+	}
 }
 
 // InitializeWorker starts background goroutines for worker processes.
 // Use context cancellation to stop them.
 func InitializeWorker(ctx context.Context) {
-	// no background goroutines at the moment
+	logger := zerolog.Ctx(ctx).With().Bool("background", true).Logger()
+	ctx = logger.WithContext(ctx)
+
+	// start reload of runtime settings and secrets
+	config.WatchChanges(ctx)
+	secrets.StartRefresh(ctx)
+
+	// start recurring jobs scheduler (only the elected replica runs tasks)
+	if config.Scheduler.Enabled {
+		s, err := newScheduler()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Unable to initialize scheduler")
+		}
+		go schedulerLoop(ctx, s, schedulerTick)
+	}
+
+	// start outbox relay of notifications and webhook events stored by jobs
+	startOutboxRelay(ctx)
+
+	// start webhook deliveries
+	startWebhookDelivery(ctx)
+}
+
+func startOutboxRelay(ctx context.Context) {
+	if config.Worker.OutboxInterval > 0 && (config.Kafka.Enabled || config.Webhooks.Enabled) {
+		go outboxRelayLoop(ctx, config.Worker.OutboxInterval)
+	} else {
+		zerolog.Ctx(ctx).Debug().Msg("Outbox relay is disabled")
+	}
+}
+
+func startWebhookDelivery(ctx context.Context) {
+	if config.Webhooks.Enabled {
+		go webhookDeliveryLoop(ctx, config.Webhooks.Interval)
+	} else {
+		zerolog.Ctx(ctx).Debug().Msg("Webhook delivery is disabled")
+	}
 }
 
 // InitializeStats starts background goroutines for the statuser process.
@@ -36,9 +88,53 @@ func InitializeStats(ctx context.Context) {
 	logger := zerolog.Ctx(ctx).With().Bool("background", true).Logger()
 	ctx = logger.WithContext(ctx)
 
+	// start reload of runtime settings and secrets
+	config.WatchChanges(ctx)
+	secrets.StartRefresh(ctx)
+
 	// start job queue telemetry
 	go jobQueueMetricLoop(ctx, config.Stats.JobQueue, config.Hostname())
 
+	// recurring tasks are run by the worker scheduler when enabled
+	if config.Scheduler.Enabled {
+		logger.Debug().Msg("Recurring tasks are handled by the worker scheduler")
+		return
+	}
+
 	// start database statistics
 	go dbStatsLoop(ctx, config.Stats.ReservationsInterval)
+
+	// start instance type availability refresh (memory queue is process-local and has no workers here)
+	if config.Stats.AvailabilityInterval > 0 && config.Worker.Queue != "memory" {
+		go availabilityRefreshLoop(ctx, config.Stats.AvailabilityInterval)
+	} else {
+		logger.Debug().Msg("Instance type availability refresh is disabled")
+	}
+
+	// start pubkey expiry notifications (sent from workers which have notifications configured),
+	// the digest replaces them when enabled
+	if config.Stats.ExpiryDigestInterval > 0 && config.Application.ExpiryDigestDays > 0 && config.Worker.Queue != "memory" {
+		go expiryDigestLoop(ctx, config.Stats.ExpiryDigestInterval)
+	} else if config.Stats.PubkeyExpiryInterval > 0 && config.Application.PubkeyExpiry.NotifyDays > 0 && config.Worker.Queue != "memory" {
+		go pubkeyExpiryLoop(ctx, config.Stats.PubkeyExpiryInterval)
+	} else {
+		logger.Debug().Msg("Pubkey expiry notifications are disabled")
+	}
+
+	// jobs of dead workers are only enqueued again by the worker scheduler
+	logger.Debug().Msg("Job reaper is disabled, it requires the worker scheduler")
+
+	// start purge of records deleted before the retention period
+	if config.Stats.PurgeInterval > 0 && config.Application.DeletedRetention > 0 {
+		go purgeDeletedLoop(ctx, config.Stats.PurgeInterval)
+	} else {
+		logger.Debug().Msg("Purge of deleted records is disabled")
+	}
+
+	// start archival of old terminated reservations
+	if config.Stats.ArchiveInterval > 0 && config.Application.ArchiveAge > 0 {
+		go archiveReservationsLoop(ctx, config.Stats.ArchiveInterval)
+	} else {
+		logger.Debug().Msg("Archival of reservations is disabled")
+	}
 }
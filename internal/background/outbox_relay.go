@@ -0,0 +1,67 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/webhooks"
+	"github.com/rs/zerolog"
+)
+
+// Maximum amount of outbox messages published within a single transaction.
+const outboxRelayBatchSize = 100
+
+// outboxRelayLoop periodically publishes messages stored in the outbox together with reservation
+// changes. Messages are deleted only after they were sent, guaranteeing at-least-once delivery.
+// Webhook events are not sent to Kafka, they are turned into webhook deliveries.
+// Multiple processes can relay at the same time, rows are locked.
+func outboxRelayLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started outbox relay routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Outbox relay routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	for {
+		select {
+		case <-ticker.C:
+			outboxRelayTick(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// outboxRelayTick publishes pending messages in batches until there are no messages left.
+func outboxRelayTick(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	publish := func(m *models.OutboxMessage) error {
+		if m.Topic == webhooks.Topic {
+			return webhooks.Fanout(ctx, m)
+		}
+		return kafka.Send(ctx, kafka.NewMessageFromOutbox(m))
+	}
+
+	for ctx.Err() == nil {
+		published, failed, err := dao.GetOutboxDao(ctx).UnscopedRelay(ctx, outboxRelayBatchSize, publish)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to relay outbox messages")
+			return
+		}
+		metrics.AddOutboxMessages(published, failed)
+		if failed > 0 {
+			logger.Warn().Msgf("Unable to publish %d outbox messages, will retry later", failed)
+		}
+		if published+failed < outboxRelayBatchSize {
+			return
+		}
+		logger.Debug().Msgf("Published %d outbox messages, continuing", published)
+	}
+}
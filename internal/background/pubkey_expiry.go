@@ -0,0 +1,48 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// pubkeyExpiryLoop periodically enqueues a job which notifies about pubkeys which are about
+// to expire. It must only run in a single process (stats).
+func pubkeyExpiryLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started pubkey expiry routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Pubkey expiry routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	for {
+		select {
+		case <-ticker.C:
+			pubkeyExpiryTick(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// pubkeyExpiryTick enqueues a pubkey expiry notification job.
+func pubkeyExpiryTick(ctx context.Context) {
+	job := worker.Job{
+		Type: jobs.TypeNotifyPubkeyExpiry,
+		Args: jobs.NotifyPubkeyExpiryTaskArgs{
+			NotifyDays: config.Application.PubkeyExpiry.NotifyDays,
+		},
+	}
+	err := queue.GetEnqueuer(ctx).Enqueue(ctx, &job)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to enqueue pubkey expiry notification job")
+	}
+}
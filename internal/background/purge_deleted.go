@@ -0,0 +1,54 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/rs/zerolog"
+)
+
+// purgeDeletedLoop periodically purges pubkeys and reservations which were deleted by users
+// before the retention period. It must only run in a single process (stats).
+func purgeDeletedLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started purge of deleted records routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Purge of deleted records routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	for {
+		select {
+		case <-ticker.C:
+			purgeDeletedTick(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// purgeDeletedTick permanently deletes records deleted before the retention period. Reservations
+// are purged first, details of the remaining ones are deleted together with their pubkey.
+func purgeDeletedTick(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	before := time.Now().Add(-config.Application.DeletedRetention)
+
+	count, err := dao.GetReservationDao(ctx).UnscopedPurgeDeleted(ctx, before)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to purge deleted reservations")
+		return
+	} else if count > 0 {
+		logger.Info().Msgf("Purged %d reservations deleted before %s", count, before.Format(time.RFC3339))
+	}
+
+	count, err = dao.GetPubkeyDao(ctx).UnscopedPurgeDeleted(ctx, before)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to purge deleted pubkeys")
+	} else if count > 0 {
+		logger.Info().Msgf("Purged %d pubkeys deleted before %s", count, before.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,200 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/rs/zerolog"
+)
+
+// schedulerLockKey is the Postgres advisory lock key electing the scheduler leader.
+const schedulerLockKey int64 = 0x70726f765f736368
+
+// schedulerTick is how often the scheduler checks due tasks and the leader lock.
+const schedulerTick = 15 * time.Second
+
+// scheduledTask is a recurring task of the scheduler. Tasks only enqueue jobs or perform
+// short database operations, they are executed synchronously by the leader.
+type scheduledTask struct {
+	name     string
+	schedule *cronSchedule
+	run      func(ctx context.Context)
+	next     time.Time
+}
+
+// leaderLock is held by the scheduler leader.
+type leaderLock interface {
+	Check(ctx context.Context) error
+	Release(ctx context.Context)
+}
+
+type scheduler struct {
+	tasks   []*scheduledTask
+	tryLock func(ctx context.Context) (leaderLock, error)
+	lock    leaderLock
+}
+
+func tryAdvisoryLeaderLock(ctx context.Context) (leaderLock, error) {
+	lock, err := db.TryAdvisoryLock(ctx, schedulerLockKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to obtain scheduler lock: %w", err)
+	}
+	if lock == nil {
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// newScheduledTask returns nil task when the cron expression is empty (disabled).
+func newScheduledTask(name, expr string, run func(ctx context.Context)) (*scheduledTask, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	schedule, err := parseCron(expr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse schedule of %s: %w", name, err)
+	}
+	return &scheduledTask{name: name, schedule: schedule, run: run}, nil
+}
+
+// newScheduler creates a scheduler with recurring tasks from the configuration.
+func newScheduler() (*scheduler, error) {
+	s := &scheduler{tryLock: tryAdvisoryLeaderLock}
+
+	add := func(name, expr string, run func(ctx context.Context)) error {
+		task, err := newScheduledTask(name, expr, run)
+		if err != nil {
+			return err
+		}
+		if task != nil {
+			s.tasks = append(s.tasks, task)
+		}
+		return nil
+	}
+
+	err := add("availability refresh", config.Scheduler.AvailabilityRefresh, availabilityRefreshTick)
+	if err != nil {
+		return nil, err
+	}
+	if config.Application.ExpiryDigestDays > 0 {
+		err = add("expiry digest", config.Scheduler.ExpiryDigest, expiryDigestTick)
+		if err != nil {
+			return nil, err
+		}
+	} else if config.Application.PubkeyExpiry.NotifyDays > 0 {
+		err = add("pubkey expiry", config.Scheduler.PubkeyExpiry, pubkeyExpiryTick)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// heartbeats are only recorded by redis workers
+	if config.Worker.Queue == "redis" {
+		err = add("job reaper", config.Scheduler.JobReaper, jobReaperTick)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = add("database statistics", config.Scheduler.DbStats, dbStatsObservedTick)
+	if err != nil {
+		return nil, err
+	}
+	if config.Application.DeletedRetention > 0 {
+		err = add("purge deleted", config.Scheduler.PurgeDeleted, purgeDeletedTick)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Application.ArchiveAge > 0 {
+		err = add("archive reservations", config.Scheduler.ArchiveReservations, archiveReservationsTick)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// tick obtains or checks the leader lock and runs due tasks when the scheduler is the leader.
+func (s *scheduler) tick(ctx context.Context, now time.Time) {
+	logger := zerolog.Ctx(ctx)
+
+	if s.lock == nil {
+		lock, err := s.tryLock(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Unable to elect scheduler leader")
+			return
+		}
+		if lock == nil {
+			return
+		}
+
+		// tasks missed during the election are not run, the previous leader likely did
+		s.lock = lock
+		for _, task := range s.tasks {
+			task.next = task.schedule.Next(now)
+		}
+		logger.Info().Msgf("Elected as scheduler leader with %d recurring tasks", len(s.tasks))
+		return
+	}
+
+	if err := s.lock.Check(ctx); err != nil {
+		logger.Warn().Err(err).Msg("Lost scheduler leadership")
+		s.release(ctx)
+		return
+	}
+
+	for _, task := range s.tasks {
+		if task.next.IsZero() || now.Before(task.next) {
+			continue
+		}
+		logger.Debug().Msgf("Running scheduled task %s", task.name)
+		task.run(ctx)
+		task.next = task.schedule.Next(now)
+	}
+}
+
+func (s *scheduler) release(ctx context.Context) {
+	if s.lock != nil {
+		s.lock.Release(ctx)
+		s.lock = nil
+	}
+}
+
+// jobReaperTick enqueues again jobs without heartbeat for the heartbeat timeout.
+func jobReaperTick(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	count, err := jobs.RequeueStaleJobs(ctx, config.Worker.HeartbeatTimeout)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to reap stale jobs")
+	} else if count > 0 {
+		logger.Warn().Msgf("Enqueued %d jobs of dead workers again", count)
+	}
+}
+
+// schedulerLoop runs recurring tasks in one of worker replicas elected via database lock.
+func schedulerLoop(ctx context.Context, s *scheduler, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started scheduler routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Scheduler routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+
+	for {
+		select {
+		case <-ticker.C:
+			s.tick(ctx, time.Now().UTC())
+
+		case <-ctx.Done():
+			ticker.Stop()
+			// use a new context, the lock connection must be closed even when cancelled
+			s.release(context.Background())
+			return
+		}
+	}
+}
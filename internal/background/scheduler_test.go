@@ -0,0 +1,94 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errLockLost = errors.New("lock lost")
+
+type fakeLeaderLock struct {
+	lost     bool
+	released bool
+}
+
+func (l *fakeLeaderLock) Check(_ context.Context) error {
+	if l.lost {
+		return errLockLost
+	}
+	return nil
+}
+
+func (l *fakeLeaderLock) Release(_ context.Context) {
+	l.released = true
+}
+
+func newTestScheduler(t *testing.T, lock *fakeLeaderLock, runs *int) *scheduler {
+	t.Helper()
+
+	task, err := newScheduledTask("test", "*/10 * * * *", func(_ context.Context) { *runs++ })
+	require.NoError(t, err)
+	return &scheduler{
+		tasks: []*scheduledTask{task},
+		tryLock: func(_ context.Context) (leaderLock, error) {
+			if lock == nil {
+				return nil, nil
+			}
+			return lock, nil
+		},
+	}
+}
+
+func TestSchedulerLeader(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, time.March, 15, 10, 5, 0, 0, time.UTC)
+	lock := &fakeLeaderLock{}
+	runs := 0
+	s := newTestScheduler(t, lock, &runs)
+
+	// election
+	s.tick(ctx, now)
+	require.NotNil(t, s.lock)
+	assert.Equal(t, 0, runs)
+
+	// not due yet
+	s.tick(ctx, now.Add(time.Minute))
+	assert.Equal(t, 0, runs)
+
+	// due at 10:10
+	s.tick(ctx, now.Add(5*time.Minute))
+	assert.Equal(t, 1, runs)
+	s.tick(ctx, now.Add(5*time.Minute+15*time.Second))
+	assert.Equal(t, 1, runs)
+
+	// leadership lost
+	lock.lost = true
+	s.tick(ctx, now.Add(15*time.Minute))
+	assert.Equal(t, 1, runs)
+	assert.True(t, lock.released)
+	assert.Nil(t, s.lock)
+}
+
+func TestSchedulerFollower(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, time.March, 15, 10, 5, 0, 0, time.UTC)
+	runs := 0
+	s := newTestScheduler(t, nil, &runs)
+
+	for i := 0; i < 30; i++ {
+		s.tick(ctx, now.Add(time.Duration(i)*time.Minute))
+	}
+	assert.Nil(t, s.lock)
+	assert.Equal(t, 0, runs)
+}
+
+func TestNewScheduledTaskDisabled(t *testing.T) {
+	task, err := newScheduledTask("test", "", func(_ context.Context) {})
+	require.NoError(t, err)
+	assert.Nil(t, task)
+}
@@ -0,0 +1,65 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/webhooks"
+	"github.com/rs/zerolog"
+)
+
+// Maximum amount of webhook deliveries sent within a single transaction. Deliveries are sent
+// sequentially, the batch is small so locks are not held for too long.
+const webhookDeliveryBatchSize = 20
+
+// webhookDeliveryLoop periodically sends pending webhook deliveries. Multiple processes can
+// deliver at the same time, rows are locked.
+func webhookDeliveryLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started webhook delivery routine with tick interval %.2f seconds", sleep.Seconds())
+	defer func() {
+		logger.Debug().Msgf("Webhook delivery routine exited")
+	}()
+	ticker := time.NewTicker(sleep)
+	client := webhooks.NewClient(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			webhookDeliveryTick(ctx, client)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// webhookDeliveryTick sends due deliveries in batches until there are no deliveries left.
+func webhookDeliveryTick(ctx context.Context, client httpClients.HttpRequestDoer) {
+	logger := zerolog.Ctx(ctx)
+	deliver := func(w *models.Webhook, d *models.WebhookDelivery) (int, error) {
+		return webhooks.Deliver(ctx, client, w, d)
+	}
+
+	for ctx.Err() == nil {
+		delivered, failed, err := dao.GetWebhookDao(ctx).UnscopedDeliver(ctx, webhookDeliveryBatchSize, config.Webhooks.MaxAttempts, deliver)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to send webhook deliveries")
+			return
+		}
+		metrics.AddWebhookDeliveries(delivered, failed)
+		if failed > 0 {
+			logger.Warn().Msgf("Unable to send %d webhook deliveries, will retry later", failed)
+		}
+		if delivered+failed < webhookDeliveryBatchSize {
+			return
+		}
+		logger.Debug().Msgf("Sent %d webhook deliveries, continuing", delivered)
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value []byte
+
+	// zero time for items without expiration
+	expires time.Time
+}
+
+func (mi memoryItem) expired(now time.Time) bool {
+	return !mi.expires.IsZero() && !mi.expires.After(now)
+}
+
+// memoryStore keeps items in process memory, expired items are removed periodically.
+type memoryStore struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+}
+
+func newMemoryStore(cleanupInterval time.Duration) *memoryStore {
+	ms := &memoryStore{items: make(map[string]memoryItem)}
+	if cleanupInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cleanupInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				ms.cleanup(time.Now())
+			}
+		}()
+	}
+	return ms
+}
+
+func (ms *memoryStore) get(_ context.Context, key string) ([]byte, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	item, ok := ms.items[key]
+	if !ok || item.expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return item.value, nil
+}
+
+func (ms *memoryStore) set(_ context.Context, key string, value []byte, expiration time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	item := memoryItem{value: value}
+	if expiration > 0 {
+		item.expires = time.Now().Add(expiration)
+	}
+	ms.items[key] = item
+	return nil
+}
+
+func (ms *memoryStore) del(_ context.Context, key string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.items, key)
+	return nil
+}
+
+func (ms *memoryStore) cleanup(now time.Time) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for key, item := range ms.items {
+		if item.expired(now) {
+			delete(ms.items, key)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withMemoryStore(t *testing.T) {
+	t.Helper()
+	backend = newMemoryStore(0)
+	t.Cleanup(func() { backend = nil })
+}
+
+func TestMemoryFindSetDelete(t *testing.T) {
+	withMemoryStore(t)
+	ctx := context.Background()
+
+	value := clients.AccountDetailsAWS{AccountID: "123456789012"}
+	require.NoError(t, SetForever(ctx, "1", &value))
+
+	result := clients.AccountDetailsAWS{}
+	require.NoError(t, Find(ctx, "1", &result))
+	assert.Equal(t, value, result)
+
+	require.NoError(t, Delete(ctx, "1", &result))
+	assert.ErrorIs(t, Find(ctx, "1", &result), ErrNotFound)
+}
+
+func TestMemoryExpiration(t *testing.T) {
+	withMemoryStore(t)
+	ctx := context.Background()
+
+	value := clients.AccountDetailsAWS{AccountID: "123456789012"}
+	require.NoError(t, SetExpires(ctx, "1", &value, time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
+
+	result := clients.AccountDetailsAWS{}
+	assert.ErrorIs(t, Find(ctx, "1", &result), ErrNotFound)
+
+	ms := backend.(*memoryStore)
+	ms.cleanup(time.Now())
+	assert.Empty(t, ms.items)
+}
+
+func TestDisabledCache(t *testing.T) {
+	ctx := context.Background()
+
+	value := clients.AccountDetailsAWS{AccountID: "123456789012"}
+	require.NoError(t, Set(ctx, "1", &value))
+	assert.ErrorIs(t, Find(ctx, "1", &value), ErrNotFound)
+	assert.NoError(t, Delete(ctx, "1", &value))
+}
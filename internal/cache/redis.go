@@ -1,6 +1,7 @@
-// Package cache provides application cache based on Redis. This feature can be turned off
-// via configuration and in that case function Find return ErrNotFound and functions Set
-// do nothing.
+// Package cache provides application cache based on Redis or process memory. This feature
+// can be turned off via configuration and in that case function Find return ErrNotFound and
+// functions Set and Delete do nothing. Memory cache is not shared between processes, items
+// are only invalidated by expiration in other processes.
 package cache
 
 import (
@@ -25,11 +26,8 @@ var (
 	ErrNotFound = errors.New("not found in cache")
 	ErrNilValue = errors.New("value is nil")
 
-	// when redis is enabled via configuration
-	redisEnabled bool
-
-	// the client
-	client *redis.Client
+	// the cache implementation, nil when cache is disabled via configuration
+	backend store
 
 	// application id "constant" memory-only cache
 	appTypeId      *string
@@ -44,25 +42,73 @@ type Cacheable interface {
 	CacheKeyName() string
 }
 
-// Initialize creates new Redis client if allowed by application config, or does nothing.
+// store is a cache implementation, get returns ErrNotFound on cache miss.
+type store interface {
+	get(ctx context.Context, key string) ([]byte, error)
+	set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	del(ctx context.Context, key string) error
+}
+
+// Initialize creates new Redis client or memory cache if allowed by application config, or does nothing.
 func Initialize() {
-	if config.Application.Cache.Type == "redis" {
-		redisEnabled = true
+	// register all Cacheable types
+	gob.Register(&models.Account{})
+	gob.Register(&clients.AccountDetailsAWS{})
+	gob.Register(&clients.Authentication{})
+	gob.Register(&clients.ImageList{})
+	gob.Register(&clients.AccessList{})
+
+	switch config.Application.Cache.Type {
+	case "redis":
 		log.Logger.Info().Bool("cache", true).Msg("Initializing redis application cache")
-
-		// register all Cacheable types
-		gob.Register(&models.Account{})
-		gob.Register(&clients.AccountDetailsAWS{})
-
-		client = redis.NewClient(&redis.Options{
+		backend = &redisStore{client: redis.NewClient(&redis.Options{
 			Addr:     config.RedisHostAndPort(),
 			Username: config.Application.Cache.Redis.User,
 			Password: config.Application.Cache.Redis.Password,
 			DB:       config.Application.Cache.Redis.DB,
-		})
-	} else {
+		})}
+	case "memory":
+		log.Logger.Info().Bool("cache", true).Msg("Initializing memory application cache")
+		backend = newMemoryStore(config.Application.Cache.Memory.CleanupInterval)
+	default:
 		log.Logger.Info().Bool("cache", true).Msg("No application cache in use")
+		backend = nil
+	}
+}
+
+type redisStore struct {
+	client *redis.Client
+}
+
+func (rs *redisStore) get(ctx context.Context, key string) ([]byte, error) {
+	cmd := rs.client.Get(ctx, key)
+	if errors.Is(cmd.Err(), redis.Nil) {
+		return nil, ErrNotFound
+	} else if cmd.Err() != nil {
+		return nil, fmt.Errorf("redis get error: %w", cmd.Err())
+	}
+
+	buf, err := cmd.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("redis bytes conversion error: %w", err)
+	}
+	return buf, nil
+}
+
+func (rs *redisStore) set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	cmd := rs.client.Set(ctx, key, value, expiration)
+	if cmd.Err() != nil {
+		return fmt.Errorf("redis set error: %w", cmd.Err())
+	}
+	return nil
+}
+
+func (rs *redisStore) del(ctx context.Context, key string) error {
+	cmd := rs.client.Del(ctx, key)
+	if cmd.Err() != nil {
+		return fmt.Errorf("redis del error: %w", cmd.Err())
 	}
+	return nil
 }
 
 // FindAppTypeId returns "application id" special identifier, or returns ErrNotFound.
@@ -88,7 +134,7 @@ func SetAppTypeId(_ context.Context, value string) error {
 // Find returns an item from cache. ErrNotFound is returned on cache miss or when
 // the item cannot be deserialized
 func Find(ctx context.Context, key string, value Cacheable) error {
-	if !redisEnabled {
+	if backend == nil {
 		return ErrNotFound
 	}
 
@@ -98,19 +144,13 @@ func Find(ctx context.Context, key string, value Cacheable) error {
 
 	prefix := value.CacheKeyName()
 
-	cmd := client.Get(ctx, prefix+key)
-	if errors.Is(cmd.Err(), redis.Nil) {
+	buf, err := backend.get(ctx, prefix+key)
+	if errors.Is(err, ErrNotFound) {
 		metrics.IncCacheHit(prefix, "miss")
 		return ErrNotFound
-	} else if cmd.Err() != nil {
-		metrics.IncCacheHit(prefix, "err")
-		return fmt.Errorf("redis get error: %w", cmd.Err())
-	}
-
-	buf, err := cmd.Bytes()
-	if err != nil {
+	} else if err != nil {
 		metrics.IncCacheHit(prefix, "err")
-		return fmt.Errorf("redis bytes conversion error: %w", err)
+		return err
 	}
 
 	dec := gob.NewDecoder(bytes.NewReader(buf))
@@ -118,7 +158,7 @@ func Find(ctx context.Context, key string, value Cacheable) error {
 	err = dec.Decode(value)
 	if err != nil {
 		// decode error can be thrown if previous cache entry was JSON-encoded, return not found to overwrite it
-		zerolog.Ctx(ctx).Warn().Err(err).Bool("cache", true).Msgf("Redis cache decode error: %s", err.Error())
+		zerolog.Ctx(ctx).Warn().Err(err).Bool("cache", true).Msgf("Cache decode error: %s", err.Error())
 		metrics.IncCacheHit(prefix, "err")
 		return ErrNotFound
 	}
@@ -130,7 +170,7 @@ func Find(ctx context.Context, key string, value Cacheable) error {
 
 // SetExpires calls Set with specific expiration.
 func SetExpires(ctx context.Context, key string, value Cacheable, expiration time.Duration) error {
-	if !redisEnabled {
+	if backend == nil {
 		return nil
 	}
 
@@ -146,18 +186,32 @@ func SetExpires(ctx context.Context, key string, value Cacheable, expiration tim
 	err := enc.Encode(value)
 	if err != nil {
 		metrics.IncCacheHit(prefix, "err")
-		return fmt.Errorf("unable to encode for Redis cache: %w", err)
+		return fmt.Errorf("unable to encode for cache: %w", err)
 	}
 
-	cmd := client.Set(ctx, prefix+key, buf.String(), expiration)
-	if cmd.Err() != nil {
+	err = backend.set(ctx, prefix+key, buf.Bytes(), expiration)
+	if err != nil {
 		metrics.IncCacheHit(prefix, "err")
-		return fmt.Errorf("redis set error: %w", cmd.Err())
+		return err
 	}
 
 	return nil
 }
 
+// Delete removes an item from cache, value is only used for the key prefix. Deleting
+// a missing item is not an error.
+func Delete(ctx context.Context, key string, value Cacheable) error {
+	if backend == nil {
+		return nil
+	}
+
+	if value == nil {
+		return ErrNilValue
+	}
+
+	return backend.del(ctx, value.CacheKeyName()+key)
+}
+
 // SetForever calls Set with Forever expiration duration.
 // nolint: wrapcheck
 func SetForever(ctx context.Context, key string, value Cacheable) error {
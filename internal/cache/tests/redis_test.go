@@ -58,3 +58,16 @@ func TestPrefix(t *testing.T) {
 
 	require.Equal(t, value1, result)
 }
+
+func TestDelete(t *testing.T) {
+	value := clients.Authentication{Payload: "arn:aws:iam::230214684733:role/test", ProviderType: models.ProviderTypeAWS}
+	err := cache.Set(context.Background(), "1/42", &value)
+	require.NoError(t, err)
+
+	err = cache.Delete(context.Background(), "1/42", &value)
+	require.NoError(t, err)
+
+	result := clients.Authentication{}
+	err = cache.Find(context.Background(), "1/42", &result)
+	require.ErrorIs(t, err, cache.ErrNotFound)
+}
@@ -12,6 +12,27 @@ type Authentication struct {
 	SourceApplictionID string              `json:"source_application_id"`
 	ProviderType       models.ProviderType `json:"type"`
 	Payload            string              `json:"payload"`
+
+	// AWS roles assumed in order before the role of the payload (jump roles), empty when the
+	// role of the payload is assumed directly.
+	RoleChain []AssumedRole `json:"role_chain,omitempty"`
+
+	// External ID used when assuming the AWS role of the payload.
+	ExternalID string `json:"external_id,omitempty"`
+
+	// Azure cloud of the subscription (AzureCloud, AzureUSGovernment or AzureChinaCloud), empty
+	// is the public cloud.
+	AzureCloud string `json:"azure_cloud,omitempty"`
+}
+
+// AssumedRole is an intermediate AWS role of a role chain.
+type AssumedRole struct {
+	ARN        string `json:"arn"`
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+func (a Authentication) CacheKeyName() string {
+	return "sources_authentication"
 }
 
 func NewAuthentication(str string, provType models.ProviderType) *Authentication {
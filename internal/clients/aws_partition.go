@@ -0,0 +1,69 @@
+package clients
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// AWS partitions, the partition is the second field of ARNs.
+const (
+	AWSPartition         = "aws"
+	AWSGovCloudPartition = "aws-us-gov"
+	AWSChinaPartition    = "aws-cn"
+)
+
+// awsPartitionRegions are regions of partitions other than the commercial one, they are not part
+// of preloaded instance type availability.
+var awsPartitionRegions = map[string][]string{
+	AWSGovCloudPartition: {"us-gov-west-1", "us-gov-east-1"},
+	AWSChinaPartition:    {"cn-north-1", "cn-northwest-1"},
+}
+
+// AWSPartitionOfARN returns the partition of an ARN, error wrapping UnsupportedAWSPartitionErr is
+// returned for unknown partitions.
+func AWSPartitionOfARN(roleArn string) (string, error) {
+	parsed, err := arn.Parse(roleArn)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", UnsupportedAWSPartitionErr, err.Error())
+	}
+	switch parsed.Partition {
+	case AWSPartition, AWSGovCloudPartition, AWSChinaPartition:
+		return parsed.Partition, nil
+	}
+	return "", fmt.Errorf("%w: %s", UnsupportedAWSPartitionErr, parsed.Partition)
+}
+
+// AWSPartitionOfRegion returns the partition of a region, all regions which are not GovCloud
+// or China regions are commercial.
+func AWSPartitionOfRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return AWSGovCloudPartition
+	case strings.HasPrefix(region, "cn-"):
+		return AWSChinaPartition
+	}
+	return AWSPartition
+}
+
+// IsAWSPartitionRegion returns true for known regions of the GovCloud and China partitions.
+func IsAWSPartitionRegion(region string) bool {
+	for _, regions := range awsPartitionRegions {
+		for _, r := range regions {
+			if r == region {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AWSPartitionDefaultRegion returns the first region of the partition, the commercial default
+// is returned for the commercial partition.
+func AWSPartitionDefaultRegion(partition, commercialDefault string) string {
+	if regions, ok := awsPartitionRegions[partition]; ok {
+		return regions[0]
+	}
+	return commercialDefault
+}
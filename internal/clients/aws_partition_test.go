@@ -0,0 +1,38 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSPartitionOfARN(t *testing.T) {
+	tests := []struct {
+		arn       string
+		partition string
+		err       error
+	}{
+		{"arn:aws:iam::230214684733:role/Test", AWSPartition, nil},
+		{"arn:aws-us-gov:iam::230214684733:role/Test", AWSGovCloudPartition, nil},
+		{"arn:aws-cn:iam::230214684733:role/Test", AWSChinaPartition, nil},
+		{"arn:aws-iso:iam::230214684733:role/Test", "", UnsupportedAWSPartitionErr},
+		{"not-an-arn", "", UnsupportedAWSPartitionErr},
+	}
+	for _, tt := range tests {
+		partition, err := AWSPartitionOfARN(tt.arn)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, tt.arn)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.partition, partition)
+	}
+}
+
+func TestAWSPartitionOfRegion(t *testing.T) {
+	assert.Equal(t, AWSPartition, AWSPartitionOfRegion("us-east-1"))
+	assert.Equal(t, AWSGovCloudPartition, AWSPartitionOfRegion("us-gov-west-1"))
+	assert.Equal(t, AWSChinaPartition, AWSPartitionOfRegion("cn-northwest-1"))
+	assert.True(t, IsAWSPartitionRegion("us-gov-east-1"))
+	assert.False(t, IsAWSPartitionRegion("us-east-1"))
+}
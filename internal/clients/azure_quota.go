@@ -0,0 +1,40 @@
+package clients
+
+// AzureQuotaUsage is usage of a single compute quota in a location.
+type AzureQuotaUsage struct {
+	// Name of the quota as shown in the Azure portal (e.g. Standard DSv3 Family vCPUs)
+	Name string
+
+	// Current usage
+	Current int64
+
+	// Limit of the subscription, zero when the quota is not reported
+	Limit int64
+}
+
+// Available returns how many units can still be allocated.
+func (u AzureQuotaUsage) Available() int64 {
+	return u.Limit - u.Current
+}
+
+// AzureVMSizeQuota describes availability of a VM size in a location together with the vCPU
+// quotas a launch of the size consumes.
+type AzureVMSizeQuota struct {
+	// Quota family of the size (e.g. standardDSv3Family)
+	Family string
+
+	// vCPUs of a single VM
+	VCPUs int64
+
+	// Restriction reason code when the size cannot be used in the location, empty when available
+	Restriction string
+
+	// Usage of the family vCPUs quota
+	FamilyUsage AzureQuotaUsage
+
+	// Usage of the total regional vCPUs quota
+	RegionalUsage AzureQuotaUsage
+
+	// Usage of the regional spot vCPUs quota, spot VMs do not consume the other quotas
+	SpotUsage AzureQuotaUsage
+}
@@ -0,0 +1,12 @@
+package clients
+
+import "time"
+
+// ConsoleOutput is the serial console output of an instance.
+type ConsoleOutput struct {
+	// Output of the console, empty when the instance has not written any yet
+	Output string
+
+	// Time the output was last updated, not reported by all providers
+	Timestamp *time.Time
+}
@@ -0,0 +1,22 @@
+package clients
+
+import "context"
+
+type reservationCtxKeyType int
+
+const reservationIdCtxKey reservationCtxKeyType = iota
+
+// WithReservationId returns context copy with ID of the reservation cloud API calls are made for,
+// it is used to attribute the calls (e.g. AWS session tags).
+func WithReservationId(ctx context.Context, reservationId int64) context.Context {
+	return context.WithValue(ctx, reservationIdCtxKey, reservationId)
+}
+
+// ReservationIdOrZero returns ID of the reservation cloud API calls are made for or 0 when not set.
+func ReservationIdOrZero(ctx context.Context) int64 {
+	value, ok := ctx.Value(reservationIdCtxKey).(int64)
+	if !ok {
+		return 0
+	}
+	return value
+}
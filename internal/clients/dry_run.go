@@ -0,0 +1,17 @@
+package clients
+
+// DryRunResult is the verdict of a provider on a launch validated without creating resources.
+type DryRunResult struct {
+	// Passed is true when the provider would perform the launch
+	Passed bool
+
+	// Code of the verdict as returned by the provider (e.g. DryRunOperation, UnauthorizedOperation
+	// or InvalidTemplateDeployment), empty when the provider does not return one
+	Code string
+
+	// Message of the verdict as returned by the provider
+	Message string
+
+	// MissingPermissions are permissions the provider reported as not granted
+	MissingPermissions []string
+}
@@ -18,4 +18,60 @@ var (
 	UnknownAuthenticationTypeErr = errors.New("unknown authentication type")
 	UnknownProviderErr           = errors.New("unknown provider type")
 	MissingProvisioningSources   = errors.New("missing provisioning source authentication")
+
+	// Key host errors
+	UnknownKeyHostErr     = errors.New("unknown key host site")
+	InvalidKeyHostUserErr = errors.New("invalid key host username")
+
+	// AWS partition errors
+	UnsupportedAWSPartitionErr   = errors.New("unsupported AWS partition")
+	AWSPartitionMismatchErr      = errors.New("region is not in the AWS partition of the role")
+	AWSPartitionNotConfiguredErr = errors.New("service account of the AWS partition is not configured")
+
+	// Azure cloud errors
+	UnknownAzureCloudErr       = errors.New("unknown Azure cloud")
+	AzureCloudNotConfiguredErr = errors.New("service account of the Azure cloud is not configured")
 )
+
+// ClientError is an error returned by a backend service or a cloud provider API. It matches
+// its kind via errors.Is (e.g. NotFoundErr) while the underlying error, if any, is available
+// via errors.As and errors.Unwrap, so both the taxonomy and the original SDK error are kept.
+type ClientError struct {
+	// Kind of the error, one of sentinel errors (e.g. NotFoundErr)
+	Kind error
+
+	// Backend service or cloud provider (e.g. "sources", "AWS")
+	Provider string
+
+	// HTTP status code of the response, zero when not known
+	Status int
+
+	// Request ID of the backend service or cloud provider for support cases, empty when not known
+	RequestID string
+
+	// Underlying error, can be nil
+	Err error
+}
+
+func (e *ClientError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Provider, e.Kind.Error())
+	if e.Status != 0 {
+		msg = fmt.Sprintf("%s (status %d)", msg, e.Status)
+	}
+	if e.RequestID != "" {
+		msg = fmt.Sprintf("%s (request id %s)", msg, e.RequestID)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err.Error())
+	}
+	return msg
+}
+
+func (e *ClientError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind of the error and all errors the kind wraps.
+func (e *ClientError) Is(target error) bool {
+	return errors.Is(e.Kind, target)
+}
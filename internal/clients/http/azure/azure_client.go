@@ -3,19 +3,23 @@ package azure
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
-	"github.com/RHEnVision/provisioning-backend/internal/config"
-	"go.opentelemetry.io/otel"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 )
 
 type client struct {
 	subscriptionID string
+	cloud          *cloudSettings
 	credential     *azidentity.ClientSecretCredential
 }
 
@@ -24,20 +28,26 @@ func init() {
 }
 
 func newAzureClient(ctx context.Context, auth *clients.Authentication) (clients.Azure, error) {
-	opts := azidentity.ClientSecretCredentialOptions{}
-	identityClient, err := azidentity.NewClientSecretCredential(config.Azure.TenantID, config.Azure.ClientID, config.Azure.ClientSecret, &opts)
+	settings, err := cloudSettingsFor(auth.AzureCloud)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := azidentity.ClientSecretCredentialOptions{ClientOptions: coreOptions(ctx, settings.configuration)}
+	identityClient, err := azidentity.NewClientSecretCredential(settings.tenantID, settings.clientID, settings.clientSecret, &opts)
 	if err != nil {
 		return nil, fmt.Errorf("unable to init Azure credentials: %w", err)
 	}
 
 	return &client{
 		subscriptionID: auth.Payload,
+		cloud:          settings,
 		credential:     identityClient,
 	}, nil
 }
 
 func (c *client) newResourceGroupsClient(ctx context.Context) (*armresources.ResourceGroupsClient, error) {
-	client, err := armresources.NewResourceGroupsClient(c.subscriptionID, c.credential, nil)
+	client, err := armresources.NewResourceGroupsClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create resources Azure client: %w", err)
 	}
@@ -45,7 +55,7 @@ func (c *client) newResourceGroupsClient(ctx context.Context) (*armresources.Res
 }
 
 func (c *client) newImagesClient(ctx context.Context) (*armcompute.ImagesClient, error) {
-	vmClient, err := armcompute.NewImagesClient(c.subscriptionID, c.credential, nil)
+	vmClient, err := armcompute.NewImagesClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create Image Azure client: %w", err)
 	}
@@ -53,15 +63,31 @@ func (c *client) newImagesClient(ctx context.Context) (*armcompute.ImagesClient,
 }
 
 func (c *client) newVirtualMachinesClient(ctx context.Context) (*armcompute.VirtualMachinesClient, error) {
-	vmClient, err := armcompute.NewVirtualMachinesClient(c.subscriptionID, c.credential, nil)
+	vmClient, err := armcompute.NewVirtualMachinesClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create VM Azure client: %w", err)
 	}
 	return vmClient, nil
 }
 
+func (c *client) newResourceSKUsClient(ctx context.Context) (*armcompute.ResourceSKUsClient, error) {
+	skuClient, err := armcompute.NewResourceSKUsClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create resource SKUs Azure client: %w", err)
+	}
+	return skuClient, nil
+}
+
+func (c *client) newUsageClient(ctx context.Context) (*armcompute.UsageClient, error) {
+	usageClient, err := armcompute.NewUsageClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create compute usage Azure client: %w", err)
+	}
+	return usageClient, nil
+}
+
 func (c *client) newSubscriptionsClient(ctx context.Context) (*armsubscriptions.Client, error) {
-	client, err := armsubscriptions.NewClient(c.credential, nil)
+	client, err := armsubscriptions.NewClient(c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create subscriptioons Azure client: %w", err)
 	}
@@ -69,7 +95,7 @@ func (c *client) newSubscriptionsClient(ctx context.Context) (*armsubscriptions.
 }
 
 func (c *client) newSshKeysClient(ctx context.Context) (*armcompute.SSHPublicKeysClient, error) {
-	client, err := armcompute.NewSSHPublicKeysClient(c.subscriptionID, c.credential, nil)
+	client, err := armcompute.NewSSHPublicKeysClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create SSH keys Azure client: %w", err)
 	}
@@ -77,7 +103,7 @@ func (c *client) newSshKeysClient(ctx context.Context) (*armcompute.SSHPublicKey
 }
 
 func (c *client) newVirtualNetworksClient(ctx context.Context) (*armnetwork.VirtualNetworksClient, error) {
-	vnetClient, err := armnetwork.NewVirtualNetworksClient(c.subscriptionID, c.credential, nil)
+	vnetClient, err := armnetwork.NewVirtualNetworksClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create Virtual networks Azure client: %w", err)
 	}
@@ -85,7 +111,7 @@ func (c *client) newVirtualNetworksClient(ctx context.Context) (*armnetwork.Virt
 }
 
 func (c *client) newSubnetsClient(ctx context.Context) (*armnetwork.SubnetsClient, error) {
-	subnetClient, err := armnetwork.NewSubnetsClient(c.subscriptionID, c.credential, nil)
+	subnetClient, err := armnetwork.NewSubnetsClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create SSH keys Azure client: %w", err)
 	}
@@ -93,7 +119,7 @@ func (c *client) newSubnetsClient(ctx context.Context) (*armnetwork.SubnetsClien
 }
 
 func (c *client) newPublicIPAddressesClient(ctx context.Context) (*armnetwork.PublicIPAddressesClient, error) {
-	publicIPAddressClient, err := armnetwork.NewPublicIPAddressesClient(c.subscriptionID, c.credential, nil)
+	publicIPAddressClient, err := armnetwork.NewPublicIPAddressesClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create public IP addresses Azure client: %w", err)
 	}
@@ -101,7 +127,7 @@ func (c *client) newPublicIPAddressesClient(ctx context.Context) (*armnetwork.Pu
 }
 
 func (c *client) newSecurityGroupsClient(ctx context.Context) (*armnetwork.SecurityGroupsClient, error) {
-	nsgClient, err := armnetwork.NewSecurityGroupsClient(c.subscriptionID, c.credential, nil)
+	nsgClient, err := armnetwork.NewSecurityGroupsClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create security groups Azure client: %w", err)
 	}
@@ -109,35 +135,48 @@ func (c *client) newSecurityGroupsClient(ctx context.Context) (*armnetwork.Secur
 }
 
 func (c *client) newInterfacesClient(ctx context.Context) (*armnetwork.InterfacesClient, error) {
-	nicClient, err := armnetwork.NewInterfacesClient(c.subscriptionID, c.credential, nil)
+	nicClient, err := armnetwork.NewInterfacesClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create interfaces Azure client: %w", err)
 	}
 	return nicClient, nil
 }
 
+// Status only acquires a client credential token and reads the subscription. Token errors wrap
+// AzureSecretExpiredErr or AzureCredentialsInvalidErr, subscription errors wrap
+// AzureRoleAssignmentMissingErr, so service credential problems can be told apart from
+// subscriptions which were not delegated.
 func (c *client) Status(ctx context.Context) error {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "Status")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "Status", "")
 	defer span.End()
 
+	_, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{c.cloud.managementScope()}})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to acquire token: %w", http.MapAzureTokenError(err))
+	}
+
 	client, err := c.newSubscriptionsClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return fmt.Errorf("unable to initialize status request: %w", err)
 	}
 	_, err = client.Get(ctx, c.subscriptionID, nil)
 	if err != nil {
-		return fmt.Errorf("unable to perform status request: %w", err)
+		span.Fail(err)
+		return fmt.Errorf("unable to perform status request: %w", http.MapAzureSubscriptionError(err))
 	}
 	return nil
 }
 
 func (c *client) ListResourceGroups(ctx context.Context) ([]string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListResourceGroups")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "ListResourceGroups", "")
 	defer span.End()
 
 	var list []string
 	rgClient, err := c.newResourceGroupsClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return list, err
 	}
 
@@ -145,6 +184,7 @@ func (c *client) ListResourceGroups(ctx context.Context) ([]string, error) {
 	for pager.More() {
 		page, pagerErr := pager.NextPage(ctx)
 		if pagerErr != nil {
+			span.Fail(pagerErr)
 			return list, fmt.Errorf("failed to fetch resource groups: %w", pagerErr)
 		}
 		for _, rg := range page.ResourceGroupListResult.Value {
@@ -156,17 +196,74 @@ func (c *client) ListResourceGroups(ctx context.Context) ([]string, error) {
 }
 
 func (c *client) TenantId(ctx context.Context) (clients.AzureTenantId, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "TenantId")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "TenantId", "")
 	defer span.End()
 
 	subClient, err := c.newSubscriptionsClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return "", err
 	}
 	response, err := subClient.Get(ctx, c.subscriptionID, nil)
 	if err != nil {
+		span.Fail(err)
 		return "", fmt.Errorf("failed to fetch subscription: %w", err)
 	}
 
 	return clients.AzureTenantId(*response.TenantID), nil
 }
+
+func (c *client) GetImageArchitecture(ctx context.Context, imageID string) (clients.ArchitectureType, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "GetImageArchitecture", "")
+	defer span.End()
+
+	logger := logger(ctx)
+	id, err := arm.ParseResourceID(imageID)
+	if err != nil {
+		// not an ARM resource ID (e.g. community gallery image), it cannot be verified
+		logger.Debug().Err(err).Msgf("Unable to parse Azure image ID %s, skipping verification", imageID)
+		return "", nil
+	}
+
+	var arch string
+	switch strings.ToLower(id.ResourceType.String()) {
+	case "microsoft.compute/images":
+		// managed images are only available for x64 architecture
+		imagesClient, clientErr := armcompute.NewImagesClient(id.SubscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
+		if clientErr != nil {
+			span.Fail(clientErr)
+			return "", fmt.Errorf("unable to create Image Azure client: %w", clientErr)
+		}
+		_, err = imagesClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		arch = string(armcompute.ArchitectureX64)
+	case "microsoft.compute/galleries/images/versions":
+		galleryClient, clientErr := armcompute.NewGalleryImagesClient(id.SubscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
+		if clientErr != nil {
+			span.Fail(clientErr)
+			return "", fmt.Errorf("unable to create Gallery images Azure client: %w", clientErr)
+		}
+		var resp armcompute.GalleryImagesClientGetResponse
+		resp, err = galleryClient.Get(ctx, id.ResourceGroupName, id.Parent.Parent.Name, id.Parent.Name, nil)
+		arch = string(armcompute.ArchitectureX64)
+		if err == nil && resp.Properties != nil && resp.Properties.Architecture != nil {
+			arch = string(*resp.Properties.Architecture)
+		}
+	default:
+		logger.Debug().Msgf("Unsupported Azure image resource type %s, skipping verification", id.ResourceType)
+		return "", nil
+	}
+	if err != nil {
+		span.Fail(err)
+		if http.IsProviderNotFound(err) {
+			return "", fmt.Errorf("cannot get image %s: %w", imageID, http.ImageNotFoundErr)
+		}
+		return "", fmt.Errorf("cannot get image %s: %w", imageID, err)
+	}
+
+	result, err := clients.MapArchitectures(ctx, arch)
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("unknown architecture of image %s: %w", imageID, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
+)
+
+// names of Azure clouds as listed by "az cloud list"
+const (
+	publicCloud     = "AzureCloud"
+	governmentCloud = "AzureUSGovernment"
+	chinaCloud      = "AzureChinaCloud"
+)
+
+// cloudSettings is the environment (authority host, ARM endpoint) and the service account of an
+// Azure cloud.
+type cloudSettings struct {
+	configuration cloud.Configuration
+	tenantID      string
+	clientID      string
+	clientSecret  string
+}
+
+// cloudSettingsFor returns settings of the named cloud, blank name is the public cloud. Error
+// wrapping UnknownAzureCloudErr or AzureCloudNotConfiguredErr is returned for unknown clouds
+// and clouds without a service account.
+func cloudSettingsFor(name string) (*cloudSettings, error) {
+	var settings *cloudSettings
+	switch name {
+	case "", publicCloud:
+		return &cloudSettings{
+			configuration: cloud.AzurePublic,
+			tenantID:      config.Azure.TenantID,
+			clientID:      secrets.AzureClientID.Value(),
+			clientSecret:  secrets.AzureClientSecret.Value(),
+		}, nil
+	case governmentCloud:
+		settings = &cloudSettings{
+			configuration: cloud.AzureGovernment,
+			tenantID:      config.Azure.Government.TenantID,
+			clientID:      secrets.AzureGovClientID.Value(),
+			clientSecret:  secrets.AzureGovClientSecret.Value(),
+		}
+	case chinaCloud:
+		settings = &cloudSettings{
+			configuration: cloud.AzureChina,
+			tenantID:      config.Azure.China.TenantID,
+			clientID:      secrets.AzureCNClientID.Value(),
+			clientSecret:  secrets.AzureCNClientSecret.Value(),
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", clients.UnknownAzureCloudErr, name)
+	}
+
+	if settings.tenantID == "" || settings.clientID == "" || settings.clientSecret == "" {
+		return nil, fmt.Errorf("%w: %s", clients.AzureCloudNotConfiguredErr, name)
+	}
+	return settings, nil
+}
+
+// managementEndpoint returns the Azure Resource Manager endpoint without a trailing slash.
+func (s *cloudSettings) managementEndpoint() string {
+	return strings.TrimSuffix(s.configuration.Services[cloud.ResourceManager].Endpoint, "/")
+}
+
+// managementScope returns the scope of Azure Resource Manager tokens.
+func (s *cloudSettings) managementScope() string {
+	return strings.TrimSuffix(s.configuration.Services[cloud.ResourceManager].Audience, "/") + "/.default"
+}
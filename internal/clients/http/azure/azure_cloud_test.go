@@ -0,0 +1,52 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudSettingsFor(t *testing.T) {
+	government := config.Azure.Government
+	china := config.Azure.China
+	defer func() {
+		config.Azure.Government = government
+		config.Azure.China = china
+	}()
+	config.Azure.Government.TenantID = "gov-tenant"
+	config.Azure.Government.ClientID = "gov-client"
+	config.Azure.Government.ClientSecret = "gov-secret"
+	config.Azure.China.TenantID = ""
+
+	t.Run("public by default", func(t *testing.T) {
+		settings, err := cloudSettingsFor("")
+		require.NoError(t, err)
+		assert.Equal(t, cloud.AzurePublic.ActiveDirectoryAuthorityHost, settings.configuration.ActiveDirectoryAuthorityHost)
+		assert.Equal(t, "https://management.azure.com", settings.managementEndpoint())
+		assert.Equal(t, "https://management.core.windows.net/.default", settings.managementScope())
+	})
+
+	t.Run("government", func(t *testing.T) {
+		settings, err := cloudSettingsFor("AzureUSGovernment")
+		require.NoError(t, err)
+		assert.Equal(t, "gov-tenant", settings.tenantID)
+		assert.Equal(t, "gov-client", settings.clientID)
+		assert.Equal(t, "https://management.usgovcloudapi.net", settings.managementEndpoint())
+		assert.Equal(t, "https://management.core.usgovcloudapi.net/.default", settings.managementScope())
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		_, err := cloudSettingsFor("AzureChinaCloud")
+		assert.True(t, errors.Is(err, clients.AzureCloudNotConfiguredErr))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := cloudSettingsFor("AzureGermanCloud")
+		assert.True(t, errors.Is(err, clients.UnknownAzureCloudErr))
+	})
+}
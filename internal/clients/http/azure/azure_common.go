@@ -2,7 +2,13 @@ package azure
 
 import (
 	"context"
+	stdhttp "net/http"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/rs/zerolog"
 )
@@ -12,3 +18,15 @@ const TraceName = telemetry.TracePrefix + "internal/clients/http/azure"
 func logger(ctx context.Context) zerolog.Logger {
 	return zerolog.Ctx(ctx).With().Str("client", "azure").Logger()
 }
+
+// coreOptions returns options of Azure SDK clients and credentials of the cloud with transport
+// observing request metrics and trusting AZURE_CA_BUNDLE certificates.
+func coreOptions(ctx context.Context, cloudConfig cloud.Configuration) azcore.ClientOptions {
+	transport := http.NewMetricsTransport(http.TransportFor(ctx, "", config.Azure.CABundle))
+	return azcore.ClientOptions{Cloud: cloudConfig, Transport: &stdhttp.Client{Transport: transport}}
+}
+
+// clientOptions returns options of Azure resource manager clients of the cloud.
+func clientOptions(ctx context.Context, cloudConfig cloud.Configuration) *arm.ClientOptions {
+	return &arm.ClientOptions{ClientOptions: coreOptions(ctx, cloudConfig)}
+}
@@ -6,10 +6,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 )
 
 type serviceClient struct {
@@ -21,8 +23,8 @@ func init() {
 }
 
 func newServiceClient(ctx context.Context) (clients.ServiceAzure, error) {
-	opts := azidentity.ClientSecretCredentialOptions{}
-	identityClient, err := azidentity.NewClientSecretCredential(config.Azure.TenantID, config.Azure.ClientID, config.Azure.ClientSecret, &opts)
+	opts := azidentity.ClientSecretCredentialOptions{ClientOptions: coreOptions(ctx, cloud.AzurePublic)}
+	identityClient, err := azidentity.NewClientSecretCredential(config.Azure.TenantID, secrets.AzureClientID.Value(), secrets.AzureClientSecret.Value(), &opts)
 	if err != nil {
 		return nil, fmt.Errorf("unable to init Azure credentials: %w", err)
 	}
@@ -35,7 +37,7 @@ func newServiceClient(ctx context.Context) (clients.ServiceAzure, error) {
 func (c *serviceClient) RegisterInstanceTypes(ctx context.Context, instanceTypes *clients.RegisteredInstanceTypes, regionalTypes *clients.RegionalTypeAvailability) error {
 	restricted := make(map[armcompute.ResourceSKURestrictionsReasonCode]int, 0)
 
-	skuClient, err := armcompute.NewResourceSKUsClient(config.Azure.SubscriptionID, c.credential, nil)
+	skuClient, err := armcompute.NewResourceSKUsClient(config.Azure.SubscriptionID, c.credential, clientOptions(ctx, cloud.AzurePublic))
 	if err != nil {
 		return fmt.Errorf("unable to generate types: %w", err)
 	}
@@ -131,6 +133,12 @@ func (c *serviceClient) typeFromSKU(ctx context.Context, v *armcompute.ResourceS
 				return clients.InstanceType{}, fmt.Errorf("unable to generate types: %w", memErr)
 			}
 			instanceType.MemoryMiB = int64(memoryGB * 1000)
+		case "GPUs":
+			gpus, gpuErr := strconv.ParseInt(*c.Value, 10, 32)
+			if gpuErr != nil {
+				return clients.InstanceType{}, fmt.Errorf("unable to generate types: %w", gpuErr)
+			}
+			instanceType.GPUs = int32(gpus)
 		case "HyperVGenerations":
 			instanceType.AzureDetail.GenV1 = strings.Contains(*c.Value, "V1")
 			instanceType.AzureDetail.GenV2 = strings.Contains(*c.Value, "V2")
@@ -0,0 +1,82 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdhttp "net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+)
+
+// maxSerialLogSize limits the serial log read from the boot diagnostics blob
+const maxSerialLogSize = 1024 * 1024
+
+// GetConsoleOutput downloads the serial log of boot diagnostics via a short-lived SAS URI.
+func (c *client) GetConsoleOutput(ctx context.Context, id string) (*clients.ConsoleOutput, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "GetConsoleOutput", "")
+	defer span.End()
+
+	vmID, err := arm.ParseResourceID(id)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to parse VM ID %s: %w", id, err)
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+	data, err := vmClient.RetrieveBootDiagnosticsData(ctx, vmID.ResourceGroupName, vmID.Name, &armcompute.VirtualMachinesClientRetrieveBootDiagnosticsDataOptions{
+		SasURIExpirationTimeInMinutes: to.Ptr[int32](5),
+	})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot retrieve boot diagnostics of VM %s: %w", vmID.Name, err)
+	}
+	if data.SerialConsoleLogBlobURI == nil {
+		span.Fail(http.BootDiagnosticsDisabledErr)
+		return nil, fmt.Errorf("cannot retrieve serial log of VM %s: %w", vmID.Name, http.BootDiagnosticsDisabledErr)
+	}
+
+	req, err := stdhttp.NewRequestWithContext(ctx, stdhttp.MethodGet, *data.SerialConsoleLogBlobURI, nil)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot create serial log request: %w", err)
+	}
+	httpClient := &stdhttp.Client{Transport: http.NewMetricsTransport(http.TransportFor(ctx, "", config.Azure.CABundle))}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot download serial log of VM %s: %w", vmID.Name, err)
+	}
+	defer resp.Body.Close()
+
+	// the blob does not exist until the VM writes to the serial console
+	if resp.StatusCode == stdhttp.StatusNotFound {
+		return &clients.ConsoleOutput{}, nil
+	}
+	if resp.StatusCode != stdhttp.StatusOK {
+		err = fmt.Errorf("%w: %d", clients.Non2xxResponseErr, resp.StatusCode)
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot download serial log of VM %s: %w", vmID.Name, err)
+	}
+
+	output, err := io.ReadAll(io.LimitReader(resp.Body, maxSerialLogSize))
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot read serial log of VM %s: %w", vmID.Name, err)
+	}
+	result := &clients.ConsoleOutput{Output: string(output)}
+	if modified, err := stdhttp.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		result.Timestamp = &modified
+	}
+	return result, nil
+}
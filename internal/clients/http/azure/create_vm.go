@@ -18,8 +18,9 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
-	"go.opentelemetry.io/otel"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"go.opentelemetry.io/otel/codes"
 )
 
@@ -27,14 +28,14 @@ const (
 	vnetName              = "redhat-vnet"
 	subnetName            = "redhat-subnet"
 	nsgName               = "redhat-nsg"
-	adminUsername         = "azureuser"
+	adminUsername         = models.AzureUser
 	vpnIPAddress          = "172.22.0.0/16"
 	resourcePollFrequency = 5 * time.Second
 	vmPollFrequency       = 10 * time.Second
 )
 
 func (c *client) BeginCreateVM(ctx context.Context, networkInterface *armnetwork.Interface, vmParams clients.AzureInstanceParams, vmName string) (string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "BeginCreateVM")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "BeginCreateVM", vmParams.Location)
 	defer span.End()
 
 	logger := logger(ctx)
@@ -42,10 +43,15 @@ func (c *client) BeginCreateVM(ctx context.Context, networkInterface *armnetwork
 
 	vmClient, err := c.newVirtualMachinesClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return "", err
 	}
 
 	vmAzureParams := c.prepareVirtualMachineParameters(vmParams.Location, armcompute.VirtualMachineSizeTypes(vmParams.InstanceType), networkInterface, vmParams.ImageID, vmParams.Pubkey.Body, vmParams.UserData, vmName)
+	vmAzureParams.Tags = azureTags(vmParams.Tags)
+	if vmParams.Priority == models.AzurePrioritySpot {
+		applySpotParameters(vmAzureParams, vmParams)
+	}
 
 	poller, err := vmClient.BeginCreateOrUpdate(ctx, vmParams.ResourceGroupName, vmName, *vmAzureParams, nil)
 	if err != nil {
@@ -65,7 +71,7 @@ func (c *client) BeginCreateVM(ctx context.Context, networkInterface *armnetwork
 }
 
 func (c *client) WaitForVM(ctx context.Context, resumeToken string) (clients.AzureInstanceID, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "WaitForVM")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "WaitForVM", "")
 	defer span.End()
 
 	logger := logger(ctx)
@@ -73,6 +79,7 @@ func (c *client) WaitForVM(ctx context.Context, resumeToken string) (clients.Azu
 
 	vmClient, err := c.newVirtualMachinesClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return "", err
 	}
 
@@ -97,7 +104,7 @@ func (c *client) WaitForVM(ctx context.Context, resumeToken string) (clients.Azu
 }
 
 func (c *client) ensureSharedNetworking(ctx context.Context, location, resourceGroupName string) (*armnetwork.Subnet, *armnetwork.SecurityGroup, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ensureSharedNetworking")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "ensureSharedNetworking", location)
 	defer span.End()
 
 	logger := logger(ctx)
@@ -130,13 +137,13 @@ func (c *client) ensureSharedNetworking(ctx context.Context, location, resourceG
 }
 
 func (c *client) prepareVMNetworking(ctx context.Context, subnet *armnetwork.Subnet, securityGroup *armnetwork.SecurityGroup, vmParams clients.AzureInstanceParams, vmName string) (*armnetwork.Interface, *armnetwork.PublicIPAddress, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "prepareVMNetworking")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "prepareVMNetworking", vmParams.Location)
 	defer span.End()
 
 	logger := logger(ctx)
 
 	publicIPName := vmName + "_ip"
-	publicIP, err := c.createPublicIP(ctx, vmParams.Location, vmParams.ResourceGroupName, publicIPName)
+	publicIP, err := c.createPublicIP(ctx, vmParams.Location, vmParams.ResourceGroupName, publicIPName, vmParams.Tags)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot create public IP address")
 		logger.Error().Err(err).Msg("cannot create public IP address")
@@ -144,7 +151,7 @@ func (c *client) prepareVMNetworking(ctx context.Context, subnet *armnetwork.Sub
 	}
 	logger.Trace().Msgf("Using public IP address id=%s", *publicIP.ID)
 	nicName := vmName + "_nic"
-	networkInterface, err := c.createNetworkInterface(ctx, vmParams.Location, vmParams.ResourceGroupName, subnet, publicIP, securityGroup, nicName)
+	networkInterface, err := c.createNetworkInterface(ctx, vmParams.Location, vmParams.ResourceGroupName, subnet, publicIP, securityGroup, nicName, vmParams.Tags)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot create network interface")
 		logger.Error().Err(err).Msg("cannot create network interface")
@@ -154,9 +161,13 @@ func (c *client) prepareVMNetworking(ctx context.Context, subnet *armnetwork.Sub
 	return networkInterface, publicIP, nil
 }
 
-func (c *client) EnsureResourceGroup(ctx context.Context, name string, location string) (*string, error) {
+func (c *client) EnsureResourceGroup(ctx context.Context, name string, location string, tags map[string]string) (*string, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "EnsureResourceGroup", location)
+	defer span.End()
+
 	resourceGroupClient, err := c.newResourceGroupsClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 
@@ -171,30 +182,59 @@ func (c *client) EnsureResourceGroup(ctx context.Context, name string, location
 			logger.Debug().Msgf("resource group %s not found, creating", name)
 			// 404 is expected, continue
 		} else {
+			span.Fail(err)
 			return nil, fmt.Errorf("failed to fetch resource group: %w", err)
 		}
 	}
 
 	parameters := armresources.ResourceGroup{
 		Location: ptr.To(location),
-		// TODO tag the resource group by some RH identifier
-		// Tags:     map[string]*string{"sample-rs-tag": ptr.To("sample-tag")}, // resource group update tags
+		Tags:     azureTags(tags),
 	}
 
 	resp, err := resourceGroupClient.CreateOrUpdate(ctx, name, parameters, nil)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("cannot create resource group: %w", err)
 	}
 
 	return resp.ResourceGroup.ID, nil
 }
 
+func (c *client) DeleteResourceGroup(ctx context.Context, name string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "DeleteResourceGroup", "")
+	defer span.End()
+
+	resourceGroupClient, err := c.newResourceGroupsClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return err
+	}
+
+	pollerResponse, err := resourceGroupClient.BeginDelete(ctx, name, nil)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("delete of resource group failed to start: %w", err)
+	}
+
+	_, err = pollerResponse.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: resourcePollFrequency,
+	})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("failed to poll for delete resource group result: %w", err)
+	}
+
+	return nil
+}
+
 func (c *client) createVirtualNetwork(ctx context.Context, location string, resourceGroupName string, name string) (*armnetwork.VirtualNetwork, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "createVirtualNetwork")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "createVirtualNetwork", location)
 	defer span.End()
 
 	vnetClient, err := c.newVirtualNetworksClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 
@@ -234,6 +274,7 @@ func (c *client) createVirtualNetwork(ctx context.Context, location string, reso
 
 	pollerResponse, err := vnetClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, parameters, nil)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("create of virtual network failed to start: %w", err)
 	}
 
@@ -241,6 +282,7 @@ func (c *client) createVirtualNetwork(ctx context.Context, location string, reso
 		Frequency: resourcePollFrequency,
 	})
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to poll for create virtual network result: %w", err)
 	}
 
@@ -248,11 +290,12 @@ func (c *client) createVirtualNetwork(ctx context.Context, location string, reso
 }
 
 func (c *client) createSubnets(ctx context.Context, resourceGroupName string, vnetName string, name string) (*armnetwork.Subnet, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "createSubnets")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "createSubnets", "")
 	defer span.End()
 
 	subnetClient, err := c.newSubnetsClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 
@@ -265,6 +308,7 @@ func (c *client) createSubnets(ctx context.Context, resourceGroupName string, vn
 
 	pollerResponse, err := subnetClient.BeginCreateOrUpdate(ctx, resourceGroupName, vnetName, name, parameters, nil)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("create of subnet failed to start: %w", err)
 	}
 
@@ -272,6 +316,7 @@ func (c *client) createSubnets(ctx context.Context, resourceGroupName string, vn
 		Frequency: resourcePollFrequency,
 	})
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to poll for create subnet result: %w", err)
 	}
 
@@ -279,11 +324,12 @@ func (c *client) createSubnets(ctx context.Context, resourceGroupName string, vn
 }
 
 func (c *client) createNetworkSecurityGroup(ctx context.Context, location string, resourceGroupName string, name string) (*armnetwork.SecurityGroup, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "createNetworkSecurityGroup")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "createNetworkSecurityGroup", location)
 	defer span.End()
 
 	nsgClient, err := c.newSecurityGroupsClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 
@@ -327,6 +373,7 @@ func (c *client) createNetworkSecurityGroup(ctx context.Context, location string
 
 	pollerResponse, err := nsgClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, parameters, nil)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("create of network security group failed to start: %w", err)
 	}
 
@@ -334,22 +381,25 @@ func (c *client) createNetworkSecurityGroup(ctx context.Context, location string
 		Frequency: resourcePollFrequency,
 	})
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to poll for create network security group result: %w", err)
 	}
 	return &resp.SecurityGroup, nil
 }
 
-func (c *client) createPublicIP(ctx context.Context, location string, resourceGroupName string, name string) (*armnetwork.PublicIPAddress, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "createPublicIP")
+func (c *client) createPublicIP(ctx context.Context, location string, resourceGroupName string, name string, tags map[string]string) (*armnetwork.PublicIPAddress, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "createPublicIP", location)
 	defer span.End()
 
 	publicIPAddressClient, err := c.newPublicIPAddressesClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 
 	parameters := armnetwork.PublicIPAddress{
 		Location: to.Ptr(location),
+		Tags:     azureTags(tags),
 		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
 			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic), // Static or Dynamic
 		},
@@ -357,6 +407,7 @@ func (c *client) createPublicIP(ctx context.Context, location string, resourceGr
 
 	pollerResponse, err := publicIPAddressClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, parameters, nil)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("create of public IP address failed to start: %w", err)
 	}
 
@@ -364,22 +415,25 @@ func (c *client) createPublicIP(ctx context.Context, location string, resourceGr
 		Frequency: resourcePollFrequency,
 	})
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to poll for create public IP address result: %w", err)
 	}
 	return &resp.PublicIPAddress, nil
 }
 
-func (c *client) createNetworkInterface(ctx context.Context, location string, resourceGroupName string, subnet *armnetwork.Subnet, publicIP *armnetwork.PublicIPAddress, nsg *armnetwork.SecurityGroup, name string) (*armnetwork.Interface, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "createNetworkInterface")
+func (c *client) createNetworkInterface(ctx context.Context, location string, resourceGroupName string, subnet *armnetwork.Subnet, publicIP *armnetwork.PublicIPAddress, nsg *armnetwork.SecurityGroup, name string, tags map[string]string) (*armnetwork.Interface, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "createNetworkInterface", location)
 	defer span.End()
 
 	nicClient, err := c.newInterfacesClient(ctx)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 
 	parameters := armnetwork.Interface{
 		Location: to.Ptr(location),
+		Tags:     azureTags(tags),
 		Properties: &armnetwork.InterfacePropertiesFormat{
 			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
 				{
@@ -403,6 +457,7 @@ func (c *client) createNetworkInterface(ctx context.Context, location string, re
 
 	pollerResponse, err := nicClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, parameters, nil)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("create of network interface failed to start: %w", err)
 	}
 
@@ -410,6 +465,7 @@ func (c *client) createNetworkInterface(ctx context.Context, location string, re
 		Frequency: resourcePollFrequency,
 	})
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to poll for create network interface result: %w", err)
 	}
 
@@ -466,7 +522,41 @@ func (c *client) prepareVirtualMachineParameters(location string, instanceType a
 					},
 				},
 			},
+			// boot diagnostics in managed storage provide the serial log of the VM
+			DiagnosticsProfile: &armcompute.DiagnosticsProfile{
+				BootDiagnostics: &armcompute.BootDiagnostics{
+					Enabled: to.Ptr(true),
+				},
+			},
 			UserData: to.Ptr(string(userDataEncoded)),
 		},
 	}
 }
+
+// azureTags returns tags in the form of the Azure SDK, nil when there are no tags.
+func azureTags(tags map[string]string) map[string]*string {
+	if len(tags) == 0 {
+		return nil
+	}
+	result := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		result[key] = ptr.To(value)
+	}
+	return result
+}
+
+// applySpotParameters sets spot priority, eviction policy and maximum price of the VM.
+func applySpotParameters(vm *armcompute.VirtualMachine, vmParams clients.AzureInstanceParams) {
+	maxPrice := float64(-1)
+	if vmParams.MaxPrice != nil {
+		maxPrice = *vmParams.MaxPrice
+	}
+	evictionPolicy := armcompute.VirtualMachineEvictionPolicyTypesDeallocate
+	if vmParams.EvictionPolicy == models.AzureEvictionDelete {
+		evictionPolicy = armcompute.VirtualMachineEvictionPolicyTypesDelete
+	}
+
+	vm.Properties.Priority = to.Ptr(armcompute.VirtualMachinePriorityTypesSpot)
+	vm.Properties.EvictionPolicy = to.Ptr(evictionPolicy)
+	vm.Properties.BillingProfile = &armcompute.BillingProfile{MaxPrice: to.Ptr(maxPrice)}
+}
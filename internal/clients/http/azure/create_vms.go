@@ -5,13 +5,13 @@ import (
 	"fmt"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/google/uuid"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 )
 
 func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstanceParams, amount int64, vmNamePrefix string) ([]clients.InstanceDescription, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "CreateVMs")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "CreateVMs", vmParams.Location)
 	defer span.End()
 
 	logger := logger(ctx)
@@ -19,6 +19,7 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 
 	subnet, nsg, err := c.ensureSharedNetworking(ctx, vmParams.Location, vmParams.ResourceGroupName)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 
@@ -28,12 +29,14 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 	for i = 0; i < amount; i++ {
 		uuid, err := uuid.NewUUID()
 		if err != nil {
+			span.Fail(err)
 			return vmDescriptions, fmt.Errorf("could not generate a new UUID: %w", err)
 		}
 		vmName := fmt.Sprintf("%s-%s", vmNamePrefix, uuid.String())
 
 		networkInterface, publicIP, err := c.prepareVMNetworking(ctx, subnet, nsg, vmParams, vmName)
 		if err != nil {
+			span.Fail(err)
 			return nil, err
 		}
 
@@ -0,0 +1,111 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+)
+
+const powerStatePrefix = "PowerState/"
+
+// GetInstanceDescriptionByID returns the power state and addresses of a VM with the full
+// resource ID, addresses are read from the primary IP configuration of the first interface.
+func (c *client) GetInstanceDescriptionByID(ctx context.Context, id string) (*clients.InstanceDescription, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "GetInstanceDescriptionByID", "")
+	defer span.End()
+
+	vmID, err := arm.ParseResourceID(id)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to parse VM ID %s: %w", id, err)
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+	vm, err := vmClient.Get(ctx, vmID.ResourceGroupName, vmID.Name, &armcompute.VirtualMachinesClientGetOptions{
+		Expand: to.Ptr(armcompute.InstanceViewTypesInstanceView),
+	})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot get VM %s: %w", vmID.Name, err)
+	}
+
+	description := &clients.InstanceDescription{ID: id}
+	if vm.Properties == nil {
+		return description, nil
+	}
+	description.LaunchTime = vm.Properties.TimeCreated
+	if vm.Properties.InstanceView != nil {
+		for _, status := range vm.Properties.InstanceView.Statuses {
+			code := ptr.FromOrEmpty(status.Code)
+			if strings.HasPrefix(code, powerStatePrefix) {
+				description.State = strings.TrimPrefix(code, powerStatePrefix)
+			}
+		}
+	}
+
+	if vm.Properties.NetworkProfile == nil || len(vm.Properties.NetworkProfile.NetworkInterfaces) == 0 {
+		return description, nil
+	}
+	err = c.describeInterface(ctx, ptr.FromOrEmpty(vm.Properties.NetworkProfile.NetworkInterfaces[0].ID), description)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+	return description, nil
+}
+
+func (c *client) describeInterface(ctx context.Context, nicID string, description *clients.InstanceDescription) error {
+	id, err := arm.ParseResourceID(nicID)
+	if err != nil {
+		return fmt.Errorf("unable to parse network interface ID %s: %w", nicID, err)
+	}
+
+	nicClient, err := c.newInterfacesClient(ctx)
+	if err != nil {
+		return err
+	}
+	nic, err := nicClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get network interface %s: %w", id.Name, err)
+	}
+	if nic.Properties == nil || len(nic.Properties.IPConfigurations) == 0 || nic.Properties.IPConfigurations[0].Properties == nil {
+		return nil
+	}
+
+	ipConfig := nic.Properties.IPConfigurations[0].Properties
+	description.PrivateIPv4 = ptr.FromOrEmpty(ipConfig.PrivateIPAddress)
+	if ipConfig.PublicIPAddress == nil || ipConfig.PublicIPAddress.ID == nil {
+		return nil
+	}
+
+	ipID, err := arm.ParseResourceID(*ipConfig.PublicIPAddress.ID)
+	if err != nil {
+		return fmt.Errorf("unable to parse public IP ID %s: %w", *ipConfig.PublicIPAddress.ID, err)
+	}
+	ipClient, err := c.newPublicIPAddressesClient(ctx)
+	if err != nil {
+		return err
+	}
+	ip, err := ipClient.Get(ctx, ipID.ResourceGroupName, ipID.Name, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get public IP %s: %w", ipID.Name, err)
+	}
+	if ip.Properties != nil {
+		description.PublicIPv4 = ptr.FromOrEmpty(ip.Properties.IPAddress)
+		if ip.Properties.DNSSettings != nil {
+			description.PublicDNS = ptr.FromOrEmpty(ip.Properties.DNSSettings.Fqdn)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdhttp "net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+)
+
+// requiredActions are subscription actions needed to launch VMs and read their state.
+var requiredActions = []string{
+	"Microsoft.Compute/images/read",
+	"Microsoft.Compute/locations/usages/read",
+	"Microsoft.Compute/skus/read",
+	"Microsoft.Compute/sshPublicKeys/write",
+	"Microsoft.Compute/virtualMachines/deallocate/action",
+	"Microsoft.Compute/virtualMachines/read",
+	"Microsoft.Compute/virtualMachines/restart/action",
+	"Microsoft.Compute/virtualMachines/retrieveBootDiagnosticsData/action",
+	"Microsoft.Compute/virtualMachines/start/action",
+	"Microsoft.Compute/virtualMachines/write",
+	"Microsoft.Network/networkInterfaces/join/action",
+	"Microsoft.Network/networkInterfaces/read",
+	"Microsoft.Network/networkInterfaces/write",
+	"Microsoft.Network/networkSecurityGroups/join/action",
+	"Microsoft.Network/networkSecurityGroups/write",
+	"Microsoft.Network/publicIPAddresses/join/action",
+	"Microsoft.Network/publicIPAddresses/read",
+	"Microsoft.Network/publicIPAddresses/write",
+	"Microsoft.Network/virtualNetworks/subnets/join/action",
+	"Microsoft.Network/virtualNetworks/write",
+	"Microsoft.Resources/subscriptions/resourceGroups/read",
+	"Microsoft.Resources/subscriptions/resourceGroups/write",
+}
+
+// permissions API is not part of the compute and network SDK modules
+const permissionsURL = "%s/subscriptions/%s/providers/Microsoft.Authorization/permissions?api-version=2022-04-01"
+
+type permissionList struct {
+	Value []struct {
+		Actions    []string `json:"actions"`
+		NotActions []string `json:"notActions"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+func (c *client) ValidatePermissions(ctx context.Context) ([]clients.PermissionCheck, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "ValidatePermissions", "")
+	defer span.End()
+
+	token, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{c.cloud.managementScope()}})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to acquire token: %w", http.MapAzureTokenError(err))
+	}
+
+	doer := http.NewPlatformClient(ctx, "", config.Azure.CABundle)
+	checks := make([]clients.PermissionCheck, len(requiredActions))
+	for i, action := range requiredActions {
+		checks[i] = clients.PermissionCheck{Permission: action}
+	}
+	next := fmt.Sprintf(permissionsURL, c.cloud.managementEndpoint(), c.subscriptionID)
+	for next != "" {
+		list, listErr := c.listPermissions(ctx, doer, next, token.Token)
+		if listErr != nil {
+			span.Fail(listErr)
+			return nil, listErr
+		}
+		// not actions only exclude actions of the same role, an action is allowed when any role allows it
+		for _, p := range list.Value {
+			allowed, denied := actionPatterns(p.Actions), actionPatterns(p.NotActions)
+			for i := range checks {
+				if matchesAny(allowed, checks[i].Permission) && !matchesAny(denied, checks[i].Permission) {
+					checks[i].Allowed = true
+				}
+			}
+		}
+		next = list.NextLink
+	}
+	return checks, nil
+}
+
+func (c *client) listPermissions(ctx context.Context, doer http.HttpRequestDoer, url, token string) (*permissionList, error) {
+	req, err := stdhttp.NewRequestWithContext(ctx, stdhttp.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create permissions request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list permissions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err = http.HandleHTTPResponses(ctx, "azure", resp); err != nil {
+		return nil, fmt.Errorf("unable to list permissions: %w", err)
+	}
+
+	list := &permissionList{}
+	if err = json.NewDecoder(resp.Body).Decode(list); err != nil {
+		return nil, fmt.Errorf("unable to decode permissions: %w", err)
+	}
+	return list, nil
+}
+
+// actionPatterns turns actions with "*" wildcards into case-insensitive regular expressions.
+func actionPatterns(actions []string) []*regexp.Regexp {
+	result := make([]*regexp.Regexp, len(actions))
+	for i, action := range actions {
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(action), `\*`, ".*")
+		result[i] = regexp.MustCompile("(?i)^" + pattern + "$")
+	}
+	return result
+}
+
+func matchesAny(patterns []*regexp.Regexp, action string) bool {
+	for _, p := range patterns {
+		if p.MatchString(action) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+// templateAPIVersions are API versions of resources of the dry run deployment template.
+var templateAPIVersions = map[string]string{
+	"Microsoft.Compute/virtualMachines":       "2022-08-01",
+	"Microsoft.Network/networkInterfaces":     "2022-07-01",
+	"Microsoft.Network/networkSecurityGroups": "2022-07-01",
+	"Microsoft.Network/publicIPAddresses":     "2022-07-01",
+	"Microsoft.Network/virtualNetworks":       "2022-07-01",
+	"Microsoft.Resources/deployments":         "2021-04-01",
+	"Microsoft.Resources/resourceGroups":      "2021-04-01",
+}
+
+// missingActionRe matches the action of authorization failure messages
+var missingActionRe = regexp.MustCompile(`perform action '([^']+)'`)
+
+// ValidateVMs validates a deployment template with the shared networking and the VMs with their
+// networking as CreateVMs would create them. The template is only validated, it is never
+// deployed. When the resource group does not exist yet, the deployment is validated in the
+// subscription together with the resource group.
+func (c *client) ValidateVMs(ctx context.Context, vmParams clients.AzureInstanceParams, amount int64, vmNamePrefix string) (*clients.DryRunResult, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "ValidateVMs", vmParams.Location)
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Validating deployment of %d Azure VM instances", amount)
+
+	deploymentsClient, err := armresources.NewDeploymentsClient(c.subscriptionID, c.credential, clientOptions(ctx, c.cloud.configuration))
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to create deployments Azure client: %w", err)
+	}
+	resourceGroupClient, err := c.newResourceGroupsClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+
+	template, err := c.vmDeploymentTemplate(vmParams, amount, vmNamePrefix)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+
+	deploymentName := "provisioning-dry-run-" + uuid.NewString()
+	_, err = resourceGroupClient.Get(ctx, vmParams.ResourceGroupName, nil)
+	var azErr *azcore.ResponseError
+	if err != nil && (!errors.As(err, &azErr) || azErr.StatusCode != http.StatusNotFound) {
+		return dryRunVerdict(err)
+	}
+
+	var validation armresources.DeploymentValidateResult
+	if err == nil {
+		deployment := armresources.Deployment{
+			Properties: &armresources.DeploymentProperties{
+				Mode:     to.Ptr(armresources.DeploymentModeIncremental),
+				Template: template,
+			},
+		}
+		poller, validateErr := deploymentsClient.BeginValidate(ctx, vmParams.ResourceGroupName, deploymentName, deployment, nil)
+		if validateErr != nil {
+			return dryRunVerdict(validateErr)
+		}
+		resp, validateErr := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: resourcePollFrequency})
+		if validateErr != nil {
+			return dryRunVerdict(validateErr)
+		}
+		validation = resp.DeploymentValidateResult
+	} else {
+		logger.Debug().Msgf("Resource group %s not found, validating in the subscription", vmParams.ResourceGroupName)
+		deployment := armresources.Deployment{
+			Location: ptr.To(vmParams.Location),
+			Properties: &armresources.DeploymentProperties{
+				Mode:     to.Ptr(armresources.DeploymentModeIncremental),
+				Template: subscriptionDeploymentTemplate(vmParams.ResourceGroupName, vmParams.Location, template),
+			},
+		}
+		poller, validateErr := deploymentsClient.BeginValidateAtSubscriptionScope(ctx, deploymentName, deployment, nil)
+		if validateErr != nil {
+			return dryRunVerdict(validateErr)
+		}
+		resp, validateErr := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: resourcePollFrequency})
+		if validateErr != nil {
+			return dryRunVerdict(validateErr)
+		}
+		validation = resp.DeploymentValidateResult
+	}
+
+	if validation.Error != nil {
+		return errorVerdict(validation.Error), nil
+	}
+	return &clients.DryRunResult{Passed: true}, nil
+}
+
+// dryRunVerdict returns the verdict of a failed Azure request, errors which are not Azure
+// responses are returned as errors.
+func dryRunVerdict(err error) (*clients.DryRunResult, error) {
+	var azErr *azcore.ResponseError
+	if !errors.As(err, &azErr) {
+		return nil, fmt.Errorf("cannot validate deployment: %w", err)
+	}
+
+	var body struct {
+		Error *armresources.ErrorResponse `json:"error"`
+	}
+	if azErr.RawResponse != nil && azErr.RawResponse.Body != nil {
+		data, _ := io.ReadAll(azErr.RawResponse.Body)
+		_ = json.Unmarshal(data, &body)
+	}
+	if body.Error == nil {
+		body.Error = &armresources.ErrorResponse{Code: ptr.To(azErr.ErrorCode)}
+	}
+	return errorVerdict(body.Error), nil
+}
+
+// errorVerdict returns failed verdict with the error, missing permissions are collected from
+// authorization failures of the error and its details.
+func errorVerdict(errResp *armresources.ErrorResponse) *clients.DryRunResult {
+	result := &clients.DryRunResult{
+		Code:    ptr.From(errResp.Code),
+		Message: ptr.From(errResp.Message),
+	}
+
+	var collect func(e *armresources.ErrorResponse)
+	collect = func(e *armresources.ErrorResponse) {
+		if match := missingActionRe.FindStringSubmatch(ptr.From(e.Message)); match != nil {
+			result.MissingPermissions = append(result.MissingPermissions, match[1])
+		}
+		for _, detail := range e.Details {
+			collect(detail)
+		}
+	}
+	collect(errResp)
+	return result
+}
+
+// vmDeploymentTemplate returns a resource group deployment template with the shared networking
+// and amount of VMs with their networking.
+func (c *client) vmDeploymentTemplate(vmParams clients.AzureInstanceParams, amount int64, vmNamePrefix string) (map[string]any, error) {
+	vnetType := "Microsoft.Network/virtualNetworks"
+	nsgType := "Microsoft.Network/networkSecurityGroups"
+	ipType := "Microsoft.Network/publicIPAddresses"
+	nicType := "Microsoft.Network/networkInterfaces"
+	vmType := "Microsoft.Compute/virtualMachines"
+
+	vnet := armnetwork.VirtualNetwork{
+		Location: to.Ptr(vmParams.Location),
+		Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+			AddressSpace: &armnetwork.AddressSpace{AddressPrefixes: []*string{to.Ptr(vpnIPAddress)}},
+			Subnets: []*armnetwork.Subnet{
+				{
+					Name:       to.Ptr(subnetName),
+					Properties: &armnetwork.SubnetPropertiesFormat{AddressPrefix: to.Ptr(vpnIPAddress)},
+				},
+			},
+		},
+	}
+	nsg := armnetwork.SecurityGroup{
+		Location:   to.Ptr(vmParams.Location),
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{},
+	}
+
+	resources := make([]map[string]any, 0, 2+3*amount)
+	for _, r := range []struct {
+		resourceType, name string
+		model              any
+	}{{vnetType, vnetName, vnet}, {nsgType, nsgName, nsg}} {
+		resource, err := templateResource(r.resourceType, r.name, r.model)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+
+	subnetID := fmt.Sprintf("[resourceId('%s/subnets', '%s', '%s')]", vnetType, vnetName, subnetName)
+	for i := int64(0); i < amount; i++ {
+		vmName := fmt.Sprintf("%s-%d", vmNamePrefix, i)
+		ipName := vmName + "_ip"
+		nicName := vmName + "_nic"
+
+		publicIP := armnetwork.PublicIPAddress{
+			Location: to.Ptr(vmParams.Location),
+			Tags:     azureTags(vmParams.Tags),
+			Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+				PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
+			},
+		}
+		nic := armnetwork.Interface{
+			Location: to.Ptr(vmParams.Location),
+			Tags:     azureTags(vmParams.Tags),
+			Properties: &armnetwork.InterfacePropertiesFormat{
+				IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+					{
+						Name: to.Ptr("ipConfig"),
+						Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+							PrivateIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodDynamic),
+							Subnet:                    &armnetwork.Subnet{ID: to.Ptr(subnetID)},
+							PublicIPAddress:           &armnetwork.PublicIPAddress{ID: to.Ptr(resourceIDExpression(ipType, ipName))},
+						},
+					},
+				},
+				NetworkSecurityGroup: &armnetwork.SecurityGroup{ID: to.Ptr(resourceIDExpression(nsgType, nsgName))},
+			},
+		}
+		nicRef := &armnetwork.Interface{ID: to.Ptr(resourceIDExpression(nicType, nicName))}
+		vm := c.prepareVirtualMachineParameters(vmParams.Location, armcompute.VirtualMachineSizeTypes(vmParams.InstanceType), nicRef, vmParams.ImageID, vmParams.Pubkey.Body, vmParams.UserData, vmName)
+		vm.Tags = azureTags(vmParams.Tags)
+		if vmParams.Priority == models.AzurePrioritySpot {
+			applySpotParameters(vm, vmParams)
+		}
+
+		ipResource, err := templateResource(ipType, ipName, publicIP)
+		if err != nil {
+			return nil, err
+		}
+		nicResource, err := templateResource(nicType, nicName, nic,
+			resourceIDExpression(vnetType, vnetName), resourceIDExpression(nsgType, nsgName), resourceIDExpression(ipType, ipName))
+		if err != nil {
+			return nil, err
+		}
+		vmResource, err := templateResource(vmType, vmName, vm, resourceIDExpression(nicType, nicName))
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, ipResource, nicResource, vmResource)
+	}
+
+	return map[string]any{
+		"$schema":        "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources":      resources,
+	}, nil
+}
+
+// subscriptionDeploymentTemplate returns a subscription deployment template creating the resource
+// group and deploying the resource group template into it.
+func subscriptionDeploymentTemplate(resourceGroupName, location string, template map[string]any) map[string]any {
+	groupType := "Microsoft.Resources/resourceGroups"
+	deploymentType := "Microsoft.Resources/deployments"
+	return map[string]any{
+		"$schema":        "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": []map[string]any{
+			{
+				"type":       groupType,
+				"apiVersion": templateAPIVersions[groupType],
+				"name":       resourceGroupName,
+				"location":   location,
+			},
+			{
+				"type":          deploymentType,
+				"apiVersion":    templateAPIVersions[deploymentType],
+				"name":          "provisioning-dry-run-vms",
+				"resourceGroup": resourceGroupName,
+				"dependsOn":     []string{resourceIDExpression(groupType, resourceGroupName)},
+				"properties": map[string]any{
+					"mode":                        "Incremental",
+					"expressionEvaluationOptions": map[string]string{"scope": "inner"},
+					"template":                    template,
+				},
+			},
+		},
+	}
+}
+
+// templateResource returns a template resource with fields of an SDK model, the model must not
+// contain read-only fields.
+func templateResource(resourceType, name string, model any, dependsOn ...string) (map[string]any, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal %s template resource: %w", resourceType, err)
+	}
+	var resource map[string]any
+	if err = json.Unmarshal(data, &resource); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal %s template resource: %w", resourceType, err)
+	}
+
+	resource["type"] = resourceType
+	resource["apiVersion"] = templateAPIVersions[resourceType]
+	resource["name"] = name
+	if len(dependsOn) > 0 {
+		resource["dependsOn"] = dependsOn
+	}
+	return resource, nil
+}
+
+// resourceIDExpression returns a template expression of ID of a resource in the deployment.
+func resourceIDExpression(resourceType, name string) string {
+	return fmt.Sprintf("[resourceId('%s', '%s')]", resourceType, name)
+}
@@ -0,0 +1,53 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVMDeploymentTemplate(t *testing.T) {
+	c := &client{}
+	params := clients.AzureInstanceParams{
+		Location:     "eastus",
+		ImageID:      "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/images/image",
+		Pubkey:       &models.Pubkey{Body: "ssh-ed25519 AAAA"},
+		InstanceType: "Standard_B1s",
+		Tags:         map[string]string{"team": "qe"},
+	}
+
+	template, err := c.vmDeploymentTemplate(params, 2, "vm")
+	require.NoError(t, err)
+
+	resources, ok := template["resources"].([]map[string]any)
+	require.True(t, ok)
+	require.Len(t, resources, 8, "shared networking and IP, NIC and VM for each instance")
+
+	vm := resources[4]
+	assert.Equal(t, "Microsoft.Compute/virtualMachines", vm["type"])
+	assert.Equal(t, "vm-0", vm["name"])
+	assert.Equal(t, []string{"[resourceId('Microsoft.Network/networkInterfaces', 'vm-0_nic')]"}, vm["dependsOn"])
+	assert.Equal(t, map[string]any{"team": "qe"}, vm["tags"])
+}
+
+func TestErrorVerdict(t *testing.T) {
+	result := errorVerdict(&armresources.ErrorResponse{
+		Code:    ptr.To("InvalidTemplateDeployment"),
+		Message: ptr.To("The template deployment failed."),
+		Details: []*armresources.ErrorResponse{
+			{
+				Code:    ptr.To("AuthorizationFailed"),
+				Message: ptr.To("The client 'x' does not have authorization to perform action 'Microsoft.Compute/virtualMachines/write' over scope '/subscriptions/sub'."),
+			},
+		},
+	})
+
+	assert.False(t, result.Passed)
+	assert.Equal(t, "InvalidTemplateDeployment", result.Code)
+	assert.Equal(t, []string{"Microsoft.Compute/virtualMachines/write"}, result.MissingPermissions)
+}
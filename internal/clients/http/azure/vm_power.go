@@ -0,0 +1,95 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+)
+
+// StopVM deallocates the VM, powered off VMs which are not deallocated are still billed.
+func (c *client) StopVM(ctx context.Context, id string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "StopVM", "")
+	defer span.End()
+
+	vmID, err := arm.ParseResourceID(id)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to parse VM ID %s: %w", id, err)
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return err
+	}
+	poller, err := vmClient.BeginDeallocate(ctx, vmID.ResourceGroupName, vmID.Name, nil)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot deallocate VM %s: %w", vmID.Name, err)
+	}
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("failed to poll for deallocate VM %s result: %w", vmID.Name, err)
+	}
+	return nil
+}
+
+func (c *client) StartVM(ctx context.Context, id string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "StartVM", "")
+	defer span.End()
+
+	vmID, err := arm.ParseResourceID(id)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to parse VM ID %s: %w", id, err)
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return err
+	}
+	poller, err := vmClient.BeginStart(ctx, vmID.ResourceGroupName, vmID.Name, nil)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot start VM %s: %w", vmID.Name, err)
+	}
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("failed to poll for start VM %s result: %w", vmID.Name, err)
+	}
+	return nil
+}
+
+func (c *client) RestartVM(ctx context.Context, id string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "RestartVM", "")
+	defer span.End()
+
+	vmID, err := arm.ParseResourceID(id)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to parse VM ID %s: %w", id, err)
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return err
+	}
+	poller, err := vmClient.BeginRestart(ctx, vmID.ResourceGroupName, vmID.Name, nil)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot restart VM %s: %w", vmID.Name, err)
+	}
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("failed to poll for restart VM %s result: %w", vmID.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+)
+
+// usage names of total regional vCPUs and total regional spot vCPUs
+const (
+	regionalVCPUsQuota = "cores"
+	spotVCPUsQuota     = "lowPriorityCores"
+)
+
+// GetVMSizeQuota finds the VM size in compute SKUs of the location and reads usage of its
+// family and regional vCPU quotas. Error wrapping VMSizeNotAvailableErr is returned when
+// the size is not listed in the location.
+func (c *client) GetVMSizeQuota(ctx context.Context, location string, size clients.InstanceTypeName) (*clients.AzureVMSizeQuota, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "azure", "GetVMSizeQuota", location)
+	defer span.End()
+
+	skuClient, err := c.newResourceSKUsClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+
+	var quota *clients.AzureVMSizeQuota
+	skuPager := skuClient.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: ptr.To(fmt.Sprintf("location eq '%s'", location)),
+	})
+	for quota == nil && skuPager.More() {
+		page, pageErr := skuPager.NextPage(ctx)
+		if pageErr != nil {
+			span.Fail(pageErr)
+			return nil, fmt.Errorf("unable to list resource SKUs: %w", pageErr)
+		}
+		for _, sku := range page.Value {
+			if sku.ResourceType == nil || *sku.ResourceType != "virtualMachines" || sku.Name == nil || !strings.EqualFold(*sku.Name, string(size)) {
+				continue
+			}
+			quota, err = quotaFromSKU(sku)
+			if err != nil {
+				span.Fail(err)
+				return nil, err
+			}
+			break
+		}
+	}
+	if quota == nil {
+		span.Fail(http.VMSizeNotAvailableErr)
+		return nil, fmt.Errorf("cannot find VM size %s in location %s: %w", size, location, http.VMSizeNotAvailableErr)
+	}
+
+	usageClient, err := c.newUsageClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+
+	usagePager := usageClient.NewListPager(location, nil)
+	for usagePager.More() {
+		page, pageErr := usagePager.NextPage(ctx)
+		if pageErr != nil {
+			span.Fail(pageErr)
+			return nil, fmt.Errorf("unable to list compute usage: %w", pageErr)
+		}
+		for _, usage := range page.Value {
+			if usage.Name == nil || usage.Name.Value == nil {
+				continue
+			}
+			switch {
+			case strings.EqualFold(*usage.Name.Value, quota.Family):
+				quota.FamilyUsage = quotaUsage(usage)
+			case *usage.Name.Value == regionalVCPUsQuota:
+				quota.RegionalUsage = quotaUsage(usage)
+			case *usage.Name.Value == spotVCPUsQuota:
+				quota.SpotUsage = quotaUsage(usage)
+			}
+		}
+	}
+
+	return quota, nil
+}
+
+// quotaFromSKU reads family, vCPUs and location restriction of a VM size.
+func quotaFromSKU(sku *armcompute.ResourceSKU) (*clients.AzureVMSizeQuota, error) {
+	quota := &clients.AzureVMSizeQuota{}
+	if sku.Family != nil {
+		quota.Family = *sku.Family
+	}
+	for _, c := range sku.Capabilities {
+		if c.Name == nil || c.Value == nil || *c.Name != "vCPUs" {
+			continue
+		}
+		vcpus, err := strconv.ParseInt(*c.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse vCPUs of VM size %s: %w", *sku.Name, err)
+		}
+		quota.VCPUs = vcpus
+	}
+	// zonal restrictions still allow launching into other zones of the location
+	for _, r := range sku.Restrictions {
+		if r.Type != nil && *r.Type == armcompute.ResourceSKURestrictionsTypeLocation && r.ReasonCode != nil {
+			quota.Restriction = string(*r.ReasonCode)
+		}
+	}
+	return quota, nil
+}
+
+func quotaUsage(usage *armcompute.Usage) clients.AzureQuotaUsage {
+	result := clients.AzureQuotaUsage{}
+	if usage.Name.LocalizedValue != nil {
+		result.Name = *usage.Name.LocalizedValue
+	}
+	if usage.CurrentValue != nil {
+		result.Current = int64(*usage.CurrentValue)
+	}
+	if usage.Limit != nil {
+		result.Limit = *usage.Limit
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+var (
+	VMSizeNotAvailableErr         = errors.New("VM size is not available in the location")
+	AzureSecretExpiredErr         = errors.New("client secret of the Azure application has expired")
+	AzureCredentialsInvalidErr    = errors.New("credentials of the Azure application are not valid")
+	AzureRoleAssignmentMissingErr = errors.New("no role assigned to the Azure application in the subscription")
+	BootDiagnosticsDisabledErr    = errors.New("boot diagnostics are not enabled on the VM")
+)
+
+var aadErrorCode = regexp.MustCompile(`AADSTS\d+`)
+
+// AAD error codes of client credential token requests which are caused by the application
+// credentials of the service.
+var aadErrorCodes = map[string]error{
+	"AADSTS7000222": AzureSecretExpiredErr,
+	"AADSTS7000215": AzureCredentialsInvalidErr,
+	"AADSTS700016":  AzureCredentialsInvalidErr,
+	"AADSTS90002":   AzureCredentialsInvalidErr,
+}
+
+// Azure error codes of subscription requests when the application has no role in the
+// subscription, delegated subscriptions are not visible at all without a role assignment.
+var azureRoleErrorCodes = map[string]struct{}{
+	"AuthorizationFailed":              {},
+	"SubscriptionNotFound":             {},
+	"InvalidAuthenticationTokenTenant": {},
+}
+
+// MapAzureTokenError returns error wrapping AzureSecretExpiredErr or AzureCredentialsInvalidErr
+// for known AAD token acquisition errors, other errors are returned unchanged.
+func MapAzureTokenError(err error) error {
+	code := aadErrorCode.FindString(err.Error())
+	if kind, ok := aadErrorCodes[code]; ok {
+		return fmt.Errorf("%w (%s)", kind, code)
+	}
+	return err
+}
+
+// MapAzureSubscriptionError returns error wrapping AzureRoleAssignmentMissingErr when the
+// subscription cannot be read due to missing role assignment, other errors are returned unchanged.
+func MapAzureSubscriptionError(err error) error {
+	var azureErr *azcore.ResponseError
+	if errors.As(err, &azureErr) {
+		if _, ok := azureRoleErrorCodes[azureErr.ErrorCode]; ok {
+			return fmt.Errorf("%w (%s)", AzureRoleAssignmentMissingErr, azureErr.ErrorCode)
+		}
+	}
+	return err
+}
@@ -2,11 +2,15 @@ package http
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/rs/zerolog"
 )
 
+// requestIdHeaders are response headers with request ID of platform services in priority order
+var requestIdHeaders = []string{"X-Rh-Insights-Request-Id", "X-Request-Id", "X-Rh-Edge-Request-Id"}
+
 func IsHTTPStatus2xx(status int) bool {
 	return status >= 200 && status <= 299
 }
@@ -23,22 +27,46 @@ func IsHTTPForbidden(status int) bool {
 	return status == 403
 }
 
-// HandleHTTPResponses parses HTTP status code and returns on of the errors
-// defined in the client package (NotFoundErr, UnauthorizedErr, Non2xxResponseErr)
-// or nil when the response was 2xx (200-299 range).
-func HandleHTTPResponses(ctx context.Context, statusCode int) error {
-	if IsHTTPNotFound(statusCode) {
-		return clients.NotFoundErr
+// HandleHTTPResponses parses HTTP status code and returns nil when the response was 2xx
+// (200-299 range) or clients.ClientError of one of the kinds defined in the client package
+// (NotFoundErr, UnauthorizedErr, ForbiddenErr, Non2xxResponseErr) with the service name,
+// status and request ID of the response.
+func HandleHTTPResponses(ctx context.Context, service string, resp *http.Response) error {
+	status := 0
+	var header http.Header
+	if resp != nil {
+		status = resp.StatusCode
+		header = resp.Header
 	}
-	if IsHTTPUnauthorized(statusCode) {
-		return clients.UnauthorizedErr
+
+	var kind error
+	switch {
+	case IsHTTPNotFound(status):
+		kind = clients.NotFoundErr
+	case IsHTTPUnauthorized(status):
+		kind = clients.UnauthorizedErr
+	case IsHTTPForbidden(status):
+		kind = clients.ForbiddenErr
+	case !IsHTTPStatus2xx(status):
+		zerolog.Ctx(ctx).Warn().Msgf("Non-200 HTTP response seen: %v", status)
+		kind = clients.Non2xxResponseErr
+	default:
+		return nil
 	}
-	if IsHTTPForbidden(statusCode) {
-		return clients.ForbiddenErr
+
+	return &clients.ClientError{
+		Kind:      kind,
+		Provider:  service,
+		Status:    status,
+		RequestID: responseRequestId(header),
 	}
-	if !IsHTTPStatus2xx(statusCode) {
-		zerolog.Ctx(ctx).Warn().Msgf("Non-200 HTTP response seen: %v", statusCode)
-		return clients.Non2xxResponseErr
+}
+
+func responseRequestId(header http.Header) string {
+	for _, name := range requestIdHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
 	}
-	return nil
+	return ""
 }
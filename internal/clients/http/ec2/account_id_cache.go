@@ -0,0 +1,56 @@
+package ec2
+
+import (
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+)
+
+// accountIdEntry is an account ID returned by STS for a role ARN.
+type accountIdEntry struct {
+	accountId string
+	expires   time.Time
+}
+
+// account IDs memoized per role ARN, identity of a role never changes so entries only expire
+// to release memory of removed sources
+var (
+	accountIds      = make(map[string]accountIdEntry)
+	accountIdsMutex sync.Mutex
+)
+
+// cachedAccountId returns memoized account ID of a role ARN, false is returned when the ID
+// is not memoized or it has expired.
+func cachedAccountId(arn string, now time.Time) (string, bool) {
+	accountIdsMutex.Lock()
+	defer accountIdsMutex.Unlock()
+
+	entry, ok := accountIds[arn]
+	if !ok {
+		return "", false
+	}
+	if !entry.expires.After(now) {
+		delete(accountIds, arn)
+		return "", false
+	}
+	return entry.accountId, true
+}
+
+// memoizeAccountId stores account ID of a role ARN for AWS_ACCOUNT_ID_TTL, expired entries
+// of other ARNs are removed.
+func memoizeAccountId(arn, accountId string, now time.Time) {
+	if config.AWS.AccountIDTTL <= 0 {
+		return
+	}
+
+	accountIdsMutex.Lock()
+	defer accountIdsMutex.Unlock()
+
+	for key, entry := range accountIds {
+		if !entry.expires.After(now) {
+			delete(accountIds, key)
+		}
+	}
+	accountIds[arn] = accountIdEntry{accountId: accountId, expires: now.Add(config.AWS.AccountIDTTL)}
+}
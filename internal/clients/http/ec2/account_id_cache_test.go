@@ -0,0 +1,44 @@
+package ec2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoizeAccountId(t *testing.T) {
+	ttl := config.AWS.AccountIDTTL
+	config.AWS.AccountIDTTL = time.Hour
+	defer func() { config.AWS.AccountIDTTL = ttl }()
+
+	now := time.Now()
+	arn := "arn:aws:iam::230214684733:role/Test"
+	memoizeAccountId(arn, "230214684733", now)
+
+	t.Run("hit", func(t *testing.T) {
+		accountId, ok := cachedAccountId(arn, now.Add(time.Minute))
+		assert.True(t, ok)
+		assert.Equal(t, "230214684733", accountId)
+	})
+
+	t.Run("other arn", func(t *testing.T) {
+		_, ok := cachedAccountId("arn:aws:iam::123456789012:role/Test", now)
+		assert.False(t, ok)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, ok := cachedAccountId(arn, now.Add(2*time.Hour))
+		assert.False(t, ok)
+		_, ok = cachedAccountId(arn, now)
+		assert.False(t, ok, "expired entry should be removed")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		config.AWS.AccountIDTTL = 0
+		memoizeAccountId(arn, "230214684733", now)
+		_, ok := cachedAccountId(arn, now)
+		assert.False(t, ok)
+	})
+}
@@ -32,9 +32,30 @@ func NewInstanceTypes(ctx context.Context, types []types.InstanceTypeInfo) ([]*c
 			if types[i].InstanceStorageInfo != nil {
 				it.EphemeralStorageGB = *types[i].InstanceStorageInfo.TotalSizeInGB
 			}
+			if types[i].GpuInfo != nil {
+				setGPUInfo(&it, types[i].GpuInfo)
+			}
 			list = append(list, &it)
 		}
 	}
 	logger.Trace().Msgf("Number of instance types returned: %d, after filtering: %d", len(types), len(list))
 	return list, nil
 }
+
+// setGPUInfo sums up all GPU devices, model and manufacturer are taken from the first device.
+func setGPUInfo(it *clients.InstanceType, info *types.GpuInfo) {
+	for _, gpu := range info.Gpus {
+		if gpu.Count != nil {
+			it.GPUs += *gpu.Count
+		}
+		if it.GPUModel == "" && gpu.Name != nil {
+			it.GPUModel = *gpu.Name
+		}
+		if it.GPUManufacturer == "" && gpu.Manufacturer != nil {
+			it.GPUManufacturer = *gpu.Manufacturer
+		}
+	}
+	if info.TotalGpuMemoryInMiB != nil {
+		it.GPUMemoryMiB = int64(*info.TotalGpuMemoryInMiB)
+	}
+}
@@ -3,11 +3,18 @@ package ec2
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	stdhttp "net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
@@ -19,8 +26,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	stsTypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 )
 
@@ -31,6 +39,12 @@ type ec2Client struct {
 	sts     *sts.Client
 	iam     *iam.Client
 	assumed bool
+
+	// region of the client, it is recorded in spans of client calls
+	region string
+
+	// ARN of the assumed role, empty for the service account
+	arn string
 }
 
 func init() {
@@ -53,7 +67,11 @@ func awsConfig(ctx context.Context, region string, optFns ...func(*awsCfg.LoadOp
 		loggingOpt = awsCfg.WithClientLogMode(aws.LogRequestWithBody | aws.LogRetries | aws.LogResponseWithBody | aws.LogSigning)
 	}
 
+	// shared transport with request metrics trusting AWS_CA_BUNDLE certificates
+	httpClient := &stdhttp.Client{Transport: http.NewMetricsTransport(http.TransportFor(ctx, "", config.AWS.CABundle))}
+
 	optFns = append(optFns, loggingOpt,
+		awsCfg.WithHTTPClient(httpClient),
 		awsCfg.WithLogger(NewEC2Logger(ctx)),
 		awsCfg.WithRegion(region))
 
@@ -69,8 +87,12 @@ func newEC2ClientWithRegion(ctx context.Context, region string) (clients.EC2, er
 		region = config.AWS.DefaultRegion
 	}
 
-	cfg, err := awsConfig(ctx, region,
-		awsCfg.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(config.AWS.Key, config.AWS.Secret, config.AWS.Session)))
+	provider, err := serviceCredentials(clients.AWSPartitionOfRegion(region))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := awsConfig(ctx, region, awsCfg.WithCredentialsProvider(provider))
 	if err != nil {
 		return nil, fmt.Errorf("aws: %w", err)
 	}
@@ -80,6 +102,7 @@ func newEC2ClientWithRegion(ctx context.Context, region string) (clients.EC2, er
 		sts:     sts.NewFromConfig(*cfg),
 		iam:     iam.NewFromConfig(*cfg),
 		assumed: false,
+		region:  cfg.Region,
 	}, nil
 }
 
@@ -88,11 +111,17 @@ func newAssumedEC2ClientWithRegion(ctx context.Context, auth *clients.Authentica
 		return nil, fmt.Errorf("unexpected authentication: %w", typeErr)
 	}
 
-	if region == "" {
-		region = config.AWS.DefaultRegion
+	// blank region is the default region of the partition of the role
+	partition, err := clients.AWSPartitionOfARN(auth.Payload)
+	if err != nil {
+		return nil, err
+	}
+	region, err = partitionRegion(partition, region)
+	if err != nil {
+		return nil, err
 	}
 
-	assumedCredentials, err := getStsAssumedCredentials(ctx, auth.Payload, region)
+	assumedCredentials, err := getStsAssumedCredentials(ctx, auth, partition, region)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +140,8 @@ func newAssumedEC2ClientWithRegion(ctx context.Context, auth *clients.Authentica
 		sts:     sts.NewFromConfig(*cfg),
 		iam:     iam.NewFromConfig(*cfg),
 		assumed: true,
+		region:  cfg.Region,
+		arn:     auth.Payload,
 	}, nil
 }
 
@@ -119,24 +150,94 @@ func (c *ec2Client) Status(ctx context.Context) error {
 	return err
 }
 
-func getStsAssumedCredentials(ctx context.Context, arn string, region string) (*stsTypes.Credentials, error) {
+// getStsAssumedCredentials assumes the role of the authentication by the service account of the
+// partition. When the authentication has a role chain, roles of the chain are assumed in order
+// first and each role is assumed with credentials of the previous one. All roles of the chain
+// must be in the same partition.
+func getStsAssumedCredentials(ctx context.Context, auth *clients.Authentication, partition, region string) (*stsTypes.Credentials, error) {
+	provider, err := serviceCredentials(partition)
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]clients.AssumedRole, 0, len(auth.RoleChain)+1)
+	hops = append(hops, auth.RoleChain...)
+	hops = append(hops, clients.AssumedRole{ARN: auth.Payload, ExternalID: auth.ExternalID})
+
+	for _, hop := range hops {
+		hopPartition, hopErr := clients.AWSPartitionOfARN(hop.ARN)
+		if hopErr != nil {
+			return nil, fmt.Errorf("cannot assume role %s: %w", hop.ARN, hopErr)
+		}
+		if hopPartition != partition {
+			return nil, fmt.Errorf("cannot assume role %s of partition %s from partition %s: %w", hop.ARN, hopPartition, partition, clients.UnsupportedAWSPartitionErr)
+		}
+	}
+
+	if config.ExternalIDRequired(identity.OrgIdOrEmpty(ctx)) {
+		for _, hop := range hops {
+			if hop.ExternalID == "" {
+				return nil, fmt.Errorf("cannot assume role %s: %w", hop.ARN, http.ExternalIDRequiredErr)
+			}
+		}
+	}
+
+	tags := sessionTags(ctx)
+	var assumed *stsTypes.Credentials
+	for i, hop := range hops {
+		assumed, err = assumeRole(ctx, provider, hop, tags, region)
+		if err != nil {
+			if i < len(hops)-1 {
+				return nil, fmt.Errorf("cannot assume role %d of the role chain: %w", i+1, err)
+			}
+			return nil, err
+		}
+		provider = credentials.NewStaticCredentialsProvider(*assumed.AccessKeyId, *assumed.SecretAccessKey, *assumed.SessionToken)
+	}
+
+	return assumed, nil
+}
+
+// sessionTags returns session tags attributing calls to the organization and the reservation of
+// the context, nil is returned when session tags are not enabled for the organization.
+func sessionTags(ctx context.Context) []stsTypes.Tag {
+	orgId := identity.OrgIdOrEmpty(ctx)
+	if !config.SessionTagsEnabled(orgId) {
+		return nil
+	}
+
+	var tags []stsTypes.Tag
+	if orgId != "" {
+		tags = append(tags, stsTypes.Tag{Key: ptr.To("rhhc:org-id"), Value: ptr.To(orgId)})
+	}
+	if reservationId := clients.ReservationIdOrZero(ctx); reservationId != 0 {
+		tags = append(tags, stsTypes.Tag{Key: ptr.To("rhhc:reservation-id"), Value: ptr.To(strconv.FormatInt(reservationId, 10))})
+	}
+	return tags
+}
+
+// assumeRole assumes a single role with the given credentials, tags are attached to the session.
+func assumeRole(ctx context.Context, provider aws.CredentialsProvider, role clients.AssumedRole, tags []stsTypes.Tag, region string) (*stsTypes.Credentials, error) {
 	logger := logger(ctx)
 
-	cfg, err := awsConfig(ctx, region,
-		awsCfg.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(config.AWS.Key, config.AWS.Secret, config.AWS.Session)))
+	cfg, err := awsConfig(ctx, region, awsCfg.WithCredentialsProvider(provider))
 	if err != nil {
 		return nil, fmt.Errorf("aws sts: %w", err)
 	}
 	stsClient := sts.NewFromConfig(*cfg)
-	if err != nil {
-		logger.Error().Err(err).Msg("Cannot create STS client")
-		return nil, fmt.Errorf("cannot create STS client %w", err)
-	}
 
-	output, err := stsClient.AssumeRole(ctx, &sts.AssumeRoleInput{
-		RoleArn:         ptr.To(arn),
+	input := &sts.AssumeRoleInput{
+		RoleArn:         ptr.To(role.ARN),
 		RoleSessionName: ptr.To("name"),
-	})
+	}
+	if role.ExternalID != "" {
+		input.ExternalId = ptr.To(role.ExternalID)
+	}
+	if len(tags) > 0 {
+		input.Tags = tags
+	}
+
+	output, err := stsClient.AssumeRole(ctx, input)
 	if err != nil {
 		logger.Error().Err(err).Msg("Cannot assume role")
 		return nil, fmt.Errorf("cannot assume role %w", err)
@@ -148,7 +249,7 @@ func getStsAssumedCredentials(ctx context.Context, arn string, region string) (*
 // ImportPubkey imports a key and returns AWS KeyPair name.
 // The AWS name will be set to value of models.Pubkey Name.
 func (c *ec2Client) ImportPubkey(ctx context.Context, key *models.Pubkey, tag string) (string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ImportPubkey")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "ImportPubkey", c.region)
 	defer span.End()
 
 	if !c.assumed {
@@ -173,12 +274,10 @@ func (c *ec2Client) ImportPubkey(ctx context.Context, key *models.Pubkey, tag st
 	}
 	output, err := c.ec2.ImportKeyPair(ctx, input)
 	if err != nil {
-		if isAWSUnauthorizedError(err) {
-			err = clients.UnauthorizedErr
-		} else if isAWSOperationError(err, "InvalidKeyPair.Duplicate") {
+		if isAWSOperationError(err, "InvalidKeyPair.Duplicate") {
 			err = http.DuplicatePubkeyErr
 		}
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return "", fmt.Errorf("cannot import SSH key %s: %w", key.Name, err)
 	}
 
@@ -186,7 +285,7 @@ func (c *ec2Client) ImportPubkey(ctx context.Context, key *models.Pubkey, tag st
 }
 
 func (c *ec2Client) GetPubkeyName(ctx context.Context, fingerprint string) (string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "fetchPubkeyName")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "GetPubkeyName", c.region)
 	defer span.End()
 
 	if !c.assumed {
@@ -198,10 +297,7 @@ func (c *ec2Client) GetPubkeyName(ctx context.Context, fingerprint string) (stri
 	input.Filters = []types.Filter{{Name: ptr.To("fingerprint"), Values: []string{fingerprint}}}
 	output, err := c.ec2.DescribeKeyPairs(ctx, input)
 	if err != nil {
-		if isAWSUnauthorizedError(err) {
-			err = clients.UnauthorizedErr
-		}
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return "", fmt.Errorf("cannot fetch SSH key to update its tag %s: %w", fingerprint, err)
 	}
 
@@ -213,7 +309,7 @@ func (c *ec2Client) GetPubkeyName(ctx context.Context, fingerprint string) (stri
 }
 
 func (c *ec2Client) DeleteSSHKey(ctx context.Context, handle string) error {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "DeleteSSHKey")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "DeleteSSHKey", c.region)
 	defer span.End()
 
 	if !c.assumed {
@@ -226,10 +322,7 @@ func (c *ec2Client) DeleteSSHKey(ctx context.Context, handle string) error {
 	input.KeyPairId = ptr.To(handle)
 	_, err := c.ec2.DeleteKeyPair(ctx, input)
 	if err != nil {
-		if isAWSUnauthorizedError(err) {
-			err = clients.UnauthorizedErr
-		}
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return fmt.Errorf("cannot delete SSH key %v: %w", input.KeyPairId, err)
 	}
 
@@ -237,15 +330,16 @@ func (c *ec2Client) DeleteSSHKey(ctx context.Context, handle string) error {
 }
 
 func (c *ec2Client) ListAllRegions(ctx context.Context) ([]clients.Region, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "ListAllRegions", c.region)
+	defer span.End()
+
 	input := &ec2.DescribeRegionsInput{
 		AllRegions: ptr.To(true),
 	}
 
 	output, err := c.ec2.DescribeRegions(ctx, input)
 	if err != nil {
-		if isAWSUnauthorizedError(err) {
-			err = clients.UnauthorizedErr
-		}
+		span.Fail(err)
 		return nil, fmt.Errorf("cannot list regions: %w", err)
 	}
 
@@ -258,6 +352,9 @@ func (c *ec2Client) ListAllRegions(ctx context.Context) ([]clients.Region, error
 }
 
 func (c *ec2Client) ListAllZones(ctx context.Context, region clients.Region) ([]clients.Zone, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "ListAllZones", region.String())
+	defer span.End()
+
 	input := &ec2.DescribeAvailabilityZonesInput{
 		AllAvailabilityZones: ptr.To(true),
 		Filters: []types.Filter{
@@ -270,9 +367,7 @@ func (c *ec2Client) ListAllZones(ctx context.Context, region clients.Region) ([]
 
 	output, err := c.ec2.DescribeAvailabilityZones(ctx, input)
 	if err != nil {
-		if isAWSUnauthorizedError(err) {
-			err = clients.UnauthorizedErr
-		}
+		span.Fail(err)
 		return nil, fmt.Errorf("cannot list zones: %w", err)
 	}
 
@@ -285,7 +380,7 @@ func (c *ec2Client) ListAllZones(ctx context.Context, region clients.Region) ([]
 }
 
 func (c *ec2Client) ListInstanceTypes(ctx context.Context) ([]*clients.InstanceType, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListInstanceTypes")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "ListInstanceTypes", c.region)
 	defer span.End()
 
 	input := &ec2.DescribeInstanceTypesInput{MaxResults: ptr.ToInt32(100)}
@@ -295,10 +390,7 @@ func (c *ec2Client) ListInstanceTypes(ctx context.Context) ([]*clients.InstanceT
 	for pag.HasMorePages() {
 		resp, err := pag.NextPage(ctx)
 		if err != nil {
-			if isAWSUnauthorizedError(err) {
-				err = clients.UnauthorizedErr
-			}
-			span.SetStatus(codes.Error, err.Error())
+			span.Fail(err)
 			return nil, fmt.Errorf("cannot list instance types: %w", err)
 		}
 		res = append(res, resp.InstanceTypes...)
@@ -307,7 +399,7 @@ func (c *ec2Client) ListInstanceTypes(ctx context.Context) ([]*clients.InstanceT
 	// convert to the client type
 	instances, err := NewInstanceTypes(ctx, res)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return nil, fmt.Errorf("cannot convert instance types: %w", err)
 	}
 
@@ -315,7 +407,7 @@ func (c *ec2Client) ListInstanceTypes(ctx context.Context) ([]*clients.InstanceT
 }
 
 func (c *ec2Client) DescribeInstanceDetails(ctx context.Context, InstanceIds []string) ([]*clients.InstanceDescription, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "DescribeInstanceDetails")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "DescribeInstanceDetails", c.region)
 	defer span.End()
 
 	input := &ec2.DescribeInstancesInput{
@@ -323,22 +415,109 @@ func (c *ec2Client) DescribeInstanceDetails(ctx context.Context, InstanceIds []s
 	}
 	resp, err := c.ec2.DescribeInstances(ctx, input)
 	if err != nil {
-		if isAWSUnauthorizedError(err) {
-			err = clients.UnauthorizedErr
-		}
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return nil, fmt.Errorf("cannot fetch instances description: %w", err)
 	}
 	instanceDetailList, err := c.parseDescribeInstances(resp)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to fetch instances description: %w", err)
 	}
 	return instanceDetailList, nil
 }
 
+// GetConsoleOutput returns the latest console output of an instance, AWS keeps the last 64 KB
+// of output which is updated shortly after boot, reboot and stop.
+func (c *ec2Client) GetConsoleOutput(ctx context.Context, instanceID string) (*clients.ConsoleOutput, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "GetConsoleOutput", c.region)
+	defer span.End()
+
+	resp, err := c.ec2.GetConsoleOutput(ctx, &ec2.GetConsoleOutputInput{InstanceId: &instanceID})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot get console output of instance %s: %w", instanceID, err)
+	}
+
+	output, err := base64.StdEncoding.DecodeString(ptr.FromOrEmpty(resp.Output))
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot decode console output of instance %s: %w", instanceID, err)
+	}
+	return &clients.ConsoleOutput{Output: string(output), Timestamp: resp.Timestamp}, nil
+}
+
+func (c *ec2Client) GetImageArchitecture(ctx context.Context, ami string) (clients.ArchitectureType, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "GetImageArchitecture", c.region)
+	defer span.End()
+
+	input := &ec2.DescribeImagesInput{
+		ImageIds: []string{ami},
+	}
+	resp, err := c.ec2.DescribeImages(ctx, input)
+	if err != nil {
+		span.Fail(err)
+		if http.IsProviderNotFound(err) {
+			return "", fmt.Errorf("cannot describe image %s: %w", ami, http.ImageNotFoundErr)
+		}
+		return "", fmt.Errorf("cannot describe image %s: %w", ami, err)
+	}
+	if len(resp.Images) == 0 {
+		span.Fail(http.ImageNotFoundErr)
+		return "", fmt.Errorf("cannot describe image %s: %w", ami, http.ImageNotFoundErr)
+	}
+
+	arch, err := clients.MapArchitectures(ctx, string(resp.Images[0].Architecture))
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("unknown architecture of image %s: %w", ami, err)
+	}
+	return arch, nil
+}
+
+func (c *ec2Client) GetInstanceTypeArchitectures(ctx context.Context, name clients.InstanceTypeName) ([]clients.ArchitectureType, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "GetInstanceTypeArchitectures", c.region)
+	defer span.End()
+
+	offerings, err := c.ec2.DescribeInstanceTypeOfferings(ctx, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: types.LocationTypeRegion,
+		Filters: []types.Filter{{
+			Name:   ptr.To("instance-type"),
+			Values: []string{string(name)},
+		}},
+	})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot describe offerings of instance type %s: %w", name, err)
+	}
+	if len(offerings.InstanceTypeOfferings) == 0 {
+		span.Fail(http.InstanceTypeNotOfferedErr)
+		return nil, fmt.Errorf("cannot describe offerings of instance type %s: %w", name, http.InstanceTypeNotOfferedErr)
+	}
+
+	resp, err := c.ec2.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []types.InstanceType{types.InstanceType(name)},
+	})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot describe instance type %s: %w", name, err)
+	}
+	if len(resp.InstanceTypes) == 0 || resp.InstanceTypes[0].ProcessorInfo == nil {
+		span.Fail(http.InstanceTypeNotOfferedErr)
+		return nil, fmt.Errorf("cannot describe instance type %s: %w", name, http.InstanceTypeNotOfferedErr)
+	}
+
+	// architectures unknown to the application (e.g. x86_64_mac) cannot be launched
+	var result []clients.ArchitectureType
+	for _, awsArch := range resp.InstanceTypes[0].ProcessorInfo.SupportedArchitectures {
+		if arch, archErr := clients.MapArchitectures(ctx, string(awsArch)); archErr == nil {
+			result = append(result, arch)
+		}
+	}
+	return result, nil
+}
+
 func (c *ec2Client) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchTemplate, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListLaunchTemplates")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "ListLaunchTemplates", c.region)
 	defer span.End()
 
 	input := &ec2.DescribeLaunchTemplatesInput{MaxResults: ptr.ToInt32(100)}
@@ -348,10 +527,7 @@ func (c *ec2Client) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchT
 	for pag.HasMorePages() {
 		resp, err := pag.NextPage(ctx)
 		if err != nil {
-			if isAWSUnauthorizedError(err) {
-				err = clients.UnauthorizedErr
-			}
-			span.SetStatus(codes.Error, err.Error())
+			span.Fail(err)
 			return nil, fmt.Errorf("cannot list launch templates: %w", err)
 		}
 
@@ -367,16 +543,34 @@ func (c *ec2Client) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchT
 	return res, nil
 }
 
-func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanceParams, amount int32, name *string) ([]*string, *string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "RunInstances")
-	defer span.End()
+// tagSpecifications returns the Name tag (when set) and the resource tags for each of the resource
+// types, nil when there is nothing to tag. Tags are sorted by key.
+func tagSpecifications(name *string, tags map[string]string, resourceTypes ...types.ResourceType) []types.TagSpecification {
+	var awsTags []types.Tag
+	if name != nil {
+		awsTags = append(awsTags, types.Tag{Key: ptr.To("Name"), Value: name})
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		awsTags = append(awsTags, types.Tag{Key: ptr.To(key), Value: ptr.To(tags[key])})
+	}
+	if len(awsTags) == 0 {
+		return nil
+	}
 
-	if !c.assumed {
-		return nil, nil, http.ServiceAccountUnsupportedOperationErr
+	specifications := make([]types.TagSpecification, len(resourceTypes))
+	for i, resourceType := range resourceTypes {
+		specifications[i] = types.TagSpecification{ResourceType: resourceType, Tags: awsTags}
 	}
-	logger := logger(ctx)
-	logger.Trace().Msg("Run AWS EC2 instance")
+	return specifications
+}
 
+// runInstancesInput returns input of RunInstances with the launch parameters.
+func runInstancesInput(params *clients.AWSInstanceParams, amount int32, name *string) *ec2.RunInstancesInput {
 	var templateSpec *types.LaunchTemplateSpecification
 	if params.LaunchTemplateID != "" {
 		templateSpec = &types.LaunchTemplateSpecification{
@@ -391,41 +585,324 @@ func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanc
 		MinCount:       ptr.To(amount),
 		InstanceType:   params.InstanceType,
 		ImageId:        ptr.To(params.AMI),
-		KeyName:        &params.KeyName,
 		UserData:       &encodedUserData,
 	}
-	if name != nil {
-		input.TagSpecifications = []types.TagSpecification{
-			{
-				ResourceType: types.ResourceTypeInstance,
-				Tags: []types.Tag{
-					{
-						Key:   ptr.To("Name"),
-						Value: name,
-					},
-				},
-			},
+	// key is not set in dry runs, it is only uploaded during the launch
+	if params.KeyName != "" {
+		input.KeyName = ptr.To(params.KeyName)
+	}
+	if params.Tenancy != "" && params.Tenancy != string(types.TenancyDefault) {
+		input.Placement = &types.Placement{Tenancy: types.Tenancy(params.Tenancy)}
+		if params.HostID != "" {
+			input.Placement.HostId = ptr.To(params.HostID)
+		}
+	}
+	if options := params.MetadataOptions; options != nil {
+		input.MetadataOptions = &types.InstanceMetadataOptionsRequest{
+			HttpEndpoint:            types.InstanceMetadataEndpointStateEnabled,
+			HttpTokens:              types.HttpTokensState(options.HTTPTokens),
+			HttpPutResponseHopLimit: ptr.To(options.HopLimit),
+		}
+		if options.Disabled {
+			input.MetadataOptions.HttpEndpoint = types.InstanceMetadataEndpointStateDisabled
 		}
 	}
+	input.TagSpecifications = tagSpecifications(name, params.Tags, types.ResourceTypeInstance, types.ResourceTypeVolume)
+	return input
+}
+
+func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanceParams, amount int32, name *string) ([]*string, *string, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "RunInstances", c.region)
+	defer span.End()
+
+	if !c.assumed {
+		return nil, nil, http.ServiceAccountUnsupportedOperationErr
+	}
+	logger := logger(ctx)
+	logger.Trace().Msg("Run AWS EC2 instance")
+
+	input := runInstancesInput(params, amount, name)
 
 	resp, err := c.ec2.RunInstances(ctx, input)
 	if err != nil {
-		if isAWSUnauthorizedError(err) {
-			err = clients.UnauthorizedErr
-		}
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return nil, nil, fmt.Errorf("cannot run instances: %w", err)
 	}
 
 	instances := c.parseRunInstancesResponse(resp)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return nil, nil, fmt.Errorf("cannot ParseRunInstancesResponse: %w", err)
 	}
 
 	return instances, resp.ReservationId, nil
 }
 
+func (c *ec2Client) DryRunInstances(ctx context.Context, params *clients.AWSInstanceParams, amount int32, name *string) (*clients.DryRunResult, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "DryRunInstances", c.region)
+	defer span.End()
+
+	if !c.assumed {
+		return nil, http.ServiceAccountUnsupportedOperationErr
+	}
+	logger := logger(ctx)
+	logger.Trace().Msg("Dry run AWS EC2 instance")
+
+	input := runInstancesInput(params, amount, name)
+	input.DryRun = ptr.To(true)
+
+	// DryRunOperation error is returned when the request would succeed
+	_, err := c.ec2.RunInstances(ctx, input)
+	if err == nil {
+		return &clients.DryRunResult{Passed: true}, nil
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot dry run instances: %w", err)
+	}
+
+	result := &clients.DryRunResult{
+		Passed:  apiErr.ErrorCode() == "DryRunOperation",
+		Code:    apiErr.ErrorCode(),
+		Message: apiErr.ErrorMessage(),
+	}
+	if apiErr.ErrorCode() == "UnauthorizedOperation" {
+		result.MissingPermissions = []string{"ec2:RunInstances"}
+	}
+	return result, nil
+}
+
+func (c *ec2Client) LaunchFleet(ctx context.Context, params *clients.AWSInstanceParams, instanceTypes []clients.InstanceTypeName, amount int32, name *string) ([]*string, *string, clients.InstanceTypeName, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "LaunchFleet", c.region)
+	defer span.End()
+
+	if !c.assumed {
+		return nil, nil, "", http.ServiceAccountUnsupportedOperationErr
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Launch AWS EC2 fleet of types %v", instanceTypes)
+
+	// fleets can only be launched from a launch template, a temporary one is created for the launch
+	templateId, err := c.createFleetTemplate(ctx, params)
+	if err != nil {
+		span.Fail(err)
+		return nil, nil, "", fmt.Errorf("cannot create fleet launch template: %w", err)
+	}
+	defer c.deleteFleetTemplate(ctx, templateId)
+
+	var placement *types.Placement
+	if params.Tenancy != "" && params.Tenancy != string(types.TenancyDefault) {
+		placement = &types.Placement{Tenancy: types.Tenancy(params.Tenancy)}
+		if params.HostID != "" {
+			placement.HostId = ptr.To(params.HostID)
+		}
+	}
+
+	// lower priority value is launched first
+	overrides := make([]types.FleetLaunchTemplateOverridesRequest, len(instanceTypes))
+	for i, instanceType := range instanceTypes {
+		overrides[i] = types.FleetLaunchTemplateOverridesRequest{
+			InstanceType: types.InstanceType(instanceType),
+			Priority:     ptr.To(float64(i)),
+			Placement:    placement,
+		}
+	}
+
+	input := &ec2.CreateFleetInput{
+		Type: types.FleetTypeInstant,
+		LaunchTemplateConfigs: []types.FleetLaunchTemplateConfigRequest{
+			{
+				LaunchTemplateSpecification: &types.FleetLaunchTemplateSpecificationRequest{
+					LaunchTemplateId: ptr.To(templateId),
+					Version:          ptr.To("$Latest"),
+				},
+				Overrides: overrides,
+			},
+		},
+		TargetCapacitySpecification: &types.TargetCapacitySpecificationRequest{
+			TotalTargetCapacity:       ptr.To(amount),
+			DefaultTargetCapacityType: types.DefaultTargetCapacityTypeOnDemand,
+		},
+		// all instances of the same type, nothing is launched when the amount cannot be satisfied
+		OnDemandOptions: &types.OnDemandOptionsRequest{
+			AllocationStrategy: types.FleetOnDemandAllocationStrategyPrioritized,
+			SingleInstanceType: ptr.To(true),
+			MinTargetCapacity:  ptr.To(amount),
+		},
+	}
+	// instant fleets only support tagging of instances
+	input.TagSpecifications = tagSpecifications(name, params.Tags, types.ResourceTypeInstance)
+
+	resp, err := c.ec2.CreateFleet(ctx, input)
+	if err != nil {
+		span.Fail(err)
+		return nil, nil, "", fmt.Errorf("cannot create fleet: %w", err)
+	}
+
+	var instances []*string
+	var instanceType clients.InstanceTypeName
+	for _, fleetInstance := range resp.Instances {
+		for _, instanceId := range fleetInstance.InstanceIds {
+			instances = append(instances, ptr.To(instanceId))
+		}
+		instanceType = clients.InstanceTypeName(fleetInstance.InstanceType)
+	}
+	if len(instances) == 0 {
+		err = fmt.Errorf("%w: %s", http.FleetCapacityErr, fleetErrors(resp.Errors))
+		span.Fail(err)
+		return nil, nil, "", err
+	}
+
+	return instances, resp.FleetId, instanceType, nil
+}
+
+// createFleetTemplate creates a launch template with the image, pubkey, user data and metadata
+// options of the fleet launch, returning its ID.
+func (c *ec2Client) createFleetTemplate(ctx context.Context, params *clients.AWSInstanceParams) (string, error) {
+	encodedUserData := base64.StdEncoding.EncodeToString(params.UserData)
+	input := &ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: ptr.To("provisioning-fleet-" + uuid.NewString()),
+		LaunchTemplateData: &types.RequestLaunchTemplateData{
+			ImageId:  ptr.To(params.AMI),
+			KeyName:  ptr.To(params.KeyName),
+			UserData: &encodedUserData,
+		},
+	}
+	if options := params.MetadataOptions; options != nil {
+		input.LaunchTemplateData.MetadataOptions = &types.LaunchTemplateInstanceMetadataOptionsRequest{
+			HttpEndpoint:            types.LaunchTemplateInstanceMetadataEndpointStateEnabled,
+			HttpTokens:              types.LaunchTemplateHttpTokensState(options.HTTPTokens),
+			HttpPutResponseHopLimit: ptr.To(options.HopLimit),
+		}
+		if options.Disabled {
+			input.LaunchTemplateData.MetadataOptions.HttpEndpoint = types.LaunchTemplateInstanceMetadataEndpointStateDisabled
+		}
+	}
+	resp, err := c.ec2.CreateLaunchTemplate(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("cannot create launch template: %w", err)
+	}
+	return *resp.LaunchTemplate.LaunchTemplateId, nil
+}
+
+// deleteFleetTemplate deletes the temporary launch template, failures are only logged.
+func (c *ec2Client) deleteFleetTemplate(ctx context.Context, templateId string) {
+	_, err := c.ec2.DeleteLaunchTemplate(ctx, &ec2.DeleteLaunchTemplateInput{LaunchTemplateId: ptr.To(templateId)})
+	if err != nil {
+		logger(ctx).Warn().Err(err).Msgf("Unable to delete fleet launch template %s", templateId)
+	}
+}
+
+// fleetErrors returns a summary of fleet launch errors, one per instance type.
+func fleetErrors(fleetErrors []types.CreateFleetError) string {
+	messages := make([]string, 0, len(fleetErrors))
+	for _, fleetErr := range fleetErrors {
+		var instanceType types.InstanceType
+		if fleetErr.LaunchTemplateAndOverrides != nil && fleetErr.LaunchTemplateAndOverrides.Overrides != nil {
+			instanceType = fleetErr.LaunchTemplateAndOverrides.Overrides.InstanceType
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", instanceType, ptr.From(fleetErr.ErrorCode)))
+	}
+	return strings.Join(messages, ", ")
+}
+
+func (c *ec2Client) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "TerminateInstances", c.region)
+	defer span.End()
+
+	if !c.assumed {
+		return http.ServiceAccountUnsupportedOperationErr
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Terminating AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.TerminateInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.TerminateInstances(ctx, input)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot terminate instances: %w", err)
+	}
+
+	return nil
+}
+
+// instanceStateTimeout is the maximum time to wait for instances to stop or start
+const instanceStateTimeout = 10 * time.Minute
+
+// StopInstances stops instances and waits until they are stopped.
+func (c *ec2Client) StopInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "StopInstances", c.region)
+	defer span.End()
+
+	if !c.assumed {
+		return http.ServiceAccountUnsupportedOperationErr
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Stopping AWS EC2 instances %v", instanceIds)
+
+	_, err := c.ec2.StopInstances(ctx, &ec2.StopInstancesInput{InstanceIds: instanceIds})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot stop instances: %w", err)
+	}
+
+	waiter := ec2.NewInstanceStoppedWaiter(c.ec2)
+	err = waiter.Wait(ctx, &ec2.DescribeInstancesInput{InstanceIds: instanceIds}, instanceStateTimeout)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot wait for instances to stop: %w", err)
+	}
+	return nil
+}
+
+// StartInstances starts instances and waits until they are running.
+func (c *ec2Client) StartInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "StartInstances", c.region)
+	defer span.End()
+
+	if !c.assumed {
+		return http.ServiceAccountUnsupportedOperationErr
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Starting AWS EC2 instances %v", instanceIds)
+
+	_, err := c.ec2.StartInstances(ctx, &ec2.StartInstancesInput{InstanceIds: instanceIds})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot start instances: %w", err)
+	}
+
+	waiter := ec2.NewInstanceRunningWaiter(c.ec2)
+	err = waiter.Wait(ctx, &ec2.DescribeInstancesInput{InstanceIds: instanceIds}, instanceStateTimeout)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot wait for instances to start: %w", err)
+	}
+	return nil
+}
+
+// RebootInstances requests reboot of instances, EC2 does not report when the reboot finishes.
+func (c *ec2Client) RebootInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "RebootInstances", c.region)
+	defer span.End()
+
+	if !c.assumed {
+		return http.ServiceAccountUnsupportedOperationErr
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Rebooting AWS EC2 instances %v", instanceIds)
+
+	_, err := c.ec2.RebootInstances(ctx, &ec2.RebootInstancesInput{InstanceIds: instanceIds})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot reboot instances: %w", err)
+	}
+	return nil
+}
+
 func (c *ec2Client) parseRunInstancesResponse(respAWS *ec2.RunInstancesOutput) []*string {
 	instances := respAWS.Instances
 	list := make([]*string, len(instances))
@@ -439,27 +916,46 @@ func (c *ec2Client) parseDescribeInstances(respAWS *ec2.DescribeInstancesOutput)
 	if len(respAWS.Reservations) == 0 {
 		return nil, http.NoReservationErr
 	}
-	instances := respAWS.Reservations[0].Instances
-	list := make([]*clients.InstanceDescription, len(instances))
-	for i, instance := range instances {
-		list[i] = &clients.InstanceDescription{
-			ID:         *instance.InstanceId,
-			PublicIPv4: ptr.FromOrEmpty(instance.PublicIpAddress),
-			PublicDNS:  ptr.FromOrEmpty(instance.PublicDnsName),
+	var list []*clients.InstanceDescription
+	for _, reservation := range respAWS.Reservations {
+		for _, instance := range reservation.Instances {
+			description := &clients.InstanceDescription{
+				ID:          *instance.InstanceId,
+				PublicIPv4:  ptr.FromOrEmpty(instance.PublicIpAddress),
+				PublicDNS:   ptr.FromOrEmpty(instance.PublicDnsName),
+				PrivateIPv4: ptr.FromOrEmpty(instance.PrivateIpAddress),
+				LaunchTime:  instance.LaunchTime,
+			}
+			if instance.State != nil {
+				description.State = string(instance.State.Name)
+			}
+			list = append(list, description)
 		}
 	}
 	return list, nil
 }
 
+// GetAccountId returns the account ID of the caller. Account IDs of assumed roles are memoized
+// per process for AWS_ACCOUNT_ID_TTL so STS is not called on every request.
 func (c *ec2Client) GetAccountId(ctx context.Context) (string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "GetAccountId")
+	if c.arn != "" {
+		if accountId, ok := cachedAccountId(c.arn, time.Now()); ok {
+			return accountId, nil
+		}
+	}
+
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "GetAccountId", c.region)
 	defer span.End()
 
 	input := &sts.GetCallerIdentityInput{}
 	out, err := c.sts.GetCallerIdentity(ctx, input)
 	if err != nil {
+		span.Fail(err)
 		return "", fmt.Errorf("cannot get caller's identity: %w", err)
 	}
 
+	if c.arn != "" {
+		memoizeAccountId(c.arn, *out.Account, time.Now())
+	}
 	return *out.Account, nil
 }
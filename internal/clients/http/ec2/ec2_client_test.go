@@ -0,0 +1,125 @@
+package ec2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	stsTypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionTags(t *testing.T) {
+	ctx := identity.WithIdentity(t, context.Background())
+	ctx = clients.WithReservationId(ctx, 42)
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, sessionTags(ctx))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		config.AWS.SessionTagsOrgs = []string{identity.DefaultOrgId}
+		defer func() { config.AWS.SessionTagsOrgs = nil }()
+
+		assert.Equal(t, []stsTypes.Tag{
+			{Key: ptr.To("rhhc:org-id"), Value: ptr.To(identity.DefaultOrgId)},
+			{Key: ptr.To("rhhc:reservation-id"), Value: ptr.To("42")},
+		}, sessionTags(ctx))
+	})
+
+	t.Run("without reservation", func(t *testing.T) {
+		config.AWS.SessionTags = true
+		defer func() { config.AWS.SessionTags = false }()
+
+		assert.Equal(t, []stsTypes.Tag{
+			{Key: ptr.To("rhhc:org-id"), Value: ptr.To(identity.DefaultOrgId)},
+		}, sessionTags(identity.WithIdentity(t, context.Background())))
+	})
+}
+
+func TestTagSpecifications(t *testing.T) {
+	t.Run("nothing to tag", func(t *testing.T) {
+		assert.Nil(t, tagSpecifications(nil, nil, types.ResourceTypeInstance))
+	})
+
+	t.Run("name and tags", func(t *testing.T) {
+		specs := tagSpecifications(ptr.To("vm"), map[string]string{"team": "qe", "org-id": "1"},
+			types.ResourceTypeInstance, types.ResourceTypeVolume)
+
+		expected := []types.Tag{
+			{Key: ptr.To("Name"), Value: ptr.To("vm")},
+			{Key: ptr.To("org-id"), Value: ptr.To("1")},
+			{Key: ptr.To("team"), Value: ptr.To("qe")},
+		}
+		assert.Len(t, specs, 2)
+		assert.Equal(t, types.ResourceTypeInstance, specs[0].ResourceType)
+		assert.Equal(t, types.ResourceTypeVolume, specs[1].ResourceType)
+		assert.Equal(t, expected, specs[1].Tags)
+	})
+}
+
+func TestExternalIDRequired(t *testing.T) {
+	config.AWS.RequireExternalIDOrgs = []string{identity.DefaultOrgId}
+	defer func() { config.AWS.RequireExternalIDOrgs = nil }()
+
+	auth := clients.NewAuthentication("arn:aws:iam::230214684733:role/Test", models.ProviderTypeAWS)
+	auth.ExternalID = "target-id"
+	auth.RoleChain = []clients.AssumedRole{{ARN: "arn:aws:iam::111111111111:role/Jump"}}
+
+	_, err := getStsAssumedCredentials(identity.WithIdentity(t, context.Background()), auth, clients.AWSPartition, "us-east-1")
+	assert.ErrorIs(t, err, http.ExternalIDRequiredErr)
+}
+
+func TestRoleChainPartition(t *testing.T) {
+	auth := clients.NewAuthentication("arn:aws:iam::230214684733:role/Test", models.ProviderTypeAWS)
+	auth.RoleChain = []clients.AssumedRole{{ARN: "arn:aws-us-gov:iam::111111111111:role/Jump"}}
+
+	_, err := getStsAssumedCredentials(identity.WithIdentity(t, context.Background()), auth, clients.AWSPartition, "us-east-1")
+	assert.ErrorIs(t, err, clients.UnsupportedAWSPartitionErr)
+}
+
+func TestPartitionRegion(t *testing.T) {
+	tests := []struct {
+		partition, region, expected string
+		err                         error
+	}{
+		{clients.AWSPartition, "", config.AWS.DefaultRegion, nil},
+		{clients.AWSPartition, "eu-west-1", "eu-west-1", nil},
+		{clients.AWSGovCloudPartition, "", "us-gov-west-1", nil},
+		{clients.AWSGovCloudPartition, "us-gov-east-1", "us-gov-east-1", nil},
+		{clients.AWSChinaPartition, "", "cn-north-1", nil},
+		{clients.AWSGovCloudPartition, "us-east-1", "", clients.AWSPartitionMismatchErr},
+		{clients.AWSPartition, "cn-northwest-1", "", clients.AWSPartitionMismatchErr},
+	}
+	for _, tt := range tests {
+		region, err := partitionRegion(tt.partition, tt.region)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, "%s %s", tt.partition, tt.region)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, region)
+	}
+}
+
+func TestServiceCredentials(t *testing.T) {
+	original := config.AWS.GovCloud
+	defer func() { config.AWS.GovCloud = original }()
+
+	_, err := serviceCredentials(clients.AWSChinaPartition)
+	assert.ErrorIs(t, err, clients.AWSPartitionNotConfiguredErr)
+
+	config.AWS.GovCloud.Key = "key"
+	config.AWS.GovCloud.Secret = "secret"
+	provider, err := serviceCredentials(clients.AWSGovCloudPartition)
+	assert.NoError(t, err)
+	creds, err := provider.Retrieve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "key", creds.AccessKeyID)
+}
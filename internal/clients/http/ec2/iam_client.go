@@ -3,6 +3,7 @@ package ec2
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -11,8 +12,10 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go"
 )
 
 // Statement is a main policy element.
@@ -42,11 +45,13 @@ func expectedStatement() Statement {
 			"iam:GetPolicy",
 			"iam:ListAttachedRolePolicies",
 			"iam:GetRolePolicy",
+			"ec2:CreateFleet",
 			"ec2:CreateKeyPair",
 			"ec2:CreateLaunchTemplate",
 			"ec2:CreateLaunchTemplateVersion",
 			"ec2:CreateTags",
 			"ec2:DeleteKeyPair",
+			"ec2:DeleteLaunchTemplate",
 			"ec2:DeleteTags",
 			"ec2:DescribeAvailabilityZones",
 			"ec2:DescribeImages",
@@ -59,9 +64,12 @@ func expectedStatement() Statement {
 			"ec2:DescribeSecurityGroups",
 			"ec2:DescribeSnapshotAttribute",
 			"ec2:DescribeTags",
+			"ec2:GetConsoleOutput",
 			"ec2:ImportKeyPair",
+			"ec2:RebootInstances",
 			"ec2:RunInstances",
 			"ec2:StartInstances",
+			"ec2:StopInstances",
 			"iam:ListRolePolicies",
 		},
 	}
@@ -275,38 +283,101 @@ func (c *ec2Client) checkInlinePolicies(ctx context.Context, missingPermissions
 }
 
 func (c *ec2Client) CheckPermission(ctx context.Context, auth *clients.Authentication) ([]string, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "CheckPermission", c.region)
+	defer span.End()
+
 	logger := logger(ctx)
 	logger.Debug().Msgf("Listing policies attached to the role")
 
 	logger.Debug().Msgf("Parsing ARN to get a friendly role name")
 	roleName, err := getRoleName(auth.Payload)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("unable to parse ARN: %w", err)
 	}
 
 	attachedRolePolicies, err := c.listAttachedRolePolicies(ctx, roleName)
 	if err != nil {
+		span.Fail(err)
 		return nil, err
 	}
 	policies, err := c.listPoliciesFromAttached(ctx, attachedRolePolicies)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("could not get policies: %w", err)
 	}
 
 	versions, err := c.listPolicyVersions(ctx, policies)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("could not get policy versions: %w", err)
 	}
 
 	statements, err := listStatements(ctx, versions)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("could not list statements: %w", err)
 	}
 
 	missingPermissions := listMissingPermissions(statements, expectedStatement())
 	if len(missingPermissions) != 0 {
-		return c.checkInlinePolicies(ctx, missingPermissions, roleName)
+		missingPermissions, err = c.checkInlinePolicies(ctx, missingPermissions, roleName)
+		if err != nil {
+			span.Fail(err)
+		}
+		return missingPermissions, err
 	}
 
 	return nil, nil
 }
+
+func (c *ec2Client) ValidatePermissions(ctx context.Context, auth *clients.Authentication) ([]clients.PermissionCheck, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "aws", "ValidatePermissions", c.region)
+	defer span.End()
+
+	expected := expectedStatement().Action
+	decisions := make(map[string]bool, len(expected))
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(c.iam, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(auth.Payload),
+		ActionNames:     expected,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDenied" {
+			logger(ctx).Debug().Err(err).Msg("Role cannot simulate its policy, reading policy documents")
+			return c.permissionsFromDocuments(ctx, auth, expected)
+		} else if err != nil {
+			span.Fail(err)
+			return nil, fmt.Errorf("cannot simulate principal policy: %w", err)
+		}
+		for _, result := range output.EvaluationResults {
+			decisions[aws.ToString(result.EvalActionName)] = result.EvalDecision == iamTypes.PolicyEvaluationDecisionTypeAllowed
+		}
+	}
+
+	checks := make([]clients.PermissionCheck, len(expected))
+	for i, action := range expected {
+		checks[i] = clients.PermissionCheck{Permission: action, Allowed: decisions[action]}
+	}
+	return checks, nil
+}
+
+// permissionsFromDocuments returns the checklist based on attached and inline policy documents.
+func (c *ec2Client) permissionsFromDocuments(ctx context.Context, auth *clients.Authentication, expected []string) ([]clients.PermissionCheck, error) {
+	missing, err := c.CheckPermission(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	missingSet := make(map[string]struct{}, len(missing))
+	for _, action := range missing {
+		missingSet[action] = struct{}{}
+	}
+	checks := make([]clients.PermissionCheck, len(expected))
+	for i, action := range expected {
+		_, isMissing := missingSet[action]
+		checks[i] = clients.PermissionCheck{Permission: action, Allowed: !isMissing}
+	}
+	return checks, nil
+}
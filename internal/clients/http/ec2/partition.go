@@ -0,0 +1,43 @@
+package ec2
+
+import (
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// serviceCredentials returns credentials of the service account of the partition, roles can
+// only be assumed by an account of the same partition. Error wrapping AWSPartitionNotConfiguredErr
+// is returned when the partition has no service account configured.
+func serviceCredentials(partition string) (aws.CredentialsProvider, error) {
+	var key, secret string
+	switch partition {
+	case clients.AWSGovCloudPartition:
+		key, secret = secrets.AWSGovCloudKey.Value(), secrets.AWSGovCloudSecret.Value()
+	case clients.AWSChinaPartition:
+		key, secret = secrets.AWSChinaKey.Value(), secrets.AWSChinaSecret.Value()
+	default:
+		return credentials.NewStaticCredentialsProvider(secrets.AWSKey.Value(), secrets.AWSSecret.Value(), secrets.AWSSession.Value()), nil
+	}
+	if key == "" || secret == "" {
+		return nil, fmt.Errorf("%w: %s", clients.AWSPartitionNotConfiguredErr, partition)
+	}
+	return credentials.NewStaticCredentialsProvider(key, secret, ""), nil
+}
+
+// partitionRegion returns region of calls to the partition, blank region means the default region
+// of the partition. Error wrapping AWSPartitionMismatchErr is returned for regions of other
+// partitions. Service endpoints (e.g. STS) are resolved by the SDK from the region.
+func partitionRegion(partition, region string) (string, error) {
+	if region == "" {
+		return clients.AWSPartitionDefaultRegion(partition, config.AWS.DefaultRegion), nil
+	}
+	if clients.AWSPartitionOfRegion(region) != partition {
+		return "", fmt.Errorf("%w: region %s, partition %s", clients.AWSPartitionMismatchErr, region, partition)
+	}
+	return region, nil
+}
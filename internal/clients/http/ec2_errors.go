@@ -8,4 +8,7 @@ var (
 	ServiceAccountUnsupportedOperationErr = errors.New("unsupported operation on service account")
 	ARNParsingError                       = errors.New("ARN parsing error")
 	NoReservationErr                      = errors.New("no reservation has found in AWS response")
+	InstanceTypeNotOfferedErr             = errors.New("instance type is not offered in the region")
+	FleetCapacityErr                      = errors.New("fleet launched no instances of any instance type")
+	ExternalIDRequiredErr                 = errors.New("external ID is required to assume the role")
 )
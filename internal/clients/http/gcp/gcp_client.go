@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -13,10 +15,11 @@ import (
 	compute "cloud.google.com/go/compute/apiv1"
 	"cloud.google.com/go/compute/apiv1/computepb"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/codes"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
+	iam "google.golang.org/api/iam/v1"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -36,10 +39,13 @@ const TraceName = telemetry.TracePrefix + "internal/clients/http/gcp"
 // clients need to be created and closed in each function.
 // The difference between the customer and service authentication is which Project ID was given: the service or the customer
 func newGCPClient(ctx context.Context, auth *clients.Authentication) (clients.GCP, error) {
-	options := []option.ClientOption{
-		option.WithCredentialsJSON([]byte(config.GCP.JSON)),
+	options, err := withHTTPClient(ctx,
+		option.WithCredentialsJSON([]byte(secrets.GCPJSON.Value())),
 		option.WithQuotaProject(auth.Payload),
 		option.WithRequestReason(logging.TraceId(ctx)),
+	)
+	if err != nil {
+		return nil, err
 	}
 	return &gcpClient{
 		auth:    auth,
@@ -53,11 +59,12 @@ func (c *gcpClient) Status(ctx context.Context) error {
 }
 
 func (c *gcpClient) ListAllRegions(ctx context.Context) ([]clients.Region, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListAllRegions")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "ListAllRegions", "")
 	defer span.End()
 
 	client, err := compute.NewRegionsRESTClient(ctx, c.options...)
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("unable to create GCP regions client: %w", err)
 	}
 	defer client.Close()
@@ -76,7 +83,7 @@ func (c *gcpClient) ListAllRegions(ctx context.Context) ([]clients.Region, error
 			break
 		}
 		if err != nil {
-			span.SetStatus(codes.Error, err.Error())
+			span.Fail(err)
 			return nil, fmt.Errorf("iterator error: %w", err)
 		}
 		regions = append(regions, clients.Region(*region.Name))
@@ -101,7 +108,7 @@ func (c *gcpClient) NewInstanceTemplatesClient(ctx context.Context) (*compute.In
 }
 
 func (c *gcpClient) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchTemplate, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListLaunchTemplates")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "ListLaunchTemplates", "")
 	defer span.End()
 
 	logger := logger(ctx)
@@ -110,6 +117,7 @@ func (c *gcpClient) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchT
 	templatesClient, err := c.NewInstanceTemplatesClient(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("Could not get instances client")
+		span.Fail(err)
 		return nil, fmt.Errorf("unable to get instances client: %w", err)
 	}
 	defer templatesClient.Close()
@@ -126,7 +134,7 @@ func (c *gcpClient) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchT
 			break
 		} else if err != nil {
 			logger.Error().Err(err).Msg("An error occurred during listing launch templates")
-			span.SetStatus(codes.Error, err.Error())
+			span.Fail(err)
 			return nil, fmt.Errorf("cannot list launch templates: %w", err)
 		} else {
 			instancesTemplates := pair.Value.InstanceTemplates
@@ -141,7 +149,7 @@ func (c *gcpClient) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchT
 }
 
 func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInstanceParams, amount int64) ([]*string, *string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "InsertInstances")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "InsertInstances", params.Zone)
 	defer span.End()
 
 	logger := logger(ctx)
@@ -150,6 +158,7 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 	client, err := c.newInstancesClient(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("Could not get instances client")
+		span.Fail(err)
 		return nil, nil, fmt.Errorf("unable to get instances client: %w", err)
 	}
 	defer client.Close()
@@ -161,6 +170,7 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 	pk := models.Pubkey{Body: params.KeyBody}
 	pkBody, err := pk.BodyWithUsername(ctx)
 	if err != nil {
+		span.Fail(err)
 		return nil, nil, fmt.Errorf("unable to get pubkey body with username: %w", err)
 	}
 
@@ -185,9 +195,7 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 			Count:       &amount,
 			MinCount:    &amount,
 			InstanceProperties: &computepb.InstanceProperties{
-				Labels: map[string]string{
-					"rhhcc-rid": params.UUID,
-				},
+				Labels: instanceLabels(params.UUID, params.Tags),
 				Disks: []*computepb.AttachedDisk{
 					{
 						InitializeParams: &computepb.AttachedDiskInitializeParams{
@@ -207,7 +215,7 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 								Type: ptr.To("ONE_TO_ONE_NAT"),
 							},
 						},
-						Name: ptr.To("global/networks/default"),
+						Network: ptr.To("global/networks/default"),
 					},
 				},
 				Metadata: &computepb.Metadata{
@@ -217,6 +225,37 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 		},
 	}
 
+	if params.ProvisioningModel == models.GCPProvisioningSpot {
+		// spot VMs cannot be restarted automatically nor live migrated
+		req.BulkInsertInstanceResourceResource.InstanceProperties.Scheduling = &computepb.Scheduling{
+			ProvisioningModel:         ptr.To(models.GCPProvisioningSpot),
+			InstanceTerminationAction: ptr.To(params.TerminationAction),
+			AutomaticRestart:          ptr.To(false),
+			OnHostMaintenance:         ptr.To("TERMINATE"),
+		}
+	}
+
+	if params.Network != "" || params.Subnetwork != "" {
+		// network is inferred from the subnetwork by GCP when not set
+		nic := req.BulkInsertInstanceResourceResource.InstanceProperties.NetworkInterfaces[0]
+		nic.Network = nil
+		if params.Network != "" {
+			nic.Network = ptr.To(params.Network)
+		}
+		if params.Subnetwork != "" {
+			nic.Subnetwork = ptr.To(params.Subnetwork)
+		}
+	}
+
+	if params.ServiceAccount != "" {
+		req.BulkInsertInstanceResourceResource.InstanceProperties.ServiceAccounts = []*computepb.ServiceAccount{
+			{
+				Email:  ptr.To(params.ServiceAccount),
+				Scopes: params.Scopes,
+			},
+		}
+	}
+
 	if params.LaunchTemplateName != "" {
 		template := fmt.Sprintf("global/instanceTemplates/%s", params.LaunchTemplateName)
 		req.BulkInsertInstanceResourceResource.SourceInstanceTemplate = &template
@@ -224,13 +263,13 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 
 	op, err := client.BulkInsert(ctx, req)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		logger.Error().Err(err).Msg("Bulk insert operation failed")
 		return nil, nil, fmt.Errorf("cannot bulk insert instances: %w", err)
 	}
 	if err = op.Wait(ctx); err != nil {
 		logger.Error().Err(err).Msg("Bulk wait operation failed")
-		span.SetStatus(codes.Error, err.Error())
+		span.Fail(err)
 		return nil, nil, fmt.Errorf("cannot bulk insert instances: %w", err)
 	}
 
@@ -240,13 +279,14 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 
 	ids, err := c.ListInstancesIDsByLabel(ctx, params.UUID)
 	if err != nil {
+		span.Fail(err)
 		return nil, nil, fmt.Errorf("cannot list instances ids: %w", err)
 	}
 	return ids, ptr.To(op.Name()), nil
 }
 
 func (c *gcpClient) ListInstancesIDsByLabel(ctx context.Context, uuid string) ([]*string, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListInstancesIDsByLabel")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "ListInstancesIDsByLabel", "")
 	defer span.End()
 
 	logger := logger(ctx)
@@ -260,6 +300,7 @@ func (c *gcpClient) ListInstancesIDsByLabel(ctx context.Context, uuid string) ([
 	client, err := c.newInstancesClient(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("Could not get instances client")
+		span.Fail(err)
 		return nil, fmt.Errorf("unable to get instances client: %w", err)
 	}
 	defer client.Close()
@@ -272,7 +313,7 @@ func (c *gcpClient) ListInstancesIDsByLabel(ctx context.Context, uuid string) ([
 			break
 		} else if err != nil {
 			logger.Error().Err(err).Msg("An error occurred during fetching instance ids")
-			span.SetStatus(codes.Error, err.Error())
+			span.Fail(err)
 			return nil, fmt.Errorf("cannot fetch instance ids: %w", err)
 		} else {
 			instances := pair.Value.Instances
@@ -286,7 +327,7 @@ func (c *gcpClient) ListInstancesIDsByLabel(ctx context.Context, uuid string) ([
 }
 
 func (c *gcpClient) GetInstanceDescriptionByID(ctx context.Context, id, zone string) (*clients.InstanceDescription, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "GetInstanceDescriptionByID")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "GetInstanceDescriptionByID", zone)
 	defer span.End()
 
 	logger := logger(ctx)
@@ -294,6 +335,7 @@ func (c *gcpClient) GetInstanceDescriptionByID(ctx context.Context, id, zone str
 	client, err := c.newInstancesClient(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("Could not get instances client")
+		span.Fail(err)
 		return nil, fmt.Errorf("unable to get instances client: %w", err)
 	}
 	defer client.Close()
@@ -302,15 +344,295 @@ func (c *gcpClient) GetInstanceDescriptionByID(ctx context.Context, id, zone str
 
 	instance, err := client.Get(ctx, &computepb.GetInstanceRequest{Instance: id, Project: projectId, Zone: zone})
 	if err != nil {
+		span.Fail(err)
 		return nil, fmt.Errorf("unable to get instance: %w", err)
 	}
 	instanceId := strconv.FormatUint(instance.GetId(), 10)
-	instanceDesc := clients.InstanceDescription{ID: instanceId}
+	instanceDesc := clients.InstanceDescription{ID: instanceId, State: strings.ToLower(instance.GetStatus())}
+	if len(instance.NetworkInterfaces) > 0 {
+		instanceDesc.PrivateIPv4 = instance.NetworkInterfaces[0].GetNetworkIP()
+	}
 	for _, n := range instance.NetworkInterfaces {
 		if len(n.AccessConfigs) > 0 && n.AccessConfigs[0] != nil {
 			instanceDesc.PublicIPv4 = *n.AccessConfigs[0].NatIP
 			break
 		}
 	}
+	if created, err := time.Parse(time.RFC3339, instance.GetCreationTimestamp()); err == nil {
+		instanceDesc.LaunchTime = &created
+	}
 	return &instanceDesc, nil
 }
+
+// GetConsoleOutput returns the output of the first serial port, GCP keeps the last 1 MB of it.
+func (c *gcpClient) GetConsoleOutput(ctx context.Context, id, zone string) (*clients.ConsoleOutput, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "GetConsoleOutput", zone)
+	defer span.End()
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	output, err := client.GetSerialPortOutput(ctx, &computepb.GetSerialPortOutputInstanceRequest{
+		Instance: id,
+		Project:  c.auth.String(),
+		Zone:     zone,
+		Port:     ptr.To(int32(1)),
+	})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to get serial port output of instance %s: %w", id, err)
+	}
+	return &clients.ConsoleOutput{Output: output.GetContents()}, nil
+}
+
+// StopInstance stops an instance and waits for the operation, stopped instances are not billed
+// except attached disks and static addresses.
+func (c *gcpClient) StopInstance(ctx context.Context, id, zone string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "StopInstance", zone)
+	defer span.End()
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	op, err := client.Stop(ctx, &computepb.StopInstanceRequest{
+		Instance: id,
+		Project:  c.auth.String(),
+		Zone:     zone,
+	})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot stop instance %s: %w", id, err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot stop instance %s: %w", id, err)
+	}
+	return nil
+}
+
+func (c *gcpClient) StartInstance(ctx context.Context, id, zone string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "StartInstance", zone)
+	defer span.End()
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	op, err := client.Start(ctx, &computepb.StartInstanceRequest{
+		Instance: id,
+		Project:  c.auth.String(),
+		Zone:     zone,
+	})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot start instance %s: %w", id, err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot start instance %s: %w", id, err)
+	}
+	return nil
+}
+
+// ResetInstance performs a hard reset of an instance, GCP has no graceful reboot operation.
+func (c *gcpClient) ResetInstance(ctx context.Context, id, zone string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "ResetInstance", zone)
+	defer span.End()
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	op, err := client.Reset(ctx, &computepb.ResetInstanceRequest{
+		Instance: id,
+		Project:  c.auth.String(),
+		Zone:     zone,
+	})
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot reset instance %s: %w", id, err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		span.Fail(err)
+		return fmt.Errorf("cannot reset instance %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetImageArchitecture accepts image names in the "projects/PROJECT/global/images/NAME" or
+// "projects/PROJECT/global/images/family/FAMILY" form, optionally prefixed with the API URL.
+func (c *gcpClient) GetImageArchitecture(ctx context.Context, imageName string) (clients.ArchitectureType, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "GetImageArchitecture", "")
+	defer span.End()
+
+	path := imageName
+	if i := strings.Index(path, "projects/"); i > 0 {
+		path = path[i:]
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) < 5 || parts[0] != "projects" || parts[2] != "global" || parts[3] != "images" {
+		return "", fmt.Errorf("cannot parse image name %s: %w", imageName, http.ImageNotFoundErr)
+	}
+
+	client, err := compute.NewImagesRESTClient(ctx, c.options...)
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("unable to create GCP images client: %w", err)
+	}
+	defer client.Close()
+
+	var image *computepb.Image
+	if len(parts) == 6 && parts[4] == "family" {
+		image, err = client.GetFromFamily(ctx, &computepb.GetFromFamilyImageRequest{Project: parts[1], Family: parts[5]})
+	} else {
+		image, err = client.Get(ctx, &computepb.GetImageRequest{Project: parts[1], Image: parts[4]})
+	}
+	if err != nil {
+		span.Fail(err)
+		if http.IsProviderNotFound(err) {
+			return "", fmt.Errorf("cannot get image %s: %w", imageName, http.ImageNotFoundErr)
+		}
+		return "", fmt.Errorf("cannot get image %s: %w", imageName, err)
+	}
+
+	arch := strings.ToLower(image.GetArchitecture())
+	if arch == "" || arch == "architecture_unspecified" {
+		return "", nil
+	}
+	result, err := clients.MapArchitectures(ctx, arch)
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("unknown architecture of image %s: %w", imageName, err)
+	}
+	return result, nil
+}
+
+func (c *gcpClient) CheckServiceAccount(ctx context.Context, email string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "CheckServiceAccount", "")
+	defer span.End()
+
+	service, err := iam.NewService(ctx, c.options...)
+	if err != nil {
+		span.Fail(err)
+		return fmt.Errorf("unable to create GCP IAM client: %w", err)
+	}
+
+	name := fmt.Sprintf("projects/%s/serviceAccounts/%s", c.auth.Payload, email)
+	account, err := service.Projects.ServiceAccounts.Get(name).Context(ctx).Do()
+	if err != nil {
+		span.Fail(err)
+		if http.IsProviderNotFound(err) {
+			return fmt.Errorf("cannot get service account %s: %w", email, http.ServiceAccountNotFoundErr)
+		}
+		return fmt.Errorf("cannot get service account %s: %w", email, err)
+	}
+
+	if account.Disabled {
+		span.Fail(http.ServiceAccountDisabledErr)
+		return fmt.Errorf("cannot use service account %s: %w", email, http.ServiceAccountDisabledErr)
+	}
+	return nil
+}
+
+func (c *gcpClient) CheckNetwork(ctx context.Context, network, subnetwork string) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "CheckNetwork", "")
+	defer span.End()
+
+	var networkProject, networkName string
+	if network != "" {
+		var region string
+		var err error
+		networkProject, region, networkName, err = c.resourcePath(network, "networks")
+		if err == nil && region != "" {
+			err = fmt.Errorf("%w: network %s is not global", http.UnparsableNetworkResourceErr, network)
+		}
+		if err != nil {
+			span.Fail(err)
+			return err
+		}
+
+		client, err := compute.NewNetworksRESTClient(ctx, c.options...)
+		if err != nil {
+			span.Fail(err)
+			return fmt.Errorf("unable to create GCP networks client: %w", err)
+		}
+		defer client.Close()
+
+		_, err = client.Get(ctx, &computepb.GetNetworkRequest{Project: networkProject, Network: networkName})
+		if err != nil {
+			span.Fail(err)
+			if http.IsProviderNotFound(err) {
+				return fmt.Errorf("cannot get network %s: %w", network, http.NetworkNotFoundErr)
+			}
+			return fmt.Errorf("cannot get network %s: %w", network, err)
+		}
+	}
+
+	if subnetwork != "" {
+		project, region, name, err := c.resourcePath(subnetwork, "subnetworks")
+		if err == nil && region == "" {
+			err = fmt.Errorf("%w: subnetwork %s is not regional", http.UnparsableNetworkResourceErr, subnetwork)
+		}
+		if err != nil {
+			span.Fail(err)
+			return err
+		}
+
+		client, err := compute.NewSubnetworksRESTClient(ctx, c.options...)
+		if err != nil {
+			span.Fail(err)
+			return fmt.Errorf("unable to create GCP subnetworks client: %w", err)
+		}
+		defer client.Close()
+
+		subnet, err := client.Get(ctx, &computepb.GetSubnetworkRequest{Project: project, Region: region, Subnetwork: name})
+		if err != nil {
+			span.Fail(err)
+			if http.IsProviderNotFound(err) {
+				return fmt.Errorf("cannot get subnetwork %s: %w", subnetwork, http.SubnetworkNotFoundErr)
+			}
+			return fmt.Errorf("cannot get subnetwork %s: %w", subnetwork, err)
+		}
+
+		// subnetwork references its network by URL
+		if network != "" && !strings.HasSuffix(subnet.GetNetwork(), fmt.Sprintf("projects/%s/global/networks/%s", networkProject, networkName)) {
+			span.Fail(http.SubnetworkNetworkMismatchErr)
+			return fmt.Errorf("subnetwork %s of network %s: %w", subnetwork, subnet.GetNetwork(), http.SubnetworkNetworkMismatchErr)
+		}
+	}
+
+	return nil
+}
+
+// resourcePath parses "[projects/PROJECT/]global/COLLECTION/NAME" or
+// "[projects/PROJECT/]regions/REGION/COLLECTION/NAME" paths into project, region (empty for
+// global resources) and name. The customer project is used when the path has no project.
+func (c *gcpClient) resourcePath(path, collection string) (string, string, string, error) {
+	project := c.auth.Payload
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 && parts[0] == "projects" {
+		project = parts[1]
+		parts = parts[2:]
+	}
+	switch {
+	case len(parts) == 3 && parts[0] == "global" && parts[1] == collection:
+		return project, "", parts[2], nil
+	case len(parts) == 4 && parts[0] == "regions" && parts[2] == collection:
+		return project, parts[1], parts[3], nil
+	}
+	return "", "", "", fmt.Errorf("%w: %s", http.UnparsableNetworkResourceErr, path)
+}
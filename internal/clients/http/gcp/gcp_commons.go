@@ -2,10 +2,31 @@ package gcp
 
 import (
 	"context"
+	"fmt"
+	stdhttp "net/http"
 
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/rs/zerolog"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
 )
 
 func logger(ctx context.Context) zerolog.Logger {
 	return zerolog.Ctx(ctx).With().Str("client", "gcp").Logger()
 }
+
+// withHTTPClient returns client options with an authenticated HTTP client observing request metrics
+// and trusting GCP_CA_BUNDLE certificates. SDK clients ignore credential options when an HTTP
+// client is given, so the credentials, quota project and request reason of the options are
+// applied by the transport instead.
+func withHTTPClient(ctx context.Context, options ...option.ClientOption) ([]option.ClientOption, error) {
+	options = append(options, option.WithScopes(models.GCPDefaultScope))
+	base := http.NewMetricsTransport(http.TransportFor(ctx, "", config.GCP.CABundle))
+	rt, err := htransport.NewTransport(ctx, base, options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP transport: %w", err)
+	}
+	return []option.ClientOption{option.WithHTTPClient(&stdhttp.Client{Transport: rt})}, nil
+}
@@ -0,0 +1,135 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// launchPermissions are project permissions needed by InsertInstances.
+var launchPermissions = []string{
+	"compute.disks.create",
+	"compute.images.useReadOnly",
+	"compute.instances.create",
+	"compute.instances.setLabels",
+	"compute.instances.setMetadata",
+	"compute.subnetworks.use",
+	"compute.subnetworks.useExternalIp",
+}
+
+// DryRunInstances checks the launch step by step as GCP has no validate-only mode of instance
+// insertion: permissions of the parameters, the machine type in the zone and the CPU quota of
+// the region.
+func (c *gcpClient) DryRunInstances(ctx context.Context, params *clients.GCPInstanceParams, amount int64) (*clients.DryRunResult, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "DryRunInstances", params.Zone)
+	defer span.End()
+
+	permissions := append([]string{}, launchPermissions...)
+	if params.ServiceAccount != "" {
+		permissions = append(permissions, "compute.instances.setServiceAccount", "iam.serviceAccounts.actAs")
+	}
+	if params.LaunchTemplateName != "" {
+		permissions = append(permissions, "compute.instanceTemplates.useReadOnly")
+	}
+
+	service, err := cloudresourcemanager.NewService(ctx, c.options...)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to create GCP resource manager client: %w", err)
+	}
+	req := &cloudresourcemanager.TestIamPermissionsRequest{Permissions: permissions}
+	resp, err := service.Projects.TestIamPermissions(c.auth.Payload, req).Context(ctx).Do()
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot test project permissions: %w", err)
+	}
+	if missing := missingPermissions(permissions, resp.Permissions); len(missing) > 0 {
+		return &clients.DryRunResult{
+			Code:               "PERMISSION_DENIED",
+			Message:            fmt.Sprintf("Required permissions are not granted on project %s.", c.auth.Payload),
+			MissingPermissions: missing,
+		}, nil
+	}
+
+	// machine type is not known when launch template is used
+	if params.MachineType == "" {
+		return &clients.DryRunResult{Passed: true}, nil
+	}
+
+	machineTypesClient, err := compute.NewMachineTypesRESTClient(ctx, c.options...)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to create GCP machine types client: %w", err)
+	}
+	defer machineTypesClient.Close()
+
+	machineType, err := machineTypesClient.Get(ctx, &computepb.GetMachineTypeRequest{
+		Project:     c.auth.Payload,
+		Zone:        params.Zone,
+		MachineType: params.MachineType,
+	})
+	if http.IsProviderNotFound(err) {
+		return &clients.DryRunResult{
+			Code:    "NOT_FOUND",
+			Message: fmt.Sprintf("Machine type %s is not available in zone %s.", params.MachineType, params.Zone),
+		}, nil
+	} else if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot get machine type %s: %w", params.MachineType, err)
+	}
+
+	regionsClient, err := compute.NewRegionsRESTClient(ctx, c.options...)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to create GCP regions client: %w", err)
+	}
+	defer regionsClient.Close()
+
+	region, err := regionsClient.Get(ctx, &computepb.GetRegionRequest{Project: c.auth.Payload, Region: zoneRegion(params.Zone)})
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot get region of zone %s: %w", params.Zone, err)
+	}
+	requested := float64(machineType.GetGuestCpus()) * float64(amount)
+	for _, quota := range region.GetQuotas() {
+		if quota.GetMetric() == "CPUS" && quota.GetUsage()+requested > quota.GetLimit() {
+			return &clients.DryRunResult{
+				Code: "QUOTA_EXCEEDED",
+				Message: fmt.Sprintf("Quota CPUS exceeded in region %s: limit %.0f, usage %.0f, requested %.0f.",
+					region.GetName(), quota.GetLimit(), quota.GetUsage(), requested),
+			}, nil
+		}
+	}
+
+	return &clients.DryRunResult{Passed: true}, nil
+}
+
+// missingPermissions returns required permissions which are not granted.
+func missingPermissions(required, granted []string) []string {
+	grantedSet := make(map[string]struct{}, len(granted))
+	for _, p := range granted {
+		grantedSet[p] = struct{}{}
+	}
+	var missing []string
+	for _, p := range required {
+		if _, ok := grantedSet[p]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// zoneRegion returns region of a zone (e.g. us-east4 for us-east4-a).
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
@@ -0,0 +1,17 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingPermissions(t *testing.T) {
+	assert.Nil(t, missingPermissions([]string{"a", "b"}, []string{"b", "a"}))
+	assert.Equal(t, []string{"b"}, missingPermissions([]string{"a", "b"}, []string{"a"}))
+}
+
+func TestZoneRegion(t *testing.T) {
+	assert.Equal(t, "us-east4", zoneRegion("us-east4-a"))
+	assert.Equal(t, "europe-west1", zoneRegion("europe-west1-b"))
+}
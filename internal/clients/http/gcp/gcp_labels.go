@@ -0,0 +1,44 @@
+package gcp
+
+import "strings"
+
+// maxLabelLength is the maximum length of GCP label keys and values.
+const maxLabelLength = 63
+
+// sanitizeLabel returns a GCP label key or value: lowercase letters, digits, underscores and
+// dashes of at most 63 characters. Other characters are replaced with underscores.
+func sanitizeLabel(value string) string {
+	result := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '_'
+		}
+	}, value)
+	if len(result) > maxLabelLength {
+		result = result[:maxLabelLength]
+	}
+	return result
+}
+
+// instanceLabels returns the reservation UUID label with the sanitized tags, keys must start with
+// a letter so keys starting with other characters are prefixed with "t". Tags with blank keys
+// are skipped.
+func instanceLabels(uuid string, tags map[string]string) map[string]string {
+	labels := make(map[string]string, len(tags)+1)
+	for key, value := range tags {
+		key = sanitizeLabel(key)
+		if key == "" {
+			continue
+		}
+		if key[0] < 'a' || key[0] > 'z' {
+			key = sanitizeLabel("t" + key)
+		}
+		labels[key] = sanitizeLabel(value)
+	}
+	labels["rhhcc-rid"] = uuid
+	return labels
+}
@@ -0,0 +1,24 @@
+package gcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeLabel(t *testing.T) {
+	assert.Equal(t, "cost-center", sanitizeLabel("Cost-Center"))
+	assert.Equal(t, "team_qe_", sanitizeLabel("team.QE!"))
+	assert.Len(t, sanitizeLabel(strings.Repeat("a", 100)), maxLabelLength)
+}
+
+func TestInstanceLabels(t *testing.T) {
+	labels := instanceLabels("uuid", map[string]string{"Team": "QE", "1st": "x", "rhhcc-rid": "other"})
+
+	assert.Equal(t, map[string]string{
+		"team":      "qe",
+		"t1st":      "x",
+		"rhhcc-rid": "uuid",
+	}, labels)
+}
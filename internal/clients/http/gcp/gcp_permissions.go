@@ -0,0 +1,63 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// requiredPermissions are project permissions needed to launch instances and read their state.
+var requiredPermissions = []string{
+	"compute.disks.create",
+	"compute.images.useReadOnly",
+	"compute.instanceTemplates.list",
+	"compute.instanceTemplates.useReadOnly",
+	"compute.instances.create",
+	"compute.instances.get",
+	"compute.instances.getSerialPortOutput",
+	"compute.instances.list",
+	"compute.instances.reset",
+	"compute.instances.setLabels",
+	"compute.instances.setMetadata",
+	"compute.instances.setServiceAccount",
+	"compute.instances.start",
+	"compute.instances.stop",
+	"compute.regions.list",
+	"compute.subnetworks.use",
+	"compute.subnetworks.useExternalIp",
+	"compute.zoneOperations.get",
+	"iam.serviceAccounts.actAs",
+}
+
+func (c *gcpClient) ValidatePermissions(ctx context.Context) ([]clients.PermissionCheck, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "gcp", "ValidatePermissions", "")
+	defer span.End()
+
+	service, err := cloudresourcemanager.NewService(ctx, c.options...)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to create GCP resource manager client: %w", err)
+	}
+
+	req := &cloudresourcemanager.TestIamPermissionsRequest{Permissions: requiredPermissions}
+	resp, err := service.Projects.TestIamPermissions(c.auth.Payload, req).Context(ctx).Do()
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("cannot test project permissions: %w", err)
+	}
+
+	// only granted permissions are returned
+	granted := make(map[string]struct{}, len(resp.Permissions))
+	for _, p := range resp.Permissions {
+		granted[p] = struct{}{}
+	}
+	checks := make([]clients.PermissionCheck, len(requiredPermissions))
+	for i, p := range requiredPermissions {
+		_, ok := granted[p]
+		checks[i] = clients.PermissionCheck{Permission: p, Allowed: ok}
+	}
+	return checks, nil
+}
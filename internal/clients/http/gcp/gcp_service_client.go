@@ -11,6 +11,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -24,9 +25,12 @@ func init() {
 }
 
 func newServiceGCPClient(ctx context.Context) (clients.ServiceGCP, error) {
-	options := []option.ClientOption{
-		option.WithCredentialsJSON([]byte(config.GCP.JSON)),
+	options, err := withHTTPClient(ctx,
+		option.WithCredentialsJSON([]byte(secrets.GCPJSON.Value())),
 		option.WithRequestReason(logging.TraceId(ctx)),
+	)
+	if err != nil {
+		return nil, err
 	}
 	return &gcpServiceClient{
 		options: options,
@@ -130,14 +134,16 @@ func (c *gcpServiceClient) ListMachineTypes(ctx context.Context, zone string) ([
 		if getMachineFamily(*machineType.Name) == "t2a" {
 			arch = clients.ArchitectureTypeArm64
 		}
-		machineTypes = append(machineTypes, &clients.InstanceType{
+		instanceType := &clients.InstanceType{
 			Name:               clients.InstanceTypeName(*machineType.Name),
 			VCPUs:              *machineType.GuestCpus,
 			Cores:              0,
 			Architecture:       arch,
 			MemoryMiB:          mbToMib(float32(*machineType.MemoryMb)),
 			EphemeralStorageGB: getTotalStorage(machineType.ScratchDisks),
-		})
+		}
+		setAccelerators(instanceType, machineType.Accelerators)
+		machineTypes = append(machineTypes, instanceType)
 	}
 	return machineTypes, nil
 }
@@ -152,6 +158,18 @@ func getMachineFamily(machineType string) string {
 	return machineFamily[0]
 }
 
+// setAccelerators sums up all accelerator cards, model is taken from the first accelerator.
+func setAccelerators(it *clients.InstanceType, accelerators []*computepb.Accelerators) {
+	for _, acc := range accelerators {
+		if acc.GuestAcceleratorCount != nil {
+			it.GPUs += *acc.GuestAcceleratorCount
+		}
+		if it.GPUModel == "" && acc.GuestAcceleratorType != nil {
+			it.GPUModel = *acc.GuestAcceleratorType
+		}
+	}
+}
+
 func getTotalStorage(disks []*computepb.ScratchDisks) int64 {
 	var sum int64 = 0
 	for _, disk := range disks {
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+)
+
+var (
+	ServiceAccountNotFoundErr    = fmt.Errorf("service account not found in the project: %w", clients.NotFoundErr)
+	ServiceAccountDisabledErr    = errors.New("service account is disabled")
+	NetworkNotFoundErr           = fmt.Errorf("network not found: %w", clients.NotFoundErr)
+	SubnetworkNotFoundErr        = fmt.Errorf("subnetwork not found: %w", clients.NotFoundErr)
+	SubnetworkNetworkMismatchErr = errors.New("subnetwork does not belong to the network")
+	UnparsableNetworkResourceErr = errors.New("cannot parse network resource path")
+)
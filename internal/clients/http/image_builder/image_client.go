@@ -2,17 +2,20 @@ package image_builder
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/headers"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
-	"go.opentelemetry.io/otel"
 )
 
 const TraceName = telemetry.TracePrefix + "internal/clients/http/image_builder"
@@ -31,7 +34,7 @@ func logger(ctx context.Context) zerolog.Logger {
 
 func newImageBuilderClient(ctx context.Context) (clients.ImageBuilder, error) {
 	c, err := NewClientWithResponses(config.ImageBuilder.URL, func(c *Client) error {
-		c.Client = http.NewPlatformClient(ctx, config.ImageBuilder.Proxy.URL)
+		c.Client = http.NewResilientPlatformClient(ctx, config.ImageBuilder.Proxy.URL, config.ImageBuilder.CABundle)
 		return nil
 	})
 	if err != nil {
@@ -41,19 +44,21 @@ func newImageBuilderClient(ctx context.Context) (clients.ImageBuilder, error) {
 }
 
 func (c *ibClient) Ready(ctx context.Context) error {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "Ready")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "image_builder", "Ready", "")
 	defer span.End()
 
 	logger := logger(ctx)
 	resp, err := c.client.GetReadiness(ctx, headers.AddImageBuilderIdentityHeader, headers.AddEdgeRequestIdHeader)
 	if err != nil {
 		logger.Error().Err(err).Msg("Readiness request failed for image builder")
+		span.Fail(err)
 		return err
 	}
 	defer resp.Body.Close()
 
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode)
+	err = http.HandleHTTPResponses(ctx, "image builder", resp)
 	if err != nil {
+		span.Fail(err)
 		return fmt.Errorf("ready call: %w", err)
 	}
 	return nil
@@ -85,6 +90,126 @@ func (c *ibClient) GetAWSAmi(ctx context.Context, composeID string) (string, err
 	return uploadStatus.Ami, nil
 }
 
+func (c *ibClient) GetAWSAmiInRegion(ctx context.Context, composeID, region string) (string, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "image_builder", "GetAWSAmiInRegion", region)
+	defer span.End()
+	logger := logger(ctx)
+
+	composeUUID, err := uuid.Parse(composeID)
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("compose ID '%s' is not valid UUID: %w", composeID, clients.BadRequestErr)
+	}
+
+	imageStatus, err := c.fetchImageStatus(ctx, composeID)
+	if err != nil {
+		span.Fail(err)
+		return "", err
+	}
+	if imageStatus.Type != UploadTypesAws {
+		return "", fmt.Errorf("%w: expected image type AWS", http.UnknownImageTypeErr)
+	}
+	uploadStatus, err := imageStatus.Options.AsAWSUploadStatus()
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("%w: not an AWS status", http.UploadStatusErr)
+	}
+	if uploadStatus.Region == "" || uploadStatus.Region == region {
+		return uploadStatus.Ami, nil
+	}
+
+	cloneID, err := c.findAWSClone(ctx, composeUUID, region)
+	if err != nil {
+		span.Fail(err)
+		return "", err
+	}
+	if cloneID == "" {
+		logger.Debug().Str("compose_id", composeID).Msgf("Image was uploaded to %s and has no clone in %s", uploadStatus.Region, region)
+		return "", fmt.Errorf("%w: %s", http.ImageNotInRegionErr, region)
+	}
+
+	ami, err := c.GetAWSCloneAmi(ctx, cloneID)
+	if errors.Is(err, http.ImageStatusErr) {
+		// clone is still in progress, it is awaited by the launch job
+		return "", fmt.Errorf("%w: %s", http.ImageNotInRegionErr, region)
+	}
+	return ami, err
+}
+
+func (c *ibClient) CloneAWSImage(ctx context.Context, composeID, region, sourceID string) (string, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "image_builder", "CloneAWSImage", region)
+	defer span.End()
+	logger := logger(ctx)
+
+	composeUUID, err := uuid.Parse(composeID)
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("compose ID '%s' is not valid UUID: %w", composeID, clients.BadRequestErr)
+	}
+
+	cloneID, err := c.findAWSClone(ctx, composeUUID, region)
+	if err != nil {
+		span.Fail(err)
+		return "", err
+	}
+	if cloneID != "" {
+		logger.Debug().Str("compose_id", composeID).Str("clone_id", cloneID).Msgf("Reusing clone into region %s", region)
+		return cloneID, nil
+	}
+
+	var body CloneRequest
+	err = body.FromAWSEC2Clone(AWSEC2Clone{Region: region, ShareWithSources: &[]string{sourceID}})
+	if err != nil {
+		span.Fail(err)
+		return "", fmt.Errorf("unable to create clone request: %w", err)
+	}
+
+	resp, err := c.client.CloneComposeWithResponse(ctx, composeUUID, body, headers.AddImageBuilderIdentityHeader, headers.AddEdgeRequestIdHeader)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to clone compose in image builder")
+		span.Fail(err)
+		return "", fmt.Errorf("cannot clone compose: %w", err)
+	}
+
+	err = http.HandleHTTPResponses(ctx, "image builder", resp.HTTPResponse)
+	if err != nil {
+		if errors.Is(err, clients.NotFoundErr) {
+			return "", fmt.Errorf("clone compose call: %w", http.ComposeNotFoundErr)
+		}
+		return "", fmt.Errorf("clone compose call: %w", err)
+	}
+
+	logger.Info().Str("compose_id", composeID).Str("clone_id", resp.JSON201.Id.String()).
+		Msgf("Triggered clone of compose %s into region %s", composeID, region)
+	return resp.JSON201.Id.String(), nil
+}
+
+func (c *ibClient) GetAWSCloneAmi(ctx context.Context, cloneID string) (string, error) {
+	logger := logger(ctx)
+	logger.Trace().Str("clone_id", cloneID).Msgf("Getting AMI of clone ID %v", cloneID)
+
+	status, err := c.getCloneStatus(ctx, cloneID)
+	if err != nil {
+		return "", err
+	}
+
+	switch status.Status {
+	case UploadStatusStatusSuccess:
+	case UploadStatusStatusFailure:
+		return "", http.CloneFailedErr
+	case UploadStatusStatusPending, UploadStatusStatusRunning:
+		fallthrough
+	default:
+		return "", http.ImageStatusErr
+	}
+
+	uploadStatus, err := status.Options.AsAWSUploadStatus()
+	if err != nil {
+		return "", fmt.Errorf("%w: not an AWS status", http.UploadStatusErr)
+	}
+	return uploadStatus.Ami, nil
+}
+
 func (c *ibClient) GetAzureImageID(ctx context.Context, composeID string) (string, error) {
 	logger := logger(ctx)
 	logger.Trace().Msgf("Getting Azure ID of image %v", composeID)
@@ -139,8 +264,107 @@ func (c *ibClient) GetGCPImageName(ctx context.Context, composeID string) (strin
 	return result, nil
 }
 
+// composesPageSize is the amount of composes fetched per request, it is the image builder maximum
+const composesPageSize = 100
+
+func (c *ibClient) ListImages(ctx context.Context) ([]*clients.Image, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "image_builder", "ListImages", "")
+	defer span.End()
+	logger := logger(ctx)
+
+	result := make([]*clients.Image, 0)
+	for offset := 0; ; offset += composesPageSize {
+		resp, err := c.client.GetComposesWithResponse(ctx, &GetComposesParams{Limit: ptr.To(composesPageSize), Offset: ptr.To(offset)},
+			headers.AddImageBuilderIdentityHeader, headers.AddEdgeRequestIdHeader)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to fetch composes from image builder")
+			span.Fail(err)
+			return nil, fmt.Errorf("cannot list composes: %w", err)
+		}
+
+		err = http.HandleHTTPResponses(ctx, "image builder", resp.HTTPResponse)
+		if err != nil {
+			span.Fail(err)
+			return nil, fmt.Errorf("list composes call: %w", err)
+		}
+
+		for _, item := range resp.JSON200.Data {
+			image, imageErr := newImage(ctx, item)
+			if imageErr != nil {
+				logger.Trace().Err(imageErr).Str("compose_id", item.Id.String()).Msg("Skipping compose which is not a cloud image")
+				continue
+			}
+			result = append(result, image)
+		}
+
+		if len(resp.JSON200.Data) < composesPageSize || offset+len(resp.JSON200.Data) >= resp.JSON200.Meta.Count {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// newImage converts compose list item, only composes uploaded to a supported cloud provider
+// are converted.
+func newImage(ctx context.Context, item ComposesResponseItem) (*clients.Image, error) {
+	// compose request is not typed in the image builder OpenAPI, convert it via JSON
+	buf, err := json.Marshal(item.Request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal compose request: %w", err)
+	}
+	var request ComposeRequest
+	if err = json.Unmarshal(buf, &request); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal compose request: %w", err)
+	}
+	if len(request.ImageRequests) < 1 {
+		return nil, http.ImageRequestNotFoundErr
+	}
+	imageRequest := request.ImageRequests[0]
+
+	var provider models.ProviderType
+	switch imageRequest.UploadRequest.Type {
+	case UploadTypesAws:
+		provider = models.ProviderTypeAWS
+	case UploadTypesAzure:
+		provider = models.ProviderTypeAzure
+	case UploadTypesGcp:
+		provider = models.ProviderTypeGCP
+	case UploadTypesAwsS3:
+		fallthrough
+	default:
+		// images uploaded to S3 cannot be launched
+		return nil, fmt.Errorf("%w: %s", http.UnknownImageTypeErr, imageRequest.UploadRequest.Type)
+	}
+
+	arch, err := clients.MapArchitectures(ctx, string(imageRequest.Architecture))
+	if err != nil {
+		return nil, fmt.Errorf("unable to map image architecture: %w", err)
+	}
+
+	return &clients.Image{
+		ID:           item.Id.String(),
+		Name:         ptr.From(item.ImageName),
+		Distribution: string(request.Distribution),
+		Architecture: arch,
+		Provider:     provider,
+		CreatedAt:    parseCreatedAt(item.CreatedAt),
+	}, nil
+}
+
+// parseCreatedAt parses compose creation time which is either RFC 3339 or Go time.String
+// format, zero time is returned when it cannot be parsed.
+func parseCreatedAt(value string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 func (c *ibClient) fetchImageStatus(ctx context.Context, composeID string) (*UploadStatus, error) {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "fetchImageStatus")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "image_builder", "fetchImageStatus", "")
 	defer span.End()
 	logger := logger(ctx)
 	logger.Trace().Msgf("Fetching image status %v", composeID)
@@ -149,6 +373,7 @@ func (c *ibClient) fetchImageStatus(ctx context.Context, composeID string) (*Upl
 	if err != nil {
 		cloneResp, err := c.checkClone(ctx, composeID)
 		if err != nil {
+			span.Fail(err)
 			return nil, fmt.Errorf("could not find image neither in compose nor in clones: %w", err)
 		}
 		return cloneResp, nil
@@ -170,7 +395,7 @@ func (c *ibClient) getComposeStatus(ctx context.Context, composeID string) (*Com
 		return nil, fmt.Errorf("cannot get compose status: %w", err)
 	}
 
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode())
+	err = http.HandleHTTPResponses(ctx, "image builder", resp.HTTPResponse)
 	if err != nil {
 		if errors.Is(err, clients.NotFoundErr) {
 			return nil, fmt.Errorf("fetch image status call: %w", http.ComposeNotFoundErr)
@@ -202,18 +427,34 @@ func (c *ibClient) checkClone(ctx context.Context, composeID string) (*UploadSta
 	logger := logger(ctx)
 	logger.Trace().Msgf("Fetching image status %v from clones", composeID)
 
-	composeUUID, err := uuid.Parse(composeID)
+	status, err := c.getCloneStatus(ctx, composeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ImageStatusStatus(status.Status) != ImageStatusStatusSuccess {
+		logger.Warn().Msg("Clone status is not ready")
+		return nil, http.ImageStatusErr
+	}
+
+	return status, nil
+}
+
+func (c *ibClient) getCloneStatus(ctx context.Context, cloneID string) (*UploadStatus, error) {
+	logger := logger(ctx)
+
+	cloneUUID, err := uuid.Parse(cloneID)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse UUID: %w", err)
 	}
 
-	resp, err := c.client.GetCloneStatusWithResponse(ctx, composeUUID, headers.AddImageBuilderIdentityHeader, headers.AddEdgeRequestIdHeader)
+	resp, err := c.client.GetCloneStatusWithResponse(ctx, cloneUUID, headers.AddImageBuilderIdentityHeader, headers.AddEdgeRequestIdHeader)
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to fetch image status from image builder")
 		return nil, fmt.Errorf("cannot get compose status: %w", err)
 	}
 
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode())
+	err = http.HandleHTTPResponses(ctx, "image builder", resp.HTTPResponse)
 	if err != nil {
 		if errors.Is(err, clients.NotFoundErr) {
 			return nil, fmt.Errorf("fetch image status call: %w", http.CloneNotFoundErr)
@@ -221,10 +462,53 @@ func (c *ibClient) checkClone(ctx context.Context, composeID string) (*UploadSta
 		return nil, fmt.Errorf("fetch image status call: %w", err)
 	}
 
-	if ImageStatusStatus(resp.JSON200.Status) != ImageStatusStatusSuccess {
-		logger.Warn().Msg("Clone status is not ready")
-		return nil, http.ImageStatusErr
-	}
-
 	return resp.JSON200, nil
 }
+
+// findAWSClone returns identifier of a clone of the compose into the region which did not fail,
+// empty string is returned when there is no such clone.
+func (c *ibClient) findAWSClone(ctx context.Context, composeUUID uuid.UUID, region string) (string, error) {
+	logger := logger(ctx)
+
+	for offset := 0; ; offset += composesPageSize {
+		resp, err := c.client.GetComposeClonesWithResponse(ctx, composeUUID, &GetComposeClonesParams{Limit: ptr.To(composesPageSize), Offset: ptr.To(offset)},
+			headers.AddImageBuilderIdentityHeader, headers.AddEdgeRequestIdHeader)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to fetch compose clones from image builder")
+			return "", fmt.Errorf("cannot list compose clones: %w", err)
+		}
+
+		err = http.HandleHTTPResponses(ctx, "image builder", resp.HTTPResponse)
+		if err != nil {
+			if errors.Is(err, clients.NotFoundErr) {
+				return "", fmt.Errorf("list compose clones call: %w", http.ComposeNotFoundErr)
+			}
+			return "", fmt.Errorf("list compose clones call: %w", err)
+		}
+
+		for _, item := range resp.JSON200.Data {
+			// clone request is not typed in the image builder OpenAPI, convert it via JSON
+			buf, marshalErr := json.Marshal(item.Request)
+			if marshalErr != nil {
+				return "", fmt.Errorf("unable to marshal clone request: %w", marshalErr)
+			}
+			var request AWSEC2Clone
+			if marshalErr = json.Unmarshal(buf, &request); marshalErr != nil || request.Region != region {
+				continue
+			}
+
+			status, statusErr := c.getCloneStatus(ctx, item.Id.String())
+			if statusErr != nil {
+				return "", statusErr
+			}
+			if status.Status != UploadStatusStatusFailure {
+				return item.Id.String(), nil
+			}
+			logger.Debug().Str("clone_id", item.Id.String()).Msgf("Skipping failed clone into region %s", region)
+		}
+
+		if len(resp.JSON200.Data) < composesPageSize || offset+len(resp.JSON200.Data) >= resp.JSON200.Meta.Count {
+			return "", nil
+		}
+	}
+}
@@ -9,8 +9,10 @@ import (
 
 var (
 	CloneNotFoundErr        = fmt.Errorf("image clone not found: %w", clients.NotFoundErr)
+	CloneFailedErr          = errors.New("clone of requested image failed")
 	ComposeNotFoundErr      = fmt.Errorf("image compose not found: %w", clients.NotFoundErr)
 	ImageStatusErr          = errors.New("build of requested image has not finished yet")
+	ImageNotInRegionErr     = errors.New("requested image is not available in the region")
 	UnknownImageTypeErr     = errors.New("unknown image type")
 	UploadStatusErr         = fmt.Errorf("could not fetch upload status: %w", clients.NotFoundErr)
 	ImageRequestNotFoundErr = errors.New("image upload request not found in the Compose Request")
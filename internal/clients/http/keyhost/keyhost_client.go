@@ -0,0 +1,111 @@
+package keyhost
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/rs/zerolog"
+)
+
+const TraceName = telemetry.TracePrefix + "internal/clients/http/keyhost"
+
+// maximum size of the keys response, users rarely publish more than few keys
+const maxResponseSize = 64 * 1024
+
+// GitHub and GitLab usernames, GitLab also allows dots and underscores
+var usernameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,254}$`)
+
+type keyHostClient struct {
+	client httpClients.HttpRequestDoer
+}
+
+func init() {
+	clients.GetKeyHostClient = newKeyHostClient
+}
+
+func logger(ctx context.Context) zerolog.Logger {
+	return zerolog.Ctx(ctx).With().Str("client", "keyhost").Logger()
+}
+
+func newKeyHostClient(ctx context.Context) (clients.KeyHost, error) {
+	return &keyHostClient{
+		client: httpClients.NewPlatformClient(ctx, config.KeyHosts.Proxy.URL, config.KeyHosts.CABundle),
+	}, nil
+}
+
+func siteURL(site clients.KeyHostSite) (string, error) {
+	switch site {
+	case clients.KeyHostGitHub:
+		return config.KeyHosts.GitHubURL, nil
+	case clients.KeyHostGitLab:
+		return config.KeyHosts.GitLabURL, nil
+	default:
+		return "", fmt.Errorf("%w: %s", clients.UnknownKeyHostErr, site)
+	}
+}
+
+// ListUserKeys fetches "https://site/username.keys" which is supported by both GitHub and GitLab.
+func (c *keyHostClient) ListUserKeys(ctx context.Context, site clients.KeyHostSite, username string) ([]string, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "keyhost", "ListUserKeys", "")
+	defer span.End()
+
+	logger := logger(ctx)
+	if !usernameRegexp.MatchString(username) {
+		return nil, fmt.Errorf("%w: %s", clients.InvalidKeyHostUserErr, username)
+	}
+
+	baseURL, err := siteURL(site)
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+	keysURL, err := url.JoinPath(baseURL, username+".keys")
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to build %s URL: %w", site, err)
+	}
+
+	logger.Trace().Str("url", keysURL).Msgf("Fetching keys of %s user %s", site, username)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keysURL, nil)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("unable to fetch keys from %s: %w", site, err)
+	}
+	defer resp.Body.Close()
+
+	err = httpClients.HandleHTTPResponses(ctx, "key host", resp)
+	if err != nil {
+		span.Fail(err)
+		return nil, fmt.Errorf("fetch keys of %s user %s: %w", site, username, err)
+	}
+
+	var keys []string
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxResponseSize))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			keys = append(keys, line)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read keys from %s: %w", site, err)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// MetricsTransport observes duration of outbound HTTP requests and logs them. Requests are
+// labelled by the dependency and operation of the client span in the request context (see
+// telemetry.StartClientSpan), the host and "unknown" are used for requests made outside of
+// client spans. Status label is the status class (e.g. "2xx") or "error".
+type MetricsTransport struct {
+	transport http.RoundTripper
+}
+
+// NewMetricsTransport wraps the transport, nil means http.DefaultTransport.
+func NewMetricsTransport(transport http.RoundTripper) *MetricsTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &MetricsTransport{transport: transport}
+}
+
+func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dependency, operation := telemetry.ClientCall(req.Context())
+	if dependency == "" {
+		dependency = req.URL.Host
+	}
+	if operation == "" {
+		operation = "unknown"
+	}
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	duration := time.Since(start)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode/100) + "xx"
+	}
+	metrics.ObserveOutboundRequestDuration(dependency, operation, status, duration)
+
+	logger := zerolog.Ctx(req.Context())
+	event := logger.Debug()
+	if err != nil || resp.StatusCode >= 500 {
+		event = logger.Warn()
+	}
+	event = event.Str("dependency", dependency).
+		Str("operation", operation).
+		Str("method", req.Method).
+		Str("host", req.URL.Host).
+		Dur("duration", duration).
+		Str("status", status)
+	if err != nil {
+		event.Err(err).Msgf("Outbound request %s %s failed", dependency, operation)
+	} else {
+		event.Int("status_code", resp.StatusCode).Msgf("Outbound request %s %s finished with %d", dependency, operation, resp.StatusCode)
+	}
+
+	return resp, err //nolint:wrapcheck
+}
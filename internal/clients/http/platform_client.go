@@ -3,35 +3,31 @@ package http
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
-	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-// Shared HTTP transport for all platform clients to utilize connection caching
-var transport = &http.Transport{}
-
-// NewPlatformClient returns new HTTP client (doer) with W3C Trace Context, logging tracing
-// and/or HTTP proxy (non-clowder environment only) according to application configuration.
+// NewPlatformClient returns new HTTP client (doer) with W3C Trace Context, request metrics, logging tracing,
+// HTTP proxy (non-clowder environment only) and additional CA certificates according to application
+// configuration. Blank proxy and CA bundle use the defaults, see TransportFor.
 // Use this function to create HTTP clients for communication with all platform services.
-func NewPlatformClient(ctx context.Context, proxy string) HttpRequestDoer {
-	var rt http.RoundTripper = transport
+func NewPlatformClient(ctx context.Context, proxy, caBundle string) HttpRequestDoer {
+	return newPlatformClient(ctx, proxy, caBundle, 0)
+}
 
-	if proxy != "" {
-		if config.InClowder() {
-			zerolog.Ctx(ctx).Warn().Msgf("Unable to use HTTP client proxy in clowder environment: %s", proxy)
-		} else {
-			zerolog.Ctx(ctx).Warn().Msgf("Creating HTTP client with proxy %s", proxy)
-			rt = &http.Transport{Proxy: http.ProxyURL(config.StringToURL(proxy))}
-		}
-	}
+// newPlatformClient returns platform client with timeout of requests including reading of
+// the response body, zero means no timeout.
+func newPlatformClient(ctx context.Context, proxy, caBundle string, timeout time.Duration) HttpRequestDoer {
+	var rt http.RoundTripper = TransportFor(ctx, proxy, caBundle)
+	rt = NewMetricsTransport(rt)
 
 	if config.Telemetry.Enabled {
 		rt = otelhttp.NewTransport(rt)
 	}
 
-	var doer HttpRequestDoer = &http.Client{Transport: rt}
+	var doer HttpRequestDoer = &http.Client{Transport: rt, Timeout: timeout}
 	if config.RestEndpoints.TraceData {
 		doer = NewLoggingDoer(ctx, doer)
 	}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"google.golang.org/api/googleapi"
+)
+
+var (
+	ProviderErr                     = errors.New("cloud provider API error")
+	ProviderUnauthorizedErr         = fmt.Errorf("%w: operation not permitted in cloud provider account", ProviderErr)
+	ProviderQuotaExceededErr        = fmt.Errorf("%w: cloud provider account quota or limit exceeded", ProviderErr)
+	ProviderUnsupportedOperationErr = fmt.Errorf("%w: operation not supported by cloud provider", ProviderErr)
+	ImageNotFoundErr                = fmt.Errorf("image not found in cloud provider account: %w", clients.NotFoundErr)
+)
+
+var awsErrorCodes = map[string]error{
+	"UnauthorizedOperation":        ProviderUnauthorizedErr,
+	"AuthFailure":                  ProviderUnauthorizedErr,
+	"InstanceLimitExceeded":        ProviderQuotaExceededErr,
+	"VcpuLimitExceeded":            ProviderQuotaExceededErr,
+	"MaxSpotInstanceCountExceeded": ProviderQuotaExceededErr,
+	"UnsupportedOperation":         ProviderUnsupportedOperationErr,
+	"Unsupported":                  ProviderUnsupportedOperationErr,
+}
+
+var azureErrorCodes = map[string]error{
+	"AuthorizationFailed":       ProviderUnauthorizedErr,
+	"LinkedAuthorizationFailed": ProviderUnauthorizedErr,
+	"QuotaExceeded":             ProviderQuotaExceededErr,
+	"OperationNotAllowed":       ProviderQuotaExceededErr,
+	"SkuNotAvailable":           ProviderUnsupportedOperationErr,
+	"OperationNotSupported":     ProviderUnsupportedOperationErr,
+}
+
+// GCP reasons are compared case-insensitively, both "quotaExceeded" and "QUOTA_EXCEEDED" styles are used
+var gcpErrorReasons = map[string]error{
+	"forbidden":               ProviderUnauthorizedErr,
+	"insufficientpermissions": ProviderUnauthorizedErr,
+	"quotaexceeded":           ProviderQuotaExceededErr,
+	"quota_exceeded":          ProviderQuotaExceededErr,
+	"unsupportedoperation":    ProviderUnsupportedOperationErr,
+	"unsupported_operation":   ProviderUnsupportedOperationErr,
+}
+
+// AsProviderError returns clients.ClientError for AWS, Azure or GCP SDK error anywhere in the
+// chain or nil for other errors. The kind is one of ProviderUnauthorizedErr,
+// ProviderQuotaExceededErr or ProviderUnsupportedOperationErr for known error codes or
+// ProviderErr for all other SDK errors.
+func AsProviderError(err error) *clients.ClientError {
+	var awsErr smithy.APIError
+	if errors.As(err, &awsErr) {
+		result := newProviderError("AWS", awsErrorCodes[awsErr.ErrorCode()], err)
+		var responseErr *awshttp.ResponseError
+		if errors.As(err, &responseErr) {
+			result.Status = responseErr.HTTPStatusCode()
+			result.RequestID = responseErr.ServiceRequestID()
+		}
+		return result
+	}
+
+	var azureErr *azcore.ResponseError
+	if errors.As(err, &azureErr) {
+		result := newProviderError("Azure", azureErrorCodes[azureErr.ErrorCode], err)
+		result.Status = azureErr.StatusCode
+		if azureErr.RawResponse != nil {
+			result.RequestID = azureErr.RawResponse.Header.Get("X-Ms-Request-Id")
+		}
+		return result
+	}
+
+	var gcpErr *googleapi.Error
+	if errors.As(err, &gcpErr) {
+		var kind error
+		for _, item := range gcpErr.Errors {
+			if k, ok := gcpErrorReasons[strings.ToLower(item.Reason)]; ok {
+				kind = k
+				break
+			}
+		}
+		if kind == nil && gcpErr.Code == 403 {
+			kind = ProviderUnauthorizedErr
+		}
+		result := newProviderError("GCP", kind, err)
+		result.Status = gcpErr.Code
+		return result
+	}
+
+	return nil
+}
+
+// IsProviderNotFound returns true when AWS, Azure or GCP SDK error anywhere in the chain
+// reports a missing or malformed resource.
+func IsProviderNotFound(err error) bool {
+	var awsErr smithy.APIError
+	if errors.As(err, &awsErr) {
+		return strings.HasSuffix(awsErr.ErrorCode(), ".NotFound") || strings.HasSuffix(awsErr.ErrorCode(), ".Malformed")
+	}
+
+	var azureErr *azcore.ResponseError
+	if errors.As(err, &azureErr) {
+		return IsHTTPNotFound(azureErr.StatusCode)
+	}
+
+	var gcpErr *googleapi.Error
+	if errors.As(err, &gcpErr) {
+		return IsHTTPNotFound(gcpErr.Code)
+	}
+
+	return false
+}
+
+func newProviderError(provider string, kind error, err error) *clients.ClientError {
+	if kind == nil {
+		kind = ProviderErr
+	}
+	return &clients.ClientError{Kind: kind, Provider: provider, Err: err}
+}
@@ -0,0 +1,98 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+func TestAsProviderError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		provider string
+		kind     error
+	}{
+		{"aws unauthorized", &smithy.GenericAPIError{Code: "UnauthorizedOperation"}, "AWS", httpClients.ProviderUnauthorizedErr},
+		{"aws vcpu limit", &smithy.OperationError{ServiceID: "EC2", Err: &smithy.GenericAPIError{Code: "VcpuLimitExceeded"}}, "AWS", httpClients.ProviderQuotaExceededErr},
+		{"aws unknown", &smithy.GenericAPIError{Code: "InvalidAMIID.Malformed"}, "AWS", httpClients.ProviderErr},
+		{"azure quota", &azcore.ResponseError{ErrorCode: "QuotaExceeded"}, "Azure", httpClients.ProviderQuotaExceededErr},
+		{"azure sku", &azcore.ResponseError{ErrorCode: "SkuNotAvailable"}, "Azure", httpClients.ProviderUnsupportedOperationErr},
+		{"gcp quota", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "QUOTA_EXCEEDED"}}}, "GCP", httpClients.ProviderQuotaExceededErr},
+		{"gcp forbidden", &googleapi.Error{Code: 403}, "GCP", httpClients.ProviderUnauthorizedErr},
+		{"gcp not found", &googleapi.Error{Code: 404}, "GCP", httpClients.ProviderErr},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", tc.err)
+			providerErr := httpClients.AsProviderError(err)
+			require.NotNil(t, providerErr)
+			assert.Equal(t, tc.provider, providerErr.Provider)
+			assert.Equal(t, tc.kind, providerErr.Kind)
+			assert.ErrorIs(t, providerErr, httpClients.ProviderErr)
+			assert.ErrorIs(t, providerErr, tc.err, "SDK error must stay in the chain")
+		})
+	}
+
+	assert.Nil(t, httpClients.AsProviderError(errors.New("other")))
+}
+
+func TestAsProviderErrorAzureRequestID(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ms-request-id", "req-1")
+	err := &azcore.ResponseError{ErrorCode: "QuotaExceeded", StatusCode: 409, RawResponse: &http.Response{Header: header}}
+
+	providerErr := httpClients.AsProviderError(err)
+	require.NotNil(t, providerErr)
+	assert.Equal(t, 409, providerErr.Status)
+	assert.Equal(t, "req-1", providerErr.RequestID)
+}
+
+func TestMapAzureStatusErrors(t *testing.T) {
+	err := httpClients.MapAzureTokenError(errors.New("AADSTS7000222: The provided client secret keys for app '1234' are expired."))
+	require.ErrorIs(t, err, httpClients.AzureSecretExpiredErr)
+
+	err = httpClients.MapAzureTokenError(errors.New("AADSTS7000215: Invalid client secret provided."))
+	require.ErrorIs(t, err, httpClients.AzureCredentialsInvalidErr)
+
+	unknown := errors.New("connection refused")
+	assert.Equal(t, unknown, httpClients.MapAzureTokenError(unknown))
+
+	err = httpClients.MapAzureSubscriptionError(fmt.Errorf("wrapped: %w", &azcore.ResponseError{ErrorCode: "SubscriptionNotFound", StatusCode: 404}))
+	require.ErrorIs(t, err, httpClients.AzureRoleAssignmentMissingErr)
+
+	err = httpClients.MapAzureSubscriptionError(&azcore.ResponseError{ErrorCode: "InternalServerError", StatusCode: 500})
+	assert.NotErrorIs(t, err, httpClients.AzureRoleAssignmentMissingErr)
+}
+
+func TestHandleHTTPResponses(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, httpClients.HandleHTTPResponses(ctx, "sources", &http.Response{StatusCode: 204}))
+
+	header := http.Header{}
+	header.Set("X-Rh-Insights-Request-Id", "req-2")
+	err := httpClients.HandleHTTPResponses(ctx, "sources", &http.Response{StatusCode: 404, Header: header})
+	require.ErrorIs(t, err, clients.NotFoundErr)
+	require.ErrorIs(t, err, clients.HttpClientErr)
+	assert.NotErrorIs(t, err, clients.ForbiddenErr)
+
+	var clientErr *clients.ClientError
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, "sources", clientErr.Provider)
+	assert.Equal(t, 404, clientErr.Status)
+	assert.Equal(t, "req-2", clientErr.RequestID)
+
+	err = httpClients.HandleHTTPResponses(ctx, "sources", nil)
+	require.ErrorIs(t, err, clients.Non2xxResponseErr)
+}
@@ -0,0 +1,151 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/headers"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/rs/zerolog"
+)
+
+const TraceName = telemetry.TracePrefix + "internal/clients/http/rbac"
+
+// application is the RBAC application of all provisioning permissions
+const application = "provisioning"
+
+// pageLimit is the maximum page size of the RBAC access endpoint
+const pageLimit = 1000
+
+type rbacClient struct {
+	client httpClients.HttpRequestDoer
+}
+
+func init() {
+	clients.GetRBACClient = newRBACClient
+}
+
+func logger(ctx context.Context) zerolog.Logger {
+	return zerolog.Ctx(ctx).With().Str("client", "rbac").Logger()
+}
+
+func newRBACClient(ctx context.Context) (clients.RBAC, error) {
+	return &rbacClient{
+		client: httpClients.NewPlatformClient(ctx, config.RBAC.Proxy.URL, config.RBAC.CABundle),
+	}, nil
+}
+
+type accessPage struct {
+	Meta struct {
+		Count int `json:"count"`
+	} `json:"meta"`
+	Data []struct {
+		Permission string `json:"permission"`
+	} `json:"data"`
+}
+
+// GetPrincipalAccess returns permissions of the principal, they are cached for RBAC_CACHE_TTL
+// when application cache is enabled.
+func (c *rbacClient) GetPrincipalAccess(ctx context.Context) (*clients.AccessList, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "rbac", "GetPrincipalAccess", "")
+	defer span.End()
+
+	access, err := cachedAccess(ctx, c.loadAccess)
+	if err != nil {
+		span.Fail(err)
+	}
+	return access, err
+}
+
+// cachedAccess returns the access list from the application cache or loads it.
+func cachedAccess(ctx context.Context, load func(context.Context) (*clients.AccessList, error)) (*clients.AccessList, error) {
+	logger := logger(ctx)
+	if config.RBAC.CacheTTL <= 0 {
+		return load(ctx)
+	}
+
+	key := identity.OrgIdOrEmpty(ctx) + "/" + identity.PrincipalName(ctx)
+	access := &clients.AccessList{}
+	err := cache.Find(ctx, key, access)
+	if err == nil {
+		return access, nil
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		logger.Warn().Err(err).Msg("Unable to find RBAC access in cache")
+	}
+
+	access, err = load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.SetExpires(ctx, key, access, config.RBAC.CacheTTL)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to store RBAC access in cache")
+	}
+	return access, nil
+}
+
+func (c *rbacClient) loadAccess(ctx context.Context) (*clients.AccessList, error) {
+	access := &clients.AccessList{Permissions: []string{}}
+	for offset := 0; ; offset += pageLimit {
+		page, err := c.fetchPage(ctx, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Data {
+			access.Permissions = append(access.Permissions, item.Permission)
+		}
+		if len(page.Data) == 0 || offset+pageLimit >= page.Meta.Count {
+			break
+		}
+	}
+
+	logger := logger(ctx)
+	logger.Trace().Strs("permissions", access.Permissions).Msg("Fetched RBAC access")
+	return access, nil
+}
+
+func (c *rbacClient) fetchPage(ctx context.Context, offset int) (*accessPage, error) {
+	query := url.Values{}
+	query.Set("application", application)
+	query.Set("limit", strconv.Itoa(pageLimit))
+	query.Set("offset", strconv.Itoa(offset))
+	accessURL, err := url.JoinPath(config.RBAC.URL, "access/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to build RBAC URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, accessURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	_ = headers.AddRBACIdentityHeader(ctx, req)
+	_ = headers.AddEdgeRequestIdHeader(ctx, req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch RBAC access: %w", err)
+	}
+	defer resp.Body.Close()
+
+	err = httpClients.HandleHTTPResponses(ctx, "rbac", resp)
+	if err != nil {
+		return nil, fmt.Errorf("get RBAC access: %w", err)
+	}
+
+	var page accessPage
+	if err = json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("unable to decode RBAC access: %w", err)
+	}
+	return &page, nil
+}
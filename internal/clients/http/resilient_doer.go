@@ -0,0 +1,187 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+// CircuitOpenErr is returned without performing the request when the host failed repeatedly.
+var CircuitOpenErr = errors.New("circuit open for backend service host")
+
+// circuitBreaker tracks consecutive failures of a host. The circuit opens when failures reach
+// the threshold, after the cooldown a single trial request is let through which closes the
+// circuit on success or opens it again on failure.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// allow returns true when a request to the host can be performed.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	threshold := config.RestEndpoints.Resilience.BreakerThreshold
+	if threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// record stores result of a request and returns true when the circuit has been opened by it.
+func (b *circuitBreaker) record(failed bool, now time.Time) bool {
+	threshold := config.RestEndpoints.Resilience.BreakerThreshold
+	if threshold <= 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	trial := b.trial
+	b.trial = false
+	if !failed {
+		b.failures = 0
+		return false
+	}
+
+	b.failures++
+	if b.failures == threshold || (trial && b.failures > threshold) {
+		b.openUntil = now.Add(config.RestEndpoints.Resilience.BreakerCooldown)
+		return true
+	}
+	return false
+}
+
+// circuit breakers of all hosts shared by all resilient clients
+var (
+	breakers      = make(map[string]*circuitBreaker)
+	breakersMutex sync.Mutex
+)
+
+func breakerFor(host string) *circuitBreaker {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	b, ok := breakers[host]
+	if !ok {
+		b = &circuitBreaker{}
+		breakers[host] = b
+	}
+	return b
+}
+
+// ResilientDoer retries idempotent requests failed with 5xx status or a timeout using exponential
+// backoff with full jitter, and rejects requests to hosts with an open circuit.
+type ResilientDoer struct {
+	doer HttpRequestDoer
+}
+
+func NewResilientDoer(doer HttpRequestDoer) *ResilientDoer {
+	return &ResilientDoer{doer: doer}
+}
+
+// NewResilientPlatformClient returns platform client (see NewPlatformClient) with request timeout,
+// retries and circuit breaking according to application configuration.
+func NewResilientPlatformClient(ctx context.Context, proxy, caBundle string) HttpRequestDoer {
+	return NewResilientDoer(newPlatformClient(ctx, proxy, caBundle, config.RestEndpoints.Resilience.Timeout))
+}
+
+func (d *ResilientDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	breaker := breakerFor(host)
+
+	retries := 0
+	if isIdempotent(req) {
+		retries = config.RestEndpoints.Resilience.Retries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !breaker.allow(time.Now()) {
+			metrics.IncHTTPCircuitEvent(host, "reject")
+			return nil, NewDoerErr(fmt.Errorf("%w: %s", CircuitOpenErr, host))
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, NewDoerErr(err)
+			}
+			req.Body = body
+		}
+
+		resp, err := d.doer.Do(req)
+		failed := err != nil || resp.StatusCode >= 500
+		if breaker.record(failed, time.Now()) {
+			metrics.IncHTTPCircuitEvent(host, "open")
+			zerolog.Ctx(ctx).Warn().Str("host", host).Msgf("Circuit opened for %s after repeated failures", host)
+		}
+
+		if !failed || attempt >= retries || !retryable(err) || ctx.Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		wait := retryWait(attempt)
+		zerolog.Ctx(ctx).Debug().Str("host", host).Msgf("Retrying %s %s in %s", req.Method, req.URL.Path, wait)
+		select {
+		case <-ctx.Done():
+			return nil, NewDoerErr(ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// isIdempotent returns true for requests which can be repeated, the body must be rewindable.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+// retryable returns true for 5xx responses (no error) and timeouts.
+func retryable(err error) bool {
+	if err == nil {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryWait returns random wait up to the base wait doubled for every previous retry.
+//
+//nolint:gosec
+func retryWait(attempt int) time.Duration {
+	limit := config.RestEndpoints.Resilience.RetryWait << attempt
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)))
+}
@@ -0,0 +1,119 @@
+package http_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusDoer responds with the statuses in order, the last status is repeated.
+type statusDoer struct {
+	statuses []int
+	calls    int
+}
+
+func (d *statusDoer) Do(_ *http.Request) (*http.Response, error) {
+	status := d.statuses[len(d.statuses)-1]
+	if d.calls < len(d.statuses) {
+		status = d.statuses[d.calls]
+	}
+	d.calls++
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func setResilience(t *testing.T, retries, threshold int, cooldown time.Duration) {
+	t.Helper()
+	original := config.RestEndpoints.Resilience
+	t.Cleanup(func() { config.RestEndpoints.Resilience = original })
+
+	config.RestEndpoints.Resilience.Retries = retries
+	config.RestEndpoints.Resilience.RetryWait = time.Millisecond
+	config.RestEndpoints.Resilience.BreakerThreshold = threshold
+	config.RestEndpoints.Resilience.BreakerCooldown = cooldown
+}
+
+// each test uses its own host, circuit breakers are shared per host
+func newRequest(t *testing.T, method, host string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, "http://"+host+"/api", nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestResilientDoerRetries(t *testing.T) {
+	setResilience(t, 2, 0, 0)
+
+	t.Run("GET retried on 5xx", func(t *testing.T) {
+		doer := &statusDoer{statuses: []int{502, 503, 200}}
+		resp, err := httpClients.NewResilientDoer(doer).Do(newRequest(t, http.MethodGet, "retry.example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, 3, doer.calls)
+	})
+
+	t.Run("GET retries exhausted", func(t *testing.T) {
+		doer := &statusDoer{statuses: []int{500}}
+		resp, err := httpClients.NewResilientDoer(doer).Do(newRequest(t, http.MethodGet, "exhausted.example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, 500, resp.StatusCode)
+		assert.Equal(t, 3, doer.calls)
+	})
+
+	t.Run("GET not retried on 4xx", func(t *testing.T) {
+		doer := &statusDoer{statuses: []int{404}}
+		resp, err := httpClients.NewResilientDoer(doer).Do(newRequest(t, http.MethodGet, "notfound.example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, 404, resp.StatusCode)
+		assert.Equal(t, 1, doer.calls)
+	})
+
+	t.Run("POST not retried", func(t *testing.T) {
+		doer := &statusDoer{statuses: []int{503, 200}}
+		resp, err := httpClients.NewResilientDoer(doer).Do(newRequest(t, http.MethodPost, "post.example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, 503, resp.StatusCode)
+		assert.Equal(t, 1, doer.calls)
+	})
+}
+
+func TestResilientDoerCircuitBreaker(t *testing.T) {
+	setResilience(t, 0, 2, 50*time.Millisecond)
+	doer := &statusDoer{statuses: []int{500, 500, 500, 200}}
+	resilient := httpClients.NewResilientDoer(doer)
+	host := "breaker.example.com"
+
+	for i := 0; i < 2; i++ {
+		resp, err := resilient.Do(newRequest(t, http.MethodGet, host))
+		require.NoError(t, err)
+		assert.Equal(t, 500, resp.StatusCode)
+	}
+
+	_, err := resilient.Do(newRequest(t, http.MethodGet, host))
+	assert.True(t, errors.Is(err, httpClients.CircuitOpenErr), "circuit should be open")
+	assert.Equal(t, 2, doer.calls)
+
+	// failed trial request opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	resp, err := resilient.Do(newRequest(t, http.MethodGet, host))
+	require.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+	_, err = resilient.Do(newRequest(t, http.MethodGet, host))
+	assert.True(t, errors.Is(err, httpClients.CircuitOpenErr), "circuit should be open again")
+
+	// successful trial request closes the circuit
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		resp, err = resilient.Do(newRequest(t, http.MethodGet, host))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+	}
+	assert.Equal(t, 5, doer.calls)
+}
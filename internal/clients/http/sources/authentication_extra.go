@@ -0,0 +1,73 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// awsExtra are AWS fields of the authentication extra object. They are not part of the Sources
+// OpenAPI specification, the generated client does not decode them.
+type awsExtra struct {
+	// External ID of the role of the authentication
+	ExternalID string `json:"external_id"`
+
+	// Intermediate roles assumed in order before the role of the authentication
+	RoleChain []clients.AssumedRole `json:"role_chain"`
+}
+
+// azureExtra are Azure fields of the authentication extra object.
+type azureExtra struct {
+	// Cloud of the subscription (AzureCloud, AzureUSGovernment or AzureChinaCloud)
+	Cloud string `json:"cloud"`
+}
+
+// providerExtra are provider fields of the authentication extra object, only the field of the
+// provider of the authentication is applied.
+type providerExtra struct {
+	AWS   *awsExtra   `json:"aws"`
+	Azure *azureExtra `json:"azure"`
+}
+
+// authenticationExtra is an authentication with the provider extra fields only.
+type authenticationExtra struct {
+	Id    ID            `json:"id"`
+	Extra providerExtra `json:"extra"`
+}
+
+// parseExtras decodes provider extra fields of authentications of a collection, authentications
+// without provider extra fields are not returned. The result is keyed by authentication ID.
+func parseExtras(body []byte) (map[ID]*providerExtra, error) {
+	var collection struct {
+		Data []authenticationExtra `json:"data"`
+	}
+	if err := json.Unmarshal(body, &collection); err != nil {
+		return nil, fmt.Errorf("could not unmarshal authentication extra: %w", err)
+	}
+
+	result := make(map[ID]*providerExtra)
+	for i := range collection.Data {
+		auth := &collection.Data[i]
+		if auth.Extra.AWS != nil || auth.Extra.Azure != nil {
+			result[auth.Id] = &auth.Extra
+		}
+	}
+	return result, nil
+}
+
+// applyExtra sets the role chain and the external ID of an AWS authentication or the cloud of
+// an Azure authentication.
+func applyExtra(authentication *clients.Authentication, extra *providerExtra) {
+	if extra == nil {
+		return
+	}
+	if extra.AWS != nil && authentication.Is(models.ProviderTypeAWS) {
+		authentication.ExternalID = extra.AWS.ExternalID
+		authentication.RoleChain = extra.AWS.RoleChain
+	}
+	if extra.Azure != nil && authentication.Is(models.ProviderTypeAzure) {
+		authentication.AzureCloud = extra.Azure.Cloud
+	}
+}
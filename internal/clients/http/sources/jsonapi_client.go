@@ -0,0 +1,289 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdhttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/headers"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+)
+
+// jsonAPIMediaType is the JSON:API media type, it is sent in the Accept header
+const jsonAPIMediaType = "application/vnd.api+json"
+
+// jsonAPIClient speaks the newer Sources API which follows the JSON:API specification. Resources
+// are decoded into the same types as the generated v3.1 client uses.
+type jsonAPIClient struct {
+	url    *url.URL
+	client http.HttpRequestDoer
+}
+
+type jsonAPIIdentifier struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type jsonAPIRelationship struct {
+	Data *jsonAPIIdentifier `json:"data"`
+}
+
+type jsonAPIResource struct {
+	Id            string                         `json:"id"`
+	Type          string                         `json:"type"`
+	Attributes    json.RawMessage                `json:"attributes"`
+	Relationships map[string]jsonAPIRelationship `json:"relationships,omitempty"`
+}
+
+type jsonAPIDocument struct {
+	Data  []jsonAPIResource `json:"data"`
+	Links struct {
+		Next string `json:"next,omitempty"`
+	} `json:"links"`
+}
+
+// NewJSONAPISourcesClientWithUrl allows customization of the URL for the underlying client.
+// It is meant for testing only, for production please use clients.GetSourcesClient.
+func NewJSONAPISourcesClientWithUrl(ctx context.Context, baseURL string) (clients.Sources, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse sources URL: %w", err)
+	}
+	return &jsonAPIClient{url: u, client: http.NewResilientPlatformClient(ctx, config.Sources.Proxy.URL, config.Sources.CABundle)}, nil
+}
+
+func (c *jsonAPIClient) Ready(ctx context.Context) error {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "Ready", "")
+	defer span.End()
+
+	logger := logger(ctx)
+	_, err := c.get(ctx, c.resolve("application_types", url.Values{"page[limit]": {"1"}}))
+	if err != nil {
+		logger.Error().Err(err).Msg("Readiness request failed for sources")
+		span.Fail(err)
+		return fmt.Errorf("ready call: %w", err)
+	}
+	return nil
+}
+
+func (c *jsonAPIClient) ListProvisioningSourcesByProvider(ctx context.Context, provider models.ProviderType) ([]*clients.Source, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "ListProvisioningSourcesByProvider", "")
+	defer span.End()
+
+	sources, err := c.listProvisioningSources(ctx, url.Values{"filter[source_type][name]": {provider.SourcesProviderName()}})
+	if err != nil {
+		span.Fail(err)
+	}
+	return sources, err
+}
+
+func (c *jsonAPIClient) ListAllProvisioningSources(ctx context.Context) ([]*clients.Source, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "ListAllProvisioningSources", "")
+	defer span.End()
+
+	sources, err := c.listProvisioningSources(ctx, url.Values{})
+	if err != nil {
+		span.Fail(err)
+	}
+	return sources, err
+}
+
+func (c *jsonAPIClient) listProvisioningSources(ctx context.Context, query url.Values) ([]*clients.Source, error) {
+	logger := logger(ctx)
+	appTypeId, err := c.GetProvisioningTypeId(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get provisioning type id")
+		return nil, fmt.Errorf("failed to get provisioning app type: %w", err)
+	}
+
+	resources, err := c.listAll(ctx, "application_types/"+url.PathEscape(appTypeId)+"/sources", query)
+	if err != nil {
+		if errors.Is(err, clients.NotFoundErr) {
+			return nil, fmt.Errorf("list provisioning sources call: %w", http.SourceNotFoundErr)
+		}
+		return nil, fmt.Errorf("list provisioning sources call: %w", err)
+	}
+
+	data := make([]Source, len(resources))
+	for i, res := range resources {
+		if err = json.Unmarshal(res.Attributes, &data[i]); err != nil {
+			return nil, fmt.Errorf("could not unmarshal source resource: %w", err)
+		}
+		data[i].Id = ptr.To(res.Id)
+		if rel, ok := res.Relationships["source_type"]; ok && rel.Data != nil {
+			data[i].SourceTypeId = ptr.To(rel.Data.Id)
+		}
+	}
+
+	return newSources(data), nil
+}
+
+// GetAuthentication returns authentication of a source, it is cached for SOURCES_CACHE_TTL
+// when application cache is enabled.
+func (c *jsonAPIClient) GetAuthentication(ctx context.Context, sourceId string) (*clients.Authentication, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "GetAuthentication", "")
+	defer span.End()
+
+	authentication, err := cachedAuthentication(ctx, sourceId, c.loadAuthentication)
+	if err != nil {
+		span.Fail(err)
+	}
+	return authentication, err
+}
+
+func (c *jsonAPIClient) loadAuthentication(ctx context.Context, sourceId string) (*clients.Authentication, error) {
+	resources, err := c.listAll(ctx, "sources/"+url.PathEscape(sourceId)+"/authentications", url.Values{})
+	if err != nil {
+		if errors.Is(err, clients.NotFoundErr) {
+			return nil, fmt.Errorf("get source authentication call: %w", http.AuthenticationForSourcesNotFoundErr)
+		}
+		return nil, fmt.Errorf("get source authentication call: %w", err)
+	}
+
+	data := make([]AuthenticationRead, len(resources))
+	extras := make(map[ID]*providerExtra)
+	for i, res := range resources {
+		if err = json.Unmarshal(res.Attributes, &data[i]); err != nil {
+			return nil, fmt.Errorf("could not unmarshal authentication resource: %w", err)
+		}
+		var extra authenticationExtra
+		if err = json.Unmarshal(res.Attributes, &extra); err != nil {
+			return nil, fmt.Errorf("could not unmarshal authentication extra: %w", err)
+		}
+		if extra.Extra.AWS != nil || extra.Extra.Azure != nil {
+			extras[res.Id] = &extra.Extra
+		}
+		data[i].Id = ptr.To(res.Id)
+		if rel, ok := res.Relationships["resource"]; ok && rel.Data != nil {
+			data[i].ResourceId = ptr.To(rel.Data.Id)
+			data[i].ResourceType = ptr.To(jsonAPIResourceType(rel.Data.Type))
+		}
+		if data[i].Authtype == nil {
+			data[i].Authtype = ptr.To("")
+		}
+		if data[i].ResourceType == nil {
+			data[i].ResourceType = ptr.To(AuthenticationReadResourceType(""))
+		}
+	}
+
+	return newAuthentication(ctx, sourceId, data, extras)
+}
+
+// jsonAPIResourceType maps JSON:API resource types to resource types of the v3.1 API.
+func jsonAPIResourceType(t string) AuthenticationReadResourceType {
+	switch t {
+	case "application", "applications":
+		return "Application"
+	case "endpoint", "endpoints":
+		return "Endpoint"
+	case "source", "sources":
+		return "Source"
+	default:
+		return AuthenticationReadResourceType(t)
+	}
+}
+
+func (c *jsonAPIClient) GetProvisioningTypeId(ctx context.Context) (string, error) {
+	return cachedProvisioningTypeId(ctx, c.loadAppId)
+}
+
+func (c *jsonAPIClient) loadAppId(ctx context.Context) (string, error) {
+	logger := logger(ctx)
+	logger.Trace().Msg("Fetching the Application Type ID of Provisioning for Sources")
+
+	resources, err := c.listAll(ctx, "application_types", url.Values{"filter[name]": {"/insights/platform/provisioning"}})
+	if err != nil {
+		if errors.Is(err, clients.NotFoundErr) {
+			return "", fmt.Errorf("load app ID call: %w", http.ApplicationTypeNotFoundErr)
+		}
+		return "", fmt.Errorf("load app ID call: %w", err)
+	}
+
+	for _, res := range resources {
+		var t appType
+		if err = json.Unmarshal(res.Attributes, &t); err != nil {
+			return "", fmt.Errorf("could not unmarshal application type resource: %w", err)
+		}
+		if t.Name == "/insights/platform/provisioning" {
+			logger.Trace().Msgf("The Application Type ID found: '%s' and it got cached", res.Id)
+			return res.Id, nil
+		}
+	}
+	return "", http.ApplicationTypeNotFoundErr
+}
+
+// resolve returns URL of a path relative to the base URL.
+func (c *jsonAPIClient) resolve(path string, query url.Values) *url.URL {
+	u := c.url.ResolveReference(&url.URL{Path: path})
+	u.RawQuery = query.Encode()
+	return u
+}
+
+// listAll fetches all pages of a collection by following the next links.
+func (c *jsonAPIClient) listAll(ctx context.Context, path string, query url.Values) ([]jsonAPIResource, error) {
+	limit := config.Sources.PageSize
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	query.Set("page[limit]", strconv.Itoa(limit))
+
+	var result []jsonAPIResource
+	next := c.resolve(path, query)
+	for next != nil {
+		doc, err := c.get(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, doc.Data...)
+
+		if doc.Links.Next == "" || len(doc.Data) == 0 {
+			break
+		}
+		ref, err := url.Parse(doc.Links.Next)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse next page link: %w", err)
+		}
+		next = c.url.ResolveReference(ref)
+	}
+	return result, nil
+}
+
+func (c *jsonAPIClient) get(ctx context.Context, u *url.URL) (*jsonAPIDocument, error) {
+	req, err := stdhttp.NewRequestWithContext(ctx, stdhttp.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create sources request: %w", err)
+	}
+	req.Header.Set("Accept", jsonAPIMediaType)
+	for _, edit := range []RequestEditorFn{headers.AddSourcesIdentityHeader, headers.AddEdgeRequestIdHeader} {
+		if err = edit(ctx, req); err != nil {
+			return nil, fmt.Errorf("unable to edit sources request: %w", err)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sources request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	err = http.HandleHTTPResponses(ctx, "sources", resp)
+	if err != nil {
+		return nil, fmt.Errorf("sources request: %w", err)
+	}
+
+	var doc jsonAPIDocument
+	if err = json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("could not unmarshal sources response: %w", err)
+	}
+	return &doc, nil
+}
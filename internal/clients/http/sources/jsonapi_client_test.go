@@ -0,0 +1,75 @@
+package sources_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJSONAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	write := func(w http.ResponseWriter, r *http.Request, body string) {
+		assert.Equal(t, "application/vnd.api+json", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, body)
+		require.NoError(t, err, "failed to write http body for stubbed server")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/sources/v4/application_types", func(w http.ResponseWriter, r *http.Request) {
+		write(w, r, `{"data":[{"id":"5","type":"application_types","attributes":{"name":"/insights/platform/provisioning","display_name":"Launch images"}}]}`)
+	})
+	mux.HandleFunc("/api/sources/v4/application_types/5/sources", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page[after]") == "" {
+			assert.Equal(t, "amazon", r.URL.Query().Get("filter[source_type][name]"))
+			write(w, r, `{"data":[{"id":"1","type":"sources","attributes":{"name":"Source 1","uid":"u1"},"relationships":{"source_type":{"data":{"id":"1","type":"source_types"}}}}],"links":{"next":"/api/sources/v4/application_types/5/sources?page[after]=1"}}`)
+		} else {
+			write(w, r, `{"data":[{"id":"2","type":"sources","attributes":{"name":"Source 2","uid":"u2"},"relationships":{"source_type":{"data":{"id":"1","type":"source_types"}}}}],"links":{}}`)
+		}
+	})
+	mux.HandleFunc("/api/sources/v4/sources/1/authentications", func(w http.ResponseWriter, r *http.Request) {
+		write(w, r, `{"data":[{"id":"10","type":"authentications","attributes":{"authtype":"arn","username":"arn:aws:iam::123456789999:role/cost"},"relationships":{"resource":{"data":{"id":"7","type":"applications"}}}},{"id":"11","type":"authentications","attributes":{"authtype":"provisioning-arn","username":"arn:aws:iam::123456789999:role/provisioning"},"relationships":{"resource":{"data":{"id":"8","type":"applications"}}}}]}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestJSONAPISourcesClient_ListProvisioningSourcesByProvider(t *testing.T) {
+	ts := newJSONAPIServer(t)
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := sources.NewJSONAPISourcesClientWithUrl(ctx, ts.URL+"/api/sources/v4")
+	require.NoError(t, err, "failed to initialize sources client with test server")
+
+	result, clientErr := client.ListProvisioningSourcesByProvider(ctx, models.ProviderTypeAWS)
+	require.NoError(t, clientErr, "Could not list provisioning sources")
+	require.Len(t, result, 2)
+	assert.Equal(t, "1", result[0].ID)
+	assert.Equal(t, "Source 1", result[0].Name)
+	assert.Equal(t, "1", result[0].SourceTypeID)
+	assert.Equal(t, "2", result[1].ID)
+}
+
+func TestJSONAPISourcesClient_GetAuthentication(t *testing.T) {
+	ts := newJSONAPIServer(t)
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := sources.NewJSONAPISourcesClientWithUrl(ctx, ts.URL+"/api/sources/v4/")
+	require.NoError(t, err, "failed to initialize sources client with test server")
+
+	authentication, clientErr := client.GetAuthentication(ctx, "1")
+	require.NoError(t, clientErr, "Could not get source authentication")
+	assert.Equal(t, "arn:aws:iam::123456789999:role/provisioning", authentication.Payload)
+	assert.Equal(t, "8", authentication.SourceApplictionID)
+}
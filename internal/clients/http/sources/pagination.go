@@ -0,0 +1,51 @@
+package sources
+
+import (
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+)
+
+// defaultPageSize is used when page size is not configured, it is also the Sources default
+const defaultPageSize = 100
+
+// pageFn fetches a single page of a collection using the query editor, it returns items of
+// the page and collection metadata (can be nil).
+type pageFn[T any] func(query RequestEditorFn) ([]T, *CollectionMetadata, error)
+
+// listAllPages fetches all pages of a collection. Query is a list of additional query keys and
+// values, limit and offset are added for each page.
+func listAllPages[T any](fetch pageFn[T], query ...string) ([]T, error) {
+	limit := config.Sources.PageSize
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	var result []T
+	offset := 0
+	for {
+		args := make([]string, 0, len(query)+4)
+		args = append(args, query...)
+		args = append(args, "limit", strconv.Itoa(limit), "offset", strconv.Itoa(offset))
+
+		items, meta, err := fetch(BuildQuery(args...))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		offset += len(items)
+
+		// Sources can apply lower limit than requested
+		pageLimit := limit
+		if meta != nil && meta.Limit != nil && *meta.Limit > 0 {
+			pageLimit = *meta.Limit
+		}
+
+		if len(items) == 0 || len(items) < pageLimit {
+			return result, nil
+		}
+		if meta != nil && meta.Count != nil && offset >= *meta.Count {
+			return result, nil
+		}
+	}
+}
@@ -14,11 +14,11 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/headers"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/rs/zerolog"
-	"go.opentelemetry.io/otel"
 )
 
 const TraceName = telemetry.TracePrefix + "internal/clients/http/sources"
@@ -36,14 +36,21 @@ func logger(ctx context.Context) zerolog.Logger {
 }
 
 func newSourcesClient(ctx context.Context) (clients.Sources, error) {
-	return NewSourcesClientWithUrl(ctx, config.Sources.URL)
+	switch config.Sources.API {
+	case "", "v3.1":
+		return NewSourcesClientWithUrl(ctx, config.Sources.URL)
+	case "jsonapi":
+		return NewJSONAPISourcesClientWithUrl(ctx, config.Sources.URL)
+	default:
+		return nil, fmt.Errorf("%w: %s", http.UnknownSourcesAPIErr, config.Sources.API)
+	}
 }
 
 // NewSourcesClientWithUrl allows customization of the URL for the underlying client.
 // It is meant for testing only, for production please use clients.GetSourcesClient.
 func NewSourcesClientWithUrl(ctx context.Context, url string) (clients.Sources, error) {
 	c, err := NewClientWithResponses(url, func(c *Client) error {
-		c.Client = http.NewPlatformClient(ctx, config.Sources.Proxy.URL)
+		c.Client = http.NewResilientPlatformClient(ctx, config.Sources.Proxy.URL, config.Sources.CABundle)
 		return nil
 	})
 	if err != nil {
@@ -63,19 +70,21 @@ type dataElement struct {
 }
 
 func (c *sourcesClient) Ready(ctx context.Context) error {
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "Ready")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "Ready", "")
 	defer span.End()
 
 	logger := logger(ctx)
 	resp, err := c.client.ListApplicationTypes(ctx, &ListApplicationTypesParams{}, headers.AddSourcesIdentityHeader, headers.AddEdgeRequestIdHeader)
 	if err != nil {
 		logger.Error().Err(err).Msg("Readiness request failed for sources")
+		span.Fail(err)
 		return err
 	}
 	defer resp.Body.Close()
 
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode)
+	err = http.HandleHTTPResponses(ctx, "sources", resp)
 	if err != nil {
+		span.Fail(err)
 		return fmt.Errorf("ready call: %w", err)
 	}
 	return nil
@@ -83,78 +92,56 @@ func (c *sourcesClient) Ready(ctx context.Context) error {
 
 func (c *sourcesClient) ListProvisioningSourcesByProvider(ctx context.Context, provider models.ProviderType) ([]*clients.Source, error) {
 	logger := logger(ctx)
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListProvisioningSourcesByProvider")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "ListProvisioningSourcesByProvider", "")
 	defer span.End()
 
 	appTypeId, err := c.GetProvisioningTypeId(ctx)
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to get provisioning type id")
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to get provisioning app type: %w", err)
 	}
 
 	sourcesProviderName := provider.SourcesProviderName()
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to get provider name according to sources service")
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to get provider name according to sources service: %w", err)
 	}
 
-	resp, err := c.client.ListApplicationTypeSourcesWithResponse(ctx, appTypeId, &ListApplicationTypeSourcesParams{}, headers.AddSourcesIdentityHeader,
-		headers.AddEdgeRequestIdHeader, BuildQuery("filter[source_type][name]", sourcesProviderName))
-	if err != nil {
-		logger.Warn().Err(err).Msg("Failed to fetch ApplicationTypes from sources")
-		return nil, fmt.Errorf("failed to get ApplicationTypes: %w", err)
-	}
-
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode())
+	data, err := c.listApplicationTypeSources(ctx, appTypeId, "filter[source_type][name]", sourcesProviderName)
 	if err != nil {
-		if errors.Is(err, clients.NotFoundErr) {
-			return nil, fmt.Errorf("list provisioning sources call: %w", http.SourceNotFoundErr)
-		}
-		return nil, fmt.Errorf("list provisioning sources call: %w", err)
-	}
-
-	result := make([]*clients.Source, 0, len(*resp.JSON200.Data))
-
-	for _, src := range *resp.JSON200.Data {
-		newSrc := clients.Source{
-			ID:           ptr.From(src.Id),
-			Name:         ptr.From(src.Name),
-			SourceTypeID: ptr.From(src.SourceTypeId),
-			Uid:          ptr.From(src.Uid),
-		}
-		result = append(result, &newSrc)
+		span.Fail(err)
+		return nil, err
 	}
 
-	return result, nil
+	return newSources(data), nil
 }
 
 func (c *sourcesClient) ListAllProvisioningSources(ctx context.Context) ([]*clients.Source, error) {
 	logger := logger(ctx)
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListAllProvisioningSources")
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "ListAllProvisioningSources", "")
 	defer span.End()
 
 	appTypeId, err := c.GetProvisioningTypeId(ctx)
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to get provisioning type id")
+		span.Fail(err)
 		return nil, fmt.Errorf("failed to get provisioning app type: %w", err)
 	}
 
-	resp, err := c.client.ListApplicationTypeSourcesWithResponse(ctx, appTypeId, &ListApplicationTypeSourcesParams{}, headers.AddSourcesIdentityHeader, headers.AddEdgeRequestIdHeader)
+	data, err := c.listApplicationTypeSources(ctx, appTypeId)
 	if err != nil {
-		logger.Warn().Err(err).Msg("Failed to fetch ApplicationTypes from sources")
-		return nil, fmt.Errorf("failed to get ApplicationTypes: %w", err)
+		span.Fail(err)
+		return nil, err
 	}
 
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode())
-	if err != nil {
-		if errors.Is(err, clients.NotFoundErr) {
-			return nil, fmt.Errorf("list provisioning sources call: %w", http.SourceNotFoundErr)
-		}
-		return nil, fmt.Errorf("list provisioning sources call: %w", err)
-	}
+	return newSources(data), nil
+}
 
-	result := make([]*clients.Source, len(*resp.JSON200.Data))
-	for i, src := range *resp.JSON200.Data {
+func newSources(data []Source) []*clients.Source {
+	result := make([]*clients.Source, len(data))
+	for i, src := range data {
 		newSrc := clients.Source{
 			ID:           ptr.From(src.Id),
 			Name:         ptr.From(src.Name),
@@ -163,34 +150,136 @@ func (c *sourcesClient) ListAllProvisioningSources(ctx context.Context) ([]*clie
 		}
 		result[i] = &newSrc
 	}
-	return result, nil
+	return result
 }
 
-func (c *sourcesClient) GetAuthentication(ctx context.Context, sourceId string) (*clients.Authentication, error) {
+// listApplicationTypeSources fetches all pages of sources of an application type, query is a list
+// of additional query keys and values.
+func (c *sourcesClient) listApplicationTypeSources(ctx context.Context, appTypeId string, query ...string) ([]Source, error) {
 	logger := logger(ctx)
-	ctx, span := otel.Tracer(TraceName).Start(ctx, "GetAuthentication")
+
+	return listAllPages(func(page RequestEditorFn) ([]Source, *CollectionMetadata, error) {
+		resp, err := c.client.ListApplicationTypeSourcesWithResponse(ctx, appTypeId, &ListApplicationTypeSourcesParams{}, headers.AddSourcesIdentityHeader,
+			headers.AddEdgeRequestIdHeader, page)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to fetch ApplicationTypes from sources")
+			return nil, nil, fmt.Errorf("failed to get ApplicationTypes: %w", err)
+		}
+
+		err = http.HandleHTTPResponses(ctx, "sources", resp.HTTPResponse)
+		if err != nil {
+			if errors.Is(err, clients.NotFoundErr) {
+				return nil, nil, fmt.Errorf("list provisioning sources call: %w", http.SourceNotFoundErr)
+			}
+			return nil, nil, fmt.Errorf("list provisioning sources call: %w", err)
+		}
+
+		return ptr.From(resp.JSON200.Data), resp.JSON200.Meta, nil
+	}, query...)
+}
+
+// authenticationCacheKey returns cache key of a source authentication, source IDs are only
+// unique within an organization.
+func authenticationCacheKey(ctx context.Context, sourceId string) string {
+	return identity.OrgIdOrEmpty(ctx) + "/" + sourceId
+}
+
+// InvalidateAuthentication removes a cached authentication of a source, it is fetched from
+// Sources by the next GetAuthentication call.
+func InvalidateAuthentication(ctx context.Context, sourceId string) error {
+	err := cache.Delete(ctx, authenticationCacheKey(ctx, sourceId), &clients.Authentication{})
+	if err != nil {
+		return fmt.Errorf("unable to invalidate source authentication: %w", err)
+	}
+	return nil
+}
+
+// GetAuthentication returns authentication of a source, it is cached for SOURCES_CACHE_TTL
+// when application cache is enabled.
+func (c *sourcesClient) GetAuthentication(ctx context.Context, sourceId string) (*clients.Authentication, error) {
+	ctx, span := telemetry.StartClientSpan(ctx, TraceName, "sources", "GetAuthentication", "")
 	defer span.End()
 
-	// Get all the authentications linked to a specific source
-	resp, err := c.client.ListSourceAuthenticationsWithResponse(ctx, sourceId, &ListSourceAuthenticationsParams{}, headers.AddSourcesIdentityHeader, headers.AddEdgeRequestIdHeader)
+	authentication, err := cachedAuthentication(ctx, sourceId, c.loadAuthentication)
 	if err != nil {
-		return nil, fmt.Errorf("cannot list source authentication: %w", err)
+		span.Fail(err)
 	}
+	return authentication, err
+}
 
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode())
+// cachedAuthentication returns authentication from the application cache or loads it.
+func cachedAuthentication(ctx context.Context, sourceId string, load func(context.Context, string) (*clients.Authentication, error)) (*clients.Authentication, error) {
+	logger := logger(ctx)
+	if config.Sources.CacheTTL <= 0 {
+		return load(ctx, sourceId)
+	}
+
+	key := authenticationCacheKey(ctx, sourceId)
+	authentication := &clients.Authentication{}
+	err := cache.Find(ctx, key, authentication)
+	if err == nil {
+		return authentication, nil
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		logger.Warn().Err(err).Msg("Unable to find source authentication in cache")
+	}
+
+	authentication, err = load(ctx, sourceId)
 	if err != nil {
-		if errors.Is(err, clients.NotFoundErr) {
-			return nil, fmt.Errorf("get source authentication call: %w", http.AuthenticationForSourcesNotFoundErr)
+		return nil, err
+	}
+
+	err = cache.SetExpires(ctx, key, authentication, config.Sources.CacheTTL)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to store source authentication in cache")
+	}
+	return authentication, nil
+}
+
+func (c *sourcesClient) loadAuthentication(ctx context.Context, sourceId string) (*clients.Authentication, error) {
+	// Get all the authentications linked to a specific source
+	extras := make(map[ID]*providerExtra)
+	data, err := listAllPages(func(page RequestEditorFn) ([]AuthenticationRead, *CollectionMetadata, error) {
+		resp, respErr := c.client.ListSourceAuthenticationsWithResponse(ctx, sourceId, &ListSourceAuthenticationsParams{}, headers.AddSourcesIdentityHeader, headers.AddEdgeRequestIdHeader, page)
+		if respErr != nil {
+			return nil, nil, fmt.Errorf("cannot list source authentication: %w", respErr)
+		}
+
+		respErr = http.HandleHTTPResponses(ctx, "sources", resp.HTTPResponse)
+		if respErr != nil {
+			if errors.Is(respErr, clients.NotFoundErr) {
+				return nil, nil, fmt.Errorf("get source authentication call: %w", http.AuthenticationForSourcesNotFoundErr)
+			}
+			return nil, nil, fmt.Errorf("get source authentication call: %w", respErr)
 		}
-		return nil, fmt.Errorf("get source authentication call: %w", err)
+
+		pageExtras, respErr := parseExtras(resp.Body)
+		if respErr != nil {
+			return nil, nil, respErr
+		}
+		for id, extra := range pageExtras {
+			extras[id] = extra
+		}
+
+		return ptr.From(resp.JSON200.Data), resp.JSON200.Meta, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return newAuthentication(ctx, sourceId, data, extras)
+}
+
+// newAuthentication creates authentication from the provisioning authentication of a source,
+// extras are provider extra fields of the authentications keyed by authentication ID.
+func newAuthentication(ctx context.Context, sourceId string, data []AuthenticationRead, extras map[ID]*providerExtra) (*clients.Authentication, error) {
+	logger := logger(ctx)
+
 	// Filter authentications to include only auth where resource_type == "Application". We do this because
 	// Sources API currently does not provide a good server-side filtering.
-	auth, err := filterSourceAuthentications(*resp.JSON200.Data)
+	auth, err := filterSourceAuthentications(data)
 	if err != nil {
 		at := make([]string, 0)
-		for _, auth := range *resp.JSON200.Data {
+		for _, auth := range data {
 			at = append(at, string(*auth.Authtype))
 		}
 		logger.Warn().Msgf("Sources did not return any Provisioning authentication for source(auth types): %s(%v)", sourceId, at)
@@ -201,13 +290,21 @@ func (c *sourcesClient) GetAuthentication(ctx context.Context, sourceId string)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create source from source authentication type: %w", err)
 	}
+	if auth.Id != nil {
+		applyExtra(authentication, extras[*auth.Id])
+	}
 	return authentication, nil
 }
 
 func (c *sourcesClient) GetProvisioningTypeId(ctx context.Context) (string, error) {
+	return cachedProvisioningTypeId(ctx, c.loadAppId)
+}
+
+// cachedProvisioningTypeId returns provisioning application type ID from the memory cache or loads it.
+func cachedProvisioningTypeId(ctx context.Context, load func(context.Context) (string, error)) (string, error) {
 	appTypeId, err := cache.FindAppTypeId(ctx)
 	if errors.Is(err, cache.ErrNotFound) {
-		appTypeId, err = c.loadAppId(ctx)
+		appTypeId, err = load(ctx)
 		if err != nil {
 			return "", err
 		}
@@ -233,7 +330,7 @@ func (c *sourcesClient) loadAppId(ctx context.Context) (string, error) {
 	}
 	defer resp.Body.Close()
 
-	err = http.HandleHTTPResponses(ctx, resp.StatusCode)
+	err = http.HandleHTTPResponses(ctx, "sources", resp)
 	if err != nil {
 		if errors.Is(err, clients.NotFoundErr) {
 			return "", fmt.Errorf("load app ID call: %w", http.ApplicationTypeNotFoundErr)
@@ -9,8 +9,12 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -87,6 +91,84 @@ func TestSourcesClient_GetAuthentication(t *testing.T) {
 
 		assert.Equal(t, "arn:aws:iam::123456789999:role/redhat-provisioning-role-2f6d01c", authentication.Payload)
 	})
+
+	t.Run("source with AWS role chain", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := io.WriteString(w, `{"data":[{"id":"256144","authtype":"provisioning-arn","username":"arn:aws:iam::123456789999:role/target","extra":{"aws":{"external_id":"target-id","role_chain":[{"arn":"arn:aws:iam::111111111111:role/jump","external_id":"jump-id"}]}},"availability_status":"in_progress","resource_type":"Application","resource_id":"304935"}],"meta":{"count":1,"limit":100,"offset":0}}`)
+			require.NoError(t, err, "failed to write http body for stubbed server")
+		}))
+		defer ts.Close()
+
+		ctx := context.Background()
+		client, err := sources.NewSourcesClientWithUrl(ctx, ts.URL)
+		require.NoError(t, err, "failed to initialize sources client with test server")
+
+		authentication, err := client.GetAuthentication(ctx, "256144")
+		require.NoError(t, err, "failed to get authentication with role chain")
+		assert.Equal(t, "arn:aws:iam::123456789999:role/target", authentication.Payload)
+		assert.Equal(t, "target-id", authentication.ExternalID)
+		assert.Equal(t, []clients.AssumedRole{{ARN: "arn:aws:iam::111111111111:role/jump", ExternalID: "jump-id"}}, authentication.RoleChain)
+	})
+
+	t.Run("source with Azure Government cloud", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := io.WriteString(w, `{"data":[{"id":"256144","authtype":"provisioning_lighthouse_subscription_id","username":"1a2b3c4d-5e6f-7a8b-9c8d-7e8f9a8b7c6d","extra":{"azure":{"cloud":"AzureUSGovernment"}},"availability_status":"in_progress","resource_type":"Application","resource_id":"40340"}],"meta":{"count":1,"limit":100,"offset":0}}`)
+			require.NoError(t, err, "failed to write http body for stubbed server")
+		}))
+		defer ts.Close()
+
+		ctx := context.Background()
+		client, err := sources.NewSourcesClientWithUrl(ctx, ts.URL)
+		require.NoError(t, err, "failed to initialize sources client with test server")
+
+		authentication, err := client.GetAuthentication(ctx, "256144")
+		require.NoError(t, err, "failed to get authentication with Azure cloud")
+		assert.Equal(t, "1a2b3c4d-5e6f-7a8b-9c8d-7e8f9a8b7c6d", authentication.Payload)
+		assert.Equal(t, "AzureUSGovernment", authentication.AzureCloud)
+		assert.Empty(t, authentication.RoleChain)
+	})
+}
+
+func TestSourcesClient_GetAuthenticationCached(t *testing.T) {
+	config.Application.Cache.Type = "memory"
+	config.Sources.CacheTTL = time.Minute
+	cache.Initialize()
+	defer func() {
+		config.Application.Cache.Type = "none"
+		config.Sources.CacheTTL = 0
+		cache.Initialize()
+	}()
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"data":[{"id":"256144","authtype":"provisioning-arn","username":"arn:aws:cached","availability_status":"in_progress","resource_type":"Application","resource_id":"304935"}]}`)
+		require.NoError(t, err, "failed to write http body for stubbed server")
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := sources.NewSourcesClientWithUrl(ctx, ts.URL)
+	require.NoError(t, err, "failed to initialize sources client with test server")
+
+	for i := 0; i < 2; i++ {
+		authentication, clientErr := client.GetAuthentication(ctx, "256144")
+		require.NoError(t, clientErr)
+		assert.Equal(t, "arn:aws:cached", authentication.Payload)
+		assert.Equal(t, "304935", authentication.SourceApplictionID)
+	}
+	assert.Equal(t, 1, requests)
+
+	require.NoError(t, sources.InvalidateAuthentication(ctx, "256144"))
+	_, err = client.GetAuthentication(ctx, "256144")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
 }
 
 func TestSourcesClient_ListAllProvisioningSources(t *testing.T) {
@@ -119,6 +201,49 @@ func TestSourcesClient_ListAllProvisioningSources(t *testing.T) {
 	assert.Equal(t, "2", sources[1].SourceTypeID)
 }
 
+func TestSourcesClient_ListAllProvisioningSourcesPaginated(t *testing.T) {
+	config.Sources.PageSize = 2
+	defer func() { config.Sources.PageSize = 0 }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/application_types", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, applicationTypes)
+		require.NoError(t, err, "failed to write http body for stubbed server")
+	})
+
+	var offsets []string
+	mux.HandleFunc("/application_types/5/sources", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		offset := r.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		var err error
+		if offset == "0" {
+			_, err = io.WriteString(w, `{"data":[{"id":"1","source_type_id":"1"},{"id":"2","source_type_id":"1"}],"meta":{"count":3,"limit":2,"offset":0}}`)
+		} else {
+			_, err = io.WriteString(w, `{"data":[{"id":"3","source_type_id":"2"}],"meta":{"count":3,"limit":2,"offset":2}}`)
+		}
+		require.NoError(t, err, "failed to write http body for stubbed server")
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := sources.NewSourcesClientWithUrl(ctx, ts.URL)
+	require.NoError(t, err, "failed to initialize sources client with test server")
+
+	result, clientErr := client.ListAllProvisioningSources(ctx)
+	require.NoError(t, clientErr, "Could not list all provisioning sources")
+	assert.Equal(t, []string{"0", "2"}, offsets)
+	require.Len(t, result, 3)
+	assert.Equal(t, "3", result[2].ID)
+}
+
 func TestSourcesClient_BuildQuery(t *testing.T) {
 	parsedURL, err := url.Parse("")
 	assert.NoError(t, err)
@@ -15,4 +15,5 @@ var (
 	ApplicationReadErr                  = fmt.Errorf("application read returned no application type in sources: %w", clients.NotFoundErr)
 	SourceTypeNameNotFoundErr           = fmt.Errorf("source type name not found: %w", clients.NotFoundErr)
 	NotEvenErr                          = fmt.Errorf("number of keys and values is not even when building a query")
+	UnknownSourcesAPIErr                = fmt.Errorf("unknown sources API client implementation")
 )
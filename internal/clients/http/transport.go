@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/rs/zerolog"
+)
+
+type transportKey struct {
+	proxy    string
+	caBundle string
+}
+
+// transports by proxy and CA bundle, clients with the same settings share connections
+var (
+	transports      = make(map[transportKey]*http.Transport)
+	transportsMutex sync.Mutex
+)
+
+// TransportFor returns shared HTTP transport of platform and cloud clients. Proxy URL overrides
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables which are respected otherwise,
+// proxy URLs are ignored in clowder environment. CA bundle is a path to a PEM file with CA
+// certificates trusted in addition to the system ones.
+func TransportFor(ctx context.Context, proxy, caBundle string) *http.Transport {
+	if proxy != "" && config.InClowder() {
+		zerolog.Ctx(ctx).Warn().Msgf("Unable to use HTTP client proxy in clowder environment: %s", proxy)
+		proxy = ""
+	}
+	key := transportKey{proxy: proxy, caBundle: caBundle}
+
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+
+	if t, ok := transports[key]; ok {
+		return t
+	}
+
+	//nolint:forcetypeassert
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != "" {
+		zerolog.Ctx(ctx).Warn().Msgf("Creating HTTP client with proxy %s", proxy)
+		t.Proxy = http.ProxyURL(config.StringToURL(proxy))
+	}
+	if caBundle != "" {
+		pool, err := certPool(caBundle)
+		if err != nil {
+			// bundles are verified during startup, the file must have changed since then
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("Unable to load CA bundle %s, using system CA certificates only", caBundle)
+			return t
+		}
+		t.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+	transports[key] = t
+	return t
+}
+
+// certPool returns system CA certificates with certificates of the PEM bundle.
+func certPool(caBundle string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", caBundle)
+	}
+	return pool, nil
+}
@@ -0,0 +1,37 @@
+package clients
+
+import (
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// Image is an image build (compose) requested in image builder for a cloud provider.
+type Image struct {
+	// Compose ID, it is used as image ID for launch requests
+	ID string
+
+	// Name of the image, can be empty
+	Name string
+
+	// Distribution of the image (e.g. rhel-92)
+	Distribution string
+
+	// Architecture of the image
+	Architecture ArchitectureType
+
+	// Provider the image was uploaded to
+	Provider models.ProviderType
+
+	// Time of the compose request
+	CreatedAt time.Time
+}
+
+// ImageList is a list of all images of an organization.
+type ImageList struct {
+	Images []*Image
+}
+
+func (il ImageList) CacheKeyName() string {
+	return "image_builder_images"
+}
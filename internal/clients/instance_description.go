@@ -1,5 +1,7 @@
 package clients
 
+import "time"
+
 type AzureInstanceID string
 
 // InstanceDescription defines a model for an instance description
@@ -12,4 +14,25 @@ type InstanceDescription struct {
 
 	// the public ipv4 of the instance
 	PublicIPv4 string `json:"ipv4,omitempty" yaml:"ipv4"`
+
+	// The private ipv4 of the instance, only set when the instance is described
+	PrivateIPv4 string `json:"private_ipv4,omitempty" yaml:"private_ipv4"`
+
+	// Provider specific state of the instance (e.g. running), only set when the instance is described
+	State string `json:"state,omitempty" yaml:"state"`
+
+	// Time the instance was launched, only set when the instance is described
+	LaunchTime *time.Time `json:"launch_time,omitempty" yaml:"launch_time"`
+}
+
+// InstanceStateNotFound is the state of instances which no longer exist at the provider.
+const InstanceStateNotFound = "not_found"
+
+// InstanceDescriptionList is a list of described instances of a reservation.
+type InstanceDescriptionList struct {
+	Instances []*InstanceDescription
+}
+
+func (il InstanceDescriptionList) CacheKeyName() string {
+	return "reservation_instances"
 }
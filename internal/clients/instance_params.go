@@ -29,6 +29,27 @@ type GCPInstanceParams struct {
 
 	// StartupScript contains metadata startup script (GCP tools must be installed on the image)
 	StartupScript string
+
+	// ProvisioningModel of the instances (STANDARD or SPOT)
+	ProvisioningModel string
+
+	// TerminationAction of preempted spot instances (STOP or DELETE)
+	TerminationAction string
+
+	// ServiceAccount email attached to the instances, none when empty
+	ServiceAccount string
+
+	// Scopes of the attached service account
+	Scopes []string
+
+	// Network path, the default network is used when both network and subnetwork are empty
+	Network string
+
+	// Subnetwork path
+	Subnetwork string
+
+	// Tags of the instances applied as labels, including the standard tags
+	Tags map[string]string
 }
 
 type AWSInstanceParams struct {
@@ -49,6 +70,18 @@ type AWSInstanceParams struct {
 
 	// UserData for the instance launch
 	UserData []byte
+
+	// Tenancy of the instance, empty or "default" for shared hardware
+	Tenancy string
+
+	// HostID of a dedicated host for the "host" tenancy, can be empty
+	HostID string
+
+	// MetadataOptions of the instance metadata service, nil keeps the AWS defaults
+	MetadataOptions *models.AWSMetadataOptions
+
+	// Tags of the instances and volumes, including the standard tags
+	Tags map[string]string
 }
 
 // AzureInstanceParams define parameters for a single instance launch on Azure.
@@ -71,4 +104,16 @@ type AzureInstanceParams struct {
 
 	// UserData for the instance launch
 	UserData []byte
+
+	// Priority of the VMs (regular or spot)
+	Priority string
+
+	// MaxPrice of spot VMs per hour, nil or -1 caps the price at the regular price
+	MaxPrice *float64
+
+	// EvictionPolicy of spot VMs (deallocate or delete)
+	EvictionPolicy string
+
+	// Tags of the VMs, network interfaces and public IPs, including the standard tags
+	Tags map[string]string
 }
@@ -36,6 +36,18 @@ type InstanceType struct {
 	// Instance type's Architecture: i386, arm64, x86_64
 	Architecture ArchitectureType `json:"architecture,omitempty" yaml:"arch"`
 
+	// Number of GPUs (accelerators), zero when there are none
+	GPUs int32 `json:"gpus,omitempty" yaml:"gpus,omitempty"`
+
+	// GPU (accelerator) model name, e.g. "T4" or "nvidia-tesla-t4" depending on provider
+	GPUModel string `json:"gpu_model,omitempty" yaml:"gpu_model,omitempty"`
+
+	// GPU manufacturer, e.g. "NVIDIA", empty when not provided by the provider
+	GPUManufacturer string `json:"gpu_manufacturer,omitempty" yaml:"gpu_manufacturer,omitempty"`
+
+	// Total memory of all GPUs in MiB, zero when not provided by the provider
+	GPUMemoryMiB int64 `json:"gpu_memory_mib,omitempty" yaml:"gpu_memory_mib,omitempty"`
+
 	// Extra information for Azure, nil for other types
 	AzureDetail *InstanceTypeDetailAzure `json:"azure,omitempty" yaml:"azure,omitempty"`
 }
@@ -64,6 +76,23 @@ func (it *InstanceType) String() string {
 	sb.WriteString(" MiB | Disk: ")
 	sb.WriteString(strconv.Itoa(int(it.EphemeralStorageGB)))
 	sb.WriteString(" GB")
+	if it.GPUs > 0 {
+		sb.WriteString(" | GPUs: ")
+		sb.WriteString(strconv.Itoa(int(it.GPUs)))
+		if it.GPUModel != "" {
+			sb.WriteString(" x ")
+			if it.GPUManufacturer != "" {
+				sb.WriteString(it.GPUManufacturer)
+				sb.WriteString(" ")
+			}
+			sb.WriteString(it.GPUModel)
+		}
+		if it.GPUMemoryMiB > 0 {
+			sb.WriteString(" (")
+			sb.WriteString(strconv.Itoa(int(it.GPUMemoryMiB)))
+			sb.WriteString(" MiB)")
+		}
+	}
 	if it.AzureDetail != nil {
 		sb.WriteString(" | Azure Gen:")
 		if it.AzureDetail.GenV1 {
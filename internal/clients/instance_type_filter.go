@@ -0,0 +1,54 @@
+package clients
+
+// InstanceTypeFilter narrows down a list of instance types. Zero values of all fields
+// (and nil pointers) mean the attribute is not filtered.
+type InstanceTypeFilter struct {
+	// Minimum number of virtual CPUs
+	MinVCPUs int32
+
+	// Minimum amount of memory in MiB
+	MinMemoryMiB int64
+
+	// Minimum number of GPUs
+	MinGPUs int32
+
+	// Exact architecture
+	Architecture ArchitectureType
+
+	// Supported flag
+	Supported *bool
+}
+
+// Match returns true when the instance type passes all filter criteria.
+func (f *InstanceTypeFilter) Match(it *InstanceType) bool {
+	if f == nil {
+		return true
+	}
+	if it.VCPUs < f.MinVCPUs {
+		return false
+	}
+	if it.MemoryMiB < f.MinMemoryMiB {
+		return false
+	}
+	if it.GPUs < f.MinGPUs {
+		return false
+	}
+	if f.Architecture != "" && it.Architecture != f.Architecture {
+		return false
+	}
+	if f.Supported != nil && *f.Supported != it.Supported {
+		return false
+	}
+	return true
+}
+
+// Filter returns a new slice with instance types which match the filter.
+func (f *InstanceTypeFilter) Filter(types []*InstanceType) []*InstanceType {
+	result := make([]*InstanceType, 0, len(types))
+	for _, it := range types {
+		if f.Match(it) {
+			result = append(result, it)
+		}
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/stretchr/testify/assert"
+)
+
+var gpuType = InstanceType{
+	Name:         "gpu",
+	VCPUs:        8,
+	Cores:        4,
+	MemoryMiB:    61_000,
+	Supported:    true,
+	Architecture: ArchitectureTypeX86_64,
+	GPUs:         1,
+	GPUModel:     "V100",
+}
+
+func TestInstanceTypeFilter_MatchNil(t *testing.T) {
+	var f *InstanceTypeFilter
+	assert.True(t, f.Match(&smallType))
+}
+
+func TestInstanceTypeFilter_Match(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter InstanceTypeFilter
+		it     InstanceType
+		match  bool
+	}{
+		{"empty", InstanceTypeFilter{}, smallType, true},
+		{"vcpus", InstanceTypeFilter{MinVCPUs: 2}, smallType, false},
+		{"memory", InstanceTypeFilter{MinMemoryMiB: 1500}, smallType, true},
+		{"memory too low", InstanceTypeFilter{MinMemoryMiB: 2000}, smallType, false},
+		{"architecture", InstanceTypeFilter{Architecture: ArchitectureTypeArm64}, smallType, false},
+		{"supported", InstanceTypeFilter{Supported: ptr.To(true)}, smallType, false},
+		{"no gpu", InstanceTypeFilter{MinGPUs: 1}, smallType, false},
+		{"gpu", InstanceTypeFilter{MinGPUs: 1}, gpuType, true},
+		{"all", InstanceTypeFilter{MinVCPUs: 8, MinMemoryMiB: 60_000, MinGPUs: 1, Architecture: ArchitectureTypeX86_64, Supported: ptr.To(true)}, gpuType, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, tt.filter.Match(&tt.it))
+		})
+	}
+}
+
+func TestInstanceTypeFilter_Filter(t *testing.T) {
+	f := InstanceTypeFilter{MinGPUs: 1}
+	result := f.Filter([]*InstanceType{&smallType, &gpuType})
+	assert.Equal(t, []*InstanceType{&gpuType}, result)
+}
@@ -13,7 +13,9 @@ type InstanceTypeInfo struct {
 	RegionalAvailability RegionalTypeAvailability
 }
 
-func (iii *InstanceTypeInfo) InstanceTypesForZone(region, zone string, supported *bool) ([]*InstanceType, error) {
+// InstanceTypesForZone returns registered instance types available in a region and zone
+// matching the filter. Nil filter returns all types.
+func (iii *InstanceTypeInfo) InstanceTypesForZone(region, zone string, filter *InstanceTypeFilter) ([]*InstanceType, error) {
 	names, err := iii.RegionalAvailability.NamesForZone(region, zone)
 	if err != nil {
 		return nil, err
@@ -21,7 +23,7 @@ func (iii *InstanceTypeInfo) InstanceTypesForZone(region, zone string, supported
 	result := make([]*InstanceType, 0, len(names))
 	for _, name := range names {
 		rt := iii.RegisteredTypes.Get(name)
-		if supported != nil && *supported != rt.Supported {
+		if rt == nil || !filter.Match(rt) {
 			continue
 		}
 		result = append(result, rt)
@@ -37,6 +37,18 @@ type ImageBuilder interface {
 	// GetAWSAmi returns related AWS image AMI identifier
 	GetAWSAmi(ctx context.Context, composeID string) (string, error)
 
+	// GetAWSAmiInRegion returns related AWS image AMI identifier in the region, either of the compose
+	// itself or of its successful clone. Returns http.ImageNotInRegionErr when the image must be cloned.
+	GetAWSAmiInRegion(ctx context.Context, composeID, region string) (string, error)
+
+	// CloneAWSImage triggers clone of the compose into the region and shares it with the source account.
+	// An existing clone into the region which did not fail is reused. Returns the clone identifier.
+	CloneAWSImage(ctx context.Context, composeID, region, sourceID string) (string, error)
+
+	// GetAWSCloneAmi returns AMI identifier of a finished clone, http.ImageStatusErr is returned
+	// while the clone is in progress and http.CloneFailedErr when it failed.
+	GetAWSCloneAmi(ctx context.Context, cloneID string) (string, error)
+
 	// GetAzureImageID returns partial image id, that is missing the subscription prefix
 	// Full name is /subscriptions/<subscription-id>/resourceGroups/<Group>/providers/Microsoft.Compute/images/<ImageName>
 	// GetAzureImageID returns /resourceGroups/<Group>/providers/Microsoft.Compute/images/<ImageName>
@@ -45,10 +57,34 @@ type ImageBuilder interface {
 	// GetGCPImageName returns GCP image name
 	GetGCPImageName(ctx context.Context, composeID string) (string, error)
 
+	// ListImages returns all images uploaded to cloud providers for the organization. Build
+	// status is not checked, images which failed to build are also returned.
+	ListImages(ctx context.Context) ([]*Image, error)
+
 	// Ready returns readiness information
 	Ready(ctx context.Context) error
 }
 
+// GetKeyHostClient returns KeyHost interface implementation. There are currently
+// two implementations available: HTTP and stub
+var GetKeyHostClient func(ctx context.Context) (KeyHost, error)
+
+// KeyHost interface provides access to public SSH keys published on code hosting sites
+type KeyHost interface {
+	// ListUserKeys returns public keys in authorized_keys format published by the user on given site
+	ListUserKeys(ctx context.Context, site KeyHostSite, username string) ([]string, error)
+}
+
+// GetRBACClient returns RBAC interface implementation. There are currently
+// two implementations available: HTTP and stub
+var GetRBACClient func(ctx context.Context) (RBAC, error)
+
+// RBAC interface provides access to the platform role-based access control service API
+type RBAC interface {
+	// GetPrincipalAccess returns provisioning permissions of the principal from the context identity
+	GetPrincipalAccess(ctx context.Context) (*AccessList, error)
+}
+
 // ClientStatuser provides a function to test client connection. Since most clouds do not
 // provide any "ping" or "status" call, it is usually implemented via some "cheap" operation
 // which is fast and returns minimum amount of data (e.g. list regions or ssh-keys).
@@ -56,7 +92,8 @@ type ClientStatuser interface {
 	Status(ctx context.Context) error
 }
 
-// GetEC2Client returns an EC2 facade interface with assumed role.
+// GetEC2Client returns an EC2 facade interface with assumed role. Blank region is the default
+// region of the AWS partition of the role.
 var GetEC2Client func(ctx context.Context, auth *Authentication, region string) (EC2, error)
 
 // GetServiceEC2Client returns an EC2 client for the service account.
@@ -92,12 +129,49 @@ type EC2 interface {
 	//
 	RunInstances(ctx context.Context, details *AWSInstanceParams, amount int32, name *string) ([]*string, *string, error)
 
+	// DryRunInstances checks whether RunInstances with the parameters would succeed without
+	// launching anything. Provider errors are returned as the verdict, error is only returned
+	// when the request cannot be performed.
+	DryRunInstances(ctx context.Context, details *AWSInstanceParams, amount int32, name *string) (*DryRunResult, error)
+
+	// LaunchFleet launches instances via an instant EC2 Fleet, instance types are tried in the
+	// given order until there is enough capacity. Instance type of details is ignored, launch
+	// template is not supported. Returns instance IDs, fleet ID and the launched instance type.
+	LaunchFleet(ctx context.Context, details *AWSInstanceParams, instanceTypes []InstanceTypeName, amount int32, name *string) ([]*string, *string, InstanceTypeName, error)
+
+	// TerminateInstances terminates instances with given IDs.
+	TerminateInstances(ctx context.Context, instanceIds []string) error
+
+	// StopInstances stops instances with given IDs and waits until they are stopped.
+	StopInstances(ctx context.Context, instanceIds []string) error
+
+	// StartInstances starts stopped instances with given IDs and waits until they are running.
+	StartInstances(ctx context.Context, instanceIds []string) error
+
+	// RebootInstances requests reboot of instances with given IDs.
+	RebootInstances(ctx context.Context, instanceIds []string) error
+
 	// GetAccountId returns AWS account number.
 	GetAccountId(ctx context.Context) (string, error)
 
 	CheckPermission(ctx context.Context, auth *Authentication) ([]string, error)
 
+	// ValidatePermissions simulates the role policy for every action the service needs, policy
+	// documents are read instead when the role is not allowed to simulate its own policy.
+	ValidatePermissions(ctx context.Context, auth *Authentication) ([]PermissionCheck, error)
+
 	DescribeInstanceDetails(ctx context.Context, InstanceIds []string) ([]*InstanceDescription, error)
+
+	// GetConsoleOutput returns the console output of an instance.
+	GetConsoleOutput(ctx context.Context, instanceID string) (*ConsoleOutput, error)
+
+	// GetImageArchitecture returns architecture of an AMI, error wrapping NotFoundErr is
+	// returned when the AMI does not exist or is not shared with the account.
+	GetImageArchitecture(ctx context.Context, ami string) (ArchitectureType, error)
+
+	// GetInstanceTypeArchitectures returns architectures supported by an instance type, error
+	// wrapping InstanceTypeNotOfferedErr is returned when the type is not offered in the region.
+	GetInstanceTypeArchitectures(ctx context.Context, name InstanceTypeName) ([]ArchitectureType, error)
 }
 
 // GetAzureClient returns an Azure client with customer's subscription ID.
@@ -112,14 +186,54 @@ type Azure interface {
 	// TenantId returns current subscription's tenant
 	TenantId(ctx context.Context) (AzureTenantId, error)
 
-	// EnsureResourceGroup makes sure that group with give name exists in a location
-	EnsureResourceGroup(ctx context.Context, name string, location string) (*string, error)
+	// EnsureResourceGroup makes sure that group with give name exists in a location, tags are
+	// only set when the group is created.
+	EnsureResourceGroup(ctx context.Context, name string, location string, tags map[string]string) (*string, error)
+
+	// DeleteResourceGroup deletes a resource group including all resources in it and waits until
+	// the deletion finishes.
+	DeleteResourceGroup(ctx context.Context, name string) error
 
 	// CreateVMs creates multiple Azure virtual machines
 	// Returns array of instance IDs and error if something went wrong
 	CreateVMs(ctx context.Context, instanceParams AzureInstanceParams, amount int64, vmNamePrefix string) (vmIds []InstanceDescription, err error)
 
+	// ValidateVMs validates creation of VMs as CreateVMs would create them without creating any
+	// resources. Provider errors are returned as the verdict, error is only returned when the
+	// validation cannot be performed.
+	ValidateVMs(ctx context.Context, instanceParams AzureInstanceParams, amount int64, vmNamePrefix string) (*DryRunResult, error)
+
 	ListResourceGroups(ctx context.Context) ([]string, error)
+
+	// GetInstanceDescriptionByID returns the current state and addresses of a VM with the full
+	// resource ID.
+	GetInstanceDescriptionByID(ctx context.Context, id string) (*InstanceDescription, error)
+
+	// GetConsoleOutput returns the serial log of boot diagnostics of a VM with the full resource
+	// ID, boot diagnostics must be enabled on the VM.
+	GetConsoleOutput(ctx context.Context, id string) (*ConsoleOutput, error)
+
+	// StopVM deallocates a VM with the full resource ID and waits until it is deallocated.
+	StopVM(ctx context.Context, id string) error
+
+	// StartVM starts a VM with the full resource ID and waits until it is running.
+	StartVM(ctx context.Context, id string) error
+
+	// RestartVM restarts a VM with the full resource ID and waits until it is running.
+	RestartVM(ctx context.Context, id string) error
+
+	// GetImageArchitecture returns architecture of a managed image or a gallery image version,
+	// error wrapping NotFoundErr is returned when the image does not exist. Empty architecture
+	// is returned for other image IDs which cannot be verified.
+	GetImageArchitecture(ctx context.Context, imageID string) (ArchitectureType, error)
+
+	// GetVMSizeQuota returns family, restriction and vCPU quota usage of a VM size in a location,
+	// error wrapping VMSizeNotAvailableErr is returned when the size is not offered there.
+	GetVMSizeQuota(ctx context.Context, location string, size InstanceTypeName) (*AzureVMSizeQuota, error)
+
+	// ValidatePermissions checks every action the service needs against permissions granted to
+	// the application in the subscription by its role assignments.
+	ValidatePermissions(ctx context.Context) ([]PermissionCheck, error)
 }
 
 type ServiceAzure interface {
@@ -151,10 +265,45 @@ type GCP interface {
 	// InsertInstances launches one or more instances and returns a list of instances ids that were created, the GCP operation name and error
 	InsertInstances(ctx context.Context, params *GCPInstanceParams, amount int64) ([]*string, *string, error)
 
+	// DryRunInstances checks whether InsertInstances with the parameters would succeed without
+	// launching anything. Provider errors are returned as the verdict, error is only returned
+	// when the checks cannot be performed.
+	DryRunInstances(ctx context.Context, params *GCPInstanceParams, amount int64) (*DryRunResult, error)
+
 	// List of instance IDs associated with a specific label UUID, which serves as a unique identifier for the reservation used when creating these instances
 	ListInstancesIDsByLabel(ctx context.Context, uuid string) ([]*string, error)
 
 	GetInstanceDescriptionByID(ctx context.Context, id, zone string) (*InstanceDescription, error)
 
+	// GetConsoleOutput returns the output of the first serial port of an instance.
+	GetConsoleOutput(ctx context.Context, id, zone string) (*ConsoleOutput, error)
+
+	// StopInstance stops an instance and waits until the operation finishes.
+	StopInstance(ctx context.Context, id, zone string) error
+
+	// StartInstance starts a stopped instance and waits until the operation finishes.
+	StartInstance(ctx context.Context, id, zone string) error
+
+	// ResetInstance resets an instance and waits until the operation finishes.
+	ResetInstance(ctx context.Context, id, zone string) error
+
 	ListLaunchTemplates(ctx context.Context) ([]*LaunchTemplate, error)
+
+	// GetImageArchitecture returns architecture of an image or the latest image of a family,
+	// error wrapping NotFoundErr is returned when the image does not exist. Empty architecture
+	// is returned when the image does not specify it.
+	GetImageArchitecture(ctx context.Context, imageName string) (ArchitectureType, error)
+
+	// CheckServiceAccount verifies the service account email exists in the project and is enabled,
+	// error wrapping ServiceAccountNotFoundErr or ServiceAccountDisabledErr is returned otherwise.
+	CheckServiceAccount(ctx context.Context, email string) error
+
+	// CheckNetwork verifies the network and the subnetwork exist and the subnetwork belongs to the
+	// network, either can be empty. Paths are in the "global/networks/NAME" and
+	// "regions/REGION/subnetworks/NAME" forms optionally prefixed with "projects/PROJECT/" for
+	// shared VPC host projects, the customer project is used otherwise.
+	CheckNetwork(ctx context.Context, network, subnetwork string) error
+
+	// ValidatePermissions tests every permission the service needs on the project.
+	ValidatePermissions(ctx context.Context) ([]PermissionCheck, error)
 }
@@ -0,0 +1,27 @@
+package clients
+
+import "strings"
+
+// KeyHostSite is a code hosting site which publishes public SSH keys of its users.
+type KeyHostSite string
+
+const (
+	KeyHostGitHub KeyHostSite = "github"
+	KeyHostGitLab KeyHostSite = "gitlab"
+)
+
+// KeyHostSiteFromString returns site for given string or an empty string for unknown sites.
+func KeyHostSiteFromString(str string) KeyHostSite {
+	switch strings.ToLower(str) {
+	case "github":
+		return KeyHostGitHub
+	case "gitlab":
+		return KeyHostGitLab
+	default:
+		return ""
+	}
+}
+
+func (s KeyHostSite) String() string {
+	return string(s)
+}
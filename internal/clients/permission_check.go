@@ -0,0 +1,11 @@
+package clients
+
+// PermissionCheck is a result of a single item of the provider permission checklist.
+type PermissionCheck struct {
+	// Permission as named by the provider (e.g. ec2:RunInstances, compute.instances.create or
+	// Microsoft.Compute/virtualMachines/write)
+	Permission string
+
+	// Allowed is true when the source credentials are granted the permission
+	Allowed bool
+}
@@ -0,0 +1,52 @@
+package clients
+
+import "strings"
+
+// Permission is an RBAC permission in the form "application:resource:verb".
+type Permission string
+
+const (
+	ReservationWritePermission Permission = "provisioning:reservation:write"
+	PubkeyWritePermission      Permission = "provisioning:pubkey:write"
+	SettingsWritePermission    Permission = "provisioning:settings:write"
+	WebhookWritePermission     Permission = "provisioning:webhook:write"
+	AdminReadPermission        Permission = "provisioning:admin:read"
+	AdminWritePermission       Permission = "provisioning:admin:write"
+)
+
+func (p Permission) String() string {
+	return string(p)
+}
+
+// AccessList is the list of permissions granted to a principal by the RBAC service.
+type AccessList struct {
+	Permissions []string
+}
+
+func (a AccessList) CacheKeyName() string {
+	return "rbac_access"
+}
+
+// Allows returns true when one of the granted permissions matches the permission, the resource
+// and verb of a granted permission can be a "*" wildcard.
+func (a *AccessList) Allows(permission Permission) bool {
+	required := strings.Split(string(permission), ":")
+	for _, granted := range a.Permissions {
+		if permissionMatches(strings.Split(granted, ":"), required) {
+			return true
+		}
+	}
+	return false
+}
+
+func permissionMatches(granted, required []string) bool {
+	if len(granted) != len(required) {
+		return false
+	}
+	for i := range granted {
+		if granted[i] != required[i] && (i == 0 || granted[i] != "*") {
+			return false
+		}
+	}
+	return true
+}
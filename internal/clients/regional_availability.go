@@ -69,6 +69,45 @@ func (rit *RegionalTypeAvailability) Add(region, zone string, it InstanceType) {
 	}
 }
 
+// Set replaces list of instance type names for a region and zone.
+func (rit *RegionalTypeAvailability) Set(region, zone string, names []InstanceTypeName) {
+	sorted := make(sortableInstanceTypeName, len(names))
+	copy(sorted, names)
+	slices.Sort(sorted)
+	rit.types[key(region, zone)] = sorted
+}
+
+// Contains returns true when there is information for region and zone in the key format
+// "region_zone" or "region" for providers without zones.
+func (rit *RegionalTypeAvailability) Contains(regionZone string) bool {
+	_, ok := rit.types[regionZone]
+	return ok
+}
+
+// Each calls the function for every region and zone with the sorted list of type names.
+func (rit *RegionalTypeAvailability) Each(fn func(region, zone string, names []InstanceTypeName) error) error {
+	for raz, names := range rit.types {
+		region, zone, err := splitRegionZone(raz)
+		if err != nil {
+			return err
+		}
+		err = fn(region, zone, names)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bytes returns a stable serialized form of the data, e.g. for etag calculation.
+func (rit *RegionalTypeAvailability) Bytes() ([]byte, error) {
+	buffer, err := yaml.Marshal(rit.types)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal availability: %w", err)
+	}
+	return buffer, nil
+}
+
 func (rit *RegionalTypeAvailability) Save(directory string) error {
 	for key, value := range rit.types {
 		slices.Sort(value)
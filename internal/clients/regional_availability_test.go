@@ -41,3 +41,28 @@ func TestAddSmallDifferentZones(t *testing.T) {
 	require.Equal(t, "\nRegion 'region' availability zone 'zone1': small\n", rit.Sprint("region", "zone1"))
 	require.Equal(t, "\nRegion 'region' availability zone 'zone2': small\n", rit.Sprint("region", "zone2"))
 }
+
+func TestSetAndContains(t *testing.T) {
+	rit := NewRegionalInstanceTypes()
+	rit.Set("region", "zone", []InstanceTypeName{"b", "a"})
+	require.True(t, rit.Contains("region_zone"))
+	require.False(t, rit.Contains("region"))
+	require.Equal(t, "\nRegion 'region' availability zone 'zone': a, b\n", rit.Sprint("region", "zone"))
+}
+
+func TestEach(t *testing.T) {
+	rit := NewRegionalInstanceTypes()
+	rit.Add("region1", "zone", smallType)
+	rit.Add("region2", "", smallType)
+
+	result := make(map[string][]InstanceTypeName)
+	err := rit.Each(func(region, zone string, names []InstanceTypeName) error {
+		result[region+"/"+zone] = names
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]InstanceTypeName{
+		"region1/zone": {"small"},
+		"region2/":     {"small"},
+	}, result)
+}
@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 )
 
 var ErrNotStartedVM = errors.New("the VM under given resumeToken not started")
@@ -32,6 +36,24 @@ func DidCreateAzureResourceGroup(ctx context.Context, name string) bool {
 	return false
 }
 
+// StubAzureResourceGroupTags returns tags of a created resource group or nil when not created.
+func StubAzureResourceGroupTags(ctx context.Context, name string) map[string]string {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return nil
+	}
+	for _, rg := range client.createdRgs {
+		if *rg.Name == name {
+			tags := make(map[string]string, len(rg.Tags))
+			for key, value := range rg.Tags {
+				tags[key] = *value
+			}
+			return tags
+		}
+	}
+	return nil
+}
+
 func CountStubAzureVMs(ctx context.Context) int {
 	client, err := getAzureClientStub(ctx)
 	if err != nil {
@@ -40,6 +62,21 @@ func CountStubAzureVMs(ctx context.Context) int {
 	return len(client.createdVms)
 }
 
+// CountStubAzureSpotVMs returns number of created spot VMs.
+func CountStubAzureSpotVMs(ctx context.Context) int {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, vm := range client.createdVms {
+		if vm.Properties != nil && vm.Properties.Priority != nil && *vm.Properties.Priority == armcompute.VirtualMachinePriorityTypesSpot {
+			count++
+		}
+	}
+	return count
+}
+
 func (stub *AzureClientStub) Status(ctx context.Context) error {
 	return nil
 }
@@ -76,6 +113,11 @@ func (stub *AzureClientStub) BeginCreateVM(ctx context.Context, vmParams clients
 		Name:     &vmName,
 		Location: &vmParams.Location,
 	}
+	if vmParams.Priority == models.AzurePrioritySpot {
+		vm.Properties = &armcompute.VirtualMachineProperties{
+			Priority: ptr.To(armcompute.VirtualMachinePriorityTypesSpot),
+		}
+	}
 	stub.startedVms = append(stub.startedVms, &vm)
 	// we use the id as a resume token
 	return id, nil
@@ -92,22 +134,117 @@ func (stub *AzureClientStub) WaitForVM(ctx context.Context, resumeToken string)
 	return "", ErrNotStartedVM
 }
 
-func (stub *AzureClientStub) EnsureResourceGroup(ctx context.Context, name string, location string) (*string, error) {
+func (stub *AzureClientStub) EnsureResourceGroup(ctx context.Context, name string, location string, tags map[string]string) (*string, error) {
 	id := strconv.Itoa(len(stub.createdRgs) + 1)
 
 	rg := armresources.ResourceGroup{
 		ID:       &id,
 		Name:     &name,
 		Location: &location,
+		Tags:     make(map[string]*string, len(tags)),
+	}
+	for key, value := range tags {
+		value := value
+		rg.Tags[key] = &value
 	}
 	stub.createdRgs = append(stub.createdRgs, &rg)
 	return &id, nil
 }
 
+func (stub *AzureClientStub) DeleteResourceGroup(ctx context.Context, name string) error {
+	for i, rg := range stub.createdRgs {
+		if *rg.Name == name {
+			stub.createdRgs = append(stub.createdRgs[:i], stub.createdRgs[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func (stub *AzureClientStub) TenantId(ctx context.Context) (clients.AzureTenantId, error) {
 	return "4645f0cb-43f5-4586-b2c9-8d5c58577e3e", nil
 }
 
+// ValidateVMs passes unless the instance size contains "unavailable".
+func (stub *AzureClientStub) ValidateVMs(ctx context.Context, vmParams clients.AzureInstanceParams, amount int64, vmNamePrefix string) (*clients.DryRunResult, error) {
+	if strings.Contains(string(vmParams.InstanceType), "unavailable") {
+		return &clients.DryRunResult{
+			Code:    "InvalidTemplateDeployment",
+			Message: "The requested size for resource is currently not available in location.",
+		}, nil
+	}
+	return &clients.DryRunResult{Passed: true}, nil
+}
+
 func (stub *AzureClientStub) ListResourceGroups(ctx context.Context) ([]string, error) {
 	return []string{"firstGroup", "secondGroup", "test"}, nil
 }
+
+func (stub *AzureClientStub) GetInstanceDescriptionByID(ctx context.Context, id string) (*clients.InstanceDescription, error) {
+	for i, vm := range stub.createdVms {
+		if *vm.ID == id {
+			return &clients.InstanceDescription{ID: id, PublicIPv4: fmt.Sprintf("198.51.100.%d", i+1), State: "running"}, nil
+		}
+	}
+	return nil, MissingInstanceIDErr
+}
+
+func (stub *AzureClientStub) GetConsoleOutput(ctx context.Context, id string) (*clients.ConsoleOutput, error) {
+	for _, vm := range stub.createdVms {
+		if *vm.ID == id {
+			return &clients.ConsoleOutput{Output: fmt.Sprintf("%s login:", *vm.Name)}, nil
+		}
+	}
+	return nil, MissingInstanceIDErr
+}
+
+func (stub *AzureClientStub) StopVM(ctx context.Context, id string) error {
+	return stub.findCreatedVM(id)
+}
+
+func (stub *AzureClientStub) StartVM(ctx context.Context, id string) error {
+	return stub.findCreatedVM(id)
+}
+
+func (stub *AzureClientStub) RestartVM(ctx context.Context, id string) error {
+	return stub.findCreatedVM(id)
+}
+
+func (stub *AzureClientStub) findCreatedVM(id string) error {
+	for _, vm := range stub.createdVms {
+		if *vm.ID == id {
+			return nil
+		}
+	}
+	return MissingInstanceIDErr
+}
+
+func (stub *AzureClientStub) GetImageArchitecture(ctx context.Context, imageID string) (clients.ArchitectureType, error) {
+	return stubImageArchitecture(imageID)
+}
+
+// GetVMSizeQuota returns a family with 10 vCPUs limit and no usage, every size has 2 vCPUs.
+// Size "missing" is not available and size "restricted" is restricted for the subscription.
+func (stub *AzureClientStub) GetVMSizeQuota(ctx context.Context, location string, size clients.InstanceTypeName) (*clients.AzureVMSizeQuota, error) {
+	quota := &clients.AzureVMSizeQuota{
+		Family:        "standardBasicAFamily",
+		VCPUs:         2,
+		FamilyUsage:   clients.AzureQuotaUsage{Name: "Basic A Family vCPUs", Limit: 10},
+		RegionalUsage: clients.AzureQuotaUsage{Name: "Total Regional vCPUs", Limit: 100},
+		SpotUsage:     clients.AzureQuotaUsage{Name: "Total Regional Spot vCPUs", Limit: 100},
+	}
+	switch size {
+	case "missing":
+		return nil, http.VMSizeNotAvailableErr
+	case "restricted":
+		quota.Restriction = "NotAvailableForSubscription"
+	}
+	return quota, nil
+}
+
+// ValidatePermissions allows all permissions.
+func (stub *AzureClientStub) ValidatePermissions(ctx context.Context) ([]clients.PermissionCheck, error) {
+	return []clients.PermissionCheck{
+		{Permission: "Microsoft.Compute/virtualMachines/write", Allowed: true},
+	}, nil
+}
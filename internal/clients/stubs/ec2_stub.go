@@ -3,6 +3,7 @@ package stubs
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
@@ -16,7 +17,13 @@ type ec2CtxKeyType int
 const ec2CtxKey ec2CtxKeyType = iota
 
 type EC2ClientStub struct {
-	Imported []*types.KeyPairInfo
+	Imported       []*types.KeyPairInfo
+	Deleted        []string
+	Terminated     []string
+	Stopped        []string
+	Started        []string
+	Rebooted       []string
+	AccountIdCalls int
 }
 
 func init() {
@@ -89,6 +96,7 @@ func (mock *EC2ClientStub) GetPubkeyName(ctx context.Context, fingerprint string
 }
 
 func (mock *EC2ClientStub) DeleteSSHKey(ctx context.Context, handle string) error {
+	mock.Deleted = append(mock.Deleted, handle)
 	return nil
 }
 
@@ -159,12 +167,63 @@ func (mock *EC2ClientStub) CheckPermission(ctx context.Context, auth *clients.Au
 	return nil, nil
 }
 
+// ValidatePermissions allows all permissions except launch templates creation.
+func (mock *EC2ClientStub) ValidatePermissions(ctx context.Context, auth *clients.Authentication) ([]clients.PermissionCheck, error) {
+	return []clients.PermissionCheck{
+		{Permission: "ec2:RunInstances", Allowed: true},
+		{Permission: "ec2:CreateLaunchTemplate", Allowed: false},
+	}, nil
+}
+
 func (mock *EC2ClientStub) RunInstances(ctx context.Context, details *clients.AWSInstanceParams, amount int32, name *string) ([]*string, *string, error) {
 	return nil, nil, nil
 }
 
+// DryRunInstances passes unless the AMI contains "unauthorized".
+func (mock *EC2ClientStub) DryRunInstances(ctx context.Context, details *clients.AWSInstanceParams, amount int32, name *string) (*clients.DryRunResult, error) {
+	if strings.Contains(details.AMI, "unauthorized") {
+		return &clients.DryRunResult{
+			Code:               "UnauthorizedOperation",
+			Message:            "You are not authorized to perform this operation.",
+			MissingPermissions: []string{"ec2:RunInstances"},
+		}, nil
+	}
+	return &clients.DryRunResult{Passed: true, Code: "DryRunOperation"}, nil
+}
+
+// LaunchFleet returns the first instance type not containing "missing" as the launched type.
+func (mock *EC2ClientStub) LaunchFleet(ctx context.Context, details *clients.AWSInstanceParams, instanceTypes []clients.InstanceTypeName, amount int32, name *string) ([]*string, *string, clients.InstanceTypeName, error) {
+	for _, instanceType := range instanceTypes {
+		if !strings.Contains(string(instanceType), "missing") {
+			return nil, nil, instanceType, nil
+		}
+	}
+	return nil, nil, "", http.FleetCapacityErr
+}
+
+func (mock *EC2ClientStub) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	mock.Terminated = append(mock.Terminated, instanceIds...)
+	return nil
+}
+
+func (mock *EC2ClientStub) StopInstances(ctx context.Context, instanceIds []string) error {
+	mock.Stopped = append(mock.Stopped, instanceIds...)
+	return nil
+}
+
+func (mock *EC2ClientStub) StartInstances(ctx context.Context, instanceIds []string) error {
+	mock.Started = append(mock.Started, instanceIds...)
+	return nil
+}
+
+func (mock *EC2ClientStub) RebootInstances(ctx context.Context, instanceIds []string) error {
+	mock.Rebooted = append(mock.Rebooted, instanceIds...)
+	return nil
+}
+
 func (mock *EC2ClientStub) GetAccountId(ctx context.Context) (string, error) {
-	return "", nil
+	mock.AccountIdCalls++
+	return "230214684733", nil
 }
 
 func (mock *EC2ClientStub) DescribeInstanceDetails(ctx context.Context, InstanceIds []string) ([]*clients.InstanceDescription, error) {
@@ -176,6 +235,43 @@ func (mock *EC2ClientStub) DescribeInstanceDetails(ctx context.Context, Instance
 			ID:         id,
 			PublicDNS:  dns,
 			PublicIPv4: ip,
+			State:      "running",
 		},
 	}, nil
 }
+
+func (mock *EC2ClientStub) GetConsoleOutput(ctx context.Context, instanceID string) (*clients.ConsoleOutput, error) {
+	return &clients.ConsoleOutput{Output: fmt.Sprintf("%s login:", instanceID)}, nil
+}
+
+func (mock *EC2ClientStub) GetImageArchitecture(ctx context.Context, ami string) (clients.ArchitectureType, error) {
+	return stubImageArchitecture(ami)
+}
+
+// GetInstanceTypeArchitectures returns not offered error for types containing "missing",
+// architecture of types returned by ListInstanceTypes and x86_64 for all other types.
+func (mock *EC2ClientStub) GetInstanceTypeArchitectures(ctx context.Context, name clients.InstanceTypeName) ([]clients.ArchitectureType, error) {
+	if strings.Contains(string(name), "missing") {
+		return nil, http.InstanceTypeNotOfferedErr
+	}
+	types, _ := mock.ListInstanceTypes(ctx)
+	for _, it := range types {
+		if it.Name == name {
+			return []clients.ArchitectureType{it.Architecture}, nil
+		}
+	}
+	return []clients.ArchitectureType{clients.ArchitectureTypeX86_64}, nil
+}
+
+// stubImageArchitecture returns arm64 for image IDs containing "arm64", not found error for
+// image IDs containing "missing" and x86_64 for all other images.
+func stubImageArchitecture(imageID string) (clients.ArchitectureType, error) {
+	switch {
+	case strings.Contains(imageID, "missing"):
+		return "", http.ImageNotFoundErr
+	case strings.Contains(imageID, "arm64"):
+		return clients.ArchitectureTypeArm64, nil
+	default:
+		return clients.ArchitectureTypeX86_64, nil
+	}
+}
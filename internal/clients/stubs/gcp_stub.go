@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 )
 
@@ -20,7 +22,8 @@ var (
 
 type (
 	GCPClientStub struct {
-		Instances []*string
+		Instances    []*string
+		insertParams *clients.GCPInstanceParams
 	}
 	GCPServiceClientStub struct{}
 )
@@ -72,6 +75,15 @@ func CountStubInstancesGCP(ctx context.Context) int {
 	return len(client.Instances)
 }
 
+// StubGCPInsertParams returns parameters of the last instances insert or nil.
+func StubGCPInsertParams(ctx context.Context) *clients.GCPInstanceParams {
+	client, err := getCustomerGCPClientStub(ctx, &clients.Authentication{})
+	if err != nil {
+		return nil
+	}
+	return client.insertParams
+}
+
 func (mock *GCPClientStub) ListAllRegions(ctx context.Context) ([]clients.Region, error) {
 	return nil, nil
 }
@@ -83,7 +95,7 @@ func (mock *GCPClientStub) Status(ctx context.Context) error {
 func (mock *GCPClientStub) GetInstanceDescriptionByID(ctx context.Context, id, zone string) (*clients.InstanceDescription, error) {
 	for _, instanceID := range mock.Instances {
 		if ptr.From(instanceID) == id {
-			instanceDesc := &clients.InstanceDescription{ID: id, PublicIPv4: fmt.Sprintf("10.0.0.%v", ipCounter)}
+			instanceDesc := &clients.InstanceDescription{ID: id, PublicIPv4: fmt.Sprintf("10.0.0.%v", ipCounter), State: "running"}
 			ipCounter = ipCounter + 1
 			return instanceDesc, nil
 		}
@@ -91,11 +103,42 @@ func (mock *GCPClientStub) GetInstanceDescriptionByID(ctx context.Context, id, z
 	return nil, MissingInstanceIDErr
 }
 
+func (mock *GCPClientStub) GetConsoleOutput(ctx context.Context, id, zone string) (*clients.ConsoleOutput, error) {
+	for _, instanceID := range mock.Instances {
+		if ptr.From(instanceID) == id {
+			return &clients.ConsoleOutput{Output: fmt.Sprintf("%s login:", id)}, nil
+		}
+	}
+	return nil, MissingInstanceIDErr
+}
+
+func (mock *GCPClientStub) StopInstance(ctx context.Context, id, zone string) error {
+	return mock.findInstance(id)
+}
+
+func (mock *GCPClientStub) StartInstance(ctx context.Context, id, zone string) error {
+	return mock.findInstance(id)
+}
+
+func (mock *GCPClientStub) ResetInstance(ctx context.Context, id, zone string) error {
+	return mock.findInstance(id)
+}
+
+func (mock *GCPClientStub) findInstance(id string) error {
+	for _, instanceID := range mock.Instances {
+		if ptr.From(instanceID) == id {
+			return nil
+		}
+	}
+	return MissingInstanceIDErr
+}
+
 func (mock *GCPClientStub) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchTemplate, error) {
 	return nil, nil
 }
 
 func (mock *GCPClientStub) InsertInstances(ctx context.Context, params *clients.GCPInstanceParams, amount int64) ([]*string, *string, error) {
+	mock.insertParams = params
 	for i := 0; i < int(amount); i++ {
 		ID := fmt.Sprintf("300394200587658274%s", strconv.Itoa(len(mock.Instances)+1))
 		mock.Instances = append(mock.Instances, &ID)
@@ -104,6 +147,17 @@ func (mock *GCPClientStub) InsertInstances(ctx context.Context, params *clients.
 	return ids, ptr.To("operation-1686646674436-5fdff07e43209-66146b7e-f3f65ec5"), err
 }
 
+// DryRunInstances passes unless the launch template name contains "missing".
+func (mock *GCPClientStub) DryRunInstances(ctx context.Context, params *clients.GCPInstanceParams, amount int64) (*clients.DryRunResult, error) {
+	if strings.Contains(params.LaunchTemplateName, "missing") {
+		return &clients.DryRunResult{
+			Code:    "NOT_FOUND",
+			Message: fmt.Sprintf("Instance template %s was not found.", params.LaunchTemplateName),
+		}, nil
+	}
+	return &clients.DryRunResult{Passed: true}, nil
+}
+
 func (mock *GCPClientStub) ListInstancesIDsByLabel(ctx context.Context, _ string) ([]*string, error) {
 	return mock.Instances, nil
 }
@@ -131,3 +185,36 @@ func (mock *GCPServiceClientStub) ListAllRegionsAndZones(ctx context.Context) ([
 	}
 	return regions, zones, nil
 }
+
+func (mock *GCPClientStub) GetImageArchitecture(ctx context.Context, imageName string) (clients.ArchitectureType, error) {
+	return stubImageArchitecture(imageName)
+}
+
+// CheckServiceAccount accepts any service account except emails starting with "missing" or "disabled".
+func (mock *GCPClientStub) CheckServiceAccount(ctx context.Context, email string) error {
+	switch {
+	case strings.HasPrefix(email, "missing"):
+		return http.ServiceAccountNotFoundErr
+	case strings.HasPrefix(email, "disabled"):
+		return http.ServiceAccountDisabledErr
+	}
+	return nil
+}
+
+// CheckNetwork accepts any network and subnetwork except paths ending with "missing".
+func (mock *GCPClientStub) CheckNetwork(ctx context.Context, network, subnetwork string) error {
+	if strings.HasSuffix(network, "missing") {
+		return http.NetworkNotFoundErr
+	}
+	if strings.HasSuffix(subnetwork, "missing") {
+		return http.SubnetworkNotFoundErr
+	}
+	return nil
+}
+
+// ValidatePermissions allows all permissions.
+func (mock *GCPClientStub) ValidatePermissions(ctx context.Context) ([]clients.PermissionCheck, error) {
+	return []clients.PermissionCheck{
+		{Permission: "compute.instances.create", Allowed: true},
+	}, nil
+}
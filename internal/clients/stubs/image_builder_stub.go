@@ -2,8 +2,11 @@ package stubs
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 )
 
 type imageBuilderCtxKeyType string
@@ -37,6 +40,22 @@ func (mock *ImageBuilderClientStub) GetAWSAmi(ctx context.Context, composeID str
 	return "ami-0c830793775595d4b-test", nil
 }
 
+// GetAWSAmiInRegion returns the AMI for all regions except "eu-west-3" where the image must be cloned
+func (mock *ImageBuilderClientStub) GetAWSAmiInRegion(ctx context.Context, composeID, region string) (string, error) {
+	if region == "eu-west-3" {
+		return "", fmt.Errorf("%w: %s", http.ImageNotInRegionErr, region)
+	}
+	return "ami-0c830793775595d4b-test", nil
+}
+
+func (mock *ImageBuilderClientStub) CloneAWSImage(ctx context.Context, composeID, region, sourceID string) (string, error) {
+	return "3f7a2c1e-5b1d-4a8e-9c2f-0d6e8b4a1c3d", nil
+}
+
+func (mock *ImageBuilderClientStub) GetAWSCloneAmi(ctx context.Context, cloneID string) (string, error) {
+	return "ami-0e1c5a2d3b4f6a7c8-clone-test", nil
+}
+
 func (mock *ImageBuilderClientStub) GetAzureImageID(ctx context.Context, composeID string) (string, error) {
 	return "/resourceGroups/redhat-deployed/providers/Microsoft.Compute/images/composer-api-92ea98f8-7697-472e-80b1-7454fa0e7fa7", nil
 }
@@ -44,3 +63,29 @@ func (mock *ImageBuilderClientStub) GetAzureImageID(ctx context.Context, compose
 func (mock *ImageBuilderClientStub) GetGCPImageName(ctx context.Context, composeID string) (string, error) {
 	return "projects/red-hat-image-builder/global/images/composer-api-871fa36d-0b5b-4001-8c95-a11f751a4d66-test", nil
 }
+
+func (mock *ImageBuilderClientStub) ListImages(ctx context.Context) ([]*clients.Image, error) {
+	return []*clients.Image{
+		{
+			ID:           "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+			Name:         "aws-x86",
+			Distribution: "rhel-92",
+			Architecture: clients.ArchitectureTypeX86_64,
+			Provider:     models.ProviderTypeAWS,
+		},
+		{
+			ID:           "871fa36d-0b5b-4001-8c95-a11f751a4d66",
+			Name:         "aws-arm",
+			Distribution: "rhel-92",
+			Architecture: clients.ArchitectureTypeArm64,
+			Provider:     models.ProviderTypeAWS,
+		},
+		{
+			ID:           "f1e1c2a6-3b0d-4a59-9a8b-2c1c0b8e9d4f",
+			Name:         "azure-x86",
+			Distribution: "rhel-92",
+			Architecture: clients.ArchitectureTypeX86_64,
+			Provider:     models.ProviderTypeAzure,
+		},
+	}, nil
+}
@@ -0,0 +1,13 @@
+package stubs
+
+// Real clients register themselves in init functions as well. Importing them makes sure stubs
+// are registered last, regardless of the initialization order of packages under test.
+import (
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/azure"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/ec2"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/gcp"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/image_builder"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/keyhost"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/rbac"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
+)
@@ -0,0 +1,45 @@
+package stubs
+
+import (
+	"context"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+)
+
+type keyHostCtxKeyType string
+
+var keyHostCtxKey keyHostCtxKeyType = "keyhost-interface"
+
+// KeyHostClientStub returns two published keys for any user except "notfound".
+type KeyHostClientStub struct{}
+
+func init() {
+	clients.GetKeyHostClient = getKeyHostClientStub
+}
+
+func WithKeyHostClient(parent context.Context) context.Context {
+	ctx := context.WithValue(parent, keyHostCtxKey, &KeyHostClientStub{})
+	return ctx
+}
+
+func getKeyHostClientStub(ctx context.Context) (kh clients.KeyHost, err error) {
+	var ok bool
+	if kh, ok = ctx.Value(keyHostCtxKey).(*KeyHostClientStub); !ok {
+		err = ContextReadError
+	}
+	return kh, err
+}
+
+func (*KeyHostClientStub) ListUserKeys(ctx context.Context, site clients.KeyHostSite, username string) ([]string, error) {
+	if username == "notfound" {
+		return nil, clients.NotFoundErr
+	}
+	return []string{
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEhnn80ZywmjeBFFOGm+cm+5HUwm62qTVnjKlOdYFLHN",
+		"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC8w6DONv1qn3IdgxSpkYOClq7oe7davWFqKVHPbLoS6+dF" +
+			"Inru7gdEO5byhTih6+PwRhHv/b1I+Mtt5MDZ8Sv7XFYpX/3P/u5zQiy1PkMSFSz0brRRUfEQxhXLW97FJa7l+bej2HJ" +
+			"Dt7f9Gvcj+d/fNWC9Z58/GX11kWk4SIXaKotkN+kWn54xGGS7Zvtm86fP59Srt6wlklSsG8mZBF7jVUjyhAgm/V5gDF" +
+			"b2/6jfiwSb2HyJ9/NbhLkWNdwrvpdGZqQlYhnwTfEZdpwizW/Mj3MxP5O31HN45aE0wog0UeWY4gvTl4Ogb6kescizA" +
+			"M6pCff3RBslbFxLdOO7cR17",
+	}, nil
+}
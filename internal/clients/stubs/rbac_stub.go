@@ -0,0 +1,37 @@
+package stubs
+
+import (
+	"context"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+)
+
+type rbacCtxKeyType string
+
+var rbacCtxKey rbacCtxKeyType = "rbac-interface"
+
+// RBACClientStub grants the permissions it was created with.
+type RBACClientStub struct {
+	permissions []string
+}
+
+func init() {
+	clients.GetRBACClient = getRBACClientStub
+}
+
+func WithRBACClient(parent context.Context, permissions ...string) context.Context {
+	ctx := context.WithValue(parent, rbacCtxKey, &RBACClientStub{permissions: permissions})
+	return ctx
+}
+
+func getRBACClientStub(ctx context.Context) (rc clients.RBAC, err error) {
+	var ok bool
+	if rc, ok = ctx.Value(rbacCtxKey).(*RBACClientStub); !ok {
+		err = ContextReadError
+	}
+	return rc, err
+}
+
+func (stub *RBACClientStub) GetPrincipalAccess(_ context.Context) (*clients.AccessList, error) {
+	return &clients.AccessList{Permissions: stub.permissions}, nil
+}
@@ -1,7 +1,17 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -21,3 +31,105 @@ func TestBlankNon1(t *testing.T) {
 func TestBlankNon2(t *testing.T) {
 	require.True(t, present("x", "x"))
 }
+
+func TestWorkerTimeout(t *testing.T) {
+	original := *Worker
+	t.Cleanup(func() { *Worker = original })
+
+	Worker.Timeout = 30 * time.Minute
+	Worker.TypeTimeouts = map[string]time.Duration{"launch_instance_aws": 10 * time.Minute}
+
+	require.Equal(t, 10*time.Minute, WorkerTimeout("launch_instance_aws"))
+	require.Equal(t, 30*time.Minute, WorkerTimeout("refresh_availability"))
+}
+
+func TestIMDSv1Allowed(t *testing.T) {
+	original := Application.AWSMetadata
+	t.Cleanup(func() { Application.AWSMetadata = original })
+
+	Application.AWSMetadata.AllowIMDSv1 = false
+	Application.AWSMetadata.AllowIMDSv1Orgs = []string{"42"}
+	require.True(t, IMDSv1Allowed("42"))
+	require.False(t, IMDSv1Allowed("13"))
+
+	Application.AWSMetadata.AllowIMDSv1 = true
+	require.True(t, IMDSv1Allowed("13"))
+}
+
+func TestExternalIDRequired(t *testing.T) {
+	original := *AWS
+	t.Cleanup(func() { *AWS = original })
+
+	AWS.RequireExternalID = false
+	AWS.RequireExternalIDOrgs = []string{"42"}
+	require.True(t, ExternalIDRequired("42"))
+	require.False(t, ExternalIDRequired("13"))
+	require.False(t, ExternalIDRequired(""))
+
+	AWS.RequireExternalID = true
+	require.True(t, ExternalIDRequired("13"))
+}
+
+func TestValidateReport(t *testing.T) {
+	original := config
+	t.Cleanup(func() { config = original })
+
+	config = configuration{}
+	config.App.Port = 8000
+	config.Prometheus.Port = 9000
+	config.App.Cache.Type = "none"
+	config.Worker.Queue = "memory"
+	config.RestEndpoints.Sources.API = "v3.1"
+	config.GCP.JSON = "e30K"
+	config.App.AWSMetadata.HopLimit = 1
+	config.App.AzureResourceGroup.Pattern = "redhat-reservation-%d"
+	require.NoError(t, validate())
+
+	config.Worker.Queue = "postgres"
+	config.Splunk.Enabled = true
+	config.Unleash.URL = "localhost:4242"
+	config.Secrets.Vault.Token = "token"
+	config.Secrets.Vault.Role = "provisioning"
+	err := validate()
+
+	var report *ValidationError
+	require.ErrorAs(t, err, &report)
+	require.Len(t, report.Problems, 4, "all problems are reported at once")
+	require.ErrorIs(t, err, validateSplunkError)
+	require.ErrorIs(t, err, ErrInvalidValue)
+	require.ErrorIs(t, err, ErrInvalidURL)
+	require.ErrorIs(t, err, ErrMutuallyExclusive)
+	require.Contains(t, err.Error(), "4 problem(s)")
+}
+
+func TestValidateCABundle(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Test CA"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.pem")
+	require.NoError(t, os.WriteFile(valid, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a certificate"), 0o600))
+
+	r := report{}
+	r.caBundle("BLANK", "")
+	r.caBundle("VALID", valid)
+	require.Empty(t, r.problems)
+
+	r.caBundle("INVALID", invalid)
+	r.caBundle("MISSING", filepath.Join(dir, "missing.pem"))
+	require.Len(t, r.problems, 2)
+	require.ErrorIs(t, r.problems[0], ErrInvalidValue)
+	require.Contains(t, r.problems[0].Error(), "INVALID")
+	require.Contains(t, r.problems[1].Error(), "MISSING")
+}
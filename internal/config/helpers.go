@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	clowder "github.com/redhatinsights/app-common-go/pkg/api/v1"
 	"github.com/rs/zerolog"
@@ -57,6 +58,104 @@ func TopicName(ctx context.Context, topic string) string {
 	return topic
 }
 
+// PubkeyExpiryEnforced returns true when reservations using expired pubkeys must be blocked
+// for the organization.
+func PubkeyExpiryEnforced(orgId string) bool {
+	if Application.PubkeyExpiry.Enforce {
+		return true
+	}
+	for _, org := range Application.PubkeyExpiry.EnforceOrgs {
+		if org == orgId {
+			return true
+		}
+	}
+	return false
+}
+
+// IMDSv1Allowed returns true when AWS reservations of the organization can opt out of IMDSv2
+// and accept metadata requests without a session token.
+func IMDSv1Allowed(orgId string) bool {
+	if Application.AWSMetadata.AllowIMDSv1 {
+		return true
+	}
+	for _, org := range Application.AWSMetadata.AllowIMDSv1Orgs {
+		if org == orgId {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionTagsEnabled returns true when customer AWS roles of the organization are assumed with
+// session tags attributing the calls.
+func SessionTagsEnabled(orgId string) bool {
+	if AWS.SessionTags {
+		return true
+	}
+	for _, org := range AWS.SessionTagsOrgs {
+		if org == orgId {
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalIDRequired returns true when customer AWS roles of the organization must not be assumed
+// without an external ID.
+func ExternalIDRequired(orgId string) bool {
+	if AWS.RequireExternalID {
+		return true
+	}
+	for _, org := range AWS.RequireExternalIDOrgs {
+		if org == orgId {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkerTimeout returns the timeout of a job type, WORKER_TIMEOUT is used when not configured.
+func WorkerTimeout(jobType string) time.Duration {
+	if timeout, ok := Worker.TypeTimeouts[jobType]; ok && timeout > 0 {
+		return timeout
+	}
+	return Worker.Timeout
+}
+
+// IsAdminOrg returns true when the organization is allowed to access the admin API.
+func IsAdminOrg(orgId string) bool {
+	for _, org := range Application.AdminOrgs {
+		if org != "" && org == orgId {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdminRole returns true when any of the associate roles is allowed to access the internal
+// admin API.
+func IsAdminRole(roles []string) bool {
+	for _, allowed := range Application.AdminRoles {
+		for _, role := range roles {
+			if allowed != "" && allowed == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsMachinePrincipalAllowed returns true when the non-user identity type is listed in the
+// MACHINE_PRINCIPALS setting.
+func IsMachinePrincipalAllowed(principalType string) bool {
+	for _, allowed := range Application.MachinePrincipals {
+		if allowed != "" && allowed == principalType {
+			return true
+		}
+	}
+	return false
+}
+
 func StringToURL(urlStr string) *url.URL {
 	if urlStr == "" {
 		return nil
@@ -97,17 +196,27 @@ func DumpConfig(logger zerolog.Logger) {
 	configCopy.Cloudwatch.Key = replacement
 	configCopy.Cloudwatch.Secret = replacement
 	configCopy.Cloudwatch.Session = replacement
+	configCopy.Splunk.Token = replacement
 	configCopy.AWS.Key = replacement
 	configCopy.AWS.Secret = replacement
 	configCopy.AWS.Session = replacement
+	configCopy.AWS.GovCloud.Key = replacement
+	configCopy.AWS.GovCloud.Secret = replacement
+	configCopy.AWS.China.Key = replacement
+	configCopy.AWS.China.Secret = replacement
 	configCopy.RestEndpoints.Sources.Password = replacement
 	configCopy.RestEndpoints.ImageBuilder.Password = replacement
 	configCopy.Azure.ClientID = replacement
 	configCopy.Azure.ClientSecret = replacement
+	configCopy.Azure.Government.ClientID = replacement
+	configCopy.Azure.Government.ClientSecret = replacement
+	configCopy.Azure.China.ClientID = replacement
+	configCopy.Azure.China.ClientSecret = replacement
 	configCopy.GCP.JSON = replacement
 	configCopy.Unleash.Token = replacement
 	configCopy.Kafka.SASL.Username = replacement
 	configCopy.Kafka.SASL.Password = replacement
+	configCopy.Secrets.Vault.Token = replacement
 	// We want to know if the DSN was empty
 	if configCopy.Sentry.Dsn != "" {
 		configCopy.Sentry.Dsn = replacement
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Runtime is the subset of settings which is applied without restart when configuration files
+// change, see WatchChanges. Snapshots are immutable and swapped atomically, these settings must
+// be read via Current because the package variables keep values loaded during startup. Other
+// settings (e.g. number of worker polling goroutines which size connection pools) require restart.
+type Runtime struct {
+	// LogLevel is the LOGGING_LEVEL setting
+	LogLevel string
+
+	// TenantConcurrency is the WORKER_TENANT_CONCURRENCY setting
+	TenantConcurrency int
+
+	// UnleashFallback is the UNLEASH_FALLBACK setting
+	UnleashFallback map[string]string
+
+	// AuditEnabled is the APP_AUDIT_ENABLED setting
+	AuditEnabled bool
+
+	// AuditKafka is the APP_AUDIT_KAFKA setting
+	AuditKafka bool
+
+	// RateLimits are the RATE_LIMIT_*_PER_MINUTE and RATE_LIMIT_*_BURST settings, the
+	// RATE_LIMIT_ENABLED setting requires restart because the Redis limiter is created on startup
+	RateLimits RateLimits
+}
+
+// RateLimits are requests per minute and bursts of rate limit classes.
+type RateLimits struct {
+	ReadPerMinute   int
+	ReadBurst       int
+	WritePerMinute  int
+	WriteBurst      int
+	LaunchPerMinute int
+	LaunchBurst     int
+}
+
+// RuntimeListener is called after the runtime snapshot was swapped.
+type RuntimeListener func(previous, current *Runtime)
+
+var ErrInvalidRuntime = errors.New("invalid runtime configuration")
+
+var (
+	// runtimeSnapshot is nil until the configuration is initialized
+	runtimeSnapshot atomic.Pointer[Runtime]
+
+	// watchedFiles are configuration files passed to Initialize
+	watchedFiles []string
+
+	listenersMu sync.Mutex
+	listeners   []RuntimeListener
+)
+
+func newRuntime(cfg *configuration) *Runtime {
+	return &Runtime{
+		LogLevel:          cfg.Logging.Level,
+		TenantConcurrency: cfg.Worker.TenantConcurrency,
+		UnleashFallback:   cfg.Unleash.Fallback,
+		AuditEnabled:      cfg.App.Audit.Enabled,
+		AuditKafka:        cfg.App.Audit.Kafka,
+		RateLimits: RateLimits{
+			ReadPerMinute:   cfg.RateLimit.ReadPerMinute,
+			ReadBurst:       cfg.RateLimit.ReadBurst,
+			WritePerMinute:  cfg.RateLimit.WritePerMinute,
+			WriteBurst:      cfg.RateLimit.WriteBurst,
+			LaunchPerMinute: cfg.RateLimit.LaunchPerMinute,
+			LaunchBurst:     cfg.RateLimit.LaunchBurst,
+		},
+	}
+}
+
+func (r *Runtime) validate() error {
+	if _, err := zerolog.ParseLevel(r.LogLevel); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRuntime, err.Error())
+	}
+	if r.TenantConcurrency < 0 {
+		return fmt.Errorf("%w: negative tenant concurrency %d", ErrInvalidRuntime, r.TenantConcurrency)
+	}
+	limits := r.RateLimits
+	for _, value := range []int{limits.ReadPerMinute, limits.ReadBurst, limits.WritePerMinute, limits.WriteBurst, limits.LaunchPerMinute, limits.LaunchBurst} {
+		if value < 0 {
+			return fmt.Errorf("%w: negative rate limit %d", ErrInvalidRuntime, value)
+		}
+	}
+	return nil
+}
+
+// Current returns the snapshot of runtime settings. When the configuration was not initialized
+// (e.g. in tests), the snapshot is created from the package variables.
+func Current() *Runtime {
+	if r := runtimeSnapshot.Load(); r != nil {
+		return r
+	}
+	return newRuntime(&config)
+}
+
+// OnChange registers a listener called after runtime settings changed. Listeners are called
+// sequentially from the watching goroutine.
+func OnChange(listener RuntimeListener) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, listener)
+}
+
+// Reload reads configuration files again and applies Clowder overrides like Initialize, then
+// swaps the runtime snapshot when runtime settings changed, changes of other settings are
+// ignored. Returns true when the snapshot was swapped.
+func Reload() (bool, error) {
+	var fresh configuration
+	if err := read(&fresh, watchedFiles); err != nil {
+		return false, err
+	}
+	applyClowder(&fresh)
+
+	next := newRuntime(&fresh)
+	if err := next.validate(); err != nil {
+		return false, err
+	}
+
+	previous := Current()
+	if reflect.DeepEqual(previous, next) {
+		return false, nil
+	}
+	runtimeSnapshot.Store(next)
+
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	for _, listener := range listeners {
+		listener(previous, next)
+	}
+	return true, nil
+}
+
+// WatchChanges checks modification times of configuration files every APP_CONFIG_RELOAD and
+// reloads runtime settings when a file changed. Use context cancellation to stop watching.
+func WatchChanges(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	if config.App.ConfigReload <= 0 || len(watchedFiles) == 0 {
+		logger.Debug().Msg("Configuration reload is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(config.App.ConfigReload)
+		defer ticker.Stop()
+		stamps := fileStamps(watchedFiles)
+
+		for {
+			select {
+			case <-ticker.C:
+				current := fileStamps(watchedFiles)
+				if reflect.DeepEqual(stamps, current) {
+					continue
+				}
+				stamps = current
+
+				changed, err := Reload()
+				if err != nil {
+					logger.Warn().Err(err).Msg("Unable to reload configuration, keeping previous runtime settings")
+				} else if changed {
+					logger.Info().Msgf("Runtime configuration reloaded: %+v", *Current())
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// fileStamps returns modification times of files, zero time for files which do not exist.
+// Stat follows symlinks, therefore atomic updates of mounted config maps are detected too.
+func fileStamps(files []string) map[string]time.Time {
+	stamps := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stamps[file] = info.ModTime()
+		} else {
+			stamps[file] = time.Time{}
+		}
+	}
+	return stamps
+}
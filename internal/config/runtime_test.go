@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.env")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	// values from env files are exported into the environment, restore them afterwards
+	t.Setenv("LOGGING_LEVEL", "info")
+	t.Setenv("WORKER_TENANT_CONCURRENCY", "10")
+	t.Setenv("RATE_LIMIT_WRITE_PER_MINUTE", "120")
+	write("LOGGING_LEVEL=info\nWORKER_TENANT_CONCURRENCY=10\n")
+
+	files, snapshot := watchedFiles, runtimeSnapshot.Load()
+	t.Cleanup(func() {
+		watchedFiles = files
+		runtimeSnapshot.Store(snapshot)
+		listeners = nil
+	})
+	watchedFiles = []string{file}
+	var initial configuration
+	require.NoError(t, read(&initial, watchedFiles))
+	runtimeSnapshot.Store(newRuntime(&initial))
+
+	var calls []*Runtime
+	OnChange(func(previous, current *Runtime) {
+		calls = append(calls, previous, current)
+	})
+
+	changed, err := Reload()
+	require.NoError(t, err)
+	require.False(t, changed, "nothing changed")
+	require.Empty(t, calls)
+
+	write("LOGGING_LEVEL=debug\nWORKER_TENANT_CONCURRENCY=3\nRATE_LIMIT_WRITE_PER_MINUTE=60\n")
+	changed, err = Reload()
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "debug", Current().LogLevel)
+	require.Equal(t, 3, Current().TenantConcurrency)
+	require.Equal(t, 60, Current().RateLimits.WritePerMinute)
+	require.Len(t, calls, 2)
+	require.Equal(t, "info", calls[0].LogLevel)
+	require.Same(t, Current(), calls[1])
+
+	write("LOGGING_LEVEL=verbose\n")
+	changed, err = Reload()
+	require.ErrorIs(t, err, ErrInvalidRuntime)
+	require.False(t, changed)
+	require.Equal(t, "debug", Current().LogLevel, "invalid configuration is not applied")
+}
@@ -1,9 +1,16 @@
 package config
 
 import (
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
 )
 
 // present checks if all arguments are not blank
@@ -16,27 +23,322 @@ func present(args ...string) bool {
 	return true
 }
 
+// ValidationError is the report of all problems found in the configuration.
+type ValidationError struct {
+	Binary   string
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "config error: %d problem(s) found in configuration of %s:", len(e.Problems), e.Binary)
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(strings.TrimPrefix(problem.Error(), "config error: "))
+	}
+	return b.String()
+}
+
+// Is returns true when one of the problems matches the target.
+func (e *ValidationError) Is(target error) bool {
+	for _, problem := range e.Problems {
+		if errors.Is(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrRequired          = errors.New("required setting is blank")
+	ErrInvalidValue      = errors.New("invalid value")
+	ErrInvalidURL        = errors.New("invalid URL")
+	ErrPortCollision     = errors.New("port collision")
+	ErrMutuallyExclusive = errors.New("mutually exclusive settings")
+)
+
+// databaseBinaries are processes which connect to the database
+var databaseBinaries = []string{"api", "worker", "statuser", "stats", "migrate", "replay"}
+
+// report collects problems of the configuration
+type report struct {
+	binary   string
+	problems []error
+	warnings []string
+}
+
+func (r *report) add(err error) {
+	r.problems = append(r.problems, err)
+}
+
+func (r *report) warn(format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
+
+func (r *report) binaryIs(binaries ...string) bool {
+	for _, binary := range binaries {
+		if r.binary == binary {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *report) required(name, value string) {
+	if value == "" {
+		r.add(fmt.Errorf("%w: %s", ErrRequired, name))
+	}
+}
+
+func (r *report) oneOf(name, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	r.add(fmt.Errorf("%w: %s must be one of %s, got %q", ErrInvalidValue, name, strings.Join(allowed, ", "), value))
+}
+
+func (r *report) port(name string, value int) {
+	if value < 1 || value > 65535 {
+		r.add(fmt.Errorf("%w: %s must be a port number, got %d", ErrInvalidValue, name, value))
+	}
+}
+
+// url verifies the value is an absolute URL with one of the schemes, blank values are skipped
+func (r *report) url(name, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		r.add(fmt.Errorf("%w: %s must be an absolute URL, got %q", ErrInvalidURL, name, value))
+		return
+	}
+	if len(schemes) > 0 {
+		for _, scheme := range schemes {
+			if u.Scheme == scheme {
+				return
+			}
+		}
+		r.add(fmt.Errorf("%w: %s must use one of %s schemes, got %q", ErrInvalidURL, name, strings.Join(schemes, ", "), u.Scheme))
+	}
+}
+
+// caBundle verifies the value is a path to a PEM file with at least one certificate, blank values
+// are skipped
+func (r *report) caBundle(name, value string) {
+	if value == "" {
+		return
+	}
+	pem, err := os.ReadFile(value)
+	if err != nil {
+		r.add(fmt.Errorf("%w: %s: %s", ErrInvalidValue, name, err.Error()))
+		return
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+		r.add(fmt.Errorf("%w: %s: no certificates found in %s", ErrInvalidValue, name, value))
+	}
+}
+
+func (r *report) exclusive(name1 string, set1 bool, name2 string, set2 bool) {
+	if set1 && set2 {
+		r.add(fmt.Errorf("%w: %s and %s", ErrMutuallyExclusive, name1, name2))
+	}
+}
+
+// validate verifies the configuration of the current binary and returns all problems at once.
+// Non-fatal findings are kept for the startup report, see Warnings.
 func validate() error {
+	r := report{binary: BinaryName()}
+
+	// logging
+	if _, err := zerolog.ParseLevel(Logging.Level); err != nil {
+		r.add(fmt.Errorf("%w: LOGGING_LEVEL: %s", ErrInvalidValue, err.Error()))
+	}
 	if Cloudwatch.Enabled {
 		if Cloudwatch.Region == "" || Cloudwatch.Key == "" || Cloudwatch.Secret == "" {
-			return validateMissingSecretError
+			r.add(validateMissingSecretError)
 		}
 		if Cloudwatch.Group == "" || Cloudwatch.Stream == "" {
-			return validateGroupStreamError
+			r.add(validateGroupStreamError)
 		}
 	}
+	if Splunk.Enabled {
+		if !present(Splunk.URL, Splunk.Token) {
+			r.add(validateSplunkError)
+		}
+		r.url("SPLUNK_URL", Splunk.URL, "http", "https")
+	}
+
+	// services
+	r.port("APP_PORT", Application.Port)
+	r.port("PROMETHEUS_PORT", Prometheus.Port)
+	if r.binaryIs("api") && Application.Port == Prometheus.Port {
+		r.add(fmt.Errorf("%w: APP_PORT and PROMETHEUS_PORT are both %d", ErrPortCollision, Application.Port))
+	}
+	r.oneOf("APP_CACHE_TYPE", Application.Cache.Type, "none", "memory", "redis")
+	if Application.Cache.Type == "redis" || Worker.Queue == "redis" {
+		r.required("APP_CACHE_REDIS_HOST", Application.Cache.Redis.Host)
+		r.port("APP_CACHE_REDIS_PORT", Application.Cache.Redis.Port)
+	}
+	r.url("UNLEASH_URL", Unleash.URL, "http", "https")
+	if Application.AWSMetadata.HopLimit < 1 || Application.AWSMetadata.HopLimit > 64 {
+		r.add(fmt.Errorf("%w: APP_AWS_METADATA_HOP_LIMIT must be between 1 and 64, got %d", ErrInvalidValue, Application.AWSMetadata.HopLimit))
+	}
+	if strings.Count(Application.AzureResourceGroup.Pattern, "%") != 1 || !strings.Contains(Application.AzureResourceGroup.Pattern, "%d") {
+		r.add(fmt.Errorf("%w: APP_AZURE_RESOURCE_GROUP_PATTERN must contain a single %%d, got %q", ErrInvalidValue, Application.AzureResourceGroup.Pattern))
+	}
+	for _, principal := range Application.MachinePrincipals {
+		if principal != "" {
+			r.oneOf("APP_MACHINE_PRINCIPALS", principal, "System", "ServiceAccount")
+		}
+	}
+
+	// database
+	if r.binaryIs(databaseBinaries...) {
+		r.required("DATABASE_HOST", Database.Host)
+		r.required("DATABASE_NAME", Database.Name)
+		r.required("DATABASE_USER", Database.User)
+	}
+	r.oneOf("DATABASE_TENANT_AUDIT", Database.TenantAudit, "", "log", "panic")
+	if Database.TenantAudit != "" && InProdClowder() {
+		r.warn("Tenant audit (DATABASE_TENANT_AUDIT) is meant for development and tests only")
+	}
+
+	// workers
+	r.oneOf("WORKER_QUEUE", Worker.Queue, "memory", "redis")
+	if r.binaryIs("worker") && Worker.Queue == "memory" {
+		r.add(fmt.Errorf("%w: WORKER_QUEUE memory only runs jobs in the api process", ErrInvalidValue))
+	}
+	if Worker.Queue == "redis" && (Worker.Concurrency < 1 || Worker.BackgroundConcurrency < 1) {
+		r.add(fmt.Errorf("%w: WORKER_CONCURRENCY and WORKER_BACKGROUND_CONCURRENCY must be positive", ErrInvalidValue))
+	}
+	r.exclusive("SCHEDULER_ENABLED", Scheduler.Enabled, "WORKER_QUEUE memory", Worker.Queue == "memory")
+
+	// rate limiting
+	if RateLimit.Enabled {
+		if RateLimit.ReadPerMinute < 0 || RateLimit.WritePerMinute < 0 || RateLimit.LaunchPerMinute < 0 {
+			r.add(fmt.Errorf("%w: RATE_LIMIT_*_PER_MINUTE must not be negative", ErrInvalidValue))
+		}
+		if RateLimit.ReadBurst < 1 || RateLimit.WriteBurst < 1 || RateLimit.LaunchBurst < 1 {
+			r.add(fmt.Errorf("%w: RATE_LIMIT_*_BURST must be positive", ErrInvalidValue))
+		}
+		if Application.Cache.Type != "redis" {
+			r.warn("Rate limits (RATE_LIMIT_ENABLED) are counted per process because APP_CACHE_TYPE is not redis")
+		}
+	}
+
+	// webhooks
+	if Webhooks.Enabled {
+		if Webhooks.Interval <= 0 || Webhooks.Timeout <= 0 {
+			r.add(fmt.Errorf("%w: WEBHOOKS_INTERVAL and WEBHOOKS_TIMEOUT must be positive", ErrInvalidValue))
+		}
+		if Webhooks.MaxAttempts < 1 {
+			r.add(fmt.Errorf("%w: WEBHOOKS_MAX_ATTEMPTS must be positive", ErrInvalidValue))
+		}
+	}
+	if Webhooks.AllowHTTP && InProdClowder() {
+		r.add(fmt.Errorf("%w: WEBHOOKS_ALLOW_HTTP is meant for development only", ErrInvalidValue))
+	}
 
-	slice, err := base64.StdEncoding.DecodeString(config.GCP.JSON)
-	config.GCP.JSON = string(slice)
+	// platform services
+	r.url("REST_ENDPOINTS_SOURCES_URL", Sources.URL, "http", "https")
+	r.oneOf("REST_ENDPOINTS_SOURCES_API", Sources.API, "v3.1", "jsonapi")
+	r.url("REST_ENDPOINTS_IMAGE_BUILDER_URL", ImageBuilder.URL, "http", "https")
+	r.url("REST_ENDPOINTS_KEY_HOSTS_GITHUB_URL", KeyHosts.GitHubURL, "http", "https")
+	r.url("REST_ENDPOINTS_KEY_HOSTS_GITLAB_URL", KeyHosts.GitLabURL, "http", "https")
+	r.url("REST_ENDPOINTS_SOURCES_PROXY_URL", Sources.Proxy.URL)
+	r.url("REST_ENDPOINTS_IMAGE_BUILDER_PROXY_URL", ImageBuilder.Proxy.URL)
+	r.url("REST_ENDPOINTS_KEY_HOSTS_PROXY_URL", KeyHosts.Proxy.URL)
+	if RBAC.Enabled {
+		r.required("REST_ENDPOINTS_RBAC_URL", RBAC.URL)
+	}
+	r.url("REST_ENDPOINTS_RBAC_URL", RBAC.URL, "http", "https")
+	r.url("REST_ENDPOINTS_RBAC_PROXY_URL", RBAC.Proxy.URL)
+	r.caBundle("REST_ENDPOINTS_SOURCES_CA_BUNDLE", Sources.CABundle)
+	r.caBundle("REST_ENDPOINTS_IMAGE_BUILDER_CA_BUNDLE", ImageBuilder.CABundle)
+	r.caBundle("REST_ENDPOINTS_KEY_HOSTS_CA_BUNDLE", KeyHosts.CABundle)
+	r.caBundle("REST_ENDPOINTS_RBAC_CA_BUNDLE", RBAC.CABundle)
+
+	// cloud providers
+	r.caBundle("AWS_CA_BUNDLE", AWS.CABundle)
+	r.caBundle("AZURE_CA_BUNDLE", Azure.CABundle)
+	r.caBundle("GCP_CA_BUNDLE", GCP.CABundle)
+
+	// kafka
+	if r.binaryIs("statuser", "replay") && !Kafka.Enabled {
+		r.add(fmt.Errorf("%w: KAFKA_ENABLED must be true for %s", ErrInvalidValue, r.binary))
+	}
+	if Kafka.Enabled {
+		r.oneOf("KAFKA_BACKEND", Kafka.Backend, "kafka", "sqs", "amqp")
+		r.oneOf("KAFKA_AUTH_TYPE", Kafka.AuthType, "", "mtls", "sasl")
+		if Kafka.AuthType == "sasl" {
+			r.required("KAFKA_SASL_USERNAME", Kafka.SASL.Username)
+			r.required("KAFKA_SASL_PASSWORD", Kafka.SASL.Password)
+		}
+		r.url("KAFKA_SCHEMA_REGISTRY_URL", Kafka.SchemaRegistry.URL, "http", "https")
+		switch Kafka.Backend {
+		case "sqs":
+			r.required("KAFKA_SQS_ACCOUNT_ID", Kafka.SQS.AccountID)
+			r.url("KAFKA_SQS_ENDPOINT", Kafka.SQS.Endpoint, "http", "https")
+		case "amqp":
+			r.url("KAFKA_AMQP_URL", Kafka.AMQP.URL, "amqp", "amqps")
+		}
+	}
+	if Application.Audit.Kafka && !Kafka.Enabled {
+		r.warn("Audit records are not published (APP_AUDIT_KAFKA) because Kafka is disabled")
+	}
+
+	// telemetry
+	if Telemetry.Enabled && Telemetry.OTLP.Enabled && Telemetry.Jaeger.Enabled {
+		r.warn("Both OTLP and Jaeger exporters are enabled, only OTLP is used")
+	}
+
+	// secrets
+	r.url("SECRETS_VAULT_ADDRESS", Secrets.Vault.Address, "http", "https")
+	r.exclusive("SECRETS_VAULT_TOKEN", Secrets.Vault.Token != "", "SECRETS_VAULT_ROLE", Secrets.Vault.Role != "")
+	r.url("SECRETS_AWS_ENDPOINT", Secrets.AWS.Endpoint, "http", "https")
+
+	// references are decoded when the secret is resolved
+	if !IsSecretReference(config.GCP.JSON) {
+		if _, err := DecodeGCPJSON(config.GCP.JSON); err != nil {
+			r.add(err)
+		}
+	}
+
+	validationWarnings = r.warnings
+	if len(r.problems) > 0 {
+		return &ValidationError{Binary: r.binary, Problems: r.problems}
+	}
+	return nil
+}
+
+// validationWarnings are non-fatal findings of the last validation
+var validationWarnings []string
+
+// Warnings returns non-fatal findings of the configuration validation, they are meant to be
+// logged during startup.
+func Warnings() []string {
+	return validationWarnings
+}
+
+// DecodeGCPJSON decodes base64-encoded GCP service account credentials and verifies they are JSON.
+func DecodeGCPJSON(value string) (string, error) {
+	slice, err := base64.StdEncoding.DecodeString(value)
 	if err != nil {
-		return fmt.Errorf("unable to base64-decode GCP JSON config: %w", err)
+		return "", fmt.Errorf("unable to base64-decode GCP JSON config: %w", err)
 	}
 
 	var data json.RawMessage
 	err = json.Unmarshal(slice, &data)
 	if err != nil {
-		return fmt.Errorf("unable to parse GCP JSON config: %w", err)
+		return "", fmt.Errorf("unable to parse GCP JSON config: %w", err)
 	}
+	return string(slice), nil
+}
 
-	return nil
+// IsSecretReference returns true for values resolved from a secrets backend, see the secrets package.
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, "vault:") || strings.HasPrefix(value, "awssm:")
 }
@@ -16,6 +16,10 @@ var (
 	// Typically, REST requests should end up with 409 error
 	ErrAffectedMismatch = errors.New("unexpected affected rows")
 
+	// ErrConflict is returned when an operation would violate uniqueness of existing records.
+	// Typically, REST requests should end up with 409 error
+	ErrConflict = errors.New("conflicting records")
+
 	// ErrValidation is returned when model does not validate
 	ErrValidation = errors.New("validation error")
 
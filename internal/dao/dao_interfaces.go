@@ -7,11 +7,14 @@
 //
 // Functions marked as UNSCOPED can be safely used from contexts where there is
 // exactly zero function arguments coming from an user (e.g. ID was retrieved via
-// another DAO call that was scoped).
+// another DAO call that was scoped). Their statements must use context from
+// db.WithUnscoped, other statements are verified by the tenant audit in development
+// and integration tests.
 package dao
 
 import (
 	"context"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -27,30 +30,74 @@ type AccountDao interface {
 	GetOrCreateByIdentity(ctx context.Context, orgId string, accountNumber string) (*models.Account, error)
 	GetByOrgId(ctx context.Context, orgId string) (*models.Account, error)
 	List(ctx context.Context, limit, offset int64) ([]*models.Account, error)
+
+	// UnscopedMerge moves pubkeys, reservations, archived reservations, reservation templates,
+	// instance actions, webhooks and their deliveries, source identities and account settings of
+	// the source account to the target account and stores the audit record in a single
+	// transaction. Source identities and settings of the target are kept when both accounts have
+	// them. Source, target and requester are read from the audit, operation, organization IDs and
+	// counts are filled in. Returns ErrNoRows when an account does not exist and ErrConflict when
+	// names or fingerprints of records of both accounts collide. UNSCOPED.
+	UnscopedMerge(ctx context.Context, audit *models.AccountAudit) error
+
+	// UnscopedRename changes organization ID of the account to audit.NewOrgID and stores the
+	// audit record in a single transaction. Returns ErrNoRows when the account does not exist
+	// and ErrConflict when another account has the organization ID, merge it instead. UNSCOPED.
+	UnscopedRename(ctx context.Context, audit *models.AccountAudit) error
 }
 
 var GetPubkeyDao func(ctx context.Context) PubkeyDao
 
 // PubkeyDao represents Pubkeys (public part of ssh key pair) and corresponding Resources (uploaded pubkeys
-// to specific cloud providers in specific regions).
+// to specific cloud providers in specific regions). Scoped reads only return pubkeys visible to
+// the user (see models.Pubkey.VisibleTo), scoped writes only modify pubkeys owned by the user.
 type PubkeyDao interface {
 	Create(ctx context.Context, pk *models.Pubkey) error
 	Update(ctx context.Context, pk *models.Pubkey) error
 	GetById(ctx context.Context, id int64) (*models.Pubkey, error)
 	List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error)
+	Count(ctx context.Context) (int64, error)
+
+	// Delete marks the pubkey as deleted and removes its resource records in a single transaction,
+	// key-pairs must be removed from clouds beforehand. Deleted pubkeys are not returned.
 	Delete(ctx context.Context, id int64) error
 
+	// Restore clears the deleted mark of a pubkey, returns ErrAffectedMismatch when the pubkey
+	// does not exist or is not deleted.
+	Restore(ctx context.Context, id int64) error
+
+	// UpdateShared sets the shared flag of a pubkey, returns ErrAffectedMismatch when the pubkey
+	// does not exist or is owned by another user.
+	UpdateShared(ctx context.Context, id int64, shared bool) error
+
 	UnscopedCreateResource(ctx context.Context, pkr *models.PubkeyResource) error
 	UnscopedGetResourceBySourceAndRegion(ctx context.Context, pubkeyId int64, sourceId string, region string) (*models.PubkeyResource, error)
 	UnscopedListResourcesByPubkeyId(ctx context.Context, pkId int64) ([]*models.PubkeyResource, error)
 	UnscopedDeleteResource(ctx context.Context, id int64) error
+
+	// UnscopedListExpiring returns pubkeys of all accounts expiring before given time which were
+	// not notified about the expiry yet. UNSCOPED.
+	UnscopedListExpiring(ctx context.Context, before time.Time) ([]*models.Pubkey, error)
+
+	// UnscopedListExpiringBetween returns pubkeys of all accounts which are not deleted and expire
+	// after the first and before or at the second time, notified or not. UNSCOPED.
+	UnscopedListExpiringBetween(ctx context.Context, after, before time.Time) ([]*models.Pubkey, error)
+
+	// UnscopedMarkExpiryNotified records that expiry notification was sent. UNSCOPED.
+	UnscopedMarkExpiryNotified(ctx context.Context, id int64) error
+
+	// UnscopedPurgeDeleted permanently deletes pubkeys of all accounts deleted before given time,
+	// returns number of purged pubkeys. UNSCOPED.
+	UnscopedPurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 var GetReservationDao func(ctx context.Context) ReservationDao
 
 // ReservationDao represents a reservation, an abstraction of one or more background jobs with
 // associated detail information different for different cloud providers (like number of vCPUs,
-// instance IDs created etc).
+// instance IDs created etc). Scoped reads only return reservations visible to the user (see
+// models.Reservation.VisibleTo), scoped writes only modify reservations owned by the user.
+// Statistics, running instance types and pubkey usage cover reservations of all users.
 type ReservationDao interface {
 	// CreateNoop creates no operation reservation with details in a single transaction.
 	CreateNoop(ctx context.Context, reservation *models.NoopReservation) error
@@ -82,6 +129,25 @@ type ReservationDao interface {
 	// List returns reservation for a particular account.
 	List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error)
 
+	// Count returns number of reservations of a particular account.
+	Count(ctx context.Context) (int64, error)
+
+	// StatsByProvider returns aggregate statistics of reservations created since the time for each provider.
+	StatsByProvider(ctx context.Context, since time.Time) ([]*models.ReservationProviderStat, error)
+
+	// DailyStats returns number of reservations created since the time per day, provider and result.
+	DailyStats(ctx context.Context, since time.Time) ([]*models.ReservationDailyStat, error)
+
+	// ListRunningInstanceTypes returns instance types and amounts of live instances of pending
+	// and successful reservations of all providers: requested amount of pending reservations and
+	// launched instances which were not stopped or powered off. Failed, deleted and archived
+	// reservations are not listed. Fleets are listed with the instance type chosen and recorded
+	// by the launch job, the first candidate type is used only until the fleet is launched.
+	ListRunningInstanceTypes(ctx context.Context) ([]*models.ReservationInstanceTypeAmount, error)
+
+	// ListByPubkeyId returns reservations of all providers which used the pubkey.
+	ListByPubkeyId(ctx context.Context, pubkeyId int64) ([]*models.PubkeyReservation, error)
+
 	// ListInstances returns instances associated to a reservation. UNSCOPED.
 	// It currently lists all instances and not instances for a reservation, this is a TODO.
 	ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error)
@@ -92,6 +158,9 @@ type ReservationDao interface {
 	// UnscopedUpdateAWSDetail updates details of the AWS reservation. UNSCOPED.
 	UnscopedUpdateAWSDetail(ctx context.Context, id int64, awsDetail *models.AWSDetail) error
 
+	// UnscopedUpdateAzureDetail updates details of the Azure reservation. UNSCOPED.
+	UnscopedUpdateAzureDetail(ctx context.Context, id int64, azureDetail *models.AzureDetail) error
+
 	// UpdateReservationIDForAWS updates AWS reservation id field. UNSCOPED.
 	UpdateReservationIDForAWS(ctx context.Context, id int64, awsReservationId string) error
 
@@ -101,11 +170,76 @@ type ReservationDao interface {
 	// UpdateReservationInstance updates an instance with its description
 	UpdateReservationInstance(ctx context.Context, reservationID int64, instance *clients.InstanceDescription) error
 
-	// FinishWithSuccess sets Success flag. UNSCOPED.
-	FinishWithSuccess(ctx context.Context, id int64) error
+	// FinishWithSuccess sets Success flag and stores outbox messages in the same transaction. UNSCOPED.
+	FinishWithSuccess(ctx context.Context, id int64, outbox ...*models.OutboxMessage) error
+
+	// FinishWithError sets Success flag and Error flag and stores outbox messages in the same
+	// transaction. UNSCOPED.
+	FinishWithError(ctx context.Context, id int64, errorString string, outbox ...*models.OutboxMessage) error
+
+	// RequestCancel sets cancel flag of an unfinished reservation, returns ErrAffectedMismatch
+	// when the reservation does not exist or is already finished.
+	RequestCancel(ctx context.Context, id int64) error
+
+	// UnscopedIsCancelRequested returns the cancel flag of a reservation. UNSCOPED.
+	UnscopedIsCancelRequested(ctx context.Context, id int64) (bool, error)
+
+	// FinishWithCancel sets Success flag, Error and the cancelled status. UNSCOPED.
+	FinishWithCancel(ctx context.Context, id int64, errorString string) error
+
+	// UnscopedUpdateSSHReady sets the result of the SSH probe of reservation instances. UNSCOPED.
+	UnscopedUpdateSSHReady(ctx context.Context, id int64, ready bool) error
+
+	// SoftDelete marks a finished reservation as deleted, returns ErrAffectedMismatch when the
+	// reservation does not exist or is still running. Deleted reservations are not returned.
+	SoftDelete(ctx context.Context, id int64) error
+
+	// Restore clears the deleted mark of a reservation, returns ErrAffectedMismatch when the
+	// reservation does not exist or is not deleted.
+	Restore(ctx context.Context, id int64) error
+
+	// UpdateShared sets the shared flag of a reservation, returns ErrAffectedMismatch when the
+	// reservation does not exist or is owned by another user.
+	UpdateShared(ctx context.Context, id int64, shared bool) error
+
+	// UnscopedGetById returns reservation of any account and owner including deleted ones,
+	// used by the internal admin API. UNSCOPED.
+	UnscopedGetById(ctx context.Context, id int64) (*models.Reservation, error)
+
+	// UnscopedListByAccount returns reservations of the account including deleted ones, newest
+	// first. Used by the internal admin API. UNSCOPED.
+	UnscopedListByAccount(ctx context.Context, accountId, limit, offset int64) ([]*models.Reservation, error)
 
-	// FinishWithError sets Success flag and Error flag. UNSCOPED.
-	FinishWithError(ctx context.Context, id int64, errorString string) error
+	// UnscopedCountByAccount returns number of reservations of the account including deleted ones.
+	// UNSCOPED.
+	UnscopedCountByAccount(ctx context.Context, accountId int64) (int64, error)
+
+	// UnscopedListInstances returns instances of a reservation of any account. UNSCOPED.
+	UnscopedListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error)
+
+	// UnscopedListDeletedBetween returns reservations of all accounts deleted after the first and
+	// before or at the second time. UNSCOPED.
+	UnscopedListDeletedBetween(ctx context.Context, after, before time.Time) ([]*models.Reservation, error)
+
+	// UnscopedPurgeDeleted permanently deletes reservations of all accounts deleted before given
+	// time, returns number of purged reservations. UNSCOPED.
+	UnscopedPurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	// UnscopedArchive moves up to limit terminated reservations of all accounts finished before
+	// given time into the archive together with their details and instances, returns number of
+	// archived reservations. Terminated are failed or cancelled reservations and reservations
+	// without instances, successful reservations with instances are never archived since the
+	// instances may still run. Deleted reservations are not archived, they are purged. UNSCOPED.
+	UnscopedArchive(ctx context.Context, before time.Time, limit int64) (int64, error)
+
+	// GetArchivedById returns an archived reservation for a particular account.
+	GetArchivedById(ctx context.Context, id int64) (*models.ArchivedReservation, error)
+
+	// ListArchived returns archived reservations of a particular account.
+	ListArchived(ctx context.Context, limit, offset int64) ([]*models.ArchivedReservation, error)
+
+	// CountArchived returns number of archived reservations of a particular account.
+	CountArchived(ctx context.Context) (int64, error)
 
 	// Delete deletes a reservation. Only used in tests and background cleanup job. UNSCOPED.
 	Delete(ctx context.Context, id int64) error
@@ -117,3 +251,203 @@ var GetStatDao func(ctx context.Context) StatDao
 type StatDao interface {
 	Get(ctx context.Context) (*models.Statistics, error)
 }
+
+var GetInstanceTypeAvailabilityDao func(ctx context.Context) InstanceTypeAvailabilityDao
+
+// InstanceTypeAvailabilityDao represents instance types available per region and zone refreshed
+// from live provider APIs. The data is not tenant-specific, all functions are UNSCOPED.
+type InstanceTypeAvailabilityDao interface {
+	// UnscopedReplace replaces all records of a provider in a single transaction. UNSCOPED.
+	UnscopedReplace(ctx context.Context, provider models.ProviderType, availability []*models.InstanceTypeAvailability) error
+
+	// UnscopedList returns all records of a provider. UNSCOPED.
+	UnscopedList(ctx context.Context, provider models.ProviderType) ([]*models.InstanceTypeAvailability, error)
+}
+
+var GetReservationTemplateDao func(ctx context.Context) ReservationTemplateDao
+
+// ReservationTemplateDao represents named launch configurations which can be referenced when
+// creating reservations.
+type ReservationTemplateDao interface {
+	Create(ctx context.Context, template *models.ReservationTemplate) error
+	Update(ctx context.Context, template *models.ReservationTemplate) error
+	GetById(ctx context.Context, id int64) (*models.ReservationTemplate, error)
+	List(ctx context.Context, limit, offset int64) ([]*models.ReservationTemplate, error)
+	Count(ctx context.Context) (int64, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+var GetAccountSettingsDao func(ctx context.Context) AccountSettingsDao
+
+// AccountSettingsDao represents defaults and restrictions of an account.
+type AccountSettingsDao interface {
+	// Get returns settings of the account, blank settings are returned when they were never set.
+	Get(ctx context.Context) (*models.AccountSettings, error)
+
+	// Upsert creates or replaces settings of the account.
+	Upsert(ctx context.Context, settings *models.AccountSettings) error
+}
+
+var GetSourceIdentityDao func(ctx context.Context) SourceIdentityDao
+
+// SourceIdentityDao represents cloud account identities resolved from source authentications.
+type SourceIdentityDao interface {
+	// GetBySourceId returns the identity of a source or ErrNoRows.
+	GetBySourceId(ctx context.Context, sourceId string) (*models.SourceIdentity, error)
+
+	// Upsert creates or replaces the identity of a source.
+	Upsert(ctx context.Context, sourceIdentity *models.SourceIdentity) error
+}
+
+var GetWebhookDao func(ctx context.Context) WebhookDao
+
+// WebhookDao represents webhooks registered by an account and their delivery log.
+type WebhookDao interface {
+	// Create registers a webhook.
+	Create(ctx context.Context, webhook *models.Webhook) error
+
+	// GetById returns a webhook or ErrNoRows.
+	GetById(ctx context.Context, id int64) (*models.Webhook, error)
+
+	// List returns webhooks of the account ordered by ID.
+	List(ctx context.Context, limit, offset int64) ([]*models.Webhook, error)
+
+	// Count returns number of webhooks of the account.
+	Count(ctx context.Context) (int64, error)
+
+	// Delete removes a webhook together with its delivery log, returns ErrAffectedMismatch when
+	// the webhook does not exist.
+	Delete(ctx context.Context, id int64) error
+
+	// ListDeliveries returns deliveries of a webhook, newest first.
+	ListDeliveries(ctx context.Context, webhookId, limit, offset int64) ([]*models.WebhookDelivery, error)
+
+	// CountDeliveries returns number of deliveries of a webhook.
+	CountDeliveries(ctx context.Context, webhookId int64) (int64, error)
+
+	// UnscopedCreateDeliveries creates a pending delivery of the payload for every webhook of the
+	// account subscribed to the event. Returns number of created deliveries. UNSCOPED.
+	UnscopedCreateDeliveries(ctx context.Context, accountId int64, event models.WebhookEvent, payload []byte) (int64, error)
+
+	// UnscopedDeliver locks up to limit due deliveries, oldest first, and passes them to the
+	// deliver function one by one together with their webhook. The function returns the HTTP
+	// status of the response. Failed deliveries are attempted again later with exponential
+	// backoff and marked as failed after maxAttempts. Deliveries locked by a concurrent call are
+	// skipped. Returns number of delivered and failed attempts. UNSCOPED.
+	UnscopedDeliver(ctx context.Context, limit int64, maxAttempts int, deliver func(*models.Webhook, *models.WebhookDelivery) (int, error)) (int, int, error)
+}
+
+var GetInstanceActionDao func(ctx context.Context) InstanceActionDao
+
+// InstanceActionDao represents lifecycle actions requested on instances of reservations.
+type InstanceActionDao interface {
+	// Create stores a pending action, returns ErrAffectedMismatch when another action of the
+	// same instance is still pending or running.
+	Create(ctx context.Context, action *models.InstanceAction) error
+
+	// GetById returns an action or ErrNoRows.
+	GetById(ctx context.Context, id int64) (*models.InstanceAction, error)
+
+	// ListByReservation returns actions of all instances of a reservation, newest first.
+	ListByReservation(ctx context.Context, reservationId, limit, offset int64) ([]*models.InstanceAction, error)
+
+	// CountByReservation returns number of actions of all instances of a reservation.
+	CountByReservation(ctx context.Context, reservationId int64) (int64, error)
+
+	// UnscopedUpdateStatus sets status and error of an action, finish time is set for success
+	// and failed statuses. UNSCOPED.
+	UnscopedUpdateStatus(ctx context.Context, id int64, status models.InstanceActionStatus, errorString string) error
+}
+
+var GetFailedJobDao func(ctx context.Context) FailedJobDao
+
+// FailedJobDao represents background jobs which failed on the last attempt. Failed jobs are
+// accessed by operators via the admin API, all functions are UNSCOPED.
+type FailedJobDao interface {
+	// UnscopedCreate stores a failed job. UNSCOPED.
+	UnscopedCreate(ctx context.Context, job *models.FailedJob) error
+
+	// UnscopedGetById returns a failed job. UNSCOPED.
+	UnscopedGetById(ctx context.Context, id int64) (*models.FailedJob, error)
+
+	// UnscopedList returns failed jobs, the most recent first. UNSCOPED.
+	UnscopedList(ctx context.Context, limit, offset int64) ([]*models.FailedJob, error)
+
+	// UnscopedCount returns number of all failed jobs. UNSCOPED.
+	UnscopedCount(ctx context.Context) (int64, error)
+
+	// UnscopedMarkReplayed records that the job was enqueued again. UNSCOPED.
+	UnscopedMarkReplayed(ctx context.Context, id int64) error
+}
+
+var GetRunningJobDao func(ctx context.Context) RunningJobDao
+
+// RunningJobDao represents background jobs currently processed by workers, all functions
+// are UNSCOPED.
+type RunningJobDao interface {
+	// UnscopedStart records a running job, an existing record of the same job is replaced. UNSCOPED.
+	UnscopedStart(ctx context.Context, job *models.RunningJob) error
+
+	// UnscopedHeartbeat updates heartbeat time of a running job. UNSCOPED.
+	UnscopedHeartbeat(ctx context.Context, jobId string) error
+
+	// UnscopedFinish deletes a running job record. UNSCOPED.
+	UnscopedFinish(ctx context.Context, jobId string) error
+
+	// UnscopedReapStale deletes and returns jobs without heartbeat since the given time. Each
+	// job is returned exactly once even when called concurrently. UNSCOPED.
+	UnscopedReapStale(ctx context.Context, before time.Time) ([]*models.RunningJob, error)
+}
+
+var GetJobAuditDao func(ctx context.Context) JobAuditDao
+
+// JobAuditDao represents the audit log of background job attempts. The log is accessed by
+// operators via the admin API, all functions are UNSCOPED.
+type JobAuditDao interface {
+	// UnscopedCreate stores a job attempt. UNSCOPED.
+	UnscopedCreate(ctx context.Context, audit *models.JobAudit) error
+
+	// UnscopedListByReservation returns attempts of jobs of a reservation, newest first. UNSCOPED.
+	UnscopedListByReservation(ctx context.Context, reservationId int64, limit, offset int64) ([]*models.JobAudit, error)
+
+	// UnscopedListByAccount returns attempts of jobs of an account, newest first. UNSCOPED.
+	UnscopedListByAccount(ctx context.Context, accountId int64, limit, offset int64) ([]*models.JobAudit, error)
+
+	// UnscopedCountByReservation returns number of attempts of jobs of a reservation. UNSCOPED.
+	UnscopedCountByReservation(ctx context.Context, reservationId int64) (int64, error)
+
+	// UnscopedCountByAccount returns number of attempts of jobs of an account. UNSCOPED.
+	UnscopedCountByAccount(ctx context.Context, accountId int64) (int64, error)
+}
+
+var GetAPIAuditDao func(ctx context.Context) APIAuditDao
+
+// APIAuditDao represents the audit log of mutating API calls. The log is written by the audit
+// middleware and accessed by operators via the admin API, all functions are UNSCOPED.
+type APIAuditDao interface {
+	// UnscopedCreate stores an API call. UNSCOPED.
+	UnscopedCreate(ctx context.Context, audit *models.APIAudit) error
+
+	// UnscopedListByAccount returns API calls of an account, newest first. UNSCOPED.
+	UnscopedListByAccount(ctx context.Context, accountId int64, limit, offset int64) ([]*models.APIAudit, error)
+
+	// UnscopedCountByAccount returns number of API calls of an account. UNSCOPED.
+	UnscopedCountByAccount(ctx context.Context, accountId int64) (int64, error)
+}
+
+var GetOutboxDao func(ctx context.Context) OutboxDao
+
+// OutboxDao represents messages waiting to be published to Kafka, all functions are UNSCOPED.
+type OutboxDao interface {
+	// UnscopedEnqueue stores messages to be published. UNSCOPED.
+	UnscopedEnqueue(ctx context.Context, messages ...*models.OutboxMessage) error
+
+	// UnscopedRelay locks up to limit due messages, oldest first, and passes them to the publish
+	// function one by one. Published messages are deleted, failed messages are attempted again
+	// later. Messages locked by a concurrent call are skipped. Returns number of published and
+	// failed messages. UNSCOPED.
+	UnscopedRelay(ctx context.Context, limit int64, publish func(*models.OutboxMessage) error) (int, int, error)
+
+	// UnscopedCount returns number of messages waiting to be published. UNSCOPED.
+	UnscopedCount(ctx context.Context) (int64, error)
+}
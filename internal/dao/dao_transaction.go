@@ -17,7 +17,7 @@ type TxFn func(tx pgx.Tx) error
 // error object returned by the `TxFn` or when it panics.
 func WithTransaction(ctx context.Context, fn TxFn) error {
 	logger := zerolog.Ctx(ctx)
-	tx, beginErr := db.Pool.Begin(ctx)
+	tx, beginErr := db.Conn(ctx).Begin(ctx)
 	if beginErr != nil {
 		logger.Warn().Err(beginErr).Msg("Cannot begin database transaction")
 		return fmt.Errorf("tx error: %w", beginErr)
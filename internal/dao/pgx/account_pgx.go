@@ -9,6 +9,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
 )
 
 func init() {
@@ -23,7 +24,7 @@ func getAccountDao(ctx context.Context) dao.AccountDao {
 
 func (x *accountDao) Create(ctx context.Context, account *models.Account) error {
 	query := `INSERT INTO accounts (account_number, org_id) VALUES ($1, $2) RETURNING id`
-	err := db.Pool.QueryRow(ctx, query, account.AccountNumber, account.OrgID).Scan(&account.ID)
+	err := db.Conn(ctx).QueryRow(ctx, query, account.AccountNumber, account.OrgID).Scan(&account.ID)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -35,7 +36,7 @@ func (x *accountDao) GetById(ctx context.Context, id int64) (*models.Account, er
 	query := `SELECT * FROM accounts WHERE id = $1 LIMIT 1`
 	result := &models.Account{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, id)
+	err := pgxscan.Get(ctx, db.Conn(ctx), result, query, id)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -71,7 +72,7 @@ func (x *accountDao) GetOrCreateByIdentity(ctx context.Context, orgId string, ac
 		SELECT * FROM accounts WHERE $2 IS NOT NULL AND account_number=$2
 		LIMIT 1;`
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, orgId, account)
+	err := pgxscan.Get(ctx, db.Conn(ctx), result, query, orgId, account)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -83,7 +84,7 @@ func (x *accountDao) GetByOrgId(ctx context.Context, orgId string) (*models.Acco
 	query := `SELECT * FROM accounts WHERE org_id = $1 LIMIT 1`
 	result := &models.Account{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, orgId)
+	err := pgxscan.Get(ctx, db.Conn(ctx), result, query, orgId)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -94,7 +95,7 @@ func (x *accountDao) List(ctx context.Context, limit, offset int64) ([]*models.A
 	query := `SELECT * FROM accounts ORDER BY id LIMIT $1 OFFSET $2`
 	var result []*models.Account
 
-	rows, err := db.Pool.Query(ctx, query, limit, offset)
+	rows, err := db.Conn(ctx).Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -105,3 +106,161 @@ func (x *accountDao) List(ctx context.Context, limit, offset int64) ([]*models.A
 	}
 	return result, nil
 }
+
+// lockAccounts locks account records for the rest of the transaction, inserts of records
+// of the accounts wait until it finishes. Returns organization IDs by account ID.
+func lockAccounts(ctx context.Context, tx pgx.Tx, ids ...int64) (map[int64]string, error) {
+	query := `SELECT id, org_id FROM accounts WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]string, len(ids))
+	for rows.Next() {
+		var id int64
+		var orgId string
+		if err := rows.Scan(&id, &orgId); err != nil {
+			return nil, fmt.Errorf("pgx error: %w", err)
+		}
+		result[id] = orgId
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	for _, id := range ids {
+		if _, ok := result[id]; !ok {
+			return nil, fmt.Errorf("account %d: %w", id, dao.ErrNoRows)
+		}
+	}
+	return result, nil
+}
+
+func insertAccountAudit(ctx context.Context, tx pgx.Tx, audit *models.AccountAudit) error {
+	query := `INSERT INTO account_audit (operation, account_id, source_account_id, old_org_id, new_org_id,
+		pubkeys, reservations, reservation_templates, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at`
+	err := tx.QueryRow(ctx, query, audit.Operation, audit.AccountID, audit.SourceAccountID, audit.OldOrgID,
+		audit.NewOrgID, audit.Pubkeys, audit.Reservations, audit.ReservationTemplates, audit.RequestedBy).
+		Scan(&audit.ID, &audit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *accountDao) UnscopedMerge(ctx context.Context, audit *models.AccountAudit) error {
+	ctx = db.WithUnscoped(ctx)
+	source, target := audit.SourceAccountID, audit.AccountID
+	if source == target {
+		return fmt.Errorf("merge of account %d into itself: %w", source, dao.ErrConflict)
+	}
+
+	return dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		orgIds, err := lockAccounts(ctx, tx, source, target)
+		if err != nil {
+			return err
+		}
+
+		// unique indexes would fail the update, report the conflict explicitly
+		conflictQuery := `SELECT
+			(SELECT COUNT(*) FROM pubkeys s JOIN pubkeys t
+				ON t.account_id = $2 AND t.deleted_at IS NULL AND (t.name = s.name OR t.fingerprint = s.fingerprint)
+				WHERE s.account_id = $1 AND s.deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM reservation_templates s JOIN reservation_templates t
+				ON t.account_id = $2 AND t.name = s.name
+				WHERE s.account_id = $1)`
+		var conflicts int64
+		err = tx.QueryRow(ctx, conflictQuery, source, target).Scan(&conflicts)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		if conflicts > 0 {
+			return fmt.Errorf("%d pubkeys or templates with the same name or fingerprint: %w", conflicts, dao.ErrConflict)
+		}
+
+		move := func(table string) (int64, error) {
+			tag, moveErr := tx.Exec(ctx, `UPDATE `+table+` SET account_id = $2 WHERE account_id = $1`, source, target)
+			if moveErr != nil {
+				return 0, fmt.Errorf("pgx error: %w", moveErr)
+			}
+			return tag.RowsAffected(), nil
+		}
+		var archived int64
+		if audit.Pubkeys, err = move("pubkeys"); err != nil {
+			return err
+		}
+		if audit.Reservations, err = move("reservations"); err != nil {
+			return err
+		}
+		if archived, err = move("reservations_archive"); err != nil {
+			return err
+		}
+		if audit.ReservationTemplates, err = move("reservation_templates"); err != nil {
+			return err
+		}
+		// instance actions are kept with their reservations, webhooks with their deliveries
+		for _, table := range []string{"instance_actions", "webhooks", "webhook_deliveries"} {
+			if _, err = move(table); err != nil {
+				return err
+			}
+		}
+
+		// resolved identities are a cache, identities of the target are kept on conflict
+		_, err = tx.Exec(ctx, `DELETE FROM source_identities s USING source_identities t
+			WHERE s.account_id = $1 AND t.account_id = $2 AND t.source_id = s.source_id`, source, target)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		if _, err = move("source_identities"); err != nil {
+			return err
+		}
+
+		// settings of the target are kept, settings of the source are only moved when the
+		// target has none
+		_, err = tx.Exec(ctx, `DELETE FROM account_settings
+			WHERE account_id = $1 AND EXISTS(SELECT 1 FROM account_settings WHERE account_id = $2)`, source, target)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		if _, err = move("account_settings"); err != nil {
+			return err
+		}
+
+		audit.Operation = models.AccountAuditMerge
+		audit.Reservations += archived
+		audit.OldOrgID = orgIds[source]
+		audit.NewOrgID = orgIds[target]
+		return insertAccountAudit(ctx, tx, audit)
+	})
+}
+
+func (x *accountDao) UnscopedRename(ctx context.Context, audit *models.AccountAudit) error {
+	ctx = db.WithUnscoped(ctx)
+
+	return dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		orgIds, err := lockAccounts(ctx, tx, audit.AccountID)
+		if err != nil {
+			return err
+		}
+
+		var taken bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM accounts WHERE org_id = $1)`, audit.NewOrgID).Scan(&taken)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		if taken {
+			return fmt.Errorf("organization %s already has an account: %w", audit.NewOrgID, dao.ErrConflict)
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE accounts SET org_id = $2 WHERE id = $1`, audit.AccountID, audit.NewOrgID)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+
+		audit.Operation = models.AccountAuditRename
+		audit.OldOrgID = orgIds[audit.AccountID]
+		return insertAccountAudit(ctx, tx, audit)
+	})
+}
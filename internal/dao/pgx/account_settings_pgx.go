@@ -0,0 +1,68 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+func init() {
+	dao.GetAccountSettingsDao = getAccountSettingsDao
+}
+
+type accountSettingsDao struct{}
+
+func getAccountSettingsDao(ctx context.Context) dao.AccountSettingsDao {
+	return &accountSettingsDao{}
+}
+
+func (x *accountSettingsDao) Get(ctx context.Context) (*models.AccountSettings, error) {
+	query := `SELECT * FROM account_settings WHERE account_id = $1 LIMIT 1`
+	accountId := identity.AccountId(ctx)
+	result := &models.AccountSettings{}
+
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId)
+	if errors.Is(err, dao.ErrNoRows) {
+		return &models.AccountSettings{AccountID: accountId, AllowedProviders: []string{}, RequiredTags: []string{}}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *accountSettingsDao) Upsert(ctx context.Context, settings *models.AccountSettings) error {
+	query := `
+		INSERT INTO account_settings (account_id, aws_region, azure_location, gcp_zone, pubkey_id, allowed_providers, max_hourly_spend, required_tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (account_id) DO UPDATE SET
+			aws_region = EXCLUDED.aws_region,
+			azure_location = EXCLUDED.azure_location,
+			gcp_zone = EXCLUDED.gcp_zone,
+			pubkey_id = EXCLUDED.pubkey_id,
+			allowed_providers = EXCLUDED.allowed_providers,
+			max_hourly_spend = EXCLUDED.max_hourly_spend,
+			required_tags = EXCLUDED.required_tags,
+			updated_at = now()
+		RETURNING updated_at`
+
+	settings.AccountID = identity.AccountId(ctx)
+	if settings.AllowedProviders == nil {
+		settings.AllowedProviders = []string{}
+	}
+	if settings.RequiredTags == nil {
+		settings.RequiredTags = []string{}
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, settings.AccountID, settings.AWSRegion, settings.AzureLocation, settings.GCPZone,
+		settings.PubkeyID, settings.AllowedProviders, settings.MaxHourlySpend, settings.RequiredTags).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+func init() {
+	dao.GetAPIAuditDao = getAPIAuditDao
+}
+
+type apiAuditDao struct{}
+
+func getAPIAuditDao(ctx context.Context) dao.APIAuditDao {
+	return &apiAuditDao{}
+}
+
+func (x *apiAuditDao) UnscopedCreate(ctx context.Context, audit *models.APIAudit) error {
+	query := `
+		INSERT INTO api_audit (account_id, org_id, principal, method, route, resource_id, status, trace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`
+
+	if vError := models.Validate(ctx, audit); vError != nil {
+		return fmt.Errorf("api audit validation: %w", vError)
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, audit.AccountID, audit.OrgID, audit.Principal, audit.Method, audit.Route,
+		audit.ResourceID, audit.Status, audit.TraceID).Scan(&audit.ID, &audit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *apiAuditDao) UnscopedListByAccount(ctx context.Context, accountId int64, limit, offset int64) ([]*models.APIAudit, error) {
+	query := `SELECT * FROM api_audit WHERE account_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`
+	var result []*models.APIAudit
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *apiAuditDao) UnscopedCountByAccount(ctx context.Context, accountId int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM api_audit WHERE account_id = $1`
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
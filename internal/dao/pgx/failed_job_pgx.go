@@ -0,0 +1,96 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+func init() {
+	dao.GetFailedJobDao = getFailedJobDao
+}
+
+type failedJobDao struct{}
+
+func getFailedJobDao(ctx context.Context) dao.FailedJobDao {
+	return &failedJobDao{}
+}
+
+func (x *failedJobDao) UnscopedCreate(ctx context.Context, job *models.FailedJob) error {
+	query := `
+		INSERT INTO failed_jobs (job_id, job_type, account_id, args, payload, error, attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, failed_at`
+
+	if vError := models.Validate(ctx, job); vError != nil {
+		return fmt.Errorf("failed job validation: %w", vError)
+	}
+	if len(job.Args) == 0 {
+		job.Args = []byte("{}")
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, job.JobID, job.JobType, job.AccountID, job.Args, job.Payload,
+		job.Error, job.Attempts).Scan(&job.ID, &job.FailedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *failedJobDao) UnscopedGetById(ctx context.Context, id int64) (*models.FailedJob, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM failed_jobs WHERE id = $1 LIMIT 1`
+	result := &models.FailedJob{}
+
+	err := pgxscan.Get(ctx, db.Conn(ctx), result, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *failedJobDao) UnscopedList(ctx context.Context, limit, offset int64) ([]*models.FailedJob, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM failed_jobs ORDER BY failed_at DESC, id DESC LIMIT $1 OFFSET $2`
+	var result []*models.FailedJob
+
+	rows, err := db.Conn(ctx).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *failedJobDao) UnscopedCount(ctx context.Context) (int64, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT COUNT(*) FROM failed_jobs`
+	var result int64
+
+	err := db.Conn(ctx).QueryRow(ctx, query).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *failedJobDao) UnscopedMarkReplayed(ctx context.Context, id int64) error {
+	ctx = db.WithUnscoped(ctx)
+	query := `UPDATE failed_jobs SET replayed_at = now() WHERE id = $1`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	dao.GetInstanceActionDao = getInstanceActionDao
+}
+
+type instanceActionDao struct{}
+
+func getInstanceActionDao(ctx context.Context) dao.InstanceActionDao {
+	return &instanceActionDao{}
+}
+
+func (x *instanceActionDao) Create(ctx context.Context, action *models.InstanceAction) error {
+	query := `
+		INSERT INTO instance_actions (account_id, reservation_id, instance_id, action) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (reservation_id, instance_id) WHERE status IN ('pending', 'running') DO NOTHING
+		RETURNING id, status, created_at`
+	action.AccountID = identity.AccountId(ctx)
+
+	if vError := models.Validate(ctx, action); vError != nil {
+		return fmt.Errorf("validate: %w", vError)
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, action.AccountID, action.ReservationID, action.InstanceID, string(action.Action)).
+		Scan(&action.ID, &action.Status, &action.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("instance %s has an unfinished action: %w", action.InstanceID, dao.ErrAffectedMismatch)
+	} else if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *instanceActionDao) GetById(ctx context.Context, id int64) (*models.InstanceAction, error) {
+	query := `SELECT * FROM instance_actions WHERE account_id = $1 AND id = $2 LIMIT 1`
+	accountId := identity.AccountId(ctx)
+	result := &models.InstanceAction{}
+
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *instanceActionDao) ListByReservation(ctx context.Context, reservationId, limit, offset int64) ([]*models.InstanceAction, error) {
+	query := `SELECT * FROM instance_actions WHERE account_id = $1 AND reservation_id = $2 ORDER BY id DESC LIMIT $3 OFFSET $4`
+	accountId := identity.AccountId(ctx)
+	var result []*models.InstanceAction
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, reservationId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *instanceActionDao) CountByReservation(ctx context.Context, reservationId int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM instance_actions WHERE account_id = $1 AND reservation_id = $2`
+	accountId := identity.AccountId(ctx)
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId, reservationId).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *instanceActionDao) UnscopedUpdateStatus(ctx context.Context, id int64, status models.InstanceActionStatus, errorString string) error {
+	query := `UPDATE instance_actions SET
+		status = $2,
+		error = $3,
+		finished_at = CASE WHEN $2 IN ('success', 'failed') THEN now() END
+		WHERE id = $1`
+	ctx = db.WithUnscoped(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, string(status), errorString)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	dao.GetInstanceTypeAvailabilityDao = getInstanceTypeAvailabilityDao
+}
+
+type instanceTypeAvailabilityDao struct{}
+
+func getInstanceTypeAvailabilityDao(ctx context.Context) dao.InstanceTypeAvailabilityDao {
+	return &instanceTypeAvailabilityDao{}
+}
+
+func (x *instanceTypeAvailabilityDao) UnscopedReplace(ctx context.Context, provider models.ProviderType, availability []*models.InstanceTypeAvailability) error {
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		deleteQuery := `DELETE FROM instance_type_availability WHERE provider = $1`
+		_, err := tx.Exec(ctx, deleteQuery, provider)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+
+		insertQuery := `INSERT INTO instance_type_availability (provider, region, zone, instance_types)
+			VALUES ($1, $2, $3, $4)`
+		for _, a := range availability {
+			tag, insertErr := tx.Exec(ctx, insertQuery, provider, a.Region, a.Zone, a.InstanceTypes)
+			if insertErr != nil {
+				return fmt.Errorf("pgx error: %w", insertErr)
+			}
+			if tag.RowsAffected() != 1 {
+				return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+			}
+		}
+
+		return nil
+	})
+
+	if txErr != nil {
+		return fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return nil
+}
+
+func (x *instanceTypeAvailabilityDao) UnscopedList(ctx context.Context, provider models.ProviderType) ([]*models.InstanceTypeAvailability, error) {
+	query := `SELECT * FROM instance_type_availability WHERE provider = $1 ORDER BY region, zone`
+	var result []*models.InstanceTypeAvailability
+
+	rows, err := db.Conn(ctx).Query(ctx, query, provider)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
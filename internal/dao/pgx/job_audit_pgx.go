@@ -0,0 +1,85 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+func init() {
+	dao.GetJobAuditDao = getJobAuditDao
+}
+
+type jobAuditDao struct{}
+
+func getJobAuditDao(ctx context.Context) dao.JobAuditDao {
+	return &jobAuditDao{}
+}
+
+func (x *jobAuditDao) UnscopedCreate(ctx context.Context, audit *models.JobAudit) error {
+	query := `
+		INSERT INTO job_audit (job_id, job_type, account_id, reservation_id, args_hash, attempt, outcome, error, started_at, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	if vError := models.Validate(ctx, audit); vError != nil {
+		return fmt.Errorf("job audit validation: %w", vError)
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, audit.JobID, audit.JobType, audit.AccountID, audit.ReservationID, audit.ArgsHash,
+		audit.Attempt, audit.Outcome, audit.Error, audit.StartedAt, audit.DurationMs).Scan(&audit.ID)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *jobAuditDao) UnscopedListByReservation(ctx context.Context, reservationId int64, limit, offset int64) ([]*models.JobAudit, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM job_audit WHERE reservation_id = $1 ORDER BY started_at DESC, id DESC LIMIT $2 OFFSET $3`
+	return x.list(ctx, query, reservationId, limit, offset)
+}
+
+func (x *jobAuditDao) UnscopedListByAccount(ctx context.Context, accountId int64, limit, offset int64) ([]*models.JobAudit, error) {
+	query := `SELECT * FROM job_audit WHERE account_id = $1 ORDER BY started_at DESC, id DESC LIMIT $2 OFFSET $3`
+	return x.list(ctx, query, accountId, limit, offset)
+}
+
+func (x *jobAuditDao) list(ctx context.Context, query string, id, limit, offset int64) ([]*models.JobAudit, error) {
+	var result []*models.JobAudit
+
+	rows, err := db.Conn(ctx).Query(ctx, query, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *jobAuditDao) UnscopedCountByReservation(ctx context.Context, reservationId int64) (int64, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT COUNT(*) FROM job_audit WHERE reservation_id = $1`
+	return x.count(ctx, query, reservationId)
+}
+
+func (x *jobAuditDao) UnscopedCountByAccount(ctx context.Context, accountId int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM job_audit WHERE account_id = $1`
+	return x.count(ctx, query, accountId)
+}
+
+func (x *jobAuditDao) count(ctx context.Context, query string, id int64) (int64, error) {
+	var result int64
+
+	err := db.Conn(ctx).QueryRow(ctx, query, id).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	dao.GetOutboxDao = getOutboxDao
+}
+
+type outboxDao struct{}
+
+func getOutboxDao(ctx context.Context) dao.OutboxDao {
+	return &outboxDao{}
+}
+
+// insertOutbox stores messages within a transaction of another change
+func insertOutbox(ctx context.Context, tx pgx.Tx, messages []*models.OutboxMessage) error {
+	query := `INSERT INTO outbox (topic, key, value, headers) VALUES ($1, $2, $3, $4) RETURNING id, created_at, next_attempt_at`
+
+	for _, m := range messages {
+		if vError := models.Validate(ctx, m); vError != nil {
+			return fmt.Errorf("outbox message validation: %w", vError)
+		}
+		if m.Headers == nil {
+			m.Headers = []models.OutboxHeader{}
+		}
+
+		err := tx.QueryRow(ctx, query, m.Topic, m.Key, m.Value, m.Headers).Scan(&m.ID, &m.CreatedAt, &m.NextAttemptAt)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (x *outboxDao) UnscopedEnqueue(ctx context.Context, messages ...*models.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		return insertOutbox(ctx, tx, messages)
+	})
+	if txErr != nil {
+		return fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return nil
+}
+
+func (x *outboxDao) UnscopedRelay(ctx context.Context, limit int64, publish func(*models.OutboxMessage) error) (int, int, error) {
+	selectQuery := `SELECT * FROM outbox WHERE next_attempt_at <= now() ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED`
+	deleteQuery := `DELETE FROM outbox WHERE id = ANY($1)`
+	failQuery := `UPDATE outbox SET
+		attempts = attempts + 1,
+		next_attempt_at = now() + make_interval(secs => LEAST(power(2, attempts + 1), 3600)),
+		last_error = $2
+		WHERE id = $1`
+	var published, failed int
+
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		var messages []*models.OutboxMessage
+		rows, err := tx.Query(ctx, selectQuery, limit)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		err = pgxscan.ScanAll(&messages, rows)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+
+		sent := make([]int64, 0, len(messages))
+		for _, m := range messages {
+			if pubErr := publish(m); pubErr != nil {
+				_, err = tx.Exec(ctx, failQuery, m.ID, pubErr.Error())
+				if err != nil {
+					return fmt.Errorf("pgx error: %w", err)
+				}
+				failed++
+				continue
+			}
+			sent = append(sent, m.ID)
+		}
+
+		if len(sent) > 0 {
+			_, err = tx.Exec(ctx, deleteQuery, sent)
+			if err != nil {
+				return fmt.Errorf("pgx error: %w", err)
+			}
+		}
+		published = len(sent)
+		return nil
+	})
+	if txErr != nil {
+		return 0, 0, fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return published, failed, nil
+}
+
+func (x *outboxDao) UnscopedCount(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM outbox`
+	var result int64
+
+	err := db.Conn(ctx).QueryRow(ctx, query).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
@@ -3,12 +3,14 @@ package pgx
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
 )
 
 func init() {
@@ -39,16 +41,18 @@ func (x *pubkeyDao) validate(ctx context.Context, pubkey *models.Pubkey) error {
 
 func (x *pubkeyDao) Create(ctx context.Context, pubkey *models.Pubkey) error {
 	query := `
-		INSERT INTO pubkeys (account_id, type, name, body, fingerprint, fingerprint_legacy)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		INSERT INTO pubkeys (account_id, type, name, body, fingerprint, fingerprint_legacy, origin, origin_username, imported_at, expires_at, owner, shared)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
 
 	pubkey.AccountID = identity.AccountId(ctx)
+	pubkey.Owner = identity.Owner(ctx)
 
 	if vError := x.validate(ctx, pubkey); vError != nil {
 		return fmt.Errorf("pubkey validation: %w", vError)
 	}
 
-	err := db.Pool.QueryRow(ctx, query, pubkey.AccountID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy).Scan(&pubkey.ID)
+	err := db.Conn(ctx).QueryRow(ctx, query, pubkey.AccountID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy,
+		pubkey.Origin, pubkey.OriginUsername, pubkey.ImportedAt, pubkey.ExpiresAt, pubkey.Owner, pubkey.Shared).Scan(&pubkey.ID)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -57,11 +61,12 @@ func (x *pubkeyDao) Create(ctx context.Context, pubkey *models.Pubkey) error {
 }
 
 func (x *pubkeyDao) GetById(ctx context.Context, id int64) (*models.Pubkey, error) {
-	query := `SELECT * FROM pubkeys WHERE account_id = $1 AND id = $2 LIMIT 1`
+	query := `SELECT * FROM pubkeys WHERE account_id = $1 AND id = $2 AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $3)) LIMIT 1`
 	accountId := identity.AccountId(ctx)
 	result := &models.Pubkey{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, accountId, id)
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id, identity.Owner(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -75,15 +80,17 @@ func (x *pubkeyDao) Update(ctx context.Context, pubkey *models.Pubkey) error {
 			name = $4,
 			body = $5,
 			fingerprint = $6,
-			fingerprint_legacy = $7
-		WHERE account_id = $1 AND id = $2`
+			fingerprint_legacy = $7,
+			expiry_notified_at = CASE WHEN expires_at IS DISTINCT FROM $8 THEN NULL ELSE expiry_notified_at END,
+			expires_at = $8
+		WHERE account_id = $1 AND id = $2 AND deleted_at IS NULL AND owner IN ('', $9)`
 	accountId := identity.AccountId(ctx)
 
 	if vError := x.validate(ctx, pubkey); vError != nil {
 		return fmt.Errorf("pubkey validation: %w", vError)
 	}
 
-	tag, err := db.Pool.Exec(ctx, query, accountId, pubkey.ID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy)
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, pubkey.ID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy, pubkey.ExpiresAt, identity.Owner(ctx))
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -94,11 +101,12 @@ func (x *pubkeyDao) Update(ctx context.Context, pubkey *models.Pubkey) error {
 }
 
 func (x *pubkeyDao) List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error) {
-	query := `SELECT * FROM pubkeys WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
+	query := `SELECT * FROM pubkeys WHERE account_id = $1 AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $4)) ORDER BY id LIMIT $2 OFFSET $3`
 	accountId := identity.AccountId(ctx)
 	var result []*models.Pubkey
 
-	rows, err := db.Pool.Query(ctx, query, accountId, limit, offset)
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, limit, offset, identity.Owner(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -110,11 +118,67 @@ func (x *pubkeyDao) List(ctx context.Context, limit, offset int64) ([]*models.Pu
 	return result, nil
 }
 
+func (x *pubkeyDao) Count(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM pubkeys WHERE account_id = $1 AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $2))`
+	accountId := identity.AccountId(ctx)
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId, identity.Owner(ctx)).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
 func (x *pubkeyDao) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM pubkeys WHERE account_id = $1 AND id = $2`
+	query := `UPDATE pubkeys SET deleted_at = now() WHERE account_id = $1 AND id = $2 AND deleted_at IS NULL
+		AND owner IN ('', $3)`
+	resourcesQuery := `DELETE FROM pubkey_resources WHERE pubkey_id = $1`
 	accountId := identity.AccountId(ctx)
 
-	tag, err := db.Pool.Exec(ctx, query, accountId, id)
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, accountId, id, identity.Owner(ctx))
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		if tag.RowsAffected() != 1 {
+			return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+		}
+
+		_, err = tx.Exec(ctx, resourcesQuery, id)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return nil
+}
+
+func (x *pubkeyDao) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE pubkeys SET deleted_at = NULL WHERE account_id = $1 AND id = $2 AND deleted_at IS NOT NULL
+		AND owner IN ('', $3)`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id, identity.Owner(ctx))
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *pubkeyDao) UpdateShared(ctx context.Context, id int64, shared bool) error {
+	query := `UPDATE pubkeys SET shared = $3 WHERE account_id = $1 AND id = $2 AND deleted_at IS NULL
+		AND owner IN ('', $4)`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id, shared, identity.Owner(ctx))
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -129,7 +193,7 @@ func (x *pubkeyDao) UnscopedCreateResource(ctx context.Context, pkr *models.Pubk
     	(pubkey_id, provider, source_id, handle, tag, region)
 		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, tag`
 
-	err := db.Pool.QueryRow(ctx, query,
+	err := db.Conn(ctx).QueryRow(ctx, query,
 		pkr.PubkeyID,
 		pkr.Provider,
 		pkr.SourceID,
@@ -147,7 +211,7 @@ func (x *pubkeyDao) UnscopedGetResourceBySourceAndRegion(ctx context.Context, pu
 	query := `SELECT * FROM pubkey_resources WHERE pubkey_id = $1 AND source_id = $2 AND region = $3`
 	result := &models.PubkeyResource{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, pubkeyId, sourceId, region)
+	err := pgxscan.Get(ctx, db.Conn(ctx), result, query, pubkeyId, sourceId, region)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -158,7 +222,7 @@ func (x *pubkeyDao) UnscopedListResourcesByPubkeyId(ctx context.Context, id int6
 	query := `SELECT * FROM pubkey_resources WHERE pubkey_id = $1`
 	var result []*models.PubkeyResource
 
-	rows, err := db.Pool.Query(ctx, query, id)
+	rows, err := db.Conn(ctx).Query(ctx, query, id)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -173,7 +237,55 @@ func (x *pubkeyDao) UnscopedListResourcesByPubkeyId(ctx context.Context, id int6
 func (x *pubkeyDao) UnscopedDeleteResource(ctx context.Context, id int64) error {
 	query := `DELETE FROM pubkey_resources WHERE id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id)
+	tag, err := db.Conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *pubkeyDao) UnscopedListExpiring(ctx context.Context, before time.Time) ([]*models.Pubkey, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM pubkeys WHERE expires_at <= $1 AND expiry_notified_at IS NULL AND deleted_at IS NULL ORDER BY id`
+	var result []*models.Pubkey
+
+	rows, err := db.Conn(ctx).Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *pubkeyDao) UnscopedListExpiringBetween(ctx context.Context, after, before time.Time) ([]*models.Pubkey, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM pubkeys WHERE expires_at > $1 AND expires_at <= $2 AND deleted_at IS NULL ORDER BY account_id, expires_at, id`
+	var result []*models.Pubkey
+
+	rows, err := db.Conn(ctx).Query(ctx, query, after, before)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *pubkeyDao) UnscopedMarkExpiryNotified(ctx context.Context, id int64) error {
+	ctx = db.WithUnscoped(ctx)
+	query := `UPDATE pubkeys SET expiry_notified_at = now() WHERE id = $1`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -182,3 +294,14 @@ func (x *pubkeyDao) UnscopedDeleteResource(ctx context.Context, id int64) error
 	}
 	return nil
 }
+
+func (x *pubkeyDao) UnscopedPurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `DELETE FROM pubkeys WHERE deleted_at < $1`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
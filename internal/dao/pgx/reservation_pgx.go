@@ -3,6 +3,7 @@ package pgx
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
@@ -40,7 +41,7 @@ func (x *reservationDao) CreateAWS(ctx context.Context, reservation *models.AWSR
 
 		awsQuery := `INSERT INTO aws_reservation_details (reservation_id, pubkey_id, source_id, image_id, detail)
 		VALUES ($1, $2, $3, $4, $5)`
-		tag, err := db.Pool.Exec(ctx, awsQuery,
+		tag, err := db.Conn(ctx).Exec(ctx, awsQuery,
 			reservation.ID,
 			reservation.PubkeyID,
 			reservation.SourceID,
@@ -71,7 +72,7 @@ func (x *reservationDao) CreateAzure(ctx context.Context, reservation *models.Az
 
 		azureQuery := `INSERT INTO azure_reservation_details (reservation_id, pubkey_id, source_id, image_id, detail)
 			VALUES ($1, $2, $3, $4, $5)`
-		tag, err := db.Pool.Exec(ctx, azureQuery,
+		tag, err := db.Conn(ctx).Exec(ctx, azureQuery,
 			reservation.ID,
 			reservation.PubkeyID,
 			reservation.SourceID,
@@ -102,7 +103,7 @@ func (x *reservationDao) CreateGCP(ctx context.Context, reservation *models.GCPR
 
 		gcpQuery := `INSERT INTO gcp_reservation_details (reservation_id, pubkey_id, source_id, image_id, detail)
 			VALUES ($1, $2, $3, $4, $5)`
-		tag, err := db.Pool.Exec(ctx, gcpQuery,
+		tag, err := db.Conn(ctx).Exec(ctx, gcpQuery,
 			reservation.ID,
 			reservation.PubkeyID,
 			reservation.SourceID,
@@ -126,16 +127,19 @@ func (x *reservationDao) CreateGCP(ctx context.Context, reservation *models.GCPR
 
 func (x *reservationDao) createGenericReservation(ctx context.Context, reservation *models.Reservation) error {
 	reservation.AccountID = identity.AccountId(ctx)
+	reservation.Owner = identity.Owner(ctx)
 	reservation.Status = "Created"
 
-	reservationQuery := `INSERT INTO reservations (provider, account_id, steps, step_titles, status)
-		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
-	err := db.Pool.QueryRow(ctx, reservationQuery,
+	reservationQuery := `INSERT INTO reservations (provider, account_id, steps, step_titles, status, owner, shared)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+	err := db.Conn(ctx).QueryRow(ctx, reservationQuery,
 		reservation.Provider,
 		reservation.AccountID,
 		reservation.Steps,
 		reservation.StepTitles,
-		reservation.Status).Scan(&reservation.ID, &reservation.CreatedAt)
+		reservation.Status,
+		reservation.Owner,
+		reservation.Shared).Scan(&reservation.ID, &reservation.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create reservation record: %w", err)
 	}
@@ -145,7 +149,7 @@ func (x *reservationDao) createGenericReservation(ctx context.Context, reservati
 func (x *reservationDao) CreateInstance(ctx context.Context, instance *models.ReservationInstance) error {
 	query := `INSERT INTO reservation_instances (reservation_id, instance_id, detail) VALUES ($1, $2, $3)`
 
-	tag, err := db.Pool.Exec(ctx, query,
+	tag, err := db.Conn(ctx).Exec(ctx, query,
 		instance.ReservationID,
 		instance.InstanceID,
 		instance.Detail)
@@ -165,7 +169,7 @@ func (x *reservationDao) UpdateReservationInstance(ctx context.Context, reservat
 		PublicIPv4: instance.PublicIPv4,
 		PublicDNS:  instance.PublicDNS,
 	}
-	tag, err := db.Pool.Exec(ctx, query, reservationID, instance.ID, detail)
+	tag, err := db.Conn(ctx).Exec(ctx, query, reservationID, instance.ID, detail)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -177,11 +181,12 @@ func (x *reservationDao) UpdateReservationInstance(ctx context.Context, reservat
 }
 
 func (x *reservationDao) GetById(ctx context.Context, id int64) (*models.Reservation, error) {
-	query := `SELECT * FROM reservations WHERE account_id = $1 AND id = $2 LIMIT 1`
+	query := `SELECT * FROM reservations WHERE account_id = $1 AND id = $2 AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $3)) LIMIT 1`
 	accountId := identity.AccountId(ctx)
 	result := &models.Reservation{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, accountId, id)
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id, identity.Owner(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -189,14 +194,15 @@ func (x *reservationDao) GetById(ctx context.Context, id int64) (*models.Reserva
 }
 
 func (x *reservationDao) GetAWSById(ctx context.Context, id int64) (*models.AWSReservation, error) {
-	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success,
+	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success, owner, shared,
     	pubkey_id, source_id, image_id, aws_reservation_id, detail
 		FROM reservations, aws_reservation_details
-		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND provider = provider_type_aws() LIMIT 1`
+		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND provider = provider_type_aws() AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $3)) LIMIT 1`
 	accountId := identity.AccountId(ctx)
 	result := &models.AWSReservation{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, accountId, id)
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id, identity.Owner(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -204,14 +210,15 @@ func (x *reservationDao) GetAWSById(ctx context.Context, id int64) (*models.AWSR
 }
 
 func (x *reservationDao) GetAzureById(ctx context.Context, id int64) (*models.AzureReservation, error) {
-	query := `SELECT id, reservations.provider, account_id, created_at, steps, step, status, error, finished_at, success,
+	query := `SELECT id, reservations.provider, account_id, created_at, steps, step, status, error, finished_at, success, owner, shared,
     	pubkey_id, source_id, image_id, detail
 		FROM reservations, azure_reservation_details
-		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND reservations.provider = provider_type_azure() LIMIT 1`
+		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND reservations.provider = provider_type_azure() AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $3)) LIMIT 1`
 	accountId := identity.AccountId(ctx)
 	result := &models.AzureReservation{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, accountId, id)
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id, identity.Owner(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -219,14 +226,15 @@ func (x *reservationDao) GetAzureById(ctx context.Context, id int64) (*models.Az
 }
 
 func (x *reservationDao) GetGCPById(ctx context.Context, id int64) (*models.GCPReservation, error) {
-	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success,
+	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success, owner, shared,
     	pubkey_id, source_id, image_id, detail
 		FROM reservations, gcp_reservation_details
-		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND provider = provider_type_gcp() LIMIT 1`
+		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND provider = provider_type_gcp() AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $3)) LIMIT 1`
 	accountId := identity.AccountId(ctx)
 	result := &models.GCPReservation{}
 
-	err := pgxscan.Get(ctx, db.Pool, result, query, accountId, id)
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id, identity.Owner(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -234,12 +242,171 @@ func (x *reservationDao) GetGCPById(ctx context.Context, id int64) (*models.GCPR
 }
 
 func (x *reservationDao) List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error) {
-	query := `SELECT * FROM reservations WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
+	query := `SELECT * FROM reservations WHERE account_id = $1 AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $4)) ORDER BY id LIMIT $2 OFFSET $3`
 
 	accountId := identity.AccountId(ctx)
 	var result []*models.Reservation
 
-	rows, err := db.Pool.Query(ctx, query, accountId, limit, offset)
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, limit, offset, identity.Owner(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) Count(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM reservations WHERE account_id = $1 AND deleted_at IS NULL
+		AND (shared OR owner IN ('', $2))`
+	accountId := identity.AccountId(ctx)
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId, identity.Owner(ctx)).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) StatsByProvider(ctx context.Context, since time.Time) ([]*models.ReservationProviderStat, error) {
+	query := `
+		SELECT provider,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE success = true) AS success,
+			COUNT(*) FILTER (WHERE success = false AND status <> $3) AS failure,
+			COUNT(*) FILTER (WHERE success = false AND status = $3) AS cancelled,
+			COUNT(*) FILTER (WHERE success IS NULL) AS pending,
+			COALESCE(SUM(instances.count), 0) AS instances,
+			COALESCE(COUNT(*) FILTER (WHERE success = true)::float8 /
+				NULLIF(COUNT(*) FILTER (WHERE success IS NOT NULL AND status <> $3), 0), 0) AS success_rate
+		FROM reservations
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS count FROM reservation_instances WHERE reservation_id = reservations.id
+		) AS instances ON true
+		WHERE account_id = $1 AND created_at >= $2 AND deleted_at IS NULL
+		GROUP BY provider
+		ORDER BY provider`
+
+	accountId := identity.AccountId(ctx)
+	var result []*models.ReservationProviderStat
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, since, models.ReservationStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) DailyStats(ctx context.Context, since time.Time) ([]*models.ReservationDailyStat, error) {
+	query := `
+		SELECT date_trunc('day', created_at) AS day, provider,
+			CASE
+				WHEN success IS NULL THEN $3
+				WHEN success = true THEN $4
+				WHEN status = $6 THEN $5
+				ELSE $7
+			END AS result,
+			COUNT(*) AS count
+		FROM reservations
+		WHERE account_id = $1 AND created_at >= $2 AND deleted_at IS NULL
+		GROUP BY day, provider, result
+		ORDER BY day, provider, result`
+
+	accountId := identity.AccountId(ctx)
+	var result []*models.ReservationDailyStat
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, since,
+		models.ReservationResultPending, models.ReservationResultSuccess, models.ReservationResultCancelled,
+		models.ReservationStatusCancelled, models.ReservationResultFailure)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+// liveInstanceAmount is the amount of instances of a reservation which are expected to run:
+// the requested amount while the reservation is pending, launched instances not stopped by
+// the last successful stop or start action once it has finished.
+const liveInstanceAmount = `CASE WHEN finished_at IS NULL THEN COALESCE((detail->>'amount')::bigint, 0) ELSE (
+		SELECT count(*) FROM reservation_instances ri
+		WHERE ri.reservation_id = reservations.id AND (
+			SELECT ia.action FROM instance_actions ia
+			WHERE ia.reservation_id = ri.reservation_id AND ia.instance_id = ri.instance_id
+				AND ia.status = 'success' AND ia.action IN ('stop', 'start')
+			ORDER BY ia.id DESC LIMIT 1) IS DISTINCT FROM 'stop') END`
+
+func (x *reservationDao) ListRunningInstanceTypes(ctx context.Context) ([]*models.ReservationInstanceTypeAmount, error) {
+	query := `
+		SELECT provider, instance_type, amount FROM (
+			SELECT reservations.provider,
+				COALESCE(NULLIF(detail->>'instance_type', ''), detail->'instance_types'->>0, '') AS instance_type,
+				` + liveInstanceAmount + ` AS amount
+			FROM reservations JOIN aws_reservation_details ON reservations.id = aws_reservation_details.reservation_id
+			WHERE account_id = $1 AND deleted_at IS NULL AND success IS NOT false
+				AND NOT COALESCE((detail->>'poweroff')::boolean, false)
+			UNION ALL
+			SELECT reservations.provider, COALESCE(detail->>'instance_size', '') AS instance_type,
+				` + liveInstanceAmount + ` AS amount
+			FROM reservations JOIN azure_reservation_details ON reservations.id = azure_reservation_details.reservation_id
+			WHERE account_id = $1 AND deleted_at IS NULL AND success IS NOT false
+				AND NOT COALESCE((detail->>'poweroff')::boolean, false)
+			UNION ALL
+			SELECT reservations.provider, COALESCE(detail->>'machine_type', '') AS instance_type,
+				` + liveInstanceAmount + ` AS amount
+			FROM reservations JOIN gcp_reservation_details ON reservations.id = gcp_reservation_details.reservation_id
+			WHERE account_id = $1 AND deleted_at IS NULL AND success IS NOT false
+				AND NOT COALESCE((detail->>'poweroff')::boolean, false)
+		) AS live WHERE amount > 0`
+
+	accountId := identity.AccountId(ctx)
+	var result []*models.ReservationInstanceTypeAmount
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) ListByPubkeyId(ctx context.Context, pubkeyId int64) ([]*models.PubkeyReservation, error) {
+	query := `
+		SELECT id AS reservation_id, provider, source_id, detail->>'region' AS region, status, created_at, success
+			FROM reservations, aws_reservation_details
+			WHERE account_id = $1 AND pubkey_id = $2 AND id = reservation_id AND deleted_at IS NULL
+		UNION ALL
+		SELECT id, reservations.provider, source_id, detail->>'location', status, created_at, success
+			FROM reservations, azure_reservation_details
+			WHERE account_id = $1 AND pubkey_id = $2 AND id = reservation_id AND deleted_at IS NULL
+		UNION ALL
+		SELECT id, provider, source_id, detail->>'zone', status, created_at, success
+			FROM reservations, gcp_reservation_details
+			WHERE account_id = $1 AND pubkey_id = $2 AND id = reservation_id AND deleted_at IS NULL
+		ORDER BY reservation_id`
+
+	accountId := identity.AccountId(ctx)
+	var result []*models.PubkeyReservation
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, pubkeyId)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -253,12 +420,13 @@ func (x *reservationDao) List(ctx context.Context, limit, offset int64) ([]*mode
 
 func (x *reservationDao) ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
 	query := `SELECT reservation_id, instance_id, detail FROM reservation_instances, reservations
-         WHERE reservation_id = reservations.id AND account_id = $1 AND reservation_id = $2`
+         WHERE reservation_id = reservations.id AND account_id = $1 AND reservation_id = $2 AND deleted_at IS NULL
+         AND (shared OR owner IN ('', $3))`
 
 	accountId := identity.AccountId(ctx)
 	var result []*models.ReservationInstance
 
-	rows, err := db.Pool.Query(ctx, query, accountId, reservationId)
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, reservationId, identity.Owner(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -271,9 +439,10 @@ func (x *reservationDao) ListInstances(ctx context.Context, reservationId int64)
 }
 
 func (x *reservationDao) UpdateStatus(ctx context.Context, id int64, status string, addSteps int32) error {
+	ctx = db.WithUnscoped(ctx)
 	query := `UPDATE reservations SET status = $2, step = step + $3 WHERE id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id, status, addSteps)
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, status, addSteps)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -286,7 +455,20 @@ func (x *reservationDao) UpdateStatus(ctx context.Context, id int64, status stri
 func (x *reservationDao) UnscopedUpdateAWSDetail(ctx context.Context, id int64, awsDetail *models.AWSDetail) error {
 	query := `UPDATE aws_reservation_details SET detail = $2 WHERE reservation_id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id, awsDetail)
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, awsDetail)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) UnscopedUpdateAzureDetail(ctx context.Context, id int64, azureDetail *models.AzureDetail) error {
+	query := `UPDATE azure_reservation_details SET detail = $2 WHERE reservation_id = $1`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, azureDetail)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -299,7 +481,7 @@ func (x *reservationDao) UnscopedUpdateAWSDetail(ctx context.Context, id int64,
 func (x *reservationDao) UpdateReservationIDForAWS(ctx context.Context, id int64, awsReservationId string) error {
 	query := `UPDATE aws_reservation_details SET aws_reservation_id = $2 WHERE reservation_id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id, awsReservationId)
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, awsReservationId)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -312,7 +494,7 @@ func (x *reservationDao) UpdateReservationIDForAWS(ctx context.Context, id int64
 func (x *reservationDao) UpdateOperationNameForGCP(ctx context.Context, id int64, gcpOperationName string) error {
 	query := `UPDATE gcp_reservation_details SET gcp_operation_name = $2 WHERE reservation_id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id, gcpOperationName)
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, gcpOperationName)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -322,10 +504,52 @@ func (x *reservationDao) UpdateOperationNameForGCP(ctx context.Context, id int64
 	return nil
 }
 
-func (x *reservationDao) FinishWithSuccess(ctx context.Context, id int64) error {
+func (x *reservationDao) FinishWithSuccess(ctx context.Context, id int64, outbox ...*models.OutboxMessage) error {
+	ctx = db.WithUnscoped(ctx)
 	query := `UPDATE reservations SET success = true, finished_at = now() WHERE id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id)
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		if tag.RowsAffected() != 1 {
+			return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+		}
+		return insertOutbox(ctx, tx, outbox)
+	})
+	if txErr != nil {
+		return fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return nil
+}
+
+func (x *reservationDao) FinishWithError(ctx context.Context, id int64, errorString string, outbox ...*models.OutboxMessage) error {
+	ctx = db.WithUnscoped(ctx)
+	query := `UPDATE reservations SET success = false, error = $2, finished_at = now() WHERE id = $1`
+
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id, errorString)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		if tag.RowsAffected() != 1 {
+			return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+		}
+		return insertOutbox(ctx, tx, outbox)
+	})
+	if txErr != nil {
+		return fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return nil
+}
+
+func (x *reservationDao) RequestCancel(ctx context.Context, id int64) error {
+	query := `UPDATE reservations SET cancel_requested = true WHERE account_id = $1 AND id = $2 AND finished_at IS NULL
+		AND owner IN ('', $3)`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id, identity.Owner(ctx))
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -335,10 +559,23 @@ func (x *reservationDao) FinishWithSuccess(ctx context.Context, id int64) error
 	return nil
 }
 
-func (x *reservationDao) FinishWithError(ctx context.Context, id int64, errorString string) error {
-	query := `UPDATE reservations SET success = false, error = $2, finished_at = now() WHERE id = $1`
+func (x *reservationDao) UnscopedIsCancelRequested(ctx context.Context, id int64) (bool, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT cancel_requested FROM reservations WHERE id = $1`
+	var result bool
+
+	err := db.Conn(ctx).QueryRow(ctx, query, id).Scan(&result)
+	if err != nil {
+		return false, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) FinishWithCancel(ctx context.Context, id int64, errorString string) error {
+	ctx = db.WithUnscoped(ctx)
+	query := `UPDATE reservations SET success = false, status = $2, error = $3, finished_at = now() WHERE id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id, errorString)
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, models.ReservationStatusCancelled, errorString)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -348,10 +585,232 @@ func (x *reservationDao) FinishWithError(ctx context.Context, id int64, errorStr
 	return nil
 }
 
+func (x *reservationDao) UnscopedUpdateSSHReady(ctx context.Context, id int64, ready bool) error {
+	ctx = db.WithUnscoped(ctx)
+	query := `UPDATE reservations SET ssh_ready = $2 WHERE id = $1`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, id, ready)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) SoftDelete(ctx context.Context, id int64) error {
+	query := `UPDATE reservations SET deleted_at = now() WHERE account_id = $1 AND id = $2 AND finished_at IS NOT NULL AND deleted_at IS NULL
+		AND owner IN ('', $3)`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id, identity.Owner(ctx))
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE reservations SET deleted_at = NULL WHERE account_id = $1 AND id = $2 AND deleted_at IS NOT NULL
+		AND owner IN ('', $3)`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id, identity.Owner(ctx))
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) UpdateShared(ctx context.Context, id int64, shared bool) error {
+	query := `UPDATE reservations SET shared = $3 WHERE account_id = $1 AND id = $2 AND deleted_at IS NULL
+		AND owner IN ('', $4)`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id, shared, identity.Owner(ctx))
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) UnscopedGetById(ctx context.Context, id int64) (*models.Reservation, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM reservations WHERE id = $1 LIMIT 1`
+	result := &models.Reservation{}
+
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedListByAccount(ctx context.Context, accountId, limit, offset int64) ([]*models.Reservation, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM reservations WHERE account_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3`
+	var result []*models.Reservation
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedCountByAccount(ctx context.Context, accountId int64) (int64, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT COUNT(*) FROM reservations WHERE account_id = $1`
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT reservation_id, instance_id, detail FROM reservation_instances WHERE reservation_id = $1`
+	var result []*models.ReservationInstance
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, reservationId)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedListDeletedBetween(ctx context.Context, after, before time.Time) ([]*models.Reservation, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `SELECT * FROM reservations WHERE deleted_at > $1 AND deleted_at <= $2 ORDER BY account_id, deleted_at, id`
+	var result []*models.Reservation
+
+	rows, err := db.Conn(ctx).Query(ctx, query, after, before)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedPurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx = db.WithUnscoped(ctx)
+	query := `DELETE FROM reservations WHERE deleted_at < $1`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (x *reservationDao) UnscopedArchive(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	ctx = db.WithUnscoped(ctx)
+	// only terminated reservations are archived: failed or cancelled (launched instances were
+	// terminated by compensation) and those without instances, details and instances are deleted
+	// by cascade, sub-queries still see them (same snapshot)
+	query := `WITH archived AS (
+			DELETE FROM reservations WHERE id IN (
+				SELECT id FROM reservations r WHERE finished_at < $1 AND deleted_at IS NULL
+					AND (success = false OR NOT EXISTS (SELECT 1 FROM reservation_instances i WHERE i.reservation_id = r.id))
+				ORDER BY id LIMIT $2)
+			RETURNING *)
+		INSERT INTO reservations_archive (id, provider, account_id, created_at, steps, step_titles, step,
+			status, error, finished_at, success, cancel_requested, ssh_ready, owner, shared, detail, instances)
+		SELECT a.id, a.provider, a.account_id, a.created_at, a.steps, a.step_titles, a.step,
+			a.status, a.error, a.finished_at, a.success, a.cancel_requested, a.ssh_ready, a.owner, a.shared,
+			COALESCE(
+				(SELECT to_jsonb(d) - 'reservation_id' - 'provider' FROM aws_reservation_details d WHERE d.reservation_id = a.id),
+				(SELECT to_jsonb(d) - 'reservation_id' - 'provider' FROM gcp_reservation_details d WHERE d.reservation_id = a.id),
+				(SELECT to_jsonb(d) - 'reservation_id' - 'provider_fk' FROM azure_reservation_details d WHERE d.reservation_id = a.id),
+				'{}'::jsonb),
+			COALESCE(
+				(SELECT jsonb_agg(to_jsonb(i) - 'reservation_id') FROM reservation_instances i WHERE i.reservation_id = a.id),
+				'[]'::jsonb)
+		FROM archived a`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (x *reservationDao) GetArchivedById(ctx context.Context, id int64) (*models.ArchivedReservation, error) {
+	query := `SELECT * FROM reservations_archive WHERE account_id = $1 AND id = $2
+		AND (shared OR owner IN ('', $3)) LIMIT 1`
+	accountId := identity.AccountId(ctx)
+	result := &models.ArchivedReservation{}
+
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id, identity.Owner(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) ListArchived(ctx context.Context, limit, offset int64) ([]*models.ArchivedReservation, error) {
+	query := `SELECT * FROM reservations_archive WHERE account_id = $1
+		AND (shared OR owner IN ('', $4)) ORDER BY id LIMIT $2 OFFSET $3`
+	accountId := identity.AccountId(ctx)
+	var result []*models.ArchivedReservation
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, limit, offset, identity.Owner(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) CountArchived(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM reservations_archive WHERE account_id = $1
+		AND (shared OR owner IN ('', $2))`
+	accountId := identity.AccountId(ctx)
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId, identity.Owner(ctx)).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
 func (x *reservationDao) Delete(ctx context.Context, id int64) error {
+	ctx = db.WithUnscoped(ctx)
 	query := `DELETE FROM reservations WHERE id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, id)
+	tag, err := db.Conn(ctx).Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
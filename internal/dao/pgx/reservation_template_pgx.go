@@ -0,0 +1,135 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+func init() {
+	dao.GetReservationTemplateDao = getReservationTemplateDao
+}
+
+type reservationTemplateDao struct{}
+
+func getReservationTemplateDao(ctx context.Context) dao.ReservationTemplateDao {
+	return &reservationTemplateDao{}
+}
+
+func (x *reservationTemplateDao) validate(ctx context.Context, template *models.ReservationTemplate) error {
+	if vError := models.Validate(ctx, template); vError != nil {
+		return fmt.Errorf("validate: %w", vError)
+	}
+	if template.Tags == nil {
+		template.Tags = map[string]string{}
+	}
+	return nil
+}
+
+func (x *reservationTemplateDao) Create(ctx context.Context, template *models.ReservationTemplate) error {
+	query := `
+		INSERT INTO reservation_templates (account_id, name, provider, source_id, image_id, instance_type, region, pubkey_id, tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at`
+
+	template.AccountID = identity.AccountId(ctx)
+
+	if vError := x.validate(ctx, template); vError != nil {
+		return fmt.Errorf("reservation template validation: %w", vError)
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, template.AccountID, template.Name, template.Provider, template.SourceID, template.ImageID,
+		template.InstanceType, template.Region, template.PubkeyID, template.Tags).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+
+	return nil
+}
+
+func (x *reservationTemplateDao) Update(ctx context.Context, template *models.ReservationTemplate) error {
+	query := `
+		UPDATE reservation_templates SET
+			name = $3,
+			provider = $4,
+			source_id = $5,
+			image_id = $6,
+			instance_type = $7,
+			region = $8,
+			pubkey_id = $9,
+			tags = $10,
+			updated_at = now()
+		WHERE account_id = $1 AND id = $2
+		RETURNING updated_at`
+	accountId := identity.AccountId(ctx)
+
+	if vError := x.validate(ctx, template); vError != nil {
+		return fmt.Errorf("reservation template validation: %w", vError)
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, accountId, template.ID, template.Name, template.Provider, template.SourceID, template.ImageID,
+		template.InstanceType, template.Region, template.PubkeyID, template.Tags).Scan(&template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *reservationTemplateDao) GetById(ctx context.Context, id int64) (*models.ReservationTemplate, error) {
+	query := `SELECT * FROM reservation_templates WHERE account_id = $1 AND id = $2 LIMIT 1`
+	accountId := identity.AccountId(ctx)
+	result := &models.ReservationTemplate{}
+
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationTemplateDao) List(ctx context.Context, limit, offset int64) ([]*models.ReservationTemplate, error) {
+	query := `SELECT * FROM reservation_templates WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
+	accountId := identity.AccountId(ctx)
+	var result []*models.ReservationTemplate
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationTemplateDao) Count(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM reservation_templates WHERE account_id = $1`
+	accountId := identity.AccountId(ctx)
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationTemplateDao) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM reservation_templates WHERE account_id = $1 AND id = $2`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+func init() {
+	dao.GetRunningJobDao = getRunningJobDao
+}
+
+type runningJobDao struct{}
+
+func getRunningJobDao(ctx context.Context) dao.RunningJobDao {
+	return &runningJobDao{}
+}
+
+func (x *runningJobDao) UnscopedStart(ctx context.Context, job *models.RunningJob) error {
+	query := `
+		INSERT INTO running_jobs (job_id, job_type, worker, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_id) DO UPDATE SET
+			worker = EXCLUDED.worker,
+			payload = EXCLUDED.payload,
+			started_at = now(),
+			heartbeat_at = now()
+		RETURNING started_at, heartbeat_at`
+
+	if vError := models.Validate(ctx, job); vError != nil {
+		return fmt.Errorf("running job validation: %w", vError)
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, job.JobID, job.JobType, job.Worker, job.Payload).Scan(&job.StartedAt, &job.HeartbeatAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *runningJobDao) UnscopedHeartbeat(ctx context.Context, jobId string) error {
+	query := `UPDATE running_jobs SET heartbeat_at = now() WHERE job_id = $1`
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, jobId)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *runningJobDao) UnscopedFinish(ctx context.Context, jobId string) error {
+	query := `DELETE FROM running_jobs WHERE job_id = $1`
+
+	_, err := db.Conn(ctx).Exec(ctx, query, jobId)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *runningJobDao) UnscopedReapStale(ctx context.Context, before time.Time) ([]*models.RunningJob, error) {
+	query := `DELETE FROM running_jobs WHERE heartbeat_at < $1 RETURNING *`
+	var result []*models.RunningJob
+
+	rows, err := db.Conn(ctx).Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
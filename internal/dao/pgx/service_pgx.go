@@ -31,7 +31,7 @@ func UnscopedUpdatePubkey(ctx context.Context, pubkey *models.Pubkey) error {
 			fingerprint_legacy = $6
 		WHERE id = $1`
 
-	tag, err := db.Pool.Exec(ctx, query, pubkey.ID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy)
+	tag, err := db.Conn(ctx).Exec(ctx, query, pubkey.ID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -45,11 +45,12 @@ func UnscopedUpdatePubkey(ctx context.Context, pubkey *models.Pubkey) error {
 // the fingerprints or type. The type column with value "test" is also considered as pubkey which needs
 // to be recalculated as this is used in tests. Fingerprints starting with "SHA256" are also considered the same.
 func (x *serviceDao) RecalculatePubkeyFingerprints(ctx context.Context) (int, error) {
+	ctx = db.WithUnscoped(ctx)
 	total := 0
 	query := `SELECT * FROM pubkeys WHERE type = '' OR type = 'test' OR fingerprint LIKE 'SHA256:%' OR fingerprint = '' OR fingerprint_legacy = ''`
 	logger := zerolog.Ctx(ctx)
 
-	rows, err := db.Pool.Query(ctx, query)
+	rows, err := db.Conn(ctx).Query(ctx, query)
 	if err != nil {
 		return total, fmt.Errorf("pgx error: %w", err)
 	}
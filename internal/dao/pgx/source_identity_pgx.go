@@ -0,0 +1,54 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+func init() {
+	dao.GetSourceIdentityDao = getSourceIdentityDao
+}
+
+type sourceIdentityDao struct{}
+
+func getSourceIdentityDao(ctx context.Context) dao.SourceIdentityDao {
+	return &sourceIdentityDao{}
+}
+
+func (x *sourceIdentityDao) GetBySourceId(ctx context.Context, sourceId string) (*models.SourceIdentity, error) {
+	query := `SELECT * FROM source_identities WHERE account_id = $1 AND source_id = $2 LIMIT 1`
+	accountId := identity.AccountId(ctx)
+	result := &models.SourceIdentity{}
+
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, sourceId)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *sourceIdentityDao) Upsert(ctx context.Context, sourceIdentity *models.SourceIdentity) error {
+	query := `
+		INSERT INTO source_identities (account_id, source_id, authentication, provider_account_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account_id, source_id) DO UPDATE SET
+			authentication = EXCLUDED.authentication,
+			provider_account_id = EXCLUDED.provider_account_id,
+			resolved_at = now()
+		RETURNING resolved_at`
+
+	sourceIdentity.AccountID = identity.AccountId(ctx)
+
+	err := db.Conn(ctx).QueryRow(ctx, query, sourceIdentity.AccountID, sourceIdentity.SourceID, sourceIdentity.Authentication,
+		sourceIdentity.ProviderAccountID).Scan(&sourceIdentity.ResolvedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ func getStatDao(ctx context.Context) dao.StatDao {
 }
 
 func (x *statDao) getUsage(ctx context.Context, interval string) ([]*models.UsageStat, error) {
+	ctx = db.WithUnscoped(ctx)
 	query := `select provider, 'success' as result, count(provider) as count
 	from reservations
 	where created_at >= now() - cast($1 as interval)
@@ -44,7 +45,7 @@ func (x *statDao) getUsage(ctx context.Context, interval string) ([]*models.Usag
 	group by provider`
 
 	var result []*models.UsageStat
-	rows, err := db.Pool.Query(ctx, query, interval)
+	rows, err := db.ReadPool(ctx).Query(ctx, query, interval)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
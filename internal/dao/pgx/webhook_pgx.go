@@ -0,0 +1,201 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	dao.GetWebhookDao = getWebhookDao
+}
+
+type webhookDao struct{}
+
+func getWebhookDao(ctx context.Context) dao.WebhookDao {
+	return &webhookDao{}
+}
+
+func (x *webhookDao) Create(ctx context.Context, webhook *models.Webhook) error {
+	query := `INSERT INTO webhooks (account_id, url, secret, events) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	webhook.AccountID = identity.AccountId(ctx)
+
+	if vError := models.Validate(ctx, webhook); vError != nil {
+		return fmt.Errorf("validate: %w", vError)
+	}
+
+	err := db.Conn(ctx).QueryRow(ctx, query, webhook.AccountID, webhook.URL, webhook.Secret, webhook.Events).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	return nil
+}
+
+func (x *webhookDao) GetById(ctx context.Context, id int64) (*models.Webhook, error) {
+	query := `SELECT * FROM webhooks WHERE account_id = $1 AND id = $2 LIMIT 1`
+	accountId := identity.AccountId(ctx)
+	result := &models.Webhook{}
+
+	err := pgxscan.Get(ctx, db.ReadPool(ctx), result, query, accountId, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *webhookDao) List(ctx context.Context, limit, offset int64) ([]*models.Webhook, error) {
+	query := `SELECT * FROM webhooks WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
+	accountId := identity.AccountId(ctx)
+	var result []*models.Webhook
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *webhookDao) Count(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM webhooks WHERE account_id = $1`
+	accountId := identity.AccountId(ctx)
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *webhookDao) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM webhooks WHERE account_id = $1 AND id = $2`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row: %w", dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *webhookDao) ListDeliveries(ctx context.Context, webhookId, limit, offset int64) ([]*models.WebhookDelivery, error) {
+	query := `SELECT * FROM webhook_deliveries WHERE account_id = $1 AND webhook_id = $2 ORDER BY id DESC LIMIT $3 OFFSET $4`
+	accountId := identity.AccountId(ctx)
+	var result []*models.WebhookDelivery
+
+	rows, err := db.ReadPool(ctx).Query(ctx, query, accountId, webhookId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *webhookDao) CountDeliveries(ctx context.Context, webhookId int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM webhook_deliveries WHERE account_id = $1 AND webhook_id = $2`
+	accountId := identity.AccountId(ctx)
+	var result int64
+
+	err := db.ReadPool(ctx).QueryRow(ctx, query, accountId, webhookId).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *webhookDao) UnscopedCreateDeliveries(ctx context.Context, accountId int64, event models.WebhookEvent, payload []byte) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, account_id, event, payload)
+		SELECT id, account_id, $2, $3 FROM webhooks WHERE account_id = $1 AND $2 = ANY(events)`
+	ctx = db.WithUnscoped(ctx)
+
+	tag, err := db.Conn(ctx).Exec(ctx, query, accountId, string(event), payload)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// webhookDeliveryJoin is a delivery scanned together with the target endpoint.
+type webhookDeliveryJoin struct {
+	models.WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+func (x *webhookDao) UnscopedDeliver(ctx context.Context, limit int64, maxAttempts int, deliver func(*models.Webhook, *models.WebhookDelivery) (int, error)) (int, int, error) {
+	selectQuery := `
+		SELECT d.*, w.url, w.secret FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = 'pending' AND d.next_attempt_at <= now()
+		ORDER BY d.id LIMIT $1 FOR UPDATE OF d SKIP LOCKED`
+	successQuery := `UPDATE webhook_deliveries SET
+		status = 'delivered',
+		attempts = attempts + 1,
+		response_status = $2,
+		last_error = '',
+		delivered_at = now()
+		WHERE id = $1`
+	failQuery := `UPDATE webhook_deliveries SET
+		status = CASE WHEN attempts + 1 >= $4 THEN 'failed' ELSE 'pending' END,
+		attempts = attempts + 1,
+		next_attempt_at = now() + make_interval(secs => LEAST(power(2, attempts + 1), 3600)),
+		response_status = $2,
+		last_error = $3
+		WHERE id = $1`
+	ctx = db.WithUnscoped(ctx)
+	var delivered, failed int
+
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		var deliveries []*webhookDeliveryJoin
+		rows, err := tx.Query(ctx, selectQuery, limit)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		err = pgxscan.ScanAll(&deliveries, rows)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+
+		for _, d := range deliveries {
+			webhook := &models.Webhook{ID: d.WebhookID, AccountID: d.AccountID, URL: d.URL, Secret: d.Secret}
+			status, deliverErr := deliver(webhook, &d.WebhookDelivery)
+			if deliverErr != nil {
+				_, err = tx.Exec(ctx, failQuery, d.ID, status, deliverErr.Error(), maxAttempts)
+				if err != nil {
+					return fmt.Errorf("pgx error: %w", err)
+				}
+				failed++
+				continue
+			}
+
+			_, err = tx.Exec(ctx, successQuery, d.ID, status)
+			if err != nil {
+				return fmt.Errorf("pgx error: %w", err)
+			}
+			delivered++
+		}
+		return nil
+	})
+	if txErr != nil {
+		return 0, 0, fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return delivered, failed, nil
+}
@@ -3,6 +3,7 @@ package stubs
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -12,6 +13,7 @@ import (
 type accountDaoStub struct {
 	store  []*models.Account
 	lastId int64
+	audits []*models.AccountAudit
 }
 
 func buildAccountDaoWithOneAccount() *accountDaoStub {
@@ -102,3 +104,51 @@ func (stub *accountDaoStub) getByAccountNumber(ctx context.Context, number strin
 func (stub *accountDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Account, error) {
 	return stub.store, nil
 }
+
+// AccountAuditStubList returns audit records of account merges and renames.
+func AccountAuditStubList(ctx context.Context) []*models.AccountAudit {
+	return getAccountDaoStub(ctx).audits
+}
+
+// UnscopedMerge only records the audit, records of other stubs are not moved.
+func (stub *accountDaoStub) UnscopedMerge(ctx context.Context, audit *models.AccountAudit) error {
+	if audit.SourceAccountID == audit.AccountID {
+		return dao.ErrConflict
+	}
+	source, err := stub.GetById(ctx, audit.SourceAccountID)
+	if err != nil {
+		return err
+	}
+	target, err := stub.GetById(ctx, audit.AccountID)
+	if err != nil {
+		return err
+	}
+
+	audit.Operation = models.AccountAuditMerge
+	audit.OldOrgID = source.OrgID
+	audit.NewOrgID = target.OrgID
+	stub.addAudit(audit)
+	return nil
+}
+
+func (stub *accountDaoStub) UnscopedRename(ctx context.Context, audit *models.AccountAudit) error {
+	acc, err := stub.GetById(ctx, audit.AccountID)
+	if err != nil {
+		return err
+	}
+	if _, err = stub.GetByOrgId(ctx, audit.NewOrgID); err == nil {
+		return dao.ErrConflict
+	}
+
+	audit.Operation = models.AccountAuditRename
+	audit.OldOrgID = acc.OrgID
+	acc.OrgID = audit.NewOrgID
+	stub.addAudit(audit)
+	return nil
+}
+
+func (stub *accountDaoStub) addAudit(audit *models.AccountAudit) {
+	audit.ID = int64(len(stub.audits)) + 1
+	audit.CreatedAt = time.Now()
+	stub.audits = append(stub.audits, audit)
+}
@@ -0,0 +1,41 @@
+package stubs
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+type accountSettingsDaoStub struct {
+	store map[int64]*models.AccountSettings
+}
+
+func init() {
+	dao.GetAccountSettingsDao = getAccountSettingsDao
+}
+
+func getAccountSettingsDao(ctx context.Context) dao.AccountSettingsDao {
+	return getAccountSettingsDaoStub(ctx)
+}
+
+func (stub *accountSettingsDaoStub) Get(ctx context.Context) (*models.AccountSettings, error) {
+	if settings, ok := stub.store[ctxAccountId(ctx)]; ok {
+		return settings, nil
+	}
+	return &models.AccountSettings{AccountID: ctxAccountId(ctx), AllowedProviders: []string{}, RequiredTags: []string{}}, nil
+}
+
+func (stub *accountSettingsDaoStub) Upsert(ctx context.Context, settings *models.AccountSettings) error {
+	settings.AccountID = ctxAccountId(ctx)
+	if settings.AllowedProviders == nil {
+		settings.AllowedProviders = []string{}
+	}
+	if settings.RequiredTags == nil {
+		settings.RequiredTags = []string{}
+	}
+	settings.UpdatedAt = time.Now()
+	stub.store[settings.AccountID] = settings
+	return nil
+}
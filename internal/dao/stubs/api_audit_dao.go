@@ -0,0 +1,67 @@
+package stubs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// apiAuditDaoStub is safe for concurrent use, calls are recorded from request goroutines.
+type apiAuditDaoStub struct {
+	mu     sync.Mutex
+	lastId int64
+	store  []*models.APIAudit
+}
+
+func init() {
+	dao.GetAPIAuditDao = getAPIAuditDao
+}
+
+func APIAuditStubCount(ctx context.Context) int {
+	aadao := getAPIAuditDaoStub(ctx)
+	aadao.mu.Lock()
+	defer aadao.mu.Unlock()
+	return len(aadao.store)
+}
+
+func getAPIAuditDao(ctx context.Context) dao.APIAuditDao {
+	return getAPIAuditDaoStub(ctx)
+}
+
+func (stub *apiAuditDaoStub) UnscopedCreate(ctx context.Context, audit *models.APIAudit) error {
+	if err := models.Validate(ctx, audit); err != nil {
+		return dao.ErrValidation
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	audit.ID = stub.lastId + 1
+	audit.CreatedAt = time.Now()
+	stub.store = append(stub.store, audit)
+	stub.lastId++
+	return nil
+}
+
+func (stub *apiAuditDaoStub) UnscopedListByAccount(_ context.Context, accountId int64, limit, offset int64) ([]*models.APIAudit, error) {
+	return stub.list(accountId), nil
+}
+
+func (stub *apiAuditDaoStub) UnscopedCountByAccount(_ context.Context, accountId int64) (int64, error) {
+	return int64(len(stub.list(accountId))), nil
+}
+
+func (stub *apiAuditDaoStub) list(accountId int64) []*models.APIAudit {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var result []*models.APIAudit
+	for i := len(stub.store) - 1; i >= 0; i-- {
+		if stub.store[i].AccountID == accountId {
+			result = append(result, stub.store[i])
+		}
+	}
+	return result
+}
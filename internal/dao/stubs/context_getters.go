@@ -14,12 +14,26 @@ const (
 	accountCtxKey     daoStubCtxKeyType = iota
 	pubkeyCtxKey      daoStubCtxKeyType = iota
 	reservationCtxKey daoStubCtxKeyType = iota
+	templateCtxKey    daoStubCtxKeyType = iota
+	failedJobCtxKey   daoStubCtxKeyType = iota
+	runningJobCtxKey  daoStubCtxKeyType = iota
+	jobAuditCtxKey    daoStubCtxKeyType = iota
+	outboxCtxKey      daoStubCtxKeyType = iota
+	apiAuditCtxKey    daoStubCtxKeyType = iota
+	settingsCtxKey    daoStubCtxKeyType = iota
+	webhookCtxKey     daoStubCtxKeyType = iota
+	sourceIdentityKey daoStubCtxKeyType = iota
+	instanceActionKey daoStubCtxKeyType = iota
 )
 
 func ctxAccountId(ctx context.Context) int64 {
 	return identity.AccountId(ctx)
 }
 
+func ctxOwner(ctx context.Context) string {
+	return identity.Owner(ctx)
+}
+
 func WithPubkeyDao(parent context.Context) context.Context {
 	if parent.Value(pubkeyCtxKey) != nil {
 		panic(dao.ErrStubContextAlreadySet)
@@ -58,6 +72,132 @@ func getReservationDaoStub(ctx context.Context) *reservationDaoStub {
 	return resDao
 }
 
+func WithReservationTemplateDao(parent context.Context) context.Context {
+	if parent.Value(templateCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, templateCtxKey, &reservationTemplateDaoStub{lastId: 0, store: []*models.ReservationTemplate{}})
+	return ctx
+}
+
+func getReservationTemplateDaoStub(ctx context.Context) *reservationTemplateDaoStub {
+	var ok bool
+	var rtdao *reservationTemplateDaoStub
+	if rtdao, ok = ctx.Value(templateCtxKey).(*reservationTemplateDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return rtdao
+}
+
+func WithFailedJobDao(parent context.Context) context.Context {
+	if parent.Value(failedJobCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, failedJobCtxKey, &failedJobDaoStub{lastId: 0, store: []*models.FailedJob{}})
+	return ctx
+}
+
+func getFailedJobDaoStub(ctx context.Context) *failedJobDaoStub {
+	var ok bool
+	var fjdao *failedJobDaoStub
+	if fjdao, ok = ctx.Value(failedJobCtxKey).(*failedJobDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return fjdao
+}
+
+func WithRunningJobDao(parent context.Context) context.Context {
+	if parent.Value(runningJobCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, runningJobCtxKey, &runningJobDaoStub{store: map[string]*models.RunningJob{}})
+	return ctx
+}
+
+func getRunningJobDaoStub(ctx context.Context) *runningJobDaoStub {
+	var ok bool
+	var rjdao *runningJobDaoStub
+	if rjdao, ok = ctx.Value(runningJobCtxKey).(*runningJobDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return rjdao
+}
+
+func WithJobAuditDao(parent context.Context) context.Context {
+	if parent.Value(jobAuditCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, jobAuditCtxKey, &jobAuditDaoStub{store: []*models.JobAudit{}})
+	return ctx
+}
+
+func getJobAuditDaoStub(ctx context.Context) *jobAuditDaoStub {
+	var ok bool
+	var jadao *jobAuditDaoStub
+	if jadao, ok = ctx.Value(jobAuditCtxKey).(*jobAuditDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return jadao
+}
+
+func WithOutboxDao(parent context.Context) context.Context {
+	if parent.Value(outboxCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, outboxCtxKey, &outboxDaoStub{store: []*models.OutboxMessage{}})
+	return ctx
+}
+
+func getOutboxDaoStub(ctx context.Context) *outboxDaoStub {
+	var ok bool
+	var odao *outboxDaoStub
+	if odao, ok = ctx.Value(outboxCtxKey).(*outboxDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return odao
+}
+
+func WithAPIAuditDao(parent context.Context) context.Context {
+	if parent.Value(apiAuditCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, apiAuditCtxKey, &apiAuditDaoStub{store: []*models.APIAudit{}})
+	return ctx
+}
+
+func getAPIAuditDaoStub(ctx context.Context) *apiAuditDaoStub {
+	var ok bool
+	var aadao *apiAuditDaoStub
+	if aadao, ok = ctx.Value(apiAuditCtxKey).(*apiAuditDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return aadao
+}
+
+func WithAccountSettingsDao(parent context.Context) context.Context {
+	if parent.Value(settingsCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, settingsCtxKey, &accountSettingsDaoStub{store: map[int64]*models.AccountSettings{}})
+	return ctx
+}
+
+func getAccountSettingsDaoStub(ctx context.Context) *accountSettingsDaoStub {
+	var ok bool
+	var asdao *accountSettingsDaoStub
+	if asdao, ok = ctx.Value(settingsCtxKey).(*accountSettingsDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return asdao
+}
+
 func WithAccountDaoOne(parent context.Context) context.Context {
 	if parent.Value(accountCtxKey) != nil {
 		panic(dao.ErrStubContextAlreadySet)
@@ -84,3 +224,57 @@ func getAccountDaoStub(ctx context.Context) *accountDaoStub {
 	}
 	return accdao
 }
+
+func WithWebhookDao(parent context.Context) context.Context {
+	if parent.Value(webhookCtxKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, webhookCtxKey, &webhookDaoStub{})
+	return ctx
+}
+
+func getWebhookDaoStub(ctx context.Context) *webhookDaoStub {
+	var ok bool
+	var whdao *webhookDaoStub
+	if whdao, ok = ctx.Value(webhookCtxKey).(*webhookDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return whdao
+}
+
+func WithSourceIdentityDao(parent context.Context) context.Context {
+	if parent.Value(sourceIdentityKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, sourceIdentityKey, &sourceIdentityDaoStub{store: map[sourceIdentityStubKey]*models.SourceIdentity{}})
+	return ctx
+}
+
+func getSourceIdentityDaoStub(ctx context.Context) *sourceIdentityDaoStub {
+	var ok bool
+	var sidao *sourceIdentityDaoStub
+	if sidao, ok = ctx.Value(sourceIdentityKey).(*sourceIdentityDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return sidao
+}
+
+func WithInstanceActionDao(parent context.Context) context.Context {
+	if parent.Value(instanceActionKey) != nil {
+		panic(dao.ErrStubContextAlreadySet)
+	}
+
+	ctx := context.WithValue(parent, instanceActionKey, &instanceActionDaoStub{store: []*models.InstanceAction{}})
+	return ctx
+}
+
+func getInstanceActionDaoStub(ctx context.Context) *instanceActionDaoStub {
+	var ok bool
+	var iadao *instanceActionDaoStub
+	if iadao, ok = ctx.Value(instanceActionKey).(*instanceActionDaoStub); !ok {
+		panic(dao.ErrStubMissingContext)
+	}
+	return iadao
+}
@@ -0,0 +1,71 @@
+package stubs
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+type failedJobDaoStub struct {
+	lastId int64
+	store  []*models.FailedJob
+}
+
+func init() {
+	dao.GetFailedJobDao = getFailedJobDao
+}
+
+func FailedJobStubCount(ctx context.Context) int {
+	fjdao := getFailedJobDaoStub(ctx)
+	return len(fjdao.store)
+}
+
+func getFailedJobDao(ctx context.Context) dao.FailedJobDao {
+	return getFailedJobDaoStub(ctx)
+}
+
+func (stub *failedJobDaoStub) UnscopedCreate(ctx context.Context, job *models.FailedJob) error {
+	if err := models.Validate(ctx, job); err != nil {
+		return dao.ErrValidation
+	}
+
+	job.ID = stub.lastId + 1
+	job.FailedAt = time.Now()
+	stub.store = append(stub.store, job)
+	stub.lastId++
+	return nil
+}
+
+func (stub *failedJobDaoStub) UnscopedGetById(_ context.Context, id int64) (*models.FailedJob, error) {
+	for _, j := range stub.store {
+		if j.ID == id {
+			return j, nil
+		}
+	}
+	return nil, dao.ErrNoRows
+}
+
+func (stub *failedJobDaoStub) UnscopedList(_ context.Context, limit, offset int64) ([]*models.FailedJob, error) {
+	var result []*models.FailedJob
+	for i := len(stub.store) - 1; i >= 0; i-- {
+		result = append(result, stub.store[i])
+	}
+	return result, nil
+}
+
+func (stub *failedJobDaoStub) UnscopedCount(_ context.Context) (int64, error) {
+	return int64(len(stub.store)), nil
+}
+
+func (stub *failedJobDaoStub) UnscopedMarkReplayed(_ context.Context, id int64) error {
+	for _, j := range stub.store {
+		if j.ID == id {
+			now := time.Now()
+			j.ReplayedAt = &now
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
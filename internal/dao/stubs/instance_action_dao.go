@@ -0,0 +1,99 @@
+package stubs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// instanceActionDaoStub is safe for concurrent use, actions are updated from worker goroutines.
+type instanceActionDaoStub struct {
+	mu     sync.Mutex
+	lastId int64
+	store  []*models.InstanceAction
+}
+
+func init() {
+	dao.GetInstanceActionDao = getInstanceActionDao
+}
+
+func getInstanceActionDao(ctx context.Context) dao.InstanceActionDao {
+	return getInstanceActionDaoStub(ctx)
+}
+
+func (stub *instanceActionDaoStub) Create(ctx context.Context, action *models.InstanceAction) error {
+	action.AccountID = ctxAccountId(ctx)
+	if err := models.Validate(ctx, action); err != nil {
+		return dao.ErrValidation
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	for _, a := range stub.store {
+		if a.ReservationID == action.ReservationID && a.InstanceID == action.InstanceID &&
+			(a.Status == models.InstanceActionPending || a.Status == models.InstanceActionRunning) {
+			return dao.ErrAffectedMismatch
+		}
+	}
+
+	stub.lastId++
+	action.ID = stub.lastId
+	action.Status = models.InstanceActionPending
+	action.CreatedAt = time.Now()
+	stub.store = append(stub.store, action)
+	return nil
+}
+
+func (stub *instanceActionDaoStub) GetById(ctx context.Context, id int64) (*models.InstanceAction, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	for _, a := range stub.store {
+		if a.AccountID == ctxAccountId(ctx) && a.ID == id {
+			return a, nil
+		}
+	}
+	return nil, dao.ErrNoRows
+}
+
+func (stub *instanceActionDaoStub) ListByReservation(ctx context.Context, reservationId, limit, offset int64) ([]*models.InstanceAction, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var result []*models.InstanceAction
+	for _, a := range stub.store {
+		if a.AccountID == ctxAccountId(ctx) && a.ReservationID == reservationId {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, nil
+}
+
+func (stub *instanceActionDaoStub) CountByReservation(ctx context.Context, reservationId int64) (int64, error) {
+	list, err := stub.ListByReservation(ctx, reservationId, 0, 0)
+	return int64(len(list)), err
+}
+
+func (stub *instanceActionDaoStub) UnscopedUpdateStatus(_ context.Context, id int64, status models.InstanceActionStatus, errorString string) error {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	for _, a := range stub.store {
+		if a.ID == id {
+			a.Status = status
+			a.Error = errorString
+			a.FinishedAt = nil
+			if status == models.InstanceActionSuccess || status == models.InstanceActionFailed {
+				now := time.Now()
+				a.FinishedAt = &now
+			}
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
@@ -0,0 +1,73 @@
+package stubs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// jobAuditDaoStub is safe for concurrent use, attempts are recorded from worker goroutines.
+type jobAuditDaoStub struct {
+	mu     sync.Mutex
+	lastId int64
+	store  []*models.JobAudit
+}
+
+func init() {
+	dao.GetJobAuditDao = getJobAuditDao
+}
+
+func JobAuditStubCount(ctx context.Context) int {
+	jadao := getJobAuditDaoStub(ctx)
+	jadao.mu.Lock()
+	defer jadao.mu.Unlock()
+	return len(jadao.store)
+}
+
+func getJobAuditDao(ctx context.Context) dao.JobAuditDao {
+	return getJobAuditDaoStub(ctx)
+}
+
+func (stub *jobAuditDaoStub) UnscopedCreate(ctx context.Context, audit *models.JobAudit) error {
+	if err := models.Validate(ctx, audit); err != nil {
+		return dao.ErrValidation
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	audit.ID = stub.lastId + 1
+	stub.store = append(stub.store, audit)
+	stub.lastId++
+	return nil
+}
+
+func (stub *jobAuditDaoStub) UnscopedListByReservation(_ context.Context, reservationId int64, limit, offset int64) ([]*models.JobAudit, error) {
+	return stub.list(func(a *models.JobAudit) bool { return a.ReservationID == reservationId }), nil
+}
+
+func (stub *jobAuditDaoStub) UnscopedListByAccount(_ context.Context, accountId int64, limit, offset int64) ([]*models.JobAudit, error) {
+	return stub.list(func(a *models.JobAudit) bool { return a.AccountID == accountId }), nil
+}
+
+func (stub *jobAuditDaoStub) UnscopedCountByReservation(_ context.Context, reservationId int64) (int64, error) {
+	return int64(len(stub.list(func(a *models.JobAudit) bool { return a.ReservationID == reservationId }))), nil
+}
+
+func (stub *jobAuditDaoStub) UnscopedCountByAccount(_ context.Context, accountId int64) (int64, error) {
+	return int64(len(stub.list(func(a *models.JobAudit) bool { return a.AccountID == accountId }))), nil
+}
+
+func (stub *jobAuditDaoStub) list(match func(a *models.JobAudit) bool) []*models.JobAudit {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var result []*models.JobAudit
+	for i := len(stub.store) - 1; i >= 0; i-- {
+		if match(stub.store[i]) {
+			result = append(result, stub.store[i])
+		}
+	}
+	return result
+}
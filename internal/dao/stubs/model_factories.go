@@ -11,6 +11,11 @@ func AddPubkey(ctx context.Context, pubkey *models.Pubkey) error {
 	return pubkeyDao.Create(ctx, pubkey)
 }
 
+func AddReservationTemplate(ctx context.Context, template *models.ReservationTemplate) error {
+	templateDao := getReservationTemplateDaoStub(ctx)
+	return templateDao.Create(ctx, template)
+}
+
 func AddAWSReservation(ctx context.Context, reservation *models.AWSReservation) error {
 	reservationDao := getReservationDaoStub(ctx)
 	return reservationDao.CreateAWS(ctx, reservation)
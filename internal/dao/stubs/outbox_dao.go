@@ -0,0 +1,82 @@
+package stubs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// outboxDaoStub is safe for concurrent use, messages are enqueued from worker goroutines.
+type outboxDaoStub struct {
+	mu     sync.Mutex
+	lastId int64
+	store  []*models.OutboxMessage
+}
+
+func init() {
+	dao.GetOutboxDao = getOutboxDao
+}
+
+func OutboxStubCount(ctx context.Context) int {
+	odao := getOutboxDaoStub(ctx)
+	odao.mu.Lock()
+	defer odao.mu.Unlock()
+	return len(odao.store)
+}
+
+func getOutboxDao(ctx context.Context) dao.OutboxDao {
+	return getOutboxDaoStub(ctx)
+}
+
+func (stub *outboxDaoStub) UnscopedEnqueue(ctx context.Context, messages ...*models.OutboxMessage) error {
+	for _, m := range messages {
+		if err := models.Validate(ctx, m); err != nil {
+			return dao.ErrValidation
+		}
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	for _, m := range messages {
+		stub.lastId++
+		m.ID = stub.lastId
+		m.CreatedAt = time.Now()
+		m.NextAttemptAt = m.CreatedAt
+		stub.store = append(stub.store, m)
+	}
+	return nil
+}
+
+func (stub *outboxDaoStub) UnscopedRelay(_ context.Context, limit int64, publish func(*models.OutboxMessage) error) (int, int, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var published, failed int
+	remaining := make([]*models.OutboxMessage, 0, len(stub.store))
+	for _, m := range stub.store {
+		if int64(published+failed) >= limit || m.NextAttemptAt.After(time.Now()) {
+			remaining = append(remaining, m)
+			continue
+		}
+		if err := publish(m); err != nil {
+			m.Attempts++
+			m.LastError = err.Error()
+			m.NextAttemptAt = time.Now().Add(time.Second)
+			remaining = append(remaining, m)
+			failed++
+			continue
+		}
+		published++
+	}
+	stub.store = remaining
+	return published, failed, nil
+}
+
+func (stub *outboxDaoStub) UnscopedCount(_ context.Context) (int64, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return int64(len(stub.store)), nil
+}
@@ -2,6 +2,7 @@ package stubs
 
 import (
 	"context"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -17,9 +18,16 @@ func init() {
 	dao.GetPubkeyDao = getPubkeyDao
 }
 
+// PubkeyStubCount returns number of pubkeys which are not deleted
 func PubkeyStubCount(ctx context.Context) int {
 	pkdao := getPubkeyDaoStub(ctx)
-	return len(pkdao.store)
+	count := 0
+	for _, pk := range pkdao.store {
+		if pk.DeletedAt == nil {
+			count++
+		}
+	}
+	return count
 }
 
 func getPubkeyDao(ctx context.Context) dao.PubkeyDao {
@@ -33,6 +41,9 @@ func (stub *pubkeyDaoStub) Create(ctx context.Context, pubkey *models.Pubkey) er
 	if pubkey.AccountID != ctxAccountId(ctx) {
 		return dao.ErrWrongAccount
 	}
+	if pubkey.Owner == "" {
+		pubkey.Owner = ctxOwner(ctx)
+	}
 	if err := models.Validate(ctx, pubkey); err != nil {
 		return dao.ErrValidation
 	}
@@ -55,7 +66,7 @@ func (stub *pubkeyDaoStub) Update(ctx context.Context, pubkey *models.Pubkey) er
 	}
 
 	for idx, p := range stub.store {
-		if p.ID == pubkey.ID {
+		if p.ID == pubkey.ID && p.DeletedAt == nil && p.ModifiableBy(ctxOwner(ctx)) {
 			stub.store[idx] = pubkey
 			return nil
 		}
@@ -65,7 +76,7 @@ func (stub *pubkeyDaoStub) Update(ctx context.Context, pubkey *models.Pubkey) er
 
 func (stub *pubkeyDaoStub) GetById(ctx context.Context, id int64) (*models.Pubkey, error) {
 	for _, pk := range stub.store {
-		if pk.AccountID == ctxAccountId(ctx) && pk.ID == id {
+		if pk.AccountID == ctxAccountId(ctx) && pk.ID == id && pk.DeletedAt == nil && pk.VisibleTo(ctxOwner(ctx)) {
 			return pk, nil
 		}
 	}
@@ -75,23 +86,57 @@ func (stub *pubkeyDaoStub) GetById(ctx context.Context, id int64) (*models.Pubke
 func (stub *pubkeyDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error) {
 	var filtered []*models.Pubkey
 	for _, pk := range stub.store {
-		if pk.AccountID == ctxAccountId(ctx) {
+		if pk.AccountID == ctxAccountId(ctx) && pk.DeletedAt == nil && pk.VisibleTo(ctxOwner(ctx)) {
 			filtered = append(filtered, pk)
 		}
 	}
 	return filtered, nil
 }
 
+func (stub *pubkeyDaoStub) Count(ctx context.Context) (int64, error) {
+	pubkeys, _ := stub.List(ctx, 0, 0)
+	return int64(len(pubkeys)), nil
+}
+
 func (stub *pubkeyDaoStub) Delete(ctx context.Context, id int64) error {
-	for idx, p := range stub.store {
-		if p.AccountID == ctxAccountId(ctx) && p.ID == id {
-			stub.store = append(stub.store[:idx], stub.store[idx+1:]...)
+	for _, p := range stub.store {
+		if p.AccountID == ctxAccountId(ctx) && p.ID == id && p.DeletedAt == nil && p.ModifiableBy(ctxOwner(ctx)) {
+			now := time.Now()
+			p.DeletedAt = &now
+
+			var resources []*models.PubkeyResource
+			for _, pkr := range stub.resourceStore {
+				if pkr.PubkeyID != id {
+					resources = append(resources, pkr)
+				}
+			}
+			stub.resourceStore = resources
 			return nil
 		}
 	}
 	return nil
 }
 
+func (stub *pubkeyDaoStub) Restore(ctx context.Context, id int64) error {
+	for _, p := range stub.store {
+		if p.AccountID == ctxAccountId(ctx) && p.ID == id && p.DeletedAt != nil && p.ModifiableBy(ctxOwner(ctx)) {
+			p.DeletedAt = nil
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
+func (stub *pubkeyDaoStub) UpdateShared(ctx context.Context, id int64, shared bool) error {
+	for _, p := range stub.store {
+		if p.AccountID == ctxAccountId(ctx) && p.ID == id && p.DeletedAt == nil && p.ModifiableBy(ctxOwner(ctx)) {
+			p.Shared = shared
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
 func (stub *pubkeyDaoStub) UnscopedGetResourceBySourceAndRegion(ctx context.Context, pubkeyId int64, sourceId string, region string) (*models.PubkeyResource, error) {
 	for _, pkr := range stub.resourceStore {
 		if pkr.PubkeyID == pubkeyId && pkr.SourceID == sourceId && pkr.Region == region {
@@ -108,6 +153,12 @@ func (stub *pubkeyDaoStub) UnscopedCreateResource(ctx context.Context, pkr *mode
 }
 
 func (stub *pubkeyDaoStub) UnscopedDeleteResource(ctx context.Context, id int64) error {
+	for idx, pkr := range stub.resourceStore {
+		if pkr.ID == id {
+			stub.resourceStore = append(stub.resourceStore[:idx], stub.resourceStore[idx+1:]...)
+			return nil
+		}
+	}
 	return nil
 }
 
@@ -120,3 +171,46 @@ func (stub *pubkeyDaoStub) UnscopedListResourcesByPubkeyId(ctx context.Context,
 	}
 	return result, nil
 }
+
+func (stub *pubkeyDaoStub) UnscopedListExpiring(ctx context.Context, before time.Time) ([]*models.Pubkey, error) {
+	var result []*models.Pubkey
+	for _, pk := range stub.store {
+		if pk.ExpiresAt != nil && !pk.ExpiresAt.After(before) && pk.ExpiryNotifiedAt == nil && pk.DeletedAt == nil {
+			result = append(result, pk)
+		}
+	}
+	return result, nil
+}
+
+func (stub *pubkeyDaoStub) UnscopedListExpiringBetween(ctx context.Context, after, before time.Time) ([]*models.Pubkey, error) {
+	var result []*models.Pubkey
+	for _, pk := range stub.store {
+		if pk.ExpiresAt != nil && pk.ExpiresAt.After(after) && !pk.ExpiresAt.After(before) && pk.DeletedAt == nil {
+			result = append(result, pk)
+		}
+	}
+	return result, nil
+}
+
+func (stub *pubkeyDaoStub) UnscopedMarkExpiryNotified(ctx context.Context, id int64) error {
+	for _, pk := range stub.store {
+		if pk.ID == id {
+			now := time.Now()
+			pk.ExpiryNotifiedAt = &now
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
+func (stub *pubkeyDaoStub) UnscopedPurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var kept []*models.Pubkey
+	for _, pk := range stub.store {
+		if pk.DeletedAt == nil || !pk.DeletedAt.Before(before) {
+			kept = append(kept, pk)
+		}
+	}
+	purged := int64(len(stub.store) - len(kept))
+	stub.store = kept
+	return purged, nil
+}
@@ -2,7 +2,12 @@ package stubs
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
@@ -14,6 +19,7 @@ type reservationDaoStub struct {
 	storeAzure []*models.AzureReservation
 	storeGCP   []*models.GCPReservation
 	instances  map[int64][]*models.ReservationInstance
+	archived   []*models.ArchivedReservation
 }
 
 func init() {
@@ -69,16 +75,21 @@ func (stub *reservationDaoStub) CreateInstance(ctx context.Context, resInstance
 
 func (stub *reservationDaoStub) GetById(ctx context.Context, id int64) (*models.Reservation, error) {
 	for _, awsReservation := range stub.storeAWS {
-		if awsReservation.AccountID == ctxAccountId(ctx) && awsReservation.ID == id {
+		if awsReservation.AccountID == ctxAccountId(ctx) && awsReservation.ID == id && awsReservation.DeletedAt == nil && awsReservation.VisibleTo(ctxOwner(ctx)) {
 			return &awsReservation.Reservation, nil
 		}
 	}
+	for _, azureReservation := range stub.storeAzure {
+		if azureReservation.AccountID == ctxAccountId(ctx) && azureReservation.ID == id && azureReservation.DeletedAt == nil && azureReservation.VisibleTo(ctxOwner(ctx)) {
+			return &azureReservation.Reservation, nil
+		}
+	}
 	return nil, dao.ErrNoRows
 }
 
 func (stub *reservationDaoStub) GetAWSById(ctx context.Context, id int64) (*models.AWSReservation, error) {
 	for _, awsReservation := range stub.storeAWS {
-		if awsReservation.AccountID == ctxAccountId(ctx) && awsReservation.ID == id {
+		if awsReservation.AccountID == ctxAccountId(ctx) && awsReservation.ID == id && awsReservation.DeletedAt == nil && awsReservation.VisibleTo(ctxOwner(ctx)) {
 			return awsReservation, nil
 		}
 	}
@@ -87,7 +98,7 @@ func (stub *reservationDaoStub) GetAWSById(ctx context.Context, id int64) (*mode
 
 func (stub *reservationDaoStub) GetAzureById(ctx context.Context, id int64) (*models.AzureReservation, error) {
 	for _, azureReservation := range stub.storeAzure {
-		if azureReservation.AccountID == ctxAccountId(ctx) && azureReservation.ID == id {
+		if azureReservation.AccountID == ctxAccountId(ctx) && azureReservation.ID == id && azureReservation.DeletedAt == nil && azureReservation.VisibleTo(ctxOwner(ctx)) {
 			return azureReservation, nil
 		}
 	}
@@ -96,7 +107,7 @@ func (stub *reservationDaoStub) GetAzureById(ctx context.Context, id int64) (*mo
 
 func (stub *reservationDaoStub) GetGCPById(ctx context.Context, id int64) (*models.GCPReservation, error) {
 	for _, gcpReservation := range stub.storeGCP {
-		if gcpReservation.AccountID == ctxAccountId(ctx) && gcpReservation.ID == id {
+		if gcpReservation.AccountID == ctxAccountId(ctx) && gcpReservation.ID == id && gcpReservation.DeletedAt == nil && gcpReservation.VisibleTo(ctxOwner(ctx)) {
 			return gcpReservation, nil
 		}
 	}
@@ -107,6 +118,160 @@ func (stub *reservationDaoStub) List(ctx context.Context, limit, offset int64) (
 	return nil, nil
 }
 
+func (stub *reservationDaoStub) Count(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// scopedList returns generic reservations of all providers of the account created since the time
+func (stub *reservationDaoStub) scopedList(ctx context.Context, since time.Time) []*models.Reservation {
+	var result []*models.Reservation
+	add := func(r *models.Reservation) {
+		if r.AccountID == ctxAccountId(ctx) && !r.CreatedAt.Before(since) && r.DeletedAt == nil {
+			result = append(result, r)
+		}
+	}
+	for _, res := range stub.storeAWS {
+		add(&res.Reservation)
+	}
+	for _, res := range stub.storeAzure {
+		add(&res.Reservation)
+	}
+	for _, res := range stub.storeGCP {
+		add(&res.Reservation)
+	}
+	return result
+}
+
+func stubReservationResult(r *models.Reservation) string {
+	switch {
+	case !r.Success.Valid:
+		return models.ReservationResultPending
+	case r.Success.Bool:
+		return models.ReservationResultSuccess
+	case r.Status == models.ReservationStatusCancelled:
+		return models.ReservationResultCancelled
+	default:
+		return models.ReservationResultFailure
+	}
+}
+
+func (stub *reservationDaoStub) StatsByProvider(ctx context.Context, since time.Time) ([]*models.ReservationProviderStat, error) {
+	var result []*models.ReservationProviderStat
+	stats := make(map[models.ProviderType]*models.ReservationProviderStat)
+	for _, r := range stub.scopedList(ctx, since) {
+		stat, ok := stats[r.Provider]
+		if !ok {
+			stat = &models.ReservationProviderStat{Provider: r.Provider}
+			stats[r.Provider] = stat
+			result = append(result, stat)
+		}
+		stat.Total++
+		stat.Instances += int64(len(stub.instances[r.ID]))
+		switch stubReservationResult(r) {
+		case models.ReservationResultSuccess:
+			stat.Success++
+		case models.ReservationResultFailure:
+			stat.Failure++
+		case models.ReservationResultCancelled:
+			stat.Cancelled++
+		case models.ReservationResultPending:
+			stat.Pending++
+		}
+	}
+	for _, stat := range result {
+		if finished := stat.Success + stat.Failure; finished > 0 {
+			stat.SuccessRate = float64(stat.Success) / float64(finished)
+		}
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) DailyStats(ctx context.Context, since time.Time) ([]*models.ReservationDailyStat, error) {
+	var result []*models.ReservationDailyStat
+	for _, r := range stub.scopedList(ctx, since) {
+		day := r.CreatedAt.Truncate(24 * time.Hour)
+		resultName := stubReservationResult(r)
+		found := false
+		for _, stat := range result {
+			if stat.Day.Equal(day) && stat.Provider == r.Provider && stat.Result == resultName {
+				stat.Count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, &models.ReservationDailyStat{Day: day, Provider: r.Provider, Result: resultName, Count: 1})
+		}
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) ListRunningInstanceTypes(ctx context.Context) ([]*models.ReservationInstanceTypeAmount, error) {
+	var result []*models.ReservationInstanceTypeAmount
+	// instance actions are not tracked, all launched instances of finished reservations are live
+	add := func(r *models.Reservation, instanceType string, amount int64) {
+		if r.FinishedAt.Valid {
+			amount = int64(len(stub.instances[r.ID]))
+		}
+		if r.AccountID == ctxAccountId(ctx) && r.DeletedAt == nil && (!r.Success.Valid || r.Success.Bool) && amount > 0 {
+			result = append(result, &models.ReservationInstanceTypeAmount{Provider: r.Provider, InstanceType: instanceType, Amount: amount})
+		}
+	}
+	for _, res := range stub.storeAWS {
+		if res.Detail != nil {
+			instanceType := res.Detail.InstanceType
+			if instanceType == "" && len(res.Detail.InstanceTypes) > 0 {
+				instanceType = res.Detail.InstanceTypes[0]
+			}
+			add(&res.Reservation, instanceType, int64(res.Detail.Amount))
+		}
+	}
+	for _, res := range stub.storeAzure {
+		if res.Detail != nil {
+			add(&res.Reservation, res.Detail.InstanceSize, res.Detail.Amount)
+		}
+	}
+	for _, res := range stub.storeGCP {
+		if res.Detail != nil {
+			add(&res.Reservation, res.Detail.MachineType, res.Detail.Amount)
+		}
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) ListByPubkeyId(ctx context.Context, pubkeyId int64) ([]*models.PubkeyReservation, error) {
+	var result []*models.PubkeyReservation
+	add := func(r *models.Reservation, pkId int64, sourceId, region string) {
+		if r.AccountID == ctxAccountId(ctx) && pkId == pubkeyId && r.DeletedAt == nil {
+			result = append(result, &models.PubkeyReservation{
+				ReservationID: r.ID,
+				Provider:      r.Provider,
+				SourceID:      sourceId,
+				Region:        region,
+				Status:        r.Status,
+				CreatedAt:     r.CreatedAt,
+				Success:       r.Success,
+			})
+		}
+	}
+	for _, res := range stub.storeAWS {
+		if res.Detail != nil {
+			add(&res.Reservation, res.PubkeyID, res.SourceID, res.Detail.Region)
+		}
+	}
+	for _, res := range stub.storeAzure {
+		if res.Detail != nil {
+			add(&res.Reservation, res.PubkeyID, res.SourceID, res.Detail.Location)
+		}
+	}
+	for _, res := range stub.storeGCP {
+		if res.Detail != nil {
+			add(&res.Reservation, res.PubkeyID, res.SourceID, res.Detail.Zone)
+		}
+	}
+	return result, nil
+}
+
 func (stub *reservationDaoStub) ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
 	return stub.instances[reservationId], nil
 }
@@ -124,6 +289,15 @@ func (stub *reservationDaoStub) UnscopedUpdateAWSDetail(ctx context.Context, id
 	return nil
 }
 
+func (stub *reservationDaoStub) UnscopedUpdateAzureDetail(ctx context.Context, id int64, azureDetail *models.AzureDetail) error {
+	res, err := stub.GetAzureById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("stubbed lookup of Azure reservation failed: %w", err)
+	}
+	res.Detail = azureDetail
+	return nil
+}
+
 func (stub *reservationDaoStub) UpdateReservationIDForAWS(ctx context.Context, id int64, awsReservationId string) error {
 	return nil
 }
@@ -132,14 +306,287 @@ func (stub *reservationDaoStub) UpdateOperationNameForGCP(ctx context.Context, i
 	return nil
 }
 
-func (stub *reservationDaoStub) FinishWithSuccess(ctx context.Context, id int64) error {
+func (stub *reservationDaoStub) FinishWithSuccess(ctx context.Context, id int64, outbox ...*models.OutboxMessage) error {
+	if len(outbox) > 0 {
+		return dao.GetOutboxDao(ctx).UnscopedEnqueue(ctx, outbox...)
+	}
+	return nil
+}
+
+func (stub *reservationDaoStub) FinishWithError(ctx context.Context, id int64, errorString string, outbox ...*models.OutboxMessage) error {
+	if res := stub.unscopedFind(id); res != nil {
+		res.Error = errorString
+		res.Success = sql.NullBool{Bool: false, Valid: true}
+		res.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	if len(outbox) > 0 {
+		return dao.GetOutboxDao(ctx).UnscopedEnqueue(ctx, outbox...)
+	}
+	return nil
+}
+
+// unscopedFind returns generic reservation of any provider and account
+func (stub *reservationDaoStub) unscopedFind(id int64) *models.Reservation {
+	for _, res := range stub.storeAWS {
+		if res.ID == id {
+			return &res.Reservation
+		}
+	}
+	for _, res := range stub.storeAzure {
+		if res.ID == id {
+			return &res.Reservation
+		}
+	}
+	for _, res := range stub.storeGCP {
+		if res.ID == id {
+			return &res.Reservation
+		}
+	}
+	return nil
+}
+
+func (stub *reservationDaoStub) RequestCancel(ctx context.Context, id int64) error {
+	res := stub.unscopedFind(id)
+	if res == nil || res.AccountID != ctxAccountId(ctx) || !res.ModifiableBy(ctxOwner(ctx)) || res.FinishedAt.Valid {
+		return dao.ErrAffectedMismatch
+	}
+	res.CancelRequested = true
+	return nil
+}
+
+func (stub *reservationDaoStub) UnscopedIsCancelRequested(ctx context.Context, id int64) (bool, error) {
+	res := stub.unscopedFind(id)
+	if res == nil {
+		return false, dao.ErrNoRows
+	}
+	return res.CancelRequested, nil
+}
+
+func (stub *reservationDaoStub) FinishWithCancel(ctx context.Context, id int64, errorString string) error {
+	res := stub.unscopedFind(id)
+	if res == nil {
+		return dao.ErrAffectedMismatch
+	}
+	res.Status = models.ReservationStatusCancelled
+	res.Error = errorString
+	res.Success = sql.NullBool{Bool: false, Valid: true}
+	res.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (stub *reservationDaoStub) UnscopedUpdateSSHReady(ctx context.Context, id int64, ready bool) error {
+	res := stub.unscopedFind(id)
+	if res == nil {
+		return dao.ErrAffectedMismatch
+	}
+	res.SSHReady = sql.NullBool{Bool: ready, Valid: true}
+	return nil
+}
+
+func (stub *reservationDaoStub) SoftDelete(ctx context.Context, id int64) error {
+	res := stub.unscopedFind(id)
+	if res == nil || res.AccountID != ctxAccountId(ctx) || !res.ModifiableBy(ctxOwner(ctx)) || !res.FinishedAt.Valid || res.DeletedAt != nil {
+		return dao.ErrAffectedMismatch
+	}
+	now := time.Now()
+	res.DeletedAt = &now
+	return nil
+}
+
+func (stub *reservationDaoStub) Restore(ctx context.Context, id int64) error {
+	res := stub.unscopedFind(id)
+	if res == nil || res.AccountID != ctxAccountId(ctx) || !res.ModifiableBy(ctxOwner(ctx)) || res.DeletedAt == nil {
+		return dao.ErrAffectedMismatch
+	}
+	res.DeletedAt = nil
 	return nil
 }
 
-func (stub *reservationDaoStub) FinishWithError(ctx context.Context, id int64, errorString string) error {
+func (stub *reservationDaoStub) UpdateShared(ctx context.Context, id int64, shared bool) error {
+	res := stub.unscopedFind(id)
+	if res == nil || res.AccountID != ctxAccountId(ctx) || !res.ModifiableBy(ctxOwner(ctx)) || res.DeletedAt != nil {
+		return dao.ErrAffectedMismatch
+	}
+	res.Shared = shared
 	return nil
 }
 
+func (stub *reservationDaoStub) UnscopedGetById(ctx context.Context, id int64) (*models.Reservation, error) {
+	res := stub.unscopedFind(id)
+	if res == nil {
+		return nil, dao.ErrNoRows
+	}
+	return res, nil
+}
+
+func (stub *reservationDaoStub) UnscopedListByAccount(ctx context.Context, accountId, limit, offset int64) ([]*models.Reservation, error) {
+	var result []*models.Reservation
+	add := func(r *models.Reservation) {
+		if r.AccountID == accountId {
+			result = append(result, r)
+		}
+	}
+	for _, res := range stub.storeAWS {
+		add(&res.Reservation)
+	}
+	for _, res := range stub.storeAzure {
+		add(&res.Reservation)
+	}
+	for _, res := range stub.storeGCP {
+		add(&res.Reservation)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	if offset >= int64(len(result)) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if limit < int64(len(result)) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) UnscopedCountByAccount(ctx context.Context, accountId int64) (int64, error) {
+	result, _ := stub.UnscopedListByAccount(ctx, accountId, math.MaxInt64, 0)
+	return int64(len(result)), nil
+}
+
+func (stub *reservationDaoStub) UnscopedListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
+	return stub.instances[reservationId], nil
+}
+
+func (stub *reservationDaoStub) UnscopedListDeletedBetween(ctx context.Context, after, before time.Time) ([]*models.Reservation, error) {
+	var result []*models.Reservation
+	deleted := func(r *models.Reservation) {
+		if r.DeletedAt != nil && r.DeletedAt.After(after) && !r.DeletedAt.After(before) {
+			result = append(result, r)
+		}
+	}
+	for _, res := range stub.storeAWS {
+		deleted(&res.Reservation)
+	}
+	for _, res := range stub.storeAzure {
+		deleted(&res.Reservation)
+	}
+	for _, res := range stub.storeGCP {
+		deleted(&res.Reservation)
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) UnscopedPurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	purge := func(r *models.Reservation) bool {
+		if r.DeletedAt != nil && r.DeletedAt.Before(before) {
+			delete(stub.instances, r.ID)
+			purged++
+			return true
+		}
+		return false
+	}
+
+	var keptAWS []*models.AWSReservation
+	for _, res := range stub.storeAWS {
+		if !purge(&res.Reservation) {
+			keptAWS = append(keptAWS, res)
+		}
+	}
+	var keptAzure []*models.AzureReservation
+	for _, res := range stub.storeAzure {
+		if !purge(&res.Reservation) {
+			keptAzure = append(keptAzure, res)
+		}
+	}
+	var keptGCP []*models.GCPReservation
+	for _, res := range stub.storeGCP {
+		if !purge(&res.Reservation) {
+			keptGCP = append(keptGCP, res)
+		}
+	}
+	stub.storeAWS, stub.storeAzure, stub.storeGCP = keptAWS, keptAzure, keptGCP
+	return purged, nil
+}
+
+func (stub *reservationDaoStub) UnscopedArchive(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	var archived int64
+	archive := func(r *models.Reservation, provider any) bool {
+		if archived >= limit || r.DeletedAt != nil || !r.FinishedAt.Valid || !r.FinishedAt.Time.Before(before) {
+			return false
+		}
+		if r.Success.Bool && len(stub.instances[r.ID]) > 0 {
+			return false
+		}
+		detail, _ := json.Marshal(provider)
+		instances, _ := json.Marshal(stub.instances[r.ID])
+		stub.archived = append(stub.archived, &models.ArchivedReservation{
+			Reservation: *r,
+			Detail:      detail,
+			Instances:   instances,
+			ArchivedAt:  time.Now(),
+		})
+		delete(stub.instances, r.ID)
+		archived++
+		return true
+	}
+
+	var keptAWS []*models.AWSReservation
+	for _, res := range stub.storeAWS {
+		if !archive(&res.Reservation, res) {
+			keptAWS = append(keptAWS, res)
+		}
+	}
+	var keptAzure []*models.AzureReservation
+	for _, res := range stub.storeAzure {
+		if !archive(&res.Reservation, res) {
+			keptAzure = append(keptAzure, res)
+		}
+	}
+	var keptGCP []*models.GCPReservation
+	for _, res := range stub.storeGCP {
+		if !archive(&res.Reservation, res) {
+			keptGCP = append(keptGCP, res)
+		}
+	}
+	stub.storeAWS, stub.storeAzure, stub.storeGCP = keptAWS, keptAzure, keptGCP
+	return archived, nil
+}
+
+func (stub *reservationDaoStub) GetArchivedById(ctx context.Context, id int64) (*models.ArchivedReservation, error) {
+	for _, res := range stub.archived {
+		if res.ID == id && res.AccountID == ctxAccountId(ctx) && res.VisibleTo(ctxOwner(ctx)) {
+			return res, nil
+		}
+	}
+	return nil, dao.ErrNoRows
+}
+
+func (stub *reservationDaoStub) ListArchived(ctx context.Context, limit, offset int64) ([]*models.ArchivedReservation, error) {
+	var result []*models.ArchivedReservation
+	for _, res := range stub.archived {
+		if res.AccountID == ctxAccountId(ctx) && res.VisibleTo(ctxOwner(ctx)) {
+			result = append(result, res)
+		}
+	}
+	if offset >= int64(len(result)) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if limit < int64(len(result)) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) CountArchived(ctx context.Context) (int64, error) {
+	var count int64
+	for _, res := range stub.archived {
+		if res.AccountID == ctxAccountId(ctx) && res.VisibleTo(ctxOwner(ctx)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (stub *reservationDaoStub) Delete(ctx context.Context, id int64) error {
 	return nil
 }
@@ -0,0 +1,101 @@
+package stubs
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+type reservationTemplateDaoStub struct {
+	lastId int64
+	store  []*models.ReservationTemplate
+}
+
+func init() {
+	dao.GetReservationTemplateDao = getReservationTemplateDao
+}
+
+func ReservationTemplateStubCount(ctx context.Context) int {
+	rtdao := getReservationTemplateDaoStub(ctx)
+	return len(rtdao.store)
+}
+
+func getReservationTemplateDao(ctx context.Context) dao.ReservationTemplateDao {
+	return getReservationTemplateDaoStub(ctx)
+}
+
+func (stub *reservationTemplateDaoStub) Create(ctx context.Context, template *models.ReservationTemplate) error {
+	if template.AccountID == 0 {
+		template.AccountID = ctxAccountId(ctx)
+	}
+	if template.AccountID != ctxAccountId(ctx) {
+		return dao.ErrWrongAccount
+	}
+	if err := models.Validate(ctx, template); err != nil {
+		return dao.ErrValidation
+	}
+
+	template.ID = stub.lastId + 1
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	stub.store = append(stub.store, template)
+	stub.lastId++
+	return nil
+}
+
+func (stub *reservationTemplateDaoStub) Update(ctx context.Context, template *models.ReservationTemplate) error {
+	if template.AccountID == 0 {
+		template.AccountID = ctxAccountId(ctx)
+	}
+	if template.AccountID != ctxAccountId(ctx) {
+		return dao.ErrWrongAccount
+	}
+	if err := models.Validate(ctx, template); err != nil {
+		return dao.ErrValidation
+	}
+
+	for idx, t := range stub.store {
+		if t.ID == template.ID {
+			template.UpdatedAt = time.Now()
+			stub.store[idx] = template
+			return nil
+		}
+	}
+	return dao.ErrNoRows
+}
+
+func (stub *reservationTemplateDaoStub) GetById(ctx context.Context, id int64) (*models.ReservationTemplate, error) {
+	for _, t := range stub.store {
+		if t.AccountID == ctxAccountId(ctx) && t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, dao.ErrNoRows
+}
+
+func (stub *reservationTemplateDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.ReservationTemplate, error) {
+	var filtered []*models.ReservationTemplate
+	for _, t := range stub.store {
+		if t.AccountID == ctxAccountId(ctx) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+func (stub *reservationTemplateDaoStub) Count(ctx context.Context) (int64, error) {
+	templates, _ := stub.List(ctx, 0, 0)
+	return int64(len(templates)), nil
+}
+
+func (stub *reservationTemplateDaoStub) Delete(ctx context.Context, id int64) error {
+	for idx, t := range stub.store {
+		if t.AccountID == ctxAccountId(ctx) && t.ID == id {
+			stub.store = append(stub.store[:idx], stub.store[idx+1:]...)
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
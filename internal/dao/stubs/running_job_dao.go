@@ -0,0 +1,78 @@
+package stubs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// runningJobDaoStub is safe for concurrent use, heartbeats are recorded from a goroutine.
+type runningJobDaoStub struct {
+	mu    sync.Mutex
+	store map[string]*models.RunningJob
+}
+
+func init() {
+	dao.GetRunningJobDao = getRunningJobDao
+}
+
+func RunningJobStubCount(ctx context.Context) int {
+	rjdao := getRunningJobDaoStub(ctx)
+	rjdao.mu.Lock()
+	defer rjdao.mu.Unlock()
+	return len(rjdao.store)
+}
+
+func getRunningJobDao(ctx context.Context) dao.RunningJobDao {
+	return getRunningJobDaoStub(ctx)
+}
+
+func (stub *runningJobDaoStub) UnscopedStart(ctx context.Context, job *models.RunningJob) error {
+	if err := models.Validate(ctx, job); err != nil {
+		return dao.ErrValidation
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	job.StartedAt = time.Now()
+	job.HeartbeatAt = job.StartedAt
+	stub.store[job.JobID] = job
+	return nil
+}
+
+func (stub *runningJobDaoStub) UnscopedHeartbeat(_ context.Context, jobId string) error {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	job, ok := stub.store[jobId]
+	if !ok {
+		return dao.ErrAffectedMismatch
+	}
+	job.HeartbeatAt = time.Now()
+	return nil
+}
+
+func (stub *runningJobDaoStub) UnscopedFinish(_ context.Context, jobId string) error {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	delete(stub.store, jobId)
+	return nil
+}
+
+func (stub *runningJobDaoStub) UnscopedReapStale(_ context.Context, before time.Time) ([]*models.RunningJob, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var result []*models.RunningJob
+	for id, job := range stub.store {
+		if job.HeartbeatAt.Before(before) {
+			result = append(result, job)
+			delete(stub.store, id)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package stubs
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+type sourceIdentityStubKey struct {
+	accountId int64
+	sourceId  string
+}
+
+type sourceIdentityDaoStub struct {
+	store map[sourceIdentityStubKey]*models.SourceIdentity
+}
+
+func init() {
+	dao.GetSourceIdentityDao = getSourceIdentityDao
+}
+
+func getSourceIdentityDao(ctx context.Context) dao.SourceIdentityDao {
+	return getSourceIdentityDaoStub(ctx)
+}
+
+func (stub *sourceIdentityDaoStub) GetBySourceId(ctx context.Context, sourceId string) (*models.SourceIdentity, error) {
+	if sourceIdentity, ok := stub.store[sourceIdentityStubKey{accountId: ctxAccountId(ctx), sourceId: sourceId}]; ok {
+		return sourceIdentity, nil
+	}
+	return nil, dao.ErrNoRows
+}
+
+func (stub *sourceIdentityDaoStub) Upsert(ctx context.Context, sourceIdentity *models.SourceIdentity) error {
+	sourceIdentity.AccountID = ctxAccountId(ctx)
+	sourceIdentity.ResolvedAt = time.Now()
+	stub.store[sourceIdentityStubKey{accountId: sourceIdentity.AccountID, sourceId: sourceIdentity.SourceID}] = sourceIdentity
+	return nil
+}
@@ -0,0 +1,186 @@
+package stubs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// webhookDaoStub is safe for concurrent use, deliveries are created from worker goroutines.
+type webhookDaoStub struct {
+	mu             sync.Mutex
+	lastId         int64
+	lastDeliveryId int64
+	store          []*models.Webhook
+	deliveries     []*models.WebhookDelivery
+}
+
+func init() {
+	dao.GetWebhookDao = getWebhookDao
+}
+
+// WebhookDeliveries returns all deliveries of all accounts in order of creation.
+func WebhookDeliveries(ctx context.Context) []*models.WebhookDelivery {
+	stub := getWebhookDaoStub(ctx)
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return append([]*models.WebhookDelivery{}, stub.deliveries...)
+}
+
+func getWebhookDao(ctx context.Context) dao.WebhookDao {
+	return getWebhookDaoStub(ctx)
+}
+
+func (stub *webhookDaoStub) Create(ctx context.Context, webhook *models.Webhook) error {
+	webhook.AccountID = ctxAccountId(ctx)
+	if err := models.Validate(ctx, webhook); err != nil {
+		return dao.ErrValidation
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	stub.lastId++
+	webhook.ID = stub.lastId
+	webhook.CreatedAt = time.Now()
+	stub.store = append(stub.store, webhook)
+	return nil
+}
+
+func (stub *webhookDaoStub) GetById(ctx context.Context, id int64) (*models.Webhook, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	for _, w := range stub.store {
+		if w.AccountID == ctxAccountId(ctx) && w.ID == id {
+			return w, nil
+		}
+	}
+	return nil, dao.ErrNoRows
+}
+
+func (stub *webhookDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Webhook, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var result []*models.Webhook
+	for _, w := range stub.store {
+		if w.AccountID == ctxAccountId(ctx) {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (stub *webhookDaoStub) Count(ctx context.Context) (int64, error) {
+	list, err := stub.List(ctx, 0, 0)
+	return int64(len(list)), err
+}
+
+func (stub *webhookDaoStub) Delete(ctx context.Context, id int64) error {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	for i, w := range stub.store {
+		if w.AccountID == ctxAccountId(ctx) && w.ID == id {
+			stub.store = append(stub.store[:i], stub.store[i+1:]...)
+			deliveries := make([]*models.WebhookDelivery, 0, len(stub.deliveries))
+			for _, d := range stub.deliveries {
+				if d.WebhookID != id {
+					deliveries = append(deliveries, d)
+				}
+			}
+			stub.deliveries = deliveries
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
+func (stub *webhookDaoStub) ListDeliveries(ctx context.Context, webhookId, limit, offset int64) ([]*models.WebhookDelivery, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var result []*models.WebhookDelivery
+	for _, d := range stub.deliveries {
+		if d.AccountID == ctxAccountId(ctx) && d.WebhookID == webhookId {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, nil
+}
+
+func (stub *webhookDaoStub) CountDeliveries(ctx context.Context, webhookId int64) (int64, error) {
+	list, err := stub.ListDeliveries(ctx, webhookId, 0, 0)
+	return int64(len(list)), err
+}
+
+func (stub *webhookDaoStub) UnscopedCreateDeliveries(_ context.Context, accountId int64, event models.WebhookEvent, payload []byte) (int64, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var created int64
+	for _, w := range stub.store {
+		if w.AccountID != accountId || !w.Subscribed(event) {
+			continue
+		}
+		stub.lastDeliveryId++
+		now := time.Now()
+		stub.deliveries = append(stub.deliveries, &models.WebhookDelivery{
+			ID:            stub.lastDeliveryId,
+			WebhookID:     w.ID,
+			AccountID:     accountId,
+			Event:         event,
+			Payload:       payload,
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		created++
+	}
+	return created, nil
+}
+
+func (stub *webhookDaoStub) UnscopedDeliver(_ context.Context, limit int64, maxAttempts int, deliver func(*models.Webhook, *models.WebhookDelivery) (int, error)) (int, int, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var delivered, failed int
+	for _, d := range stub.deliveries {
+		if int64(delivered+failed) >= limit {
+			break
+		}
+		if d.Status != models.WebhookDeliveryPending || d.NextAttemptAt.After(time.Now()) {
+			continue
+		}
+		var webhook *models.Webhook
+		for _, w := range stub.store {
+			if w.ID == d.WebhookID {
+				webhook = w
+			}
+		}
+
+		status, err := deliver(webhook, d)
+		d.Attempts++
+		d.ResponseStatus = int32(status)
+		if err != nil {
+			d.LastError = err.Error()
+			d.NextAttemptAt = time.Now().Add(time.Second)
+			if int(d.Attempts) >= maxAttempts {
+				d.Status = models.WebhookDeliveryFailed
+			}
+			failed++
+			continue
+		}
+		now := time.Now()
+		d.Status = models.WebhookDeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+		delivered++
+	}
+	return delivered, failed, nil
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountSettingsGetAndUpsert(t *testing.T) {
+	ctx := identity.WithTenant(t, context.Background())
+	settingsDao := dao.GetAccountSettingsDao(ctx)
+	defer reset()
+
+	t.Run("blank when never set", func(t *testing.T) {
+		settings, err := settingsDao.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "", settings.AWSRegion)
+		assert.Nil(t, settings.PubkeyID)
+		assert.Empty(t, settings.AllowedProviders)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		pk := factories.NewPubkeyRSA()
+		require.NoError(t, dao.GetPubkeyDao(ctx).Create(ctx, pk))
+
+		settings, err := settingsDao.Get(ctx)
+		require.NoError(t, err)
+		settings.AWSRegion = "eu-west-1"
+		settings.PubkeyID = &pk.ID
+		settings.AllowedProviders = []string{"aws", "gcp"}
+		settings.MaxHourlySpend = 12.5
+		settings.RequiredTags = []string{"cost-center"}
+		require.NoError(t, settingsDao.Upsert(ctx, settings))
+
+		settings.AWSRegion = "us-east-2"
+		require.NoError(t, settingsDao.Upsert(ctx, settings))
+
+		stored, err := settingsDao.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "us-east-2", stored.AWSRegion)
+		assert.Equal(t, pk.ID, *stored.PubkeyID)
+		assert.Equal(t, []string{"aws", "gcp"}, stored.AllowedProviders)
+		assert.Equal(t, 12.5, stored.MaxHourlySpend)
+		assert.Equal(t, []string{"cost-center"}, stored.RequiredTags)
+		assert.False(t, stored.UpdatedAt.IsZero())
+	})
+}
@@ -155,3 +155,102 @@ func TestAccountGetOrCreateByIdentity(t *testing.T) {
 		assert.Equal(t, "1", account.AccountNumber.String)
 	})
 }
+
+func TestAccountMerge(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		k := newKit(t)
+		accDao := dao.GetAccountDao(k.Ctx)
+		other, err := accDao.GetByOrgId(k.Ctx, "2")
+		require.NoError(t, err)
+
+		audit := &models.AccountAudit{SourceAccountID: k.Account.ID, AccountID: other.ID, RequestedBy: "admin"}
+		err = accDao.UnscopedMerge(k.Ctx, audit)
+		require.NoError(t, err)
+		assert.Equal(t, models.AccountAuditMerge, audit.Operation)
+		assert.Equal(t, int64(2), audit.Pubkeys)
+		assert.Equal(t, int64(2), audit.Reservations)
+		assert.NotZero(t, audit.ID)
+
+		_, err = dao.GetPubkeyDao(k.OtherCtx).GetById(k.OtherCtx, k.Pubkey.ID)
+		require.NoError(t, err)
+		_, err = dao.GetReservationDao(k.OtherCtx).GetById(k.OtherCtx, k.Finished.ID)
+		require.NoError(t, err)
+		_, err = dao.GetPubkeyDao(k.Ctx).GetById(k.Ctx, k.Pubkey.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+	})
+
+	t.Run("webhooks, identities and settings", func(t *testing.T) {
+		k := newKit(t)
+		webhook := newWebhook(models.WebhookEventReservationSuccess)
+		require.NoError(t, dao.GetWebhookDao(k.Ctx).Create(k.Ctx, webhook))
+
+		identityDao := dao.GetSourceIdentityDao(k.Ctx)
+		require.NoError(t, identityDao.Upsert(k.Ctx, &models.SourceIdentity{SourceID: "1", Authentication: "source", ProviderAccountID: "111111111111"}))
+		require.NoError(t, identityDao.Upsert(k.Ctx, &models.SourceIdentity{SourceID: "2", Authentication: "source", ProviderAccountID: "222222222222"}))
+		require.NoError(t, identityDao.Upsert(k.OtherCtx, &models.SourceIdentity{SourceID: "1", Authentication: "target", ProviderAccountID: "333333333333"}))
+
+		require.NoError(t, dao.GetAccountSettingsDao(k.Ctx).Upsert(k.Ctx, &models.AccountSettings{AWSRegion: "us-east-1"}))
+		require.NoError(t, dao.GetAccountSettingsDao(k.OtherCtx).Upsert(k.OtherCtx, &models.AccountSettings{AWSRegion: "eu-west-1"}))
+
+		other, err := dao.GetAccountDao(k.Ctx).GetByOrgId(k.Ctx, "2")
+		require.NoError(t, err)
+		audit := &models.AccountAudit{SourceAccountID: k.Account.ID, AccountID: other.ID}
+		require.NoError(t, dao.GetAccountDao(k.Ctx).UnscopedMerge(k.Ctx, audit))
+
+		_, err = dao.GetWebhookDao(k.OtherCtx).GetById(k.OtherCtx, webhook.ID)
+		require.NoError(t, err)
+
+		kept, err := identityDao.GetBySourceId(k.OtherCtx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "333333333333", kept.ProviderAccountID)
+		moved, err := identityDao.GetBySourceId(k.OtherCtx, "2")
+		require.NoError(t, err)
+		assert.Equal(t, "222222222222", moved.ProviderAccountID)
+
+		settings, err := dao.GetAccountSettingsDao(k.OtherCtx).Get(k.OtherCtx)
+		require.NoError(t, err)
+		assert.Equal(t, "eu-west-1", settings.AWSRegion)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		k := newKit(t)
+		duplicate := *k.Pubkey
+		duplicate.ID = 0
+		duplicate.AccountID = 0
+		err := dao.GetPubkeyDao(k.OtherCtx).Create(k.OtherCtx, &duplicate)
+		require.NoError(t, err)
+
+		audit := &models.AccountAudit{SourceAccountID: k.Account.ID, AccountID: duplicate.AccountID}
+		err = dao.GetAccountDao(k.Ctx).UnscopedMerge(k.Ctx, audit)
+		require.ErrorIs(t, err, dao.ErrConflict)
+	})
+
+	t.Run("no account", func(t *testing.T) {
+		k := newKit(t)
+		audit := &models.AccountAudit{SourceAccountID: k.Account.ID, AccountID: math.MaxInt64}
+		err := dao.GetAccountDao(k.Ctx).UnscopedMerge(k.Ctx, audit)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+	})
+}
+
+func TestAccountRename(t *testing.T) {
+	k := newKit(t)
+	accDao := dao.GetAccountDao(k.Ctx)
+
+	t.Run("success", func(t *testing.T) {
+		audit := &models.AccountAudit{AccountID: k.Account.ID, NewOrgID: "1000"}
+		err := accDao.UnscopedRename(k.Ctx, audit)
+		require.NoError(t, err)
+		assert.Equal(t, k.Account.OrgID, audit.OldOrgID)
+
+		account, err := accDao.GetById(k.Ctx, k.Account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "1000", account.OrgID)
+	})
+
+	t.Run("organization taken", func(t *testing.T) {
+		audit := &models.AccountAudit{AccountID: k.Account.ID, NewOrgID: "2"}
+		err := accDao.UnscopedRename(k.Ctx, audit)
+		require.ErrorIs(t, err, dao.ErrConflict)
+	})
+}
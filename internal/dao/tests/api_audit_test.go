@@ -0,0 +1,48 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAPIAudit(method, resourceId string) *models.APIAudit {
+	return &models.APIAudit{
+		AccountID:  1,
+		OrgID:      "1",
+		Principal:  "user",
+		Method:     method,
+		Route:      "/api/provisioning/v1/pubkeys/{ID}",
+		ResourceID: resourceId,
+		Status:     204,
+		TraceID:    "0af7651916cd43dd8448eb211c80319c",
+	}
+}
+
+func TestAPIAuditCreateAndList(t *testing.T) {
+	ctx := context.Background()
+	aaDao := dao.GetAPIAuditDao(ctx)
+	defer reset()
+
+	require.NoError(t, aaDao.UnscopedCreate(ctx, newAPIAudit("PUT", "1")))
+	require.NoError(t, aaDao.UnscopedCreate(ctx, newAPIAudit("DELETE", "1")))
+	require.NoError(t, aaDao.UnscopedCreate(ctx, newAPIAudit("DELETE", "2")))
+
+	audits, err := aaDao.UnscopedListByAccount(ctx, 1, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, audits, 2)
+	assert.Equal(t, "2", audits[0].ResourceID)
+	assert.Equal(t, "user", audits[0].Principal)
+	assert.False(t, audits[0].CreatedAt.IsZero())
+
+	count, err := aaDao.UnscopedCountByAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
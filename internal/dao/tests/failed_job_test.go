@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFailedJob() *models.FailedJob {
+	return &models.FailedJob{
+		JobID:     "c4d2b6a4-2b5f-4a48-9d0e-4d3f0b9c7a11",
+		JobType:   "refresh_availability",
+		AccountID: 1,
+		Args:      []byte(`{"Provider":1}`),
+		Payload:   []byte{1, 2, 3},
+		Error:     "cloud outage",
+		Attempts:  3,
+	}
+}
+
+func TestFailedJobCreate(t *testing.T) {
+	ctx := context.Background()
+	fjDao := dao.GetFailedJobDao(ctx)
+	defer reset()
+
+	job := newFailedJob()
+	err := fjDao.UnscopedCreate(ctx, job)
+	require.NoError(t, err)
+	require.NotZero(t, job.ID)
+
+	job2, err := fjDao.UnscopedGetById(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, job.JobType, job2.JobType)
+	assert.Equal(t, job.Payload, job2.Payload)
+	assert.JSONEq(t, string(job.Args), string(job2.Args))
+	assert.Equal(t, 3, job2.Attempts)
+	assert.Nil(t, job2.ReplayedAt)
+}
+
+func TestFailedJobListAndReplay(t *testing.T) {
+	ctx := context.Background()
+	fjDao := dao.GetFailedJobDao(ctx)
+	defer reset()
+
+	first := newFailedJob()
+	require.NoError(t, fjDao.UnscopedCreate(ctx, first))
+	second := newFailedJob()
+	require.NoError(t, fjDao.UnscopedCreate(ctx, second))
+
+	jobs, err := fjDao.UnscopedList(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, second.ID, jobs[0].ID, "most recent job must be first")
+
+	count, err := fjDao.UnscopedCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	err = fjDao.UnscopedMarkReplayed(ctx, first.ID)
+	require.NoError(t, err)
+
+	replayed, err := fjDao.UnscopedGetById(ctx, first.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, replayed.ReplayedAt)
+
+	err = fjDao.UnscopedMarkReplayed(ctx, 9999)
+	assert.ErrorIs(t, err, dao.ErrAffectedMismatch)
+}
@@ -0,0 +1,59 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceActionCreateAndUpdate(t *testing.T) {
+	k := newKit(t)
+	actionDao := dao.GetInstanceActionDao(k.Ctx)
+
+	action := &models.InstanceAction{ReservationID: k.Finished.ID, InstanceID: "i-1", Action: models.InstanceActionStop}
+	require.NoError(t, actionDao.Create(k.Ctx, action))
+	assert.NotZero(t, action.ID)
+	assert.Equal(t, models.InstanceActionPending, action.Status)
+
+	t.Run("unfinished action of the same instance", func(t *testing.T) {
+		err := actionDao.Create(k.Ctx, &models.InstanceAction{ReservationID: k.Finished.ID, InstanceID: "i-1", Action: models.InstanceActionStart})
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+
+	t.Run("another instance", func(t *testing.T) {
+		require.NoError(t, actionDao.Create(k.Ctx, &models.InstanceAction{ReservationID: k.Finished.ID, InstanceID: "i-2", Action: models.InstanceActionReboot}))
+	})
+
+	t.Run("finish", func(t *testing.T) {
+		require.NoError(t, actionDao.UnscopedUpdateStatus(k.Ctx, action.ID, models.InstanceActionFailed, "insufficient capacity"))
+
+		stored, err := actionDao.GetById(k.Ctx, action.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.InstanceActionFailed, stored.Status)
+		assert.Equal(t, "insufficient capacity", stored.Error)
+		assert.NotNil(t, stored.FinishedAt)
+
+		require.NoError(t, actionDao.Create(k.Ctx, &models.InstanceAction{ReservationID: k.Finished.ID, InstanceID: "i-1", Action: models.InstanceActionStop}))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		list, err := actionDao.ListByReservation(k.Ctx, k.Finished.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, list, 3)
+		assert.Equal(t, "i-1", list[0].InstanceID, "newest first")
+
+		count, err := actionDao.CountByReservation(k.Ctx, k.Finished.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		other, err := dao.GetInstanceActionDao(k.OtherCtx).ListByReservation(k.OtherCtx, k.Finished.ID, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, other)
+	})
+}
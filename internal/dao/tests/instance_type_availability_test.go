@@ -0,0 +1,73 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAvailability(region, zone string, types ...string) *models.InstanceTypeAvailability {
+	return &models.InstanceTypeAvailability{
+		Provider:      models.ProviderTypeAWS,
+		Region:        region,
+		Zone:          zone,
+		InstanceTypes: types,
+	}
+}
+
+func setupInstanceTypeAvailability(t *testing.T) (dao.InstanceTypeAvailabilityDao, context.Context) {
+	ctx := identity.WithTenant(t, context.Background())
+	itaDao := dao.GetInstanceTypeAvailabilityDao(ctx)
+	return itaDao, ctx
+}
+
+func TestInstanceTypeAvailabilityReplace(t *testing.T) {
+	itaDao, ctx := setupInstanceTypeAvailability(t)
+	defer reset()
+
+	t.Run("empty", func(t *testing.T) {
+		list, err := itaDao.UnscopedList(ctx, models.ProviderTypeAWS)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		err := itaDao.UnscopedReplace(ctx, models.ProviderTypeAWS, []*models.InstanceTypeAvailability{
+			newAvailability("us-east-1", "", "t2.micro", "t2.small"),
+			newAvailability("us-west-1", "", "t2.micro"),
+		})
+		require.NoError(t, err)
+
+		list, err := itaDao.UnscopedList(ctx, models.ProviderTypeAWS)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(list))
+		assert.Equal(t, "us-east-1", list[0].Region)
+		assert.Equal(t, []string{"t2.micro", "t2.small"}, list[0].InstanceTypes)
+	})
+
+	t.Run("replaces previous records", func(t *testing.T) {
+		err := itaDao.UnscopedReplace(ctx, models.ProviderTypeAWS, []*models.InstanceTypeAvailability{
+			newAvailability("eu-west-1", "", "m5.large"),
+		})
+		require.NoError(t, err)
+
+		list, err := itaDao.UnscopedList(ctx, models.ProviderTypeAWS)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(list))
+		assert.Equal(t, "eu-west-1", list[0].Region)
+	})
+
+	t.Run("other providers untouched", func(t *testing.T) {
+		list, err := itaDao.UnscopedList(ctx, models.ProviderTypeGCP)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
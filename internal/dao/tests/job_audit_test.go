@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJobAudit(accountId, reservationId int64, outcome string) *models.JobAudit {
+	return &models.JobAudit{
+		JobID:         "3f6c1a4e-8b2d-4c7a-9e1f-5d0b2a6c8e33",
+		JobType:       "launch_instances_aws",
+		AccountID:     accountId,
+		ReservationID: reservationId,
+		ArgsHash:      "abc",
+		Attempt:       1,
+		Outcome:       outcome,
+		StartedAt:     time.Now(),
+		DurationMs:    1500,
+	}
+}
+
+func TestJobAuditCreateAndList(t *testing.T) {
+	ctx := context.Background()
+	jaDao := dao.GetJobAuditDao(ctx)
+	defer reset()
+
+	require.NoError(t, jaDao.UnscopedCreate(ctx, newJobAudit(1, 42, "failure")))
+	require.NoError(t, jaDao.UnscopedCreate(ctx, newJobAudit(1, 42, "success")))
+	require.NoError(t, jaDao.UnscopedCreate(ctx, newJobAudit(1, 0, "success")))
+
+	byReservation, err := jaDao.UnscopedListByReservation(ctx, 42, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, byReservation, 2)
+	assert.Equal(t, "success", byReservation[0].Outcome)
+	assert.Equal(t, int64(1500), byReservation[0].DurationMs)
+
+	byAccount, err := jaDao.UnscopedListByAccount(ctx, 1, 2, 0)
+	require.NoError(t, err)
+	assert.Len(t, byAccount, 2)
+
+	count, err := jaDao.UnscopedCountByReservation(ctx, 42)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = jaDao.UnscopedCountByAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
@@ -0,0 +1,97 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/require"
+)
+
+// kit is a test environment with canonical fixtures. All statements executed with kit contexts
+// run in a single transaction which is rolled back when the test finishes, so tests using the
+// kit do not need to call reset.
+//
+// A failed statement aborts the transaction: tests expecting database errors (e.g. constraint
+// violations) must run them within dao.WithTransaction (a savepoint) or use reset instead.
+type kit struct {
+	// Ctx is the context of the default tenant (account 1).
+	Ctx context.Context
+
+	// OtherCtx is the context of the second tenant (account 2) in the same transaction.
+	OtherCtx context.Context
+
+	// Account is the default tenant account.
+	Account *models.Account
+
+	// Pubkey is an RSA pubkey of the default tenant.
+	Pubkey *models.Pubkey
+
+	// Finished is a successfully finished AWS reservation of the default tenant using Pubkey.
+	Finished *models.AWSReservation
+
+	// Running is an unfinished noop reservation of the default tenant.
+	Running *models.NoopReservation
+}
+
+// newKit begins the test transaction and creates fixtures.
+func newKit(t *testing.T) *kit {
+	t.Helper()
+	tx, err := db.Pool.Begin(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, tx.Rollback(context.Background()))
+	})
+
+	txCtx := db.WithTx(context.Background(), tx)
+	k := &kit{
+		Ctx:      identity.WithTenant(t, txCtx),
+		OtherCtx: identity.WithTenantOrgId(t, txCtx, "2"),
+	}
+
+	k.Account, err = dao.GetAccountDao(k.Ctx).GetByOrgId(k.Ctx, identity.DefaultOrgId)
+	require.NoError(t, err)
+
+	k.Pubkey = factories.NewPubkeyRSA()
+	k.Pubkey.AccountID = k.Account.ID
+	k.Pubkey.Body = factories.GenerateRSAPubKey(t)
+	err = dao.GetPubkeyDao(k.Ctx).Create(k.Ctx, k.Pubkey)
+	require.NoError(t, err)
+
+	resDao := dao.GetReservationDao(k.Ctx)
+	k.Finished = &models.AWSReservation{
+		Reservation: models.Reservation{
+			Provider:   models.ProviderTypeAWS,
+			AccountID:  k.Account.ID,
+			Steps:      1,
+			StepTitles: []string{"Kit step"},
+			Status:     "Finished",
+		},
+		PubkeyID: k.Pubkey.ID,
+	}
+	err = resDao.CreateAWS(k.Ctx, k.Finished)
+	require.NoError(t, err)
+	err = resDao.FinishWithSuccess(k.Ctx, k.Finished.ID)
+	require.NoError(t, err)
+
+	k.Running = &models.NoopReservation{
+		Reservation: models.Reservation{
+			Provider:   models.ProviderTypeNoop,
+			AccountID:  k.Account.ID,
+			Steps:      1,
+			StepTitles: []string{"Kit step"},
+			Status:     "Created",
+		},
+	}
+	err = resDao.CreateNoop(k.Ctx, k.Running)
+	require.NoError(t, err)
+
+	return k
+}
@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errPublish = errors.New("broker unavailable")
+
+func newOutboxMessage(value string) *models.OutboxMessage {
+	return &models.OutboxMessage{
+		Topic:   "platform.notifications.ingress",
+		Key:     []byte("key"),
+		Value:   []byte(value),
+		Headers: []models.OutboxHeader{{Key: "rh-trace-id", Value: "abc"}},
+	}
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ctx := context.Background()
+	outboxDao := dao.GetOutboxDao(ctx)
+	defer reset()
+
+	t.Run("published", func(t *testing.T) {
+		require.NoError(t, outboxDao.UnscopedEnqueue(ctx, newOutboxMessage("one"), newOutboxMessage("two")))
+
+		var relayed []*models.OutboxMessage
+		published, failed, err := outboxDao.UnscopedRelay(ctx, 100, func(m *models.OutboxMessage) error {
+			relayed = append(relayed, m)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Equal(t, 0, failed)
+		require.Len(t, relayed, 2)
+		assert.Equal(t, "one", string(relayed[0].Value))
+		assert.Equal(t, []byte("key"), relayed[0].Key)
+		assert.Equal(t, "abc", relayed[0].Headers[0].Value)
+
+		count, err := outboxDao.UnscopedCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		require.NoError(t, outboxDao.UnscopedEnqueue(ctx, newOutboxMessage("three")))
+
+		published, failed, err := outboxDao.UnscopedRelay(ctx, 100, func(m *models.OutboxMessage) error {
+			return errPublish
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, published)
+		assert.Equal(t, 1, failed)
+
+		// the message is postponed
+		published, failed, err = outboxDao.UnscopedRelay(ctx, 100, func(m *models.OutboxMessage) error {
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, published+failed)
+
+		count, err := outboxDao.UnscopedCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
+
+func TestOutboxReservationFinish(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	outboxDao := dao.GetOutboxDao(ctx)
+	defer reset()
+
+	t.Run("stored with reservation", func(t *testing.T) {
+		res := newNoopReservation()
+		require.NoError(t, reservationDao.CreateNoop(ctx, res))
+
+		err := reservationDao.FinishWithSuccess(ctx, res.ID, newOutboxMessage("success"))
+		require.NoError(t, err)
+
+		count, err := outboxDao.UnscopedCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("rolled back with reservation", func(t *testing.T) {
+		err := reservationDao.FinishWithError(ctx, 0, "error", newOutboxMessage("failure"))
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+
+		count, err := outboxDao.UnscopedCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
@@ -80,6 +81,10 @@ func TestPubkeyList(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(pubkeys))
 		require.Contains(t, pubkeys, newKey)
+
+		count, err := pkDao.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
 	})
 }
 
@@ -174,3 +179,114 @@ func TestPubkeyDeleteById(t *testing.T) {
 		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
 	})
 }
+
+func TestPubkeyRestore(t *testing.T) {
+	pkDao, ctx := setupPubkey(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		pk := factories.NewPubkeyRSA()
+		err := pkDao.Create(ctx, pk)
+		require.NoError(t, err)
+
+		err = pkDao.Delete(ctx, pk.ID)
+		require.NoError(t, err)
+
+		err = pkDao.Restore(ctx, pk.ID)
+		require.NoError(t, err)
+
+		restored, err := pkDao.GetById(ctx, pk.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		pk := factories.NewPubkeyRSA()
+		pk.Body = factories.GenerateRSAPubKey(t)
+		err := pkDao.Create(ctx, pk)
+		require.NoError(t, err)
+
+		err = pkDao.Restore(ctx, pk.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
+
+func TestPubkeySharing(t *testing.T) {
+	pkDao, ctx := setupPubkey(t)
+	defer reset()
+	alice := identity.WithUser(t, ctx, "alice")
+	bob := identity.WithUser(t, ctx, "bob")
+
+	pk := factories.NewPubkeyRSA()
+	err := pkDao.Create(alice, pk)
+	require.NoError(t, err)
+
+	count, err := pkDao.Count(bob)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	err = pkDao.UpdateShared(bob, pk.ID, true)
+	require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	err = pkDao.UpdateShared(alice, pk.ID, true)
+	require.NoError(t, err)
+
+	shared, err := pkDao.GetById(bob, pk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", shared.Owner)
+	assert.True(t, shared.Shared)
+
+	err = pkDao.Delete(bob, pk.ID)
+	require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+}
+
+func TestPubkeyListExpiringBetween(t *testing.T) {
+	pkDao, ctx := setupPubkey(t)
+	defer reset()
+
+	soon := time.Now().Add(24 * time.Hour)
+	expiring := factories.NewPubkeyRSA()
+	expiring.ExpiresAt = &soon
+	err := pkDao.Create(ctx, expiring)
+	require.NoError(t, err)
+
+	expired := time.Now().Add(-time.Hour)
+	old := factories.NewPubkeyRSA()
+	old.Body = factories.GenerateRSAPubKey(t)
+	old.ExpiresAt = &expired
+	err = pkDao.Create(ctx, old)
+	require.NoError(t, err)
+
+	pubkeys, err := pkDao.UnscopedListExpiringBetween(ctx, time.Now(), time.Now().Add(7*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, pubkeys, 1)
+	assert.Equal(t, expiring.ID, pubkeys[0].ID)
+}
+
+func TestPubkeyPurgeDeleted(t *testing.T) {
+	pkDao, ctx := setupPubkey(t)
+	defer reset()
+
+	deleted := factories.NewPubkeyRSA()
+	err := pkDao.Create(ctx, deleted)
+	require.NoError(t, err)
+	err = pkDao.Delete(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	active := factories.NewPubkeyRSA()
+	active.Body = factories.GenerateRSAPubKey(t)
+	err = pkDao.Create(ctx, active)
+	require.NoError(t, err)
+
+	count, err := pkDao.UnscopedPurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	count, err = pkDao.UnscopedPurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	err = pkDao.Restore(ctx, deleted.ID)
+	require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	_, err = pkDao.GetById(ctx, active.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,106 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupReservationTemplate(t *testing.T) (dao.ReservationTemplateDao, context.Context) {
+	ctx := identity.WithTenant(t, context.Background())
+	templateDao := dao.GetReservationTemplateDao(ctx)
+	return templateDao, ctx
+}
+
+func newReservationTemplate() *models.ReservationTemplate {
+	return &models.ReservationTemplate{
+		Name:         factories.SeqNameWithPrefix("template"),
+		Provider:     models.ProviderTypeAWS,
+		SourceID:     "1",
+		ImageID:      "ami-0",
+		InstanceType: "t3.small",
+		Region:       "us-east-1",
+		Tags:         map[string]string{"team": "qe"},
+	}
+}
+
+func TestReservationTemplateCreate(t *testing.T) {
+	templateDao, ctx := setupReservationTemplate(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		template := newReservationTemplate()
+		err := templateDao.Create(ctx, template)
+		require.NoError(t, err)
+
+		template2, err := templateDao.GetById(ctx, template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, template.Name, template2.Name)
+		assert.Equal(t, template.Tags, template2.Tags)
+		assert.Nil(t, template2.PubkeyID)
+	})
+
+	t.Run("validation error on name", func(t *testing.T) {
+		template := newReservationTemplate()
+		template.Name = ""
+		err := templateDao.Create(ctx, template)
+		require.ErrorAs(t, err, &validator.ValidationErrors{})
+	})
+}
+
+func TestReservationTemplateUpdate(t *testing.T) {
+	templateDao, ctx := setupReservationTemplate(t)
+	defer reset()
+
+	pk := factories.NewPubkeyRSA()
+	err := dao.GetPubkeyDao(ctx).Create(ctx, pk)
+	require.NoError(t, err)
+
+	template := newReservationTemplate()
+	err = templateDao.Create(ctx, template)
+	require.NoError(t, err)
+
+	template.InstanceType = "t3.large"
+	template.PubkeyID = &pk.ID
+	err = templateDao.Update(ctx, template)
+	require.NoError(t, err)
+
+	template2, err := templateDao.GetById(ctx, template.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "t3.large", template2.InstanceType)
+	assert.Equal(t, pk.ID, *template2.PubkeyID)
+}
+
+func TestReservationTemplateListAndDelete(t *testing.T) {
+	templateDao, ctx := setupReservationTemplate(t)
+	defer reset()
+
+	for i := 0; i < 2; i++ {
+		err := templateDao.Create(ctx, newReservationTemplate())
+		require.NoError(t, err)
+	}
+
+	templates, err := templateDao.List(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+
+	count, err := templateDao.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	err = templateDao.Delete(ctx, templates[0].ID)
+	require.NoError(t, err)
+
+	_, err = templateDao.GetById(ctx, templates[0].ID)
+	assert.ErrorIs(t, err, dao.ErrNoRows)
+}
@@ -51,6 +51,18 @@ func newAWSReservation() *models.AWSReservation {
 	}
 }
 
+func newAzureReservation() *models.AzureReservation {
+	return &models.AzureReservation{
+		Reservation: models.Reservation{
+			Provider:  models.ProviderTypeAzure,
+			AccountID: 1,
+			Status:    "Created",
+		},
+		PubkeyID: 1,
+		Detail:   &models.AzureDetail{Location: "eastus_1", Amount: 1},
+	}
+}
+
 func newGCPReservation() *models.GCPReservation {
 	return &models.GCPReservation{
 		Reservation: models.Reservation{
@@ -202,6 +214,40 @@ func TestReservationList(t *testing.T) {
 		reservations, err := reservationDao.List(ctx, 10, 0)
 		require.NoError(t, err)
 		assert.Equal(t, 2, len(reservations))
+
+		count, err := reservationDao.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+}
+
+func TestReservationListByPubkeyId(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		awsReservation := newAWSReservation()
+		awsReservation.Detail = &models.AWSDetail{Region: "us-east-1"}
+		err := reservationDao.CreateAWS(ctx, awsReservation)
+		require.NoError(t, err)
+
+		gcpReservation := newGCPReservation()
+		gcpReservation.Detail = &models.GCPDetail{Zone: "us-east4-a"}
+		err = reservationDao.CreateGCP(ctx, gcpReservation)
+		require.NoError(t, err)
+
+		reservations, err := reservationDao.ListByPubkeyId(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(reservations))
+		assert.Equal(t, "us-east-1", reservations[0].Region)
+		assert.Equal(t, models.ProviderTypeGCP, reservations[1].Provider)
+		assert.Equal(t, "us-east4-a", reservations[1].Region)
+	})
+
+	t.Run("other pubkey", func(t *testing.T) {
+		reservations, err := reservationDao.ListByPubkeyId(ctx, 2)
+		require.NoError(t, err)
+		assert.Empty(t, reservations)
 	})
 }
 
@@ -231,6 +277,29 @@ func TestUnscopedUpdateAWSDetail(t *testing.T) {
 	})
 }
 
+func TestUnscopedUpdateAzureDetail(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	t.Run("updates detail field", func(t *testing.T) {
+		reservation := newAzureReservation()
+		err := reservationDao.CreateAzure(ctx, reservation)
+		require.NoError(t, err)
+		newDetail := &models.AzureDetail{
+			Location:              "eastus_1",
+			Amount:                1,
+			ResourceGroupStrategy: models.AzureResourceGroupReservation,
+			ResourceGroup:         "redhat-reservation-1",
+		}
+		err = reservationDao.UnscopedUpdateAzureDetail(ctx, reservation.ID, newDetail)
+		require.NoError(t, err, "failed to update reservation")
+
+		reservationAfter, err := reservationDao.GetAzureById(ctx, reservation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "redhat-reservation-1", reservationAfter.Detail.ResourceGroup)
+	})
+}
+
 func TestReservationUpdateIDForAWS(t *testing.T) {
 	reservationDao, ctx := setupReservation(t)
 	defer reset()
@@ -355,6 +424,58 @@ func TestReservationFinish(t *testing.T) {
 	})
 }
 
+func TestReservationUpdateSSHReady(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	res := newNoopReservation()
+	err := reservationDao.CreateNoop(ctx, res)
+	require.NoError(t, err)
+
+	newRes, err := reservationDao.GetById(ctx, res.ID)
+	require.NoError(t, err)
+	assert.False(t, newRes.SSHReady.Valid)
+
+	err = reservationDao.UnscopedUpdateSSHReady(ctx, res.ID, true)
+	require.NoError(t, err)
+
+	newRes, err = reservationDao.GetById(ctx, res.ID)
+	require.NoError(t, err)
+	assert.True(t, newRes.SSHReady.Valid)
+	assert.True(t, newRes.SSHReady.Bool)
+
+	t.Run("mismatch", func(t *testing.T) {
+		err := reservationDao.UnscopedUpdateSSHReady(ctx, math.MaxInt64, true)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
+
+func TestReservationListDeletedBetween(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	res := newNoopReservation()
+	err := reservationDao.CreateNoop(ctx, res)
+	require.NoError(t, err)
+	err = reservationDao.FinishWithSuccess(ctx, res.ID)
+	require.NoError(t, err)
+	err = reservationDao.SoftDelete(ctx, res.ID)
+	require.NoError(t, err)
+
+	active := newNoopReservation()
+	err = reservationDao.CreateNoop(ctx, active)
+	require.NoError(t, err)
+
+	deleted, err := reservationDao.UnscopedListDeletedBetween(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, res.ID, deleted[0].ID)
+
+	deleted, err = reservationDao.UnscopedListDeletedBetween(ctx, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+}
+
 func TestReservationRate(t *testing.T) {
 	rdao, ctx := setupReservation(t)
 	t.Run("allows slow reservations", func(t *testing.T) {
@@ -385,3 +506,250 @@ func TestReservationRate(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestReservationStats(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	since := time.Now().Add(-24 * time.Hour)
+	createAWS := func(t *testing.T) int64 {
+		res := newAWSReservation()
+		err := reservationDao.CreateAWS(ctx, res)
+		require.NoError(t, err)
+		return res.ID
+	}
+
+	successId := createAWS(t)
+	require.NoError(t, reservationDao.FinishWithSuccess(ctx, successId))
+	require.NoError(t, reservationDao.CreateInstance(ctx, newReservationInstance(successId)))
+	require.NoError(t, reservationDao.FinishWithError(ctx, createAWS(t), "error"))
+	require.NoError(t, reservationDao.FinishWithCancel(ctx, createAWS(t), "cancelled"))
+	require.NoError(t, reservationDao.CreateGCP(ctx, newGCPReservation()))
+
+	rDao2, ctx2 := setupReservationOrg2(t)
+	require.NoError(t, rDao2.CreateGCP(ctx2, newGCPReservation()))
+
+	t.Run("by provider", func(t *testing.T) {
+		stats, err := reservationDao.StatsByProvider(ctx, since)
+		require.NoError(t, err)
+		require.Len(t, stats, 2)
+
+		assert.Equal(t, models.ProviderTypeAWS, stats[0].Provider)
+		assert.Equal(t, int64(3), stats[0].Total)
+		assert.Equal(t, int64(1), stats[0].Success)
+		assert.Equal(t, int64(1), stats[0].Failure)
+		assert.Equal(t, int64(1), stats[0].Cancelled)
+		assert.Equal(t, int64(0), stats[0].Pending)
+		assert.Equal(t, int64(1), stats[0].Instances)
+		assert.InDelta(t, 0.5, stats[0].SuccessRate, 0.001)
+
+		assert.Equal(t, models.ProviderTypeGCP, stats[1].Provider)
+		assert.Equal(t, int64(1), stats[1].Total)
+		assert.Equal(t, int64(1), stats[1].Pending)
+		assert.Zero(t, stats[1].SuccessRate)
+	})
+
+	t.Run("daily", func(t *testing.T) {
+		stats, err := reservationDao.DailyStats(ctx, since)
+		require.NoError(t, err)
+		require.Len(t, stats, 4)
+
+		results := make(map[string]int64)
+		for _, stat := range stats {
+			results[stat.Provider.String()+"/"+stat.Result] += stat.Count
+		}
+		assert.Equal(t, map[string]int64{
+			"aws/cancelled": 1,
+			"aws/failure":   1,
+			"aws/success":   1,
+			"gcp/pending":   1,
+		}, results)
+	})
+
+	t.Run("empty period", func(t *testing.T) {
+		stats, err := reservationDao.StatsByProvider(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, stats)
+	})
+}
+
+func TestReservationListRunningInstanceTypes(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	running := newAWSReservation()
+	running.Detail = &models.AWSDetail{InstanceType: "t3.small", Amount: 3}
+	require.NoError(t, reservationDao.CreateAWS(ctx, running))
+	for _, instanceId := range []string{"i-running-1", "i-running-2", "i-stopped"} {
+		require.NoError(t, reservationDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: running.ID, InstanceID: instanceId}))
+	}
+	require.NoError(t, reservationDao.FinishWithSuccess(ctx, running.ID))
+
+	actionDao := dao.GetInstanceActionDao(ctx)
+	stop := &models.InstanceAction{ReservationID: running.ID, InstanceID: "i-stopped", Action: models.InstanceActionStop}
+	require.NoError(t, actionDao.Create(ctx, stop))
+	require.NoError(t, actionDao.UnscopedUpdateStatus(ctx, stop.ID, models.InstanceActionSuccess, ""))
+	failedStop := &models.InstanceAction{ReservationID: running.ID, InstanceID: "i-running-1", Action: models.InstanceActionStop}
+	require.NoError(t, actionDao.Create(ctx, failedStop))
+	require.NoError(t, actionDao.UnscopedUpdateStatus(ctx, failedStop.ID, models.InstanceActionFailed, "error"))
+
+	// finished long ago, instances were terminated outside of the service
+	terminated := newAWSReservation()
+	terminated.Detail = &models.AWSDetail{InstanceType: "t3.xlarge", Amount: 1}
+	require.NoError(t, reservationDao.CreateAWS(ctx, terminated))
+	require.NoError(t, reservationDao.FinishWithSuccess(ctx, terminated.ID))
+
+	poweroff := newAWSReservation()
+	poweroff.Detail = &models.AWSDetail{InstanceType: "t3.medium", Amount: 1, PowerOff: true}
+	require.NoError(t, reservationDao.CreateAWS(ctx, poweroff))
+
+	fleet := newAWSReservation()
+	fleet.Detail = &models.AWSDetail{InstanceTypes: []string{"m5.large", "m5a.large"}, Amount: 1}
+	require.NoError(t, reservationDao.CreateAWS(ctx, fleet))
+
+	// the launch job records the type chosen by the fleet, the second candidate here
+	launchedFleet := newAWSReservation()
+	launchedFleet.Detail = &models.AWSDetail{InstanceTypes: []string{"c5.large", "c5a.large"}, Amount: 1}
+	require.NoError(t, reservationDao.CreateAWS(ctx, launchedFleet))
+	launchedFleet.Detail.InstanceType = "c5a.large"
+	require.NoError(t, reservationDao.UnscopedUpdateAWSDetail(ctx, launchedFleet.ID, launchedFleet.Detail))
+	require.NoError(t, reservationDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: launchedFleet.ID, InstanceID: "i-fleet"}))
+	require.NoError(t, reservationDao.FinishWithSuccess(ctx, launchedFleet.ID))
+
+	failed := newAWSReservation()
+	failed.Detail = &models.AWSDetail{InstanceType: "t3.large", Amount: 1}
+	require.NoError(t, reservationDao.CreateAWS(ctx, failed))
+	require.NoError(t, reservationDao.FinishWithError(ctx, failed.ID, "error"))
+
+	azure := newAzureReservation()
+	azure.Detail.InstanceSize = "Standard_B1s"
+	require.NoError(t, reservationDao.CreateAzure(ctx, azure))
+
+	rDao2, ctx2 := setupReservationOrg2(t)
+	require.NoError(t, rDao2.CreateAzure(ctx2, newAzureReservation()))
+
+	result, err := reservationDao.ListRunningInstanceTypes(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*models.ReservationInstanceTypeAmount{
+		{Provider: models.ProviderTypeAWS, InstanceType: "t3.small", Amount: 2},
+		{Provider: models.ProviderTypeAWS, InstanceType: "m5.large", Amount: 1},
+		{Provider: models.ProviderTypeAWS, InstanceType: "c5a.large", Amount: 1},
+		{Provider: models.ProviderTypeAzure, InstanceType: "Standard_B1s", Amount: 1},
+	}, result)
+}
+
+func TestReservationArchive(t *testing.T) {
+	k := newKit(t)
+	reservationDao := dao.GetReservationDao(k.Ctx)
+	err := reservationDao.CreateInstance(k.Ctx, newReservationInstance(k.Finished.ID))
+	require.NoError(t, err)
+
+	failed := &models.AWSReservation{
+		Reservation: models.Reservation{
+			Provider:   models.ProviderTypeAWS,
+			AccountID:  k.Account.ID,
+			Steps:      1,
+			StepTitles: []string{"Kit step"},
+			Status:     "Failed",
+		},
+		PubkeyID: k.Pubkey.ID,
+	}
+	require.NoError(t, reservationDao.CreateAWS(k.Ctx, failed))
+	require.NoError(t, reservationDao.FinishWithError(k.Ctx, failed.ID, "error"))
+
+	t.Run("not old enough", func(t *testing.T) {
+		count, err := reservationDao.UnscopedArchive(k.Ctx, time.Now().Add(-time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		count, err := reservationDao.UnscopedArchive(k.Ctx, time.Now().Add(time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		_, err = reservationDao.GetById(k.Ctx, failed.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+		_, err = reservationDao.GetById(k.Ctx, k.Running.ID)
+		require.NoError(t, err)
+
+		// instances of the successful reservation may still be running
+		_, err = reservationDao.GetById(k.Ctx, k.Finished.ID)
+		require.NoError(t, err)
+		_, err = reservationDao.GetArchivedById(k.Ctx, k.Finished.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+
+		archived, err := reservationDao.GetArchivedById(k.Ctx, failed.ID)
+		require.NoError(t, err)
+		assert.False(t, archived.Success.Bool)
+		assert.Equal(t, "error", archived.Error)
+		assert.Equal(t, failed.StepTitles, archived.StepTitles)
+
+		list, err := reservationDao.ListArchived(k.Ctx, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(list))
+
+		count, err = reservationDao.CountArchived(k.Ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("other account", func(t *testing.T) {
+		_, err := reservationDao.GetArchivedById(k.OtherCtx, failed.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+	})
+}
+
+func TestReservationSharing(t *testing.T) {
+	k := newKit(t)
+	alice := identity.WithUser(t, k.Ctx, "alice")
+	bob := identity.WithUser(t, k.Ctx, "bob")
+	reservationDao := dao.GetReservationDao(k.Ctx)
+
+	res := newNoopReservation()
+	err := reservationDao.CreateNoop(alice, res)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", res.Owner)
+
+	t.Run("private", func(t *testing.T) {
+		_, err := reservationDao.GetById(bob, res.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+		err = reservationDao.UpdateShared(bob, res.ID, true)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+
+		// organization-wide identities only see shared reservations of users
+		_, err = reservationDao.GetById(k.Ctx, res.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+		err = reservationDao.UpdateShared(k.Ctx, res.ID, true)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+		err = reservationDao.RequestCancel(k.Ctx, res.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+
+	t.Run("service account", func(t *testing.T) {
+		sa := identity.WithServiceAccount(t, k.Ctx, "client-1")
+		_, err := reservationDao.GetById(sa, res.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+		err = reservationDao.UpdateShared(sa, res.ID, true)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+
+		owned := newNoopReservation()
+		require.NoError(t, reservationDao.CreateNoop(sa, owned))
+		assert.Equal(t, "client-1", owned.Owner)
+		_, err = reservationDao.GetById(sa, owned.ID)
+		require.NoError(t, err)
+		_, err = reservationDao.GetById(alice, owned.ID)
+		require.ErrorIs(t, err, dao.ErrNoRows)
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		err := reservationDao.UpdateShared(alice, res.ID, true)
+		require.NoError(t, err)
+
+		shared, err := reservationDao.GetById(bob, res.ID)
+		require.NoError(t, err)
+		assert.True(t, shared.Shared)
+		err = reservationDao.RequestCancel(bob, res.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
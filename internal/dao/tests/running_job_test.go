@@ -0,0 +1,61 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRunningJob() *models.RunningJob {
+	return &models.RunningJob{
+		JobID:   "5b0f3c1e-6a3d-4f0e-8f8a-2e7d9b1c4a22",
+		JobType: "launch_instances_aws",
+		Worker:  "worker-1",
+		Payload: []byte{1, 2, 3},
+	}
+}
+
+func TestRunningJobHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	rjDao := dao.GetRunningJobDao(ctx)
+	defer reset()
+
+	job := newRunningJob()
+	require.NoError(t, rjDao.UnscopedStart(ctx, job))
+	require.NoError(t, rjDao.UnscopedHeartbeat(ctx, job.JobID))
+
+	require.NoError(t, rjDao.UnscopedFinish(ctx, job.JobID))
+	err := rjDao.UnscopedHeartbeat(ctx, job.JobID)
+	require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+}
+
+func TestRunningJobReapStale(t *testing.T) {
+	ctx := context.Background()
+	rjDao := dao.GetRunningJobDao(ctx)
+	defer reset()
+
+	job := newRunningJob()
+	require.NoError(t, rjDao.UnscopedStart(ctx, job))
+
+	stale, err := rjDao.UnscopedReapStale(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+
+	stale, err = rjDao.UnscopedReapStale(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.Equal(t, job.JobID, stale[0].JobID)
+	assert.Equal(t, job.Payload, stale[0].Payload)
+
+	stale, err = rjDao.UnscopedReapStale(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+}
@@ -0,0 +1,45 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceIdentityGetAndUpsert(t *testing.T) {
+	ctx := identity.WithTenant(t, context.Background())
+	sourceIdentityDao := dao.GetSourceIdentityDao(ctx)
+	defer reset()
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := sourceIdentityDao.GetBySourceId(ctx, "1")
+		require.ErrorIs(t, err, dao.ErrNoRows)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		sourceIdentity := &models.SourceIdentity{
+			SourceID:          "1",
+			Authentication:    "arn:aws:iam::230214684733:role/Test",
+			ProviderAccountID: "230214684733",
+		}
+		require.NoError(t, sourceIdentityDao.Upsert(ctx, sourceIdentity))
+
+		sourceIdentity.Authentication = "arn:aws:iam::123456789012:role/Test"
+		sourceIdentity.ProviderAccountID = "123456789012"
+		require.NoError(t, sourceIdentityDao.Upsert(ctx, sourceIdentity))
+
+		stored, err := sourceIdentityDao.GetBySourceId(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "arn:aws:iam::123456789012:role/Test", stored.Authentication)
+		assert.Equal(t, "123456789012", stored.ProviderAccountID)
+		assert.False(t, stored.ResolvedAt.IsZero())
+	})
+}
@@ -0,0 +1,104 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWebhook(events ...models.WebhookEvent) *models.Webhook {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = string(e)
+	}
+	return &models.Webhook{
+		URL:    "https://example.com/hook",
+		Secret: "s3cr3t",
+		Events: names,
+	}
+}
+
+func TestWebhookCreateListDelete(t *testing.T) {
+	ctx := identity.WithTenant(t, context.Background())
+	webhookDao := dao.GetWebhookDao(ctx)
+	defer reset()
+
+	webhook := newWebhook(models.WebhookEventReservationSuccess)
+	require.NoError(t, webhookDao.Create(ctx, webhook))
+	assert.NotZero(t, webhook.ID)
+
+	stored, err := webhookDao.GetById(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reservation.success"}, stored.Events)
+
+	list, err := webhookDao.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	count, err := webhookDao.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	require.NoError(t, webhookDao.Delete(ctx, webhook.ID))
+	require.ErrorIs(t, webhookDao.Delete(ctx, webhook.ID), dao.ErrAffectedMismatch)
+	_, err = webhookDao.GetById(ctx, webhook.ID)
+	require.ErrorIs(t, err, dao.ErrNoRows)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	ctx := identity.WithTenant(t, context.Background())
+	webhookDao := dao.GetWebhookDao(ctx)
+	defer reset()
+
+	subscribed := newWebhook(models.WebhookEventReservationSuccess, models.WebhookEventReservationFailure)
+	require.NoError(t, webhookDao.Create(ctx, subscribed))
+	other := newWebhook(models.WebhookEventInstanceTerminated)
+	require.NoError(t, webhookDao.Create(ctx, other))
+
+	created, err := webhookDao.UnscopedCreateDeliveries(ctx, identity2.AccountId(ctx), models.WebhookEventReservationSuccess, []byte(`{"reservation_id":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created)
+
+	t.Run("failed attempt", func(t *testing.T) {
+		delivered, failed, err := webhookDao.UnscopedDeliver(ctx, 10, 2, func(w *models.Webhook, d *models.WebhookDelivery) (int, error) {
+			assert.Equal(t, subscribed.URL, w.URL)
+			assert.Equal(t, subscribed.Secret, w.Secret)
+			assert.JSONEq(t, `{"reservation_id":1}`, string(d.Payload))
+			return http.StatusInternalServerError, errPublish
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+		assert.Equal(t, 1, failed)
+
+		deliveries, err := webhookDao.ListDeliveries(ctx, subscribed.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+		assert.Equal(t, int32(1), deliveries[0].Attempts)
+		assert.Equal(t, int32(http.StatusInternalServerError), deliveries[0].ResponseStatus)
+		assert.Equal(t, errPublish.Error(), deliveries[0].LastError)
+		assert.True(t, deliveries[0].NextAttemptAt.After(deliveries[0].CreatedAt))
+	})
+
+	t.Run("not due", func(t *testing.T) {
+		delivered, failed, err := webhookDao.UnscopedDeliver(ctx, 10, 2, func(w *models.Webhook, d *models.WebhookDelivery) (int, error) {
+			return http.StatusOK, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, delivered+failed)
+
+		count, err := webhookDao.CountDeliveries(ctx, subscribed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
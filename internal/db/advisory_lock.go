@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdvisoryLock is a session-level Postgres advisory lock held by a dedicated pool connection.
+// The lock is released by the database when the connection is closed, also when the process
+// dies.
+type AdvisoryLock struct {
+	conn *pgxpool.Conn
+}
+
+// TryAdvisoryLock acquires a connection from the pool and tries to obtain an advisory lock
+// without waiting. Returns nil lock when the lock is held by another session.
+func TryAdvisoryLock(ctx context.Context, key int64) (*AdvisoryLock, error) {
+	conn, err := Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to acquire connection: %w", err)
+	}
+
+	var locked bool
+	err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked)
+	if err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, nil
+	}
+
+	return &AdvisoryLock{conn: conn}, nil
+}
+
+// Check verifies the connection holding the lock is still alive.
+func (l *AdvisoryLock) Check(ctx context.Context) error {
+	err := l.conn.Ping(ctx)
+	if err != nil {
+		return fmt.Errorf("advisory lock connection lost: %w", err)
+	}
+	return nil
+}
+
+// Release closes the connection holding the lock, so the lock is never returned to the pool.
+func (l *AdvisoryLock) Release(ctx context.Context) {
+	_ = l.conn.Conn().Close(ctx)
+	l.conn.Release()
+}
@@ -5,16 +5,15 @@ import (
 	"fmt"
 	"net/url"
 
-	"github.com/IBM/pgxpoolprometheus"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
-	"github.com/RHEnVision/provisioning-backend/internal/version"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"github.com/exaring/otelpgx"
 	pgxlog "github.com/jackc/pgx-zerolog"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/tracelog"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
@@ -45,31 +44,26 @@ func getConnString(prefix, schema string) string {
 	}
 }
 
-// Initialize creates connection pool. Close must be called when done.
-func Initialize(ctx context.Context, schema string) error {
-	var err error
-	if schema == "" {
-		schema = "public"
-	}
-
-	// register and setup logging configuration
-	connStr := getConnString("postgres", schema)
+// newPoolConfig parses the connection string and applies pool, tracing and logging settings.
+func newPoolConfig(connStr string) (*pgxpool.Config, error) {
 	poolConfig, err := pgxpool.ParseConfig(connStr)
 	if err != nil {
-		return fmt.Errorf("unable to parse db configuration: %w", err)
+		return nil, fmt.Errorf("unable to parse db configuration: %w", err)
 	}
 
 	poolConfig.MaxConns = config.Database.MaxConn
 	poolConfig.MinConns = config.Database.MinConn
 	poolConfig.MaxConnLifetime = config.Database.MaxLifetime
 	poolConfig.MaxConnIdleTime = config.Database.MaxIdleTime
+	poolConfig.MaxConnLifetimeJitter = config.Database.LifetimeJitter
+	poolConfig.HealthCheckPeriod = config.Database.HealthCheck
 
 	if config.Telemetry.Enabled {
 		poolConfig.ConnConfig.Tracer = otelpgx.NewTracer()
 	} else {
 		logLevel, configErr := tracelog.LogLevelFromString(config.Database.LogLevel)
 		if configErr != nil {
-			return fmt.Errorf("cannot parse db log level configuration: %w", configErr)
+			return nil, fmt.Errorf("cannot parse db log level configuration: %w", configErr)
 		}
 
 		if logLevel > 0 {
@@ -92,6 +86,44 @@ func Initialize(ctx context.Context, schema string) error {
 		}
 	}
 
+	if config.Database.SlowQuery > 0 {
+		poolConfig.ConnConfig.Tracer = newSlowQueryTracer(config.Database.SlowQuery, poolConfig.ConnConfig.Tracer)
+	}
+
+	if config.Database.TenantAudit != "" {
+		tracer, auditErr := newTenantAuditTracer(config.Database.TenantAudit, poolConfig.ConnConfig.Tracer)
+		if auditErr != nil {
+			return nil, auditErr
+		}
+		poolConfig.ConnConfig.Tracer = tracer
+	}
+
+	return poolConfig, nil
+}
+
+// Initialize creates connection pool. When replica connection string is not empty, a second
+// pool is created for read-only queries (see ReadPool). Close must be called when done.
+func Initialize(ctx context.Context, schema string, replicaConnStr string) error {
+	var err error
+	if schema == "" {
+		schema = "public"
+	}
+
+	// register and setup logging configuration
+	connStr := getConnString("postgres", schema)
+	poolConfig, err := newPoolConfig(connStr)
+	if err != nil {
+		return err
+	}
+
+	// new connections use the rotated password
+	if secrets.DatabasePassword.Managed() {
+		poolConfig.BeforeConnect = func(_ context.Context, cc *pgx.ConnConfig) error {
+			cc.Password = secrets.DatabasePassword.Value()
+			return nil
+		}
+	}
+
 	Pool, err = pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return fmt.Errorf("unable to create connection pool: %w", err)
@@ -102,19 +134,20 @@ func Initialize(ctx context.Context, schema string) error {
 		return fmt.Errorf("unable to ping the database: %w", err)
 	}
 
-	// Register telemetry
-	labels := map[string]string{
-		"service": version.PrometheusLabelName,
-		"db_host": config.Database.Host,
-		"db_name": config.Database.Name,
+	registerPoolMetrics(Pool, "primary")
+
+	if replicaConnStr != "" {
+		err = initializeReplica(ctx, replicaConnStr)
+		if err != nil {
+			return err
+		}
 	}
-	collector := pgxpoolprometheus.NewCollector(Pool, labels)
-	prometheus.MustRegister(collector)
 
 	return nil
 }
 
 func Close() {
 	log.Logger.Info().Msg("Closing all database connections")
+	closeReplica()
 	Pool.Close()
 }
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"github.com/IBM/pgxpoolprometheus"
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// registerPoolMetrics publishes statistics of the pool as pgx_pool_* metrics: acquired, idle,
+// constructing and total connections, acquire count and duration (time spent waiting for
+// a connection), canceled and empty acquires and destroyed connections. The pool label
+// distinguishes the primary pool from the replica.
+func registerPoolMetrics(pool *pgxpool.Pool, name string) {
+	connConfig := pool.Config().ConnConfig
+	labels := map[string]string{
+		"service": version.PrometheusLabelName,
+		"pool":    name,
+		"db_host": connConfig.Host,
+		"db_name": connConfig.Database,
+	}
+	collector := pgxpoolprometheus.NewCollector(pool, labels)
+	prometheus.MustRegister(collector)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier executes statements, it is implemented by the pool and transactions.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type txCtxKeyType int
+
+const txCtxKey txCtxKeyType = iota
+
+// WithTx returns context which routes all DAO statements into the transaction, transactions
+// started by DAOs become savepoints. It is meant for tests which roll back all changes. The
+// transaction must not be used concurrently.
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txCtxKey, tx)
+}
+
+func ctxTx(ctx context.Context) pgx.Tx {
+	tx, _ := ctx.Value(txCtxKey).(pgx.Tx)
+	return tx
+}
+
+// Conn returns the querier for DAO statements: the transaction from the context or the main pool.
+func Conn(ctx context.Context) Querier {
+	if tx := ctxTx(ctx); tx != nil {
+		return tx
+	}
+	return Pool
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// replicaCheckInterval is how often availability of the read-only replica is verified.
+const replicaCheckInterval = 10 * time.Second
+
+// replicaPingTimeout is the maximum duration of the availability check.
+const replicaPingTimeout = 2 * time.Second
+
+// replica is the optional read-only replica pool, nil when not configured.
+var replica *replicaPool
+
+type replicaPool struct {
+	pool *pgxpool.Pool
+
+	// available is false when the last ping failed, reads fall back to the main pool
+	available atomic.Bool
+
+	// checkedAt is the Unix time in nanoseconds of the last (or running) availability check
+	checkedAt atomic.Int64
+}
+
+type primaryCtxKeyType int
+
+const primaryCtxKey primaryCtxKeyType = iota
+
+// WithPrimary returns context which routes all reads to the main pool. Use it to read data
+// written in the same request, replica can lag behind the primary.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryCtxKey, true)
+}
+
+// ReadPool returns the querier for read-only queries: the replica when configured and available,
+// the main pool otherwise. Queries in transactions must always use the main pool.
+func ReadPool(ctx context.Context) Querier {
+	if tx := ctxTx(ctx); tx != nil {
+		return tx
+	}
+	if replica == nil {
+		return Pool
+	}
+	if primary, ok := ctx.Value(primaryCtxKey).(bool); ok && primary {
+		return Pool
+	}
+
+	replica.checkInBackground()
+	if !replica.available.Load() {
+		return Pool
+	}
+	return replica.pool
+}
+
+func initializeReplica(ctx context.Context, connStr string) error {
+	poolConfig, err := newPoolConfig(connStr)
+	if err != nil {
+		return fmt.Errorf("replica: %w", err)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create replica connection pool: %w", err)
+	}
+	replica = &replicaPool{pool: pool}
+
+	// replica being down must not prevent the application from starting
+	replica.check(ctx)
+	if !replica.available.Load() {
+		log.Logger.Warn().Msg("Database replica is not available, reading from the main database")
+	}
+
+	registerPoolMetrics(pool, "replica")
+
+	return nil
+}
+
+// checkInBackground starts an availability check when the last one is older than the interval.
+// Only one check runs at a time.
+func (r *replicaPool) checkInBackground() {
+	last := r.checkedAt.Load()
+	now := time.Now().UnixNano()
+	if now-last < int64(replicaCheckInterval) || !r.checkedAt.CompareAndSwap(last, now) {
+		return
+	}
+
+	go r.check(context.Background())
+}
+
+// check pings the replica and updates its availability.
+func (r *replicaPool) check(ctx context.Context) {
+	r.checkedAt.Store(time.Now().UnixNano())
+	ctx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+	defer cancel()
+
+	err := r.pool.Ping(ctx)
+	available := err == nil
+	if r.available.Swap(available) == available {
+		return
+	}
+	if available {
+		log.Logger.Info().Msg("Database replica is available, reading from the replica")
+	} else {
+		log.Logger.Warn().Err(err).Msg("Database replica is not available, reading from the main database")
+	}
+}
+
+func closeReplica() {
+	if replica != nil {
+		replica.pool.Close()
+		replica = nil
+	}
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPool(t *testing.T) {
+	ctx := context.Background()
+	primary := &pgxpool.Pool{}
+	Pool = primary
+	defer func() {
+		Pool = nil
+		replica = nil
+	}()
+
+	t.Run("no replica", func(t *testing.T) {
+		replica = nil
+		assert.Same(t, primary, ReadPool(ctx))
+	})
+
+	// recent check prevents pinging the replica
+	replica = &replicaPool{pool: &pgxpool.Pool{}}
+	replica.checkedAt.Store(time.Now().UnixNano())
+
+	t.Run("replica available", func(t *testing.T) {
+		replica.available.Store(true)
+		assert.Same(t, replica.pool, ReadPool(ctx))
+	})
+
+	t.Run("replica down", func(t *testing.T) {
+		replica.available.Store(false)
+		assert.Same(t, primary, ReadPool(ctx))
+	})
+
+	t.Run("primary requested", func(t *testing.T) {
+		replica.available.Store(true)
+		assert.Same(t, primary, ReadPool(WithPrimary(ctx)))
+	})
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// daoPackagePrefix is the function name prefix of DAO implementations, used to find
+// the DAO method of a slow query in the call stack.
+const daoPackagePrefix = "github.com/RHEnVision/provisioning-backend/internal/dao/pgx."
+
+// maxCallerDepth is the maximum number of stack frames searched for the DAO method.
+const maxCallerDepth = 32
+
+type slowQueryCtxKeyType int
+
+const slowQueryCtxKey slowQueryCtxKeyType = iota
+
+type slowQueryStart struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+// slowQueryTracer logs statements which take longer than the threshold and passes the trace
+// to the next tracer. Values of bound parameters are never logged, only their types.
+type slowQueryTracer struct {
+	next      pgx.QueryTracer
+	threshold time.Duration
+}
+
+func newSlowQueryTracer(threshold time.Duration, next pgx.QueryTracer) *slowQueryTracer {
+	return &slowQueryTracer{next: next, threshold: threshold}
+}
+
+func (t *slowQueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx = context.WithValue(ctx, slowQueryCtxKey, &slowQueryStart{
+		start: time.Now(),
+		sql:   data.SQL,
+		args:  data.Args,
+	})
+
+	if t.next != nil {
+		return t.next.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (t *slowQueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if t.next != nil {
+		t.next.TraceQueryEnd(ctx, conn, data)
+	}
+
+	query, ok := ctx.Value(slowQueryCtxKey).(*slowQueryStart)
+	if !ok {
+		return
+	}
+	duration := time.Since(query.start)
+	if duration < t.threshold {
+		return
+	}
+
+	logger := zerolog.Ctx(ctx).Warn().
+		Str("sql", query.sql).
+		Strs("args", redactArgs(query.args)).
+		Dur("duration", duration).
+		Str("dao", daoCaller()).
+		Err(data.Err)
+	if traceId := logging.TraceId(ctx); traceId != "" {
+		logger = logger.Str("trace_id", traceId)
+	}
+	logger.Msgf("Slow query took %s", duration.Round(time.Millisecond))
+}
+
+// redactArgs returns types of bound parameters instead of their values.
+func redactArgs(args []any) []string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		result[i] = fmt.Sprintf("$%d=%T", i+1, arg)
+	}
+	return result
+}
+
+// daoCaller returns the DAO method (e.g. "(*pubkeyDao).GetById") which executed the statement
+// or an empty string when it was not executed from a DAO.
+func daoCaller() string {
+	pc := make([]uintptr, maxCallerDepth)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, daoPackagePrefix) {
+			name := strings.TrimPrefix(frame.Function, daoPackagePrefix)
+			// strip closures (e.g. "func1") of functions running in transactions
+			if i := strings.Index(name, ".func"); i > 0 {
+				name = name[:i]
+			}
+			return name
+		}
+		if !more {
+			return ""
+		}
+	}
+}
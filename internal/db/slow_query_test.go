@@ -0,0 +1,39 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowQueryTracer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	ctx := logging.WithTraceId(logger.WithContext(context.Background()), "trace1")
+	query := pgx.TraceQueryStartData{
+		SQL:  `SELECT * FROM pubkeys WHERE account_id = $1 AND name = $2`,
+		Args: []any{int64(1), "secret"},
+	}
+
+	t.Run("fast query", func(t *testing.T) {
+		buf.Reset()
+		tracer := newSlowQueryTracer(time.Hour, nil)
+		tracer.TraceQueryEnd(tracer.TraceQueryStart(ctx, nil, query), nil, pgx.TraceQueryEndData{})
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("slow query", func(t *testing.T) {
+		buf.Reset()
+		tracer := newSlowQueryTracer(time.Nanosecond, nil)
+		tracer.TraceQueryEnd(tracer.TraceQueryStart(ctx, nil, query), nil, pgx.TraceQueryEndData{})
+		assert.Contains(t, buf.String(), `"trace_id":"trace1"`)
+		assert.Contains(t, buf.String(), `"$2=string"`)
+		assert.NotContains(t, buf.String(), "secret")
+	})
+}
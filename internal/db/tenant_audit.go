@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// TenantAuditLog logs statements without tenant scoping.
+const TenantAuditLog = "log"
+
+// TenantAuditPanic panics on statements without tenant scoping.
+const TenantAuditPanic = "panic"
+
+// tenantTables matches tables with the account_id column, statements must filter them by
+// the account unless marked as unscoped. Detail tables (e.g. pubkey_resources) are scoped
+// through their parent records.
+var tenantTables = regexp.MustCompile(`(?i)\b(pubkeys|reservations|reservations_archive|reservation_templates|account_settings|source_identities|failed_jobs|job_audit|account_audit|api_audit|webhooks|webhook_deliveries)\b`)
+
+// tenantPredicate matches the account scope of a statement.
+var tenantPredicate = regexp.MustCompile(`(?i)\baccount_id\b`)
+
+type unscopedCtxKeyType int
+
+const unscopedCtxKey unscopedCtxKeyType = iota
+
+// WithUnscoped returns context which marks statements as intentionally not scoped by account,
+// they are skipped by the tenant audit. Use it only in DAO functions marked with UNSCOPED word.
+func WithUnscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedCtxKey, true)
+}
+
+func isUnscoped(ctx context.Context) bool {
+	unscoped, ok := ctx.Value(unscopedCtxKey).(bool)
+	return ok && unscoped
+}
+
+// TenantScopeError is the panic value of statements without tenant scoping.
+type TenantScopeError struct {
+	SQL string
+}
+
+func (e TenantScopeError) Error() string {
+	return fmt.Sprintf("statement is not scoped by account: %s", e.SQL)
+}
+
+// unscopedStatement returns true when the statement uses a tenant table without account scope.
+func unscopedStatement(sql string) bool {
+	return tenantTables.MatchString(sql) && !tenantPredicate.MatchString(sql)
+}
+
+// tenantAuditTracer verifies statements are scoped by account before they are executed and
+// passes the trace to the next tracer. Only query tracing of the next tracer is kept, the
+// audit is meant for development and tests.
+type tenantAuditTracer struct {
+	next  pgx.QueryTracer
+	panic bool
+}
+
+func newTenantAuditTracer(mode string, next pgx.QueryTracer) (*tenantAuditTracer, error) {
+	if mode != TenantAuditLog && mode != TenantAuditPanic {
+		return nil, fmt.Errorf("unknown tenant audit mode %q, must be %s or %s", mode, TenantAuditLog, TenantAuditPanic)
+	}
+	return &tenantAuditTracer{next: next, panic: mode == TenantAuditPanic}, nil
+}
+
+func (t *tenantAuditTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !isUnscoped(ctx) && unscopedStatement(data.SQL) {
+		err := TenantScopeError{SQL: data.SQL}
+		if t.panic {
+			panic(err)
+		}
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Tenant audit: statement without account scope")
+	}
+
+	if t.next != nil {
+		return t.next.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (t *tenantAuditTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if t.next != nil {
+		t.next.TraceQueryEnd(ctx, conn, data)
+	}
+}
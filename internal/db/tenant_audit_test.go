@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnscopedStatement(t *testing.T) {
+	assert.False(t, unscopedStatement(`SELECT * FROM pubkeys WHERE account_id = $1 AND id = $2`))
+	assert.False(t, unscopedStatement(`INSERT INTO reservations (provider, account_id, steps) VALUES ($1, $2, $3)`))
+	assert.False(t, unscopedStatement(`SELECT * FROM pubkey_resources WHERE pubkey_id = $1`))
+	assert.False(t, unscopedStatement(`SELECT pg_try_advisory_lock($1)`))
+	assert.True(t, unscopedStatement(`UPDATE reservations SET status = $2 WHERE id = $1`))
+	assert.True(t, unscopedStatement(`SELECT COUNT(*) FROM Reservation_Templates`))
+}
+
+func TestTenantAuditTracer(t *testing.T) {
+	ctx := context.Background()
+	unscoped := pgx.TraceQueryStartData{SQL: `DELETE FROM pubkeys WHERE id = $1`}
+
+	t.Run("panic", func(t *testing.T) {
+		tracer, err := newTenantAuditTracer(TenantAuditPanic, nil)
+		require.NoError(t, err)
+
+		assert.Panics(t, func() { tracer.TraceQueryStart(ctx, nil, unscoped) })
+		assert.NotPanics(t, func() { tracer.TraceQueryStart(WithUnscoped(ctx), nil, unscoped) })
+	})
+
+	t.Run("log", func(t *testing.T) {
+		tracer, err := newTenantAuditTracer(TenantAuditLog, nil)
+		require.NoError(t, err)
+
+		assert.NotPanics(t, func() { tracer.TraceQueryStart(ctx, nil, unscoped) })
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := newTenantAuditTracer("strict", nil)
+		require.Error(t, err)
+	})
+}
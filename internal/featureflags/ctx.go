@@ -0,0 +1,35 @@
+package featureflags
+
+import (
+	"context"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+	ucontext "github.com/Unleash/unleash-client-go/v3/context"
+)
+
+type contextKeyId int
+
+const (
+	unleashContextCtxKey contextKeyId = iota
+)
+
+// unleashContext returns unleash context, or a context with organization of the identity when
+// not set (e.g. in background jobs).
+func unleashContext(ctx context.Context) ucontext.Context {
+	value := ctx.Value(unleashContextCtxKey)
+	if value == nil {
+		return ucontext.Context{
+			UserId:      identity.OrgIdOrEmpty(ctx),
+			Environment: config.Unleash.Environment,
+			AppName:     version.UnleashAppName,
+		}
+	}
+	return value.(ucontext.Context)
+}
+
+// WithUnleashContext returns context copy with unleash context as a value.
+func WithUnleashContext(ctx context.Context, uctx ucontext.Context) context.Context {
+	return context.WithValue(ctx, unleashContextCtxKey, uctx)
+}
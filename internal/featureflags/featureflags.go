@@ -0,0 +1,109 @@
+// Package featureflags provides feature flags backed by the platform Unleash server. Flags are
+// evaluated per organization, values of the UNLEASH_FALLBACK setting are used when Unleash is
+// not configured or does not know the flag. Unknown flags are enabled.
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+	"github.com/Unleash/unleash-client-go/v3"
+	"github.com/rs/zerolog"
+)
+
+const unleashProjectName = "default"
+
+// Flag is a feature flag name without the UNLEASH_PREFIX setting.
+type Flag string
+
+const (
+	// Launch enables the launch button in UI which initiates the application workflow.
+	Launch Flag = "launch"
+
+	// Azure enables reservations and launch jobs of the Azure provider.
+	Azure Flag = "azure"
+
+	// GCP enables reservations and launch jobs of the GCP provider.
+	GCP Flag = "gcp"
+)
+
+// Name returns the full Unleash name of the flag.
+func (f Flag) Name() string {
+	return fmt.Sprintf("%s.%s", config.Unleash.Prefix, f)
+}
+
+// Enabled returns an organization-specific or global feature flag.
+func Enabled(ctx context.Context, flag Flag) bool {
+	return EnabledByName(ctx, flag.Name())
+}
+
+// EnabledByName returns an organization-specific or global feature flag with the full
+// Unleash name.
+func EnabledByName(ctx context.Context, name string) bool {
+	fallback := fallbackValue(name)
+	if !config.Unleash.Enabled {
+		return fallback
+	}
+
+	return unleash.IsEnabled(name, unleash.WithContext(unleashContext(ctx)), unleash.WithFallback(fallback))
+}
+
+// fallbackValue returns the value of the flag from the UNLEASH_FALLBACK setting, true when
+// the flag is not listed.
+func fallbackValue(name string) bool {
+	value, ok := config.Current().UnleashFallback[name]
+	if !ok {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+func unleashLogger(ctx context.Context) *zerolog.Logger {
+	logger := zerolog.Ctx(ctx).With().Bool("unleash", true).Logger()
+	return &logger
+}
+
+// Initialize configures unleash client and starts poller routine. Callers must close poller
+// by calling Stop function.
+func Initialize(ctx context.Context) error {
+	if !config.Unleash.Enabled {
+		return nil
+	}
+
+	listener := logListener{
+		logger: unleashLogger(ctx),
+	}
+	err := unleash.Initialize(
+		unleash.WithListener(&listener),
+		unleash.WithUrl(config.Unleash.URL),
+		unleash.WithProjectName(unleashProjectName),
+		unleash.WithAppName(version.UnleashAppName),
+		unleash.WithEnvironment(config.Unleash.Environment),
+		unleash.WithCustomHeaders(http.Header{"Authorization": {config.Unleash.Token}}),
+	)
+	if err != nil {
+		return fmt.Errorf("unleash error: %w", err)
+	}
+
+	return nil
+}
+
+// Stop stops the unleash feature flag poller
+func Stop(ctx context.Context) {
+	if !config.Unleash.Enabled {
+		return
+	}
+
+	err := unleash.Close()
+	if err != nil {
+		unleashLogger(ctx).Warn().Err(err).Msg("Unable to close unleash poller")
+	}
+}
@@ -0,0 +1,32 @@
+package featureflags
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnabled(t *testing.T) {
+	config.Unleash.Prefix = "provisioning"
+	config.Unleash.Fallback = map[string]string{
+		"provisioning.azure": "false",
+		"provisioning.gcp":   "invalid",
+	}
+	t.Cleanup(func() { config.Unleash.Fallback = nil })
+	ctx := context.Background()
+
+	t.Run("unknown flag is enabled", func(t *testing.T) {
+		assert.True(t, Enabled(ctx, Launch))
+	})
+
+	t.Run("fallback disables flag", func(t *testing.T) {
+		assert.False(t, Enabled(ctx, Azure))
+		assert.False(t, EnabledByName(ctx, "provisioning.azure"))
+	})
+
+	t.Run("invalid fallback is ignored", func(t *testing.T) {
+		assert.True(t, Enabled(ctx, GCP))
+	})
+}
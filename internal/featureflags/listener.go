@@ -1,4 +1,4 @@
-package config
+package featureflags
 
 import (
 	"github.com/Unleash/unleash-client-go/v3"
@@ -7,6 +7,7 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
 	"github.com/redhatinsights/platform-go-middlewares/identity"
 	"github.com/rs/zerolog"
 )
@@ -36,12 +37,16 @@ func AddEdgeRequestIdHeader(ctx context.Context, req *http.Request) error {
 
 func AddSourcesIdentityHeader(ctx context.Context, req *http.Request) error {
 	username := config.Sources.Username
-	password := config.Sources.Password
+	password := secrets.SourcesPassword.Value()
 	return addIdentityHeader(ctx, req, username, password)
 }
 
 func AddImageBuilderIdentityHeader(ctx context.Context, req *http.Request) error {
 	username := config.ImageBuilder.Username
-	password := config.ImageBuilder.Password
+	password := secrets.ImageBuilderPassword.Value()
 	return addIdentityHeader(ctx, req, username, password)
 }
+
+func AddRBACIdentityHeader(ctx context.Context, req *http.Request) error {
+	return addIdentityHeader(ctx, req, "", "")
+}
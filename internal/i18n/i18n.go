@@ -0,0 +1,114 @@
+// Package i18n translates user facing messages according to the Accept-Language header.
+// Messages are identified by their English text, catalogs of other languages are JSON maps
+// of English messages to translations in the catalog directory named by the language
+// (e.g. es.json). Messages missing in a catalog are returned in English.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed catalog/*.json
+var catalogFS embed.FS
+
+type languageCtxKeyType int
+
+const languageCtxKey languageCtxKeyType = iota
+
+// catalogs of messages by base language, English is the default language without a catalog
+var catalogs map[string]map[string]string
+
+var matcher language.Matcher
+
+func init() {
+	if err := loadCatalogs(); err != nil {
+		panic(err)
+	}
+}
+
+func loadCatalogs() error {
+	entries, err := catalogFS.ReadDir("catalog")
+	if err != nil {
+		return fmt.Errorf("unable to read message catalogs: %w", err)
+	}
+
+	catalogs = make(map[string]map[string]string, len(entries))
+	tags := []language.Tag{language.English}
+	for _, entry := range entries {
+		data, err := catalogFS.ReadFile(path.Join("catalog", entry.Name()))
+		if err != nil {
+			return fmt.Errorf("unable to read message catalog %s: %w", entry.Name(), err)
+		}
+
+		messages := make(map[string]string)
+		if err = json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("unable to parse message catalog %s: %w", entry.Name(), err)
+		}
+
+		tag := language.MustParse(strings.TrimSuffix(entry.Name(), ".json"))
+		catalogs[baseLanguage(tag)] = messages
+		tags = append(tags, tag)
+	}
+	matcher = language.NewMatcher(tags)
+	return nil
+}
+
+func baseLanguage(tag language.Tag) string {
+	base, _ := tag.Base()
+	return base.String()
+}
+
+// Negotiate returns the best supported language for the Accept-Language header value,
+// English when no supported language is accepted.
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return "en"
+	}
+	tag, _, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return "en"
+	}
+	return baseLanguage(tag)
+}
+
+// WithLanguage returns context copy with the language of user facing messages.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageCtxKey, lang)
+}
+
+// Language returns language of user facing messages, English when not set.
+func Language(ctx context.Context) string {
+	value := ctx.Value(languageCtxKey)
+	if value == nil {
+		return "en"
+	}
+	return value.(string)
+}
+
+// T translates a message into the language from the context, the message is returned as-is
+// when there is no translation.
+func T(ctx context.Context, msg string) string {
+	if translated, ok := catalogs[Language(ctx)][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Tf translates a format string and formats it with the arguments. String arguments are
+// translated too when found in the catalog.
+func Tf(ctx context.Context, format string, args ...any) string {
+	for i, arg := range args {
+		if str, ok := arg.(string); ok {
+			args[i] = T(ctx, str)
+		}
+	}
+	return fmt.Sprintf(T(ctx, format), args...)
+}
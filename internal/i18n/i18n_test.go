@@ -0,0 +1,28 @@
+package i18n_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, "en", i18n.Negotiate(""))
+	assert.Equal(t, "en", i18n.Negotiate("invalid;;"))
+	assert.Equal(t, "en", i18n.Negotiate("de-DE"))
+	assert.Equal(t, "es", i18n.Negotiate("es-MX"))
+	assert.Equal(t, "es", i18n.Negotiate("de;q=0.9, es;q=0.8, en;q=0.1"))
+	assert.Equal(t, "en", i18n.Negotiate("en-US, es;q=0.5"))
+}
+
+func TestTranslate(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "Invalid request: %s", i18n.T(ctx, "Invalid request: %s"))
+
+	ctx = i18n.WithLanguage(ctx, "es")
+	assert.Equal(t, "Solicitud no válida: %s", i18n.T(ctx, "Invalid request: %s"))
+	assert.Equal(t, "not in catalog", i18n.T(ctx, "not in catalog"))
+	assert.Equal(t, "Solicitud no válida: tipo de proveedor desconocido", i18n.Tf(ctx, "Invalid request: %s", "unknown provider type"))
+}
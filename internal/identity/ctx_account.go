@@ -9,6 +9,7 @@ type cxtKeyId int
 
 const (
 	accountIdCtxKey cxtKeyId = iota
+	machinePrincipalCtxKey
 )
 
 var MissingAccountInContextError = errors.New("operation requires account_id in context")
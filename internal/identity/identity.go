@@ -16,6 +16,15 @@ func Identity(ctx context.Context) Principal {
 	return identity.Get(ctx)
 }
 
+// OrgIdOrEmpty returns organization id of the identity or an empty string when identity is not set.
+func OrgIdOrEmpty(ctx context.Context) string {
+	id, ok := ctx.Value(identity.Key).(Principal)
+	if !ok {
+		return ""
+	}
+	return id.Identity.OrgID
+}
+
 // IdentityHeader returns identity header (base64-encoded JSON)
 func IdentityHeader(ctx context.Context) string {
 	return identity.GetIdentityHeader(ctx)
@@ -39,5 +48,63 @@ func WithIdentityFrom64(ctx context.Context, id string) (context.Context, error)
 		return nil, fmt.Errorf("could not unmarshal json %w", err)
 	}
 
-	return context.WithValue(ctx, identity.Key, jsonData), nil
+	machine, err := ParseMachinePrincipal(idRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	return WithMachine(context.WithValue(ctx, identity.Key, jsonData), machine), nil
+}
+
+// PrincipalName returns human-readable name of the caller: user name for users, e-mail for
+// associates, certificate subject for X509 identities and systems and user name or client ID
+// for service accounts. Identity type is returned for other identities, an empty string when
+// identity is not set.
+func PrincipalName(ctx context.Context) string {
+	id, ok := ctx.Value(identity.Key).(Principal)
+	if !ok {
+		return ""
+	}
+	if machine := Machine(ctx); machine != nil {
+		return machine.Name()
+	}
+
+	switch {
+	case id.Identity.User.Username != "":
+		return id.Identity.User.Username
+	case id.Identity.Associate.Email != "":
+		return id.Identity.Associate.Email
+	case id.Identity.X509.SubjectDN != "":
+		return id.Identity.X509.SubjectDN
+	default:
+		return id.Identity.Type
+	}
+}
+
+// Owner returns the owner of resources created by the caller: user name for users and client ID
+// for service accounts, reservations and pubkeys are owned by the principal who created them.
+// An empty string is returned for all other identities, their resources belong to the whole
+// organization and they can only access resources which are shared or have no owner.
+func Owner(ctx context.Context) string {
+	id, ok := ctx.Value(identity.Key).(Principal)
+	if !ok {
+		return ""
+	}
+	if machine := Machine(ctx); machine != nil {
+		if machine.Type == ServiceAccountPrincipal {
+			return machine.ClientID
+		}
+		return ""
+	}
+	return id.Identity.User.Username
+}
+
+// WithIdentityOf returns context copy with identity and machine principal of the source context,
+// the context is returned unchanged when the source has no identity.
+func WithIdentityOf(ctx, source context.Context) context.Context {
+	id, ok := source.Value(identity.Key).(Principal)
+	if !ok {
+		return ctx
+	}
+	return WithMachine(WithIdentity(ctx, id), Machine(source))
 }
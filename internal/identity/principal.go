@@ -0,0 +1,115 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PrincipalType is the type of the identity header.
+type PrincipalType string
+
+const (
+	UserPrincipal           PrincipalType = "User"
+	SystemPrincipal         PrincipalType = "System"
+	ServiceAccountPrincipal PrincipalType = "ServiceAccount"
+	AssociatePrincipal      PrincipalType = "Associate"
+	X509Principal           PrincipalType = "X509"
+)
+
+var (
+	MissingCommonNameErr = errors.New("X-Rh-Identity header of a system is missing cn")
+	MissingClientIdErr   = errors.New("X-Rh-Identity header of a service account is missing client_id")
+)
+
+// MachinePrincipal is a non-user caller: a system authenticated via certificate (cert-auth)
+// or a service account authenticated via API token.
+type MachinePrincipal struct {
+	Type PrincipalType
+
+	// CommonName is the certificate subject of a system
+	CommonName string
+
+	// CertType is the certificate type of a system (e.g. "satellite" or "system")
+	CertType string
+
+	// ClientID is the client of a service account
+	ClientID string
+
+	// Username is the name of a service account
+	Username string
+}
+
+// Name returns human-readable name of the principal.
+func (p *MachinePrincipal) Name() string {
+	switch {
+	case p.Username != "":
+		return p.Username
+	case p.ClientID != "":
+		return p.ClientID
+	default:
+		return p.CommonName
+	}
+}
+
+// machineIdentity are identity header fields not available in the platform identity struct
+type machineIdentity struct {
+	Identity struct {
+		Type   PrincipalType `json:"type"`
+		System struct {
+			CommonName string `json:"cn"`
+			CertType   string `json:"cert_type"`
+		} `json:"system"`
+		ServiceAccount struct {
+			ClientID string `json:"client_id"`
+			Username string `json:"username"`
+		} `json:"service_account"`
+	} `json:"identity"`
+}
+
+// ParseMachinePrincipal returns the machine principal from identity header JSON, nil is
+// returned for all other principal types.
+func ParseMachinePrincipal(idRaw []byte) (*MachinePrincipal, error) {
+	var id machineIdentity
+	if err := json.Unmarshal(idRaw, &id); err != nil {
+		return nil, fmt.Errorf("could not unmarshal json %w", err)
+	}
+
+	switch id.Identity.Type {
+	case SystemPrincipal:
+		if id.Identity.System.CommonName == "" {
+			return nil, MissingCommonNameErr
+		}
+		return &MachinePrincipal{
+			Type:       SystemPrincipal,
+			CommonName: id.Identity.System.CommonName,
+			CertType:   id.Identity.System.CertType,
+		}, nil
+	case ServiceAccountPrincipal:
+		if id.Identity.ServiceAccount.ClientID == "" {
+			return nil, MissingClientIdErr
+		}
+		return &MachinePrincipal{
+			Type:     ServiceAccountPrincipal,
+			ClientID: id.Identity.ServiceAccount.ClientID,
+			Username: id.Identity.ServiceAccount.Username,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Machine returns the machine principal or nil when the caller is not a system or a service account.
+func Machine(ctx context.Context) *MachinePrincipal {
+	value, _ := ctx.Value(machinePrincipalCtxKey).(*MachinePrincipal)
+	return value
+}
+
+// WithMachine returns context copy with the machine principal, nil principal is not stored.
+func WithMachine(ctx context.Context, principal *MachinePrincipal) context.Context {
+	if principal == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, machinePrincipalCtxKey, principal)
+}
@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+var ErrReservationCancelled = errors.New("reservation cancelled by user")
+
+// checkCancelled returns ErrReservationCancelled when user requested cancellation of the
+// reservation. Errors from the database are logged and ignored, the job continues.
+func checkCancelled(ctx context.Context, reservationId int64) error {
+	requested, err := dao.GetReservationDao(ctx).UnscopedIsCancelRequested(ctx, reservationId)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to check reservation cancel flag")
+		return nil
+	}
+	if requested {
+		return ErrReservationCancelled
+	}
+	return nil
+}
+
+// cancelWatcher periodically checks the reservation cancel flag and cancels the context
+// returned by watchCancel, so long-running cloud calls are interrupted.
+type cancelWatcher struct {
+	reservationId int64
+	cancelled     atomic.Bool
+	cancel        context.CancelFunc
+}
+
+// watchCancel starts a goroutine watching the reservation cancel flag, call Stop when the
+// job is done.
+func watchCancel(ctx context.Context, reservationId int64) (context.Context, *cancelWatcher) {
+	wCtx, cancel := context.WithCancel(ctx)
+	w := &cancelWatcher{reservationId: reservationId, cancel: cancel}
+
+	if config.Worker.CancelPoll > 0 {
+		go w.loop(wCtx)
+	}
+	return wCtx, w
+}
+
+func (w *cancelWatcher) loop(ctx context.Context) {
+	ticker := time.NewTicker(config.Worker.CancelPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if errors.Is(checkCancelled(ctx, w.reservationId), ErrReservationCancelled) {
+				zerolog.Ctx(ctx).Info().Msg("Reservation cancel requested, interrupting job")
+				w.cancelled.Store(true)
+				w.cancel()
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop terminates the watching goroutine and releases the context.
+func (w *cancelWatcher) Stop() {
+	w.cancel()
+}
+
+// runStep checks the cancel flag and runs a job step. It returns ErrReservationCancelled
+// when cancellation was requested before the step or the step was interrupted by the watcher.
+func (w *cancelWatcher) runStep(ctx context.Context, step func() error) error {
+	if err := checkCancelled(ctx, w.reservationId); err != nil {
+		return err
+	}
+
+	err := step()
+	if w.cancelled.Load() {
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrReservationCancelled, err.Error())
+		}
+		return ErrReservationCancelled
+	}
+	return err
+}
+
+// finishWithCancel closes a reservation and sets it into the cancelled state.
+func finishWithCancel(ctx context.Context, reservationId int64, jobError error) {
+	logger := zerolog.Ctx(ctx)
+	if ctx.Err() != nil {
+		// the original context is cancelled and unusable at this point
+		ctx = copyContext(ctx)
+	}
+
+	rDao := dao.GetReservationDao(ctx)
+	reservation, err := rDao.GetById(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to update job status: get by id")
+		return
+	}
+	logger.Info().Msgf("Reservation for %s was cancelled", reservation.Provider.String())
+
+	// total count of reservations
+	metrics.IncReservationCount(reservation.Provider.String(), "cancelled")
+
+	err = rDao.FinishWithCancel(ctx, reservationId, jobError.Error())
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to update job status: finish")
+	}
+}
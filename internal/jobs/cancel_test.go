@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareCancelReservation(t *testing.T) (context.Context, int64) {
+	t.Helper()
+
+	ctx := daoStubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = daoStubs.WithReservationDao(ctx)
+
+	reservation := &models.AWSReservation{Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeAWS
+	require.NoError(t, daoStubs.AddAWSReservation(ctx, reservation))
+	return ctx, reservation.ID
+}
+
+func TestRunStepCancelledBefore(t *testing.T) {
+	ctx, id := prepareCancelReservation(t)
+	require.NoError(t, dao.GetReservationDao(ctx).RequestCancel(ctx, id))
+
+	ctx, watcher := watchCancel(ctx, id)
+	defer watcher.Stop()
+
+	called := false
+	err := watcher.runStep(ctx, func() error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrReservationCancelled)
+	assert.False(t, called, "step must not run after cancel was requested")
+}
+
+func TestRunStepCancelledDuring(t *testing.T) {
+	original := config.Worker.CancelPoll
+	config.Worker.CancelPoll = time.Millisecond
+	t.Cleanup(func() { config.Worker.CancelPoll = original })
+
+	ctx, id := prepareCancelReservation(t)
+	wCtx, watcher := watchCancel(ctx, id)
+	defer watcher.Stop()
+
+	err := watcher.runStep(wCtx, func() error {
+		require.NoError(t, dao.GetReservationDao(ctx).RequestCancel(ctx, id))
+		return sleepCtx(wCtx, 10*time.Second)
+	})
+	assert.ErrorIs(t, err, ErrReservationCancelled)
+	assert.True(t, errors.Is(wCtx.Err(), context.Canceled))
+
+	finishWithCancel(ctx, id, err)
+	reservation, err := dao.GetReservationDao(ctx).GetById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReservationStatusCancelled, reservation.Status)
+	assert.True(t, reservation.Success.Valid)
+	assert.False(t, reservation.Success.Bool)
+	assert.True(t, reservation.FinishedAt.Valid)
+}
+
+func TestRunStepNotCancelled(t *testing.T) {
+	ctx, id := prepareCancelReservation(t)
+	ctx, watcher := watchCancel(ctx, id)
+	defer watcher.Stop()
+
+	errStep := errors.New("step error")
+	err := watcher.runStep(ctx, func() error { return errStep })
+	assert.ErrorIs(t, err, errStep)
+	assert.NotErrorIs(t, err, ErrReservationCancelled)
+}
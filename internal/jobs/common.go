@@ -6,9 +6,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/RHEnVision/provisioning-backend/internal/webhooks"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/rs/zerolog"
 )
 
@@ -16,6 +23,28 @@ const TraceName = telemetry.TracePrefix + "internal/jobs"
 
 var ErrTypeAssertion = errors.New("type assert error")
 
+var ErrFeatureDisabled = errors.New("feature disabled")
+
+// retryPending returns true when the job failed but the worker is going to attempt it again
+// according to the retry policy. Reservation must not be finished in that case.
+func retryPending(ctx context.Context, job *worker.Job, jobErr error) bool {
+	if jobErr == nil || job.LastAttempt() {
+		return false
+	}
+	zerolog.Ctx(ctx).Warn().Err(jobErr).Msgf("Job attempt %d of %d failed, reservation kept pending", job.Attempt, job.MaxAttempts)
+	return true
+}
+
+// featureDisabled fails the reservation when the feature flag was disabled after the job was
+// enqueued. The job must not be retried in that case.
+func featureDisabled(ctx context.Context, flag featureflags.Flag, reservationId int64) bool {
+	if featureflags.Enabled(ctx, flag) {
+		return false
+	}
+	finishWithError(ctx, reservationId, fmt.Errorf("%w: %s", ErrFeatureDisabled, flag.Name()))
+	return true
+}
+
 func finishJob(ctx context.Context, reservationId int64, jobErr error) {
 	if jobErr != nil {
 		finishWithError(ctx, reservationId, jobErr)
@@ -42,22 +71,28 @@ func finishWithSuccess(ctx context.Context, reservationId int64) {
 	// total count of reservations
 	metrics.IncReservationCount(reservation.Provider.String(), "success")
 
-	// if this was the last step, set the success flag
+	// if this was the last step, set the success flag and notify about the launch
 	if reservation.Step >= reservation.Steps {
 		logger.Info().Msgf("All jobs executed, marking job as success")
+		outbox := outboxMessages(notifications.GetNotificationClient(ctx).SuccessfulLaunchMessage(ctx, reservationId))
+		outbox = append(outbox, webhookMessages(ctx, reservation, models.WebhookEventReservationSuccess, nil, nil)...)
 
-		err = rDao.FinishWithSuccess(ctx, reservationId)
+		err = rDao.FinishWithSuccess(ctx, reservationId, outbox...)
 		if err != nil {
 			logger.Warn().Err(err).Msg("unable to update job status: finish")
+			return
 		}
+		enqueueSSHProbe(ctx, reservationId)
 	}
 }
 
 // finishWithError closes a reservation and sets it into error state. Error message is also
-// stored into the reservation.
+// stored into the reservation, the failure notification is stored into the outbox in the same
+// transaction.
 func finishWithError(ctx context.Context, reservationId int64, jobError error) {
 	logger := zerolog.Ctx(ctx)
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		jobError = timeoutError(jobError)
 		// the original context is expired and unusable at this point
 		ctx = copyContext(ctx)
 	}
@@ -72,13 +107,76 @@ func finishWithError(ctx context.Context, reservationId int64, jobError error) {
 
 	// total count of reservations
 	metrics.IncReservationCount(reservation.Provider.String(), "failure")
+	metrics.IncLaunchFailures(reservation.Provider, reservation.RunningStepTitle())
 
-	err = rDao.FinishWithError(ctx, reservationId, jobError.Error())
+	outbox := outboxMessages(notifications.GetNotificationClient(ctx).FailedLaunchMessage(ctx, reservationId, jobError))
+	outbox = append(outbox, webhookMessages(ctx, reservation, models.WebhookEventReservationFailure, nil, jobError)...)
+	err = rDao.FinishWithError(ctx, reservationId, jobError.Error(), outbox...)
 	if err != nil {
 		logger.Warn().Err(err).Msg("unable to update job status: finish")
 	}
 }
 
+// outboxMessages converts notifications into outbox messages, nil messages are skipped.
+func outboxMessages(messages ...*kafka.GenericMessage) []*models.OutboxMessage {
+	result := make([]*models.OutboxMessage, 0, len(messages))
+	for _, m := range messages {
+		if m != nil {
+			result = append(result, m.OutboxMessage())
+		}
+	}
+	return result
+}
+
+// webhookMessages returns a webhook event of the reservation as outbox messages, nothing is
+// returned when webhooks are disabled. Instances of the reservation are listed when no instance
+// IDs are passed.
+func webhookMessages(ctx context.Context, reservation *models.Reservation, event models.WebhookEvent, instanceIds []string, jobError error) []*models.OutboxMessage {
+	if !config.Webhooks.Enabled {
+		return nil
+	}
+	logger := zerolog.Ctx(ctx)
+
+	data := webhooks.ReservationData{
+		ReservationID: reservation.ID,
+		Provider:      reservation.Provider.String(),
+		Instances:     instanceIds,
+	}
+	if jobError != nil {
+		data.Error = jobError.Error()
+	}
+	if instanceIds == nil {
+		instances, err := dao.GetReservationDao(ctx).ListInstances(ctx, reservation.ID)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Unable to get instances for webhook event")
+		}
+		for _, instance := range instances {
+			data.Instances = append(data.Instances, instance.InstanceID)
+		}
+	}
+
+	m, err := webhooks.EventMessage(reservation.AccountID, event, data)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to create webhook event")
+		return nil
+	}
+	return []*models.OutboxMessage{m}
+}
+
+// enqueueTerminatedEvent stores the instance.terminated webhook event of instances which were
+// terminated outside the reservation state change.
+func enqueueTerminatedEvent(ctx context.Context, reservation *models.Reservation, instanceIds []string) {
+	outbox := webhookMessages(ctx, reservation, models.WebhookEventInstanceTerminated, instanceIds, nil)
+	if len(outbox) == 0 {
+		return
+	}
+
+	err := dao.GetOutboxDao(ctx).UnscopedEnqueue(ctx, outbox...)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("unable to enqueue webhook event")
+	}
+}
+
 // updateStatusBefore is called after every step function within a job. It updates reservation status
 // message.
 func updateStatusBefore(ctx context.Context, id int64, status string) {
@@ -93,6 +191,9 @@ func updateStatusAfter(ctx context.Context, id int64, status string, addSteps in
 		status = "Timeout"
 		// the original context is expired and unusable at this point
 		ctx = copyContext(ctx)
+	} else if ctx.Err() != nil {
+		// the original context was cancelled on user request
+		ctx = copyContext(ctx)
 	}
 
 	logger.Debug().Bool("step", true).Msgf("Reservation status change: '%s'", status)
@@ -110,11 +211,20 @@ func updateStatusAfter(ctx context.Context, id int64, status string, addSteps in
 
 func nilUnlessTimeout(ctx context.Context) error {
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-		return fmt.Errorf("context timeout: %w", ctx.Err())
+		return fmt.Errorf("%w: %s", worker.ErrJobTimeout, ctx.Err().Error())
 	}
 	return nil
 }
 
+// timeoutError wraps an error of a job which exceeded its timeout, so the reservation error
+// always starts with the job timeout error message.
+func timeoutError(jobError error) error {
+	if errors.Is(jobError, worker.ErrJobTimeout) {
+		return jobError
+	}
+	return fmt.Errorf("%w: %s", worker.ErrJobTimeout, jobError.Error())
+}
+
 // sleep with context deadline
 //
 //nolint:wrapcheck
@@ -2,9 +2,17 @@ package jobs
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/webhooks"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +27,52 @@ func TestSleepCtxDeadline(t *testing.T) {
 	err := sleepCtx(ctx, 500*time.Microsecond)
 	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
+
+func TestNilUnlessTimeout(t *testing.T) {
+	require.NoError(t, nilUnlessTimeout(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	require.ErrorIs(t, nilUnlessTimeout(ctx), worker.ErrJobTimeout)
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := timeoutError(errors.New("cannot run instances"))
+	require.ErrorIs(t, err, worker.ErrJobTimeout)
+	require.Equal(t, "job timed out: cannot run instances", err.Error())
+
+	require.Equal(t, err, timeoutError(err), "timeout errors must not be wrapped twice")
+}
+
+func TestWebhookMessages(t *testing.T) {
+	ctx, id := prepareCancelReservation(t)
+	require.NoError(t, dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{ReservationID: id, InstanceID: "i-1"}))
+	reservation, err := dao.GetReservationDao(ctx).GetById(ctx, id)
+	require.NoError(t, err)
+
+	require.Empty(t, webhookMessages(ctx, reservation, models.WebhookEventReservationSuccess, nil, nil), "webhooks are disabled")
+
+	config.Webhooks.Enabled = true
+	t.Cleanup(func() { config.Webhooks.Enabled = false })
+
+	t.Run("success", func(t *testing.T) {
+		messages := webhookMessages(ctx, reservation, models.WebhookEventReservationSuccess, nil, nil)
+		require.Len(t, messages, 1)
+		assert.Equal(t, webhooks.Topic, messages[0].Topic)
+
+		var event struct {
+			Event models.WebhookEvent      `json:"event"`
+			Data  webhooks.ReservationData `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(messages[0].Value, &event))
+		assert.Equal(t, models.WebhookEventReservationSuccess, event.Event)
+		assert.Equal(t, webhooks.ReservationData{ReservationID: id, Provider: "aws", Instances: []string{"i-1"}}, event.Data)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		messages := webhookMessages(ctx, reservation, models.WebhookEventReservationFailure, nil, errors.New("boom"))
+		require.Len(t, messages, 1)
+		assert.Contains(t, string(messages[0].Value), `"error":"boom"`)
+	})
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+type compensationCtxKeyType int
+
+const compensationCtxKey compensationCtxKeyType = iota
+
+type compensationAction struct {
+	name string
+	undo func(ctx context.Context) error
+}
+
+// compensation is a saga-style registry of undo actions. Job steps register an action for
+// each side effect (e.g. uploaded key, launched instance) and when a later step of the chain
+// fails, all actions are executed in reverse order.
+type compensation struct {
+	mu      sync.Mutex
+	actions []compensationAction
+}
+
+// withCompensation returns a context with a new compensation registry.
+func withCompensation(ctx context.Context) (context.Context, *compensation) {
+	c := &compensation{}
+	return context.WithValue(ctx, compensationCtxKey, c), c
+}
+
+// registerCompensation registers an undo action of a side effect performed by a job step.
+// Steps called without a registry (e.g. in tests) do not register anything.
+func registerCompensation(ctx context.Context, name string, undo func(ctx context.Context) error) {
+	c, ok := ctx.Value(compensationCtxKey).(*compensation)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.actions = append(c.actions, compensationAction{name: name, undo: undo})
+}
+
+// Commit discards all registered actions, side effects are kept from this point.
+func (c *compensation) Commit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.actions = nil
+}
+
+// Compensate executes registered actions in reverse order. Failing actions are logged and
+// the remaining actions are still executed.
+func (c *compensation) Compensate(ctx context.Context) {
+	c.mu.Lock()
+	actions := c.actions
+	c.actions = nil
+	c.mu.Unlock()
+
+	if len(actions) == 0 {
+		return
+	}
+
+	logger := zerolog.Ctx(ctx)
+	if ctx.Err() != nil {
+		// the original context is cancelled and unusable at this point
+		ctx = copyContext(ctx)
+	}
+
+	for i := len(actions) - 1; i >= 0; i-- {
+		logger.Info().Msgf("Compensating job step: %s", actions[i].name)
+		if err := actions[i].undo(ctx); err != nil {
+			logger.Error().Err(err).Msgf("Unable to compensate job step: %s", actions[i].name)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUndo = errors.New("undo failed")
+
+func TestCompensateReverseOrder(t *testing.T) {
+	ctx, comp := withCompensation(context.Background())
+
+	var undone []string
+	record := func(name string, err error) func(context.Context) error {
+		return func(_ context.Context) error {
+			undone = append(undone, name)
+			return err
+		}
+	}
+	registerCompensation(ctx, "first", record("first", nil))
+	registerCompensation(ctx, "second", record("second", errUndo))
+	registerCompensation(ctx, "third", record("third", nil))
+
+	comp.Compensate(ctx)
+	assert.Equal(t, []string{"third", "second", "first"}, undone)
+
+	// actions are executed only once
+	comp.Compensate(ctx)
+	assert.Len(t, undone, 3)
+}
+
+func TestCompensateCommitted(t *testing.T) {
+	ctx, comp := withCompensation(context.Background())
+
+	called := false
+	registerCompensation(ctx, "step", func(_ context.Context) error {
+		called = true
+		return nil
+	})
+	comp.Commit()
+	comp.Compensate(ctx)
+	assert.False(t, called)
+}
+
+func TestRegisterCompensationWithoutRegistry(t *testing.T) {
+	assert.NotPanics(t, func() {
+		registerCompensation(context.Background(), "step", func(_ context.Context) error { return nil })
+	})
+}
+
+func TestCompensateUploadedPubkeyAWS(t *testing.T) {
+	ctx := daoStubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+	ctx = daoStubs.WithReservationDao(ctx)
+	ctx = daoStubs.WithPubkeyDao(ctx)
+	ctx, comp := withCompensation(ctx)
+
+	pk := &models.Pubkey{
+		Name: factories.SeqNameWithPrefix("pubkey"),
+		Body: factories.GenerateRSAPubKey(t),
+	}
+	require.NoError(t, daoStubs.AddPubkey(ctx, pk))
+
+	reservation := &models.AWSReservation{
+		PubkeyID: pk.ID,
+		SourceID: "irrelevant",
+		ImageID:  "irrelevant",
+		Detail:   &models.AWSDetail{Region: "us-east-1", InstanceType: "t1.micro", Amount: 1},
+	}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeAWS
+	require.NoError(t, dao.GetReservationDao(ctx).CreateAWS(ctx, reservation))
+
+	args := &LaunchInstanceAWSTaskArgs{
+		ReservationID: reservation.ID,
+		Region:        reservation.Detail.Region,
+		PubkeyID:      pk.ID,
+		SourceID:      reservation.SourceID,
+		Detail:        reservation.Detail,
+		ARN:           &clients.Authentication{ProviderType: models.ProviderTypeAWS, Payload: "arn:aws:123123123123"},
+	}
+	require.NoError(t, DoEnsurePubkeyOnAWS(ctx, args))
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	resources, err := pkDao.UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+
+	comp.Compensate(ctx)
+
+	resources, err = pkDao.UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+
+	ec2Client, err := clients.GetEC2Client(ctx, args.ARN, args.Region)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key-0"}, ec2Client.(*clientStubs.EC2ClientStub).Deleted)
+}
@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 
+	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/rs/zerolog/log"
@@ -16,5 +17,7 @@ func copyContext(ctx context.Context) context.Context {
 	nCtx = logging.WithTraceId(nCtx, logging.TraceId(ctx))
 	nCtx = logging.WithEdgeRequestId(nCtx, logging.EdgeRequestId(ctx))
 	nCtx = identity.WithAccountId(nCtx, identity.AccountId(ctx))
+	nCtx = identity.WithIdentityOf(nCtx, ctx)
+	nCtx = db.WithPrimary(nCtx)
 	return nCtx
 }
@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+type DeletePubkeyResourceTaskArgs struct {
+	// Associated pubkey, it is deleted once all its resources are gone
+	PubkeyID int64
+
+	// Pubkey resource to delete
+	ResourceID int64
+
+	// Provider of the resource
+	Provider models.ProviderType
+
+	// Source ID of the resource
+	SourceID string
+
+	// Region the key-pair was uploaded to
+	Region string
+
+	// Provider-dependant handle of the key-pair
+	Handle string
+}
+
+// Unmarshall arguments and handle error
+func HandleDeletePubkeyResource(ctx context.Context, job *worker.Job) error {
+	args, ok := job.Args.(DeletePubkeyResourceTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, args: %#v", ErrTypeAssertion, job.ID, job.Args)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	logger := zerolog.Ctx(ctx).With().
+		Int64("pubkey_id", args.PubkeyID).
+		Int64("pubkey_resource_id", args.ResourceID).
+		Str("region", args.Region).
+		Logger()
+	ctx = logger.WithContext(ctx)
+
+	jobErr := DoDeletePubkeyResource(ctx, &args)
+	if jobErr != nil {
+		logger.Error().Err(jobErr).Msg("Unable to delete pubkey resource, pubkey was not deleted")
+	}
+	return jobErr
+}
+
+// DoDeletePubkeyResource removes the key-pair from the cloud provider and deletes the resource
+// record. When this was the last resource of the pubkey, the pubkey itself is deleted. When
+// deletion fails, both the pubkey and the resource are kept so no cloud key-pair is orphaned.
+func DoDeletePubkeyResource(ctx context.Context, args *DeletePubkeyResourceTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started delete pubkey resource job")
+
+	switch args.Provider {
+	case models.ProviderTypeAWS:
+		err := deletePubkeyResourceAWS(ctx, args)
+		if err != nil {
+			return err
+		}
+	case models.ProviderTypeAzure, models.ProviderTypeGCP:
+		// keys are passed inline during launch, there is no key-pair resource to delete
+		logger.Debug().Msgf("No key-pair to delete for provider %s", args.Provider)
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+	}
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	err := pkDao.UnscopedDeleteResource(ctx, args.ResourceID)
+	if err != nil && !errors.Is(err, dao.ErrAffectedMismatch) {
+		return fmt.Errorf("unable to delete pubkey resource: %w", err)
+	}
+
+	remaining, err := pkDao.UnscopedListResourcesByPubkeyId(ctx, args.PubkeyID)
+	if err != nil {
+		return fmt.Errorf("unable to list remaining pubkey resources: %w", err)
+	}
+	if len(remaining) > 0 {
+		logger.Debug().Msgf("Pubkey has %d resources left, not deleting it yet", len(remaining))
+		return nil
+	}
+
+	// another job might have been faster deleting the pubkey
+	err = pkDao.Delete(ctx, args.PubkeyID)
+	if err != nil && !errors.Is(err, dao.ErrAffectedMismatch) {
+		return fmt.Errorf("unable to delete pubkey: %w", err)
+	}
+	logger.Info().Msg("Deleted pubkey including all its resources")
+
+	return nil
+}
+
+func deletePubkeyResourceAWS(ctx context.Context, args *DeletePubkeyResourceTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+
+	if args.Handle == "" {
+		logger.Warn().Msg("Skipping pubkey resource with empty handle")
+		return nil
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get sources client: %w", err)
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(ctx, args.SourceID)
+	if err != nil {
+		if errors.Is(err, http.AuthenticationForSourcesNotFoundErr) {
+			logger.Warn().Msgf("Skipping source %s authorization which is no longer available", args.SourceID)
+			return nil
+		}
+		return fmt.Errorf("unable to get authentication from sources: %w", err)
+	}
+
+	ec2Client, err := clients.GetEC2Client(ctx, authentication, args.Region)
+	if err != nil {
+		return fmt.Errorf("unable to get AWS EC2 client: %w", err)
+	}
+
+	logger.Info().Msgf("Deleting AWS key-pair with handle %s", args.Handle)
+	err = ec2Client.DeleteSSHKey(ctx, args.Handle)
+	if err != nil {
+		return fmt.Errorf("unable to delete AWS key-pair: %w", err)
+	}
+
+	return nil
+}
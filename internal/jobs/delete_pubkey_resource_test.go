@@ -0,0 +1,69 @@
+package jobs_test
+
+import (
+	"testing"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoDeletePubkeyResource(t *testing.T) {
+	ctx := prepareEC2Context(t)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	pkDao := dao.GetPubkeyDao(ctx)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err)
+
+	resources := make([]*models.PubkeyResource, 0, 2)
+	for _, region := range []string{"us-east-1", "eu-central-1"} {
+		pkr := &models.PubkeyResource{
+			PubkeyID: pk.ID,
+			Provider: models.ProviderTypeAWS,
+			SourceID: "1",
+			Handle:   "key-" + region,
+			Region:   region,
+		}
+		err = pkDao.UnscopedCreateResource(ctx, pkr)
+		require.NoError(t, err)
+		resources = append(resources, pkr)
+	}
+
+	t.Run("keeps pubkey until last resource is deleted", func(t *testing.T) {
+		err = jobs.DoDeletePubkeyResource(ctx, argsForResource(resources[0]))
+		require.NoError(t, err)
+
+		_, err = pkDao.GetById(ctx, pk.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deletes pubkey with the last resource", func(t *testing.T) {
+		err = jobs.DoDeletePubkeyResource(ctx, argsForResource(resources[1]))
+		require.NoError(t, err)
+
+		left, listErr := pkDao.UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+		require.NoError(t, listErr)
+		assert.Empty(t, left)
+
+		_, err = pkDao.GetById(ctx, pk.ID)
+		assert.ErrorIs(t, err, dao.ErrNoRows)
+	})
+}
+
+func argsForResource(pkr *models.PubkeyResource) *jobs.DeletePubkeyResourceTaskArgs {
+	return &jobs.DeletePubkeyResourceTaskArgs{
+		PubkeyID:   pkr.PubkeyID,
+		ResourceID: pkr.ID,
+		Provider:   pkr.Provider,
+		SourceID:   pkr.SourceID,
+		Region:     pkr.Region,
+		Handle:     pkr.Handle,
+	}
+}
@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/userdata"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Dry runs validate the launch of a reservation which was not created, instances are launched with
+// the same parameters as the launch jobs use. The reservation ID is zero in the standard tags.
+
+// DryRunLaunchAWS validates the launch of AWS instances. The public key is not uploaded yet, it is
+// not part of the validation. Fleet launches are validated with the first instance type.
+func DryRunLaunchAWS(ctx context.Context, ec2Client clients.EC2, detail *models.AWSDetail, ami string) (*clients.DryRunResult, error) {
+	userData, err := userdata.GenerateUserData(&userdata.UserData{
+		Type:         models.ProviderTypeAWS,
+		PowerOff:     detail.PowerOff,
+		InsightsTags: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate user data: %w", err)
+	}
+
+	instanceType := detail.InstanceType
+	if len(detail.InstanceTypes) > 0 {
+		instanceType = detail.InstanceTypes[0]
+	}
+	req := &clients.AWSInstanceParams{
+		LaunchTemplateID: detail.LaunchTemplateID,
+		InstanceType:     types.InstanceType(instanceType),
+		AMI:              ami,
+		UserData:         userData,
+		Tenancy:          detail.Tenancy,
+		HostID:           detail.HostID,
+		MetadataOptions:  detail.MetadataOptions,
+		Tags:             models.ResourceTags(detail.Tags, 0, identity.OrgIdOrEmpty(ctx)),
+	}
+
+	result, err := ec2Client.DryRunInstances(ctx, req, detail.Amount, detail.Name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dry run instances: %w", err)
+	}
+	return result, nil
+}
+
+// DryRunLaunchAzure validates the deployment of Azure VMs into the resource group of the strategy,
+// resource group of the reservation strategy does not exist and its creation is validated too.
+func DryRunLaunchAzure(ctx context.Context, azureClient clients.Azure, detail *models.AzureDetail, imageID string, pubkey *models.Pubkey) (*clients.DryRunResult, error) {
+	userData, err := userdata.GenerateUserData(&userdata.UserData{
+		Type:         models.ProviderTypeAzure,
+		PowerOff:     detail.PowerOff,
+		InsightsTags: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate user data: %w", err)
+	}
+
+	var rgName string
+	switch detail.ResourceGroupStrategy {
+	case models.AzureResourceGroupExisting:
+		rgName = detail.ResourceGroup
+	case models.AzureResourceGroupReservation:
+		rgName = fmt.Sprintf(config.Application.AzureResourceGroup.Pattern, 0)
+	default:
+		rgName = resourceGroupName
+	}
+
+	vmParams := clients.AzureInstanceParams{
+		Location:          location,
+		ResourceGroupName: rgName,
+		ImageID:           imageID,
+		Pubkey:            pubkey,
+		InstanceType:      clients.InstanceTypeName(detail.InstanceSize),
+		UserData:          userData,
+		Priority:          detail.Priority,
+		MaxPrice:          detail.MaxPrice,
+		EvictionPolicy:    detail.EvictionPolicy,
+		Tags:              models.ResourceTags(detail.Tags, 0, identity.OrgIdOrEmpty(ctx)),
+	}
+
+	result, err := azureClient.ValidateVMs(ctx, vmParams, detail.Amount, vmNamePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("cannot validate Azure instances: %w", err)
+	}
+	return result, nil
+}
+
+// DryRunLaunchGCP validates the launch of GCP instances.
+func DryRunLaunchGCP(ctx context.Context, gcpClient clients.GCP, detail *models.GCPDetail, imageName string, pubkey *models.Pubkey) (*clients.DryRunResult, error) {
+	userData, err := userdata.GenerateUserData(&userdata.UserData{
+		Type:         models.ProviderTypeGCP,
+		PowerOff:     detail.PowerOff,
+		InsightsTags: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate user data: %w", err)
+	}
+
+	name := "inst-####"
+	if detail.NamePattern != nil {
+		name = fmt.Sprintf("%s-#####", *detail.NamePattern)
+	}
+	params := &clients.GCPInstanceParams{
+		NamePattern:        ptr.To(name),
+		ImageName:          imageName,
+		MachineType:        detail.MachineType,
+		Zone:               detail.Zone,
+		KeyBody:            pubkey.Body,
+		StartupScript:      string(userData),
+		UUID:               detail.UUID,
+		LaunchTemplateName: detail.LaunchTemplateName,
+		ProvisioningModel:  detail.ProvisioningModel,
+		TerminationAction:  detail.TerminationAction,
+		ServiceAccount:     detail.ServiceAccount,
+		Scopes:             detail.Scopes,
+		Network:            detail.Network,
+		Subnetwork:         detail.Subnetwork,
+		Tags:               models.ResourceTags(detail.Tags, 0, identity.OrgIdOrEmpty(ctx)),
+	}
+
+	result, err := gcpClient.DryRunInstances(ctx, params, detail.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dry run GCP instances: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// StoreFailedJob is a dead-letter handler which persists jobs failed on the last attempt so
+// operators can replay them via the admin API. Failures are also reported to Sentry when enabled.
+func StoreFailedJob(ctx context.Context, job *worker.Job, jobErr error) {
+	logger := zerolog.Ctx(ctx)
+	logging.ReportError(ctx, jobErr, map[string]string{
+		"job_id":     job.ID.String(),
+		"job_type":   job.Type.String(),
+		"account_id": strconv.FormatInt(job.AccountID, 10),
+	})
+
+	payload, err := worker.EncodeJob(job)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to encode failed job")
+		return
+	}
+
+	args, err := json.Marshal(job.Args)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to marshal failed job arguments")
+		args = []byte("{}")
+	}
+
+	failedJob := &models.FailedJob{
+		JobID:     job.ID.String(),
+		JobType:   job.Type.String(),
+		AccountID: job.AccountID,
+		Args:      args,
+		Payload:   payload,
+		Attempts:  job.Attempt,
+	}
+	if jobErr != nil {
+		failedJob.Error = jobErr.Error()
+	}
+
+	err = dao.GetFailedJobDao(ctx).UnscopedCreate(ctx, failedJob)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to store failed job")
+		return
+	}
+	logger.Info().Int64("failed_job_id", failedJob.ID).Msg("Failed job stored for replay")
+}
@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+var ErrUnknownInstanceAction = errors.New("unknown instance action")
+
+type InstanceActionTaskArgs struct {
+	// Tracked instance action
+	ActionID int64
+
+	// Associated reservation
+	ReservationID int64
+
+	// Instance ID of the cloud provider, full resource ID for Azure
+	InstanceID string
+
+	// Action to perform
+	Action models.InstanceActionType
+
+	// Source of the reservation
+	SourceID string
+
+	// Region (AWS) or zone (GCP) of the instance, not used for Azure
+	Location string
+}
+
+func HandleInstanceActionAWS(ctx context.Context, job *worker.Job) error {
+	return handleInstanceAction(ctx, job, DoInstanceActionAWS)
+}
+
+func HandleInstanceActionAzure(ctx context.Context, job *worker.Job) error {
+	return handleInstanceAction(ctx, job, DoInstanceActionAzure)
+}
+
+func HandleInstanceActionGCP(ctx context.Context, job *worker.Job) error {
+	return handleInstanceAction(ctx, job, DoInstanceActionGCP)
+}
+
+// Unmarshall arguments and handle error
+func handleInstanceAction(ctx context.Context, job *worker.Job, do func(context.Context, *InstanceActionTaskArgs) error) error {
+	args, ok := job.Args.(InstanceActionTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, args: %#v", ErrTypeAssertion, job.ID, job.Args)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	logger := zerolog.Ctx(ctx).With().
+		Int64("reservation_id", args.ReservationID).
+		Int64("instance_action_id", args.ActionID).
+		Str("instance_id", args.InstanceID).
+		Str("instance_action", string(args.Action)).
+		Logger()
+	ctx = logger.WithContext(ctx)
+
+	jobErr := do(ctx, &args)
+	if jobErr != nil {
+		logger.Error().Err(jobErr).Msg("Unable to perform instance action")
+	}
+	return jobErr
+}
+
+// DoInstanceActionAWS stops, starts or reboots an EC2 instance.
+func DoInstanceActionAWS(ctx context.Context, args *InstanceActionTaskArgs) error {
+	return trackInstanceAction(ctx, args, func(authentication *clients.Authentication) error {
+		ec2Client, err := clients.GetEC2Client(ctx, authentication, args.Location)
+		if err != nil {
+			return fmt.Errorf("unable to get AWS EC2 client: %w", err)
+		}
+
+		ids := []string{args.InstanceID}
+		switch args.Action {
+		case models.InstanceActionStop:
+			return ec2Client.StopInstances(ctx, ids)
+		case models.InstanceActionStart:
+			return ec2Client.StartInstances(ctx, ids)
+		case models.InstanceActionReboot:
+			return ec2Client.RebootInstances(ctx, ids)
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownInstanceAction, args.Action)
+	})
+}
+
+// DoInstanceActionAzure deallocates, starts or restarts an Azure VM.
+func DoInstanceActionAzure(ctx context.Context, args *InstanceActionTaskArgs) error {
+	return trackInstanceAction(ctx, args, func(authentication *clients.Authentication) error {
+		azureClient, err := clients.GetAzureClient(ctx, authentication)
+		if err != nil {
+			return fmt.Errorf("unable to get Azure client: %w", err)
+		}
+
+		switch args.Action {
+		case models.InstanceActionStop:
+			return azureClient.StopVM(ctx, args.InstanceID)
+		case models.InstanceActionStart:
+			return azureClient.StartVM(ctx, args.InstanceID)
+		case models.InstanceActionReboot:
+			return azureClient.RestartVM(ctx, args.InstanceID)
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownInstanceAction, args.Action)
+	})
+}
+
+// DoInstanceActionGCP stops, starts or resets a GCP instance.
+func DoInstanceActionGCP(ctx context.Context, args *InstanceActionTaskArgs) error {
+	return trackInstanceAction(ctx, args, func(authentication *clients.Authentication) error {
+		gcpClient, err := clients.GetGCPClient(ctx, authentication)
+		if err != nil {
+			return fmt.Errorf("unable to get GCP client: %w", err)
+		}
+
+		switch args.Action {
+		case models.InstanceActionStop:
+			return gcpClient.StopInstance(ctx, args.InstanceID, args.Location)
+		case models.InstanceActionStart:
+			return gcpClient.StartInstance(ctx, args.InstanceID, args.Location)
+		case models.InstanceActionReboot:
+			return gcpClient.ResetInstance(ctx, args.InstanceID, args.Location)
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownInstanceAction, args.Action)
+	})
+}
+
+// trackInstanceAction marks the action as running, performs it with the authentication of the
+// source and stores the result. Failed actions keep the error message.
+func trackInstanceAction(ctx context.Context, args *InstanceActionTaskArgs, perform func(*clients.Authentication) error) error {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started instance action job")
+	actionDao := dao.GetInstanceActionDao(ctx)
+
+	err := actionDao.UnscopedUpdateStatus(ctx, args.ActionID, models.InstanceActionRunning, "")
+	if err != nil {
+		return fmt.Errorf("cannot update instance action status: %w", err)
+	}
+
+	err = performWithAuthentication(ctx, args.SourceID, perform)
+	if err != nil {
+		updateErr := actionDao.UnscopedUpdateStatus(ctx, args.ActionID, models.InstanceActionFailed, err.Error())
+		if updateErr != nil {
+			logger.Warn().Err(updateErr).Msg("Unable to mark instance action as failed")
+		}
+		return err
+	}
+
+	err = actionDao.UnscopedUpdateStatus(ctx, args.ActionID, models.InstanceActionSuccess, "")
+	if err != nil {
+		return fmt.Errorf("cannot update instance action status: %w", err)
+	}
+	logger.Info().Msgf("Instance action %s finished", args.Action)
+	return nil
+}
+
+func performWithAuthentication(ctx context.Context, sourceID string, perform func(*clients.Authentication) error) error {
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get sources client: %w", err)
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(ctx, sourceID)
+	if err != nil {
+		return fmt.Errorf("unable to get authentication from sources: %w", err)
+	}
+
+	return perform(authentication)
+}
@@ -0,0 +1,60 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoInstanceAction(t *testing.T) {
+	ctx := prepareEC2Context(t)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithGCPCCustomerClient(ctx)
+	ctx = daoStubs.WithInstanceActionDao(ctx)
+	actionDao := dao.GetInstanceActionDao(ctx)
+
+	newArgs := func(t *testing.T, instanceID string, action models.InstanceActionType) *jobs.InstanceActionTaskArgs {
+		t.Helper()
+		instanceAction := &models.InstanceAction{ReservationID: 1, InstanceID: instanceID, Action: action}
+		require.NoError(t, actionDao.Create(ctx, instanceAction))
+		return &jobs.InstanceActionTaskArgs{
+			ActionID:      instanceAction.ID,
+			ReservationID: 1,
+			InstanceID:    instanceID,
+			Action:        action,
+			SourceID:      "1",
+			Location:      "us-east-1",
+		}
+	}
+
+	t.Run("stop AWS instance", func(t *testing.T) {
+		args := newArgs(t, "i-1", models.InstanceActionStop)
+		require.NoError(t, jobs.DoInstanceActionAWS(ctx, args))
+
+		ec2Client, err := clients.GetEC2Client(ctx, nil, args.Location)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-1"}, ec2Client.(*clientStubs.EC2ClientStub).Stopped)
+
+		stored, err := actionDao.GetById(ctx, args.ActionID)
+		require.NoError(t, err)
+		assert.Equal(t, models.InstanceActionSuccess, stored.Status)
+		assert.NotNil(t, stored.FinishedAt)
+	})
+
+	t.Run("failed GCP action", func(t *testing.T) {
+		args := newArgs(t, "missing", models.InstanceActionReboot)
+		require.Error(t, jobs.DoInstanceActionGCP(ctx, args))
+
+		stored, err := actionDao.GetById(ctx, args.ActionID)
+		require.NoError(t, err)
+		assert.Equal(t, models.InstanceActionFailed, stored.Status)
+		assert.Contains(t, stored.Error, "instance id is not present")
+	})
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// jobReservationId returns reservation ID of job arguments or zero for jobs without reservation.
+func jobReservationId(args any) int64 {
+	switch a := args.(type) {
+	case NoopJobArgs:
+		return a.ReservationID
+	case LaunchInstanceAWSTaskArgs:
+		return a.ReservationID
+	case LaunchInstanceAzureTaskArgs:
+		return a.ReservationID
+	case LaunchInstanceGCPTaskArgs:
+		return a.ReservationID
+	default:
+		return 0
+	}
+}
+
+// RecordJobExecution is an execution recorder which stores every job attempt into the audit
+// log for support debugging. Errors are logged, they do not affect job processing.
+func RecordJobExecution(ctx context.Context, job *worker.Job, execution *worker.Execution) {
+	logger := zerolog.Ctx(ctx)
+
+	audit := &models.JobAudit{
+		JobID:         job.ID.String(),
+		JobType:       job.Type.String(),
+		AccountID:     job.AccountID,
+		ReservationID: jobReservationId(job.Args),
+		Attempt:       job.Attempt,
+		Outcome:       execution.Result,
+		StartedAt:     execution.Started,
+		DurationMs:    execution.Duration.Milliseconds(),
+	}
+	if execution.Err != nil {
+		audit.Error = execution.Err.Error()
+	}
+
+	args, err := json.Marshal(job.Args)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to marshal job arguments for audit")
+	} else {
+		sum := sha256.Sum256(args)
+		audit.ArgsHash = hex.EncodeToString(sum[:])
+	}
+
+	err = dao.GetJobAuditDao(ctx).UnscopedCreate(ctx, audit)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to store job audit record")
+	}
+}
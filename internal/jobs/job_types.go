@@ -3,8 +3,16 @@ package jobs
 import "github.com/RHEnVision/provisioning-backend/pkg/worker"
 
 const (
-	TypeNoop                worker.JobType = "no_operation"
-	TypeLaunchInstanceAws   worker.JobType = "launch_instances_aws"
-	TypeLaunchInstanceAzure worker.JobType = "launch_instances_azure"
-	TypeLaunchInstanceGcp   worker.JobType = "launch_instances_gcp"
+	TypeNoop                 worker.JobType = "no_operation"
+	TypeLaunchInstanceAws    worker.JobType = "launch_instances_aws"
+	TypeLaunchInstanceAzure  worker.JobType = "launch_instances_azure"
+	TypeLaunchInstanceGcp    worker.JobType = "launch_instances_gcp"
+	TypeRefreshAvailability  worker.JobType = "refresh_availability"
+	TypeDeletePubkeyResource worker.JobType = "delete_pubkey_resource"
+	TypeNotifyPubkeyExpiry   worker.JobType = "notify_pubkey_expiry"
+	TypeNotifyExpiryDigest   worker.JobType = "notify_expiry_digest"
+	TypeProbeSSH             worker.JobType = "probe_ssh"
+	TypeInstanceActionAws    worker.JobType = "instance_action_aws"
+	TypeInstanceActionAzure  worker.JobType = "instance_action_azure"
+	TypeInstanceActionGcp    worker.JobType = "instance_action_gcp"
 )
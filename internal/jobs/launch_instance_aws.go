@@ -9,8 +9,9 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
-	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -33,9 +34,12 @@ type LaunchInstanceAWSTaskArgs struct {
 	// Detail information
 	Detail *models.AWSDetail
 
-	// AWS AMI as fetched from image builder
+	// AWS AMI as fetched from image builder, empty when the image must be cloned first
 	AMI string
 
+	// Image builder compose to clone into the region, set only when the AMI is not available there
+	ComposeID string
+
 	// LaunchTemplateID or empty string when no template in use
 	LaunchTemplateID string
 
@@ -44,39 +48,127 @@ type LaunchInstanceAWSTaskArgs struct {
 }
 
 // Unmarshall arguments and handle error
-func HandleLaunchInstanceAWS(ctx context.Context, job *worker.Job) {
+func HandleLaunchInstanceAWS(ctx context.Context, job *worker.Job) error {
 	args, ok := job.Args.(LaunchInstanceAWSTaskArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	logger := zerolog.Ctx(ctx).With().Int64("reservation_id", args.ReservationID).Logger()
 	ctx = logger.WithContext(ctx)
-	nc := notifications.GetNotificationClient(ctx)
+	ctx = clients.WithReservationId(ctx, args.ReservationID)
+	ctx, comp := withCompensation(ctx)
+	ctx, watcher := watchCancel(ctx, args.ReservationID)
+	defer watcher.Stop()
+
+	// side effects of steps before a failed launch are undone, including a retried launch
+	// which starts from scratch
+	var jobErr error
+	if args.AMI == "" && args.ComposeID != "" {
+		jobErr = watcher.runStep(ctx, func() error { return DoEnsureAMIOnAWS(ctx, &args) })
+		if errors.Is(jobErr, ErrReservationCancelled) {
+			finishWithCancel(ctx, args.ReservationID, jobErr)
+			return nil
+		}
+		if jobErr != nil {
+			if !retryPending(ctx, job, jobErr) {
+				finishWithError(ctx, args.ReservationID, jobErr)
+			}
+			return jobErr
+		}
+	}
 
-	jobErr := DoEnsurePubkeyOnAWS(ctx, &args)
+	jobErr = watcher.runStep(ctx, func() error { return DoEnsurePubkeyOnAWS(ctx, &args) })
+	if errors.Is(jobErr, ErrReservationCancelled) {
+		comp.Compensate(ctx)
+		finishWithCancel(ctx, args.ReservationID, jobErr)
+		return nil
+	}
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-		return
+		comp.Compensate(ctx)
+		if !retryPending(ctx, job, jobErr) {
+			finishWithError(ctx, args.ReservationID, jobErr)
+		}
+		return jobErr
 	}
 
-	jobErr = DoLaunchInstanceAWS(ctx, &args)
-	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-		return
+	jobErr = watcher.runStep(ctx, func() error { return DoLaunchInstanceAWS(ctx, &args) })
+	if errors.Is(jobErr, ErrReservationCancelled) {
+		comp.Compensate(ctx)
+		finishWithCancel(ctx, args.ReservationID, jobErr)
+		return nil
 	}
-	jobErr = FetchInstancesDescriptionAWS(ctx, &args)
 	if jobErr != nil {
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-	} else {
-		nc.SuccessfulLaunch(ctx, args.ReservationID)
+		comp.Compensate(ctx)
+		if !retryPending(ctx, job, jobErr) {
+			finishWithError(ctx, args.ReservationID, jobErr)
+		}
+		return jobErr
 	}
 
+	// instances are running and use the uploaded key, description failure is not undone
+	comp.Commit()
+
+	jobErr = watcher.runStep(ctx, func() error { return FetchInstancesDescriptionAWS(ctx, &args) })
+	if errors.Is(jobErr, ErrReservationCancelled) {
+		finishWithCancel(ctx, args.ReservationID, jobErr)
+		return nil
+	}
+	if retryPending(ctx, job, jobErr) {
+		return jobErr
+	}
 	finishJob(ctx, args.ReservationID, jobErr)
+	return jobErr
+}
+
+// Backoff of polling image builder for the clone status, the clone usually takes several minutes
+const (
+	cloneStatusBackoff    = 10 * time.Second
+	cloneStatusMaxBackoff = time.Minute
+)
+
+// DoEnsureAMIOnAWS triggers or reuses an image builder clone of the compose into the region
+// and waits until it finishes. The cloned AMI is stored into the job arguments.
+func DoEnsureAMIOnAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started image clone AWS job")
+
+	updateStatusBefore(ctx, args.ReservationID, "Cloning image to region")
+	defer updateStatusAfter(ctx, args.ReservationID, "Cloned image to region", 1)
+
+	ibClient, err := clients.GetImageBuilderClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot create image builder client: %w", err)
+	}
+
+	cloneID, err := ibClient.CloneAWSImage(ctx, args.ComposeID, args.Region, args.SourceID)
+	if err != nil {
+		return fmt.Errorf("cannot clone image to region %s: %w", args.Region, err)
+	}
+	logger.Info().Str("clone_id", cloneID).Msgf("Awaiting clone of compose %s into region %s", args.ComposeID, args.Region)
+
+	backoff := cloneStatusBackoff
+	for {
+		ami, amiErr := ibClient.GetAWSCloneAmi(ctx, cloneID)
+		if amiErr == nil {
+			logger.Info().Str("clone_id", cloneID).Str("ami", ami).Msg("Image clone finished")
+			args.AMI = ami
+			return nil
+		}
+		if !errors.Is(amiErr, http.ImageStatusErr) {
+			return fmt.Errorf("cannot get status of image clone %s: %w", cloneID, amiErr)
+		}
+
+		if sleepErr := sleepCtx(ctx, backoff); sleepErr != nil {
+			return fmt.Errorf("interrupted while waiting for image clone: %w", sleepErr)
+		}
+		backoff *= 2
+		if backoff > cloneStatusMaxBackoff {
+			backoff = cloneStatusMaxBackoff
+		}
+	}
 }
 
 // Job logic, when error is returned the job status is updated accordingly
@@ -138,7 +230,16 @@ func DoEnsurePubkeyOnAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 			} else if err != nil {
 				return fmt.Errorf("cannot upload aws pubkey: %w", err)
 			}
+			metrics.IncPubkeysUploaded(models.ProviderTypeAWS)
 			ec2Name = pubkey.Name
+
+			handle := pkr.Handle
+			registerCompensation(ctx, "upload pubkey to AWS", func(ctx context.Context) error {
+				if deleteErr := ec2Client.DeleteSSHKey(ctx, handle); deleteErr != nil {
+					return fmt.Errorf("cannot delete uploaded aws pubkey: %w", deleteErr)
+				}
+				return nil
+			})
 		} else {
 			logger.Error().Err(err).Str("pubkey_fingerprint", fingerprint).Msg("Cannot fetch name of pubkey by its fingerprint")
 			return fmt.Errorf("cannot fetch name of pubkey by its fingerprint: %w", err)
@@ -159,6 +260,14 @@ func DoEnsurePubkeyOnAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 		if err != nil {
 			return fmt.Errorf("cannot create resource for aws pubkey: %w", err)
 		}
+
+		resourceId := pkr.ID
+		registerCompensation(ctx, "create pubkey resource", func(ctx context.Context) error {
+			if deleteErr := dao.GetPubkeyDao(ctx).UnscopedDeleteResource(ctx, resourceId); deleteErr != nil {
+				return fmt.Errorf("cannot delete aws pubkey resource: %w", deleteErr)
+			}
+			return nil
+		})
 	}
 
 	return nilUnlessTimeout(ctx)
@@ -204,12 +313,40 @@ func DoLaunchInstanceAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 		AMI:              args.AMI,
 		KeyName:          reservation.Detail.PubkeyName,
 		UserData:         userData,
+		Tenancy:          args.Detail.Tenancy,
+		HostID:           args.Detail.HostID,
+		MetadataOptions:  args.Detail.MetadataOptions,
+		Tags:             models.ResourceTags(args.Detail.Tags, args.ReservationID, identity.OrgIdOrEmpty(ctx)),
 	}
 
-	logger.Trace().Msg("Executing RunInstances")
-	instances, awsReservationId, err := ec2Client.RunInstances(ctx, req, args.Detail.Amount, args.Detail.Name)
-	if err != nil {
-		return fmt.Errorf("cannot run instances: %w", err)
+	var instances []*string
+	var awsReservationId *string
+	if len(args.Detail.InstanceTypes) > 0 {
+		instances, awsReservationId, err = launchFleetAWS(ctx, ec2Client, req, reservation, args.Detail)
+		if err != nil {
+			return err
+		}
+	} else {
+		logger.Trace().Msg("Executing RunInstances")
+		instances, awsReservationId, err = ec2Client.RunInstances(ctx, req, args.Detail.Amount, args.Detail.Name)
+		if err != nil {
+			return fmt.Errorf("cannot run instances: %w", err)
+		}
+	}
+	metrics.AddInstancesLaunched(models.ProviderTypeAWS, len(instances))
+
+	if len(instances) > 0 {
+		instanceIds := make([]string, len(instances))
+		for i, instanceId := range instances {
+			instanceIds[i] = *instanceId
+		}
+		registerCompensation(ctx, "run instances", func(ctx context.Context) error {
+			if terminateErr := ec2Client.TerminateInstances(ctx, instanceIds); terminateErr != nil {
+				return fmt.Errorf("cannot terminate launched instances: %w", terminateErr)
+			}
+			enqueueTerminatedEvent(ctx, &reservation.Reservation, instanceIds)
+			return nil
+		})
 	}
 
 	// For each instance that was created in AWS, add it as a DB record
@@ -234,6 +371,31 @@ func DoLaunchInstanceAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 	return nilUnlessTimeout(ctx)
 }
 
+// launchFleetAWS launches instances via EC2 Fleet and records the launched instance type on the
+// reservation detail. The fleet ID is returned in place of the AWS reservation ID. Failure to
+// record the type is only logged, the instances are already running.
+func launchFleetAWS(ctx context.Context, ec2Client clients.EC2, req *clients.AWSInstanceParams, reservation *models.AWSReservation, detail *models.AWSDetail) ([]*string, *string, error) {
+	logger := zerolog.Ctx(ctx)
+	instanceTypes := make([]clients.InstanceTypeName, len(detail.InstanceTypes))
+	for i, name := range detail.InstanceTypes {
+		instanceTypes[i] = clients.InstanceTypeName(name)
+	}
+
+	logger.Trace().Msg("Executing LaunchFleet")
+	instances, fleetId, instanceType, err := ec2Client.LaunchFleet(ctx, req, instanceTypes, detail.Amount, detail.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot launch fleet: %w", err)
+	}
+	logger.Info().Str("instance_type", instanceType.String()).Msgf("Fleet launched %d instance(s)", len(instances))
+
+	reservation.Detail.InstanceType = instanceType.String()
+	err = dao.GetReservationDao(ctx).UnscopedUpdateAWSDetail(ctx, reservation.ID, reservation.Detail)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to record launched instance type")
+	}
+	return instances, fleetId, nil
+}
+
 func FetchInstancesDescriptionAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) error {
 	logger := zerolog.Ctx(ctx)
 	logger.Debug().Msg("Started fetch instances description")
@@ -256,7 +418,9 @@ func FetchInstancesDescriptionAWS(ctx context.Context, args *LaunchInstanceAWSTa
 	}
 	backoffInterval := [...]int64{1000, 500, 500, 1000, 2000}
 	for _, interval := range backoffInterval {
-		time.Sleep(time.Duration(interval) * time.Millisecond)
+		if err := sleepCtx(ctx, time.Duration(interval)*time.Millisecond); err != nil {
+			return fmt.Errorf("interrupted while waiting for instances description: %w", err)
+		}
 		instancesDescriptionList, err := ec2Client.DescribeInstanceDetails(ctx, instancesIDList)
 		if err != nil {
 			return fmt.Errorf("cannot get list instances description: %w", err)
@@ -152,3 +152,34 @@ func TestHandleEnsurePubkeyOnAWS(t *testing.T) {
 		assert.Equal(t, 1, len(pkrList))
 	})
 }
+
+func TestDoEnsureAMIOnAWS(t *testing.T) {
+	ctx := prepareEC2Context(t)
+	ctx = clientStubs.WithImageBuilderClient(ctx)
+
+	pk := &models.Pubkey{
+		Name: factories.SeqNameWithPrefix("pubkey"),
+		Body: factories.GenerateRSAPubKey(t),
+	}
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	reservation := prepareAWSReservation(t, ctx, pk)
+	reservation.Detail.Region = "eu-west-3"
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAWS(ctx, reservation)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceAWSTaskArgs{
+		ReservationID: reservation.ID,
+		Region:        reservation.Detail.Region,
+		PubkeyID:      pk.ID,
+		SourceID:      reservation.SourceID,
+		Detail:        reservation.Detail,
+		ComposeID:     "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+	}
+
+	err = jobs.DoEnsureAMIOnAWS(ctx, args)
+	require.NoError(t, err, "the ensure AMI job failed to run")
+	assert.Equal(t, "ami-0e1c5a2d3b4f6a7c8-clone-test", args.AMI)
+}
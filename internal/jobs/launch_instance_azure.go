@@ -2,12 +2,17 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
-	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/rs/zerolog"
@@ -19,6 +24,9 @@ const (
 	resourceGroupName = "redhat-deployed"
 	location          = "eastus"
 	vmNamePrefix      = "redhat-vm"
+
+	// reservationTagKey tags resource groups created for a reservation with its ID
+	reservationTagKey = "redhat-provisioning-reservation"
 )
 
 var LaunchInstanceAzureSteps = []string{"Prepare resource group", "Launch instance(s)"}
@@ -43,37 +51,60 @@ type LaunchInstanceAzureTaskArgs struct {
 	Subscription *clients.Authentication
 }
 
-func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) {
+func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) error {
 	args, ok := job.Args.(LaunchInstanceAzureTaskArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	logger := zerolog.Ctx(ctx).With().Int64("reservation_id", args.ReservationID).Logger()
 	ctx = logger.WithContext(ctx)
 
 	logger.Info().Msg("Started launch instance Azure job")
+	if featureDisabled(ctx, featureflags.Azure, args.ReservationID) {
+		return nil
+	}
 	ctx, span := otel.Tracer(TraceName).Start(ctx, "LaunchInstanceAzureJob")
 	defer span.End()
-	nc := notifications.GetNotificationClient(ctx)
-	jobErr := DoEnsureAzureResourceGroup(ctx, &args)
+	ctx, comp := withCompensation(ctx)
+	ctx, watcher := watchCancel(ctx, args.ReservationID)
+	defer watcher.Stop()
+
+	// resource group created for the reservation is deleted when the launch fails, including
+	// all instances launched into it
+	jobErr := watcher.runStep(ctx, func() error { return DoEnsureAzureResourceGroup(ctx, &args) })
+	if errors.Is(jobErr, ErrReservationCancelled) {
+		comp.Compensate(ctx)
+		finishWithCancel(ctx, args.ReservationID, jobErr)
+		return nil
+	}
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-		return
+		comp.Compensate(ctx)
+		if !retryPending(ctx, job, jobErr) {
+			finishWithError(ctx, args.ReservationID, jobErr)
+		}
+		return jobErr
 	}
 
-	jobErr = DoLaunchInstanceAzure(ctx, &args)
+	jobErr = watcher.runStep(ctx, func() error { return DoLaunchInstanceAzure(ctx, &args) })
+	if errors.Is(jobErr, ErrReservationCancelled) {
+		comp.Compensate(ctx)
+		finishWithCancel(ctx, args.ReservationID, jobErr)
+		return nil
+	}
 	if jobErr != nil {
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-	} else {
-		nc.SuccessfulLaunch(ctx, args.ReservationID)
+		comp.Compensate(ctx)
+	}
+	if retryPending(ctx, job, jobErr) {
+		return jobErr
 	}
+	comp.Commit()
 	finishJob(ctx, args.ReservationID, jobErr)
 
 	logger.Info().Msg("Finished launch instance Azure job")
+	return jobErr
 }
 
 func DoEnsureAzureResourceGroup(ctx context.Context, args *LaunchInstanceAzureTaskArgs) error {
@@ -86,18 +117,55 @@ func DoEnsureAzureResourceGroup(ctx context.Context, args *LaunchInstanceAzureTa
 	updateStatusBefore(ctx, args.ReservationID, "Ensuring resource group presence")
 	defer updateStatusAfter(ctx, args.ReservationID, "Ensured resource group presence", 1)
 
+	resDao := dao.GetReservationDao(ctx)
+	reservation, err := resDao.GetAzureById(ctx, args.ReservationID)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot get azure reservation record")
+		return fmt.Errorf("cannot get azure reservation by id: %w", err)
+	}
+
+	detail := reservation.Detail
+	if detail.ResourceGroupStrategy == models.AzureResourceGroupExisting {
+		logger.Debug().Msgf("Using existing resource group %s", detail.ResourceGroup)
+		return nil
+	}
+
 	azureClient, err := clients.GetAzureClient(ctx, args.Subscription)
 	if err != nil {
 		return fmt.Errorf("cannot create new Azure client: %w", err)
 	}
 
-	resourceGroupID, err := azureClient.EnsureResourceGroup(ctx, resourceGroupName, location)
+	var tags map[string]string
+	if detail.ResourceGroupStrategy == models.AzureResourceGroupReservation {
+		detail.ResourceGroup = fmt.Sprintf(config.Application.AzureResourceGroup.Pattern, reservation.ID)
+		tags = models.ResourceTags(detail.ResourceGroupTags, reservation.ID, identity.OrgIdOrEmpty(ctx))
+		tags[reservationTagKey] = strconv.FormatInt(reservation.ID, 10)
+	} else {
+		detail.ResourceGroup = resourceGroupName
+	}
+
+	resourceGroupID, err := azureClient.EnsureResourceGroup(ctx, detail.ResourceGroup, location, tags)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot create resource group")
 		logger.Error().Err(err).Msg("Cannot create resource group")
 		return fmt.Errorf("failed to ensure resource group: %w", err)
 	}
 	logger.Trace().Msgf("Using resource group id=%s", *resourceGroupID)
+
+	if detail.ResourceGroupStrategy == models.AzureResourceGroupReservation {
+		name := detail.ResourceGroup
+		registerCompensation(ctx, "create resource group", func(ctx context.Context) error {
+			if deleteErr := azureClient.DeleteResourceGroup(ctx, name); deleteErr != nil {
+				return fmt.Errorf("cannot delete resource group %s: %w", name, deleteErr)
+			}
+			return nil
+		})
+	}
+
+	err = resDao.UnscopedUpdateAzureDetail(ctx, reservation.ID, detail)
+	if err != nil {
+		return fmt.Errorf("cannot update resource group of reservation: %w", err)
+	}
 	return nil
 }
 
@@ -143,13 +211,23 @@ func DoLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArg
 	}
 	logger.Trace().Bool("userdata", true).Msg(string(userData))
 
+	// reservations made before the strategy was recorded use the shared group
+	rgName := reservation.Detail.ResourceGroup
+	if rgName == "" {
+		rgName = resourceGroupName
+	}
+
 	vmParams := clients.AzureInstanceParams{
 		Location:          location,
-		ResourceGroupName: resourceGroupName,
+		ResourceGroupName: rgName,
 		ImageID:           args.AzureImageID,
 		Pubkey:            pubkey,
 		InstanceType:      clients.InstanceTypeName(reservation.Detail.InstanceSize),
 		UserData:          userData,
+		Priority:          reservation.Detail.Priority,
+		MaxPrice:          reservation.Detail.MaxPrice,
+		EvictionPolicy:    reservation.Detail.EvictionPolicy,
+		Tags:              models.ResourceTags(reservation.Detail.Tags, reservation.ID, identity.OrgIdOrEmpty(ctx)),
 	}
 
 	instanceDescriptions, err := azureClient.CreateVMs(ctx, vmParams, reservation.Detail.Amount, vmNamePrefix)
@@ -157,6 +235,7 @@ func DoLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArg
 		span.SetStatus(codes.Error, "failed to create instances")
 		return fmt.Errorf("cannot create Azure instance: %w", err)
 	}
+	metrics.AddInstancesLaunched(models.ProviderTypeAzure, len(instanceDescriptions))
 
 	for _, instanceDescription := range instanceDescriptions {
 		err = resDao.CreateInstance(ctx, &models.ReservationInstance{
@@ -2,10 +2,13 @@ package jobs_test
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
@@ -78,6 +81,48 @@ func TestDoEnsureAzureResourceGroup(t *testing.T) {
 	assert.True(t, clientStubs.DidCreateAzureResourceGroup(ctx, "redhat-deployed"))
 }
 
+func TestDoEnsureAzureResourceGroupReservation(t *testing.T) {
+	ctx := prepareAzureContext(t)
+	original := config.Application.AzureResourceGroup.Pattern
+	config.Application.AzureResourceGroup.Pattern = "redhat-reservation-%d"
+	t.Cleanup(func() { config.Application.AzureResourceGroup.Pattern = original })
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	res.Detail.ResourceGroupStrategy = models.AzureResourceGroupReservation
+	res.Detail.ResourceGroupTags = map[string]string{"team": "qe"}
+
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceAzureTaskArgs{
+		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
+		Location:      "useast",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		SourceID:      "2",
+		Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+	}
+
+	err = jobs.DoEnsureAzureResourceGroup(ctx, args)
+	require.NoError(t, err, "the ensure resource group failed to run")
+
+	name := fmt.Sprintf("redhat-reservation-%d", res.ID)
+	assert.True(t, clientStubs.DidCreateAzureResourceGroup(ctx, name))
+	tags := clientStubs.StubAzureResourceGroupTags(ctx, name)
+	assert.Equal(t, "qe", tags["team"])
+	assert.Equal(t, strconv.FormatInt(res.ID, 10), tags["redhat-provisioning-reservation"])
+	assert.Equal(t, models.TagManagedByValue, tags[models.TagManagedBy])
+
+	updated, err := rDao.GetAzureById(ctx, res.ID)
+	require.NoError(t, err, "failed to fetch reservation")
+	assert.Equal(t, name, updated.Detail.ResourceGroup)
+}
+
 func TestDoLaunchInstanceAzure(t *testing.T) {
 	ctx := prepareAzureContext(t)
 
@@ -110,3 +155,34 @@ func TestDoLaunchInstanceAzure(t *testing.T) {
 	assert.Equal(t, 2, len(resultInstances))
 	assert.NotEmpty(t, resultInstances[0].Detail.PublicIPv4)
 }
+
+func TestDoLaunchInstanceAzureSpot(t *testing.T) {
+	ctx := prepareAzureContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	res.Detail.Amount = 2
+	res.Detail.Priority = models.AzurePrioritySpot
+	res.Detail.EvictionPolicy = models.AzureEvictionDelete
+
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceAzureTaskArgs{
+		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
+		Location:      "useast",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		SourceID:      "2",
+		Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+	}
+
+	err = jobs.DoLaunchInstanceAzure(ctx, args)
+	require.NoError(t, err, "launch instances failed to run")
+
+	assert.Equal(t, 2, clientStubs.CountStubAzureSpotVMs(ctx))
+}
@@ -2,13 +2,16 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/gcp"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
-	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
@@ -41,32 +44,44 @@ type LaunchInstanceGCPTaskArgs struct {
 }
 
 // Unmarshall arguments and handle error
-func HandleLaunchInstanceGCP(ctx context.Context, job *worker.Job) {
+func HandleLaunchInstanceGCP(ctx context.Context, job *worker.Job) error {
 	args, ok := job.Args.(LaunchInstanceGCPTaskArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	logger := zerolog.Ctx(ctx).With().Int64("reservation_id", args.ReservationID).Logger()
 	ctx = logger.WithContext(ctx)
-	nc := notifications.GetNotificationClient(ctx)
+	if featureDisabled(ctx, featureflags.GCP, args.ReservationID) {
+		return nil
+	}
+	ctx, watcher := watchCancel(ctx, args.ReservationID)
+	defer watcher.Stop()
 
-	jobErr := DoLaunchInstanceGCP(ctx, &args)
+	jobErr := watcher.runStep(ctx, func() error { return DoLaunchInstanceGCP(ctx, &args) })
+	if errors.Is(jobErr, ErrReservationCancelled) {
+		finishWithCancel(ctx, args.ReservationID, jobErr)
+		return nil
+	}
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-		return
+		if !retryPending(ctx, job, jobErr) {
+			finishWithError(ctx, args.ReservationID, jobErr)
+		}
+		return jobErr
 	}
 
-	jobErr = FetchInstancesDescriptionGCP(ctx, &args)
-	if jobErr != nil {
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-	} else {
-		nc.SuccessfulLaunch(ctx, args.ReservationID)
+	jobErr = watcher.runStep(ctx, func() error { return FetchInstancesDescriptionGCP(ctx, &args) })
+	if errors.Is(jobErr, ErrReservationCancelled) {
+		finishWithCancel(ctx, args.ReservationID, jobErr)
+		return nil
+	}
+	if retryPending(ctx, job, jobErr) {
+		return jobErr
 	}
 	finishJob(ctx, args.ReservationID, jobErr)
+	return jobErr
 }
 
 // Job logic, when error is returned the job status is updated accordingly
@@ -114,12 +129,20 @@ func DoLaunchInstanceGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs) e
 		StartupScript:      string(userData),
 		UUID:               args.Detail.UUID,
 		LaunchTemplateName: args.LaunchTemplateName,
+		ProvisioningModel:  args.Detail.ProvisioningModel,
+		TerminationAction:  args.Detail.TerminationAction,
+		ServiceAccount:     args.Detail.ServiceAccount,
+		Scopes:             args.Detail.Scopes,
+		Network:            args.Detail.Network,
+		Subnetwork:         args.Detail.Subnetwork,
+		Tags:               models.ResourceTags(args.Detail.Tags, args.ReservationID, identity.OrgIdOrEmpty(ctx)),
 	}
 
 	instances, opName, err := gcpClient.InsertInstances(ctx, params, args.Detail.Amount)
 	if err != nil {
 		return fmt.Errorf("cannot run instances for gcp client: %w", err)
 	}
+	metrics.AddInstancesLaunched(models.ProviderTypeGCP, len(instances))
 
 	rDao := dao.GetReservationDao(ctx)
 
@@ -88,3 +88,40 @@ func TestDoLaunchInstanceGCP(t *testing.T) {
 		assert.Equal(t, "10.0.0.11", resultInstances[1].Detail.PublicIPv4)
 	})
 }
+
+func TestDoLaunchInstanceGCPSpot(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	res.Detail.ProvisioningModel = models.GCPProvisioningSpot
+	res.Detail.TerminationAction = models.GCPTerminationDelete
+	res.Detail.ServiceAccount = "app@example-project-id.iam.gserviceaccount.com"
+	res.Detail.Scopes = []string{models.GCPDefaultScope}
+	res.Detail.Subnetwork = "projects/host-project/regions/europe-west8/subnetworks/shared"
+	err = dao.GetReservationDao(ctx).CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceGCPTaskArgs{
+		ImageName:     "composer-api-3b6225fc-d55a-4dcc-9d0a-b478ae152a",
+		Zone:          "europe-west8-c",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		ProjectID:     clients.NewAuthentication("example-project-id", models.ProviderTypeGCP),
+		Detail:        res.Detail,
+	}
+
+	err = jobs.DoLaunchInstanceGCP(ctx, args)
+	require.NoError(t, err, "launch instances failed to run")
+
+	params := clientStubs.StubGCPInsertParams(ctx)
+	require.NotNil(t, params)
+	assert.Equal(t, models.GCPProvisioningSpot, params.ProvisioningModel)
+	assert.Equal(t, models.GCPTerminationDelete, params.TerminationAction)
+	assert.Equal(t, "app@example-project-id.iam.gserviceaccount.com", params.ServiceAccount)
+	assert.Equal(t, []string{models.GCPDefaultScope}, params.Scopes)
+	assert.Equal(t, "projects/host-project/regions/europe-west8/subnetworks/shared", params.Subnetwork)
+}
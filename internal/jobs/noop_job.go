@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/rs/zerolog"
 )
@@ -20,26 +19,23 @@ type NoopJobArgs struct {
 var NoOperationFailure = errors.New("job failed on request")
 
 // Unmarshall arguments and handle error
-func HandleNoop(ctx context.Context, job *worker.Job) {
+func HandleNoop(ctx context.Context, job *worker.Job) error {
 	args, ok := job.Args.(NoopJobArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	logger := zerolog.Ctx(ctx).With().Int64("reservation_id", args.ReservationID).Logger()
 	ctx = logger.WithContext(ctx)
-	nc := notifications.GetNotificationClient(ctx)
 
 	jobErr := DoNoop(ctx, &args)
-	if jobErr != nil {
-		nc.FailedLaunch(ctx, args.ReservationID, jobErr)
-	} else {
-		nc.SuccessfulLaunch(ctx, args.ReservationID)
+	if retryPending(ctx, job, jobErr) {
+		return jobErr
 	}
-
 	finishJob(ctx, args.ReservationID, jobErr)
+	return jobErr
 }
 
 // Job logic, when error is returned the job status is updated accordingly
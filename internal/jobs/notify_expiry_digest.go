@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+type NotifyExpiryDigestTaskArgs struct {
+	// Number of days ahead covered by the digest
+	Days int
+}
+
+// Unmarshall arguments and handle error
+func HandleNotifyExpiryDigest(ctx context.Context, job *worker.Job) error {
+	args, ok := job.Args.(NotifyExpiryDigestTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, args: %#v", ErrTypeAssertion, job.ID, job.Args)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	jobErr := DoNotifyExpiryDigest(ctx, &args)
+	if jobErr != nil {
+		zerolog.Ctx(ctx).Error().Err(jobErr).Msg("Unable to send expiry digest notifications")
+	}
+	return jobErr
+}
+
+// DoNotifyExpiryDigest sends a single notification to every organization which has pubkeys
+// expiring or deleted reservations purged within the given number of days. Unlike pubkey expiry
+// notifications, resources are listed in every digest until they expire.
+func DoNotifyExpiryDigest(ctx context.Context, args *NotifyExpiryDigestTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started notify expiry digest job")
+
+	days := args.Days
+	if days <= 0 {
+		days = config.Application.ExpiryDigestDays
+	}
+	now := time.Now()
+	before := now.Add(time.Duration(days) * 24 * time.Hour)
+
+	items := make(map[int64][]kafka.NotificationDigestItem)
+	pubkeys, err := dao.GetPubkeyDao(ctx).UnscopedListExpiringBetween(ctx, now, before)
+	if err != nil {
+		return fmt.Errorf("unable to list expiring pubkeys: %w", err)
+	}
+	for _, pk := range pubkeys {
+		items[pk.AccountID] = append(items[pk.AccountID], kafka.NotificationDigestItem{
+			Type:      kafka.NotificationDigestPubkey,
+			ID:        pk.ID,
+			Name:      pk.Name,
+			ExpiresAt: *pk.ExpiresAt,
+		})
+	}
+
+	// deleted reservations are purged after the retention period
+	if retention := config.Application.DeletedRetention; retention > 0 {
+		reservations, err := dao.GetReservationDao(ctx).UnscopedListDeletedBetween(ctx, now.Add(-retention), before.Add(-retention))
+		if err != nil {
+			return fmt.Errorf("unable to list deleted reservations: %w", err)
+		}
+		for _, r := range reservations {
+			items[r.AccountID] = append(items[r.AccountID], kafka.NotificationDigestItem{
+				Type:      kafka.NotificationDigestReservation,
+				ID:        r.ID,
+				ExpiresAt: r.DeletedAt.Add(retention),
+			})
+		}
+	}
+
+	accountIds := make([]int64, 0, len(items))
+	for accountId := range items {
+		accountIds = append(accountIds, accountId)
+	}
+	sort.Slice(accountIds, func(i, j int) bool { return accountIds[i] < accountIds[j] })
+
+	for _, accountId := range accountIds {
+		account, err := dao.GetAccountDao(ctx).GetById(ctx, accountId)
+		if err != nil {
+			return fmt.Errorf("unable to get account %d: %w", accountId, err)
+		}
+
+		accountItems := items[accountId]
+		sort.SliceStable(accountItems, func(i, j int) bool { return accountItems[i].ExpiresAt.Before(accountItems[j].ExpiresAt) })
+
+		notifyCtx := accountContext(ctx, account)
+		notifications.GetNotificationClient(notifyCtx).ExpiryDigest(notifyCtx, days, accountItems)
+	}
+	logger.Debug().Msgf("Sent %d expiry digest notifications", len(accountIds))
+
+	return nil
+}
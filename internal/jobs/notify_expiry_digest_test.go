@@ -0,0 +1,84 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingDigestClient struct {
+	notifications.NotificationClient
+	orgIds []string
+	items  [][]kafka.NotificationDigestItem
+}
+
+func (c *recordingDigestClient) ExpiryDigest(ctx context.Context, days int, items []kafka.NotificationDigestItem) {
+	c.orgIds = append(c.orgIds, identity.Identity(ctx).Identity.OrgID)
+	c.items = append(c.items, items)
+}
+
+func TestDoNotifyExpiryDigest(t *testing.T) {
+	ctx := prepareEC2Context(t)
+
+	recorder := &recordingDigestClient{}
+	original := notifications.GetNotificationClient
+	notifications.GetNotificationClient = func(_ context.Context) notifications.NotificationClient { return recorder }
+	t.Cleanup(func() { notifications.GetNotificationClient = original })
+
+	retention := config.Application.DeletedRetention
+	config.Application.DeletedRetention = 30 * 24 * time.Hour
+	t.Cleanup(func() { config.Application.DeletedRetention = retention })
+
+	soon := time.Now().Add(3 * 24 * time.Hour)
+	later := time.Now().Add(30 * 24 * time.Hour)
+
+	expiring := factories.NewPubkeyRSA()
+	expiring.ExpiresAt = &soon
+	require.NoError(t, daoStubs.AddPubkey(ctx, expiring))
+
+	notExpiring := factories.NewPubkeyED25519()
+	notExpiring.ExpiresAt = &later
+	require.NoError(t, daoStubs.AddPubkey(ctx, notExpiring))
+
+	// purged in two days
+	deletedAt := time.Now().Add(-28 * 24 * time.Hour)
+	reservation := prepareAWSReservation(t, ctx, expiring)
+	reservation.DeletedAt = &deletedAt
+	require.NoError(t, daoStubs.AddAWSReservation(ctx, reservation))
+
+	err := jobs.DoNotifyExpiryDigest(ctx, &jobs.NotifyExpiryDigestTaskArgs{Days: 7})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.items, 1)
+	assert.Equal(t, []string{identity.Identity(ctx).Identity.OrgID}, recorder.orgIds)
+	require.Len(t, recorder.items[0], 2)
+	// ordered by expiration
+	assert.Equal(t, kafka.NotificationDigestReservation, recorder.items[0][0].Type)
+	assert.Equal(t, reservation.ID, recorder.items[0][0].ID)
+	assert.WithinDuration(t, deletedAt.Add(config.Application.DeletedRetention), recorder.items[0][0].ExpiresAt, time.Second)
+	assert.Equal(t, kafka.NotificationDigestPubkey, recorder.items[0][1].Type)
+	assert.Equal(t, expiring.ID, recorder.items[0][1].ID)
+
+	t.Run("repeats daily", func(t *testing.T) {
+		err = jobs.DoNotifyExpiryDigest(ctx, &jobs.NotifyExpiryDigestTaskArgs{Days: 7})
+		require.NoError(t, err)
+		assert.Len(t, recorder.items, 2)
+	})
+
+	t.Run("nothing expiring", func(t *testing.T) {
+		recorder.items = nil
+		err = jobs.DoNotifyExpiryDigest(ctx, &jobs.NotifyExpiryDigestTaskArgs{Days: 1})
+		require.NoError(t, err)
+		assert.Empty(t, recorder.items)
+	})
+}
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+type NotifyPubkeyExpiryTaskArgs struct {
+	// Number of days before expiration the notification is sent
+	NotifyDays int
+}
+
+// Unmarshall arguments and handle error
+func HandleNotifyPubkeyExpiry(ctx context.Context, job *worker.Job) error {
+	args, ok := job.Args.(NotifyPubkeyExpiryTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, args: %#v", ErrTypeAssertion, job.ID, job.Args)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	jobErr := DoNotifyPubkeyExpiry(ctx, &args)
+	if jobErr != nil {
+		zerolog.Ctx(ctx).Error().Err(jobErr).Msg("Unable to send pubkey expiry notifications")
+	}
+	return jobErr
+}
+
+// DoNotifyPubkeyExpiry sends a notification for every pubkey of all accounts which expires
+// within the configured number of days. Each pubkey is notified only once, updating the
+// expiration time resets the flag.
+func DoNotifyPubkeyExpiry(ctx context.Context, args *NotifyPubkeyExpiryTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started notify pubkey expiry job")
+
+	days := args.NotifyDays
+	if days <= 0 {
+		days = config.Application.PubkeyExpiry.NotifyDays
+	}
+	before := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	pubkeys, err := pkDao.UnscopedListExpiring(ctx, before)
+	if err != nil {
+		return fmt.Errorf("unable to list expiring pubkeys: %w", err)
+	}
+
+	accounts := make(map[int64]*models.Account)
+	for _, pk := range pubkeys {
+		account, ok := accounts[pk.AccountID]
+		if !ok {
+			account, err = dao.GetAccountDao(ctx).GetById(ctx, pk.AccountID)
+			if err != nil {
+				return fmt.Errorf("unable to get account %d: %w", pk.AccountID, err)
+			}
+			accounts[pk.AccountID] = account
+		}
+
+		notifyCtx := accountContext(ctx, account)
+		notifications.GetNotificationClient(notifyCtx).PubkeyExpiring(notifyCtx, pk)
+
+		err = pkDao.UnscopedMarkExpiryNotified(ctx, pk.ID)
+		if err != nil {
+			return fmt.Errorf("unable to mark pubkey %d as notified: %w", pk.ID, err)
+		}
+	}
+	logger.Debug().Msgf("Sent %d pubkey expiry notifications", len(pubkeys))
+
+	return nil
+}
+
+// accountContext returns context with identity and account of the given account, notifications
+// are sent to the organization of the identity.
+func accountContext(ctx context.Context, account *models.Account) context.Context {
+	principal := identity.Principal{}
+	principal.Identity.OrgID = account.OrgID
+	principal.Identity.Internal.OrgID = account.OrgID
+	principal.Identity.AccountNumber = account.AccountNumber.String
+
+	ctx = identity.WithIdentity(ctx, principal)
+	ctx = identity.WithAccountId(ctx, account.ID)
+	logger := zerolog.Ctx(ctx).With().Int64("account_id", account.ID).Str("org_id", account.OrgID).Logger()
+	return logger.WithContext(ctx)
+}
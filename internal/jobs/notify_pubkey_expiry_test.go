@@ -0,0 +1,65 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingNotificationClient struct {
+	notifications.NotificationClient
+	orgIds  []string
+	pubkeys []int64
+}
+
+func (c *recordingNotificationClient) PubkeyExpiring(ctx context.Context, pubkey *models.Pubkey) {
+	c.orgIds = append(c.orgIds, identity.Identity(ctx).Identity.OrgID)
+	c.pubkeys = append(c.pubkeys, pubkey.ID)
+}
+
+func TestDoNotifyPubkeyExpiry(t *testing.T) {
+	ctx := prepareEC2Context(t)
+	pkDao := dao.GetPubkeyDao(ctx)
+
+	recorder := &recordingNotificationClient{}
+	original := notifications.GetNotificationClient
+	notifications.GetNotificationClient = func(_ context.Context) notifications.NotificationClient { return recorder }
+	t.Cleanup(func() { notifications.GetNotificationClient = original })
+
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().Add(30 * 24 * time.Hour)
+
+	expiring := factories.NewPubkeyRSA()
+	expiring.ExpiresAt = &soon
+	require.NoError(t, daoStubs.AddPubkey(ctx, expiring))
+
+	notExpiring := factories.NewPubkeyED25519()
+	notExpiring.ExpiresAt = &later
+	require.NoError(t, daoStubs.AddPubkey(ctx, notExpiring))
+
+	err := jobs.DoNotifyPubkeyExpiry(ctx, &jobs.NotifyPubkeyExpiryTaskArgs{NotifyDays: 7})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{expiring.ID}, recorder.pubkeys)
+	assert.Equal(t, []string{identity.Identity(ctx).Identity.OrgID}, recorder.orgIds)
+
+	t.Run("notifies only once", func(t *testing.T) {
+		err = jobs.DoNotifyPubkeyExpiry(ctx, &jobs.NotifyPubkeyExpiryTaskArgs{NotifyDays: 7})
+		require.NoError(t, err)
+		assert.Len(t, recorder.pubkeys, 1)
+
+		pk, err := pkDao.GetById(ctx, expiring.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, pk.ExpiryNotifiedAt)
+	})
+}
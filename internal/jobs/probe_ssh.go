@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// sshProbePort is the probed TCP port, tests listen on a random port
+var sshProbePort = "22"
+
+type ProbeSSHTaskArgs struct {
+	// Associated reservation
+	ReservationID int64
+}
+
+// Unmarshall arguments and handle error
+func HandleProbeSSH(ctx context.Context, job *worker.Job) error {
+	args, ok := job.Args.(ProbeSSHTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, args: %#v", ErrTypeAssertion, job.ID, job.Args)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	logger := zerolog.Ctx(ctx).With().Int64("reservation_id", args.ReservationID).Logger()
+	ctx = logger.WithContext(ctx)
+
+	jobErr := DoProbeSSH(ctx, &args)
+	if jobErr != nil {
+		logger.Error().Err(jobErr).Msg("Unable to probe SSH of reservation instances")
+	}
+	return jobErr
+}
+
+// DoProbeSSH connects to the SSH port of all instances of the reservation until they accept
+// connections or attempts are exhausted, the result is stored as the ssh_ready flag. Instances
+// without a public IP address are never ready, reservations without instances are not updated.
+func DoProbeSSH(ctx context.Context, args *ProbeSSHTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started SSH probe job")
+
+	instances, err := dao.GetReservationDao(ctx).ListInstances(ctx, args.ReservationID)
+	if err != nil {
+		return fmt.Errorf("cannot get instances list: %w", err)
+	}
+
+	if len(instances) == 0 {
+		logger.Info().Msg("Reservation has no instances, nothing to probe")
+		return nil
+	}
+
+	ready := true
+	for _, instance := range instances {
+		if instance.Detail.PublicIPv4 == "" {
+			logger.Info().Str("instance_id", instance.InstanceID).Msg("Instance has no public IP address, SSH is not reachable")
+			ready = false
+			break
+		}
+
+		address := net.JoinHostPort(instance.Detail.PublicIPv4, sshProbePort)
+		err = probeTCP(ctx, address)
+		if err != nil {
+			logger.Info().Err(err).Str("instance_id", instance.InstanceID).Msgf("SSH of instance not reachable at %s", address)
+			ready = false
+			break
+		}
+		logger.Debug().Str("instance_id", instance.InstanceID).Msgf("SSH of instance reachable at %s", address)
+	}
+
+	err = dao.GetReservationDao(ctx).UnscopedUpdateSSHReady(ctx, args.ReservationID, ready)
+	if err != nil {
+		return fmt.Errorf("cannot update SSH ready flag: %w", err)
+	}
+	return nil
+}
+
+// probeTCP attempts to open a TCP connection according to the SSH probe configuration, the last
+// connection error is returned when all attempts fail.
+func probeTCP(ctx context.Context, address string) error {
+	dialer := net.Dialer{Timeout: config.Application.SSHProbe.Timeout}
+	attempts := config.Application.SSHProbe.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			_ = conn.Close()
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		if sleepErr := sleepCtx(ctx, config.Application.SSHProbe.Interval); sleepErr != nil {
+			return fmt.Errorf("interrupted while waiting for SSH: %w", sleepErr)
+		}
+	}
+	return fmt.Errorf("no connection after %d attempt(s): %w", attempts, err)
+}
+
+// enqueueSSHProbe enqueues the SSH probe of a successfully launched reservation when the probe
+// is enabled. Failures are only logged, the reservation stays without the ssh_ready flag.
+func enqueueSSHProbe(ctx context.Context, reservationId int64) {
+	if !config.Application.SSHProbe.Enabled {
+		return
+	}
+
+	job := worker.Job{
+		Type:      TypeProbeSSH,
+		Identity:  identity.Identity(ctx),
+		AccountID: identity.AccountId(ctx),
+		Args:      ProbeSSHTaskArgs{ReservationID: reservationId},
+	}
+	err := queue.GetEnqueuer(ctx).Enqueue(ctx, &job)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to enqueue SSH probe job")
+	}
+}
@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	queueStub "github.com/RHEnVision/provisioning-backend/internal/queue/stub"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareSSHProbe(t *testing.T) context.Context {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	originalPort, originalProbe := sshProbePort, config.Application.SSHProbe
+	sshProbePort = port
+	config.Application.SSHProbe.Enabled = true
+	config.Application.SSHProbe.Timeout = time.Second
+	config.Application.SSHProbe.Attempts = 2
+	config.Application.SSHProbe.Interval = time.Millisecond
+	t.Cleanup(func() {
+		sshProbePort = originalPort
+		config.Application.SSHProbe = originalProbe
+	})
+
+	ctx := daoStubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = daoStubs.WithReservationDao(ctx)
+	return ctx
+}
+
+func createProbedReservation(t *testing.T, ctx context.Context, ips ...string) int64 {
+	t.Helper()
+
+	reservation := &models.AWSReservation{Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeAWS
+	require.NoError(t, daoStubs.AddAWSReservation(ctx, reservation))
+
+	for i, ip := range ips {
+		require.NoError(t, dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{
+			ReservationID: reservation.ID,
+			InstanceID:    "i-" + string(rune('a'+i)),
+			Detail:        models.ReservationInstanceDetail{PublicIPv4: ip},
+		}))
+	}
+	return reservation.ID
+}
+
+func sshReady(t *testing.T, ctx context.Context, id int64) *bool {
+	t.Helper()
+
+	reservation, err := dao.GetReservationDao(ctx).GetById(ctx, id)
+	require.NoError(t, err)
+	if !reservation.SSHReady.Valid {
+		return nil
+	}
+	return &reservation.SSHReady.Bool
+}
+
+func TestDoProbeSSH(t *testing.T) {
+	ctx := prepareSSHProbe(t)
+
+	t.Run("reachable", func(t *testing.T) {
+		id := createProbedReservation(t, ctx, "127.0.0.1", "127.0.0.1")
+		require.NoError(t, DoProbeSSH(ctx, &ProbeSSHTaskArgs{ReservationID: id}))
+
+		ready := sshReady(t, ctx, id)
+		require.NotNil(t, ready)
+		assert.True(t, *ready)
+	})
+
+	t.Run("without public IP", func(t *testing.T) {
+		id := createProbedReservation(t, ctx, "127.0.0.1", "")
+		require.NoError(t, DoProbeSSH(ctx, &ProbeSSHTaskArgs{ReservationID: id}))
+
+		ready := sshReady(t, ctx, id)
+		require.NotNil(t, ready)
+		assert.False(t, *ready)
+	})
+
+	t.Run("without instances", func(t *testing.T) {
+		id := createProbedReservation(t, ctx)
+		require.NoError(t, DoProbeSSH(ctx, &ProbeSSHTaskArgs{ReservationID: id}))
+		assert.Nil(t, sshReady(t, ctx, id))
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		closed, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		_, sshProbePort, err = net.SplitHostPort(closed.Addr().String())
+		require.NoError(t, err)
+		require.NoError(t, closed.Close())
+
+		id := createProbedReservation(t, ctx, "127.0.0.1")
+		require.NoError(t, DoProbeSSH(ctx, &ProbeSSHTaskArgs{ReservationID: id}))
+
+		ready := sshReady(t, ctx, id)
+		require.NotNil(t, ready)
+		assert.False(t, *ready)
+	})
+}
+
+func TestEnqueueSSHProbe(t *testing.T) {
+	ctx := queueStub.WithEnqueuer(prepareSSHProbe(t))
+
+	enqueueSSHProbe(ctx, 42)
+	jobs := queueStub.EnqueuedJobs(ctx)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, TypeProbeSSH, jobs[0].Type)
+	assert.Equal(t, ProbeSSHTaskArgs{ReservationID: 42}, jobs[0].Args)
+
+	t.Run("disabled", func(t *testing.T) {
+		config.Application.SSHProbe.Enabled = false
+		enqueueSSHProbe(ctx, 43)
+		assert.Len(t, queueStub.EnqueuedJobs(ctx), 1)
+	})
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/azure"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/ec2"
+	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/gcp"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+type RefreshAvailabilityTaskArgs struct {
+	// Provider to refresh instance type availability for
+	Provider models.ProviderType
+}
+
+var ErrUnsupportedRefreshProvider = errors.New("availability refresh not supported for provider")
+
+// Unmarshall arguments and handle error
+func HandleRefreshAvailability(ctx context.Context, job *worker.Job) error {
+	args, ok := job.Args.(RefreshAvailabilityTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, args: %#v", ErrTypeAssertion, job.ID, job.Args)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	logger := zerolog.Ctx(ctx).With().Str("provider", args.Provider.String()).Logger()
+	ctx = logger.WithContext(ctx)
+
+	jobErr := DoRefreshAvailability(ctx, &args)
+	if jobErr != nil {
+		logger.Error().Err(jobErr).Msg("Unable to refresh instance type availability")
+	}
+	return jobErr
+}
+
+// DoRefreshAvailability fetches instance types available per region and zone from provider API
+// and stores them in the database.
+func DoRefreshAvailability(ctx context.Context, args *RefreshAvailabilityTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started refresh availability job")
+
+	regionalTypes := clients.NewRegionalInstanceTypes()
+	var err error
+	switch args.Provider {
+	case models.ProviderTypeAWS:
+		err = fetchAvailabilityEC2(ctx, regionalTypes)
+	case models.ProviderTypeAzure:
+		err = fetchAvailabilityAzure(ctx, regionalTypes)
+	case models.ProviderTypeGCP:
+		err = fetchAvailabilityGCP(ctx, regionalTypes)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedRefreshProvider, args.Provider.String())
+	}
+	if err != nil {
+		return err
+	}
+
+	availability := make([]*models.InstanceTypeAvailability, 0)
+	err = regionalTypes.Each(func(region, zone string, names []clients.InstanceTypeName) error {
+		types := make([]string, len(names))
+		for i, name := range names {
+			types[i] = name.String()
+		}
+		availability = append(availability, &models.InstanceTypeAvailability{
+			Provider:      args.Provider,
+			Region:        region,
+			Zone:          zone,
+			InstanceTypes: types,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to process regional types: %w", err)
+	}
+
+	if len(availability) == 0 {
+		// do not wipe existing data when provider returns nothing
+		logger.Warn().Msg("No instance type availability returned from provider, skipping")
+		return nilUnlessTimeout(ctx)
+	}
+
+	err = dao.GetInstanceTypeAvailabilityDao(ctx).UnscopedReplace(ctx, args.Provider, availability)
+	if err != nil {
+		return fmt.Errorf("unable to store availability: %w", err)
+	}
+
+	logger.Info().Msgf("Refreshed instance type availability for %d regions and zones", len(availability))
+	return nilUnlessTimeout(ctx)
+}
+
+func fetchAvailabilityEC2(ctx context.Context, regionalTypes *clients.RegionalTypeAvailability) error {
+	logger := zerolog.Ctx(ctx)
+	defaultClient, err := clients.GetServiceEC2Client(ctx, config.AWS.DefaultRegion)
+	if err != nil {
+		return fmt.Errorf("unable to get default EC2 client: %w", err)
+	}
+
+	regions, err := defaultClient.ListAllRegions(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list EC2 regions: %w", err)
+	}
+
+	for _, region := range regions {
+		client, regionErr := clients.GetServiceEC2Client(ctx, region.String())
+		if regionErr != nil {
+			return fmt.Errorf("unable to get regional EC2 client: %w", regionErr)
+		}
+		instTypes, regionErr := client.ListInstanceTypes(ctx)
+		if regionErr != nil {
+			// regions which are not enabled for the service account are skipped
+			logger.Warn().Err(regionErr).Msgf("Unable to list EC2 instance types in region %s, skipping", region.String())
+			continue
+		}
+		for _, instanceType := range instTypes {
+			regionalTypes.Add(region.String(), "", *instanceType)
+		}
+	}
+
+	return nil
+}
+
+func fetchAvailabilityAzure(ctx context.Context, regionalTypes *clients.RegionalTypeAvailability) error {
+	client, err := clients.GetServiceAzureClient(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get Azure service client: %w", err)
+	}
+
+	err = client.RegisterInstanceTypes(ctx, clients.NewRegisteredInstanceTypes(), regionalTypes)
+	if err != nil {
+		return fmt.Errorf("unable to list Azure instance types: %w", err)
+	}
+
+	return nil
+}
+
+func fetchAvailabilityGCP(ctx context.Context, regionalTypes *clients.RegionalTypeAvailability) error {
+	client, err := clients.GetServiceGCPClient(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get GCP service client: %w", err)
+	}
+
+	err = client.RegisterInstanceTypes(ctx, clients.NewRegisteredInstanceTypes(), regionalTypes)
+	if err != nil {
+		return fmt.Errorf("unable to list GCP instance types: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// RunningJobHeartbeater records running jobs in the database, see RequeueStaleJobs.
+type RunningJobHeartbeater struct{}
+
+var _ worker.Heartbeater = RunningJobHeartbeater{}
+
+func (RunningJobHeartbeater) Started(ctx context.Context, job *worker.Job) error {
+	payload, err := worker.EncodeJob(job)
+	if err != nil {
+		return fmt.Errorf("unable to encode running job: %w", err)
+	}
+
+	err = dao.GetRunningJobDao(ctx).UnscopedStart(ctx, &models.RunningJob{
+		JobID:   job.ID.String(),
+		JobType: job.Type.String(),
+		Worker:  config.Hostname(),
+		Payload: payload,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to store running job: %w", err)
+	}
+	return nil
+}
+
+func (RunningJobHeartbeater) Beat(ctx context.Context, job *worker.Job) error {
+	err := dao.GetRunningJobDao(ctx).UnscopedHeartbeat(ctx, job.ID.String())
+	if err != nil {
+		return fmt.Errorf("unable to update job heartbeat: %w", err)
+	}
+	return nil
+}
+
+func (RunningJobHeartbeater) Finished(ctx context.Context, job *worker.Job) error {
+	err := dao.GetRunningJobDao(ctx).UnscopedFinish(ctx, job.ID.String())
+	if err != nil {
+		return fmt.Errorf("unable to delete running job: %w", err)
+	}
+	return nil
+}
+
+// ErrJobInterrupted is stored into reservations and instance actions of non-idempotent jobs
+// whose worker died mid-execution.
+var ErrJobInterrupted = errors.New("interrupted, instances may exist")
+
+// RequeueStaleJobs enqueues again jobs without heartbeat for the given duration, their worker
+// died mid-execution. Jobs are enqueued with the same attempt number since the attempt never
+// finished. Launch and instance action jobs are not idempotent and are never enqueued again,
+// their reservation or instance action is marked as failed instead. Returns number of enqueued
+// jobs.
+func RequeueStaleJobs(ctx context.Context, timeout time.Duration) (int, error) {
+	logger := zerolog.Ctx(ctx)
+
+	stale, err := dao.GetRunningJobDao(ctx).UnscopedReapStale(ctx, time.Now().Add(-timeout))
+	if err != nil {
+		return 0, fmt.Errorf("unable to reap stale jobs: %w", err)
+	}
+
+	count := 0
+	for _, running := range stale {
+		job, err := worker.DecodeJob(running.Payload)
+		if err != nil {
+			logger.Error().Err(err).Str("job_id", running.JobID).Msg("Unable to decode stale job, skipping")
+			continue
+		}
+
+		if failInterruptedJob(ctx, job) {
+			logger.Warn().Str("job_id", running.JobID).Str("job_type", running.JobType).Str("worker", running.Worker).
+				Msgf("Job without heartbeat since %s is not idempotent, marked as failed", running.HeartbeatAt.Format(time.RFC3339))
+			continue
+		}
+
+		logger.Warn().Str("job_id", running.JobID).Str("job_type", running.JobType).Str("worker", running.Worker).
+			Msgf("Job without heartbeat since %s, enqueuing again", running.HeartbeatAt.Format(time.RFC3339))
+		err = queue.GetEnqueuer(ctx).Enqueue(ctx, job)
+		if err != nil {
+			logger.Error().Err(err).Str("job_id", running.JobID).Msg("Unable to enqueue stale job, job is lost")
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// failInterruptedJob marks reservation of an interrupted launch job or an interrupted instance
+// action as failed. Returns false for idempotent jobs which can be enqueued again.
+func failInterruptedJob(ctx context.Context, job *worker.Job) bool {
+	jobCtx := identity.WithMachine(identity.WithIdentity(ctx, job.Identity), job.Machine)
+	jobCtx = identity.WithAccountId(jobCtx, job.AccountID)
+
+	switch args := job.Args.(type) {
+	case LaunchInstanceAWSTaskArgs, LaunchInstanceAzureTaskArgs, LaunchInstanceGCPTaskArgs:
+		finishWithError(jobCtx, jobReservationId(args), ErrJobInterrupted)
+		return true
+	case InstanceActionTaskArgs:
+		err := dao.GetInstanceActionDao(jobCtx).UnscopedUpdateStatus(jobCtx, args.ActionID, models.InstanceActionFailed, ErrJobInterrupted.Error())
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Int64("instance_action_id", args.ActionID).Msg("Unable to mark interrupted instance action as failed")
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/queue/stub"
+	testIdentity "github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareRunningJobContext() (context.Context, *worker.Job) {
+	gob.Register(jobs.NoopJobArgs{})
+	ctx := stubs.WithRunningJobDao(context.Background())
+	ctx = stub.WithEnqueuer(ctx)
+	job := &worker.Job{
+		ID:   uuid.New(),
+		Type: jobs.TypeNoop,
+		Args: jobs.NoopJobArgs{},
+	}
+	return ctx, job
+}
+
+func TestRunningJobHeartbeater(t *testing.T) {
+	ctx, job := prepareRunningJobContext()
+	hb := jobs.RunningJobHeartbeater{}
+
+	require.NoError(t, hb.Started(ctx, job))
+	assert.Equal(t, 1, stubs.RunningJobStubCount(ctx))
+
+	require.NoError(t, hb.Beat(ctx, job))
+
+	require.NoError(t, hb.Finished(ctx, job))
+	assert.Equal(t, 0, stubs.RunningJobStubCount(ctx))
+
+	require.Error(t, hb.Beat(ctx, job))
+}
+
+func TestRequeueStaleJobs(t *testing.T) {
+	ctx, job := prepareRunningJobContext()
+	require.NoError(t, jobs.RunningJobHeartbeater{}.Started(ctx, job))
+
+	t.Run("Alive", func(t *testing.T) {
+		count, err := jobs.RequeueStaleJobs(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, 1, stubs.RunningJobStubCount(ctx))
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		// negative timeout makes the just started job stale
+		count, err := jobs.RequeueStaleJobs(ctx, -time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 0, stubs.RunningJobStubCount(ctx))
+
+		enqueued := stub.EnqueuedJobs(ctx)
+		require.Len(t, enqueued, 1)
+		assert.Equal(t, job.ID, enqueued[0].ID)
+		assert.Equal(t, jobs.TypeNoop, enqueued[0].Type)
+	})
+}
+
+func TestRequeueStaleJobsNotIdempotent(t *testing.T) {
+	ctx, _ := prepareRunningJobContext()
+	ctx = stubs.WithAccountDaoOne(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = stubs.WithInstanceActionDao(ctx)
+	tenantCtx := testIdentity.WithTenant(t, ctx)
+	gob.Register(jobs.LaunchInstanceAWSTaskArgs{})
+	gob.Register(jobs.InstanceActionTaskArgs{})
+
+	reservation := &models.AWSReservation{Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeAWS
+	require.NoError(t, stubs.AddAWSReservation(tenantCtx, reservation))
+
+	action := &models.InstanceAction{ReservationID: reservation.ID, InstanceID: "i-1", Action: models.InstanceActionStop}
+	require.NoError(t, dao.GetInstanceActionDao(tenantCtx).Create(tenantCtx, action))
+
+	launchJob := &worker.Job{
+		ID:        uuid.New(),
+		Type:      jobs.TypeLaunchInstanceAws,
+		AccountID: 1,
+		Identity:  identity.Identity(tenantCtx),
+		Args:      jobs.LaunchInstanceAWSTaskArgs{ReservationID: reservation.ID},
+	}
+	actionJob := &worker.Job{
+		ID:        uuid.New(),
+		Type:      jobs.TypeInstanceActionAws,
+		AccountID: 1,
+		Identity:  identity.Identity(tenantCtx),
+		Args:      jobs.InstanceActionTaskArgs{ActionID: action.ID, ReservationID: reservation.ID},
+	}
+	require.NoError(t, jobs.RunningJobHeartbeater{}.Started(ctx, launchJob))
+	require.NoError(t, jobs.RunningJobHeartbeater{}.Started(ctx, actionJob))
+
+	count, err := jobs.RequeueStaleJobs(ctx, -time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, stubs.RunningJobStubCount(ctx))
+	assert.Empty(t, stub.EnqueuedJobs(ctx))
+
+	stored, err := dao.GetReservationDao(tenantCtx).GetById(tenantCtx, reservation.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Success.Valid)
+	assert.False(t, stored.Success.Bool)
+	assert.Equal(t, jobs.ErrJobInterrupted.Error(), stored.Error)
+
+	storedAction, err := dao.GetInstanceActionDao(tenantCtx).GetById(tenantCtx, action.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.InstanceActionFailed, storedAction.Status)
+	assert.Equal(t, jobs.ErrJobInterrupted.Error(), storedAction.Error)
+}
@@ -0,0 +1,177 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// amqpPrefetch is the number of unacknowledged messages delivered to the consumer
+const amqpPrefetch = 10
+
+// amqpBroker publishes messages to a durable topic exchange with Kafka topics as routing
+// keys. Consumers declare a durable queue per topic bound to the exchange, therefore
+// messages sent before the first consumer started are not delivered.
+type amqpBroker struct {
+	url         string
+	exchange    string
+	queuePrefix string
+}
+
+var _ Broker = &amqpBroker{}
+
+func InitializeAMQPBroker(ctx context.Context) error {
+	var err error
+	broker, err = NewAMQPBroker(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to initialize amqp: %w", err)
+	}
+
+	InitializeTopicRequests(ctx)
+
+	return nil
+}
+
+func NewAMQPBroker(ctx context.Context) (Broker, error) {
+	u, err := url.Parse(config.Kafka.AMQP.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid AMQP URL: %s", ErrBrokerConfig, err.Error())
+	}
+	if u.Scheme != "amqp" && u.Scheme != "amqps" {
+		return nil, fmt.Errorf("%w: unsupported AMQP URL scheme %s", ErrBrokerConfig, u.Scheme)
+	}
+
+	zerolog.Ctx(ctx).Debug().Msgf("Setting up AMQP broker: %s exchange %s", u.Redacted(), config.Kafka.AMQP.Exchange)
+
+	return &amqpBroker{
+		url:         config.Kafka.AMQP.URL,
+		exchange:    config.Kafka.AMQP.Exchange,
+		queuePrefix: config.Kafka.AMQP.QueuePrefix,
+	}, nil
+}
+
+// Send publishes messages over a new connection and waits until the broker confirms them.
+func (b *amqpBroker) Send(ctx context.Context, messages ...*GenericMessage) error {
+	logger := zerolog.Ctx(ctx)
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	conn, err := dialAMQP(ctx, b.url)
+	if err != nil {
+		return fmt.Errorf("cannot connect to amqp broker: %w", err)
+	}
+	defer conn.close()
+
+	if err = conn.declareExchange(b.exchange); err != nil {
+		return fmt.Errorf("cannot declare amqp exchange: %w", err)
+	}
+	if err = conn.confirmSelect(); err != nil {
+		return fmt.Errorf("cannot enable amqp confirms: %w", err)
+	}
+
+	logger.Trace().Msgf("Sending %d messages to AMQP", len(messages))
+	now := time.Now()
+	for _, m := range messages {
+		headers := make(map[string]string, len(m.Headers)+1)
+		for _, h := range m.headersWithKey() {
+			headers[h.Key] = h.Value
+		}
+		if err = conn.publish(b.exchange, m.Topic, headers, m.Value, now); err != nil {
+			return fmt.Errorf("cannot send amqp message(s): %w", err)
+		}
+	}
+	if err = conn.flush(); err != nil {
+		return fmt.Errorf("cannot send amqp message(s): %w", err)
+	}
+	if err = conn.waitConfirms(uint64(len(messages))); err != nil {
+		return fmt.Errorf("cannot send amqp message(s): %w", err)
+	}
+
+	return nil
+}
+
+// Consume reads messages of the topic queue and reconnects on errors. Messages published
+// before the since time are acknowledged without processing.
+func (b *amqpBroker) Consume(ctx context.Context, topic string, since time.Time, handler func(ctx context.Context, message *GenericMessage)) {
+	logger := zerolog.Ctx(ctx)
+
+	for {
+		err := b.consume(ctx, topic, since, handler)
+		if ctx.Err() != nil {
+			logger.Debug().Msg("AMQP receiver has been cancelled")
+			return
+		}
+		logger.Warn().Err(err).Msg("AMQP receiver has been closed, reconnecting")
+		if !waitRetry(ctx) {
+			return
+		}
+	}
+}
+
+func (b *amqpBroker) consume(ctx context.Context, topic string, since time.Time, handler func(ctx context.Context, message *GenericMessage)) error {
+	logger := zerolog.Ctx(ctx)
+	queue := b.queuePrefix + topic
+	// message timestamps have seconds precision
+	since = since.Truncate(time.Second)
+
+	conn, err := dialAMQP(ctx, b.url)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	// unblock reading when the context is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.conn.Close()
+		case <-done:
+		}
+	}()
+
+	if err = conn.declareExchange(b.exchange); err != nil {
+		return err
+	}
+	if err = conn.declareQueue(queue); err != nil {
+		return err
+	}
+	if err = conn.bindQueue(queue, b.exchange, topic); err != nil {
+		return err
+	}
+	if err = conn.consume(queue, amqpPrefetch); err != nil {
+		return err
+	}
+	logger.Debug().Msgf("Consuming AMQP queue %s", queue)
+
+	var d *amqpDelivery
+	for {
+		d, err = conn.next()
+		if err != nil {
+			return err
+		}
+
+		if !d.timestamp.IsZero() && d.timestamp.Before(since) {
+			logger.Trace().Msgf("Skipping message published at %s", d.timestamp)
+		} else {
+			headers := make([]GenericHeader, 0, len(d.headers))
+			for k, v := range d.headers {
+				headers = append(headers, GenericHeader{Key: k, Value: fmt.Sprint(v)})
+			}
+			message := newGenericMessage(topic, d.body, headers)
+			logger.Trace().Bytes("payload", message.Value).Msgf("Received message with routing key %s", d.routingKey)
+			handler(newMessageContext(ctx, message), message)
+		}
+
+		if err = conn.ack(d.tag); err != nil {
+			return err
+		}
+	}
+}
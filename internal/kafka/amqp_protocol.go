@@ -0,0 +1,751 @@
+package kafka
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+)
+
+// Minimal AMQP 0-9-1 client: a single channel, publishing with confirms and consuming
+// with manual acknowledgements. Heartbeats are disabled.
+
+const (
+	amqpFrameMethod    = 1
+	amqpFrameHeader    = 2
+	amqpFrameBody      = 3
+	amqpFrameHeartbeat = 8
+	amqpFrameEnd       = 0xCE
+	amqpFrameMaxSize   = 131072
+	amqpChannel        = 1
+	amqpClassBasic     = 60
+
+	amqpDeliveryPersistent  = 2
+	amqpExchangeDurableFlag = 0x02
+	amqpQueueDurableFlag    = 0x02
+)
+
+// basic class property flags
+const (
+	amqpPropContentType     = 0x8000
+	amqpPropContentEncoding = 0x4000
+	amqpPropHeaders         = 0x2000
+	amqpPropDeliveryMode    = 0x1000
+	amqpPropPriority        = 0x0800
+	amqpPropCorrelationID   = 0x0400
+	amqpPropReplyTo         = 0x0200
+	amqpPropExpiration      = 0x0100
+	amqpPropMessageID       = 0x0080
+	amqpPropTimestamp       = 0x0040
+	amqpPropType            = 0x0020
+	amqpPropUserID          = 0x0010
+	amqpPropAppID           = 0x0008
+	amqpPropClusterID       = 0x0004
+)
+
+var amqpProtocolHeader = []byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1}
+
+type amqpMethodID struct {
+	class, method uint16
+}
+
+var (
+	amqpConnectionStart   = amqpMethodID{10, 10}
+	amqpConnectionStartOk = amqpMethodID{10, 11}
+	amqpConnectionTune    = amqpMethodID{10, 30}
+	amqpConnectionTuneOk  = amqpMethodID{10, 31}
+	amqpConnectionOpen    = amqpMethodID{10, 40}
+	amqpConnectionOpenOk  = amqpMethodID{10, 41}
+	amqpConnectionClose   = amqpMethodID{10, 50}
+	amqpConnectionCloseOk = amqpMethodID{10, 51}
+	amqpChannelOpen       = amqpMethodID{20, 10}
+	amqpChannelOpenOk     = amqpMethodID{20, 11}
+	amqpChannelClose      = amqpMethodID{20, 40}
+	amqpExchangeDeclare   = amqpMethodID{40, 10}
+	amqpExchangeDeclareOk = amqpMethodID{40, 11}
+	amqpQueueDeclare      = amqpMethodID{50, 10}
+	amqpQueueDeclareOk    = amqpMethodID{50, 11}
+	amqpQueueBind         = amqpMethodID{50, 20}
+	amqpQueueBindOk       = amqpMethodID{50, 21}
+	amqpBasicQos          = amqpMethodID{60, 10}
+	amqpBasicQosOk        = amqpMethodID{60, 11}
+	amqpBasicConsume      = amqpMethodID{60, 20}
+	amqpBasicConsumeOk    = amqpMethodID{60, 21}
+	amqpBasicCancel       = amqpMethodID{60, 30}
+	amqpBasicPublish      = amqpMethodID{60, 40}
+	amqpBasicDeliver      = amqpMethodID{60, 60}
+	amqpBasicAck          = amqpMethodID{60, 80}
+	amqpBasicNack         = amqpMethodID{60, 120}
+	amqpConfirmSelect     = amqpMethodID{85, 10}
+	amqpConfirmSelectOk   = amqpMethodID{85, 11}
+)
+
+var ErrAMQP = errors.New("amqp error")
+
+// amqpWriter encodes AMQP data types
+type amqpWriter struct {
+	bytes.Buffer
+}
+
+func (w *amqpWriter) octet(v uint8) {
+	w.WriteByte(v)
+}
+
+func (w *amqpWriter) short(v uint16) {
+	_ = binary.Write(&w.Buffer, binary.BigEndian, v)
+}
+
+func (w *amqpWriter) long(v uint32) {
+	_ = binary.Write(&w.Buffer, binary.BigEndian, v)
+}
+
+func (w *amqpWriter) longlong(v uint64) {
+	_ = binary.Write(&w.Buffer, binary.BigEndian, v)
+}
+
+func (w *amqpWriter) shortstr(s string) {
+	if len(s) > math.MaxUint8 {
+		s = s[:math.MaxUint8]
+	}
+	w.octet(uint8(len(s)))
+	w.WriteString(s)
+}
+
+func (w *amqpWriter) longstr(s string) {
+	w.long(uint32(len(s)))
+	w.WriteString(s)
+}
+
+// table writes a field table with string values, keys are sorted
+func (w *amqpWriter) table(t map[string]string) {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var inner amqpWriter
+	for _, k := range keys {
+		inner.shortstr(k)
+		inner.octet('S')
+		inner.longstr(t[k])
+	}
+	w.long(uint32(inner.Len()))
+	w.Write(inner.Bytes())
+}
+
+// amqpReader decodes AMQP data types, the first error is kept and zero values are returned
+// afterwards
+type amqpReader struct {
+	data []byte
+	err  error
+}
+
+func (r *amqpReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrAMQP)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *amqpReader) octet() uint8 {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *amqpReader) short() uint16 {
+	b := r.next(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *amqpReader) long() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *amqpReader) longlong() uint64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (r *amqpReader) shortstr() string {
+	return string(r.next(int(r.octet())))
+}
+
+func (r *amqpReader) longstr() string {
+	return string(r.next(int(r.long())))
+}
+
+func (r *amqpReader) table() map[string]any {
+	inner := &amqpReader{data: r.next(int(r.long()))}
+	if r.err != nil {
+		return nil
+	}
+	t := make(map[string]any)
+	for len(inner.data) > 0 && inner.err == nil {
+		key := inner.shortstr()
+		t[key] = inner.field()
+	}
+	r.err = inner.err
+	return t
+}
+
+func (r *amqpReader) field() any {
+	switch kind := r.octet(); kind {
+	case 't':
+		return r.octet() != 0
+	case 'b':
+		return int8(r.octet())
+	case 'B':
+		return r.octet()
+	case 's':
+		return int16(r.short())
+	case 'u':
+		return r.short()
+	case 'I':
+		return int32(r.long())
+	case 'i':
+		return r.long()
+	case 'l':
+		return int64(r.longlong())
+	case 'f':
+		return math.Float32frombits(r.long())
+	case 'd':
+		return math.Float64frombits(r.longlong())
+	case 'D':
+		scale := r.octet()
+		return float64(int32(r.long())) / math.Pow10(int(scale))
+	case 'S', 'x':
+		return r.longstr()
+	case 'A':
+		inner := &amqpReader{data: r.next(int(r.long()))}
+		var array []any
+		for len(inner.data) > 0 && inner.err == nil {
+			array = append(array, inner.field())
+		}
+		if r.err == nil {
+			r.err = inner.err
+		}
+		return array
+	case 'T':
+		return time.Unix(int64(r.longlong()), 0)
+	case 'F':
+		return r.table()
+	case 'V':
+		return nil
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: unknown field type %q", ErrAMQP, kind)
+		}
+		return nil
+	}
+}
+
+// amqpProperties are basic class properties used by the broker
+type amqpProperties struct {
+	headers   map[string]any
+	timestamp time.Time
+}
+
+func (r *amqpReader) properties() amqpProperties {
+	var p amqpProperties
+	flags := r.short()
+	if flags&amqpPropContentType != 0 {
+		r.shortstr()
+	}
+	if flags&amqpPropContentEncoding != 0 {
+		r.shortstr()
+	}
+	if flags&amqpPropHeaders != 0 {
+		p.headers = r.table()
+	}
+	if flags&amqpPropDeliveryMode != 0 {
+		r.octet()
+	}
+	if flags&amqpPropPriority != 0 {
+		r.octet()
+	}
+	for _, flag := range []uint16{amqpPropCorrelationID, amqpPropReplyTo, amqpPropExpiration, amqpPropMessageID} {
+		if flags&flag != 0 {
+			r.shortstr()
+		}
+	}
+	if flags&amqpPropTimestamp != 0 {
+		p.timestamp = time.Unix(int64(r.longlong()), 0)
+	}
+	for _, flag := range []uint16{amqpPropType, amqpPropUserID, amqpPropAppID, amqpPropClusterID} {
+		if flags&flag != 0 {
+			r.shortstr()
+		}
+	}
+	return p
+}
+
+type amqpFrame struct {
+	kind    uint8
+	channel uint16
+	payload []byte
+}
+
+type amqpDelivery struct {
+	tag        uint64
+	routingKey string
+	amqpProperties
+	body []byte
+}
+
+type amqpConn struct {
+	conn     net.Conn
+	r        *bufio.Reader
+	w        *bufio.Writer
+	frameMax uint32
+}
+
+// dialAMQP connects to the broker URL and opens a channel.
+func dialAMQP(ctx context.Context, rawURL string) (*amqpConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid URL: %s", ErrAMQP, err.Error())
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	switch u.Scheme {
+	case "amqp":
+		conn, err = dialer.DialContext(ctx, "tcp", amqpAddress(u, "5672"))
+	case "amqps":
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config:    &tls.Config{MinVersion: tls.VersionTLS12, ServerName: u.Hostname()},
+		}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", amqpAddress(u, "5671"))
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %s", ErrAMQP, u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAMQP, err.Error())
+	}
+
+	username, password := "guest", "guest"
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+	vhost := strings.TrimPrefix(u.Path, "/")
+	if vhost == "" {
+		vhost = "/"
+	}
+
+	c := &amqpConn{
+		conn:     conn,
+		r:        bufio.NewReader(conn),
+		w:        bufio.NewWriter(conn),
+		frameMax: amqpFrameMaxSize,
+	}
+	if err = c.handshake(username, password, vhost); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func amqpAddress(u *url.URL, defaultPort string) string {
+	if u.Port() == "" {
+		return net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+	return u.Host
+}
+
+func (c *amqpConn) handshake(username, password, vhost string) error {
+	if _, err := c.w.Write(amqpProtocolHeader); err != nil {
+		return fmt.Errorf("%w: %s", ErrAMQP, err.Error())
+	}
+	if err := c.flush(); err != nil {
+		return err
+	}
+	if _, err := c.expect(amqpConnectionStart); err != nil {
+		return err
+	}
+
+	var startOk amqpWriter
+	startOk.table(map[string]string{"product": version.KafkaClientID})
+	startOk.shortstr("PLAIN")
+	startOk.longstr("\x00" + username + "\x00" + password)
+	startOk.shortstr("en_US")
+	if err := c.writeMethod(0, amqpConnectionStartOk, startOk.Bytes()); err != nil {
+		return err
+	}
+	if err := c.flush(); err != nil {
+		return err
+	}
+
+	tune, err := c.expect(amqpConnectionTune)
+	if err != nil {
+		return err
+	}
+	channelMax := tune.short()
+	if frameMax := tune.long(); frameMax != 0 && frameMax < c.frameMax {
+		c.frameMax = frameMax
+	}
+
+	var tuneOk amqpWriter
+	tuneOk.short(channelMax)
+	tuneOk.long(c.frameMax)
+	tuneOk.short(0)
+	if err = c.writeMethod(0, amqpConnectionTuneOk, tuneOk.Bytes()); err != nil {
+		return err
+	}
+
+	return c.open(vhost)
+}
+
+// call sends a method and waits for the response method
+func (c *amqpConn) call(channel uint16, id amqpMethodID, args []byte, response amqpMethodID) error {
+	if err := c.writeMethod(channel, id, args); err != nil {
+		return err
+	}
+	if err := c.flush(); err != nil {
+		return err
+	}
+	_, err := c.expect(response)
+	return err
+}
+
+// open opens the connection to the virtual host and the channel
+func (c *amqpConn) open(vhost string) error {
+	var open amqpWriter
+	open.shortstr(vhost)
+	open.shortstr("")
+	open.octet(0)
+	if err := c.call(0, amqpConnectionOpen, open.Bytes(), amqpConnectionOpenOk); err != nil {
+		return err
+	}
+
+	var channelOpen amqpWriter
+	channelOpen.shortstr("")
+	return c.call(amqpChannel, amqpChannelOpen, channelOpen.Bytes(), amqpChannelOpenOk)
+}
+
+func (c *amqpConn) writeFrame(kind uint8, channel uint16, payload []byte) error {
+	var frame amqpWriter
+	frame.octet(kind)
+	frame.short(channel)
+	frame.long(uint32(len(payload)))
+	frame.Write(payload)
+	frame.octet(amqpFrameEnd)
+	if _, err := c.w.Write(frame.Bytes()); err != nil {
+		return fmt.Errorf("%w: %s", ErrAMQP, err.Error())
+	}
+	return nil
+}
+
+func (c *amqpConn) writeMethod(channel uint16, id amqpMethodID, args []byte) error {
+	var method amqpWriter
+	method.short(id.class)
+	method.short(id.method)
+	method.Write(args)
+	return c.writeFrame(amqpFrameMethod, channel, method.Bytes())
+}
+
+func (c *amqpConn) flush() error {
+	if err := c.w.Flush(); err != nil {
+		return fmt.Errorf("%w: %s", ErrAMQP, err.Error())
+	}
+	return nil
+}
+
+// readFrame returns the next frame other than heartbeat
+func (c *amqpConn) readFrame() (*amqpFrame, error) {
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(c.r, header); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrAMQP, err.Error())
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[3:7])+1)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrAMQP, err.Error())
+		}
+		if payload[len(payload)-1] != amqpFrameEnd {
+			return nil, fmt.Errorf("%w: invalid frame end", ErrAMQP)
+		}
+
+		if header[0] == amqpFrameHeartbeat {
+			continue
+		}
+		return &amqpFrame{
+			kind:    header[0],
+			channel: binary.BigEndian.Uint16(header[1:3]),
+			payload: payload[:len(payload)-1],
+		}, nil
+	}
+}
+
+// readMethod returns the next method, closing of the connection or the channel by
+// the broker is returned as an error.
+func (c *amqpConn) readMethod() (amqpMethodID, *amqpReader, error) {
+	frame, err := c.readFrame()
+	if err != nil {
+		return amqpMethodID{}, nil, err
+	}
+	if frame.kind != amqpFrameMethod {
+		return amqpMethodID{}, nil, fmt.Errorf("%w: unexpected frame type %d", ErrAMQP, frame.kind)
+	}
+
+	r := &amqpReader{data: frame.payload}
+	id := amqpMethodID{r.short(), r.short()}
+	if id == amqpConnectionClose || id == amqpChannelClose {
+		code := r.short()
+		text := r.shortstr()
+		return id, nil, fmt.Errorf("%w: closed by broker: %d %s", ErrAMQP, code, text)
+	}
+	return id, r, r.err
+}
+
+func (c *amqpConn) expect(id amqpMethodID) (*amqpReader, error) {
+	got, r, err := c.readMethod()
+	if err != nil {
+		return nil, err
+	}
+	if got != id {
+		return nil, fmt.Errorf("%w: expected method %d.%d, got %d.%d", ErrAMQP, id.class, id.method, got.class, got.method)
+	}
+	return r, nil
+}
+
+func (c *amqpConn) declareExchange(name string) error {
+	var args amqpWriter
+	args.short(0)
+	args.shortstr(name)
+	args.shortstr("topic")
+	args.octet(amqpExchangeDurableFlag)
+	args.table(nil)
+	return c.call(amqpChannel, amqpExchangeDeclare, args.Bytes(), amqpExchangeDeclareOk)
+}
+
+func (c *amqpConn) declareQueue(name string) error {
+	var args amqpWriter
+	args.short(0)
+	args.shortstr(name)
+	args.octet(amqpQueueDurableFlag)
+	args.table(nil)
+	return c.call(amqpChannel, amqpQueueDeclare, args.Bytes(), amqpQueueDeclareOk)
+}
+
+func (c *amqpConn) bindQueue(queue, exchange, routingKey string) error {
+	var args amqpWriter
+	args.short(0)
+	args.shortstr(queue)
+	args.shortstr(exchange)
+	args.shortstr(routingKey)
+	args.octet(0)
+	args.table(nil)
+	return c.call(amqpChannel, amqpQueueBind, args.Bytes(), amqpQueueBindOk)
+}
+
+// consume starts delivery of messages of the queue, up to prefetch messages are unacknowledged
+func (c *amqpConn) consume(queue string, prefetch uint16) error {
+	var qos amqpWriter
+	qos.long(0)
+	qos.short(prefetch)
+	qos.octet(0)
+	if err := c.call(amqpChannel, amqpBasicQos, qos.Bytes(), amqpBasicQosOk); err != nil {
+		return err
+	}
+
+	var args amqpWriter
+	args.short(0)
+	args.shortstr(queue)
+	args.shortstr("")
+	args.octet(0)
+	args.table(nil)
+	return c.call(amqpChannel, amqpBasicConsume, args.Bytes(), amqpBasicConsumeOk)
+}
+
+// next returns the next delivered message
+func (c *amqpConn) next() (*amqpDelivery, error) {
+	for {
+		id, r, err := c.readMethod()
+		if err != nil {
+			return nil, err
+		}
+		switch id {
+		case amqpBasicDeliver:
+			r.shortstr()
+			d := &amqpDelivery{tag: r.longlong()}
+			r.octet()
+			r.shortstr()
+			d.routingKey = r.shortstr()
+			if r.err != nil {
+				return nil, r.err
+			}
+			if err = c.readContent(d); err != nil {
+				return nil, err
+			}
+			return d, nil
+		case amqpBasicCancel:
+			return nil, fmt.Errorf("%w: consumer cancelled by broker", ErrAMQP)
+		}
+	}
+}
+
+func (c *amqpConn) readContent(d *amqpDelivery) error {
+	frame, err := c.readFrame()
+	if err != nil {
+		return err
+	}
+	if frame.kind != amqpFrameHeader {
+		return fmt.Errorf("%w: expected content header, got frame type %d", ErrAMQP, frame.kind)
+	}
+	r := &amqpReader{data: frame.payload}
+	r.short()
+	r.short()
+	size := r.longlong()
+	d.amqpProperties = r.properties()
+	if r.err != nil {
+		return r.err
+	}
+
+	d.body = make([]byte, 0, size)
+	for uint64(len(d.body)) < size {
+		frame, err = c.readFrame()
+		if err != nil {
+			return err
+		}
+		if frame.kind != amqpFrameBody {
+			return fmt.Errorf("%w: expected content body, got frame type %d", ErrAMQP, frame.kind)
+		}
+		d.body = append(d.body, frame.payload...)
+	}
+	return nil
+}
+
+func (c *amqpConn) ack(tag uint64) error {
+	var args amqpWriter
+	args.longlong(tag)
+	args.octet(0)
+	if err := c.writeMethod(amqpChannel, amqpBasicAck, args.Bytes()); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+// confirmSelect enables publisher confirms, each published message is acknowledged by the broker
+func (c *amqpConn) confirmSelect() error {
+	var args amqpWriter
+	args.octet(0)
+	return c.call(amqpChannel, amqpConfirmSelect, args.Bytes(), amqpConfirmSelectOk)
+}
+
+// publish writes a persistent message, call flush and waitConfirms afterwards
+func (c *amqpConn) publish(exchange, routingKey string, headers map[string]string, body []byte, timestamp time.Time) error {
+	var method amqpWriter
+	method.short(0)
+	method.shortstr(exchange)
+	method.shortstr(routingKey)
+	method.octet(0)
+	if err := c.writeMethod(amqpChannel, amqpBasicPublish, method.Bytes()); err != nil {
+		return err
+	}
+
+	var header amqpWriter
+	header.short(amqpClassBasic)
+	header.short(0)
+	header.longlong(uint64(len(body)))
+	header.short(amqpPropContentType | amqpPropHeaders | amqpPropDeliveryMode | amqpPropTimestamp)
+	header.shortstr("application/json")
+	header.table(headers)
+	header.octet(amqpDeliveryPersistent)
+	header.longlong(uint64(timestamp.Unix()))
+	if err := c.writeFrame(amqpFrameHeader, amqpChannel, header.Bytes()); err != nil {
+		return err
+	}
+
+	// frame header and end take 8 bytes
+	maxPayload := int(c.frameMax) - 8
+	for len(body) > 0 {
+		n := len(body)
+		if n > maxPayload {
+			n = maxPayload
+		}
+		if err := c.writeFrame(amqpFrameBody, amqpChannel, body[:n]); err != nil {
+			return err
+		}
+		body = body[n:]
+	}
+	return nil
+}
+
+// waitConfirms waits until the broker acknowledges the first count published messages
+func (c *amqpConn) waitConfirms(count uint64) error {
+	pending := make(map[uint64]struct{}, count)
+	for tag := uint64(1); tag <= count; tag++ {
+		pending[tag] = struct{}{}
+	}
+
+	for len(pending) > 0 {
+		id, r, err := c.readMethod()
+		if err != nil {
+			return err
+		}
+		switch id {
+		case amqpBasicAck:
+			tag := r.longlong()
+			multiple := r.octet()&1 != 0
+			for t := range pending {
+				if t == tag || (multiple && t < tag) {
+					delete(pending, t)
+				}
+			}
+		case amqpBasicNack:
+			return fmt.Errorf("%w: message %d rejected by broker", ErrAMQP, r.longlong())
+		}
+	}
+	return nil
+}
+
+// close closes the connection gracefully
+func (c *amqpConn) close() error {
+	_ = c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	var args amqpWriter
+	args.short(200)
+	args.shortstr("")
+	args.short(0)
+	args.short(0)
+	if err := c.writeMethod(0, amqpConnectionClose, args.Bytes()); err == nil && c.flush() == nil {
+		_, _ = c.expect(amqpConnectionCloseOk)
+	}
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("%w: %s", ErrAMQP, err.Error())
+	}
+	return nil
+}
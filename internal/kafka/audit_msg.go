@@ -0,0 +1,58 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// AuditMessage is a record of a mutating API call published to the audit topic.
+type AuditMessage struct {
+	AccountID  int64     `json:"account_id"`
+	OrgID      string    `json:"org_id"`
+	Principal  string    `json:"principal"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	ResourceID string    `json:"resource_id,omitempty"`
+	Status     int       `json:"status"`
+	TraceID    string    `json:"trace_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewAuditMessage creates audit message from the stored audit record.
+func NewAuditMessage(audit *models.APIAudit) AuditMessage {
+	return AuditMessage{
+		AccountID:  audit.AccountID,
+		OrgID:      audit.OrgID,
+		Principal:  audit.Principal,
+		Method:     audit.Method,
+		Route:      audit.Route,
+		ResourceID: audit.ResourceID,
+		Status:     audit.Status,
+		TraceID:    audit.TraceID,
+		CreatedAt:  audit.CreatedAt,
+	}
+}
+
+// GenericMessage returns the message keyed by the account, records of an account are kept in order.
+func (m AuditMessage) GenericMessage(ctx context.Context) (GenericMessage, error) {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return GenericMessage{}, fmt.Errorf("unable to marshal audit message: %w", err)
+	}
+
+	msg := GenericMessage{
+		Topic: AuditTopic,
+		Key:   []byte(strconv.FormatInt(m.AccountID, 10)),
+		Value: payload,
+		Headers: GenericHeaders(
+			"content-type", "application/json",
+		),
+	}
+	injectTraceContext(ctx, &msg)
+	return msg, nil
+}
@@ -34,7 +34,7 @@ func genericMessage(ctx context.Context, m any, key string, topic string) (Gener
 
 	id := identity.Identity(ctx)
 
-	return GenericMessage{
+	msg := GenericMessage{
 		Topic: topic,
 		Key:   []byte(key),
 		Value: payload,
@@ -46,5 +46,7 @@ func genericMessage(ctx context.Context, m any, key string, topic string) (Gener
 			"x-rh-sources-account-number", id.Identity.AccountNumber,
 			"event_type", "availability_status",
 		),
-	}, nil
+	}
+	injectTraceContext(ctx, &msg)
+	return msg, nil
 }
@@ -0,0 +1,448 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Minimal Avro binary codec of JSON documents. Messages are JSON in the application, the codec
+// converts them into Avro binary encoding according to a writer schema and back. Logical types
+// are encoded as their underlying types, fixed type is not supported.
+
+var (
+	ErrAvroSchema   = errors.New("invalid avro schema")
+	ErrAvroEncoding = errors.New("value does not match avro schema")
+)
+
+type avroSchema struct {
+	Type     string
+	Name     string
+	Fields   []avroField
+	Symbols  []string
+	Items    *avroSchema
+	Values   *avroSchema
+	Branches []*avroSchema
+}
+
+type avroField struct {
+	Name       string
+	Schema     *avroSchema
+	Default    any
+	HasDefault bool
+}
+
+// parseAvroSchema parses a schema in the JSON format as stored in the schema registry.
+func parseAvroSchema(schema string) (*avroSchema, error) {
+	var raw any
+	if err := json.Unmarshal([]byte(schema), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAvroSchema, err.Error())
+	}
+	return parseAvroType(raw, "", make(map[string]*avroSchema))
+}
+
+func parseAvroType(raw any, namespace string, named map[string]*avroSchema) (*avroSchema, error) {
+	switch t := raw.(type) {
+	case string:
+		switch t {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroSchema{Type: t}, nil
+		}
+		if s, ok := named[fullAvroName(t, namespace)]; ok {
+			return s, nil
+		}
+		if s, ok := named[t]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("%w: unknown type %s", ErrAvroSchema, t)
+	case []any:
+		union := &avroSchema{Type: "union"}
+		for _, b := range t {
+			branch, err := parseAvroType(b, namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			union.Branches = append(union.Branches, branch)
+		}
+		return union, nil
+	case map[string]any:
+		return parseAvroComplex(t, namespace, named)
+	default:
+		return nil, fmt.Errorf("%w: unexpected %T", ErrAvroSchema, raw)
+	}
+}
+
+func parseAvroComplex(raw map[string]any, namespace string, named map[string]*avroSchema) (*avroSchema, error) {
+	typeName, _ := raw["type"].(string)
+	if ns, ok := raw["namespace"].(string); ok {
+		namespace = ns
+	}
+
+	switch typeName {
+	case "record", "error":
+		name, _ := raw["name"].(string)
+		s := &avroSchema{Type: "record", Name: fullAvroName(name, namespace)}
+		// register before fields for recursive types
+		named[s.Name] = s
+		fields, _ := raw["fields"].([]any)
+		for _, f := range fields {
+			fm, ok := f.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid field of %s", ErrAvroSchema, s.Name)
+			}
+			fs, err := parseAvroType(fm["type"], namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := fm["name"].(string)
+			def, hasDefault := fm["default"]
+			s.Fields = append(s.Fields, avroField{Name: name, Schema: fs, Default: def, HasDefault: hasDefault})
+		}
+		return s, nil
+	case "enum":
+		name, _ := raw["name"].(string)
+		s := &avroSchema{Type: "enum", Name: fullAvroName(name, namespace)}
+		symbols, _ := raw["symbols"].([]any)
+		for _, sym := range symbols {
+			str, _ := sym.(string)
+			s.Symbols = append(s.Symbols, str)
+		}
+		named[s.Name] = s
+		return s, nil
+	case "array":
+		items, err := parseAvroType(raw["items"], namespace, named)
+		if err != nil {
+			return nil, err
+		}
+		return &avroSchema{Type: "array", Items: items}, nil
+	case "map":
+		values, err := parseAvroType(raw["values"], namespace, named)
+		if err != nil {
+			return nil, err
+		}
+		return &avroSchema{Type: "map", Values: values}, nil
+	case "fixed":
+		return nil, fmt.Errorf("%w: fixed type is not supported", ErrAvroSchema)
+	default:
+		// primitive type with attributes, e.g. logical types
+		return parseAvroType(typeName, namespace, named)
+	}
+}
+
+func fullAvroName(name, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// avroEncodeJSON encodes a JSON document into Avro binary encoding.
+func avroEncodeJSON(s *avroSchema, data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON message: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := avroEncode(buf, s, value, "$"); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func avroEncode(buf *bytes.Buffer, s *avroSchema, value any, path string) error {
+	mismatch := func() error {
+		return fmt.Errorf("%w: %s is not %s", ErrAvroEncoding, path, s.Type)
+	}
+
+	switch s.Type {
+	case "null":
+		if value != nil {
+			return mismatch()
+		}
+	case "boolean":
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch()
+		}
+		if b {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case "int", "long":
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch()
+		}
+		i, err := n.Int64()
+		if err != nil || (s.Type == "int" && (i > math.MaxInt32 || i < math.MinInt32)) {
+			return mismatch()
+		}
+		writeAvroLong(buf, i)
+	case "float", "double":
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch()
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return mismatch()
+		}
+		if s.Type == "float" {
+			_ = binary.Write(buf, binary.LittleEndian, math.Float32bits(float32(f)))
+		} else {
+			_ = binary.Write(buf, binary.LittleEndian, math.Float64bits(f))
+		}
+	case "bytes", "string":
+		str, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		writeAvroLong(buf, int64(len(str)))
+		buf.WriteString(str)
+	case "enum":
+		str, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		for i, sym := range s.Symbols {
+			if sym == str {
+				writeAvroLong(buf, int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s has unknown symbol %s", ErrAvroEncoding, path, str)
+	case "record":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		for _, f := range s.Fields {
+			fv, present := obj[f.Name]
+			if !present {
+				if !f.HasDefault {
+					return fmt.Errorf("%w: %s.%s is required", ErrAvroEncoding, path, f.Name)
+				}
+				fv = normalizeAvroDefault(f.Default)
+			}
+			if err := avroEncode(buf, f.Schema, fv, path+"."+f.Name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return mismatch()
+		}
+		if len(arr) > 0 {
+			writeAvroLong(buf, int64(len(arr)))
+			for i, item := range arr {
+				if err := avroEncode(buf, s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+		writeAvroLong(buf, 0)
+	case "map":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		if len(obj) > 0 {
+			keys := make([]string, 0, len(obj))
+			for k := range obj {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			writeAvroLong(buf, int64(len(keys)))
+			for _, k := range keys {
+				writeAvroLong(buf, int64(len(k)))
+				buf.WriteString(k)
+				if err := avroEncode(buf, s.Values, obj[k], path+"."+k); err != nil {
+					return err
+				}
+			}
+		}
+		writeAvroLong(buf, 0)
+	case "union":
+		for i, branch := range s.Branches {
+			branchBuf := &bytes.Buffer{}
+			if err := avroEncode(branchBuf, branch, value, path); err == nil {
+				writeAvroLong(buf, int64(i))
+				buf.Write(branchBuf.Bytes())
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s matches no union branch", ErrAvroEncoding, path)
+	default:
+		return fmt.Errorf("%w: unsupported type %s", ErrAvroSchema, s.Type)
+	}
+	return nil
+}
+
+// normalizeAvroDefault converts default values parsed without UseNumber.
+func normalizeAvroDefault(value any) any {
+	if f, ok := value.(float64); ok {
+		return json.Number(fmt.Sprint(f))
+	}
+	return value
+}
+
+func writeAvroLong(buf *bytes.Buffer, n int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	l := binary.PutVarint(tmp[:], n)
+	buf.Write(tmp[:l])
+}
+
+// avroDecodeJSON decodes Avro binary encoding into a JSON document. Unions are decoded into
+// the plain value of the branch.
+func avroDecodeJSON(s *avroSchema, data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	value, err := avroDecode(r, s)
+	if err != nil {
+		return nil, err
+	}
+	result, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode JSON message: %w", err)
+	}
+	return result, nil
+}
+
+func avroDecode(r *bytes.Reader, s *avroSchema) (any, error) {
+	switch s.Type {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, avroReadErr(err)
+		}
+		return b == 1, nil
+	case "int", "long":
+		return readAvroLong(r)
+	case "float":
+		var bits uint32
+		if err := binary.Read(r, binary.LittleEndian, &bits); err != nil {
+			return nil, avroReadErr(err)
+		}
+		return math.Float32frombits(bits), nil
+	case "double":
+		var bits uint64
+		if err := binary.Read(r, binary.LittleEndian, &bits); err != nil {
+			return nil, avroReadErr(err)
+		}
+		return math.Float64frombits(bits), nil
+	case "bytes", "string":
+		return readAvroString(r)
+	case "enum":
+		i, err := readAvroLong(r)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(s.Symbols) {
+			return nil, fmt.Errorf("%w: enum index %d out of range", ErrAvroEncoding, i)
+		}
+		return s.Symbols[i], nil
+	case "record":
+		obj := make(map[string]any, len(s.Fields))
+		for _, f := range s.Fields {
+			v, err := avroDecode(r, f.Schema)
+			if err != nil {
+				return nil, err
+			}
+			obj[f.Name] = v
+		}
+		return obj, nil
+	case "array":
+		arr := make([]any, 0)
+		err := readAvroBlocks(r, func() error {
+			item, err := avroDecode(r, s.Items)
+			arr = append(arr, item)
+			return err
+		})
+		return arr, err
+	case "map":
+		obj := make(map[string]any)
+		err := readAvroBlocks(r, func() error {
+			k, err := readAvroString(r)
+			if err != nil {
+				return err
+			}
+			obj[k], err = avroDecode(r, s.Values)
+			return err
+		})
+		return obj, err
+	case "union":
+		i, err := readAvroLong(r)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(s.Branches) {
+			return nil, fmt.Errorf("%w: union index %d out of range", ErrAvroEncoding, i)
+		}
+		return avroDecode(r, s.Branches[i])
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrAvroSchema, s.Type)
+	}
+}
+
+func readAvroBlocks(r *bytes.Reader, item func() error) error {
+	for {
+		count, err := readAvroLong(r)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// negative count is followed by block size in bytes
+			count = -count
+			if _, err = readAvroLong(r); err != nil {
+				return err
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			if err = item(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func readAvroLong(r *bytes.Reader) (int64, error) {
+	n, err := binary.ReadVarint(r)
+	if err != nil {
+		return 0, avroReadErr(err)
+	}
+	return n, nil
+}
+
+func readAvroString(r *bytes.Reader) (string, error) {
+	l, err := readAvroLong(r)
+	if err != nil {
+		return "", err
+	}
+	if l < 0 || l > int64(r.Len()) {
+		return "", fmt.Errorf("%w: invalid length %d", ErrAvroEncoding, l)
+	}
+	b := make([]byte, l)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return "", avroReadErr(err)
+	}
+	return string(b), nil
+}
+
+func avroReadErr(err error) error {
+	return fmt.Errorf("%w: truncated data: %s", ErrAvroEncoding, err.Error())
+}
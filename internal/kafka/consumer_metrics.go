@@ -0,0 +1,43 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/segmentio/kafka-go"
+)
+
+// consumerStatsInterval is how often reader statistics are exported as metrics.
+const consumerStatsInterval = 15 * time.Second
+
+type statsReader interface {
+	Stats() kafka.ReaderStats
+}
+
+// exportConsumerStats exports reader statistics as metrics until the context is cancelled,
+// gauges of the consumed partition are removed afterwards so alerts do not fire on stale lag.
+func exportConsumerStats(ctx context.Context, r statsReader, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var partition string
+	for {
+		select {
+		case <-ticker.C:
+			partition = recordConsumerStats(r.Stats())
+		case <-ctx.Done():
+			stats := r.Stats()
+			if partition != "" {
+				metrics.UnsetKafkaConsumerPartition(stats.Topic, partition)
+			}
+			return
+		}
+	}
+}
+
+// recordConsumerStats records a snapshot and returns the consumed partition.
+func recordConsumerStats(stats kafka.ReaderStats) string {
+	metrics.SetKafkaConsumerStats(stats.Topic, stats.Partition, stats.Lag, stats.Offset, stats.Rebalances, stats.Errors)
+	return stats.Partition
+}
@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStatsReader struct {
+	stats kafka.ReaderStats
+}
+
+func (r *fakeStatsReader) Stats() kafka.ReaderStats {
+	return r.stats
+}
+
+func TestRecordConsumerStats(t *testing.T) {
+	stats := kafka.ReaderStats{Topic: "test-record", Partition: "3", Lag: 42, Offset: 7, Rebalances: 2, Errors: 1}
+
+	recordConsumerStats(stats)
+	recordConsumerStats(stats)
+
+	assert.Equal(t, 42.0, testutil.ToFloat64(metrics.KafkaConsumerLag.WithLabelValues("test-record", "3")))
+	assert.Equal(t, 7.0, testutil.ToFloat64(metrics.KafkaConsumerOffset.WithLabelValues("test-record", "3")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.KafkaConsumerPartitionAssigned.WithLabelValues("test-record", "3")))
+	assert.Equal(t, 4.0, testutil.ToFloat64(metrics.KafkaConsumerRebalances.WithLabelValues("test-record")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.KafkaConsumerErrors.WithLabelValues("test-record")))
+}
+
+func TestExportConsumerStatsUnsetsPartition(t *testing.T) {
+	r := &fakeStatsReader{stats: kafka.ReaderStats{Topic: "test-export", Partition: "0", Lag: 5}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exportConsumerStats(ctx, r, time.Millisecond)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.KafkaConsumerLag.WithLabelValues("test-export", "0")) == 5
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	assert.False(t, metrics.KafkaConsumerLag.DeleteLabelValues("test-export", "0"), "lag gauge should be removed")
+}
@@ -2,7 +2,12 @@ package kafka
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 )
 
 type Broker interface {
@@ -15,11 +20,47 @@ type Broker interface {
 
 var broker Broker = &noopBroker{}
 
+// consumeRetryDelay is the delay of consume loops of brokers without native reconnect after an error
+const consumeRetryDelay = 5 * time.Second
+
+var ErrUnknownBackend = errors.New("unknown message broker backend")
+
+// InitializeBroker initializes the broker implementation selected via configuration. Kafka
+// is used in the platform, SQS and AMQP are meant for deployments without a Kafka cluster.
+func InitializeBroker(ctx context.Context) error {
+	switch strings.ToLower(config.Kafka.Backend) {
+	case "", "kafka":
+		return InitializeKafkaBroker(ctx)
+	case "sqs":
+		return InitializeSQSBroker(ctx)
+	case "amqp":
+		return InitializeAMQPBroker(ctx)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownBackend, config.Kafka.Backend)
+	}
+}
+
+// Send sends messages via the configured broker, trace context of the ctx is added to
+// messages which do not carry one yet.
+//
 //nolint:wrapcheck
 func Send(ctx context.Context, messages ...*GenericMessage) error {
+	for _, m := range messages {
+		injectTraceContext(ctx, m)
+	}
 	return broker.Send(ctx, messages...)
 }
 
 func Consume(ctx context.Context, topic string, since time.Time, handler func(ctx context.Context, message *GenericMessage)) {
 	broker.Consume(ctx, topic, since, handler)
 }
+
+// waitRetry waits before the next consume attempt, returns false when the context was cancelled.
+func waitRetry(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(consumeRetryDelay):
+		return true
+	}
+}
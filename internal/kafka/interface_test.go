@@ -64,3 +64,8 @@ func TestMultipleTopics(t *testing.T) {
 	_ = bus.Send(ctx, createMessage("topic2", "key2", "value"))
 	wg.Wait()
 }
+
+func TestReplayUnsupported(t *testing.T) {
+	_, err := Replay(context.Background(), ReplayOptions{Topic: "topic", Offset: 0}, func(ctx context.Context, msg *GenericMessage) {})
+	require.ErrorIs(t, err, ErrReplayUnsupported)
+}
@@ -12,22 +12,24 @@ import (
 	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
-	"github.com/RHEnVision/provisioning-backend/internal/identity"
-	"github.com/RHEnVision/provisioning-backend/internal/logging"
-	"github.com/RHEnVision/provisioning-backend/internal/random"
 	"github.com/RHEnVision/provisioning-backend/internal/version"
 	"github.com/rs/zerolog"
 	"github.com/segmentio/kafka-go"
-	"github.com/segmentio/kafka-go/protocol"
 	"github.com/segmentio/kafka-go/sasl"
 	"github.com/segmentio/kafka-go/sasl/plain"
 	"github.com/segmentio/kafka-go/sasl/scram"
-	"go.opentelemetry.io/otel/trace"
 )
 
 type kafkaBroker struct {
 	dialer    *kafka.Dialer
 	transport *kafka.Transport
+
+	// producer settings parsed from configuration
+	compression kafka.Compression
+	acks        kafka.RequiredAcks
+
+	// registry encodes message values, nil when not configured
+	registry *schemaRegistry
 }
 
 var _ Broker = &kafkaBroker{}
@@ -108,6 +110,16 @@ func NewKafkaBroker(ctx context.Context) (Broker, error) {
 		}
 	}
 
+	var compression kafka.Compression
+	if err := compression.UnmarshalText([]byte(config.Kafka.Producer.Compression)); err != nil {
+		return nil, fmt.Errorf("%w: kafka producer compression: %s", ErrBrokerConfig, err.Error())
+	}
+	var acks kafka.RequiredAcks
+	if err := acks.UnmarshalText([]byte(config.Kafka.Producer.Acks)); err != nil {
+		return nil, fmt.Errorf("%w: kafka producer acks: %s", ErrBrokerConfig, err.Error())
+	}
+	logger.Debug().Msgf("Kafka producer compression %s, acks %s", compression, acks)
+
 	dialer := &kafka.Dialer{
 		ClientID:      version.KafkaClientID,
 		Timeout:       10 * time.Second,
@@ -122,9 +134,20 @@ func NewKafkaBroker(ctx context.Context) (Broker, error) {
 		SASL:     saslMechanism,
 	}
 
+	registry, err := newSchemaRegistry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("kafka schema registry error: %w", err)
+	}
+	if registry != nil {
+		logger.Debug().Msgf("Using schema registry %s with %s schemas", registry.url, registry.schemaType)
+	}
+
 	return &kafkaBroker{
-		dialer:    dialer,
-		transport: transport,
+		dialer:      dialer,
+		transport:   transport,
+		compression: compression,
+		acks:        acks,
+		registry:    registry,
 	}, nil
 }
 
@@ -169,16 +192,39 @@ func (b *kafkaBroker) NewReader(ctx context.Context, topic string) *kafka.Reader
 }
 
 // NewWriter creates synchronous writer created from the pool. It does not have associated any topic with it,
-// therefore topic must be set on the message-level. Make sure to close it with Close() function.
+// therefore topic must be set on the message-level. Compression, acknowledgements and batching
+// are configured via KAFKA_PRODUCER_ variables. Make sure to close it with Close() function.
 func (b *kafkaBroker) NewWriter(ctx context.Context) *kafka.Writer {
 	return &kafka.Writer{
-		Addr:        kafka.TCP(config.Kafka.Brokers...),
-		Transport:   b.transport,
-		Logger:      kafka.LoggerFunc(newContextLogger(ctx)),
-		ErrorLogger: kafka.LoggerFunc(newContextErrLogger(ctx)),
+		Addr:         kafka.TCP(config.Kafka.Brokers...),
+		Transport:    b.transport,
+		Compression:  b.compression,
+		RequiredAcks: b.acks,
+		BatchSize:    config.Kafka.Producer.BatchSize,
+		BatchBytes:   config.Kafka.Producer.BatchBytes,
+		BatchTimeout: config.Kafka.Producer.Linger,
+		Logger:       kafka.LoggerFunc(newContextLogger(ctx)),
+		ErrorLogger:  kafka.LoggerFunc(newContextErrLogger(ctx)),
 	}
 }
 
+// receivedMessage converts a read message and builds its context, returns false when the
+// message should be skipped.
+func (b *kafkaBroker) receivedMessage(ctx context.Context, msg *kafka.Message) (*GenericMessage, context.Context, bool) {
+	message := NewMessageFromKafka(msg)
+	msgCtx := newMessageContext(ctx, message)
+
+	if b.registry != nil {
+		var err error
+		message.Value, err = b.registry.Decode(msgCtx, message.Value)
+		if err != nil {
+			zerolog.Ctx(msgCtx).Warn().Err(err).Msg("Unable to decode message via schema registry, skipping")
+			return nil, nil, false
+		}
+	}
+	return message, msgCtx, true
+}
+
 // Consume reads messages in batches up to 1 MB with up to 10 seconds delay. It blocks, therefore
 // it should be called from a separate goroutine. Use context cancellation to stop the loop.
 func (b *kafkaBroker) Consume(ctx context.Context, topic string, since time.Time, handler func(ctx context.Context, message *GenericMessage)) {
@@ -186,6 +232,10 @@ func (b *kafkaBroker) Consume(ctx context.Context, topic string, since time.Time
 	r := b.NewReader(ctx, topic)
 	defer r.Close()
 
+	statsCtx, statsCancel := context.WithCancel(ctx)
+	defer statsCancel()
+	go exportConsumerStats(statsCtx, r, consumerStatsInterval)
+
 	err := r.SetOffsetAt(ctx, since)
 	if err != nil {
 		logger.Warn().Err(err).Msg("Unable to set initial offset")
@@ -205,39 +255,16 @@ func (b *kafkaBroker) Consume(ctx context.Context, topic string, since time.Time
 			logger.Trace().Bytes("payload", msg.Value).Msgf("Received message with key: %s, topic: %s, offset: %d, partition: %d",
 				msg.Key, msg.Topic, msg.Offset, msg.Partition)
 
-			// build new context - identity and trace id
-			ctx, err = identity.WithIdentityFrom64(ctx, header("x-rh-identity", msg.Headers))
-			if err != nil {
-				logger.Trace().Msgf("Could not extract identity from context to Kafka message: %s", err)
-			}
-			identity := identity.Identity(ctx)
-
-			traceId := trace.SpanFromContext(ctx).SpanContext().TraceID()
-			if !traceId.IsValid() {
-				traceId = random.TraceID()
+			message, msgCtx, ok := b.receivedMessage(ctx, &msg)
+			if !ok {
+				continue
 			}
-			ctx = logging.WithTraceId(ctx, traceId.String())
 
-			newLogger := logger.With().
-				Str("trace_id", traceId.String()).
-				Str("account_number", identity.Identity.AccountNumber).
-				Str("org_id", identity.Identity.OrgID).Logger()
-			ctx = newLogger.WithContext(ctx)
-
-			handler(ctx, NewMessageFromKafka(&msg))
+			handler(msgCtx, message)
 		}
 	}
 }
 
-func header(name string, headers []protocol.Header) string {
-	for _, h := range headers {
-		if strings.EqualFold(h.Key, name) {
-			return string(h.Value)
-		}
-	}
-	return ""
-}
-
 // Send one or more generic messages with the same topic. If there is a message with
 // different topic than the first one, DifferentTopicErr is returned.
 func (b *kafkaBroker) Send(ctx context.Context, messages ...*GenericMessage) error {
@@ -259,6 +286,14 @@ func (b *kafkaBroker) Send(ctx context.Context, messages ...*GenericMessage) err
 			return DifferentTopicErr
 		}
 		kMessages[i] = m.KafkaMessage()
+
+		if b.registry != nil {
+			value, err := b.registry.Encode(ctx, m.Topic, m.Value)
+			if err != nil {
+				return fmt.Errorf("cannot encode kafka message: %w", err)
+			}
+			kMessages[i].Value = value
+		}
 	}
 
 	err := w.WriteMessages(ctx, kMessages...)
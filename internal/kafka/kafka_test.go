@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaBrokerProducerSettings(t *testing.T) {
+	saved := config.Kafka.Producer
+	defer func() { config.Kafka.Producer = saved }()
+	config.Kafka.Producer.Compression = "snappy"
+	config.Kafka.Producer.Acks = "all"
+	config.Kafka.Producer.BatchSize = 1024
+	config.Kafka.Producer.Linger = 5 * time.Millisecond
+
+	b, err := NewKafkaBroker(context.Background())
+	require.NoError(t, err)
+
+	w := b.(*kafkaBroker).NewWriter(context.Background())
+	defer w.Close()
+	assert.Equal(t, kafka.Snappy, w.Compression)
+	assert.Equal(t, kafka.RequireAll, w.RequiredAcks)
+	assert.Equal(t, 1024, w.BatchSize)
+	assert.Equal(t, 5*time.Millisecond, w.BatchTimeout)
+}
+
+func TestKafkaBrokerInvalidProducerSettings(t *testing.T) {
+	saved := config.Kafka.Producer
+	defer func() { config.Kafka.Producer = saved }()
+
+	config.Kafka.Producer.Compression = "brotli"
+	_, err := NewKafkaBroker(context.Background())
+	assert.ErrorIs(t, err, ErrBrokerConfig)
+
+	config.Kafka.Producer.Compression = "none"
+	config.Kafka.Producer.Acks = "some"
+	_, err = NewKafkaBroker(context.Background())
+	assert.ErrorIs(t, err, ErrBrokerConfig)
+}
@@ -4,7 +4,13 @@ import (
 	"context"
 	"strings"
 
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/random"
+	"github.com/rs/zerolog"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // GenericMessage is a platform independent message.
@@ -22,6 +28,9 @@ type GenericMessage struct {
 	Headers []GenericHeader
 }
 
+// messageKeyHeader carries the message key in brokers without native key support
+const messageKeyHeader = "x-message-key"
+
 type GenericHeader struct {
 	Key   string
 	Value string
@@ -90,6 +99,70 @@ func (m GenericMessage) KafkaMessage() kafka.Message {
 	}
 }
 
+// OutboxMessage converts from generic to outbox message which is published later.
+func (m GenericMessage) OutboxMessage() *models.OutboxMessage {
+	headers := make([]models.OutboxHeader, len(m.Headers))
+	for i, gh := range m.Headers {
+		headers[i] = models.OutboxHeader{
+			Key:   gh.Key,
+			Value: gh.Value,
+		}
+	}
+
+	return &models.OutboxMessage{
+		Topic:   m.Topic,
+		Key:     m.Key,
+		Value:   m.Value,
+		Headers: headers,
+	}
+}
+
+// NewMessageFromOutbox converts outbox message to generic message
+func NewMessageFromOutbox(om *models.OutboxMessage) *GenericMessage {
+	headers := make([]GenericHeader, len(om.Headers))
+	for i, h := range om.Headers {
+		headers[i] = GenericHeader{
+			Key:   h.Key,
+			Value: h.Value,
+		}
+	}
+
+	return &GenericMessage{
+		Topic:   om.Topic,
+		Key:     om.Key,
+		Value:   om.Value,
+		Headers: headers,
+	}
+}
+
+// headersWithKey returns headers including the key header when the message has a key.
+func (m GenericMessage) headersWithKey() []GenericHeader {
+	if len(m.Key) == 0 {
+		return m.Headers
+	}
+	headers := make([]GenericHeader, 0, len(m.Headers)+1)
+	headers = append(headers, m.Headers...)
+	return append(headers, GenericHeader{Key: messageKeyHeader, Value: string(m.Key)})
+}
+
+// newGenericMessage creates a message received via broker without native key support,
+// the key is taken from the key header.
+func newGenericMessage(topic string, value []byte, headers []GenericHeader) *GenericMessage {
+	m := &GenericMessage{
+		Topic:   topic,
+		Value:   value,
+		Headers: make([]GenericHeader, 0, len(headers)),
+	}
+	for _, h := range headers {
+		if strings.EqualFold(h.Key, messageKeyHeader) {
+			m.Key = []byte(h.Value)
+		} else {
+			m.Headers = append(m.Headers, h)
+		}
+	}
+	return m
+}
+
 func (m GenericMessage) Header(name string) string {
 	for _, h := range m.Headers {
 		if strings.EqualFold(h.Key, name) {
@@ -98,3 +171,31 @@ func (m GenericMessage) Header(name string) string {
 	}
 	return ""
 }
+
+// newMessageContext builds context of a received message with identity from the
+// x-rh-identity header (when present), remote span and trace id from the traceparent
+// header and a logger with both.
+func newMessageContext(ctx context.Context, m *GenericMessage) context.Context {
+	logger := zerolog.Ctx(ctx)
+	ctx = extractTraceContext(ctx, m)
+
+	logCtx := logger.With()
+	if idCtx, err := identity.WithIdentityFrom64(ctx, m.Header("x-rh-identity")); err != nil {
+		logger.Trace().Msgf("Could not extract identity from message header: %s", err)
+	} else {
+		ctx = idCtx
+		id := identity.Identity(ctx)
+		logCtx = logCtx.
+			Str("account_number", id.Identity.AccountNumber).
+			Str("org_id", id.Identity.OrgID)
+	}
+
+	traceId := trace.SpanFromContext(ctx).SpanContext().TraceID()
+	if !traceId.IsValid() {
+		traceId = random.TraceID()
+	}
+	ctx = logging.WithTraceId(ctx, traceId.String())
+
+	newLogger := logCtx.Str("trace_id", traceId.String()).Logger()
+	return newLogger.WithContext(ctx)
+}
@@ -39,3 +39,13 @@ func TestGenerateHeadersOdd(t *testing.T) {
 		_ = GenericHeaders("")
 	}, "generic headers: odd amount of arguments")
 }
+
+func TestOutboxMessageRoundtrip(t *testing.T) {
+	m := GenericMessage{
+		Topic:   "topic",
+		Key:     []byte("key"),
+		Value:   []byte("value"),
+		Headers: GenericHeaders("a", "b"),
+	}
+	require.Equal(t, &m, NewMessageFromOutbox(m.OutboxMessage()))
+}
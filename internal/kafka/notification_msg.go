@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
@@ -16,19 +17,84 @@ const (
 	notificationMessageVersion   = "v2.0.0"
 	NotificationSuccessEventType = "launch-success"
 	NotificationFailureEventType = "launch-failed"
+	NotificationPubkeyEventType  = "pubkey-expiring"
+	NotificationDigestEventType  = "expiry-digest"
+)
+
+// Types of resources in the expiry digest.
+const (
+	NotificationDigestPubkey      = "pubkey"
+	NotificationDigestReservation = "reservation"
 )
 
 type NotificationEvent struct {
 	Payload json.RawMessage `json:"payload"`
 }
 
+// NotificationContext is the context of launch-success and launch-failed events.
 type NotificationContext struct {
 	LaunchID int64  `json:"launch_id"`
 	Provider string `json:"provider"`
+
+	// Number of launched instances, zero for failed launches.
+	InstanceCount int `json:"instance_count"`
+
+	// Title of the step which failed, empty for successful launches.
+	FailedStep string `json:"failed_step,omitempty"`
+}
+
+// NotificationInstance is the event payload of a launched instance.
+type NotificationInstance struct {
+	InstanceID string `json:"instance_id"`
+	PublicDNS  string `json:"public_dns,omitempty"`
+	PublicIPv4 string `json:"public_ipv4,omitempty"`
+}
+
+type NotificationPubkeyContext struct {
+	PubkeyID    int64     `json:"pubkey_id"`
+	Name        string    `json:"name"`
+	Fingerprint string    `json:"fingerprint"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// NotificationDigestContext is the context of expiry-digest events.
+type NotificationDigestContext struct {
+	// Number of days ahead covered by the digest.
+	Days int `json:"days"`
+
+	PubkeyCount      int `json:"pubkey_count"`
+	ReservationCount int `json:"reservation_count"`
 }
 
+// NotificationDigestItem is the event payload of a single resource in the expiry digest. Pubkeys
+// expire at their expiration time, deleted reservations are purged and can no longer be restored.
+type NotificationDigestItem struct {
+	Type      string    `json:"type"`
+	ID        int64     `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NotificationError is the event payload of a failed launch.
 type NotificationError struct {
+	// Full error message.
 	Error string `json:"error"`
+
+	// Short single-line summary of the error suitable for email subjects and chat messages.
+	Summary string `json:"summary"`
+}
+
+// Maximum length of the error summary in characters.
+const notificationSummaryLength = 200
+
+// NewNotificationError returns error payload with summary of the error.
+func NewNotificationError(err error) NotificationError {
+	message := err.Error()
+	summary := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	if runes := []rune(summary); len(runes) > notificationSummaryLength {
+		summary = string(runes[:notificationSummaryLength-1]) + "…"
+	}
+	return NotificationError{Error: message, Summary: summary}
 }
 
 type notificationRecipients struct {
@@ -68,7 +134,7 @@ func (m NotificationMessage) GenericMessage(ctx context.Context) (GenericMessage
 		return GenericMessage{}, fmt.Errorf("unable to marshal notification message: %w", err)
 	}
 
-	return GenericMessage{
+	msg := GenericMessage{
 		Topic: NotificationTopic,
 		Key:   nil,
 		Value: payload,
@@ -76,5 +142,8 @@ func (m NotificationMessage) GenericMessage(ctx context.Context) (GenericMessage
 			"rh-message-id", m.ID,
 			"x-rh-identity", identity.IdentityHeader(ctx),
 		),
-	}, nil
+	}
+	// notifications are sent later via outbox, keep trace of the job
+	injectTraceContext(ctx, &msg)
+	return msg, nil
 }
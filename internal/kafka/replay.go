@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+)
+
+var ErrReplayUnsupported = errors.New("replay is only supported by the kafka backend")
+
+// ReplayOptions select messages of a topic partition to replay.
+type ReplayOptions struct {
+	Topic     string
+	Partition int
+
+	// Offset of the first message, Since is used when negative.
+	Offset int64
+
+	// Since is the time of the first message.
+	Since time.Time
+}
+
+// Replay reads messages of a topic partition from the given offset or time up to the end of
+// the partition at the time of the call and passes them to the handler. Messages are read
+// without consumer group, offsets of other consumers are not affected. Returns the number
+// of replayed messages.
+func Replay(ctx context.Context, opts ReplayOptions, handler func(ctx context.Context, message *GenericMessage)) (int, error) {
+	b, ok := broker.(*kafkaBroker)
+	if !ok {
+		return 0, ErrReplayUnsupported
+	}
+	return b.replay(ctx, opts, handler)
+}
+
+func (b *kafkaBroker) replay(ctx context.Context, opts ReplayOptions, handler func(ctx context.Context, message *GenericMessage)) (int, error) {
+	logger := zerolog.Ctx(ctx)
+
+	last, err := b.lastOffset(ctx, opts.Topic, opts.Partition)
+	if err != nil {
+		return 0, err
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     config.Kafka.Brokers,
+		Dialer:      b.dialer,
+		Topic:       opts.Topic,
+		Partition:   opts.Partition,
+		Logger:      kafka.LoggerFunc(newContextLogger(ctx)),
+		ErrorLogger: kafka.LoggerFunc(newContextErrLogger(ctx)),
+	})
+	defer r.Close()
+
+	if opts.Offset >= 0 {
+		err = r.SetOffset(opts.Offset)
+	} else {
+		err = r.SetOffsetAt(ctx, opts.Since)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to set replay offset: %w", err)
+	}
+
+	first := r.Offset()
+	if first < 0 || first >= last {
+		logger.Info().Msgf("No messages to replay, offset %d, end of partition %d", first, last)
+		return 0, nil
+	}
+	logger.Info().Msgf("Replaying %d messages of topic %s partition %d from offset %d", last-first, opts.Topic, opts.Partition, first)
+
+	count := 0
+	for {
+		msg, err := r.ReadMessage(ctx)
+		if err != nil {
+			return count, fmt.Errorf("unable to read message: %w", err)
+		}
+
+		message, msgCtx, ok := b.receivedMessage(ctx, &msg)
+		if ok {
+			handler(msgCtx, message)
+			count++
+		}
+
+		if msg.Offset+1 >= last {
+			return count, nil
+		}
+	}
+}
+
+// lastOffset returns offset of the next message of the partition.
+func (b *kafkaBroker) lastOffset(ctx context.Context, topic string, partition int) (int64, error) {
+	var err error
+	for _, address := range config.Kafka.Brokers {
+		var conn *kafka.Conn
+		conn, err = b.dialer.DialLeader(ctx, "tcp", address, topic, partition)
+		if err != nil {
+			continue
+		}
+
+		last, readErr := conn.ReadLastOffset()
+		_ = conn.Close()
+		if readErr != nil {
+			return 0, fmt.Errorf("unable to read last offset: %w", readErr)
+		}
+		return last, nil
+	}
+	return 0, fmt.Errorf("unable to connect to partition leader: %w", err)
+}
@@ -0,0 +1,251 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rs/zerolog"
+)
+
+// Schema types of the registry
+const (
+	SchemaTypeAvro = "AVRO"
+	SchemaTypeJSON = "JSON"
+)
+
+// wireMagicByte starts Confluent wire format: magic byte, big endian schema ID and the payload
+const wireMagicByte = 0
+
+var (
+	ErrSchemaRegistry   = errors.New("schema registry error")
+	ErrSchemaType       = errors.New("unexpected schema type")
+	ErrSchemaValidation = errors.New("message does not match schema")
+)
+
+type registrySchema struct {
+	ID         int
+	SchemaType string
+	avro       *avroSchema
+	json       *openapi3.Schema
+	fetchedAt  time.Time
+}
+
+// schemaRegistry encodes and decodes message values via Confluent Schema Registry. Subjects
+// follow the topic name strategy ("<topic>-value"). Messages are encoded with the latest
+// schema of the subject, Avro messages are converted from and to JSON so the rest of the
+// application works with JSON payloads only.
+type schemaRegistry struct {
+	url        string
+	username   string
+	password   string
+	schemaType string
+	ttl        time.Duration
+	client     httpClients.HttpRequestDoer
+
+	mu        sync.Mutex
+	byID      map[int]*registrySchema
+	bySubject map[string]*registrySchema
+}
+
+// newSchemaRegistry returns nil when schema registry is not configured.
+func newSchemaRegistry(ctx context.Context) (*schemaRegistry, error) {
+	if config.Kafka.SchemaRegistry.URL == "" {
+		return nil, nil
+	}
+
+	var schemaType string
+	switch strings.ToLower(config.Kafka.SchemaRegistry.Format) {
+	case "avro":
+		schemaType = SchemaTypeAvro
+	case "json":
+		schemaType = SchemaTypeJSON
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrSchemaType, config.Kafka.SchemaRegistry.Format)
+	}
+
+	return &schemaRegistry{
+		url:        strings.TrimSuffix(config.Kafka.SchemaRegistry.URL, "/"),
+		username:   config.Kafka.SchemaRegistry.Username,
+		password:   config.Kafka.SchemaRegistry.Password,
+		schemaType: schemaType,
+		ttl:        config.Kafka.SchemaRegistry.CacheTTL,
+		client:     httpClients.NewPlatformClient(ctx, "", ""),
+		byID:       make(map[int]*registrySchema),
+		bySubject:  make(map[string]*registrySchema),
+	}, nil
+}
+
+func subjectName(topic string) string {
+	return topic + "-value"
+}
+
+// Encode validates the JSON value against the latest schema of the topic subject and returns
+// it in the wire format.
+func (sr *schemaRegistry) Encode(ctx context.Context, topic string, value []byte) ([]byte, error) {
+	schema, err := sr.latest(ctx, subjectName(topic))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := value
+	switch schema.SchemaType {
+	case SchemaTypeAvro:
+		payload, err = avroEncodeJSON(schema.avro, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: topic %s: %s", ErrSchemaValidation, topic, err.Error())
+		}
+	case SchemaTypeJSON:
+		if err = validateJSONSchema(schema.json, value); err != nil {
+			return nil, fmt.Errorf("%w: topic %s: %s", ErrSchemaValidation, topic, err.Error())
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(payload)+5))
+	buf.WriteByte(wireMagicByte)
+	_ = binary.Write(buf, binary.BigEndian, uint32(schema.ID))
+	buf.Write(payload)
+	return buf.Bytes(), nil
+}
+
+// Decode returns the JSON value of a message in the wire format. Values which are not in
+// the wire format (e.g. from producers without registry) are returned unchanged.
+func (sr *schemaRegistry) Decode(ctx context.Context, value []byte) ([]byte, error) {
+	if len(value) < 5 || value[0] != wireMagicByte {
+		zerolog.Ctx(ctx).Trace().Msg("Message is not in schema registry wire format")
+		return value, nil
+	}
+
+	schema, err := sr.schemaByID(ctx, int(binary.BigEndian.Uint32(value[1:5])))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := value[5:]
+	switch schema.SchemaType {
+	case SchemaTypeAvro:
+		payload, err = avroDecodeJSON(schema.avro, payload)
+		if err != nil {
+			return nil, fmt.Errorf("%w: schema %d: %s", ErrSchemaValidation, schema.ID, err.Error())
+		}
+	case SchemaTypeJSON:
+		if err = validateJSONSchema(schema.json, payload); err != nil {
+			return nil, fmt.Errorf("%w: schema %d: %s", ErrSchemaValidation, schema.ID, err.Error())
+		}
+	}
+	return payload, nil
+}
+
+func validateJSONSchema(schema *openapi3.Schema, value []byte) error {
+	var doc any
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return fmt.Errorf("unable to decode JSON message: %w", err)
+	}
+	if err := schema.VisitJSON(doc); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	return nil
+}
+
+// latest returns the latest schema of the subject, cached for the configured time.
+func (sr *schemaRegistry) latest(ctx context.Context, subject string) (*registrySchema, error) {
+	sr.mu.Lock()
+	schema, ok := sr.bySubject[subject]
+	sr.mu.Unlock()
+	if ok && time.Since(schema.fetchedAt) < sr.ttl {
+		return schema, nil
+	}
+
+	schema, err := sr.fetch(ctx, fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject)))
+	if err != nil {
+		return nil, err
+	}
+	if schema.SchemaType != sr.schemaType {
+		return nil, fmt.Errorf("%w: subject %s has %s schema, configured %s", ErrSchemaType, subject, schema.SchemaType, sr.schemaType)
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.bySubject[subject] = schema
+	sr.byID[schema.ID] = schema
+	return schema, nil
+}
+
+// schemaByID returns schema of the ID, schemas are immutable and cached forever.
+func (sr *schemaRegistry) schemaByID(ctx context.Context, id int) (*registrySchema, error) {
+	sr.mu.Lock()
+	schema, ok := sr.byID[id]
+	sr.mu.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema, err := sr.fetch(ctx, fmt.Sprintf("/schemas/ids/%d", id))
+	if err != nil {
+		return nil, err
+	}
+	schema.ID = id
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.byID[id] = schema
+	return schema, nil
+}
+
+type registrySchemaResponse struct {
+	ID         int    `json:"id"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+func (sr *schemaRegistry) fetch(ctx context.Context, path string) (*registrySchema, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sr.url+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaRegistry, err.Error())
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if sr.username != "" {
+		req.SetBasicAuth(sr.username, sr.password)
+	}
+
+	resp, err := sr.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaRegistry, err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: GET %s returned %d", ErrSchemaRegistry, path, resp.StatusCode)
+	}
+
+	var body registrySchemaResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode response: %s", ErrSchemaRegistry, err.Error())
+	}
+
+	schema := &registrySchema{ID: body.ID, SchemaType: body.SchemaType, fetchedAt: time.Now()}
+	switch body.SchemaType {
+	case "", SchemaTypeAvro:
+		schema.SchemaType = SchemaTypeAvro
+		schema.avro, err = parseAvroSchema(body.Schema)
+	case SchemaTypeJSON:
+		schema.json = openapi3.NewSchema()
+		err = json.Unmarshal([]byte(body.Schema), schema.json)
+	default:
+		err = fmt.Errorf("%w: %s", ErrSchemaType, body.SchemaType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse schema: %s", ErrSchemaRegistry, err.Error())
+	}
+	return schema, nil
+}
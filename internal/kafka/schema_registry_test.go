@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAvroSchema = `{
+	"type": "record",
+	"name": "AvailabilityStatus",
+	"namespace": "com.redhat.provisioning",
+	"fields": [
+		{"name": "source_id", "type": "string"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["available", "unavailable"]}},
+		{"name": "err", "type": ["null", "string"], "default": null},
+		{"name": "count", "type": "long", "default": 0},
+		{"name": "regions", "type": {"type": "array", "items": "string"}},
+		{"name": "tags", "type": {"type": "map", "values": "double"}},
+		{"name": "enabled", "type": "boolean"}
+	]
+}`
+
+const testJSONSchema = `{
+	"type": "object",
+	"required": ["source_id"],
+	"properties": {
+		"source_id": {"type": "string"},
+		"status": {"type": "string", "enum": ["available", "unavailable"]}
+	}
+}`
+
+func TestAvroRoundTrip(t *testing.T) {
+	schema, err := parseAvroSchema(testAvroSchema)
+	require.NoError(t, err)
+
+	value := `{"source_id":"1","status":"unavailable","err":"timeout","count":42,"regions":["us-east-1","eu-west-1"],"tags":{"a":1.5},"enabled":true}`
+	encoded, err := avroEncodeJSON(schema, []byte(value))
+	require.NoError(t, err)
+
+	decoded, err := avroDecodeJSON(schema, encoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, value, string(decoded))
+}
+
+func TestAvroDefaults(t *testing.T) {
+	schema, err := parseAvroSchema(testAvroSchema)
+	require.NoError(t, err)
+
+	encoded, err := avroEncodeJSON(schema, []byte(`{"source_id":"1","status":"available","regions":[],"tags":{},"enabled":false}`))
+	require.NoError(t, err)
+
+	decoded, err := avroDecodeJSON(schema, encoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"source_id":"1","status":"available","err":null,"count":0,"regions":[],"tags":{},"enabled":false}`, string(decoded))
+}
+
+func TestAvroMismatch(t *testing.T) {
+	schema, err := parseAvroSchema(testAvroSchema)
+	require.NoError(t, err)
+
+	_, err = avroEncodeJSON(schema, []byte(`{"source_id":"1","status":"unknown","regions":[],"tags":{},"enabled":false}`))
+	assert.ErrorIs(t, err, ErrAvroEncoding)
+
+	_, err = avroEncodeJSON(schema, []byte(`{"status":"available","regions":[],"tags":{},"enabled":false}`))
+	assert.ErrorIs(t, err, ErrAvroEncoding)
+}
+
+func newTestRegistry(t *testing.T, format, schemaType, schema string) *schemaRegistry {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subjects/test-topic-value/versions/latest", "/schemas/ids/7":
+			_ = json.NewEncoder(w).Encode(registrySchemaResponse{ID: 7, Schema: schema, SchemaType: schemaType})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	config.Kafka.SchemaRegistry.URL = srv.URL
+	config.Kafka.SchemaRegistry.Format = format
+	config.Kafka.SchemaRegistry.CacheTTL = time.Minute
+	t.Cleanup(func() {
+		config.Kafka.SchemaRegistry.URL = ""
+	})
+
+	registry, err := newSchemaRegistry(context.Background())
+	require.NoError(t, err)
+	return registry
+}
+
+func TestSchemaRegistryAvro(t *testing.T) {
+	registry := newTestRegistry(t, "avro", "", testAvroSchema)
+	ctx := context.Background()
+
+	value := `{"source_id":"1","status":"available","err":null,"count":1,"regions":["us-east-1"],"tags":{},"enabled":true}`
+	encoded, err := registry.Encode(ctx, "test-topic", []byte(value))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 7}, encoded[:5])
+
+	// clear cache to fetch schema by ID
+	registry.byID = make(map[int]*registrySchema)
+	decoded, err := registry.Decode(ctx, encoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, value, string(decoded))
+
+	_, err = registry.Encode(ctx, "unknown-topic", []byte(value))
+	assert.ErrorIs(t, err, ErrSchemaRegistry)
+}
+
+func TestSchemaRegistryJSON(t *testing.T) {
+	registry := newTestRegistry(t, "json", SchemaTypeJSON, testJSONSchema)
+	ctx := context.Background()
+
+	encoded, err := registry.Encode(ctx, "test-topic", []byte(`{"source_id":"1","status":"available"}`))
+	require.NoError(t, err)
+
+	decoded, err := registry.Decode(ctx, encoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"source_id":"1","status":"available"}`, string(decoded))
+
+	_, err = registry.Encode(ctx, "test-topic", []byte(`{"status":"broken"}`))
+	assert.ErrorIs(t, err, ErrSchemaValidation)
+}
+
+func TestSchemaRegistryPlainMessage(t *testing.T) {
+	registry := newTestRegistry(t, "json", SchemaTypeJSON, testJSONSchema)
+
+	decoded, err := registry.Decode(context.Background(), []byte(`{"source_id":"1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"source_id":"1"}`, string(decoded))
+}
+
+func TestSchemaRegistryTypeMismatch(t *testing.T) {
+	registry := newTestRegistry(t, "avro", SchemaTypeJSON, testJSONSchema)
+
+	_, err := registry.Encode(context.Background(), "test-topic", []byte(`{"source_id":"1"}`))
+	assert.ErrorIs(t, err, ErrSchemaType)
+}
@@ -0,0 +1,316 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/secrets"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrBrokerConfig = errors.New("message broker configuration error")
+	ErrSQSRequest   = errors.New("SNS/SQS request failed")
+)
+
+// sqsBroker publishes messages to SNS topics and consumes them from SQS queues subscribed
+// to the topics. Both are named after Kafka topics with characters other than alphanumeric,
+// dash and underscore replaced by dashes and with the configured prefix. Subscriptions
+// should use raw message delivery, SNS envelopes are unwrapped otherwise.
+type sqsBroker struct {
+	region      string
+	accountID   string
+	prefix      string
+	snsEndpoint string
+	sqsEndpoint string
+	waitTime    time.Duration
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      httpClients.HttpRequestDoer
+}
+
+var _ Broker = &sqsBroker{}
+
+func InitializeSQSBroker(ctx context.Context) error {
+	var err error
+	broker, err = NewSQSBroker(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to initialize sqs: %w", err)
+	}
+
+	InitializeTopicRequests(ctx)
+
+	return nil
+}
+
+func NewSQSBroker(ctx context.Context) (Broker, error) {
+	cfg := config.Kafka.SQS
+	if cfg.AccountID == "" {
+		return nil, fmt.Errorf("%w: SQS account ID is required", ErrBrokerConfig)
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = config.AWS.DefaultRegion
+	}
+
+	key, secret, session := cfg.Key, cfg.Secret, cfg.Session
+	if key == "" {
+		key, secret, session = secrets.AWSKey.Value(), secrets.AWSSecret.Value(), secrets.AWSSession.Value()
+	}
+
+	snsEndpoint := fmt.Sprintf("https://sns.%s.amazonaws.com", region)
+	sqsEndpoint := fmt.Sprintf("https://sqs.%s.amazonaws.com", region)
+	if cfg.Endpoint != "" {
+		snsEndpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+		sqsEndpoint = snsEndpoint
+	}
+
+	waitTime := cfg.WaitTime
+	if waitTime > 20*time.Second {
+		waitTime = 20 * time.Second
+	}
+
+	zerolog.Ctx(ctx).Debug().Msgf("Setting up SNS/SQS broker: region %s, account %s, endpoint %s", region, cfg.AccountID, sqsEndpoint)
+
+	return &sqsBroker{
+		region:      region,
+		accountID:   cfg.AccountID,
+		prefix:      cfg.Prefix,
+		snsEndpoint: snsEndpoint,
+		sqsEndpoint: sqsEndpoint,
+		waitTime:    waitTime,
+		credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(key, secret, session)),
+		signer:      v4.NewSigner(),
+		client:      httpClients.NewPlatformClient(ctx, "", ""),
+	}, nil
+}
+
+var invalidSQSNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+func (b *sqsBroker) resourceName(topic string) string {
+	return b.prefix + invalidSQSNameChars.ReplaceAllString(topic, "-")
+}
+
+func (b *sqsBroker) topicARN(topic string) string {
+	return fmt.Sprintf("arn:aws:sns:%s:%s:%s", b.region, b.accountID, b.resourceName(topic))
+}
+
+func (b *sqsBroker) queueURL(topic string) string {
+	return fmt.Sprintf("%s/%s/%s", b.sqsEndpoint, b.accountID, b.resourceName(topic))
+}
+
+// Send publishes messages one by one, headers and key are sent as message attributes.
+func (b *sqsBroker) Send(ctx context.Context, messages ...*GenericMessage) error {
+	logger := zerolog.Ctx(ctx)
+
+	for _, m := range messages {
+		logger.Trace().Str("topic", m.Topic).Msg("Publishing message to SNS")
+
+		form := url.Values{}
+		form.Set("Action", "Publish")
+		form.Set("Version", "2010-03-31")
+		form.Set("TopicArn", b.topicARN(m.Topic))
+		form.Set("Message", string(m.Value))
+
+		n := 1
+		for _, h := range m.headersWithKey() {
+			// SNS rejects empty attribute values
+			if h.Value == "" {
+				continue
+			}
+			prefix := fmt.Sprintf("MessageAttributes.entry.%d.", n)
+			form.Set(prefix+"Name", h.Key)
+			form.Set(prefix+"Value.DataType", "String")
+			form.Set(prefix+"Value.StringValue", h.Value)
+			n++
+		}
+
+		_, err := b.do(ctx, "sns", b.snsEndpoint, "application/x-www-form-urlencoded; charset=utf-8", "", []byte(form.Encode()))
+		if err != nil {
+			return fmt.Errorf("cannot publish message to topic %s: %w", m.Topic, err)
+		}
+	}
+
+	return nil
+}
+
+type sqsAttribute struct {
+	DataType    string
+	StringValue string
+}
+
+type sqsMessage struct {
+	ReceiptHandle     string
+	Body              string
+	Attributes        map[string]string
+	MessageAttributes map[string]sqsAttribute
+}
+
+// snsEnvelope is the body of messages delivered from SNS without raw message delivery
+type snsEnvelope struct {
+	Type              string
+	TopicArn          string
+	Message           string
+	MessageAttributes map[string]struct {
+		Type  string
+		Value string
+	}
+}
+
+// sentAt returns the time the message was sent to the queue, zero time when unknown.
+func (sm *sqsMessage) sentAt() time.Time {
+	ms, err := strconv.ParseInt(sm.Attributes["SentTimestamp"], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+func (sm *sqsMessage) genericMessage(topic string) *GenericMessage {
+	body := sm.Body
+	headers := make([]GenericHeader, 0, len(sm.MessageAttributes))
+	for name, attr := range sm.MessageAttributes {
+		headers = append(headers, GenericHeader{Key: name, Value: attr.StringValue})
+	}
+
+	var envelope snsEnvelope
+	if err := json.Unmarshal([]byte(sm.Body), &envelope); err == nil && envelope.Type == "Notification" && envelope.TopicArn != "" {
+		body = envelope.Message
+		headers = headers[:0]
+		for name, attr := range envelope.MessageAttributes {
+			headers = append(headers, GenericHeader{Key: name, Value: attr.Value})
+		}
+	}
+
+	return newGenericMessage(topic, []byte(body), headers)
+}
+
+// Consume long polls the queue subscribed to the topic. Messages sent before the since time
+// are deleted without processing, other messages are deleted after the handler returns.
+func (b *sqsBroker) Consume(ctx context.Context, topic string, since time.Time, handler func(ctx context.Context, message *GenericMessage)) {
+	logger := zerolog.Ctx(ctx)
+	queueURL := b.queueURL(topic)
+	since = since.Truncate(time.Millisecond)
+
+	for {
+		messages, err := b.receive(ctx, queueURL)
+		if ctx.Err() != nil {
+			logger.Debug().Msg("SQS receiver has been cancelled")
+			return
+		} else if err != nil {
+			logger.Warn().Err(err).Msg("Error when receiving messages")
+			if !waitRetry(ctx) {
+				return
+			}
+			continue
+		}
+
+		for _, sm := range messages {
+			if sm.sentAt().Before(since) {
+				logger.Trace().Msgf("Skipping message sent at %s", sm.sentAt())
+			} else {
+				message := sm.genericMessage(topic)
+				logger.Trace().Bytes("payload", message.Value).Msgf("Received message from queue %s", queueURL)
+				handler(newMessageContext(ctx, message), message)
+			}
+
+			if err = b.delete(ctx, queueURL, sm.ReceiptHandle); err != nil {
+				logger.Warn().Err(err).Msg("Unable to delete message from queue")
+			}
+		}
+	}
+}
+
+func (b *sqsBroker) receive(ctx context.Context, queueURL string) ([]sqsMessage, error) {
+	request, err := json.Marshal(map[string]any{
+		"QueueUrl":              queueURL,
+		"MaxNumberOfMessages":   10,
+		"WaitTimeSeconds":       int(b.waitTime.Seconds()),
+		"AttributeNames":        []string{"SentTimestamp"},
+		"MessageAttributeNames": []string{"All"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal receive request: %w", err)
+	}
+
+	body, err := b.do(ctx, "sqs", b.sqsEndpoint, "application/x-amz-json-1.0", "AmazonSQS.ReceiveMessage", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Messages []sqsMessage
+	}
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode receive response: %s", ErrSQSRequest, err.Error())
+	}
+	return response.Messages, nil
+}
+
+func (b *sqsBroker) delete(ctx context.Context, queueURL, receiptHandle string) error {
+	request, err := json.Marshal(map[string]string{
+		"QueueUrl":      queueURL,
+		"ReceiptHandle": receiptHandle,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to marshal delete request: %w", err)
+	}
+
+	_, err = b.do(ctx, "sqs", b.sqsEndpoint, "application/x-amz-json-1.0", "AmazonSQS.DeleteMessage", request)
+	return err
+}
+
+// do sends a signed POST request and returns the response body.
+func (b *sqsBroker) do(ctx context.Context, service, endpoint, contentType, target string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSQSRequest, err.Error())
+	}
+	req.Header.Set("Content-Type", contentType)
+	if target != "" {
+		req.Header.Set("X-Amz-Target", target)
+	}
+
+	creds, err := b.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to retrieve credentials: %s", ErrSQSRequest, err.Error())
+	}
+	hash := sha256.Sum256(body)
+	err = b.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), service, b.region, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to sign request: %s", ErrSQSRequest, err.Error())
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSQSRequest, err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read response: %s", ErrSQSRequest, err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned %d: %s", ErrSQSRequest, service, resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
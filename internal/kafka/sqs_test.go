@@ -0,0 +1,137 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSQS implements SNS Publish and SQS ReceiveMessage and DeleteMessage actions, published
+// messages are delivered to the queue as they were subscribed with raw message delivery.
+type fakeSQS struct {
+	mu       sync.Mutex
+	queue    []map[string]any
+	deleted  []string
+	topicARN string
+}
+
+func (f *fakeSQS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "":
+		form, _ := url.ParseQuery(string(body))
+		f.topicARN = form.Get("TopicArn")
+		attributes := map[string]any{}
+		for i := 1; form.Get("MessageAttributes.entry."+strconv.Itoa(i)+".Name") != ""; i++ {
+			prefix := "MessageAttributes.entry." + strconv.Itoa(i) + "."
+			attributes[form.Get(prefix+"Name")] = map[string]string{"DataType": "String", "StringValue": form.Get(prefix + "Value.StringValue")}
+		}
+		f.queue = append(f.queue, map[string]any{
+			"ReceiptHandle":     strconv.Itoa(len(f.queue)),
+			"Body":              form.Get("Message"),
+			"Attributes":        map[string]string{"SentTimestamp": strconv.FormatInt(time.Now().UnixMilli(), 10)},
+			"MessageAttributes": attributes,
+		})
+		_, _ = w.Write([]byte("<PublishResponse/>"))
+	case "AmazonSQS.ReceiveMessage":
+		_ = json.NewEncoder(w).Encode(map[string]any{"Messages": f.queue})
+		f.queue = nil
+	case "AmazonSQS.DeleteMessage":
+		var request map[string]string
+		_ = json.Unmarshal(body, &request)
+		f.deleted = append(f.deleted, request["ReceiptHandle"])
+		_, _ = w.Write([]byte("{}"))
+	}
+}
+
+func newTestSQSBroker(t *testing.T) (Broker, *fakeSQS) {
+	t.Helper()
+	fake := &fakeSQS{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	config.Kafka.SQS.Endpoint = srv.URL
+	config.Kafka.SQS.AccountID = "123456789012"
+	config.Kafka.SQS.Region = "us-east-1"
+	config.Kafka.SQS.Key = "key"
+	config.Kafka.SQS.Secret = "secret"
+	t.Cleanup(func() {
+		config.Kafka.SQS.Endpoint = ""
+		config.Kafka.SQS.AccountID = ""
+		config.Kafka.SQS.Region = ""
+		config.Kafka.SQS.Key = ""
+		config.Kafka.SQS.Secret = ""
+	})
+
+	b, err := NewSQSBroker(context.Background())
+	require.NoError(t, err)
+	return b, fake
+}
+
+func TestSQSBrokerRequiresAccount(t *testing.T) {
+	_, err := NewSQSBroker(context.Background())
+	assert.ErrorIs(t, err, ErrBrokerConfig)
+}
+
+func TestSQSBrokerSendAndConsume(t *testing.T) {
+	b, fake := newTestSQSBroker(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := b.Send(ctx, &GenericMessage{
+		Topic:   "platform.sources.status",
+		Key:     []byte("key"),
+		Value:   []byte(`{"status":"ok"}`),
+		Headers: GenericHeaders("event_type", "availability_status", "empty", ""),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:platform-sources-status", fake.topicARN)
+
+	received := make(chan *GenericMessage, 1)
+	go b.Consume(ctx, "platform.sources.status", time.Now().Add(-time.Minute), func(_ context.Context, message *GenericMessage) {
+		received <- message
+		cancel()
+	})
+
+	select {
+	case message := <-received:
+		assert.Equal(t, "platform.sources.status", message.Topic)
+		assert.Equal(t, []byte("key"), message.Key)
+		assert.Equal(t, `{"status":"ok"}`, string(message.Value))
+		assert.Equal(t, "availability_status", message.Header("event_type"))
+		assert.Equal(t, "", message.Header(messageKeyHeader))
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+}
+
+func TestSQSMessageUnwrapsSNSEnvelope(t *testing.T) {
+	envelope := `{"Type":"Notification","TopicArn":"arn:aws:sns:us-east-1:1:t","Message":"{\"a\":1}",` +
+		`"MessageAttributes":{"event_type":{"Type":"String","Value":"launch"}}}`
+	sm := &sqsMessage{Body: envelope}
+
+	message := sm.genericMessage("t")
+
+	assert.Equal(t, `{"a":1}`, string(message.Value))
+	assert.Equal(t, "launch", message.Header("event_type"))
+}
@@ -11,6 +11,7 @@ var (
 	availabilityStatusRequestTopicReq = "platform.provisioning.internal.availability-check"
 	sendStatusToSourcesTopicReq       = "platform.sources.status"
 	sendNotificationMessage           = "platform.notifications.ingress"
+	auditTopicReq                     = "platform.provisioning.audit"
 )
 
 // topics after clowder mapping
@@ -18,6 +19,7 @@ var (
 	AvailabilityStatusRequestTopic string
 	SourcesStatusTopic             string
 	NotificationTopic              string
+	AuditTopic                     string
 )
 
 // InitializeTopicRequests performs clowder mapping of topics.
@@ -25,4 +27,5 @@ func InitializeTopicRequests(ctx context.Context) {
 	AvailabilityStatusRequestTopic = config.TopicName(ctx, availabilityStatusRequestTopicReq)
 	SourcesStatusTopic = config.TopicName(ctx, sendStatusToSourcesTopicReq)
 	NotificationTopic = config.TopicName(ctx, sendNotificationMessage)
+	AuditTopic = config.TopicName(ctx, auditTopicReq)
 }
@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// headerCarrier adapts message headers to the OpenTelemetry propagation API.
+type headerCarrier struct {
+	m *GenericMessage
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
+
+func (c headerCarrier) Get(key string) string {
+	return c.m.Header(key)
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range c.m.Headers {
+		if strings.EqualFold(h.Key, key) {
+			c.m.Headers[i].Value = value
+			return
+		}
+	}
+	c.m.Headers = append(c.m.Headers, GenericHeader{Key: key, Value: value})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(c.m.Headers))
+	for i, h := range c.m.Headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// injectTraceContext adds W3C trace context headers (traceparent) of the span from the context
+// unless the message already carries them, e.g. when it was created in a different context
+// than it is sent from.
+func injectTraceContext(ctx context.Context, m *GenericMessage) {
+	if m.Header("traceparent") != "" {
+		return
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{m: m})
+}
+
+// extractTraceContext returns context with the remote span from message trace context headers,
+// spans started from the context are linked to the span of the producer.
+func extractTraceContext(ctx context.Context, m *GenericMessage) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier{m: m})
+}
@@ -0,0 +1,53 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func withTestSpan(t *testing.T) (context.Context, trace.SpanContext) {
+	t.Helper()
+	saved := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(saved) })
+
+	traceId, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanId, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId, TraceFlags: trace.FlagsSampled})
+	return trace.ContextWithSpanContext(context.Background(), sc), sc
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	ctx, sc := withTestSpan(t)
+	ctx = identity.WithIdentity(t, ctx)
+
+	msg, err := AvailabilityStatusMessage{SourceID: "1"}.GenericMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", msg.Header("traceparent"))
+
+	msgCtx := newMessageContext(context.Background(), &msg)
+	remote := trace.SpanContextFromContext(msgCtx)
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, sc.TraceID(), remote.TraceID())
+	assert.Equal(t, sc.SpanID(), remote.SpanID())
+	assert.Equal(t, sc.TraceID().String(), logging.TraceId(msgCtx))
+}
+
+func TestTraceContextKeptOnSend(t *testing.T) {
+	ctx, _ := withTestSpan(t)
+
+	msg := GenericMessage{Headers: GenericHeaders("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")}
+	injectTraceContext(ctx, &msg)
+	require.Len(t, msg.Headers, 1)
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", msg.Header("traceparent"))
+}
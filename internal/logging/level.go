@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	levelMu sync.Mutex
+
+	// levelTimer restores the configured level after a temporary change, nil when not changed
+	levelTimer *time.Timer
+
+	// levelResetAt is the time when the configured level is restored
+	levelResetAt time.Time
+)
+
+// ConfiguredLevel returns the level of the LOGGING_LEVEL setting, it can change when the
+// configuration is reloaded.
+func ConfiguredLevel() zerolog.Level {
+	level, err := zerolog.ParseLevel(config.Current().LogLevel)
+	if err != nil || level == zerolog.NoLevel {
+		return zerolog.InfoLevel
+	}
+	return level
+}
+
+// Level returns the current global level and the time when the configured level is restored,
+// zero time when the level was not changed.
+func Level() (zerolog.Level, time.Time) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	return zerolog.GlobalLevel(), levelResetAt
+}
+
+// SetLevel changes the global level at runtime, the configured level is restored after the window.
+// Changes are per process, they are not shared between replicas.
+func SetLevel(level zerolog.Level, window time.Duration) time.Time {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	stopLevelTimer()
+	zerolog.SetGlobalLevel(level)
+	levelResetAt = time.Now().Add(window)
+	levelTimer = time.AfterFunc(window, expireLevel)
+	log.Warn().Msgf("Log level changed to %s until %s", level, levelResetAt.Format(time.RFC3339))
+	return levelResetAt
+}
+
+// ResetLevel restores the configured level.
+func ResetLevel() {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	stopLevelTimer()
+	zerolog.SetGlobalLevel(ConfiguredLevel())
+	log.Warn().Msgf("Log level restored to %s", ConfiguredLevel())
+}
+
+// reloadLevel applies a changed LOGGING_LEVEL setting. A temporary level is kept until it
+// expires, the new configured level is restored afterwards.
+func reloadLevel(previous, current *config.Runtime) {
+	if previous.LogLevel == current.LogLevel {
+		return
+	}
+
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	if levelResetAt.IsZero() {
+		zerolog.SetGlobalLevel(ConfiguredLevel())
+	}
+	log.Info().Msgf("Configured log level changed to %s", ConfiguredLevel())
+}
+
+// expireLevel restores the configured level unless the level was changed again meanwhile.
+func expireLevel() {
+	levelMu.Lock()
+	expired := !levelResetAt.IsZero() && !time.Now().Before(levelResetAt)
+	levelMu.Unlock()
+
+	if expired {
+		ResetLevel()
+	}
+}
+
+func stopLevelTimer() {
+	if levelTimer != nil {
+		levelTimer.Stop()
+		levelTimer = nil
+	}
+	levelResetAt = time.Time{}
+}
+
+// handleLevelSignal toggles trace level on SIGHUP, the configured level is restored after
+// the LOGGING_LEVEL_WINDOW setting or on the next signal.
+func handleLevelSignal() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			if _, resetAt := Level(); !resetAt.IsZero() {
+				ResetLevel()
+			} else {
+				SetLevel(zerolog.TraceLevel, config.Logging.LevelWindow)
+			}
+		}
+	}()
+}
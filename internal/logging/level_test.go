@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLevel(t *testing.T) {
+	configured, original := config.Logging.Level, zerolog.GlobalLevel()
+	config.Logging.Level = "info"
+	t.Cleanup(func() {
+		ResetLevel()
+		config.Logging.Level = configured
+		zerolog.SetGlobalLevel(original)
+	})
+
+	t.Run("changed until window", func(t *testing.T) {
+		resetAt := SetLevel(zerolog.TraceLevel, time.Hour)
+		level, at := Level()
+		assert.Equal(t, zerolog.TraceLevel, level)
+		assert.Equal(t, resetAt, at)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		ResetLevel()
+		level, at := Level()
+		assert.Equal(t, zerolog.InfoLevel, level)
+		assert.True(t, at.IsZero())
+	})
+
+	t.Run("restored after window", func(t *testing.T) {
+		SetLevel(zerolog.DebugLevel, 10*time.Millisecond)
+		assert.Eventually(t, func() bool {
+			level, _ := Level()
+			return level == zerolog.InfoLevel
+		}, time.Second, 5*time.Millisecond)
+	})
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type repeatEntry struct {
+	level zerolog.Level
+	msg   string
+	since time.Time
+	count int
+}
+
+// RepeatHook collapses identical warning and error messages logged within a window. The first
+// message is logged, repeated ones are discarded and counted. When the window expires a summary
+// with the number of repeats is logged. It prevents flooding of logs during provider outages.
+type RepeatHook struct {
+	window  time.Duration
+	out     zerolog.Logger
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*repeatEntry
+}
+
+// NewRepeatHook returns a hook which writes summaries into the out logger, it must be
+// a logger without the hook.
+func NewRepeatHook(out zerolog.Logger, window time.Duration) *RepeatHook {
+	return &RepeatHook{
+		window:  window,
+		out:     out,
+		now:     time.Now,
+		entries: make(map[string]*repeatEntry),
+	}
+}
+
+// Run implements zerolog.Hook interface.
+func (h *RepeatHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	// fatal and panic messages are always logged
+	if level < zerolog.WarnLevel || level > zerolog.ErrorLevel {
+		return
+	}
+
+	key := level.String() + msg
+	now := h.now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry, ok := h.entries[key]
+	if ok && now.Sub(entry.since) < h.window {
+		entry.count++
+		e.Discard()
+		return
+	}
+	if ok {
+		h.summary(entry)
+	}
+	h.entries[key] = &repeatEntry{level: level, msg: msg, since: now}
+}
+
+// Flush logs summaries of messages with expired window, or of all messages when all is true.
+func (h *RepeatHook) Flush(all bool) {
+	now := h.now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, entry := range h.entries {
+		if all || now.Sub(entry.since) >= h.window {
+			h.summary(entry)
+			delete(h.entries, key)
+		}
+	}
+}
+
+// StartFlushing periodically logs summaries until the returned function is called, which
+// also logs all pending summaries.
+func (h *RepeatHook) StartFlushing() func() {
+	ticker := time.NewTicker(h.window)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				h.Flush(false)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		h.Flush(true)
+	}
+}
+
+func (h *RepeatHook) summary(entry *repeatEntry) {
+	if entry.count == 0 {
+		return
+	}
+	h.out.WithLevel(entry.level).
+		Int("repeated", entry.count).
+		Msgf("%s (repeated %d times)", entry.msg, entry.count)
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatHook(t *testing.T) {
+	var buf bytes.Buffer
+	out := zerolog.New(&buf)
+	now := time.Now()
+	hook := NewRepeatHook(out, time.Minute)
+	hook.now = func() time.Time { return now }
+	logger := out.Hook(hook)
+
+	t.Run("repeated warnings are collapsed", func(t *testing.T) {
+		buf.Reset()
+		for i := 0; i < 5; i++ {
+			logger.Warn().Msg("sources unavailable")
+		}
+		logger.Info().Msg("sources are back")
+		logger.Info().Msg("sources are back")
+		assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("sources unavailable")))
+		assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(`"message":"sources are back"`)), "info messages are not collapsed")
+	})
+
+	t.Run("different messages are kept", func(t *testing.T) {
+		buf.Reset()
+		logger.Warn().Msg("other warning")
+		logger.Error().Msg("sources unavailable")
+		assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+	})
+
+	t.Run("summary after window", func(t *testing.T) {
+		buf.Reset()
+		now = now.Add(time.Minute)
+		hook.Flush(false)
+		assert.Contains(t, buf.String(), "sources unavailable (repeated 4 times)")
+		assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")), "messages without repeats have no summary")
+
+		buf.Reset()
+		logger.Warn().Msg("sources unavailable")
+		assert.Contains(t, buf.String(), "sources unavailable")
+	})
+}
@@ -13,7 +13,7 @@ var filters = []string{
 	// Example (AWS SDK): RequestID: ca767444-d1f9-11ed-afa1-0242ac120002
 	`[0-9a-fA-F]{8}\b-[0-9a-fA-F]{4}\b-[0-9a-fA-F]{4}\b-[0-9a-fA-F]{4}\b-[0-9a-fA-F]{12}`,
 	// Example (AWS SDK): arn:aws:iam::4328974392798432:role/my-role-123
-	`arn:aws:[[:word:]]+::\d+:[[:word:]\*-]+/[[:word:]\*-]+`,
+	`arn:aws[[:word:]-]*:[[:word:]]+::\d+:[[:word:]\*-]+/[[:word:]\*-]+`,
 	// Example (AWS SDK): i-1234567890abcdef0
 	`[a-z]-[0-9a-f]{17}`,
 	// Example: 57:d4:13:ff:c0:74:51:50:41:ec:e1:cd:f1:88:b0:61
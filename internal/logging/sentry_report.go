@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+	"github.com/getsentry/sentry-go"
+)
+
+// reportHub returns hub for error reports, the global hub is initialized with the log writer.
+var reportHub = sentry.CurrentHub
+
+// ReportError sends the error to Sentry as a separate event tagged with trace id, account,
+// organization and version so server errors and failed jobs can be searched and grouped.
+// Unlike the log writer, the event carries the error chain. Does nothing unless reporting
+// is enabled in the configuration.
+func ReportError(ctx context.Context, err error, tags map[string]string) {
+	if err == nil || !config.Sentry.Report || config.Sentry.Dsn == "" {
+		return
+	}
+	reportError(ctx, reportHub(), err, tags)
+}
+
+func reportError(ctx context.Context, parent *sentry.Hub, err error, tags map[string]string) {
+	hub := parent.Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("version", version.BuildCommit)
+		if traceId := TraceId(ctx); traceId != "" {
+			scope.SetTag("trace_id", traceId)
+		}
+		if accountId := identity.AccountIdOrNil(ctx); accountId != 0 {
+			scope.SetTag("account_id", strconv.FormatInt(accountId, 10))
+		}
+		if orgId := identity.OrgIdOrEmpty(ctx); orgId != "" {
+			scope.SetTag("org_id", orgId)
+		}
+		scope.SetTags(tags)
+	})
+	hub.CaptureException(err)
+}
@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportError(t *testing.T) {
+	var events []*sentry.Event
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		BeforeSend: func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			events = append(events, event)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	ctx := WithTraceId(context.Background(), "trace1")
+	ctx = identity.WithAccountId(ctx, 13)
+	reportError(ctx, hub, errors.New("failure"), map[string]string{"status": "500"})
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "trace1", events[0].Tags["trace_id"])
+	assert.Equal(t, "13", events[0].Tags["account_id"])
+	assert.Equal(t, "500", events[0].Tags["status"])
+	assert.Contains(t, events[0].Tags, "version")
+	assert.NotContains(t, events[0].Tags, "org_id")
+	require.Len(t, events[0].Exception, 1)
+	assert.Equal(t, "failure", events[0].Exception[0].Value)
+
+	// tags of a report do not leak into other reports
+	reportError(context.Background(), hub, errors.New("failure"), nil)
+	require.Len(t, events, 2)
+	assert.NotContains(t, events[1].Tags, "status")
+}
@@ -0,0 +1,225 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+)
+
+// hecPath is the HTTP event collector endpoint accepting batches of JSON events.
+const hecPath = "/services/collector/event"
+
+// hecTimeout is the maximum duration of a single request to the collector.
+const hecTimeout = 10 * time.Second
+
+var ErrHECRequest = errors.New("splunk HEC request failed")
+
+// hecEvent is an event in the HTTP event collector format.
+type hecEvent struct {
+	Time       float64         `json:"time"`
+	Host       string          `json:"host,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	Sourcetype string          `json:"sourcetype"`
+	Index      string          `json:"index,omitempty"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// hecWriter is a zerolog writer which sends events to the Splunk HTTP event collector in
+// batches. Writes never block on the network, events are queued and sent by a background
+// goroutine. Failed requests are retried with exponential backoff, errors of the writer are
+// printed to the standard error because they cannot be logged.
+type hecWriter struct {
+	url       string
+	token     string
+	index     string
+	source    string
+	host      string
+	client    *http.Client
+	batchSize int
+	queueSize int
+	retries   int
+	backoff   time.Duration
+
+	mu      sync.Mutex
+	queue   [][]byte
+	dropped int
+
+	flush   chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newHECWriter(url, token string) *hecWriter {
+	return &hecWriter{
+		url:       strings.TrimSuffix(url, "/") + hecPath,
+		token:     token,
+		host:      config.Hostname(),
+		client:    &http.Client{Timeout: hecTimeout},
+		batchSize: 100,
+		queueSize: 10000,
+		retries:   3,
+		backoff:   500 * time.Millisecond,
+		flush:     make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// splunkWriter creates a zerolog writer for the Splunk HTTP event collector.
+func splunkWriter() (io.Writer, func(), error) {
+	if config.Splunk.BatchSize <= 0 || config.Splunk.FlushInterval <= 0 {
+		return nil, func() {}, fmt.Errorf("cannot initialize splunk: %w: batch size and flush interval must be positive", ErrHECRequest)
+	}
+
+	w := newHECWriter(config.Splunk.URL, config.Splunk.Token)
+	w.index = config.Splunk.Index
+	w.source = config.Splunk.Source
+	if w.source == "" {
+		w.source = config.BinaryName()
+	}
+	w.batchSize = config.Splunk.BatchSize
+	w.queueSize = config.Splunk.QueueSize
+	w.retries = config.Splunk.Retries
+
+	go w.run(config.Splunk.FlushInterval)
+	return w, w.Close, nil
+}
+
+// Write queues a single JSON event, zerolog writes one event per call.
+func (w *hecWriter) Write(p []byte) (int, error) {
+	event := json.RawMessage(bytes.TrimSpace(p))
+	if !json.Valid(event) {
+		// console output or a broken event, send it as a string
+		event, _ = json.Marshal(string(event))
+	}
+	buf, err := json.Marshal(hecEvent{
+		Time:       float64(time.Now().UnixMilli()) / 1000,
+		Host:       w.host,
+		Source:     w.source,
+		Sourcetype: "_json",
+		Index:      w.index,
+		Event:      event,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot encode splunk event: %w", err)
+	}
+
+	w.mu.Lock()
+	w.queue = append(w.queue, buf)
+	if w.queueSize > 0 && len(w.queue) > w.queueSize {
+		w.queue = w.queue[1:]
+		w.dropped++
+	}
+	full := len(w.queue) >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flush <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Close sends all queued events and stops the background goroutine.
+func (w *hecWriter) Close() {
+	close(w.done)
+	<-w.stopped
+}
+
+func (w *hecWriter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(w.stopped)
+
+	for {
+		select {
+		case <-ticker.C:
+			w.sendAll()
+		case <-w.flush:
+			w.sendAll()
+		case <-w.done:
+			w.sendAll()
+			return
+		}
+	}
+}
+
+// sendAll sends queued events in batches until the queue is empty.
+func (w *hecWriter) sendAll() {
+	for {
+		w.mu.Lock()
+		n := len(w.queue)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		batch := w.queue[:n]
+		w.queue = w.queue[n:]
+		dropped := w.dropped
+		w.dropped = 0
+		w.mu.Unlock()
+
+		if dropped > 0 {
+			fmt.Fprintf(os.Stderr, "splunk: queue full, dropped %d events\n", dropped)
+		}
+		if n == 0 {
+			return
+		}
+		if err := w.send(bytes.Join(batch, nil)); err != nil {
+			fmt.Fprintf(os.Stderr, "splunk: dropped %d events: %s\n", n, err)
+		}
+	}
+}
+
+// send posts the batch and retries on network errors, throttling and server errors.
+func (w *hecWriter) send(body []byte) error {
+	var err error
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(w.backoff << (attempt - 1))
+		}
+
+		var retry bool
+		retry, err = w.post(body)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (w *hecWriter) post(body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hecTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("cannot create splunk request: %w", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+w.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("%w: %s", ErrHECRequest, err.Error())
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("%w: status %d", ErrHECRequest, resp.StatusCode)
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hecServer struct {
+	mu       sync.Mutex
+	failures int
+	requests int
+	events   []hecEvent
+}
+
+func (s *hecServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		assert.Equal(t, hecPath, r.URL.Path)
+		assert.Equal(t, "Splunk token", r.Header.Get("Authorization"))
+		s.requests++
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoder := json.NewDecoder(strings.NewReader(string(body)))
+		for decoder.More() {
+			var event hecEvent
+			require.NoError(t, decoder.Decode(&event))
+			s.events = append(s.events, event)
+		}
+	}
+}
+
+func newTestHECWriter(t *testing.T, s *hecServer) *hecWriter {
+	srv := httptest.NewServer(s.handler(t))
+	t.Cleanup(srv.Close)
+
+	w := newHECWriter(srv.URL+"/", "token")
+	w.batchSize = 2
+	w.backoff = time.Millisecond
+	go w.run(time.Hour)
+	return w
+}
+
+func TestHECWriterBatches(t *testing.T) {
+	s := &hecServer{}
+	w := newTestHECWriter(t, s)
+
+	_, _ = w.Write([]byte(`{"level":"info","message":"one"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"info","message":"two"}` + "\n"))
+	_, _ = w.Write([]byte("not json\n"))
+	w.Close()
+
+	require.Len(t, s.events, 3)
+	assert.Equal(t, 2, s.requests)
+	assert.JSONEq(t, `{"level":"info","message":"one"}`, string(s.events[0].Event))
+	assert.JSONEq(t, `"not json"`, string(s.events[2].Event))
+	assert.Equal(t, "_json", s.events[0].Sourcetype)
+}
+
+func TestHECWriterRetries(t *testing.T) {
+	s := &hecServer{failures: 2}
+	w := newTestHECWriter(t, s)
+
+	_, _ = w.Write([]byte(`{"message":"retried"}`))
+	w.Close()
+
+	require.Len(t, s.events, 1)
+	assert.Equal(t, 3, s.requests)
+}
+
+func TestHECWriterDropsOldest(t *testing.T) {
+	w := newHECWriter("http://localhost", "token")
+	w.queueSize = 2
+
+	for i := 0; i < 3; i++ {
+		_, _ = w.Write([]byte(`{}`))
+	}
+	assert.Len(t, w.queue, 2)
+	assert.Equal(t, 1, w.dropped)
+}
@@ -88,8 +88,8 @@ func InitializeStdout() {
 	log.Logger = decorate(log.Output(stdoutWriter(true))).With().Str("binary", config.BinaryName()).Logger()
 }
 
-// InitializeLogger initializes logging to cloudwatch client and enables sentry Error logging.
-// If cloudwatch is disabled, we enable stdout output.
+// InitializeLogger initializes logging to cloudwatch and splunk clients and enables sentry Error
+// logging. If both cloudwatch and splunk are disabled, we enable stdout output.
 func InitializeLogger() (zerolog.Logger, func()) {
 	configureZerolog()
 
@@ -104,8 +104,18 @@ func InitializeLogger() (zerolog.Logger, func()) {
 		}
 		writers = append(writers, cwWriter)
 		closeFns = append(closeFns, cwClose)
-	} else {
-		log.Trace().Msg("Cloudwatch not enabled, enabling stdout")
+	}
+	if config.Splunk.Enabled {
+		sWriter, sClose, err := splunkWriter()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize splunk")
+			panic(err)
+		}
+		writers = append(writers, sWriter)
+		closeFns = append(closeFns, sClose)
+	}
+	if len(writers) == 0 {
+		log.Trace().Msg("Neither cloudwatch nor splunk enabled, enabling stdout")
 		writers = append(writers, stdoutWriter(false))
 	}
 	if config.Sentry.Dsn != "" {
@@ -118,15 +128,29 @@ func InitializeLogger() (zerolog.Logger, func()) {
 		}
 	}
 
+	handleLevelSignal()
+	config.OnChange(reloadLevel)
+
+	logger := decorate(zerolog.New(io.MultiWriter(writers...)))
+	if config.Logging.RepeatWindow > 0 {
+		hook := NewRepeatHook(logger, config.Logging.RepeatWindow)
+		// summaries must be written before writers are closed
+		closeFns = append([]func(){hook.StartFlushing()}, closeFns...)
+		logger = logger.Hook(hook)
+	}
+
 	// closes all writers in one func
 	closeFn := func() {
 		for _, fn := range closeFns {
 			fn()
 		}
 	}
-	logger := decorate(zerolog.New(io.MultiWriter(writers...)))
 	log.Logger = logger
 	zerolog.DefaultContextLogger = &logger
+
+	for _, warning := range config.Warnings() {
+		logger.Warn().Msgf("Configuration: %s", warning)
+	}
 	return logger, closeFn
 }
 
@@ -31,6 +31,28 @@ var CacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
 	ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName},
 }, []string{"type", "result"})
 
+var RateLimitRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name:        "provisioning_rate_limit_rejected_total",
+	Help:        "The total number of API requests rejected by the rate limit per endpoint class",
+	ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "api"},
+}, []string{"class"})
+
+var HTTPCircuitEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name:        "provisioning_http_circuit_events_total",
+	Help:        "circuit breaker events of outbound HTTP requests per host (open/reject)",
+	ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName},
+}, []string{"host", "event"})
+
+var OutboundRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:        "provisioning_outbound_request_duration_seconds",
+		Help:        "outbound HTTP request duration (in seconds) by dependency (sources/image_builder/aws/azure/gcp...), client operation and status (2xx/3xx/4xx/5xx/error)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName},
+		Buckets:     []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	},
+	[]string{"dependency", "operation", "status"},
+)
+
 var JobQueueSize = prometheus.NewGauge(prometheus.GaugeOpts{
 	Name:        "provisioning_job_queue_size",
 	Help:        "background job queue size (total pending jobs)",
@@ -63,6 +85,24 @@ var BackgroundJobDuration = prometheus.NewHistogramVec(
 	[]string{"type"},
 )
 
+var BackgroundJobAttempts = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_background_job_attempts_total",
+		Help:        "task queue job attempts by type and result (success/retry/failure/timeout)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "worker"},
+	},
+	[]string{"type", "result"},
+)
+
+var BackgroundJobArgsVersionMismatch = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_background_job_args_version_mismatch_total",
+		Help:        "task queue jobs with arguments of other than current version by type and result (upgraded/unsupported/failure)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "worker"},
+	},
+	[]string{"type", "result"},
+)
+
 var ReservationCount = prometheus.NewCounterVec(
 	prometheus.CounterOpts{
 		Name:        "provisioning_reservation_count",
@@ -72,6 +112,60 @@ var ReservationCount = prometheus.NewCounterVec(
 	[]string{"type", "result"},
 )
 
+var ReservationsCreated = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_reservations_created_total",
+		Help:        "reservations created by users by provider (aws/gcp/azure/noop)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName},
+	},
+	[]string{"provider"},
+)
+
+var InstancesLaunched = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_instances_launched_total",
+		Help:        "instances successfully launched by provider (aws/gcp/azure)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName},
+	},
+	[]string{"provider"},
+)
+
+var LaunchFailures = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_launch_failures_total",
+		Help:        "failed reservations by provider (aws/gcp/azure/noop) and title of the failed job step",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName},
+	},
+	[]string{"provider", "step"},
+)
+
+var PubkeysUploaded = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_pubkeys_uploaded_total",
+		Help:        "pubkeys uploaded to the cloud provider (aws) during launch",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName},
+	},
+	[]string{"provider"},
+)
+
+var OutboxMessages = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_outbox_messages_total",
+		Help:        "outbox messages relayed to the message broker by result (published/failed)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "worker"},
+	},
+	[]string{"result"},
+)
+
+var WebhookDeliveries = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_webhook_deliveries_total",
+		Help:        "webhook delivery attempts by result (delivered/failed)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "worker"},
+	},
+	[]string{"result"},
+)
+
 var DbStatsDuration = prometheus.NewHistogram(
 	prometheus.HistogramOpts{
 		Name:        "provisioning_db_stats_duration",
@@ -99,6 +193,51 @@ var Reservations28dCount = prometheus.NewGaugeVec(
 	[]string{"result", "provider"},
 )
 
+var KafkaConsumerLag = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name:        "provisioning_kafka_consumer_lag",
+		Help:        "number of messages behind the end of the partition per topic and partition",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "kafka"},
+	},
+	[]string{"topic", "partition"},
+)
+
+var KafkaConsumerOffset = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name:        "provisioning_kafka_consumer_offset",
+		Help:        "offset of the last consumed message per topic and partition",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "kafka"},
+	},
+	[]string{"topic", "partition"},
+)
+
+var KafkaConsumerPartitionAssigned = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name:        "provisioning_kafka_consumer_partition_assigned",
+		Help:        "partitions currently consumed per topic (1 when assigned)",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "kafka"},
+	},
+	[]string{"topic", "partition"},
+)
+
+var KafkaConsumerRebalances = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_kafka_consumer_rebalances_total",
+		Help:        "consumer group rebalances per topic",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "kafka"},
+	},
+	[]string{"topic"},
+)
+
+var KafkaConsumerErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_kafka_consumer_errors_total",
+		Help:        "consumer read errors per topic",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "kafka"},
+	},
+	[]string{"topic"},
+)
+
 func ObserveAvailabilityCheckReqsDuration(provider string, observedFunc func() error) {
 	errString := "false"
 	start := time.Now()
@@ -137,6 +276,18 @@ func IncCacheHit(model, result string) {
 	CacheHits.WithLabelValues(model, result).Inc()
 }
 
+func IncRateLimitRejected(class string) {
+	RateLimitRejected.WithLabelValues(class).Inc()
+}
+
+func IncHTTPCircuitEvent(host, event string) {
+	HTTPCircuitEvents.WithLabelValues(host, event).Inc()
+}
+
+func ObserveOutboundRequestDuration(dependency, operation, status string, duration time.Duration) {
+	OutboundRequestDuration.WithLabelValues(dependency, operation, status).Observe(duration.Seconds())
+}
+
 func SetJobQueueSize(size uint64) {
 	JobQueueSize.Set(float64(size))
 }
@@ -145,10 +296,34 @@ func SetJobQueueInFlight(workerName string, inflight int64) {
 	JobQueueInFlight.WithLabelValues(workerName).Set(float64(inflight))
 }
 
+func IncBackgroundJobAttempt(jobType, result string) {
+	BackgroundJobAttempts.WithLabelValues(jobType, result).Inc()
+}
+
+func IncBackgroundJobArgsVersionMismatch(jobType, result string) {
+	BackgroundJobArgsVersionMismatch.WithLabelValues(jobType, result).Inc()
+}
+
 func IncReservationCount(rtype, result string) {
 	ReservationCount.WithLabelValues(rtype, result).Inc()
 }
 
+func IncReservationsCreated(provider models.ProviderType) {
+	ReservationsCreated.WithLabelValues(provider.String()).Inc()
+}
+
+func AddInstancesLaunched(provider models.ProviderType, count int) {
+	InstancesLaunched.WithLabelValues(provider.String()).Add(float64(count))
+}
+
+func IncLaunchFailures(provider models.ProviderType, step string) {
+	LaunchFailures.WithLabelValues(provider.String(), step).Inc()
+}
+
+func IncPubkeysUploaded(provider models.ProviderType) {
+	PubkeysUploaded.WithLabelValues(provider.String()).Inc()
+}
+
 func ObserveDbStatsDuration(observedFunc func()) {
 	start := time.Now()
 	defer func() {
@@ -158,6 +333,16 @@ func ObserveDbStatsDuration(observedFunc func()) {
 	observedFunc()
 }
 
+func AddOutboxMessages(published, failed int) {
+	OutboxMessages.WithLabelValues("published").Add(float64(published))
+	OutboxMessages.WithLabelValues("failed").Add(float64(failed))
+}
+
+func AddWebhookDeliveries(delivered, failed int) {
+	WebhookDeliveries.WithLabelValues("delivered").Add(float64(delivered))
+	WebhookDeliveries.WithLabelValues("failed").Add(float64(failed))
+}
+
 func SetReservations24hCount(result string, pt models.ProviderType, count int64) {
 	Reservations24hCount.WithLabelValues(result, pt.String()).Set(float64(count))
 }
@@ -165,3 +350,20 @@ func SetReservations24hCount(result string, pt models.ProviderType, count int64)
 func SetReservations28dCount(result string, pt models.ProviderType, count int64) {
 	Reservations28dCount.WithLabelValues(result, pt.String()).Set(float64(count))
 }
+
+// SetKafkaConsumerStats records a snapshot of consumer statistics, counters are increments
+// since the previous snapshot.
+func SetKafkaConsumerStats(topic, partition string, lag, offset, rebalances, errors int64) {
+	KafkaConsumerLag.WithLabelValues(topic, partition).Set(float64(lag))
+	KafkaConsumerOffset.WithLabelValues(topic, partition).Set(float64(offset))
+	KafkaConsumerPartitionAssigned.WithLabelValues(topic, partition).Set(1)
+	KafkaConsumerRebalances.WithLabelValues(topic).Add(float64(rebalances))
+	KafkaConsumerErrors.WithLabelValues(topic).Add(float64(errors))
+}
+
+// UnsetKafkaConsumerPartition removes gauges of a partition which is no longer consumed.
+func UnsetKafkaConsumerPartition(topic, partition string) {
+	KafkaConsumerLag.DeleteLabelValues(topic, partition)
+	KafkaConsumerOffset.DeleteLabelValues(topic, partition)
+	KafkaConsumerPartitionAssigned.DeleteLabelValues(topic, partition)
+}
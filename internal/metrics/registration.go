@@ -8,7 +8,10 @@ func RegisterStatuserMetrics() {
 		AvailabilityCheckReqsDuration,
 		TotalInvalidAvailabilityCheckReqs,
 		CacheHits,
+		HTTPCircuitEvents,
+		OutboundRequestDuration,
 	)
+	registerKafkaConsumerMetrics()
 }
 
 func RegisterStatsMetrics() {
@@ -24,13 +27,56 @@ func RegisterStatsMetrics() {
 func RegisterApiMetrics() {
 	prometheus.MustRegister(
 		CacheHits,
+		RateLimitRejected,
+		HTTPCircuitEvents,
+		OutboundRequestDuration,
 	)
+	registerBusinessMetrics()
 }
 
 func RegisterWorkerMetrics() {
 	prometheus.MustRegister(
 		BackgroundJobDuration,
+		BackgroundJobAttempts,
+		BackgroundJobArgsVersionMismatch,
 		ReservationCount,
+		OutboxMessages,
+		WebhookDeliveries,
 		CacheHits,
+		HTTPCircuitEvents,
+		OutboundRequestDuration,
+	)
+	registerBusinessMetrics()
+	registerKafkaConsumerMetrics()
+}
+
+// RegisterSchedulerMetrics registers metrics of recurring tasks for workers running the scheduler.
+func RegisterSchedulerMetrics() {
+	prometheus.MustRegister(
+		DbStatsDuration,
+		Reservations24hCount,
+		Reservations28dCount,
+	)
+}
+
+// registerKafkaConsumerMetrics registers metrics of processes consuming Kafka topics.
+func registerKafkaConsumerMetrics() {
+	prometheus.MustRegister(
+		KafkaConsumerLag,
+		KafkaConsumerOffset,
+		KafkaConsumerPartitionAssigned,
+		KafkaConsumerRebalances,
+		KafkaConsumerErrors,
+	)
+}
+
+// registerBusinessMetrics registers product metrics of launches, they are incremented by both
+// the API (reservations) and workers (launch results).
+func registerBusinessMetrics() {
+	prometheus.MustRegister(
+		ReservationsCreated,
+		InstancesLaunched,
+		LaunchFailures,
+		PubkeysUploaded,
 	)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/version"
@@ -66,7 +67,7 @@ func AccountMiddleware(next http.Handler) http.Handler {
 			AppName:       version.UnleashAppName,
 			Properties:    nil,
 		}
-		ctx = config.WithUnleashContext(ctx, uctx)
+		ctx = featureflags.WithUnleashContext(ctx, uctx)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+)
+
+// APIVersion stores the API version of the mounted router in the request context.
+func APIVersion(apiVersion string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(version.WithAPIVersion(r.Context(), apiVersion)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
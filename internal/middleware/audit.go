@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+)
+
+// auditedMethods are HTTP methods of mutating API calls.
+var auditedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Audit records mutating API calls into the audit log and optionally publishes them to the
+// audit topic. It must be used after AccountMiddleware. Failures are only logged, they never
+// change the response.
+func Audit(next http.Handler) http.Handler {
+	return audit(next, func(r *http.Request) bool {
+		return auditedMethods[r.Method]
+	})
+}
+
+// AuditAll records all API calls including reads into the audit log, it is meant for
+// support endpoints where associates access data of other organizations.
+func AuditAll(next http.Handler) http.Handler {
+	return audit(next, func(_ *http.Request) bool {
+		return true
+	})
+}
+
+func audit(next http.Handler, audited func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !config.Current().AuditEnabled || !audited(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		record := &models.APIAudit{
+			AccountID:  identity.AccountIdOrNil(r.Context()),
+			OrgID:      identity.OrgIdOrEmpty(r.Context()),
+			Principal:  identity.PrincipalName(r.Context()),
+			Method:     r.Method,
+			Route:      chi.RouteContext(r.Context()).RoutePattern(),
+			ResourceID: chi.URLParam(r, "ID"),
+			Status:     status,
+			TraceID:    logging.TraceId(r.Context()),
+		}
+		recordAudit(r.Context(), record)
+	})
+}
+
+func recordAudit(ctx context.Context, audit *models.APIAudit) {
+	logger := zerolog.Ctx(ctx)
+
+	err := dao.GetAPIAuditDao(ctx).UnscopedCreate(ctx, audit)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Unable to record audit of %s %s", audit.Method, audit.Route)
+		return
+	}
+
+	if !config.Kafka.Enabled || !config.Current().AuditKafka {
+		return
+	}
+	msg, err := kafka.NewAuditMessage(audit).GenericMessage(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to create audit message")
+		return
+	}
+	err = dao.GetOutboxDao(ctx).UnscopedEnqueue(ctx, msg.OutboxMessage())
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to enqueue audit message")
+	}
+}
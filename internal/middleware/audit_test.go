@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	config.Application.Audit.Enabled = true
+	t.Cleanup(func() { config.Application.Audit.Enabled = false })
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithAPIAuditDao(ctx)
+
+	router := chi.NewRouter()
+	router.Use(middleware.Audit)
+	router.Get("/pubkeys", func(w http.ResponseWriter, r *http.Request) {})
+	router.Delete("/pubkeys/{ID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	serve := func(method, path string) {
+		req, err := http.NewRequestWithContext(ctx, method, path, nil)
+		require.NoError(t, err)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("read is not recorded", func(t *testing.T) {
+		serve(http.MethodGet, "/pubkeys")
+		assert.Equal(t, 0, stubs.APIAuditStubCount(ctx))
+	})
+
+	t.Run("delete is recorded", func(t *testing.T) {
+		serve(http.MethodDelete, "/pubkeys/42")
+		require.Equal(t, 1, stubs.APIAuditStubCount(ctx))
+
+		audits, err := dao.GetAPIAuditDao(ctx).UnscopedListByAccount(ctx, 1, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, audits, 1)
+		assert.Equal(t, http.MethodDelete, audits[0].Method)
+		assert.Equal(t, "/pubkeys/{ID}", audits[0].Route)
+		assert.Equal(t, "42", audits[0].ResourceID)
+		assert.Equal(t, http.StatusNoContent, audits[0].Status)
+	})
+}
+
+func TestAuditAll(t *testing.T) {
+	config.Application.Audit.Enabled = true
+	t.Cleanup(func() { config.Application.Audit.Enabled = false })
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithAPIAuditDao(ctx)
+
+	router := chi.NewRouter()
+	router.Use(middleware.AuditAll)
+	router.Get("/reservations/{ID}", func(w http.ResponseWriter, r *http.Request) {})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/reservations/42", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, 1, stubs.APIAuditStubCount(ctx))
+
+	audits, err := dao.GetAPIAuditDao(ctx).UnscopedListByAccount(ctx, 1, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, audits, 1)
+	assert.Equal(t, http.MethodGet, audits[0].Method)
+	assert.Equal(t, "/reservations/{ID}", audits[0].Route)
+	assert.Equal(t, "42", audits[0].ResourceID)
+	assert.Equal(t, http.StatusOK, audits[0].Status)
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+)
+
+// limitedBuffer keeps only the first limit bytes written, writes never fail.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// BodyLogger logs request and response bodies with pubkeys, ARNs and credentials redacted.
+// Bodies are truncated to the LOGGING_BODY_LIMIT setting. It is meant for debugging in
+// development and ephemeral environments and must be used after LoggerMiddleware.
+func BodyLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := config.Logging.BodyLimit
+		logger := zerolog.Ctx(r.Context())
+
+		var request []byte
+		if r.Body != nil && r.Body != http.NoBody {
+			var err error
+			request, err = io.ReadAll(io.LimitReader(r.Body, int64(limit)))
+			if err != nil {
+				logger.Warn().Err(err).Msg("Unable to read request body for logging")
+			}
+			// the handler reads the whole body, the logged part first
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(request), r.Body), r.Body}
+		}
+
+		response := &limitedBuffer{limit: limit}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(response)
+		next.ServeHTTP(ww, r)
+
+		logger.Info().
+			Bytes("request_body", logging.RedactBody(request)).
+			Bytes("response_body", logging.RedactBody(response.Bytes())).
+			Int("status", ww.Status()).
+			Msgf("Bodies of %s request %s", r.Method, r.URL.Path)
+	})
+}
@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLogger(t *testing.T) {
+	config.Logging.BodyLimit = 1024
+	t.Cleanup(func() { config.Logging.BodyLimit = 0 })
+
+	// bodies are logged at info level, the global level of tests is not configured
+	globalLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(globalLevel) })
+
+	var logged bytes.Buffer
+	logger := zerolog.New(&logged).Level(zerolog.InfoLevel)
+
+	request := `{"name":"key","body":"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEhnn80ZywmjeBFFOGm+cm+5HUwm62qTVnjKlOdYFLHN"}`
+	req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), "POST", "/pubkeys", strings.NewReader(request))
+	require.NoError(t, err)
+
+	handler := middleware.BodyLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, request, string(body), "handler must read the whole body")
+		_, _ = w.Write([]byte(`{"arn":"arn:aws:iam::123456789012:role/my-role"}`))
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Contains(t, rr.Body.String(), "arn:aws:iam::123456789012:role/my-role", "response must not be redacted")
+	require.Contains(t, logged.String(), "Bodies of POST request /pubkeys")
+	assert.NotContains(t, logged.String(), "AAAAC3NzaC1lZDI1NTE5")
+	assert.NotContains(t, logged.String(), "123456789012")
+	assert.Contains(t, logged.String(), "[REDACTED]")
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/rs/zerolog"
+)
+
+// EnforceAdmin allows only identities of organizations listed in the ADMIN_ORGS setting, it
+// must be used after EnforceIdentity.
+func EnforceAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID := identity.Identity(r.Context()).Identity.OrgID
+		if !config.IsAdminOrg(orgID) {
+			zerolog.Ctx(r.Context()).Warn().Str("org_id", orgID).Msg("Organization not allowed to access admin API")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
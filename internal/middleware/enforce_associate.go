@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/rs/zerolog"
+)
+
+// EnforceAssociate allows only Red Hat associate identities with at least one role listed in
+// the ADMIN_ROLES setting, it must be used after EnforceIdentity.
+func EnforceAssociate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := identity.Identity(r.Context()).Identity
+		if id.Type != string(identity.AssociatePrincipal) || id.Associate.Email == "" {
+			zerolog.Ctx(r.Context()).Warn().Str("type", id.Type).Msg("Identity not allowed to access internal admin API")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if !config.IsAdminRole(id.Associate.Role) {
+			zerolog.Ctx(r.Context()).Warn().Str("email", id.Associate.Email).Msg("Associate not allowed to access internal admin API")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
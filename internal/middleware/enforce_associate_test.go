@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const associateIdentity = `{"identity":{"type":"Associate","auth_type":"saml-auth","associate":{"email":"jdoe@redhat.com","Role":["provisioning-support"]}}}`
+
+func serveAssociate(t *testing.T, header string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest("GET", "/internal/admin/test", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Rh-Identity", base64.StdEncoding.EncodeToString([]byte(header)))
+
+	rr := httptest.NewRecorder()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	middleware.EnforceIdentity(middleware.EnforceAssociate(next)).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestEnforceAssociate(t *testing.T) {
+	config.Application.AdminRoles = []string{"provisioning-support"}
+	t.Cleanup(func() { config.Application.AdminRoles = nil })
+
+	t.Run("associate with admin role", func(t *testing.T) {
+		rr := serveAssociate(t, associateIdentity)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("associate without admin role", func(t *testing.T) {
+		rr := serveAssociate(t, `{"identity":{"type":"Associate","auth_type":"saml-auth","associate":{"email":"jdoe@redhat.com","Role":["other"]}}}`)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("customer user", func(t *testing.T) {
+		rr := serveAssociate(t, userIdentity)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("no roles configured", func(t *testing.T) {
+		config.Application.AdminRoles = nil
+		rr := serveAssociate(t, associateIdentity)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"net/http"
 
+	pbIdentity "github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/redhatinsights/platform-go-middlewares/identity"
 	"github.com/rs/zerolog"
 )
@@ -60,7 +61,15 @@ func EnforceIdentity(next http.Handler) http.Handler {
 			return
 		}
 
+		// systems (cert-auth) and service accounts carry details of the principal
+		machine, err := pbIdentity.ParseMachinePrincipal(idRaw)
+		if err != nil {
+			doError(r.Context(), w, 400, err.Error())
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), identity.Key, jsonData)
+		ctx = pbIdentity.WithMachine(ctx, machine)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+// EnforcePermission allows only principals granted the permission by the RBAC service, it must
+// be used after EnforceIdentity. All permissions are granted when RBAC is disabled via the
+// REST_ENDPOINTS_RBAC_ENABLED setting.
+func EnforcePermission(permission clients.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !config.RBAC.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			client, err := clients.GetRBACClient(ctx)
+			if err != nil {
+				_ = render.Render(w, r, payloads.NewClientError(ctx, err))
+				return
+			}
+
+			access, err := client.GetPrincipalAccess(ctx)
+			if err != nil {
+				_ = render.Render(w, r, payloads.NewClientError(ctx, err))
+				return
+			}
+
+			if !access.Allows(permission) {
+				zerolog.Ctx(ctx).Debug().Strs("permissions", access.Permissions).Msgf("Principal is missing permission %s", permission)
+				_ = render.Render(w, r, payloads.NewMissingPermissionError(ctx, permission.String()))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
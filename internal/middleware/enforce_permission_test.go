@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func servePermission(t *testing.T, ctx context.Context, permission clients.Permission) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "POST", "/test", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	middleware.EnforcePermission(permission)(next).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestEnforcePermission(t *testing.T) {
+	config.RBAC.Enabled = true
+	t.Cleanup(func() { config.RBAC.Enabled = false })
+
+	t.Run("granted permission", func(t *testing.T) {
+		ctx := identity.WithIdentity(t, context.Background())
+		ctx = clientStubs.WithRBACClient(ctx, "provisioning:reservation:write")
+
+		rr := servePermission(t, ctx, clients.ReservationWritePermission)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("wildcard permission", func(t *testing.T) {
+		ctx := identity.WithIdentity(t, context.Background())
+		ctx = clientStubs.WithRBACClient(ctx, "provisioning:*:*")
+
+		rr := servePermission(t, ctx, clients.PubkeyWritePermission)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("missing permission", func(t *testing.T) {
+		ctx := identity.WithIdentity(t, context.Background())
+		ctx = clientStubs.WithRBACClient(ctx, "provisioning:reservation:read", "provisioning:pubkey:*")
+
+		rr := servePermission(t, ctx, clients.ReservationWritePermission)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+		assert.Equal(t, "provisioning:reservation:write", body["missing_permission"])
+	})
+
+	t.Run("disabled RBAC", func(t *testing.T) {
+		config.RBAC.Enabled = false
+		t.Cleanup(func() { config.RBAC.Enabled = true })
+		ctx := identity.WithIdentity(t, context.Background())
+
+		rr := servePermission(t, ctx, clients.AdminReadPermission)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"hash/crc64"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -30,7 +31,10 @@ type ETag struct {
 
 type ETagValueFunc func() *ETag
 
-var etags = make([]*ETag, 0)
+var (
+	etags   = make([]*ETag, 0)
+	etagsMu sync.RWMutex
+)
 
 func (etag *ETag) Header() string {
 	return fmt.Sprintf("\"pb-%s-%s\"", etag.Name, etag.Value)
@@ -68,6 +72,34 @@ func ETagMiddleware(etagFunc ETagValueFunc) func(next http.Handler) http.Handler
 
 // GenerateETagFromBuffer calculates etag value from one or more buffers (e.g. embedded files)
 func GenerateETagFromBuffer(name string, expiration time.Duration, buffers ...[]byte) (*ETag, error) {
+	etag, err := calculateETag(name, expiration, buffers...)
+	if err != nil {
+		return nil, err
+	}
+	etagsMu.Lock()
+	defer etagsMu.Unlock()
+	etags = append(etags, etag)
+	return etag, nil
+}
+
+// RegenerateETagFromBuffer calculates new etag value for an existing etag when the source data changes.
+// The old etag is replaced in the list of all etags and the new one is returned.
+func RegenerateETagFromBuffer(old *ETag, buffers ...[]byte) (*ETag, error) {
+	etag, err := calculateETag(old.Name, old.Expiration, buffers...)
+	if err != nil {
+		return nil, err
+	}
+	etagsMu.Lock()
+	defer etagsMu.Unlock()
+	for i := range etags {
+		if etags[i] == old {
+			etags[i] = etag
+		}
+	}
+	return etag, nil
+}
+
+func calculateETag(name string, expiration time.Duration, buffers ...[]byte) (*ETag, error) {
 	start := time.Now()
 	hash := crc64.New(crc64.MakeTable(crc64.ECMA))
 	for _, buffer := range buffers {
@@ -82,11 +114,14 @@ func GenerateETagFromBuffer(name string, expiration time.Duration, buffers ...[]
 		Value:      fmt.Sprintf("%x", hash.Sum64()),
 		HashTime:   time.Since(start),
 	}
-	etags = append(etags, etag)
 	return etag, nil
 }
 
-// AllETags returns all ETags for diagnostic purposes
+// AllETags returns a copy of all ETags for diagnostic purposes
 func AllETags() []*ETag {
-	return etags
+	etagsMu.RLock()
+	defer etagsMu.RUnlock()
+	result := make([]*ETag, len(etags))
+	copy(result, etags)
+	return result
 }
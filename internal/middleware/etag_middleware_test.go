@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -41,3 +42,25 @@ func TestGenerateETagFromTwoBuffers(t *testing.T) {
 	etag, _ := GenerateETagFromBuffer("test", time.Minute*1, b1, b2)
 	assert.Equal(t, "2cd8094a1a277627", etag.Value)
 }
+
+func TestRegenerateETagConcurrently(t *testing.T) {
+	etag, _ := GenerateETagFromBuffer("regenerate", time.Minute*1, []byte("a"))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			etag, _ = RegenerateETagFromBuffer(etag, []byte{byte(i)})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = AllETags()
+		}
+	}()
+	wg.Wait()
+
+	assert.Contains(t, AllETags(), etag)
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+var ErrPrincipalNotAllowed = errors.New("principal not allowed")
+
+// PrincipalPolicy is a hook deciding whether a system or a service account may call the route,
+// it returns an error wrapping ErrPrincipalNotAllowed when the call is not allowed.
+type PrincipalPolicy func(r *http.Request, principal *identity.MachinePrincipal) error
+
+// EnforcePrincipalPolicy evaluates policies for systems and service accounts, requests are
+// refused when any of the policies fails. Users are not affected. It must be used after
+// EnforceIdentity.
+func EnforcePrincipalPolicy(policies ...PrincipalPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := identity.Machine(r.Context())
+			if principal == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, policy := range policies {
+				if err := policy(r, principal); err != nil {
+					_ = render.Render(w, r, payloads.NewPrincipalNotAllowedError(r.Context(), string(principal.Type), err))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AllowConfiguredPrincipals allows principal types listed in the APP_MACHINE_PRINCIPALS setting.
+func AllowConfiguredPrincipals(_ *http.Request, principal *identity.MachinePrincipal) error {
+	if !config.IsMachinePrincipalAllowed(string(principal.Type)) {
+		return fmt.Errorf("%w: %s principals are disabled", ErrPrincipalNotAllowed, principal.Type)
+	}
+	return nil
+}
+
+// DenyPrincipals refuses all systems and service accounts, the route is for users only.
+func DenyPrincipals(r *http.Request, _ *identity.MachinePrincipal) error {
+	return fmt.Errorf("%w: %s %s is only available to users", ErrPrincipalNotAllowed, r.Method, r.URL.Path)
+}
+
+// ReadOnlyPrincipals allows principals of given types to call only GET and HEAD methods.
+func ReadOnlyPrincipals(types ...identity.PrincipalType) PrincipalPolicy {
+	return func(r *http.Request, principal *identity.MachinePrincipal) error {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return nil
+		}
+		for _, t := range types {
+			if principal.Type == t {
+				return fmt.Errorf("%w: %s principals are read-only", ErrPrincipalNotAllowed, principal.Type)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	userIdentity           = `{"identity":{"type":"User","org_id":"1","user":{"username":"jdoe"}}}`
+	systemIdentity         = `{"identity":{"type":"System","org_id":"1","auth_type":"cert-auth","system":{"cn":"c87dcb4c-8af1-40dd-878e-60c744edddd0","cert_type":"system"}}}`
+	serviceAccountIdentity = `{"identity":{"type":"ServiceAccount","org_id":"1","auth_type":"jwt-auth","service_account":{"client_id":"b69eaf9e-e6a6-4f9e-805e-02987daddfbd","username":"service-account-automation"}}}`
+)
+
+func servePrincipal(t *testing.T, method, header string, policies ...middleware.PrincipalPolicy) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, "/test", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Rh-Identity", base64.StdEncoding.EncodeToString([]byte(header)))
+
+	var name string
+	rr := httptest.NewRecorder()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name = identity.PrincipalName(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	middleware.EnforceIdentity(middleware.EnforcePrincipalPolicy(policies...)(next)).ServeHTTP(rr, req)
+	return rr, name
+}
+
+func TestEnforcePrincipalPolicy(t *testing.T) {
+	config.Application.MachinePrincipals = []string{"System", "ServiceAccount"}
+	t.Cleanup(func() { config.Application.MachinePrincipals = nil })
+	readOnlySystems := middleware.ReadOnlyPrincipals(identity.SystemPrincipal)
+
+	t.Run("user is not affected", func(t *testing.T) {
+		rr, name := servePrincipal(t, "POST", userIdentity, middleware.DenyPrincipals)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, "jdoe", name)
+	})
+
+	t.Run("service account", func(t *testing.T) {
+		rr, name := servePrincipal(t, "POST", serviceAccountIdentity, middleware.AllowConfiguredPrincipals, readOnlySystems)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, "service-account-automation", name)
+	})
+
+	t.Run("read-only system", func(t *testing.T) {
+		rr, name := servePrincipal(t, "GET", systemIdentity, middleware.AllowConfiguredPrincipals, readOnlySystems)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, "c87dcb4c-8af1-40dd-878e-60c744edddd0", name)
+
+		rr, _ = servePrincipal(t, "POST", systemIdentity, middleware.AllowConfiguredPrincipals, readOnlySystems)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("users only", func(t *testing.T) {
+		rr, _ := servePrincipal(t, "GET", serviceAccountIdentity, middleware.DenyPrincipals)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("disabled principal type", func(t *testing.T) {
+		config.Application.MachinePrincipals = []string{"System"}
+		t.Cleanup(func() { config.Application.MachinePrincipals = []string{"System", "ServiceAccount"} })
+
+		rr, _ := servePrincipal(t, "GET", serviceAccountIdentity, middleware.AllowConfiguredPrincipals)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("service account without client id", func(t *testing.T) {
+		rr, _ := servePrincipal(t, "GET", `{"identity":{"type":"ServiceAccount","org_id":"1"}}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
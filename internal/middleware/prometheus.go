@@ -16,16 +16,34 @@ import (
 
 var buckets = []float64{100, 200, 500, 750, 1000, 2000, 5000}
 
+// durationBuckets are in seconds, they cover both fast reads and slow launch requests
+var durationBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// sizeBuckets are in bytes, from empty bodies to large list responses
+var sizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+
 const (
 	metricNameHttpRequestTotal    = "provisioning_http_request_total"
 	metricNameHttpRequestDuration = "provisioning_http_request_duration_ms"
+
+	metricNameHttpRouteDuration     = "provisioning_http_route_duration_seconds"
+	metricNameHttpRouteRequestSize  = "provisioning_http_route_request_size_bytes"
+	metricNameHttpRouteResponseSize = "provisioning_http_route_response_size_bytes"
 )
 
+// routeLabels are labels of the per-endpoint histograms. Only the status class (e.g. 2xx)
+// is used so the cardinality is bounded by the number of routes.
+var routeLabels = []string{"method", "route", "status_class"}
+
 // Middleware is a handler that exposes prometheus metrics for the number of requests,
 // the latency and the response size, partitioned by status code, method and HTTP path.
 type Middleware struct {
 	reqs    *prometheus.CounterVec
 	latency *prometheus.HistogramVec
+
+	duration     *prometheus.HistogramVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
 }
 
 // NewPatternMiddleware returns a new prometheus Middleware handler that groups requests by the chi routing pattern.
@@ -51,9 +69,51 @@ func NewPatternMiddleware(name string) func(next http.Handler) http.Handler {
 		[]string{"code", "status_code", "method", "path"},
 	)
 	prometheus.MustRegister(m.latency)
+
+	m.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        metricNameHttpRouteDuration,
+		Help:        "Request duration in seconds partitioned by method, chi route pattern and status class",
+		ConstLabels: prometheus.Labels{"service": name},
+		Buckets:     durationBuckets,
+	}, routeLabels)
+	prometheus.MustRegister(m.duration)
+
+	m.requestSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        metricNameHttpRouteRequestSize,
+		Help:        "Request body size in bytes partitioned by method, chi route pattern and status class",
+		ConstLabels: prometheus.Labels{"service": name},
+		Buckets:     sizeBuckets,
+	}, routeLabels)
+	prometheus.MustRegister(m.requestSize)
+
+	m.responseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        metricNameHttpRouteResponseSize,
+		Help:        "Response body size in bytes partitioned by method, chi route pattern and status class",
+		ConstLabels: prometheus.Labels{"service": name},
+		Buckets:     sizeBuckets,
+	}, routeLabels)
+	prometheus.MustRegister(m.responseSize)
+
 	return m.patternHandler
 }
 
+// statusClass returns the class of the status code (e.g. "2xx"), handlers which do not write
+// the header respond with 200.
+func statusClass(status int) string {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// requestSize returns the request body size, zero when unknown.
+func requestSize(r *http.Request) int64 {
+	if r.ContentLength < 0 {
+		return 0
+	}
+	return r.ContentLength
+}
+
 func (c Middleware) patternHandler(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -66,6 +126,11 @@ func (c Middleware) patternHandler(next http.Handler) http.Handler {
 
 		c.reqs.WithLabelValues(strconv.Itoa(ww.Status()), http.StatusText(ww.Status()), r.Method, routePattern).Inc()
 		c.latency.WithLabelValues(strconv.Itoa(ww.Status()), http.StatusText(ww.Status()), r.Method, routePattern).Observe(float64(time.Since(start).Nanoseconds()) / 1000000)
+
+		class := statusClass(ww.Status())
+		c.duration.WithLabelValues(r.Method, routePattern, class).Observe(time.Since(start).Seconds())
+		c.requestSize.WithLabelValues(r.Method, routePattern, class).Observe(float64(requestSize(r)))
+		c.responseSize.WithLabelValues(r.Method, routePattern, class).Observe(float64(ww.BytesWritten()))
 	}
 	return http.HandlerFunc(fn)
 }
@@ -10,6 +10,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_PatternLogger(t *testing.T) {
@@ -80,3 +82,40 @@ func Test_PatternLogger(t *testing.T) {
 		t.Errorf("body does not contain first name pattern count summary '%s'", firstNamePatternCount)
 	}
 }
+
+func Test_RouteHistograms(t *testing.T) {
+	ctx := context.Background()
+
+	n := chi.NewRouter()
+	n.Use(NewPatternMiddleware("routeHistogramTest"))
+	n.Handle("/metrics", promhttp.Handler())
+	n.Post(`/users/{firstName}`, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "missing")
+	})
+
+	for _, name := range []string{"JoeBob", "Misty"} {
+		req, err := http.NewRequestWithContext(ctx, "POST", "http://localhost:3000/users/"+name, strings.NewReader("hello"))
+		require.NoError(t, err)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://localhost:3000/metrics", nil)
+	require.NoError(t, err)
+	n.ServeHTTP(recorder, req)
+	body := recorder.Body.String()
+
+	labels := `{method="POST",route="/users/{firstName}",service="routeHistogramTest",status_class="4xx"}`
+	assert.Contains(t, body, metricNameHttpRouteDuration+"_count"+labels+" 2")
+	assert.Contains(t, body, metricNameHttpRouteRequestSize+"_sum"+labels+" 10")
+	assert.Contains(t, body, metricNameHttpRouteResponseSize+"_sum"+labels+" 14")
+	assert.NotContains(t, body, "JoeBob")
+}
+
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, "2xx", statusClass(0))
+	assert.Equal(t, "2xx", statusClass(http.StatusNoContent))
+	assert.Equal(t, "4xx", statusClass(http.StatusNotFound))
+	assert.Equal(t, "5xx", statusClass(http.StatusBadGateway))
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/ratelimit"
+	"github.com/go-chi/render"
+)
+
+// RateLimit limits requests of the organization, GET and HEAD requests are counted as reads
+// and other requests as writes. It must be used after EnforceIdentity.
+func RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := ratelimit.Write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			class = ratelimit.Read
+		}
+
+		if limitRequest(w, r, class) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// RateLimitClass additionally limits requests of the class, it must be used after RateLimit.
+func RateLimitClass(class ratelimit.Class) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limitRequest(w, r, class) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// limitRequest returns true when the request is allowed, otherwise 429 with Retry-After
+// header is rendered.
+func limitRequest(w http.ResponseWriter, r *http.Request, class ratelimit.Class) bool {
+	orgID := identity.OrgIdOrEmpty(r.Context())
+	if !config.RateLimit.Enabled || orgID == "" {
+		return true
+	}
+
+	allowed, wait := ratelimit.Allow(r.Context(), orgID, class)
+	if allowed {
+		return true
+	}
+
+	metrics.IncRateLimitRejected(string(class))
+	w.Header().Set("Retry-After", strconv.Itoa(ratelimit.RetryAfter(wait)))
+	_ = render.Render(w, r, payloads.NewRateLimitError(r.Context(), string(class)))
+	return false
+}
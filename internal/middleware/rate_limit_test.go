@@ -0,0 +1,42 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	config.RateLimit.Enabled = true
+	config.RateLimit.ReadPerMinute = 0
+	config.RateLimit.WritePerMinute = 1
+	config.RateLimit.WriteBurst = 1
+	t.Cleanup(func() { config.RateLimit.Enabled = false })
+
+	ctx := identity.WithCustomIdentity(t, context.Background(), "rate-limited", nil)
+	handler := middleware.RateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(method string) *httptest.ResponseRecorder {
+		req, err := http.NewRequestWithContext(ctx, method, "/test", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve("POST").Code)
+
+	rr := serve("POST")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusNoContent, serve("GET").Code, "reads are not limited")
+}
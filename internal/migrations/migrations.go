@@ -105,14 +105,17 @@ var (
 	ErrNoMigrationsFound = errors.New("no migrations found")
 	ErrMigration         = errors.New("unable to perform migration")
 	ErrSeedProduction    = errors.New("seed in production")
+	ErrUnknownVersion    = errors.New("unknown migration version")
+
+	ErrIrreversibleMigration = errors.New("migration cannot be reverted")
 )
 
-// Migrate executes embedded SQL scripts from internal/db/migrations. For the time being
-// only "up" migrations are supported. When this package is initialized, the directory
-// is verified that it only contains XXX_*.up.sql files (XXX = numbers).
-func Migrate(ctx context.Context, schema string) error {
-	logger := log.Logger.With().Bool("migration", true).Logger()
-	logger.Debug().Msgf("Started migration")
+// LatestVersion is the target version of Migrate which applies all embedded migrations.
+const LatestVersion int32 = -1
+
+// withMigrator calls the function with a migrator of the schema which has all embedded
+// migrations loaded.
+func withMigrator(ctx context.Context, schema string, fn func(migrator *migrate.Migrator) error) error {
 	if schema == "" {
 		schema = "public"
 	}
@@ -137,27 +140,61 @@ func Migrate(ctx context.Context, schema string) error {
 		return ErrNoMigrationsFound
 	}
 
-	migrator.OnStart = func(sequence int32, name, direction, sql string) {
-		logger.Info().Str("sql", sql).Msgf("Executing migration %s %s", name, direction)
-		if HasCallback(sequence) {
-			logger.Info().Msgf("Migration callback for %s %s", name, direction)
-			callErr := CallCallback(ctx, sequence)
-			if callErr != nil {
-				logger.Error().Err(callErr).Str("script", name).Msg("Error during execution of callback script")
-				panic(callErr)
+	return fn(migrator)
+}
+
+// Migrate executes all embedded SQL scripts from internal/db/migrations which were not
+// applied yet. When this package is initialized, the directory is verified that it only
+// contains XXX_*.sql files (XXX = numbers).
+func Migrate(ctx context.Context, schema string) error {
+	return MigrateTo(ctx, schema, LatestVersion)
+}
+
+// MigrateTo migrates the schema up or down to the target version (LatestVersion for all
+// migrations, 0 for none). Down migrations are only possible for scripts with the drop
+// section separated by "---- create above / drop below ----" line. Callbacks are only
+// executed during up migrations.
+func MigrateTo(ctx context.Context, schema string, target int32) error {
+	logger := log.Logger.With().Bool("migration", true).Logger()
+	logger.Debug().Msgf("Started migration")
+	// migrations and callbacks work with data of all accounts
+	ctx = db.WithUnscoped(ctx)
+
+	err := withMigrator(ctx, schema, func(migrator *migrate.Migrator) error {
+		migrator.OnStart = func(sequence int32, name, direction, sql string) {
+			logger.Info().Str("sql", sql).Msgf("Executing migration %s %s", name, direction)
+			if direction == "up" && HasCallback(sequence) {
+				logger.Info().Msgf("Migration callback for %s %s", name, direction)
+				callErr := CallCallback(ctx, sequence)
+				if callErr != nil {
+					logger.Error().Err(callErr).Str("script", name).Msg("Error during execution of callback script")
+					panic(callErr)
+				}
 			}
 		}
-	}
 
-	err = migrator.Migrate(ctx)
-	if err != nil {
-		var mgErr *migrate.MigrationPgError
-		var pgErr *pgconn.PgError
-		if errors.As(err, &mgErr) && errors.As(err, &pgErr) {
-			return fmt.Errorf("%w: %s", ErrMigration, fmtDetailedError(mgErr.Sql, pgErr))
+		var migrateErr error
+		if target == LatestVersion {
+			migrateErr = migrator.Migrate(ctx)
 		} else {
-			return fmt.Errorf("unable to perform migration: %w", err)
+			migrateErr = migrator.MigrateTo(ctx, target)
+		}
+		if migrateErr != nil {
+			var mgErr *migrate.MigrationPgError
+			var pgErr *pgconn.PgError
+			var irErr migrate.IrreversibleMigrationError
+			if errors.As(migrateErr, &mgErr) && errors.As(migrateErr, &pgErr) {
+				return fmt.Errorf("%w: %s", ErrMigration, fmtDetailedError(mgErr.Sql, pgErr))
+			} else if errors.As(migrateErr, &irErr) {
+				return fmt.Errorf("%w: %s", ErrIrreversibleMigration, irErr.Error())
+			} else {
+				return fmt.Errorf("unable to perform migration: %w", migrateErr)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Print some additional info
@@ -201,7 +238,7 @@ func Seed(ctx context.Context, seedScript string) error {
 	if err != nil {
 		return fmt.Errorf("unable to read seed script %s: %w", seedScript, err)
 	}
-	_, err = db.Pool.Exec(ctx, string(buffer))
+	_, err = db.Pool.Exec(db.WithUnscoped(ctx), string(buffer))
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
@@ -0,0 +1,14 @@
+--
+-- Instance type availability per region and zone refreshed periodically by a background job from live
+-- provider APIs. When a provider has no records, embedded (generate-time) availability data is used instead.
+--
+CREATE TABLE instance_type_availability
+(
+  provider INTEGER NOT NULL CHECK (valid_provider(provider)),
+  region TEXT NOT NULL CHECK (NOT empty(region)),
+  zone TEXT NOT NULL DEFAULT '',
+  instance_types TEXT[] NOT NULL DEFAULT '{}',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (provider, region, zone)
+);
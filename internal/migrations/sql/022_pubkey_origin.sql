@@ -0,0 +1,8 @@
+--
+-- Provenance of imported pubkeys: site the key was imported from (e.g. "github"), username
+-- on that site and time of the import. Keys created via the API have blank origin.
+--
+ALTER TABLE pubkeys
+  ADD COLUMN origin TEXT NOT NULL DEFAULT '',
+  ADD COLUMN origin_username TEXT NOT NULL DEFAULT '',
+  ADD COLUMN imported_at TIMESTAMPTZ;
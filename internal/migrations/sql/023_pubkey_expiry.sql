@@ -0,0 +1,9 @@
+--
+-- Optional pubkey expiration. Expired pubkeys can be blocked from new reservations (configurable per
+-- organization), notification is sent before expiry and expiry_notified_at prevents sending it twice.
+--
+ALTER TABLE pubkeys
+  ADD COLUMN expires_at TIMESTAMPTZ,
+  ADD COLUMN expiry_notified_at TIMESTAMPTZ;
+
+CREATE INDEX pubkeys_expires_at ON pubkeys(expires_at) WHERE expires_at IS NOT NULL AND expiry_notified_at IS NULL;
@@ -0,0 +1,21 @@
+--
+-- Reservation templates are named, saved launch configurations. New reservations can reference
+-- a template and override individual fields.
+--
+CREATE TABLE reservation_templates
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  account_id BIGINT NOT NULL REFERENCES accounts(id),
+  name TEXT NOT NULL CHECK (NOT empty(name)),
+  provider INTEGER NOT NULL CHECK (valid_provider(provider)),
+  source_id TEXT NOT NULL DEFAULT '',
+  image_id TEXT NOT NULL DEFAULT '',
+  instance_type TEXT NOT NULL DEFAULT '',
+  region TEXT NOT NULL DEFAULT '',
+  pubkey_id BIGINT REFERENCES pubkeys(id) ON DELETE SET NULL,
+  tags JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  UNIQUE(name, account_id)
+);
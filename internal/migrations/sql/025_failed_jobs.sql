@@ -0,0 +1,20 @@
+--
+-- Dead-letter table for background jobs which failed on the last attempt. The payload column
+-- contains the encoded job which can be enqueued again, arguments are stored as JSON for
+-- display only. Account ID is zero for jobs not associated with an account.
+--
+CREATE TABLE failed_jobs
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  job_id TEXT NOT NULL,
+  job_type TEXT NOT NULL CHECK (NOT empty(job_type)),
+  account_id BIGINT NOT NULL DEFAULT 0,
+  args JSONB NOT NULL DEFAULT '{}',
+  payload BYTEA NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  attempts INTEGER NOT NULL DEFAULT 1,
+  failed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  replayed_at TIMESTAMPTZ
+);
+
+CREATE INDEX failed_jobs_failed_at ON failed_jobs(failed_at);
@@ -0,0 +1,6 @@
+--
+-- Users can request cancellation of a running reservation, launch jobs check the flag before
+-- each step and periodically during long cloud calls.
+--
+ALTER TABLE reservations
+  ADD COLUMN cancel_requested BOOLEAN NOT NULL DEFAULT FALSE;
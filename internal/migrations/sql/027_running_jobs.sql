@@ -0,0 +1,16 @@
+--
+-- Jobs currently processed by workers. Workers update heartbeat_at periodically and delete
+-- the row when the job is done, rows without recent heartbeat belong to workers which died
+-- mid-execution and are enqueued again from the payload column.
+--
+CREATE TABLE running_jobs
+(
+  job_id TEXT PRIMARY KEY,
+  job_type TEXT NOT NULL CHECK (NOT empty(job_type)),
+  worker TEXT NOT NULL DEFAULT '',
+  payload BYTEA NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX running_jobs_heartbeat_at ON running_jobs(heartbeat_at);
@@ -0,0 +1,22 @@
+--
+-- Audit log of background job executions, one record per attempt. Arguments may contain
+-- secrets so only their hash is stored. Account and reservation IDs are zero for jobs not
+-- associated with an account or a reservation.
+--
+CREATE TABLE job_audit
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  job_id TEXT NOT NULL,
+  job_type TEXT NOT NULL CHECK (NOT empty(job_type)),
+  account_id BIGINT NOT NULL DEFAULT 0,
+  reservation_id BIGINT NOT NULL DEFAULT 0,
+  args_hash TEXT NOT NULL DEFAULT '',
+  attempt INTEGER NOT NULL DEFAULT 1,
+  outcome TEXT NOT NULL CHECK (NOT empty(outcome)),
+  error TEXT NOT NULL DEFAULT '',
+  started_at TIMESTAMPTZ NOT NULL,
+  duration_ms BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX job_audit_account_id ON job_audit(account_id, started_at);
+CREATE INDEX job_audit_reservation_id ON job_audit(reservation_id) WHERE reservation_id <> 0;
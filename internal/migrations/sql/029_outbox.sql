@@ -0,0 +1,19 @@
+--
+-- Messages waiting to be published to Kafka. Messages are written in the same transaction
+-- as the change they describe and deleted by the relay once published, failed attempts
+-- are retried with exponential backoff.
+--
+CREATE TABLE outbox
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  topic TEXT NOT NULL CHECK (NOT empty(topic)),
+  key BYTEA,
+  value BYTEA NOT NULL,
+  headers JSONB NOT NULL DEFAULT '[]',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX outbox_next_attempt_at ON outbox(next_attempt_at);
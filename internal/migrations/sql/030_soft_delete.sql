@@ -0,0 +1,40 @@
+--
+-- Pubkeys and reservations deleted by users are only marked as deleted so they can be restored,
+-- records deleted before the retention period are purged by a scheduled job. Names and
+-- fingerprints of deleted pubkeys can be reused.
+--
+ALTER TABLE pubkeys
+  ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE pubkeys
+  DROP CONSTRAINT pubkeys_name_account_id_key,
+  DROP CONSTRAINT pubkeys_fingerprint_account_id_key;
+
+CREATE UNIQUE INDEX pubkeys_name_account_id_key ON pubkeys(name, account_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX pubkeys_fingerprint_account_id_key ON pubkeys(fingerprint, account_id) WHERE deleted_at IS NULL;
+CREATE INDEX pubkeys_deleted_at ON pubkeys(deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE reservations
+  ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX reservations_deleted_at ON reservations(deleted_at) WHERE deleted_at IS NOT NULL;
+
+---- create above / drop below ----
+
+DELETE FROM reservations WHERE deleted_at IS NOT NULL;
+DELETE FROM pubkeys WHERE deleted_at IS NOT NULL;
+
+DROP INDEX reservations_deleted_at;
+ALTER TABLE reservations
+  DROP COLUMN deleted_at;
+
+DROP INDEX pubkeys_deleted_at;
+DROP INDEX pubkeys_name_account_id_key;
+DROP INDEX pubkeys_fingerprint_account_id_key;
+
+ALTER TABLE pubkeys
+  ADD CONSTRAINT pubkeys_name_account_id_key UNIQUE (name, account_id),
+  ADD CONSTRAINT pubkeys_fingerprint_account_id_key UNIQUE (fingerprint, account_id);
+
+ALTER TABLE pubkeys
+  DROP COLUMN deleted_at;
@@ -0,0 +1,31 @@
+--
+-- Finished reservations older than the archive age are moved out of reservations and their
+-- detail tables by a scheduled job. Provider details and instances are stored as JSON documents
+-- so archived records do not depend on the detail tables.
+--
+CREATE TABLE reservations_archive
+(
+  id BIGINT PRIMARY KEY,
+  provider INTEGER NOT NULL,
+  account_id BIGINT NOT NULL REFERENCES accounts(id),
+  created_at TIMESTAMP NOT NULL,
+  steps SMALLINT NOT NULL,
+  step_titles TEXT[],
+  step SMALLINT NOT NULL,
+  status TEXT NOT NULL,
+  error TEXT NOT NULL,
+  finished_at TIMESTAMP NOT NULL,
+  success BOOLEAN,
+  cancel_requested BOOLEAN NOT NULL,
+  detail JSONB NOT NULL,
+  instances JSONB NOT NULL,
+  archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX reservations_archive_account_id ON reservations_archive(account_id, id);
+CREATE INDEX reservations_finished_at ON reservations(finished_at) WHERE finished_at IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX reservations_finished_at;
+DROP TABLE reservations_archive;
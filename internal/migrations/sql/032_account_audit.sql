@@ -0,0 +1,25 @@
+--
+-- Audit of account merges and renames performed by operators when organization IDs of tenants
+-- change. Merges move all records of the source account to the target account, renames change
+-- the organization ID of an account. Counts are numbers of moved records.
+--
+CREATE TABLE account_audit
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  operation TEXT NOT NULL CHECK (operation IN ('merge', 'rename')),
+  account_id BIGINT NOT NULL REFERENCES accounts(id),
+  source_account_id BIGINT NOT NULL DEFAULT 0,
+  old_org_id TEXT NOT NULL DEFAULT '',
+  new_org_id TEXT NOT NULL DEFAULT '',
+  pubkeys BIGINT NOT NULL DEFAULT 0,
+  reservations BIGINT NOT NULL DEFAULT 0,
+  reservation_templates BIGINT NOT NULL DEFAULT 0,
+  requested_by TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX account_audit_account_id ON account_audit(account_id, created_at);
+
+---- create above / drop below ----
+
+DROP TABLE account_audit;
@@ -0,0 +1,24 @@
+--
+-- Audit of mutating API calls (POST, PUT, PATCH and DELETE). Records who performed the call,
+-- on which route and resource, and with what response status. The log is accessed by operators
+-- via the admin API.
+--
+CREATE TABLE api_audit
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  account_id BIGINT NOT NULL REFERENCES accounts(id),
+  org_id TEXT NOT NULL DEFAULT '',
+  principal TEXT NOT NULL DEFAULT '',
+  method TEXT NOT NULL,
+  route TEXT NOT NULL,
+  resource_id TEXT NOT NULL DEFAULT '',
+  status INTEGER NOT NULL,
+  trace_id TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX api_audit_account_id ON api_audit(account_id, created_at);
+
+---- create above / drop below ----
+
+DROP TABLE api_audit;
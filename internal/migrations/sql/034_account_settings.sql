@@ -0,0 +1,18 @@
+--
+-- Account settings are defaults and restrictions set by organization admins. Defaults are applied
+-- to new reservations when the fields are omitted, an empty list of allowed providers allows all.
+--
+CREATE TABLE account_settings
+(
+  account_id BIGINT PRIMARY KEY REFERENCES accounts(id),
+  aws_region TEXT NOT NULL DEFAULT '',
+  azure_location TEXT NOT NULL DEFAULT '',
+  gcp_zone TEXT NOT NULL DEFAULT '',
+  pubkey_id BIGINT REFERENCES pubkeys(id) ON DELETE SET NULL,
+  allowed_providers TEXT[] NOT NULL DEFAULT '{}',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+---- create above / drop below ----
+
+DROP TABLE account_settings;
@@ -0,0 +1,17 @@
+--
+-- Launched instances are optionally probed on TCP port 22 after a successful launch, the result
+-- is stored into reservations. NULL means instances were not probed (yet).
+--
+ALTER TABLE reservations
+  ADD COLUMN ssh_ready BOOLEAN;
+
+ALTER TABLE reservations_archive
+  ADD COLUMN ssh_ready BOOLEAN;
+
+---- create above / drop below ----
+
+ALTER TABLE reservations_archive
+  DROP COLUMN ssh_ready;
+
+ALTER TABLE reservations
+  DROP COLUMN ssh_ready;
@@ -0,0 +1,18 @@
+--
+-- Cloud account identities resolved from source authentications (e.g. AWS account ID of the
+-- role ARN) are stored so providers are not asked again. The authentication is stored with the
+-- identity, records are replaced when the source authentication changes.
+--
+CREATE TABLE source_identities
+(
+  account_id BIGINT NOT NULL REFERENCES accounts(id),
+  source_id TEXT NOT NULL,
+  authentication TEXT NOT NULL,
+  provider_account_id TEXT NOT NULL,
+  resolved_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (account_id, source_id)
+);
+
+---- create above / drop below ----
+
+DROP TABLE source_identities;
@@ -0,0 +1,11 @@
+--
+-- Organization admins can cap estimated hourly spend of instances of running reservations,
+-- zero means no cap.
+--
+ALTER TABLE account_settings
+  ADD COLUMN max_hourly_spend DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+---- create above / drop below ----
+
+ALTER TABLE account_settings
+  DROP COLUMN max_hourly_spend;
@@ -0,0 +1,10 @@
+--
+-- Organization admins can require tags on all new reservations, e.g. a cost center.
+--
+ALTER TABLE account_settings
+  ADD COLUMN required_tags TEXT[] NOT NULL DEFAULT '{}';
+
+---- create above / drop below ----
+
+ALTER TABLE account_settings
+  DROP COLUMN required_tags;
@@ -0,0 +1,26 @@
+--
+-- Lifecycle actions (stop, start or reboot) requested on instances of reservations. Actions are
+-- performed by workers, the table serves as the status tracking and the action log.
+--
+CREATE TABLE instance_actions
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  account_id BIGINT NOT NULL REFERENCES accounts(id),
+  reservation_id BIGINT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+  instance_id TEXT NOT NULL CHECK (NOT empty(instance_id)),
+  action TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX instance_actions_reservation_id ON instance_actions(reservation_id, id);
+
+-- only one action per instance can be in progress
+CREATE UNIQUE INDEX instance_actions_unfinished ON instance_actions(reservation_id, instance_id)
+  WHERE status IN ('pending', 'running');
+
+---- create above / drop below ----
+
+DROP TABLE instance_actions;
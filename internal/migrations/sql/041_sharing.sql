@@ -0,0 +1,30 @@
+--
+-- Reservations and pubkeys are owned by the user who created them. Other users of the
+-- organization only see shared ones, read-only. Rows created before ownership was recorded
+-- and by non-user identities have an empty owner and belong to the whole organization.
+--
+ALTER TABLE reservations
+  ADD COLUMN owner TEXT NOT NULL DEFAULT '',
+  ADD COLUMN shared BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE reservations_archive
+  ADD COLUMN owner TEXT NOT NULL DEFAULT '',
+  ADD COLUMN shared BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE pubkeys
+  ADD COLUMN owner TEXT NOT NULL DEFAULT '',
+  ADD COLUMN shared BOOLEAN NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE pubkeys
+  DROP COLUMN shared,
+  DROP COLUMN owner;
+
+ALTER TABLE reservations_archive
+  DROP COLUMN shared,
+  DROP COLUMN owner;
+
+ALTER TABLE reservations
+  DROP COLUMN shared,
+  DROP COLUMN owner;
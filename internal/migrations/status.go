@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/tern/v2/migrate"
+)
+
+// MigrationStatus is an embedded migration and whether it was applied to the schema.
+type MigrationStatus struct {
+	Sequence   int32
+	Name       string
+	Applied    bool
+	Reversible bool
+}
+
+// PendingMigration is a migration which would be executed to reach the target version.
+type PendingMigration struct {
+	Sequence  int32
+	Name      string
+	Direction string
+	SQL       string
+}
+
+// Status returns the current schema version and the list of all embedded migrations.
+func Status(ctx context.Context, schema string) (int32, []MigrationStatus, error) {
+	var current int32
+	var result []MigrationStatus
+	err := withMigrator(ctx, schema, func(migrator *migrate.Migrator) error {
+		var err error
+		current, err = migrator.GetCurrentVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get current version: %w", err)
+		}
+
+		result = make([]MigrationStatus, 0, len(migrator.Migrations))
+		for _, m := range migrator.Migrations {
+			result = append(result, MigrationStatus{
+				Sequence:   m.Sequence,
+				Name:       m.Name,
+				Applied:    m.Sequence <= current,
+				Reversible: m.DownSQL != "",
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return current, result, nil
+}
+
+// Pending returns migrations with SQL in the order they would be executed to migrate
+// the schema to the target version. Nothing is executed, it is meant for dry runs.
+func Pending(ctx context.Context, schema string, target int32) ([]PendingMigration, error) {
+	var result []PendingMigration
+	err := withMigrator(ctx, schema, func(migrator *migrate.Migrator) error {
+		current, err := migrator.GetCurrentVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get current version: %w", err)
+		}
+
+		result, err = pendingMigrations(migrator.Migrations, current, target)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func pendingMigrations(migrations []*migrate.Migration, current, target int32) ([]PendingMigration, error) {
+	latest := int32(len(migrations))
+	if target == LatestVersion {
+		target = latest
+	}
+	if target < 0 || target > latest {
+		return nil, fmt.Errorf("%w: %d (latest is %d)", ErrUnknownVersion, target, latest)
+	}
+	if current > latest {
+		return nil, fmt.Errorf("%w: current version %d (latest is %d)", ErrUnknownVersion, current, latest)
+	}
+
+	result := make([]PendingMigration, 0)
+	for seq := current + 1; seq <= target; seq++ {
+		m := migrations[seq-1]
+		result = append(result, PendingMigration{Sequence: m.Sequence, Name: m.Name, Direction: "up", SQL: m.UpSQL})
+	}
+	for seq := current; seq > target; seq-- {
+		m := migrations[seq-1]
+		if m.DownSQL == "" {
+			return nil, fmt.Errorf("%w: %s", ErrIrreversibleMigration, m.Name)
+		}
+		result = append(result, PendingMigration{Sequence: m.Sequence, Name: m.Name, Direction: "down", SQL: m.DownSQL})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/jackc/tern/v2/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	list := []*migrate.Migration{
+		{Sequence: 1, Name: "001_one.sql", UpSQL: "up 1"},
+		{Sequence: 2, Name: "002_two.sql", UpSQL: "up 2", DownSQL: "down 2"},
+		{Sequence: 3, Name: "003_three.sql", UpSQL: "up 3", DownSQL: "down 3"},
+	}
+
+	t.Run("up to latest", func(t *testing.T) {
+		pending, err := pendingMigrations(list, 1, LatestVersion)
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+		assert.Equal(t, "up 2", pending[0].SQL)
+		assert.Equal(t, "up 3", pending[1].SQL)
+	})
+
+	t.Run("down", func(t *testing.T) {
+		pending, err := pendingMigrations(list, 3, 1)
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+		assert.Equal(t, "down", pending[0].Direction)
+		assert.Equal(t, "down 3", pending[0].SQL)
+		assert.Equal(t, "down 2", pending[1].SQL)
+	})
+
+	t.Run("nothing pending", func(t *testing.T) {
+		pending, err := pendingMigrations(list, 3, LatestVersion)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("irreversible", func(t *testing.T) {
+		_, err := pendingMigrations(list, 3, 0)
+		require.ErrorIs(t, err, ErrIrreversibleMigration)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := pendingMigrations(list, 1, 4)
+		require.ErrorIs(t, err, ErrUnknownVersion)
+	})
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// AccountAuditMerge is the operation moving all records of one account to another account.
+const AccountAuditMerge = "merge"
+
+// AccountAuditRename is the operation changing organization ID of an account.
+const AccountAuditRename = "rename"
+
+// AccountAudit is a record of an account merge or rename performed by an operator.
+type AccountAudit struct {
+	// Required auto-generated PK.
+	ID int64 `db:"id"`
+
+	// Operation, one of AccountAuditMerge or AccountAuditRename.
+	Operation string `db:"operation"`
+
+	// Target account of the merge or the renamed account.
+	AccountID int64 `db:"account_id"`
+
+	// Source account of the merge, zero for renames.
+	SourceAccountID int64 `db:"source_account_id"`
+
+	// Organization ID of the source account or the previous organization ID of the renamed account.
+	OldOrgID string `db:"old_org_id"`
+
+	// Organization ID of the target account or the new organization ID of the renamed account.
+	NewOrgID string `db:"new_org_id"`
+
+	// Number of moved pubkeys (including deleted ones).
+	Pubkeys int64 `db:"pubkeys"`
+
+	// Number of moved reservations (including archived ones).
+	Reservations int64 `db:"reservations"`
+
+	// Number of moved reservation templates.
+	ReservationTemplates int64 `db:"reservation_templates"`
+
+	// Organization ID of the operator who requested the operation.
+	RequestedBy string `db:"requested_by"`
+
+	// Time of the operation.
+	CreatedAt time.Time `db:"created_at"`
+}
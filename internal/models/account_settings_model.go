@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// AccountSettings are defaults and restrictions of an account set by organization admins.
+// Defaults are applied to new reservations when the corresponding fields are omitted.
+type AccountSettings struct {
+	// Associated Account model. Required.
+	AccountID int64 `db:"account_id"`
+
+	// Default AWS region.
+	AWSRegion string `db:"aws_region"`
+
+	// Default Azure location.
+	AzureLocation string `db:"azure_location"`
+
+	// Default GCP zone.
+	GCPZone string `db:"gcp_zone"`
+
+	// Optional default pubkey ID, set to nil when the pubkey is deleted.
+	PubkeyID *int64 `db:"pubkey_id"`
+
+	// Names of providers reservations can be created for, all providers are allowed when empty.
+	AllowedProviders []string `db:"allowed_providers"`
+
+	// Maximum estimated spend of instances of running reservations in US dollars per hour, zero
+	// when not capped.
+	MaxHourlySpend float64 `db:"max_hourly_spend"`
+
+	// Keys of tags which must be set with a non-empty value on new reservations.
+	RequiredTags []string `db:"required_tags"`
+
+	// Time of the last update.
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// AllowsProvider returns true when reservations of the provider are not restricted.
+func (s *AccountSettings) AllowsProvider(provider ProviderType) bool {
+	if len(s.AllowedProviders) == 0 {
+		return true
+	}
+	for _, name := range s.AllowedProviders {
+		if ProviderTypeFromString(name) == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRegion returns the default region (AWS), location (Azure) or zone (GCP) of the
+// provider, blank when not set.
+func (s *AccountSettings) DefaultRegion(provider ProviderType) string {
+	switch provider {
+	case ProviderTypeAWS:
+		return s.AWSRegion
+	case ProviderTypeAzure:
+		return s.AzureLocation
+	case ProviderTypeGCP:
+		return s.GCPZone
+	case ProviderTypeNoop, ProviderTypeUnknown:
+	}
+	return ""
+}
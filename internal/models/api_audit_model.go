@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// APIAudit is a record of a single mutating API call. Audit records are only accessible via
+// the admin API.
+type APIAudit struct {
+	// Required auto-generated PK.
+	ID int64 `db:"id"`
+
+	// Account of the caller. Required.
+	AccountID int64 `db:"account_id" validate:"required"`
+
+	// Organization ID of the caller.
+	OrgID string `db:"org_id"`
+
+	// Name of the caller: user name, associate e-mail or certificate subject.
+	Principal string `db:"principal"`
+
+	// HTTP method. Required.
+	Method string `db:"method" validate:"required"`
+
+	// Chi route pattern (e.g. /api/provisioning/v1/pubkeys/{ID}). Required.
+	Route string `db:"route" validate:"required"`
+
+	// ID of the resource from the route, empty for routes without ID.
+	ResourceID string `db:"resource_id"`
+
+	// HTTP response status.
+	Status int `db:"status"`
+
+	// OpenTelemetry trace ID of the call.
+	TraceID string `db:"trace_id"`
+
+	// Time of the call.
+	CreatedAt time.Time `db:"created_at"`
+}
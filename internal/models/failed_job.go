@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FailedJob is a background job which failed on the last attempt (dead-letter queue). Failed
+// jobs are not tenant-specific and are only accessible via the admin API.
+type FailedJob struct {
+	// Required auto-generated PK.
+	ID int64 `db:"id"`
+
+	// Original job UUID.
+	JobID string `db:"job_id"`
+
+	// Job type. Required.
+	JobType string `db:"job_type" validate:"required"`
+
+	// Associated account, zero for jobs without account.
+	AccountID int64 `db:"account_id"`
+
+	// Job arguments in JSON, for display only.
+	Args json.RawMessage `db:"args"`
+
+	// Encoded job used for replay. Required.
+	Payload []byte `db:"payload" validate:"required"`
+
+	// Error returned by the last attempt.
+	Error string `db:"error"`
+
+	// Number of attempts made.
+	Attempts int `db:"attempts"`
+
+	// Time of the last failed attempt.
+	FailedAt time.Time `db:"failed_at"`
+
+	// Time of the last replay, nil when never replayed.
+	ReplayedAt *time.Time `db:"replayed_at"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// InstanceActionType is a lifecycle action performed on an instance of a reservation.
+type InstanceActionType string
+
+const (
+	// InstanceActionStop stops the instance, Azure VMs are deallocated so compute is not billed.
+	InstanceActionStop InstanceActionType = "stop"
+
+	// InstanceActionStart starts a stopped instance.
+	InstanceActionStart InstanceActionType = "start"
+
+	// InstanceActionReboot reboots a running instance.
+	InstanceActionReboot InstanceActionType = "reboot"
+)
+
+// InstanceActionTypes returns all supported actions.
+func InstanceActionTypes() []string {
+	return []string{
+		string(InstanceActionStop),
+		string(InstanceActionStart),
+		string(InstanceActionReboot),
+	}
+}
+
+// InstanceActionStatus is the state of an instance action.
+type InstanceActionStatus string
+
+const (
+	// InstanceActionPending actions are waiting for a worker.
+	InstanceActionPending InstanceActionStatus = "pending"
+
+	// InstanceActionRunning actions were picked by a worker and sent to the provider.
+	InstanceActionRunning InstanceActionStatus = "running"
+
+	// InstanceActionSuccess actions were completed by the provider.
+	InstanceActionSuccess InstanceActionStatus = "success"
+
+	// InstanceActionFailed actions were rejected by the provider or could not be performed.
+	InstanceActionFailed InstanceActionStatus = "failed"
+)
+
+// InstanceAction is a lifecycle action requested on an instance of a reservation.
+type InstanceAction struct {
+	// Database ID
+	ID int64 `db:"id"`
+
+	// Associated Account model. Required.
+	AccountID int64 `db:"account_id"`
+
+	// Associated Reservation model. Required.
+	ReservationID int64 `db:"reservation_id" validate:"required"`
+
+	// Instance ID of the cloud provider. Required.
+	InstanceID string `db:"instance_id" validate:"required"`
+
+	// Requested action. Required.
+	Action InstanceActionType `db:"action" validate:"required,oneof=stop start reboot"`
+
+	// Action state.
+	Status InstanceActionStatus `db:"status"`
+
+	// Error of a failed action.
+	Error string `db:"error"`
+
+	// Time of the request.
+	CreatedAt time.Time `db:"created_at"`
+
+	// Time the action succeeded or failed.
+	FinishedAt *time.Time `db:"finished_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// InstanceTypeAvailability represents list of instance types available in a region and
+// zone of a provider. Records are refreshed periodically from provider APIs.
+type InstanceTypeAvailability struct {
+	// Provider constant (for example ProviderTypeAWS). Required.
+	Provider ProviderType `db:"provider"`
+
+	// Region name. Required.
+	Region string `db:"region"`
+
+	// Zone name or blank string for providers which do not have zonal information.
+	Zone string `db:"zone"`
+
+	// Sorted list of instance type names available in the region and zone.
+	InstanceTypes []string `db:"instance_types"`
+
+	// Time of the last refresh.
+	UpdatedAt time.Time `db:"updated_at"`
+}
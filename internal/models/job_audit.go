@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// JobAudit is a record of a single background job attempt. Audit records are not
+// tenant-specific and are only accessible via the admin API.
+type JobAudit struct {
+	// Required auto-generated PK.
+	ID int64 `db:"id"`
+
+	// Job UUID.
+	JobID string `db:"job_id"`
+
+	// Job type. Required.
+	JobType string `db:"job_type" validate:"required"`
+
+	// Associated account, zero for jobs without account.
+	AccountID int64 `db:"account_id"`
+
+	// Associated reservation, zero for jobs without reservation.
+	ReservationID int64 `db:"reservation_id"`
+
+	// SHA-256 of job arguments in JSON, arguments can contain secrets.
+	ArgsHash string `db:"args_hash"`
+
+	// Attempt number starting from 1.
+	Attempt int `db:"attempt"`
+
+	// Outcome of the attempt (success/retry/failure/timeout). Required.
+	Outcome string `db:"outcome" validate:"required"`
+
+	// Error returned by the attempt.
+	Error string `db:"error"`
+
+	// Time when the attempt started.
+	StartedAt time.Time `db:"started_at"`
+
+	// Duration of the attempt in milliseconds.
+	DurationMs int64 `db:"duration_ms"`
+}
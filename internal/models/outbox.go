@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// OutboxHeader is a header of an outbox message.
+type OutboxHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// OutboxMessage is a message waiting to be published to Kafka. Outbox messages are not
+// tenant-specific.
+type OutboxMessage struct {
+	// Database ID
+	ID int64 `db:"id"`
+
+	// Kafka topic. Required.
+	Topic string `db:"topic" validate:"required"`
+
+	// Message key, can be nil.
+	Key []byte `db:"key"`
+
+	// Message payload. Required.
+	Value []byte `db:"value" validate:"required"`
+
+	// Message headers.
+	Headers []OutboxHeader `db:"headers"`
+
+	// Time when the message was stored.
+	CreatedAt time.Time `db:"created_at"`
+
+	// Number of failed publish attempts.
+	Attempts int32 `db:"attempts"`
+
+	// Time of the next publish attempt.
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+
+	// Error of the last failed publish attempt.
+	LastError string `db:"last_error"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// ownedBy returns true when a resource of the owner can be modified by the user (see
+// identity.Owner). Resources without an owner belong to the whole organization, non-user
+// identities (empty user) can only modify those.
+func ownedBy(owner, user string) bool {
+	return owner == "" || owner == user
+}
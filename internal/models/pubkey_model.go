@@ -5,12 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/ssh"
 	"github.com/rs/zerolog"
 )
 
-var ErrInvalidPubkeyFormat = errors.New("invalid public key format")
+var (
+	ErrInvalidPubkeyFormat     = errors.New("invalid public key format")
+	ErrPubkeyTypeNotCompatible = errors.New("public key type is not supported by the provider")
+	ErrPubkeyExpired           = errors.New("public key has expired")
+)
 
 // Pubkey represents SSH public key that can be deployed to clients.
 type Pubkey struct {
@@ -29,8 +34,8 @@ type Pubkey struct {
 	// Public key body encoded in base64 (.pub format). Required.
 	Body string `db:"body" validate:"required,sshPubkey"`
 
-	// Key type: "ssh-ed25519" or "ssh-rsa".
-	Type string `db:"type" validate:"omitempty,oneof=test ssh-rsa ssh-ed25519"`
+	// Key type: "ssh-ed25519", "ssh-rsa" or "ecdsa-sha2-nistp256" (384, 521).
+	Type string `db:"type" validate:"omitempty,oneof=test ssh-rsa ssh-ed25519 ecdsa-sha2-nistp256 ecdsa-sha2-nistp384 ecdsa-sha2-nistp521"`
 
 	// SHA256 base64 encoded fingerprint with padding without any prefix. Note OpenSSH
 	// typically prints the fingerprint without padding: ssh-keygen -l -f $HOME/.ssh/key.pub
@@ -42,24 +47,84 @@ type Pubkey struct {
 	// such fingerprint: ssh-keygen -l -E md5 -f $HOME/.ssh/key.pub
 	// Example: "89:c5:99:b5:33:48:1c:84:be:da:cb:97:45:b0:4a:ee"
 	FingerprintLegacy string `db:"fingerprint_legacy" validate:"omitempty,len=47"`
+
+	// Site the key was imported from ("github" or "gitlab"), blank for keys created directly.
+	Origin string `db:"origin" validate:"omitempty,oneof=github gitlab"`
+
+	// Username on the origin site the key was published by.
+	OriginUsername string `db:"origin_username"`
+
+	// Time of the import, nil for keys created directly.
+	ImportedAt *time.Time `db:"imported_at"`
+
+	// Optional expiration time, nil for keys which never expire.
+	ExpiresAt *time.Time `db:"expires_at"`
+
+	// Time when expiry notification was sent, reset when expiration changes.
+	ExpiryNotifiedAt *time.Time `db:"expiry_notified_at"`
+
+	// User name of the user who created the key, empty for keys of the whole organization.
+	// See identity.Owner.
+	Owner string `db:"owner"`
+
+	// Shared keys are visible (read-only) to all users of the organization.
+	Shared bool `db:"shared"`
+
+	// Time when the pubkey was deleted by the user, nil for active keys. Deleted keys can be
+	// restored until they are purged.
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+// ModifiableBy returns true when the user (see identity.Owner) owns the key.
+func (pk *Pubkey) ModifiableBy(user string) bool {
+	return ownedBy(pk.Owner, user)
+}
+
+// VisibleTo returns true when the key is shared or owned by the user.
+func (pk *Pubkey) VisibleTo(user string) bool {
+	return pk.Shared || ownedBy(pk.Owner, user)
 }
 
 // FindAwsFingerprint returns suitable fingerprint for searching AWS key-pairs.
 func (pk *Pubkey) FindAwsFingerprint(ctx context.Context) string {
 	switch pk.Type {
-	case "ssh-rsa":
+	case ssh.KeyTypeRSA:
 		fp, err := ssh.GenerateAWSFingerprint([]byte(pk.Body))
 		if err != nil {
 			zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to generate AWS fingerprint for pubkey")
 			return ""
 		}
 		return string(fp)
-	case "ssh-ed25519":
+	case ssh.KeyTypeED25519:
 		return pk.Fingerprint
 	default:
 		return ""
+	}
+}
 
+// Expired returns true when the pubkey has an expiration set and it is in the past.
+func (pk *Pubkey) Expired(now time.Time) bool {
+	return pk.ExpiresAt != nil && !pk.ExpiresAt.After(now)
+}
+
+// CompatibleWith returns ErrPubkeyTypeNotCompatible when the key type cannot be imported
+// into the provider. AWS and Azure only accept RSA and ED25519 keys, ECDSA keys can be
+// only used with GCP which passes the key via instance metadata. Note AWS also rejects
+// ED25519 keys for Windows instances, this is not checked here.
+func (pk *Pubkey) CompatibleWith(provider ProviderType) error {
+	switch provider {
+	case ProviderTypeAWS, ProviderTypeAzure:
+		if pk.Type == ssh.KeyTypeRSA || pk.Type == ssh.KeyTypeED25519 {
+			return nil
+		}
+	case ProviderTypeGCP:
+		if pk.Type == ssh.KeyTypeRSA || pk.Type == ssh.KeyTypeED25519 || ssh.IsECDSA(pk.Type) {
+			return nil
+		}
+	case ProviderTypeNoop, ProviderTypeUnknown:
+		return nil
 	}
+	return fmt.Errorf("%w: %s keys cannot be used with %s", ErrPubkeyTypeNotCompatible, pk.Type, provider)
 }
 
 func (pk *Pubkey) BodyWithUsername(ctx context.Context) (string, error) {
@@ -67,5 +132,5 @@ func (pk *Pubkey) BodyWithUsername(ctx context.Context) (string, error) {
 	if len(parts) < 2 {
 		return "", ErrInvalidPubkeyFormat
 	}
-	return fmt.Sprintf("gcp-user:%s %s", parts[0], parts[1]), nil
+	return fmt.Sprintf("%s:%s %s", GCPUser, parts[0], parts[1]), nil
 }
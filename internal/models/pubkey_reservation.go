@@ -0,0 +1,31 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PubkeyReservation is a reservation which used a particular pubkey. It is assembled from
+// provider-specific reservation details and is read-only.
+type PubkeyReservation struct {
+	// Reservation ID.
+	ReservationID int64 `db:"reservation_id"`
+
+	// Provider type.
+	Provider ProviderType `db:"provider"`
+
+	// Source ID used for the reservation.
+	SourceID string `db:"source_id"`
+
+	// Region for AWS, location for Azure or zone for GCP.
+	Region string `db:"region"`
+
+	// Textual status of the reservation.
+	Status string `db:"status"`
+
+	// Time when reservation was made.
+	CreatedAt time.Time `db:"created_at"`
+
+	// Flag indicating success, error or unknown state (NULL).
+	Success sql.NullBool `db:"success"`
+}
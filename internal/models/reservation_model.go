@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -44,6 +45,46 @@ type Reservation struct {
 
 	// Flag indicating success, error or unknown state (NULL). See Status for the actual error.
 	Success sql.NullBool `db:"success" json:"success"`
+
+	// User requested cancellation, jobs abort and set Status to ReservationStatusCancelled.
+	CancelRequested bool `db:"cancel_requested" json:"cancel_requested"`
+
+	// Flag indicating all instances accept connections on the SSH port, NULL when instances were
+	// not probed (yet). Only set when the SSH probe is enabled.
+	SSHReady sql.NullBool `db:"ssh_ready" json:"ssh_ready"`
+
+	// User name of the user who made the reservation, empty for reservations of the whole
+	// organization. See identity.Owner.
+	Owner string `db:"owner" json:"owner"`
+
+	// Shared reservations are visible (read-only) to all users of the organization.
+	Shared bool `db:"shared" json:"shared"`
+
+	// Time when the reservation was deleted by the user, nil for active reservations. Deleted
+	// reservations can be restored until they are purged.
+	DeletedAt *time.Time `db:"deleted_at" json:"-"`
+}
+
+// ReservationStatusCancelled is the status of reservations aborted on user request.
+const ReservationStatusCancelled = "Cancelled"
+
+// ModifiableBy returns true when the user (see identity.Owner) owns the reservation.
+func (r *Reservation) ModifiableBy(user string) bool {
+	return ownedBy(r.Owner, user)
+}
+
+// VisibleTo returns true when the reservation is shared or owned by the user.
+func (r *Reservation) VisibleTo(user string) bool {
+	return r.Shared || ownedBy(r.Owner, user)
+}
+
+// RunningStepTitle returns title of the step which is running, or which was running when the
+// reservation failed. The step counter is only increased after a step finishes.
+func (r *Reservation) RunningStepTitle() string {
+	if int(r.Step) < len(r.StepTitles) {
+		return r.StepTitles[r.Step]
+	}
+	return "unknown"
 }
 
 type NoopReservation struct {
@@ -59,9 +100,14 @@ type AWSDetail struct {
 	// Optional launch template id ("lt-987432987342") or empty string
 	LaunchTemplateID string `json:"launch_template_id"`
 
-	// AWS Instance type. Can be blank if LaunchTemplateID is set.
+	// AWS Instance type. Can be blank if LaunchTemplateID is set. For fleet launches, it is blank
+	// until the instances are launched, then it is set to the launched type.
 	InstanceType string `json:"instance_type"`
 
+	// Ordered instance types of a fleet launch, the next type is tried when there is not enough
+	// capacity of the previous one. Empty for launches of a single instance type.
+	InstanceTypes []string `json:"instance_types,omitempty"`
+
 	// Amount of instances to provision of type: Instance type.
 	Amount int32 `json:"amount"`
 
@@ -70,8 +116,48 @@ type AWSDetail struct {
 
 	// PubkeyName on AWS in given region. Found by the EnsurePubkey job.
 	PubkeyName string `json:"pubkey_name"`
+
+	// Tenancy of the instances, see AWSTenancy constants. Empty for reservations made before
+	// tenancy was recorded, those were launched with the default tenancy.
+	Tenancy string `json:"tenancy,omitempty"`
+
+	// Optional dedicated host ID ("h-0a5bd3c4e8f9d1e2f") for the host tenancy, when empty AWS
+	// places instances on any host with auto-placement enabled.
+	HostID string `json:"host_id,omitempty"`
+
+	// Instance metadata service options the instances were launched with, nil for reservations
+	// made before the options were recorded.
+	MetadataOptions *AWSMetadataOptions `json:"metadata_options,omitempty"`
+
+	// User tags of the instances, standard tags are added during launch.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// AWSMetadataOptions configures the instance metadata service (IMDS) of AWS instances.
+type AWSMetadataOptions struct {
+	// HTTPTokens is "required" to accept IMDSv2 requests only or "optional" to accept IMDSv1 too.
+	HTTPTokens string `json:"http_tokens"`
+
+	// HopLimit of the metadata PUT response, containers need at least 2.
+	HopLimit int32 `json:"hop_limit"`
+
+	// Disabled turns off the metadata endpoint, other options have no effect then.
+	Disabled bool `json:"disabled"`
 }
 
+// AWS instance metadata HTTP tokens state.
+const (
+	AWSHTTPTokensRequired = "required"
+	AWSHTTPTokensOptional = "optional"
+)
+
+// AWS instance tenancy, instances run on shared hardware by default.
+const (
+	AWSTenancyDefault   = "default"
+	AWSTenancyDedicated = "dedicated"
+	AWSTenancyHost      = "host"
+)
+
 type AWSReservation struct {
 	Reservation
 
@@ -112,8 +198,51 @@ type GCPDetail struct {
 
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff"`
+
+	// Provisioning model, see GCPProvisioning constants. Empty for reservations made before spot
+	// VMs were supported, those are standard VMs.
+	ProvisioningModel string `json:"provisioning_model,omitempty"`
+
+	// Action taken when spot VMs are preempted, see GCPTermination constants.
+	TerminationAction string `json:"termination_action,omitempty"`
+
+	// Email of the service account attached to the instances, empty when no account is attached.
+	ServiceAccount string `json:"service_account,omitempty"`
+
+	// OAuth scopes of the attached service account.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Network path ("global/networks/NAME"), can be prefixed with a shared VPC host project
+	// ("projects/PROJECT/global/networks/NAME"). Empty for the default network.
+	Network string `json:"network,omitempty"`
+
+	// Subnetwork path ("regions/REGION/subnetworks/NAME"), can be prefixed with a shared VPC host
+	// project. Empty for the subnetwork picked by GCP.
+	Subnetwork string `json:"subnetwork,omitempty"`
+
+	// User labels of the instances, standard labels are added during launch.
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
+// GCPServiceAccountNone explicitly launches instances without any service account.
+const GCPServiceAccountNone = "none"
+
+// GCPDefaultScope is the scope of attached service accounts when no scopes are given, access is
+// then limited only by IAM roles of the service account.
+const GCPDefaultScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// GCP provisioning model: standard VMs or spot VMs which can be preempted at any time.
+const (
+	GCPProvisioningStandard = "STANDARD"
+	GCPProvisioningSpot     = "SPOT"
+)
+
+// GCP instance termination action of preempted spot VMs: stop keeping the disks or delete.
+const (
+	GCPTerminationStop   = "STOP"
+	GCPTerminationDelete = "DELETE"
+)
+
 type GCPReservation struct {
 	Reservation
 
@@ -149,8 +278,63 @@ type AzureDetail struct {
 
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff"`
+
+	// Resource group strategy, see AzureResourceGroup constants. Empty for reservations made
+	// before the strategy was recorded, those were launched into the shared group.
+	ResourceGroupStrategy string `json:"resource_group_strategy,omitempty"`
+
+	// Resource group the instances are launched into, the name of groups created for the
+	// reservation is only known after the group is ensured by the launch job.
+	ResourceGroup string `json:"resource_group,omitempty"`
+
+	// Tags of the resource group created for the reservation.
+	ResourceGroupTags map[string]string `json:"resource_group_tags,omitempty"`
+
+	// Priority of the VMs, see AzurePriority constants. Empty for reservations made before
+	// spot VMs were supported, those are regular VMs.
+	Priority string `json:"priority,omitempty"`
+
+	// Maximum price of spot VMs in US dollars per hour, -1 caps the price at the regular price
+	// and VMs are only evicted for capacity.
+	MaxPrice *float64 `json:"max_price,omitempty"`
+
+	// Eviction policy of spot VMs, see AzureEviction constants.
+	EvictionPolicy string `json:"eviction_policy,omitempty"`
+
+	// User tags of the VMs and their resources, standard tags are added during launch.
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
+// Azure VM priority: regular VMs or spot VMs using spare capacity which can be evicted.
+const (
+	AzurePriorityRegular = "regular"
+	AzurePrioritySpot    = "spot"
+)
+
+// Azure spot VM eviction policy: evicted VMs are stopped and deallocated, or deleted including
+// their disks.
+const (
+	AzureEvictionDeallocate = "deallocate"
+	AzureEvictionDelete     = "delete"
+)
+
+// Eviction status of spot VMs of a reservation reported while polling the reservation.
+const (
+	AzureEvictionStatusNone    = "none"
+	AzureEvictionStatusPartial = "partial"
+	AzureEvictionStatusEvicted = "evicted"
+	AzureEvictionStatusUnknown = "unknown"
+)
+
+// Azure resource group strategy: the shared group of the service created in every subscription,
+// an existing group of the customer or a group created for the reservation. Groups created for
+// the reservation are deleted when the launch fails.
+const (
+	AzureResourceGroupShared      = "shared"
+	AzureResourceGroupExisting    = "existing"
+	AzureResourceGroupReservation = "reservation"
+)
+
 type AzureReservation struct {
 	Reservation
 
@@ -182,3 +366,19 @@ type ReservationInstance struct {
 	// Instance's description, ip and dns
 	Detail ReservationInstanceDetail `db:"detail" json:"detail" yaml:"detail"`
 }
+
+// ArchivedReservation is a finished reservation moved out of the reservations table by the
+// archival job. It is read-only, provider details and instances are kept as JSON documents.
+type ArchivedReservation struct {
+	Reservation
+
+	// Provider details (e.g. pubkey_id, source_id or detail) of the reservation, empty object
+	// for reservations without details.
+	Detail json.RawMessage `db:"detail" json:"detail"`
+
+	// Instances created by the reservation as an array of objects.
+	Instances json.RawMessage `db:"instances" json:"instances"`
+
+	// Time when the reservation was archived.
+	ArchivedAt time.Time `db:"archived_at" json:"archived_at"`
+}
@@ -0,0 +1,36 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunningStepTitle(t *testing.T) {
+	reservation := &models.Reservation{StepTitles: []string{"Ensure public key", "Launch instance(s)"}}
+	require.Equal(t, "Ensure public key", reservation.RunningStepTitle())
+
+	reservation.Step = 1
+	require.Equal(t, "Launch instance(s)", reservation.RunningStepTitle())
+
+	reservation.Step = 2
+	require.Equal(t, "unknown", reservation.RunningStepTitle())
+}
+
+func TestReservationOwnership(t *testing.T) {
+	private := &models.Reservation{Owner: "alice"}
+	require.True(t, private.VisibleTo("alice"))
+	require.True(t, private.ModifiableBy("alice"))
+	require.False(t, private.VisibleTo("bob"))
+	require.False(t, private.VisibleTo(""), "organization-wide identities must not see private reservations")
+	require.False(t, private.ModifiableBy(""))
+
+	shared := &models.Reservation{Owner: "alice", Shared: true}
+	require.True(t, shared.VisibleTo(""))
+	require.False(t, shared.ModifiableBy(""))
+
+	unowned := &models.Reservation{}
+	require.True(t, unowned.VisibleTo("bob"))
+	require.True(t, unowned.ModifiableBy(""))
+}
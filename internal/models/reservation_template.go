@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// ReservationTemplate is a named launch configuration which can be referenced when creating
+// a reservation instead of sending the full payload. Fields of the reservation request
+// override template fields.
+type ReservationTemplate struct {
+	// Required auto-generated PK.
+	ID int64 `db:"id"`
+
+	// Associated Account model. Required.
+	AccountID int64 `db:"account_id"`
+
+	// User-facing name, unique per account. Required.
+	Name string `db:"name" validate:"required"`
+
+	// Provider type. Required.
+	Provider ProviderType `db:"provider" validate:"required"`
+
+	// Source ID.
+	SourceID string `db:"source_id"`
+
+	// Image Builder UUID of the image or provider image ID.
+	ImageID string `db:"image_id"`
+
+	// Instance type (AWS), instance size (Azure) or machine type (GCP).
+	InstanceType string `db:"instance_type"`
+
+	// Region (AWS), location (Azure) or zone (GCP).
+	Region string `db:"region"`
+
+	// Optional pubkey ID, set to nil when the pubkey is deleted.
+	PubkeyID *int64 `db:"pubkey_id"`
+
+	// Arbitrary user-defined key-value pairs.
+	Tags map[string]string `db:"tags"`
+
+	// Time of creation.
+	CreatedAt time.Time `db:"created_at"`
+
+	// Time of the last update.
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "strconv"
+
+// Standard tags set on all cloud resources created for reservations, users cannot set them.
+const (
+	TagManagedBy     = "managed-by"
+	TagReservationID = "reservation-id"
+	TagOrgID         = "org-id"
+)
+
+// TagManagedByValue is the value of the managed-by tag.
+const TagManagedByValue = "red-hat-provisioning"
+
+// IsStandardTag returns true for keys of tags set by the service.
+func IsStandardTag(key string) bool {
+	return key == TagManagedBy || key == TagReservationID || key == TagOrgID
+}
+
+// ResourceTags returns user tags of a reservation with the standard tags, blank organization
+// ID is not tagged. The user tags are not modified.
+func ResourceTags(tags map[string]string, reservationID int64, orgID string) map[string]string {
+	result := make(map[string]string, len(tags)+3)
+	for key, value := range tags {
+		result[key] = value
+	}
+	result[TagManagedBy] = TagManagedByValue
+	result[TagReservationID] = strconv.FormatInt(reservationID, 10)
+	if orgID != "" {
+		result[TagOrgID] = orgID
+	}
+	return result
+}
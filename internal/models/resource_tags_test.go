@@ -0,0 +1,24 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceTags(t *testing.T) {
+	userTags := map[string]string{"cost-center": "42", "managed-by": "me"}
+	tags := models.ResourceTags(userTags, 7, "13")
+
+	assert.Equal(t, map[string]string{
+		"cost-center":    "42",
+		"managed-by":     "red-hat-provisioning",
+		"reservation-id": "7",
+		"org-id":         "13",
+	}, tags)
+	assert.Equal(t, "me", userTags["managed-by"], "user tags must not be modified")
+
+	tags = models.ResourceTags(nil, 7, "")
+	assert.NotContains(t, tags, "org-id")
+}
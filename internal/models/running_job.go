@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RunningJob is a background job currently processed by a worker. Running jobs are not
+// tenant-specific.
+type RunningJob struct {
+	// Job UUID, the same for all attempts of a job. Required.
+	JobID string `db:"job_id" validate:"required"`
+
+	// Job type. Required.
+	JobType string `db:"job_type" validate:"required"`
+
+	// Hostname of the worker processing the job.
+	Worker string `db:"worker"`
+
+	// Encoded job used to enqueue it again. Required.
+	Payload []byte `db:"payload" validate:"required"`
+
+	// Time when the worker started processing the job.
+	StartedAt time.Time `db:"started_at"`
+
+	// Time of the last heartbeat of the worker.
+	HeartbeatAt time.Time `db:"heartbeat_at"`
+}
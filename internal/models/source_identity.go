@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// SourceIdentity is a cloud account identity resolved from a source authentication.
+type SourceIdentity struct {
+	// Associated Account model. Required.
+	AccountID int64 `db:"account_id"`
+
+	// Source ID from Sources Database.
+	SourceID string `db:"source_id"`
+
+	// Authentication the identity was resolved from (e.g. AWS role ARN).
+	Authentication string `db:"authentication"`
+
+	// Cloud account ID (e.g. AWS account ID).
+	ProviderAccountID string `db:"provider_account_id"`
+
+	// Time of the resolution.
+	ResolvedAt time.Time `db:"resolved_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "strings"
+
+const (
+	// AWSUser is the default cloud-init user of RHEL and CentOS images on AWS.
+	AWSUser = "ec2-user"
+
+	// AWSFedoraUser is the default cloud-init user of Fedora images on AWS.
+	AWSFedoraUser = "fedora"
+
+	// AzureUser is the admin user of launched Azure VMs.
+	AzureUser = "azureuser"
+
+	// GCPUser is the user the pubkey is assigned to in metadata of launched GCP instances.
+	GCPUser = "gcp-user"
+)
+
+// SSHUser returns the user to log in as into instances launched by the provider from an image
+// of the distribution (e.g. rhel-92). The distribution is empty when not known (e.g. direct AMI).
+// Returns an empty string for providers without instances.
+//
+//nolint:exhaustive
+func SSHUser(provider ProviderType, distribution string) string {
+	switch provider {
+	case ProviderTypeAWS:
+		if strings.HasPrefix(distribution, "fedora") {
+			return AWSFedoraUser
+		}
+		return AWSUser
+	case ProviderTypeAzure:
+		return AzureUser
+	case ProviderTypeGCP:
+		return GCPUser
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,17 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHUser(t *testing.T) {
+	assert.Equal(t, "ec2-user", models.SSHUser(models.ProviderTypeAWS, "rhel-92"))
+	assert.Equal(t, "ec2-user", models.SSHUser(models.ProviderTypeAWS, ""))
+	assert.Equal(t, "fedora", models.SSHUser(models.ProviderTypeAWS, "fedora-38"))
+	assert.Equal(t, "azureuser", models.SSHUser(models.ProviderTypeAzure, "rhel-92"))
+	assert.Equal(t, "gcp-user", models.SSHUser(models.ProviderTypeGCP, "rhel-92"))
+	assert.Empty(t, models.SSHUser(models.ProviderTypeNoop, ""))
+}
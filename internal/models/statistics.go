@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Statistics struct {
 	Usage24h []*UsageStat
 	Usage28d []*UsageStat
@@ -10,3 +12,48 @@ type UsageStat struct {
 	Result   string       `db:"result"`
 	Count    int64        `db:"count"`
 }
+
+// Results of reservations used in reservation statistics.
+const (
+	ReservationResultSuccess   = "success"
+	ReservationResultFailure   = "failure"
+	ReservationResultCancelled = "cancelled"
+	ReservationResultPending   = "pending"
+)
+
+// ReservationProviderStat are aggregate statistics of reservations of an account for a provider.
+type ReservationProviderStat struct {
+	Provider  ProviderType `db:"provider"`
+	Total     int64        `db:"total"`
+	Success   int64        `db:"success"`
+	Failure   int64        `db:"failure"`
+	Cancelled int64        `db:"cancelled"`
+	Pending   int64        `db:"pending"`
+
+	// Number of instances launched by the reservations.
+	Instances int64 `db:"instances"`
+
+	// Ratio of successful reservations to finished ones (cancelled are not counted), zero when
+	// no reservation finished.
+	SuccessRate float64 `db:"success_rate"`
+}
+
+// ReservationInstanceTypeAmount is the number of instances of a type requested by a reservation.
+type ReservationInstanceTypeAmount struct {
+	Provider ProviderType `db:"provider"`
+
+	// Instance type (AWS), size (Azure) or machine type (GCP), blank when launched from a launch
+	// template.
+	InstanceType string `db:"instance_type"`
+
+	Amount int64 `db:"amount"`
+}
+
+// ReservationDailyStat is the number of reservations of an account created on a day for
+// a provider with a result.
+type ReservationDailyStat struct {
+	Day      time.Time    `db:"day"`
+	Provider ProviderType `db:"provider"`
+	Result   string       `db:"result"`
+	Count    int64        `db:"count"`
+}
@@ -56,7 +56,7 @@ func validateAWS(ctx context.Context, sl mold.StructLevel) error {
 	_, err := ssh.GenerateAWSFingerprint([]byte(pk.Body))
 	if err != nil {
 		zerolog.Ctx(ctx).Warn().Err(err).Str("pubkey", pk.Body).Msg("AWS fingerprint validation error")
-		return fmt.Errorf("invalid public key type (only ed25519 and rsa keys are supported): %w", err)
+		return fmt.Errorf("invalid public key type (only ed25519, ecdsa and rsa keys are supported): %w", err)
 	}
 	sl.Struct().Set(reflect.ValueOf(pk))
 
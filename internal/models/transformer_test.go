@@ -19,6 +19,7 @@ func TestFingerprintGeneration(t *testing.T) {
 	tests := []test{
 		{"ed25519", factories.NewPubkeyED25519(), "gL/y6MvNmJ8jDXtsL/oMmK8jUuIefN39BBuvYw/Rndk="},
 		{"rsa", factories.NewPubkeyRSA(), "ENShRe/0uDLSw9c+7tc9PxkD/p4blyB/DTgBSIyTAJY="},
+		{"ecdsa", factories.NewPubkeyECDSA(), "i2SD7CQSFn/jesN7jfPEkMTxOQKatfdM3jy8Q92IC5c="},
 	}
 
 	for _, td := range tests {
@@ -41,6 +42,7 @@ func TestFingerprintLegacyGeneration(t *testing.T) {
 	tests := []test{
 		{"ed25519", factories.NewPubkeyED25519(), "ee:f1:d4:62:99:ab:17:d9:3b:00:66:62:32:b2:55:9e"},
 		{"rsa", factories.NewPubkeyRSA(), "89:c5:99:b5:33:48:1c:84:be:da:cb:97:45:b0:4a:ee"},
+		{"ecdsa", factories.NewPubkeyECDSA(), "a1:e4:56:47:d7:31:4d:09:05:58:fe:d5:77:4b:d2:a1"},
 	}
 
 	for _, td := range tests {
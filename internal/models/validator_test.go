@@ -3,6 +3,7 @@ package models_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
@@ -98,3 +99,44 @@ func TestBodyWithUsername(t *testing.T) {
 		assert.Equal(t, pkBody, "")
 	})
 }
+
+func TestPubkeyCompatibleWith(t *testing.T) {
+	type test struct {
+		name       string
+		pubkey     *models.Pubkey
+		provider   models.ProviderType
+		compatible bool
+	}
+
+	tests := []test{
+		{"rsa aws", factories.NewPubkeyRSA(), models.ProviderTypeAWS, true},
+		{"ed25519 aws", factories.NewPubkeyED25519(), models.ProviderTypeAWS, true},
+		{"ecdsa aws", factories.NewPubkeyECDSA(), models.ProviderTypeAWS, false},
+		{"ed25519 azure", factories.NewPubkeyED25519(), models.ProviderTypeAzure, true},
+		{"ecdsa azure", factories.NewPubkeyECDSA(), models.ProviderTypeAzure, false},
+		{"ecdsa gcp", factories.NewPubkeyECDSA(), models.ProviderTypeGCP, true},
+	}
+
+	for _, td := range tests {
+		t.Run(td.name, func(t *testing.T) {
+			pk := factories.PubkeyWithTrans(t, context.Background(), td.pubkey)
+			err := pk.CompatibleWith(td.provider)
+			if td.compatible {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, models.ErrPubkeyTypeNotCompatible)
+			}
+		})
+	}
+}
+
+func TestPubkeyExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	assert.False(t, (&models.Pubkey{}).Expired(now))
+	assert.True(t, (&models.Pubkey{ExpiresAt: &past}).Expired(now))
+	assert.True(t, (&models.Pubkey{ExpiresAt: &now}).Expired(now))
+	assert.False(t, (&models.Pubkey{ExpiresAt: &future}).Expired(now))
+}
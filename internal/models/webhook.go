@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent is a reservation lifecycle event webhooks can subscribe to.
+type WebhookEvent string
+
+const (
+	// WebhookEventReservationSuccess is sent when all steps of a reservation finished.
+	WebhookEventReservationSuccess WebhookEvent = "reservation.success"
+
+	// WebhookEventReservationFailure is sent when a reservation finished with an error.
+	WebhookEventReservationFailure WebhookEvent = "reservation.failure"
+
+	// WebhookEventInstanceTerminated is sent when launched instances were terminated.
+	WebhookEventInstanceTerminated WebhookEvent = "instance.terminated"
+)
+
+// WebhookEvents returns all supported events.
+func WebhookEvents() []string {
+	return []string{
+		string(WebhookEventReservationSuccess),
+		string(WebhookEventReservationFailure),
+		string(WebhookEventInstanceTerminated),
+	}
+}
+
+// WebhookDeliveryStatus is the state of a single delivery.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending deliveries are waiting for the first or the next attempt.
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+
+	// WebhookDeliveryDelivered deliveries were accepted by the endpoint.
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+
+	// WebhookDeliveryFailed deliveries exhausted all attempts.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// Webhook is an HTTPS endpoint registered by an organization to receive events.
+type Webhook struct {
+	// Database ID
+	ID int64 `db:"id"`
+
+	// Associated Account model. Required.
+	AccountID int64 `db:"account_id"`
+
+	// Endpoint URL. Required.
+	URL string `db:"url" validate:"required"`
+
+	// Secret used to sign deliveries. Required.
+	Secret string `db:"secret" validate:"required"`
+
+	// Subscribed events. Required.
+	Events []string `db:"events" validate:"required"`
+
+	// Time of the registration.
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Subscribed returns true when the webhook subscribes to the event.
+func (w *Webhook) Subscribed(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == string(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a single event delivery to a webhook, it serves as the delivery log.
+type WebhookDelivery struct {
+	// Database ID
+	ID int64 `db:"id"`
+
+	// Associated Webhook model.
+	WebhookID int64 `db:"webhook_id"`
+
+	// Associated Account model.
+	AccountID int64 `db:"account_id"`
+
+	// Delivered event.
+	Event WebhookEvent `db:"event"`
+
+	// JSON payload sent as the request body.
+	Payload json.RawMessage `db:"payload"`
+
+	// Delivery state.
+	Status WebhookDeliveryStatus `db:"status"`
+
+	// Number of attempts.
+	Attempts int32 `db:"attempts"`
+
+	// Time of the next attempt of a pending delivery.
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+
+	// HTTP status code of the last attempt, zero when no response was received.
+	ResponseStatus int32 `db:"response_status"`
+
+	// Error of the last failed attempt.
+	LastError string `db:"last_error"`
+
+	// Time of the event.
+	CreatedAt time.Time `db:"created_at"`
+
+	// Time of the successful attempt.
+	DeliveredAt *time.Time `db:"delivered_at"`
+}
@@ -2,11 +2,23 @@ package notifications
 
 import (
 	"context"
+
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 )
 
 var GetNotificationClient func(ctx context.Context) NotificationClient = getNoopNotificationClient
 
 type NotificationClient interface {
-	SuccessfulLaunch(ctx context.Context, reservationId int64)
-	FailedLaunch(ctx context.Context, reservationId int64, jobError error)
+	// SuccessfulLaunchMessage returns a launch-success notification with details of all launched
+	// instances or nil when it cannot be created. Messages are stored into the outbox together
+	// with the reservation state change.
+	SuccessfulLaunchMessage(ctx context.Context, reservationId int64) *kafka.GenericMessage
+	// FailedLaunchMessage returns a launch-failed notification with the failed step and the error
+	// summary or nil when it cannot be created.
+	FailedLaunchMessage(ctx context.Context, reservationId int64, jobError error) *kafka.GenericMessage
+	PubkeyExpiring(ctx context.Context, pubkey *models.Pubkey)
+	// ExpiryDigest sends a single expiry-digest notification with all resources of the
+	// organization expiring within the given number of days.
+	ExpiryDigest(ctx context.Context, days int, items []kafka.NotificationDigestItem)
 }
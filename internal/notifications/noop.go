@@ -3,6 +3,9 @@ package notifications
 import (
 	"context"
 
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+
 	"github.com/rs/zerolog"
 )
 
@@ -15,12 +18,24 @@ func getNoopNotificationClient(ctx context.Context) NotificationClient {
 	return &noopNotificationClient{}
 }
 
-func (s *noopNotificationClient) SuccessfulLaunch(ctx context.Context, reservationId int64) {
+func (s *noopNotificationClient) SuccessfulLaunchMessage(ctx context.Context, reservationId int64) *kafka.GenericMessage {
 	logger := zerolog.Ctx(ctx)
 	logger.Warn().Msg("SuccessfulLaunch not started (Notifications not configured)")
+	return nil
 }
 
-func (s *noopNotificationClient) FailedLaunch(ctx context.Context, reservationId int64, jobError error) {
+func (s *noopNotificationClient) FailedLaunchMessage(ctx context.Context, reservationId int64, jobError error) *kafka.GenericMessage {
 	logger := zerolog.Ctx(ctx)
 	logger.Warn().Msg("FailedLaunch not started (Notifications not configured)")
+	return nil
+}
+
+func (s *noopNotificationClient) PubkeyExpiring(ctx context.Context, pubkey *models.Pubkey) {
+	logger := zerolog.Ctx(ctx)
+	logger.Warn().Msg("PubkeyExpiring not started (Notifications not configured)")
+}
+
+func (s *noopNotificationClient) ExpiryDigest(ctx context.Context, days int, items []kafka.NotificationDigestItem) {
+	logger := zerolog.Ctx(ctx)
+	logger.Warn().Msg("ExpiryDigest not started (Notifications not configured)")
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/rs/zerolog"
 )
 
@@ -26,63 +27,101 @@ func Initialize(ctx context.Context) {
 	}
 }
 
-func (x *client) SuccessfulLaunch(ctx context.Context, reservationId int64) {
+func (x *client) SuccessfulLaunchMessage(ctx context.Context, reservationId int64) *kafka.GenericMessage {
 	logger := zerolog.Ctx(ctx)
-	logger.Debug().Msg("Triggering a successful launch notification")
+	logger.Debug().Msg("Creating a successful launch notification")
 	rDao := dao.GetReservationDao(ctx)
 	reservation, err := rDao.GetById(ctx, reservationId)
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to find reservation by id")
-		return
+		return nil
 	}
 	instances, err := rDao.ListInstances(ctx, reservationId)
 	if err != nil {
 		logger.Warn().Err(err).Msg("Unable to get instances for reservation")
 	}
-	NotificationInstancesEvents := make([]kafka.NotificationEvent, len(instances))
+
+	events := make([]kafka.NotificationEvent, len(instances))
 	for i, instance := range instances {
-		marshalInstance, er := json.Marshal(instance)
+		payload, er := json.Marshal(kafka.NotificationInstance{
+			InstanceID: instance.InstanceID,
+			PublicDNS:  instance.Detail.PublicDNS,
+			PublicIPv4: instance.Detail.PublicIPv4,
+		})
 		if er != nil {
-			logger.Error().Err(err).Msg("Unable to marshal instance")
-			return
+			logger.Error().Err(er).Msg("Unable to marshal instance")
+			return nil
 		}
-		NotificationInstancesEvents[i] = kafka.NotificationEvent{Payload: marshalInstance}
+		events[i] = kafka.NotificationEvent{Payload: payload}
 	}
 
 	notificationMsg, err := kafka.NotificationMessage{
-		Context:   kafka.NotificationContext{Provider: reservation.Provider.String(), LaunchID: reservationId},
-		EventType: kafka.NotificationSuccessEventType, Events: NotificationInstancesEvents,
+		Context: kafka.NotificationContext{
+			LaunchID:      reservationId,
+			Provider:      reservation.Provider.String(),
+			InstanceCount: len(instances),
+		},
+		EventType: kafka.NotificationSuccessEventType,
+		Events:    events,
 	}.GenericMessage(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to create notification message")
-		return
-	}
-	logger.Info().Msgf("Sending notification message")
-	err = kafka.Send(ctx, &notificationMsg)
-	if err != nil {
-		logger.Error().Err(err).Msg("Unable to send notification message via kafka")
+		return nil
 	}
+	return &notificationMsg
 }
 
-func (x *client) FailedLaunch(ctx context.Context, reservationId int64, jobError error) {
+func (x *client) FailedLaunchMessage(ctx context.Context, reservationId int64, jobError error) *kafka.GenericMessage {
 	logger := zerolog.Ctx(ctx)
-	logger.Debug().Msg("Triggering a failed launch notification")
+	logger.Debug().Msg("Creating a failed launch notification")
 	rDao := dao.GetReservationDao(ctx)
 	reservation, err := rDao.GetById(ctx, reservationId)
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to find reservation by id")
-		return
+		return nil
 	}
-	marshalError, err := json.Marshal(kafka.NotificationError{Error: jobError.Error()})
+	payload, err := json.Marshal(kafka.NewNotificationError(jobError))
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to marshal error")
-		return
+		return nil
 	}
 
-	notificationEvent := []kafka.NotificationEvent{{Payload: marshalError}}
-	notificationMsg, err := kafka.NotificationMessage{Context: reservation, EventType: kafka.NotificationFailureEventType, Events: notificationEvent}.GenericMessage(ctx)
+	notificationMsg, err := kafka.NotificationMessage{
+		Context: kafka.NotificationContext{
+			LaunchID:   reservationId,
+			Provider:   reservation.Provider.String(),
+			FailedStep: reservation.RunningStepTitle(),
+		},
+		EventType: kafka.NotificationFailureEventType,
+		Events:    []kafka.NotificationEvent{{Payload: payload}},
+	}.GenericMessage(ctx)
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to create notification failure message")
+		return nil
+	}
+	return &notificationMsg
+}
+
+func (x *client) PubkeyExpiring(ctx context.Context, pubkey *models.Pubkey) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Triggering a pubkey expiring notification")
+	if pubkey.ExpiresAt == nil {
+		logger.Warn().Msgf("Pubkey %d has no expiration", pubkey.ID)
+		return
+	}
+
+	notificationMsg, err := kafka.NotificationMessage{
+		Context: kafka.NotificationPubkeyContext{
+			PubkeyID:    pubkey.ID,
+			Name:        pubkey.Name,
+			Fingerprint: pubkey.Fingerprint,
+			ExpiresAt:   *pubkey.ExpiresAt,
+		},
+		EventType: kafka.NotificationPubkeyEventType,
+		Events:    []kafka.NotificationEvent{},
+	}.GenericMessage(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to create notification pubkey message")
 		return
 	}
 	logger.Info().Msg("Sending notification message")
@@ -91,3 +130,41 @@ func (x *client) FailedLaunch(ctx context.Context, reservationId int64, jobError
 		logger.Error().Err(err).Msg("Unable to send notification message via kafka")
 	}
 }
+
+func (x *client) ExpiryDigest(ctx context.Context, days int, items []kafka.NotificationDigestItem) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Triggering an expiry digest notification")
+
+	digest := kafka.NotificationDigestContext{Days: days}
+	events := make([]kafka.NotificationEvent, len(items))
+	for i, item := range items {
+		switch item.Type {
+		case kafka.NotificationDigestPubkey:
+			digest.PubkeyCount++
+		case kafka.NotificationDigestReservation:
+			digest.ReservationCount++
+		}
+
+		payload, err := json.Marshal(item)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to marshal digest item")
+			return
+		}
+		events[i] = kafka.NotificationEvent{Payload: payload}
+	}
+
+	notificationMsg, err := kafka.NotificationMessage{
+		Context:   digest,
+		EventType: kafka.NotificationDigestEventType,
+		Events:    events,
+	}.GenericMessage(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to create notification digest message")
+		return
+	}
+	logger.Info().Msgf("Sending expiry digest notification with %d items", len(items))
+	err = kafka.Send(ctx, &notificationMsg)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to send notification message via kafka")
+	}
+}
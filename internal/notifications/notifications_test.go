@@ -0,0 +1,95 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type launchMessage struct {
+	Bundle      string                    `json:"bundle"`
+	Application string                    `json:"application"`
+	EventType   string                    `json:"event_type"`
+	OrgId       string                    `json:"org_id"`
+	Context     kafka.NotificationContext `json:"context"`
+	Events      []kafka.NotificationEvent `json:"events"`
+}
+
+func prepareReservation(t *testing.T, step int32) (context.Context, int64) {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	reservation := &models.AWSReservation{Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.Steps = 2
+	reservation.StepTitles = []string{"Ensure public key", "Launch instance(s)"}
+	reservation.Step = step
+	require.NoError(t, stubs.AddAWSReservation(ctx, reservation))
+	return ctx, reservation.ID
+}
+
+func decodeMessage(t *testing.T, msg *kafka.GenericMessage) launchMessage {
+	t.Helper()
+	require.NotNil(t, msg)
+
+	var result launchMessage
+	require.NoError(t, json.Unmarshal(msg.Value, &result))
+	assert.Equal(t, "rhel", result.Bundle)
+	assert.Equal(t, "image-builder", result.Application)
+	assert.Equal(t, identity.DefaultOrgId, result.OrgId)
+	return result
+}
+
+func TestSuccessfulLaunchMessage(t *testing.T) {
+	ctx, id := prepareReservation(t, 2)
+	rDao := dao.GetReservationDao(ctx)
+	require.NoError(t, rDao.CreateInstance(ctx, &models.ReservationInstance{
+		ReservationID: id,
+		InstanceID:    "i-1",
+		Detail:        models.ReservationInstanceDetail{PublicDNS: "ec2-1.compute.amazonaws.com", PublicIPv4: "10.0.0.1"},
+	}))
+	require.NoError(t, rDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: id, InstanceID: "i-2"}))
+
+	msg := decodeMessage(t, (&client{}).SuccessfulLaunchMessage(ctx, id))
+	assert.Equal(t, kafka.NotificationSuccessEventType, msg.EventType)
+	assert.Equal(t, kafka.NotificationContext{LaunchID: id, Provider: "aws", InstanceCount: 2}, msg.Context)
+	require.Len(t, msg.Events, 2)
+	assert.JSONEq(t, `{"instance_id":"i-1","public_dns":"ec2-1.compute.amazonaws.com","public_ipv4":"10.0.0.1"}`, string(msg.Events[0].Payload))
+	assert.JSONEq(t, `{"instance_id":"i-2"}`, string(msg.Events[1].Payload))
+}
+
+func TestFailedLaunchMessage(t *testing.T) {
+	ctx, id := prepareReservation(t, 1)
+
+	msg := decodeMessage(t, (&client{}).FailedLaunchMessage(ctx, id, errors.New("cannot run instances: quota exceeded\nrequest id: 123")))
+	assert.Equal(t, kafka.NotificationFailureEventType, msg.EventType)
+	assert.Equal(t, kafka.NotificationContext{LaunchID: id, Provider: "aws", FailedStep: "Launch instance(s)"}, msg.Context)
+	require.Len(t, msg.Events, 1)
+
+	var payload kafka.NotificationError
+	require.NoError(t, json.Unmarshal(msg.Events[0].Payload, &payload))
+	assert.Equal(t, "cannot run instances: quota exceeded", payload.Summary)
+	assert.Equal(t, "cannot run instances: quota exceeded\nrequest id: 123", payload.Error)
+}
+
+func TestNotificationErrorSummaryLength(t *testing.T) {
+	payload := kafka.NewNotificationError(errors.New(strings.Repeat("x", 500)))
+	assert.Len(t, []rune(payload.Summary), 200)
+	assert.True(t, strings.HasSuffix(payload.Summary, "…"))
+	assert.Len(t, payload.Error, 500)
+}
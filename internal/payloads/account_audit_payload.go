@@ -0,0 +1,66 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// AccountMergeRequest moves all records of the account from the URL to the target account.
+type AccountMergeRequest struct {
+	TargetAccountID int64 `json:"target_account_id" yaml:"target_account_id"`
+}
+
+// AccountRenameRequest changes organization ID of the account from the URL.
+type AccountRenameRequest struct {
+	OrgID string `json:"org_id" yaml:"org_id"`
+}
+
+// See models.AccountAudit
+type AccountAuditResponse struct {
+	ID                   int64     `json:"id" yaml:"id"`
+	Operation            string    `json:"operation" yaml:"operation"`
+	AccountID            int64     `json:"account_id" yaml:"account_id"`
+	SourceAccountID      int64     `json:"source_account_id,omitempty" yaml:"source_account_id,omitempty"`
+	OldOrgID             string    `json:"old_org_id" yaml:"old_org_id"`
+	NewOrgID             string    `json:"new_org_id" yaml:"new_org_id"`
+	Pubkeys              int64     `json:"pubkeys" yaml:"pubkeys"`
+	Reservations         int64     `json:"reservations" yaml:"reservations"`
+	ReservationTemplates int64     `json:"reservation_templates" yaml:"reservation_templates"`
+	RequestedBy          string    `json:"requested_by" yaml:"requested_by"`
+	CreatedAt            time.Time `json:"created_at" yaml:"created_at"`
+}
+
+func (p *AccountMergeRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	v.requireID("target_account_id", p.TargetAccountID)
+	return v.err()
+}
+
+func (p *AccountRenameRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	v.requireString("org_id", p.OrgID)
+	return v.err()
+}
+
+func (p *AccountAuditResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewAccountAuditResponse(audit *models.AccountAudit) render.Renderer {
+	return &AccountAuditResponse{
+		ID:                   audit.ID,
+		Operation:            audit.Operation,
+		AccountID:            audit.AccountID,
+		SourceAccountID:      audit.SourceAccountID,
+		OldOrgID:             audit.OldOrgID,
+		NewOrgID:             audit.NewOrgID,
+		Pubkeys:              audit.Pubkeys,
+		Reservations:         audit.Reservations,
+		ReservationTemplates: audit.ReservationTemplates,
+		RequestedBy:          audit.RequestedBy,
+		CreatedAt:            audit.CreatedAt,
+	}
+}
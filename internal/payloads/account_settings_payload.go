@@ -0,0 +1,108 @@
+package payloads
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.AccountSettings
+type AccountSettingsRequest struct {
+	// Default AWS region, used when a reservation does not specify one.
+	AWSRegion string `json:"aws_region,omitempty" yaml:"aws_region,omitempty"`
+
+	// Default Azure location, used when a reservation does not specify one.
+	AzureLocation string `json:"azure_location,omitempty" yaml:"azure_location,omitempty"`
+
+	// Default GCP zone, used when a reservation does not specify one.
+	GCPZone string `json:"gcp_zone,omitempty" yaml:"gcp_zone,omitempty"`
+
+	// Default pubkey ID, used when a reservation does not specify one.
+	PubkeyID *int64 `json:"pubkey_id,omitempty" yaml:"pubkey_id,omitempty"`
+
+	// Providers reservations can be created for: aws, azure or gcp. All providers are allowed
+	// when empty.
+	AllowedProviders []string `json:"allowed_providers,omitempty" yaml:"allowed_providers,omitempty"`
+
+	// Maximum estimated spend of instances of running reservations in US dollars per hour,
+	// reservations which would exceed it are rejected. Spend is not capped when zero or omitted.
+	MaxHourlySpend float64 `json:"max_hourly_spend,omitempty" yaml:"max_hourly_spend,omitempty"`
+
+	// Keys of tags which must be set on new reservations, e.g. "cost-center". Standard tags
+	// set by the service cannot be required.
+	RequiredTags []string `json:"required_tags,omitempty" yaml:"required_tags,omitempty"`
+}
+
+// See models.AccountSettings
+type AccountSettingsResponse struct {
+	AWSRegion        string    `json:"aws_region" yaml:"aws_region"`
+	AzureLocation    string    `json:"azure_location" yaml:"azure_location"`
+	GCPZone          string    `json:"gcp_zone" yaml:"gcp_zone"`
+	PubkeyID         *int64    `json:"pubkey_id,omitempty" yaml:"pubkey_id,omitempty"`
+	AllowedProviders []string  `json:"allowed_providers" yaml:"allowed_providers"`
+	MaxHourlySpend   float64   `json:"max_hourly_spend" yaml:"max_hourly_spend"`
+	RequiredTags     []string  `json:"required_tags" yaml:"required_tags"`
+	UpdatedAt        time.Time `json:"updated_at" yaml:"updated_at"`
+}
+
+func (p *AccountSettingsRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	for _, provider := range p.AllowedProviders {
+		v.oneOf("allowed_providers", strings.ToLower(provider), "aws", "azure", "gcp")
+	}
+	if p.MaxHourlySpend < 0 {
+		v.add("max_hourly_spend", ConstraintMin, "max_hourly_spend must not be negative")
+	}
+	for _, key := range p.RequiredTags {
+		v.tagKey("required_tags", key)
+	}
+	return v.err()
+}
+
+func (p *AccountSettingsResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// NewModel returns settings model with lower-case provider names, the pubkey is not validated.
+func (p *AccountSettingsRequest) NewModel() *models.AccountSettings {
+	providers := make([]string, len(p.AllowedProviders))
+	for i, provider := range p.AllowedProviders {
+		providers[i] = strings.ToLower(provider)
+	}
+
+	return &models.AccountSettings{
+		AWSRegion:        p.AWSRegion,
+		AzureLocation:    p.AzureLocation,
+		GCPZone:          p.GCPZone,
+		PubkeyID:         p.PubkeyID,
+		AllowedProviders: providers,
+		MaxHourlySpend:   p.MaxHourlySpend,
+		RequiredTags:     p.RequiredTags,
+	}
+}
+
+func NewAccountSettingsResponse(settings *models.AccountSettings) render.Renderer {
+	providers := settings.AllowedProviders
+	if providers == nil {
+		providers = []string{}
+	}
+
+	requiredTags := settings.RequiredTags
+	if requiredTags == nil {
+		requiredTags = []string{}
+	}
+
+	return &AccountSettingsResponse{
+		AWSRegion:        settings.AWSRegion,
+		AzureLocation:    settings.AzureLocation,
+		GCPZone:          settings.GCPZone,
+		PubkeyID:         settings.PubkeyID,
+		AllowedProviders: providers,
+		MaxHourlySpend:   settings.MaxHourlySpend,
+		RequiredTags:     requiredTags,
+		UpdatedAt:        settings.UpdatedAt,
+	}
+}
@@ -0,0 +1,47 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// AdminReservationResponse is a reservation of any account for the internal admin API.
+type AdminReservationResponse struct {
+	GenericReservationResponsePayload `yaml:",inline"`
+
+	// Account the reservation belongs to.
+	AccountID int64 `json:"account_id" yaml:"account_id"`
+
+	// Time when the reservation was deleted by the user.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty"`
+
+	// Instances created by the reservation, only set for reservation detail.
+	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances,omitempty"`
+}
+
+func (p *AdminReservationResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewAdminReservationResponse(reservation *models.Reservation, instances []*models.ReservationInstance) render.Renderer {
+	response := &AdminReservationResponse{
+		GenericReservationResponsePayload: *reservationResponseMapper(reservation),
+		AccountID:                         reservation.AccountID,
+		DeletedAt:                         reservation.DeletedAt,
+	}
+	for _, inst := range instances {
+		response.Instances = append(response.Instances, InstanceResponse{InstanceID: inst.InstanceID, Detail: inst.Detail})
+	}
+	return response
+}
+
+func NewAdminReservationListResponse(reservations []*models.Reservation) []render.Renderer {
+	list := make([]render.Renderer, len(reservations))
+	for i, reservation := range reservations {
+		list[i] = NewAdminReservationResponse(reservation, nil)
+	}
+	return list
+}
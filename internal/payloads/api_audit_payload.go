@@ -0,0 +1,50 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.APIAudit
+type APIAuditResponse struct {
+	ID         int64     `json:"id" yaml:"id"`
+	AccountID  int64     `json:"account_id" yaml:"account_id"`
+	OrgID      string    `json:"org_id" yaml:"org_id"`
+	Principal  string    `json:"principal" yaml:"principal"`
+	Method     string    `json:"method" yaml:"method"`
+	Route      string    `json:"route" yaml:"route"`
+	ResourceID string    `json:"resource_id,omitempty" yaml:"resource_id,omitempty"`
+	Status     int       `json:"status" yaml:"status"`
+	TraceID    string    `json:"trace_id,omitempty" yaml:"trace_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at" yaml:"created_at"`
+}
+
+func (p *APIAuditResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewAPIAuditResponse(audit *models.APIAudit) render.Renderer {
+	return &APIAuditResponse{
+		ID:         audit.ID,
+		AccountID:  audit.AccountID,
+		OrgID:      audit.OrgID,
+		Principal:  audit.Principal,
+		Method:     audit.Method,
+		Route:      audit.Route,
+		ResourceID: audit.ResourceID,
+		Status:     audit.Status,
+		TraceID:    audit.TraceID,
+		CreatedAt:  audit.CreatedAt,
+	}
+}
+
+func NewAPIAuditListResponse(audits []*models.APIAudit) []render.Renderer {
+	list := make([]render.Renderer, len(audits))
+	for i, audit := range audits {
+		list[i] = NewAPIAuditResponse(audit)
+	}
+	return list
+}
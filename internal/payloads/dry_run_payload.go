@@ -0,0 +1,41 @@
+package payloads
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// DryRunResponse is the result of a reservation dry run, the reservation is not created.
+type DryRunResponse struct {
+	// Provider of the reservation: aws, azure or gcp.
+	Provider string `json:"provider" yaml:"provider"`
+
+	// True when the cloud provider accepted the launch.
+	Passed bool `json:"passed" yaml:"passed"`
+
+	// Error code of the cloud provider, e.g. UnauthorizedOperation or QUOTA_EXCEEDED.
+	Code string `json:"code,omitempty" yaml:"code"`
+
+	// Error message of the cloud provider.
+	Message string `json:"message,omitempty" yaml:"message"`
+
+	// Permissions or actions the credentials of the source are missing, when the provider reports them.
+	MissingPermissions []string `json:"missing_permissions,omitempty" yaml:"missing_permissions"`
+}
+
+func (s *DryRunResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewDryRunResponse(provider models.ProviderType, result *clients.DryRunResult) render.Renderer {
+	return &DryRunResponse{
+		Provider:           provider.String(),
+		Passed:             result.Passed,
+		Code:               result.Code,
+		Message:            result.Message,
+		MissingPermissions: result.MissingPermissions,
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/i18n"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/version"
 	"github.com/go-chi/render"
@@ -34,6 +36,9 @@ type ResponseError struct {
 	// full root cause
 	Error string `json:"error" yaml:"error"`
 
+	// request id of a backend service or cloud provider (if provided)
+	RequestId string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+
 	// build commit
 	Version string `json:"version" yaml:"version"`
 
@@ -42,6 +47,30 @@ type ResponseError struct {
 
 	// environment (prod or stage or ephemeral)
 	Environment string `json:"environment,omitempty" yaml:"environment"`
+
+	// invalid fields of the request payload (if any)
+	Fields []FieldViolation `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// RBAC permission the caller is missing (if any)
+	MissingPermission string `json:"missing_permission,omitempty" yaml:"missing_permission,omitempty"`
+
+	// cloud provider quota exhausted by the request (if any)
+	ExhaustedQuota string `json:"exhausted_quota,omitempty" yaml:"exhausted_quota,omitempty"`
+
+	// estimated hourly spend exceeding the spend cap of the account (if any)
+	SpendCap *SpendCapViolation `json:"spend_cap,omitempty" yaml:"spend_cap,omitempty"`
+}
+
+// SpendCapViolation is the estimated spend of a rejected reservation in US dollars per hour.
+type SpendCapViolation struct {
+	// spend cap from account settings
+	MaxHourlySpend float64 `json:"max_hourly_spend" yaml:"max_hourly_spend"`
+
+	// estimated spend of instances of pending and successful reservations
+	RunningHourlySpend float64 `json:"running_hourly_spend" yaml:"running_hourly_spend"`
+
+	// estimated spend of instances of the rejected reservation
+	RequestedHourlySpend float64 `json:"requested_hourly_spend" yaml:"requested_hourly_spend"`
 }
 
 func (e *ResponseError) Render(_ http.ResponseWriter, r *http.Request) error {
@@ -50,6 +79,22 @@ func (e *ResponseError) Render(_ http.ResponseWriter, r *http.Request) error {
 }
 
 func NewResponseError(ctx context.Context, status int, userMsg string, err error) *ResponseError {
+	if userMsg == "" {
+		// take only part up to the first colon to avoid unique ids (UUIDs, database IDs etc)
+		userMsg = strings.SplitN(err.Error(), ":", 2)[0]
+	}
+	return newResponseError(ctx, status, userMsg, i18n.T(ctx, userMsg), err)
+}
+
+// newResponseErrorf creates an error with a message in format "Prefix: %s" where both the
+// format and the message are translated.
+func newResponseErrorf(ctx context.Context, status int, format, message string, err error) *ResponseError {
+	return newResponseError(ctx, status, fmt.Sprintf(format, message), i18n.Tf(ctx, format, message), err)
+}
+
+// newResponseError logs the message in English, the response carries the translated message
+// while the root cause is never translated.
+func newResponseError(ctx context.Context, status int, userMsg, translatedMsg string, err error) *ResponseError {
 	var event *zerolog.Event
 	var strError string
 
@@ -58,29 +103,35 @@ func NewResponseError(ctx context.Context, status int, userMsg string, err error
 	} else {
 		event = zerolog.Ctx(ctx).Error().Stack()
 	}
+	var requestId string
 	if err != nil {
 		event = event.Err(err)
 		strError = err.Error()
-	}
-	if userMsg == "" {
-		// take only part up to the first colon to avoid unique ids (UUIDs, database IDs etc)
-		userMsg = strings.SplitN(err.Error(), ":", 2)[0]
+		requestId = findRequestId(err)
 	}
 	event.Msg(userMsg)
 
+	if status >= 500 {
+		reportErr := err
+		if reportErr == nil {
+			reportErr = errors.New(userMsg)
+		}
+		logging.ReportError(ctx, reportErr, map[string]string{"status": strconv.Itoa(status)})
+	}
+
 	return &ResponseError{
 		HTTPStatusCode: status,
-		Message:        userMsg,
+		Message:        translatedMsg,
 		TraceId:        logging.TraceId(ctx),
 		Error:          strError,
+		RequestId:      requestId,
 		Version:        version.BuildCommit,
 		BuildTime:      version.BuildTime,
 	}
 }
 
 func NewInvalidRequestError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("Invalid request: %s", message)
-	return NewResponseError(ctx, http.StatusBadRequest, message, err)
+	return newResponseErrorf(ctx, http.StatusBadRequest, "Invalid request: %s", message, err)
 }
 
 func NewWrongArchitectureUserError(ctx context.Context, err error) *ResponseError {
@@ -100,28 +151,78 @@ type userPayload struct {
 	message string
 }
 
-var errStatus = map[error]*userPayload{
-	// generic errors
-	clients.HttpClientErr:     {500, "unknown backend client error"},
-	clients.BadRequestErr:     {400, "bad request; returned from a backend service"},
-	clients.NotFoundErr:       {404, "not found; returned from a backend service"},
-	clients.UnauthorizedErr:   {401, "unauthorized; returned from a backend service"},
-	clients.ForbiddenErr:      {403, "forbidden; returned from a backend service"},
-	clients.Non2xxResponseErr: {500, "unsuccessful response;returned from a backend service"},
+type errorStatus struct {
+	err     error
+	payload *userPayload
+}
 
+// errStatus is ordered from specific to generic errors, the first error matching via errors.Is
+// wins. Specific errors often wrap generic errors (e.g. CloneNotFoundErr wraps NotFoundErr).
+var errStatus = []errorStatus{
 	// image builder specific errors
-	httpClients.CloneNotFoundErr:        {404, "image builder could not find compose clone"},
-	httpClients.ComposeNotFoundErr:      {404, "image builder could not find compose"},
-	httpClients.ImageStatusErr:          {400, "image builder compose not successfully built"},
-	httpClients.UnknownImageTypeErr:     {400, "wrong type of image builder compose"},
-	httpClients.UploadStatusErr:         {400, "wrong compose status of image builder compose"},
-	httpClients.ImageRequestNotFoundErr: {404, "image builder compose request not found"},
+	{httpClients.CloneNotFoundErr, &userPayload{404, "image builder could not find compose clone"}},
+	{httpClients.ComposeNotFoundErr, &userPayload{404, "image builder could not find compose"}},
+	{httpClients.ImageStatusErr, &userPayload{400, "image builder compose not successfully built"}},
+	{httpClients.UnknownImageTypeErr, &userPayload{400, "wrong type of image builder compose"}},
+	{httpClients.UploadStatusErr, &userPayload{400, "wrong compose status of image builder compose"}},
+	{httpClients.ImageRequestNotFoundErr, &userPayload{404, "image builder compose request not found"}},
 
 	// sources specific errors
-	clients.UnknownAuthenticationTypeErr: {500, "unknown authentication type"},
-	clients.UnknownProviderErr:           {500, "unknown provider type"},
-	clients.MissingProvisioningSources:   {500, "backend service missing provisioning source"},
-	httpClients.NotEvenErr:               {500, "client arguments error"},
+	{clients.UnknownAuthenticationTypeErr, &userPayload{500, "unknown authentication type"}},
+	{clients.UnknownProviderErr, &userPayload{500, "unknown provider type"}},
+	{clients.MissingProvisioningSources, &userPayload{500, "backend service missing provisioning source"}},
+	{httpClients.NotEvenErr, &userPayload{500, "client arguments error"}},
+
+	// AWS specific errors
+	{httpClients.ExternalIDRequiredErr, &userPayload{400, "organization requires an external ID in the AWS source authentication"}},
+	{clients.UnsupportedAWSPartitionErr, &userPayload{400, "unsupported AWS partition of the source role ARN"}},
+	{clients.AWSPartitionMismatchErr, &userPayload{400, "region is not in the AWS partition of the source"}},
+	{clients.AWSPartitionNotConfiguredErr, &userPayload{400, "AWS partition of the source is not supported by this deployment"}},
+
+	// Azure specific errors
+	{clients.UnknownAzureCloudErr, &userPayload{400, "unknown Azure cloud of the source"}},
+	{clients.AzureCloudNotConfiguredErr, &userPayload{400, "Azure cloud of the source is not supported by this deployment"}},
+
+	// key host specific errors
+	{clients.UnknownKeyHostErr, &userPayload{400, "unknown key host site, use github or gitlab"}},
+	{clients.InvalidKeyHostUserErr, &userPayload{400, "invalid key host username"}},
+
+	// generic errors
+	{httpClients.CircuitOpenErr, &userPayload{503, "backend service temporarily unavailable"}},
+	{clients.BadRequestErr, &userPayload{400, "bad request; returned from a backend service"}},
+	{clients.NotFoundErr, &userPayload{404, "not found; returned from a backend service"}},
+	{clients.UnauthorizedErr, &userPayload{401, "unauthorized; returned from a backend service"}},
+	{clients.ForbiddenErr, &userPayload{403, "forbidden; returned from a backend service"}},
+	{clients.Non2xxResponseErr, &userPayload{500, "unsuccessful response;returned from a backend service"}},
+	{clients.HttpClientErr, &userPayload{500, "unknown backend client error"}},
+}
+
+type providerErrorKey struct {
+	provider string
+	kind     error
+}
+
+var providerErrStatus = map[providerErrorKey]*userPayload{
+	{"AWS", httpClients.ProviderUnauthorizedErr}:         {403, "AWS account does not permit the operation, check permissions of the provisioning role"},
+	{"AWS", httpClients.ProviderQuotaExceededErr}:        {422, "AWS account instance or vCPU limit reached, request a limit increase from AWS"},
+	{"AWS", httpClients.ProviderUnsupportedOperationErr}: {400, "operation not supported by AWS for the selected instance type, image or region"},
+
+	{"Azure", httpClients.ProviderUnauthorizedErr}:         {403, "Azure subscription does not permit the operation, check role assignments of the provisioning application"},
+	{"Azure", httpClients.ProviderQuotaExceededErr}:        {422, "Azure subscription quota reached, request a quota increase from Azure"},
+	{"Azure", httpClients.ProviderUnsupportedOperationErr}: {400, "operation not supported by Azure for the selected instance size or location"},
+
+	{"GCP", httpClients.ProviderUnauthorizedErr}:         {403, "GCP project does not permit the operation, check roles of the provisioning service account"},
+	{"GCP", httpClients.ProviderQuotaExceededErr}:        {422, "GCP project quota reached, request a quota increase from Google"},
+	{"GCP", httpClients.ProviderUnsupportedOperationErr}: {400, "operation not supported by GCP for the selected machine type or zone"},
+}
+
+// findProviderPayload returns a user payload for common errors of cloud provider APIs.
+func findProviderPayload(err error) *userPayload {
+	providerErr := httpClients.AsProviderError(err)
+	if providerErr == nil {
+		return nil
+	}
+	return providerErrStatus[providerErrorKey{provider: providerErr.Provider, kind: providerErr.Kind}]
 }
 
 func findUserPayload(err error) *userPayload {
@@ -129,11 +230,28 @@ func findUserPayload(err error) *userPayload {
 		return nil
 	}
 
-	if result, ok := errStatus[err]; ok {
-		return result
+	if payload := findProviderPayload(err); payload != nil {
+		return payload
 	}
 
-	return findUserPayload(errors.Unwrap(err))
+	for _, status := range errStatus {
+		if errors.Is(err, status.err) {
+			return status.payload
+		}
+	}
+	return nil
+}
+
+// findRequestId returns request ID of a backend service or cloud provider from the error chain.
+func findRequestId(err error) string {
+	var clientErr *clients.ClientError
+	if errors.As(err, &clientErr) && clientErr.RequestID != "" {
+		return clientErr.RequestID
+	}
+	if providerErr := httpClients.AsProviderError(err); providerErr != nil {
+		return providerErr.RequestID
+	}
+	return ""
 }
 
 func NewClientError(ctx context.Context, err error) *ResponseError {
@@ -150,46 +268,93 @@ func NewClientError(ctx context.Context, err error) *ResponseError {
 }
 
 func NewNotFoundError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("Not found: %s", message)
-	return NewResponseError(ctx, http.StatusNotFound, message, err)
+	return newResponseErrorf(ctx, http.StatusNotFound, "Not found: %s", message, err)
+}
+
+// NewMissingPermissionError returns forbidden error naming the RBAC permission.
+func NewMissingPermissionError(ctx context.Context, permission string) *ResponseError {
+	e := newResponseErrorf(ctx, http.StatusForbidden, "Missing permission: %s", permission, nil)
+	e.MissingPermission = permission
+	return e
+}
+
+// NewQuotaExceededError returns bad request error naming the provider quota which cannot fit
+// the request.
+func NewQuotaExceededError(ctx context.Context, quota string, err error) *ResponseError {
+	e := newResponseErrorf(ctx, http.StatusBadRequest, "Quota exceeded: %s", quota, err)
+	e.ExhaustedQuota = quota
+	return e
+}
+
+// NewSpendCapExceededError returns forbidden error with estimated spend which would exceed the
+// spend cap of the account.
+func NewSpendCapExceededError(ctx context.Context, violation *SpendCapViolation, err error) *ResponseError {
+	message := fmt.Sprintf("estimated %.2f USD per hour, limit is %.2f USD per hour",
+		violation.RunningHourlySpend+violation.RequestedHourlySpend, violation.MaxHourlySpend)
+	e := newResponseErrorf(ctx, http.StatusForbidden, "Spend cap exceeded: %s", message, err)
+	e.SpendCap = violation
+	return e
+}
+
+// NewPrincipalNotAllowedError returns forbidden error for systems and service accounts
+// refused by a principal policy.
+func NewPrincipalNotAllowedError(ctx context.Context, principalType string, err error) *ResponseError {
+	return newResponseErrorf(ctx, http.StatusForbidden, "Principal not allowed: %s", principalType, err)
+}
+
+// NewProviderNotAllowedError returns forbidden error for providers restricted by account settings.
+func NewProviderNotAllowedError(ctx context.Context, provider string, err error) *ResponseError {
+	return newResponseErrorf(ctx, http.StatusForbidden, "Provider not allowed: %s", provider, err)
+}
+
+// NewNotOwnerError returns forbidden error for modifications of resources shared by another
+// user of the organization.
+func NewNotOwnerError(ctx context.Context, resource string, err error) *ResponseError {
+	return newResponseErrorf(ctx, http.StatusForbidden, "Owned by another user: %s", resource, err)
+}
+
+// NewRateLimitError returns too many requests error naming the endpoint class.
+func NewRateLimitError(ctx context.Context, class string) *ResponseError {
+	return newResponseErrorf(ctx, http.StatusTooManyRequests, "Rate limit exceeded: %s", class, nil)
 }
 
 func NewEnqueueTaskError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("Task enqueue error: %s", message)
-	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
+	return newResponseErrorf(ctx, http.StatusInternalServerError, "Task enqueue error: %s", message, err)
 }
 
 func NewDAOError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("DAO error: %s", message)
-	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
+	return newResponseErrorf(ctx, http.StatusInternalServerError, "DAO error: %s", message, err)
 }
 
 func NewRenderError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("Rendering error: %s", message)
-	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
+	return newResponseErrorf(ctx, http.StatusInternalServerError, "Rendering error: %s", message, err)
 }
 
 func NewURLParsingError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("URL parsing error: %s", message)
-	return NewResponseError(ctx, http.StatusBadRequest, message, err)
+	return newResponseErrorf(ctx, http.StatusBadRequest, "URL parsing error: %s", message, err)
 }
 
 func NewStatusError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("Status error: %s", message)
-	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
+	return newResponseErrorf(ctx, http.StatusInternalServerError, "Status error: %s", message, err)
 }
 
 func NewAWSError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("AWS API error: %s", message)
-	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
+	if payload := findProviderPayload(err); payload != nil {
+		return NewResponseError(ctx, payload.code, payload.message, err)
+	}
+	return newResponseErrorf(ctx, http.StatusInternalServerError, "AWS API error: %s", message, err)
 }
 
 func NewAzureError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("Azure API error: %s", message)
-	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
+	if payload := findProviderPayload(err); payload != nil {
+		return NewResponseError(ctx, payload.code, payload.message, err)
+	}
+	return newResponseErrorf(ctx, http.StatusInternalServerError, "Azure API error: %s", message, err)
 }
 
 func NewGCPError(ctx context.Context, message string, err error) *ResponseError {
-	message = fmt.Sprintf("Google API error: %s", message)
-	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
+	if payload := findProviderPayload(err); payload != nil {
+		return NewResponseError(ctx, payload.code, payload.message, err)
+	}
+	return newResponseErrorf(ctx, http.StatusInternalServerError, "Google API error: %s", message, err)
 }
@@ -1,11 +1,16 @@
 package payloads
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/i18n"
+	"github.com/aws/smithy-go"
 )
 
 func TestFindUserPayload(t *testing.T) {
@@ -27,6 +32,18 @@ func TestFindUserPayload(t *testing.T) {
 			clients.MissingProvisioningSources,
 			&userPayload{500, "backend service missing provisioning source"},
 		},
+		{
+			fmt.Errorf("list sources: %w", &clients.ClientError{Kind: clients.ForbiddenErr, Provider: "sources", Status: 403}),
+			&userPayload{403, "forbidden; returned from a backend service"},
+		},
+		{
+			fmt.Errorf("launch: %w", &smithy.OperationError{Err: &smithy.GenericAPIError{Code: "UnauthorizedOperation"}}),
+			&userPayload{403, "AWS account does not permit the operation, check permissions of the provisioning role"},
+		},
+		{
+			errors.New("unknown"),
+			nil,
+		},
 	}
 
 	for _, tc := range tests {
@@ -36,3 +53,46 @@ func TestFindUserPayload(t *testing.T) {
 		}
 	}
 }
+
+func TestNewResponseErrorTranslated(t *testing.T) {
+	ctx := i18n.WithLanguage(context.Background(), "es")
+
+	response := NewInvalidRequestError(ctx, "create pubkey", clients.UnknownProviderErr)
+	if response.Message != "Solicitud no válida: create pubkey" {
+		t.Fatalf("unexpected message: %s", response.Message)
+	}
+	if response.Error != clients.UnknownProviderErr.Error() {
+		t.Fatalf("root cause must not be translated: %s", response.Error)
+	}
+
+	response = NewClientError(ctx, clients.UnknownProviderErr)
+	if response.Message != "tipo de proveedor desconocido" {
+		t.Fatalf("unexpected message: %s", response.Message)
+	}
+}
+
+func TestNewClientErrorRequestId(t *testing.T) {
+	err := fmt.Errorf("get source: %w", &clients.ClientError{Kind: clients.NotFoundErr, Provider: "sources", Status: 404, RequestID: "req-1"})
+	response := NewClientError(context.Background(), err)
+	if response.HTTPStatusCode != 404 || response.RequestId != "req-1" {
+		t.Fatalf("unexpected response: %d %s", response.HTTPStatusCode, response.RequestId)
+	}
+}
+
+func TestNewAWSErrorProviderPayload(t *testing.T) {
+	ctx := context.Background()
+
+	err := fmt.Errorf("cannot run instances: %w", &smithy.GenericAPIError{Code: "InstanceLimitExceeded"})
+	response := NewAWSError(ctx, "unable to launch instances", err)
+	if response.HTTPStatusCode != 422 {
+		t.Fatalf("unexpected status: %d", response.HTTPStatusCode)
+	}
+	if response.Error != err.Error() {
+		t.Fatalf("root cause must be kept: %s", response.Error)
+	}
+
+	response = NewAWSError(ctx, "unable to launch instances", errors.New("unknown"))
+	if response.HTTPStatusCode != 500 || response.Message != "AWS API error: unable to launch instances" {
+		t.Fatalf("unexpected response: %d %s", response.HTTPStatusCode, response.Message)
+	}
+}
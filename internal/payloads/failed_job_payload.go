@@ -0,0 +1,66 @@
+package payloads
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.FailedJob
+type FailedJobResponse struct {
+	ID         int64           `json:"id" yaml:"id"`
+	JobID      string          `json:"job_id" yaml:"job_id"`
+	JobType    string          `json:"job_type" yaml:"job_type"`
+	AccountID  int64           `json:"account_id" yaml:"account_id"`
+	Args       json.RawMessage `json:"args" yaml:"args"`
+	Error      string          `json:"error" yaml:"error"`
+	Attempts   int             `json:"attempts" yaml:"attempts"`
+	FailedAt   time.Time       `json:"failed_at" yaml:"failed_at"`
+	ReplayedAt *time.Time      `json:"replayed_at,omitempty" yaml:"replayed_at,omitempty"`
+}
+
+// FailedJobReplayResponse contains ID of the newly enqueued job.
+type FailedJobReplayResponse struct {
+	ID    int64  `json:"id" yaml:"id"`
+	JobID string `json:"job_id" yaml:"job_id"`
+}
+
+func (p *FailedJobResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func (p *FailedJobReplayResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewFailedJobResponse(job *models.FailedJob) render.Renderer {
+	return &FailedJobResponse{
+		ID:         job.ID,
+		JobID:      job.JobID,
+		JobType:    job.JobType,
+		AccountID:  job.AccountID,
+		Args:       job.Args,
+		Error:      job.Error,
+		Attempts:   job.Attempts,
+		FailedAt:   job.FailedAt,
+		ReplayedAt: job.ReplayedAt,
+	}
+}
+
+func NewFailedJobListResponse(jobs []*models.FailedJob) []render.Renderer {
+	list := make([]render.Renderer, len(jobs))
+	for i, job := range jobs {
+		list[i] = NewFailedJobResponse(job)
+	}
+	return list
+}
+
+func NewFailedJobReplayResponse(id int64, jobId string) render.Renderer {
+	return &FailedJobReplayResponse{
+		ID:    id,
+		JobID: jobId,
+	}
+}
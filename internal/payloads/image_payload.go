@@ -0,0 +1,49 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/go-chi/render"
+)
+
+// See clients.Image
+type ImageResponse struct {
+	// Compose ID, use it as image ID in reservation requests
+	ID string `json:"id" yaml:"id"`
+
+	// Name of the image, can be empty
+	Name string `json:"name,omitempty" yaml:"name"`
+
+	// Distribution of the image (e.g. rhel-92)
+	Distribution string `json:"distribution" yaml:"distribution"`
+
+	// Architecture of the image (x86_64 or arm64)
+	Architecture string `json:"architecture" yaml:"architecture"`
+
+	// Provider the image was uploaded to (aws, azure or gcp)
+	Provider string `json:"provider" yaml:"provider"`
+
+	// Time of the image build request
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+func (s *ImageResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewListImagesResponse(imageList []*clients.Image) []render.Renderer {
+	list := make([]render.Renderer, len(imageList))
+	for i, image := range imageList {
+		list[i] = &ImageResponse{
+			ID:           image.ID,
+			Name:         image.Name,
+			Distribution: image.Distribution,
+			Architecture: image.Architecture.String(),
+			Provider:     image.Provider.String(),
+			CreatedAt:    image.CreatedAt,
+		}
+	}
+	return list
+}
@@ -0,0 +1,66 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.InstanceAction
+type InstanceActionRequest struct {
+	// Action to perform: stop, start or reboot. Azure VMs are deallocated when stopped.
+	Action string `json:"action" yaml:"action"`
+}
+
+// See models.InstanceAction
+type InstanceActionResponse struct {
+	ID            int64  `json:"id" yaml:"id"`
+	ReservationID int64  `json:"reservation_id" yaml:"reservation_id"`
+	InstanceID    string `json:"instance_id" yaml:"instance_id"`
+	Action        string `json:"action" yaml:"action"`
+
+	// Action state: pending, running, success or failed.
+	Status string `json:"status" yaml:"status"`
+
+	// Error of a failed action.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at" yaml:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" yaml:"finished_at,omitempty"`
+}
+
+func (p *InstanceActionRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	v.requireString("action", p.Action)
+	if p.Action != "" {
+		v.oneOf("action", p.Action, models.InstanceActionTypes()...)
+	}
+	return v.err()
+}
+
+func (p *InstanceActionResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewInstanceActionResponse(action *models.InstanceAction) render.Renderer {
+	return &InstanceActionResponse{
+		ID:            action.ID,
+		ReservationID: action.ReservationID,
+		InstanceID:    action.InstanceID,
+		Action:        string(action.Action),
+		Status:        string(action.Status),
+		Error:         action.Error,
+		CreatedAt:     action.CreatedAt,
+		FinishedAt:    action.FinishedAt,
+	}
+}
+
+func NewInstanceActionListResponse(actions []*models.InstanceAction) []render.Renderer {
+	list := make([]render.Renderer, len(actions))
+	for i, action := range actions {
+		list[i] = NewInstanceActionResponse(action)
+	}
+	return list
+}
@@ -0,0 +1,33 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/go-chi/render"
+)
+
+// InstanceConsoleResponse is the serial console output of a reservation instance.
+type InstanceConsoleResponse struct {
+	// Instance ID which has been created on a cloud provider.
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
+
+	// Console output: EC2 console output, Azure boot diagnostics serial log or GCP serial port 1.
+	Output string `json:"output" yaml:"output"`
+
+	// Time the output was last updated, not reported by GCP.
+	Timestamp *time.Time `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+}
+
+func (s *InstanceConsoleResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewInstanceConsoleResponse(instanceID string, output *clients.ConsoleOutput) render.Renderer {
+	return &InstanceConsoleResponse{
+		InstanceID: instanceID,
+		Output:     output.Output,
+		Timestamp:  output.Timestamp,
+	}
+}
@@ -0,0 +1,42 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/go-chi/render"
+)
+
+// See clients.InstanceDescription
+type InstanceStateResponse struct {
+	// Instance ID which has been created on a cloud provider.
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
+
+	// Provider specific state (e.g. running, stopped), not_found when the instance no longer exists.
+	State string `json:"state" yaml:"state"`
+
+	PublicIPv4  string     `json:"public_ipv4,omitempty" yaml:"public_ipv4,omitempty"`
+	PublicDNS   string     `json:"public_dns,omitempty" yaml:"public_dns,omitempty"`
+	PrivateIPv4 string     `json:"private_ipv4,omitempty" yaml:"private_ipv4,omitempty"`
+	LaunchTime  *time.Time `json:"launch_time,omitempty" yaml:"launch_time,omitempty"`
+}
+
+func (s *InstanceStateResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewListInstanceStateResponse(instances []*clients.InstanceDescription) []render.Renderer {
+	list := make([]render.Renderer, len(instances))
+	for i, instance := range instances {
+		list[i] = &InstanceStateResponse{
+			InstanceID:  instance.ID,
+			State:       instance.State,
+			PublicIPv4:  instance.PublicIPv4,
+			PublicDNS:   instance.PublicDNS,
+			PrivateIPv4: instance.PrivateIPv4,
+			LaunchTime:  instance.LaunchTime,
+		}
+	}
+	return list
+}
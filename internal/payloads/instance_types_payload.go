@@ -28,6 +28,10 @@ func NewListInstanceTypeResponse(sl []*clients.InstanceType) []render.Renderer {
 			EphemeralStorageGB: it.EphemeralStorageGB,
 			Supported:          it.Supported,
 			Architecture:       it.Architecture,
+			GPUs:               it.GPUs,
+			GPUModel:           it.GPUModel,
+			GPUManufacturer:    it.GPUManufacturer,
+			GPUMemoryMiB:       it.GPUMemoryMiB,
 			AzureDetail:        it.AzureDetail,
 		}
 	}
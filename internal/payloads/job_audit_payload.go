@@ -0,0 +1,52 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.JobAudit
+type JobAuditResponse struct {
+	ID            int64     `json:"id" yaml:"id"`
+	JobID         string    `json:"job_id" yaml:"job_id"`
+	JobType       string    `json:"job_type" yaml:"job_type"`
+	AccountID     int64     `json:"account_id" yaml:"account_id"`
+	ReservationID int64     `json:"reservation_id,omitempty" yaml:"reservation_id,omitempty"`
+	ArgsHash      string    `json:"args_hash" yaml:"args_hash"`
+	Attempt       int       `json:"attempt" yaml:"attempt"`
+	Outcome       string    `json:"outcome" yaml:"outcome"`
+	Error         string    `json:"error,omitempty" yaml:"error,omitempty"`
+	StartedAt     time.Time `json:"started_at" yaml:"started_at"`
+	DurationMs    int64     `json:"duration_ms" yaml:"duration_ms"`
+}
+
+func (p *JobAuditResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewJobAuditResponse(audit *models.JobAudit) render.Renderer {
+	return &JobAuditResponse{
+		ID:            audit.ID,
+		JobID:         audit.JobID,
+		JobType:       audit.JobType,
+		AccountID:     audit.AccountID,
+		ReservationID: audit.ReservationID,
+		ArgsHash:      audit.ArgsHash,
+		Attempt:       audit.Attempt,
+		Outcome:       audit.Outcome,
+		Error:         audit.Error,
+		StartedAt:     audit.StartedAt,
+		DurationMs:    audit.DurationMs,
+	}
+}
+
+func NewJobAuditListResponse(audits []*models.JobAudit) []render.Renderer {
+	list := make([]render.Renderer, len(audits))
+	for i, audit := range audits {
+		list[i] = NewJobAuditResponse(audit)
+	}
+	return list
+}
@@ -0,0 +1,99 @@
+package payloads
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+)
+
+// DefaultListLimit is the page size of paginated lists when limit parameter is not provided.
+const DefaultListLimit = 100
+
+// ListLinks are relative URLs of neighbour pages, empty when there is no such page.
+type ListLinks struct {
+	Previous string `json:"previous,omitempty" yaml:"previous,omitempty"`
+	Next     string `json:"next,omitempty" yaml:"next,omitempty"`
+}
+
+// ListMeta describes the page of a list response.
+type ListMeta struct {
+	// Number of items in the response
+	Count int `json:"count" yaml:"count"`
+
+	// Total number of items including items on other pages
+	Total int64 `json:"total" yaml:"total"`
+
+	Links ListLinks `json:"links" yaml:"links"`
+}
+
+// ListResponse is the envelope of all list responses.
+type ListResponse struct {
+	Data []render.Renderer `json:"data" yaml:"data"`
+	Meta ListMeta          `json:"meta" yaml:"meta"`
+}
+
+func (p *ListResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	for _, item := range p.Data {
+		if err := item.Render(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewListResponse returns an envelope of a list which is never paginated.
+func NewListResponse(data []render.Renderer) *ListResponse {
+	if data == nil {
+		data = []render.Renderer{}
+	}
+	return &ListResponse{
+		Data: data,
+		Meta: ListMeta{Count: len(data), Total: int64(len(data))},
+	}
+}
+
+// NewPageResponse returns an envelope of a page of a paginated list with links to the previous
+// and next pages. Links keep all query parameters of the request.
+func NewPageResponse(r *http.Request, data []render.Renderer, total, limit, offset int64) *ListResponse {
+	response := NewListResponse(data)
+	response.Meta.Total = total
+
+	if offset > 0 {
+		previous := offset - limit
+		if previous < 0 {
+			previous = 0
+		}
+		response.Meta.Links.Previous = pageLink(r, limit, previous)
+	}
+	if offset+int64(len(response.Data)) < total {
+		response.Meta.Links.Next = pageLink(r, limit, offset+limit)
+	}
+	return response
+}
+
+func pageLink(r *http.Request, limit, offset int64) string {
+	query := r.URL.Query()
+	query.Set("limit", strconv.FormatInt(limit, 10))
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// versionedList is a list response with items in the shape of an API version.
+type versionedList struct {
+	Data []any    `json:"data" yaml:"data"`
+	Meta ListMeta `json:"meta" yaml:"meta"`
+}
+
+// ForVersion converts versioned items of the list into the shape of the API version.
+func (p *ListResponse) ForVersion(apiVersion string) any {
+	data := make([]any, len(p.Data))
+	for i, item := range p.Data {
+		if versioned, ok := item.(Versioned); ok {
+			data[i] = versioned.ForVersion(apiVersion)
+		} else {
+			data[i] = item
+		}
+	}
+	return &versionedList{Data: data, Meta: p.Meta}
+}
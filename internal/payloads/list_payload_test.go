@@ -0,0 +1,45 @@
+package payloads
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pageItems(n int) []render.Renderer {
+	items := make([]render.Renderer, n)
+	for i := range items {
+		items[i] = &ResponseError{}
+	}
+	return items
+}
+
+func TestNewPageResponse(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/provisioning/v1/pubkeys?limit=10&offset=10&sort=name", nil)
+	require.NoError(t, err)
+
+	t.Run("middle page", func(t *testing.T) {
+		response := NewPageResponse(req, pageItems(10), 25, 10, 10)
+		assert.Equal(t, 10, response.Meta.Count)
+		assert.Equal(t, int64(25), response.Meta.Total)
+		assert.Equal(t, "/api/provisioning/v1/pubkeys?limit=10&offset=0&sort=name", response.Meta.Links.Previous)
+		assert.Equal(t, "/api/provisioning/v1/pubkeys?limit=10&offset=20&sort=name", response.Meta.Links.Next)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		response := NewPageResponse(req, pageItems(5), 25, 10, 20)
+		assert.Equal(t, 5, response.Meta.Count)
+		assert.NotEmpty(t, response.Meta.Links.Previous)
+		assert.Empty(t, response.Meta.Links.Next)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		response := NewPageResponse(req, nil, 0, 10, 0)
+		assert.NotNil(t, response.Data, "data must be an empty array")
+		assert.Empty(t, response.Meta.Links.Previous)
+		assert.Empty(t, response.Meta.Links.Next)
+	})
+}
@@ -0,0 +1,55 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// LogLevelRequest changes the log level of the process which serves the request.
+type LogLevelRequest struct {
+	// Level name (trace, debug, info, warn or error).
+	Level string `json:"level" yaml:"level"`
+
+	// Duration of the change (e.g. 30m), the LOGGING_LEVEL_WINDOW setting when blank.
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+
+	// Window is the parsed duration, zero when blank.
+	Window time.Duration `json:"-" yaml:"-"`
+}
+
+type LogLevelResponse struct {
+	Level      string     `json:"level" yaml:"level"`
+	Configured string     `json:"configured" yaml:"configured"`
+	ResetAt    *time.Time `json:"reset_at,omitempty" yaml:"reset_at,omitempty"`
+}
+
+func (p *LogLevelRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	v.oneOf("level", p.Level, "trace", "debug", "info", "warn", "error")
+
+	if p.Duration != "" {
+		window, err := time.ParseDuration(p.Duration)
+		if err != nil || window <= 0 {
+			v.add("duration", ConstraintMin, "duration must be a positive duration (e.g. 30m)")
+		}
+		p.Window = window
+	}
+	return v.err()
+}
+
+func (p *LogLevelResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewLogLevelResponse(level, configured string, resetAt time.Time) render.Renderer {
+	response := &LogLevelResponse{
+		Level:      level,
+		Configured: configured,
+	}
+	if !resetAt.IsZero() {
+		response.ResetAt = &resetAt
+	}
+	return response
+}
@@ -3,6 +3,7 @@ package payloads
 import (
 	"net/http"
 
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/go-chi/render"
 )
 
@@ -22,3 +23,40 @@ func NewPermissionsResponse(sl []string) render.Renderer {
 	}
 	return &response
 }
+
+// PermissionReportResponse is a per-permission report of the source credentials.
+type PermissionReportResponse struct {
+	// True when all permissions are granted.
+	Valid bool `json:"valid" yaml:"valid"`
+
+	// Provider of the source: aws, azure or gcp.
+	Provider string `json:"provider" yaml:"provider"`
+
+	// Results of the provider permission checklist.
+	Permissions []PermissionCheckResponse `json:"permissions" yaml:"permissions"`
+}
+
+type PermissionCheckResponse struct {
+	// Permission or action name as used by the provider.
+	Permission string `json:"permission" yaml:"permission"`
+
+	// True when the permission is granted.
+	Allowed bool `json:"allowed" yaml:"allowed"`
+}
+
+func (s *PermissionReportResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewPermissionReportResponse(provider string, checks []clients.PermissionCheck) render.Renderer {
+	response := PermissionReportResponse{
+		Valid:       true,
+		Provider:    provider,
+		Permissions: make([]PermissionCheckResponse, len(checks)),
+	}
+	for i, check := range checks {
+		response.Permissions[i] = PermissionCheckResponse{Permission: check.Permission, Allowed: check.Allowed}
+		response.Valid = response.Valid && check.Allowed
+	}
+	return &response
+}
@@ -0,0 +1,103 @@
+package payloads
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+	"github.com/go-chi/render"
+)
+
+// ProblemContentType is the content type of ProblemDetails (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is the v2 shape of ResponseError, see RFC 7807. Fields other than type,
+// title, status and detail are extension members with the same meaning as in ResponseError.
+type ProblemDetails struct {
+	// URI reference identifying the problem type, "about:blank" for plain HTTP errors
+	Type string `json:"type" yaml:"type"`
+
+	// HTTP status text
+	Title string `json:"title" yaml:"title"`
+
+	// HTTP status code
+	Status int `json:"status" yaml:"status"`
+
+	// user facing error message
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+
+	// full root cause
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// trace id from context (if provided)
+	TraceId string `json:"trace_id,omitempty" yaml:"trace_id,omitempty"`
+
+	// edge id from context (if provided)
+	EdgeId string `json:"edge_id,omitempty" yaml:"edge_id,omitempty"`
+
+	// request id of a backend service or cloud provider (if provided)
+	RequestId string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+
+	// build commit
+	Version string `json:"version" yaml:"version"`
+
+	// build time
+	BuildTime string `json:"build_time" yaml:"build_time"`
+
+	// environment (prod or stage or ephemeral)
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+
+	// invalid fields of the request payload (if any)
+	Fields []FieldViolation `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// RBAC permission the caller is missing (if any)
+	MissingPermission string `json:"missing_permission,omitempty" yaml:"missing_permission,omitempty"`
+
+	// cloud provider quota exhausted by the request (if any)
+	ExhaustedQuota string `json:"exhausted_quota,omitempty" yaml:"exhausted_quota,omitempty"`
+
+	// estimated hourly spend exceeding the spend cap of the account (if any)
+	SpendCap *SpendCapViolation `json:"spend_cap,omitempty" yaml:"spend_cap,omitempty"`
+}
+
+// ForVersion returns ProblemDetails for API v2 and newer.
+func (e *ResponseError) ForVersion(apiVersion string) any {
+	if apiVersion == version.APIPathVersion {
+		return e
+	}
+	return &ProblemDetails{
+		Type:              "about:blank",
+		Title:             http.StatusText(e.HTTPStatusCode),
+		Status:            e.HTTPStatusCode,
+		Detail:            e.Message,
+		Error:             e.Error,
+		TraceId:           e.TraceId,
+		EdgeId:            e.EdgeId,
+		RequestId:         e.RequestId,
+		Version:           e.Version,
+		BuildTime:         e.BuildTime,
+		Environment:       e.Environment,
+		Fields:            e.Fields,
+		MissingPermission: e.MissingPermission,
+		ExhaustedQuota:    e.ExhaustedQuota,
+		SpendCap:          e.SpendCap,
+	}
+}
+
+// writeProblem is render.JSON with the problem content type.
+func writeProblem(w http.ResponseWriter, r *http.Request, problem *ProblemDetails) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(true)
+	if err := enc.Encode(problem); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+	_, _ = w.Write(buf.Bytes())
+}
@@ -2,6 +2,9 @@ package payloads
 
 import (
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 
@@ -12,21 +15,36 @@ import (
 type PubkeyRequest struct {
 	Name string `json:"name" yaml:"name"`
 	Body string `json:"body" yaml:"body"`
+
+	// Optional expiration time (RFC 3339)
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+
+	// Share the key with all users of the organization (read-only)
+	Shared bool `json:"shared,omitempty" yaml:"shared,omitempty"`
 }
 
 // See models.Pubkey
 type PubkeyResponse struct {
-	ID                int64  `json:"id" yaml:"id"`
-	AccountID         int64  `json:"-" yaml:"-"`
-	Name              string `json:"name" yaml:"name"`
-	Body              string `json:"body" yaml:"body"`
-	Type              string `json:"type,omitempty" yaml:"type,omitempty"`
-	Fingerprint       string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
-	FingerprintLegacy string `json:"fingerprint_legacy,omitempty" yaml:"fingerprint_legacy,omitempty"`
+	ID                int64      `json:"id" yaml:"id"`
+	AccountID         int64      `json:"-" yaml:"-"`
+	Name              string     `json:"name" yaml:"name"`
+	Body              string     `json:"body" yaml:"body"`
+	Type              string     `json:"type,omitempty" yaml:"type,omitempty"`
+	Fingerprint       string     `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	FingerprintLegacy string     `json:"fingerprint_legacy,omitempty" yaml:"fingerprint_legacy,omitempty"`
+	Origin            string     `json:"origin,omitempty" yaml:"origin,omitempty"`
+	OriginUsername    string     `json:"origin_username,omitempty" yaml:"origin_username,omitempty"`
+	ImportedAt        *time.Time `json:"imported_at,omitempty" yaml:"imported_at,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	Owner             string     `json:"owner" yaml:"owner"`
+	Shared            bool       `json:"shared" yaml:"shared"`
 }
 
 func (p *PubkeyRequest) Bind(_ *http.Request) error {
-	return nil
+	v := validator{}
+	v.requireString("name", p.Name)
+	v.requireString("body", p.Body)
+	return v.err()
 }
 
 func (p *PubkeyResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
@@ -35,8 +53,10 @@ func (p *PubkeyResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
 
 func (p *PubkeyRequest) NewModel() *models.Pubkey {
 	return &models.Pubkey{
-		Name: p.Name,
-		Body: p.Body,
+		Name:      p.Name,
+		Body:      p.Body,
+		ExpiresAt: p.ExpiresAt,
+		Shared:    p.Shared,
 	}
 }
 
@@ -49,6 +69,12 @@ func NewPubkeyResponse(pubkey *models.Pubkey) render.Renderer {
 		Type:              pubkey.Type,
 		Fingerprint:       pubkey.Fingerprint,
 		FingerprintLegacy: pubkey.FingerprintLegacy,
+		Origin:            pubkey.Origin,
+		OriginUsername:    pubkey.OriginUsername,
+		ImportedAt:        pubkey.ImportedAt,
+		ExpiresAt:         pubkey.ExpiresAt,
+		Owner:             pubkey.Owner,
+		Shared:            pubkey.Shared,
 	}
 }
 
@@ -59,3 +85,163 @@ func NewPubkeyListResponse(pubkeys []*models.Pubkey) []render.Renderer {
 	}
 	return list
 }
+
+// PubkeyDeleteResourceResponse describes deletion status of a single pubkey resource during cascade delete.
+type PubkeyDeleteResourceResponse struct {
+	ID       int64  `json:"id" yaml:"id"`
+	Provider string `json:"provider" yaml:"provider"`
+	SourceID string `json:"source_id" yaml:"source_id"`
+	Region   string `json:"region" yaml:"region"`
+
+	// Either "enqueued" or "failed"
+	Status string `json:"status" yaml:"status"`
+
+	// Error message when the deletion could not be scheduled
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// PubkeyDeleteResponse is returned for cascade delete, the pubkey is deleted once all resources are removed.
+type PubkeyDeleteResponse struct {
+	ID        int64                           `json:"id" yaml:"id"`
+	Resources []*PubkeyDeleteResourceResponse `json:"resources" yaml:"resources"`
+}
+
+func (p *PubkeyDeleteResponse) Render(_ http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusAccepted)
+	return nil
+}
+
+// PubkeyImportRequest imports public keys published on GitHub or GitLab.
+type PubkeyImportRequest struct {
+	// Site to import keys from: "github" or "gitlab"
+	Site string `json:"site" yaml:"site"`
+
+	// Username on the site
+	Username string `json:"username" yaml:"username"`
+
+	// SHA256 fingerprints of keys to import. When empty, no key is imported and all keys
+	// published by the user are returned so one can pick which keys to import.
+	Fingerprints []string `json:"fingerprints,omitempty" yaml:"fingerprints,omitempty"`
+}
+
+// PubkeyImportKeyResponse is a single key published by the user.
+type PubkeyImportKeyResponse struct {
+	// Database ID when the key was imported
+	ID          int64  `json:"id,omitempty" yaml:"id,omitempty"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Body        string `json:"body" yaml:"body"`
+	Type        string `json:"type" yaml:"type"`
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+	Imported    bool   `json:"imported" yaml:"imported"`
+
+	// Error message when the key was selected but could not be imported
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type PubkeyImportResponse struct {
+	Keys []*PubkeyImportKeyResponse `json:"keys" yaml:"keys"`
+}
+
+func (p *PubkeyImportRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	v.oneOf("site", strings.ToLower(p.Site), "github", "gitlab")
+	v.requireString("username", p.Username)
+	return v.err()
+}
+
+func (p *PubkeyImportResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// PubkeyUsageResourceResponse is a key-pair uploaded to a cloud.
+type PubkeyUsageResourceResponse struct {
+	Provider string `json:"provider" yaml:"provider"`
+	SourceID string `json:"source_id" yaml:"source_id"`
+	Region   string `json:"region" yaml:"region"`
+	Handle   string `json:"handle" yaml:"handle"`
+}
+
+// PubkeyUsageReservationResponse is a reservation which used the pubkey.
+type PubkeyUsageReservationResponse struct {
+	ID        int64     `json:"id" yaml:"id"`
+	Provider  string    `json:"provider" yaml:"provider"`
+	SourceID  string    `json:"source_id" yaml:"source_id"`
+	Region    string    `json:"region" yaml:"region"`
+	Status    string    `json:"status" yaml:"status"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	Success   *bool     `json:"success" yaml:"success"`
+}
+
+// PubkeyUsageResponse lists all clouds the pubkey was uploaded to and all reservations which
+// used it. Providers, sources and regions are unique sorted summaries of both.
+type PubkeyUsageResponse struct {
+	ID           int64                             `json:"id" yaml:"id"`
+	Providers    []string                          `json:"providers" yaml:"providers"`
+	Sources      []string                          `json:"sources" yaml:"sources"`
+	Regions      []string                          `json:"regions" yaml:"regions"`
+	Resources    []*PubkeyUsageResourceResponse    `json:"resources" yaml:"resources"`
+	Reservations []*PubkeyUsageReservationResponse `json:"reservations" yaml:"reservations"`
+}
+
+func (p *PubkeyUsageResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewPubkeyUsageResponse(pubkey *models.Pubkey, resources []*models.PubkeyResource, reservations []*models.PubkeyReservation) render.Renderer {
+	providers := make(map[string]struct{})
+	sources := make(map[string]struct{})
+	regions := make(map[string]struct{})
+	add := func(provider models.ProviderType, sourceId, region string) {
+		providers[provider.String()] = struct{}{}
+		if sourceId != "" {
+			sources[sourceId] = struct{}{}
+		}
+		if region != "" {
+			regions[region] = struct{}{}
+		}
+	}
+
+	response := &PubkeyUsageResponse{
+		ID:           pubkey.ID,
+		Resources:    make([]*PubkeyUsageResourceResponse, len(resources)),
+		Reservations: make([]*PubkeyUsageReservationResponse, len(reservations)),
+	}
+	for i, res := range resources {
+		add(res.Provider, res.SourceID, res.Region)
+		response.Resources[i] = &PubkeyUsageResourceResponse{
+			Provider: res.Provider.String(),
+			SourceID: res.SourceID,
+			Region:   res.Region,
+			Handle:   res.Handle,
+		}
+	}
+	for i, res := range reservations {
+		add(res.Provider, res.SourceID, res.Region)
+		item := &PubkeyUsageReservationResponse{
+			ID:        res.ReservationID,
+			Provider:  res.Provider.String(),
+			SourceID:  res.SourceID,
+			Region:    res.Region,
+			Status:    res.Status,
+			CreatedAt: res.CreatedAt,
+		}
+		if res.Success.Valid {
+			item.Success = &res.Success.Bool
+		}
+		response.Reservations[i] = item
+	}
+
+	response.Providers = sortedKeys(providers)
+	response.Sources = sortedKeys(sources)
+	response.Regions = sortedKeys(regions)
+	return response
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -1,13 +1,22 @@
 package payloads
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/go-chi/render"
 )
 
+// GCP network and subnetwork names or paths, projects prefix selects a shared VPC host project.
+var (
+	gcpNetworkRegexp    = regexp.MustCompile(`^((projects/[a-z][-a-z0-9.:]*[a-z0-9]/)?global/networks/)?[a-z]([-a-z0-9]*[a-z0-9])?$`)
+	gcpSubnetworkRegexp = regexp.MustCompile(`^((projects/[a-z][-a-z0-9.:]*[a-z0-9]/)?regions/[a-z]+-[a-z]+[0-9]+/subnetworks/)?[a-z]([-a-z0-9]*[a-z0-9])?$`)
+)
+
 // ReservationRequest is empty, account comes in HTTP header and
 // provider type in HTTP URL. All other fields are auto-generated.
 
@@ -40,6 +49,18 @@ type GenericReservationResponsePayload struct {
 
 	// Flag indicating success, error or unknown state (NULL). See Status for the actual error.
 	Success *bool `json:"success" nullable:"true" yaml:"success"`
+
+	// User requested cancellation, status is set to "Cancelled" once the job is aborted.
+	CancelRequested bool `json:"cancel_requested" yaml:"cancel_requested"`
+
+	// Flag indicating instances accept SSH connections, null when instances were not probed (yet).
+	SSHReady *bool `json:"ssh_ready" nullable:"true" yaml:"ssh_ready"`
+
+	// User who made the reservation, empty for reservations of the whole organization.
+	Owner string `json:"owner" yaml:"owner"`
+
+	// Flag indicating the reservation is visible (read-only) to all users of the organization.
+	Shared bool `json:"shared" yaml:"shared"`
 }
 
 type InstanceResponse struct {
@@ -62,9 +83,12 @@ type AWSReservationResponsePayload struct {
 	// AWS region.
 	Region string `json:"region" yaml:"region"`
 
-	// AWS Instance type.
+	// AWS Instance type, for fleet launches the type which was launched.
 	InstanceType string `json:"instance_type" yaml:"instance_type"`
 
+	// Acceptable instance types of a fleet launch in the order of preference.
+	InstanceTypes []string `json:"instance_types,omitempty" yaml:"instance_types"`
+
 	// Amount of instances to provision of type: Instance type.
 	Amount int32 `json:"amount" yaml:"amount"`
 
@@ -83,6 +107,18 @@ type AWSReservationResponsePayload struct {
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Instance tenancy: default, dedicated or host.
+	Tenancy string `json:"tenancy,omitempty" yaml:"tenancy"`
+
+	// Dedicated host ID of the host tenancy.
+	HostID string `json:"host_id,omitempty" yaml:"host_id"`
+
+	// Instance metadata service options the instances were launched with.
+	MetadataOptions *AWSMetadataOptionsPayload `json:"metadata_options,omitempty" yaml:"metadata_options"`
+
+	// User tags of the instances, standard tags are not listed.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
 	// Instances array, only present for finished reservations
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -112,6 +148,31 @@ type AzureReservationResponsePayload struct {
 	// Immediately PowerOff the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Resource group strategy: shared, existing or reservation.
+	ResourceGroupStrategy string `json:"resource_group_strategy,omitempty" yaml:"resource_group_strategy"`
+
+	// Resource group of the instances, not known until the group is created for the reservation.
+	ResourceGroup string `json:"resource_group,omitempty" yaml:"resource_group"`
+
+	// VM priority: regular or spot.
+	Priority string `json:"priority,omitempty" yaml:"priority"`
+
+	// Maximum price of spot VMs in US dollars per hour, -1 is the regular price.
+	MaxPrice *float64 `json:"max_price,omitempty" yaml:"max_price"`
+
+	// Eviction policy of spot VMs: deallocate or delete.
+	EvictionPolicy string `json:"eviction_policy,omitempty" yaml:"eviction_policy"`
+
+	// Eviction status of spot VMs of a finished reservation: none, partial, evicted or unknown
+	// when the state of the VMs cannot be queried.
+	EvictionStatus string `json:"eviction_status,omitempty" yaml:"eviction_status"`
+
+	// IDs of evicted spot VMs, deallocated or no longer existing depending on the policy.
+	EvictedInstances []string `json:"evicted_instances,omitempty" yaml:"evicted_instances"`
+
+	// User tags of the instances, standard tags are not listed.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
 	// Instances IDs, only present for finished reservations.
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -149,6 +210,27 @@ type GCPReservationResponsePayload struct {
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Provisioning model: STANDARD or SPOT.
+	ProvisioningModel string `json:"provisioning_model,omitempty" yaml:"provisioning_model"`
+
+	// Action taken when spot VMs are preempted: STOP or DELETE.
+	TerminationAction string `json:"termination_action,omitempty" yaml:"termination_action"`
+
+	// Email of the service account attached to the instances.
+	ServiceAccount string `json:"service_account,omitempty" yaml:"service_account"`
+
+	// OAuth scopes of the attached service account.
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes"`
+
+	// Network path of the instances, empty for the default network.
+	Network string `json:"network,omitempty" yaml:"network"`
+
+	// Subnetwork path of the instances.
+	Subnetwork string `json:"subnetwork,omitempty" yaml:"subnetwork"`
+
+	// User tags of the instances, standard tags are not listed.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
 	// Instances IDs, only present for finished reservations.
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -158,7 +240,8 @@ type NoopReservationResponsePayload struct {
 }
 
 type AWSReservationRequestPayload struct {
-	// Pubkey ID. Always required even when launch template provides one.
+	// Pubkey ID. Always required even when launch template provides one, defaults to the pubkey
+	// of account settings.
 	PubkeyID int64 `json:"pubkey_id" yaml:"pubkey_id"`
 
 	// Source ID.
@@ -176,22 +259,64 @@ type AWSReservationRequestPayload struct {
 	// AWS Instance type.
 	InstanceType string `json:"instance_type" yaml:"instance_type"`
 
+	// Optional acceptable instance types in the order of preference, alternative to instance_type.
+	// Instances are launched via EC2 Fleet which falls back to the next type when there is not
+	// enough capacity, all instances are of the same type. Cannot be used with a launch template.
+	InstanceTypes []string `json:"instance_types,omitempty" yaml:"instance_types"`
+
 	// Amount of instances to provision of type: Instance type.
 	Amount int32 ` json:"amount" yaml:"amount"`
 
-	// Image Builder UUID of the image that should be launched. AMI's must be prefixed with 'ami-'.
+	// Image Builder UUID of the image that should be launched, it is cloned into the region when
+	// needed. AMI's must be prefixed with 'ami-', they must exist in the region and match
+	// architecture of the instance type.
 	ImageID string `json:"image_id" yaml:"image_id"`
 
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Require instance type with at least one GPU, reservation is rejected otherwise.
+	RequireGPU bool `json:"require_gpu,omitempty" yaml:"require_gpu"`
+
+	// Optional instance tenancy: default (shared hardware), dedicated or host. Defaults to default.
+	Tenancy string `json:"tenancy,omitempty" yaml:"tenancy"`
+
+	// Optional dedicated host ID, only allowed for the host tenancy.
+	HostID string `json:"host_id,omitempty" yaml:"host_id"`
+
+	// Optional instance metadata service options, IMDSv2 is required by default.
+	MetadataOptions *AWSMetadataOptionsPayload `json:"metadata_options,omitempty" yaml:"metadata_options"`
+
+	// Optional tags of the instances, required tags of account settings must be set. Standard
+	// tags (managed-by, reservation-id and org-id) are added by the service.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
+	// DryRun validates the launch with the cloud provider without creating the reservation,
+	// the validation result is returned instead.
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run"`
+}
+
+type AWSMetadataOptionsPayload struct {
+	// Either "required" (IMDSv2 only, the default) or "optional" (IMDSv1 allowed). Opting out of
+	// IMDSv2 must be allowed for the organization.
+	HTTPTokens string `json:"http_tokens,omitempty" yaml:"http_tokens"`
+
+	// Metadata PUT response hop limit (1-64), containers need at least 2. Defaults to the service
+	// configuration, usually 1.
+	HopLimit int32 `json:"hop_limit,omitempty" yaml:"hop_limit"`
+
+	// Turn off the instance metadata endpoint.
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled"`
 }
 
 type AzureReservationRequestPayload struct {
+	// Pubkey ID, defaults to the pubkey of account settings.
 	PubkeyID int64 `json:"pubkey_id" yaml:"pubkey_id"`
 
 	SourceID string `json:"source_id" yaml:"source_id"`
 
-	// Image Builder UUID of the image that should be launched. This can be directly Azure image ID.
+	// Image Builder UUID of the image that should be launched. This can be directly Azure image ID,
+	// managed images and gallery image versions must match architecture of the instance size.
 	ImageID string `json:"image_id" yaml:"image_id"`
 
 	// Azure Location to deploy into.
@@ -208,10 +333,44 @@ type AzureReservationRequestPayload struct {
 
 	// Immediately power off the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Require instance type with at least one GPU, reservation is rejected otherwise.
+	RequireGPU bool `json:"require_gpu,omitempty" yaml:"require_gpu"`
+
+	// Optional resource group strategy: "shared" (default) launches into the "redhat-deployed"
+	// group created by the service, "existing" launches into resource_group which must exist and
+	// "reservation" creates a new group for the reservation which is deleted when the launch fails.
+	ResourceGroupStrategy string `json:"resource_group_strategy,omitempty" yaml:"resource_group_strategy"`
+
+	// Name of an existing resource group, required for the existing strategy.
+	ResourceGroup string `json:"resource_group,omitempty" yaml:"resource_group"`
+
+	// Optional tags of the resource group created by the reservation strategy.
+	ResourceGroupTags map[string]string `json:"resource_group_tags,omitempty" yaml:"resource_group_tags"`
+
+	// Optional VM priority: "regular" (default) or "spot" for discounted VMs which can be evicted
+	// when Azure needs the capacity back or the price exceeds max_price.
+	Priority string `json:"priority,omitempty" yaml:"priority"`
+
+	// Optional maximum price of spot VMs in US dollars per hour, -1 (default) caps the price at
+	// the regular price so VMs are only evicted for capacity.
+	MaxPrice *float64 `json:"max_price,omitempty" yaml:"max_price"`
+
+	// Optional eviction policy of spot VMs: "deallocate" (default) stops evicted VMs keeping
+	// their disks, "delete" deletes them.
+	EvictionPolicy string `json:"eviction_policy,omitempty" yaml:"eviction_policy"`
+
+	// Optional tags of the VMs and their disks, network interfaces and public IPs, required tags of account settings must be set. Standard
+	// tags (managed-by, reservation-id and org-id) are added by the service.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
+	// DryRun validates the launch with the cloud provider without creating the reservation,
+	// the validation result is returned instead.
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run"`
 }
 
 type GCPReservationRequestPayload struct {
-	// Pubkey ID.
+	// Pubkey ID, defaults to the pubkey of account settings.
 	PubkeyID int64 `json:"pubkey_id" yaml:"pubkey_id"`
 
 	// Source ID.
@@ -232,11 +391,51 @@ type GCPReservationRequestPayload struct {
 	// Amount of instances to provision of type: Instance type.
 	Amount int64 ` json:"amount" yaml:"amount"`
 
-	// Image Builder UUID of the image that should be launched.
+	// Image Builder UUID of the image that should be launched. This can be directly GCP image
+	// self-link, the image must match architecture of the machine type.
 	ImageID string `json:"image_id" yaml:"image_id"`
 
 	// Immediately power off the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Require instance type with at least one GPU, reservation is rejected otherwise.
+	RequireGPU bool `json:"require_gpu,omitempty" yaml:"require_gpu"`
+
+	// Optional provisioning model: "STANDARD" (default) or "SPOT" for discounted VMs which can be
+	// preempted at any time.
+	ProvisioningModel string `json:"provisioning_model,omitempty" yaml:"provisioning_model"`
+
+	// Optional action taken when spot VMs are preempted: "STOP" (default) keeps the disks,
+	// "DELETE" deletes the VMs.
+	TerminationAction string `json:"termination_action,omitempty" yaml:"termination_action"`
+
+	// Optional email of a service account of the project to attach to the instances, "none" or
+	// empty launches instances without any service account.
+	ServiceAccount string `json:"service_account,omitempty" yaml:"service_account"`
+
+	// Optional OAuth scopes of the service account, defaults to the cloud-platform scope which
+	// limits access only by IAM roles of the service account.
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes"`
+
+	// Optional network name or path ("global/networks/NAME"). Shared VPC networks of a host
+	// project are selected by the "projects/PROJECT/global/networks/NAME" path. The default
+	// network is used when neither network nor subnetwork is set.
+	Network string `json:"network,omitempty" yaml:"network"`
+
+	// Optional subnetwork name or path ("regions/REGION/subnetworks/NAME"), the region must be
+	// the region of the zone. Shared VPC subnetworks of a host project are selected by the
+	// "projects/PROJECT/regions/REGION/subnetworks/NAME" path.
+	Subnetwork string `json:"subnetwork,omitempty" yaml:"subnetwork"`
+
+	// Optional labels of the instances, required tags of account settings must be set. Keys and
+	// values are converted to lower case and characters not allowed in GCP labels are replaced
+	// by underscores. Standard labels (managed-by, reservation-id and org-id) are added by the
+	// service.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
+	// DryRun validates the launch with the cloud provider without creating the reservation,
+	// the validation result is returned instead.
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run"`
 }
 
 func (p *GenericReservationResponsePayload) Render(_ http.ResponseWriter, _ *http.Request) error {
@@ -244,7 +443,33 @@ func (p *GenericReservationResponsePayload) Render(_ http.ResponseWriter, _ *htt
 }
 
 func (p *AWSReservationRequestPayload) Bind(_ *http.Request) error {
-	return nil
+	v := validator{}
+	v.requireString("source_id", p.SourceID)
+	v.requireString("image_id", p.ImageID)
+	v.atLeast("amount", int64(p.Amount), 1)
+	if p.Tenancy != "" {
+		v.oneOf("tenancy", p.Tenancy, models.AWSTenancyDefault, models.AWSTenancyDedicated, models.AWSTenancyHost)
+	}
+	if p.HostID != "" && p.Tenancy != models.AWSTenancyHost {
+		v.add("host_id", ConstraintFormat, "host_id requires host tenancy")
+	}
+	if len(p.InstanceTypes) > 0 && p.InstanceType != "" {
+		v.add("instance_types", ConstraintFormat, "instance_types cannot be combined with instance_type")
+	}
+	if len(p.InstanceTypes) > 0 && p.LaunchTemplateID != "" {
+		v.add("instance_types", ConstraintFormat, "instance_types cannot be combined with launch_template_id")
+	}
+	if p.MetadataOptions != nil {
+		if p.MetadataOptions.HTTPTokens != "" {
+			v.oneOf("metadata_options.http_tokens", p.MetadataOptions.HTTPTokens, models.AWSHTTPTokensRequired, models.AWSHTTPTokensOptional)
+		}
+		if p.MetadataOptions.HopLimit != 0 {
+			v.atLeast("metadata_options.hop_limit", int64(p.MetadataOptions.HopLimit), 1)
+			v.atMost("metadata_options.hop_limit", int64(p.MetadataOptions.HopLimit), 64)
+		}
+	}
+	v.tags("tags", p.Tags)
+	return v.err()
 }
 
 func (p *AWSReservationResponsePayload) Render(_ http.ResponseWriter, _ *http.Request) error {
@@ -252,7 +477,41 @@ func (p *AWSReservationResponsePayload) Render(_ http.ResponseWriter, _ *http.Re
 }
 
 func (p *AzureReservationRequestPayload) Bind(_ *http.Request) error {
-	return nil
+	v := validator{}
+	v.requireString("source_id", p.SourceID)
+	v.requireString("image_id", p.ImageID)
+	v.atLeast("amount", p.Amount, 1)
+	if p.ResourceGroupStrategy != "" {
+		v.oneOf("resource_group_strategy", p.ResourceGroupStrategy, models.AzureResourceGroupShared, models.AzureResourceGroupExisting, models.AzureResourceGroupReservation)
+	}
+	if p.ResourceGroupStrategy == models.AzureResourceGroupExisting {
+		v.requireString("resource_group", p.ResourceGroup)
+	} else if p.ResourceGroup != "" {
+		v.add("resource_group", ConstraintFormat, "resource_group requires existing resource_group_strategy")
+	}
+	if len(p.ResourceGroupTags) > 0 && p.ResourceGroupStrategy != models.AzureResourceGroupReservation {
+		v.add("resource_group_tags", ConstraintFormat, "resource_group_tags requires reservation resource_group_strategy")
+	}
+	if p.Priority != "" {
+		v.oneOf("priority", p.Priority, models.AzurePriorityRegular, models.AzurePrioritySpot)
+	}
+	if p.Priority == models.AzurePrioritySpot {
+		if p.EvictionPolicy != "" {
+			v.oneOf("eviction_policy", p.EvictionPolicy, models.AzureEvictionDeallocate, models.AzureEvictionDelete)
+		}
+		if p.MaxPrice != nil && *p.MaxPrice != -1 && *p.MaxPrice <= 0 {
+			v.add("max_price", ConstraintMin, "max_price must be -1 or greater than 0")
+		}
+	} else {
+		if p.EvictionPolicy != "" {
+			v.add("eviction_policy", ConstraintFormat, "eviction_policy requires spot priority")
+		}
+		if p.MaxPrice != nil {
+			v.add("max_price", ConstraintFormat, "max_price requires spot priority")
+		}
+	}
+	v.tags("tags", p.Tags)
+	return v.err()
 }
 
 func (p *AzureReservationResponsePayload) Render(_ http.ResponseWriter, _ *http.Request) error {
@@ -272,7 +531,42 @@ func NewReservationResponse(reservation *models.Reservation) render.Renderer {
 }
 
 func (p *GCPReservationRequestPayload) Bind(_ *http.Request) error {
-	return nil
+	v := validator{}
+	v.requireString("source_id", p.SourceID)
+	v.requireString("image_id", p.ImageID)
+	v.atLeast("amount", p.Amount, 1)
+	if p.ProvisioningModel != "" {
+		v.oneOf("provisioning_model", p.ProvisioningModel, models.GCPProvisioningStandard, models.GCPProvisioningSpot)
+	}
+	if p.TerminationAction != "" {
+		if p.ProvisioningModel == models.GCPProvisioningSpot {
+			v.oneOf("termination_action", p.TerminationAction, models.GCPTerminationStop, models.GCPTerminationDelete)
+		} else {
+			v.add("termination_action", ConstraintFormat, "termination_action requires SPOT provisioning_model")
+		}
+	}
+	if p.ServiceAccount != "" && p.ServiceAccount != models.GCPServiceAccountNone &&
+		(!strings.Contains(p.ServiceAccount, "@") || !strings.HasSuffix(p.ServiceAccount, ".gserviceaccount.com")) {
+		v.add("service_account", ConstraintFormat, "service_account must be a service account email or none")
+	}
+	if len(p.Scopes) > 0 {
+		if p.ServiceAccount == "" || p.ServiceAccount == models.GCPServiceAccountNone {
+			v.add("scopes", ConstraintFormat, "scopes requires service_account")
+		}
+		for _, scope := range p.Scopes {
+			if !strings.HasPrefix(scope, "https://www.googleapis.com/auth/") {
+				v.add("scopes", ConstraintFormat, fmt.Sprintf("scope %s must be a https://www.googleapis.com/auth/ URL", scope))
+			}
+		}
+	}
+	if p.Network != "" {
+		v.matches("network", p.Network, gcpNetworkRegexp, "a network name or path")
+	}
+	if p.Subnetwork != "" {
+		v.matches("subnetwork", p.Subnetwork, gcpSubnetworkRegexp, "a subnetwork name or path")
+	}
+	v.tags("tags", p.Tags)
+	return v.err()
 }
 
 func NewAWSReservationResponse(reservation *models.AWSReservation, instances []*models.ReservationInstance) render.Renderer {
@@ -288,15 +582,26 @@ func NewAWSReservationResponse(reservation *models.AWSReservation, instances []*
 		Region:           reservation.Detail.Region,
 		Amount:           reservation.Detail.Amount,
 		InstanceType:     reservation.Detail.InstanceType,
+		InstanceTypes:    reservation.Detail.InstanceTypes,
 		ID:               reservation.ID,
 		Name:             StringNullToEmpty(reservation.Detail.Name),
 		PowerOff:         reservation.Detail.PowerOff,
 		Instances:        instancesResponse,
 		LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+		Tenancy:          reservation.Detail.Tenancy,
+		HostID:           reservation.Detail.HostID,
+		Tags:             reservation.Detail.Tags,
 	}
 	if reservation.AWSReservationID != nil {
 		response.AWSReservationID = *reservation.AWSReservationID
 	}
+	if options := reservation.Detail.MetadataOptions; options != nil {
+		response.MetadataOptions = &AWSMetadataOptionsPayload{
+			HTTPTokens: options.HTTPTokens,
+			HopLimit:   options.HopLimit,
+			Disabled:   options.Disabled,
+		}
+	}
 	return &response
 }
 
@@ -317,10 +622,35 @@ func NewAzureReservationResponse(reservation *models.AzureReservation, instances
 		Name:         reservation.Detail.Name,
 		PowerOff:     reservation.Detail.PowerOff,
 		Instances:    instanceIds,
+
+		ResourceGroupStrategy: reservation.Detail.ResourceGroupStrategy,
+		ResourceGroup:         reservation.Detail.ResourceGroup,
+		Priority:              reservation.Detail.Priority,
+		MaxPrice:              reservation.Detail.MaxPrice,
+		EvictionPolicy:        reservation.Detail.EvictionPolicy,
+		Tags:                  reservation.Detail.Tags,
 	}
 	return &response
 }
 
+// NewAzureSpotReservationResponse returns Azure reservation response with eviction status of
+// spot VMs, status is unknown when evicted is nil.
+func NewAzureSpotReservationResponse(reservation *models.AzureReservation, instances []*models.ReservationInstance, evicted []string) render.Renderer {
+	response := NewAzureReservationResponse(reservation, instances).(*AzureReservationResponsePayload)
+	response.EvictedInstances = evicted
+	switch {
+	case evicted == nil:
+		response.EvictionStatus = models.AzureEvictionStatusUnknown
+	case len(evicted) == 0:
+		response.EvictionStatus = models.AzureEvictionStatusNone
+	case len(evicted) < len(instances):
+		response.EvictionStatus = models.AzureEvictionStatusPartial
+	default:
+		response.EvictionStatus = models.AzureEvictionStatusEvicted
+	}
+	return response
+}
+
 func NewGCPReservationResponse(reservation *models.GCPReservation, instances []*models.ReservationInstance) render.Renderer {
 	instanceIds := make([]InstanceResponse, len(instances))
 	for iter, inst := range instances {
@@ -331,17 +661,24 @@ func NewGCPReservationResponse(reservation *models.GCPReservation, instances []*
 	}
 
 	response := GCPReservationResponsePayload{
-		NamePattern:      *reservation.Detail.NamePattern,
-		PubkeyID:         reservation.PubkeyID,
-		ImageID:          reservation.ImageID,
-		SourceID:         reservation.SourceID,
-		Zone:             reservation.Detail.Zone,
-		Amount:           reservation.Detail.Amount,
-		MachineType:      reservation.Detail.MachineType,
-		GCPOperationName: reservation.GCPOperationName,
-		ID:               reservation.ID,
-		PowerOff:         reservation.Detail.PowerOff,
-		Instances:        instanceIds,
+		NamePattern:       *reservation.Detail.NamePattern,
+		PubkeyID:          reservation.PubkeyID,
+		ImageID:           reservation.ImageID,
+		SourceID:          reservation.SourceID,
+		Zone:              reservation.Detail.Zone,
+		Amount:            reservation.Detail.Amount,
+		MachineType:       reservation.Detail.MachineType,
+		GCPOperationName:  reservation.GCPOperationName,
+		ID:                reservation.ID,
+		PowerOff:          reservation.Detail.PowerOff,
+		ProvisioningModel: reservation.Detail.ProvisioningModel,
+		TerminationAction: reservation.Detail.TerminationAction,
+		ServiceAccount:    reservation.Detail.ServiceAccount,
+		Scopes:            reservation.Detail.Scopes,
+		Network:           reservation.Detail.Network,
+		Subnetwork:        reservation.Detail.Subnetwork,
+		Tags:              reservation.Detail.Tags,
+		Instances:         instanceIds,
 	}
 	return &response
 }
@@ -369,6 +706,10 @@ func reservationResponseMapper(reservation *models.Reservation) *GenericReservat
 	if reservation.Success.Valid {
 		success = &reservation.Success.Bool
 	}
+	var sshReady *bool
+	if reservation.SSHReady.Valid {
+		sshReady = &reservation.SSHReady.Bool
+	}
 	return &GenericReservationResponsePayload{
 		ID:         reservation.ID,
 		Provider:   int(reservation.Provider),
@@ -380,5 +721,10 @@ func reservationResponseMapper(reservation *models.Reservation) *GenericReservat
 		Step:       reservation.Step,
 		StepTitles: reservation.StepTitles,
 		Error:      reservation.Error,
+
+		CancelRequested: reservation.CancelRequested,
+		SSHReady:        sshReady,
+		Owner:           reservation.Owner,
+		Shared:          reservation.Shared,
 	}
 }
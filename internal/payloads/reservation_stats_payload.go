@@ -0,0 +1,94 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// See models.ReservationProviderStat
+type ReservationProviderStatResponse struct {
+	// Provider of the reservations (aws, azure or gcp)
+	Provider string `json:"provider" yaml:"provider"`
+
+	// Number of all reservations
+	Total int64 `json:"total" yaml:"total"`
+
+	// Number of successfully finished reservations
+	Success int64 `json:"success" yaml:"success"`
+
+	// Number of failed reservations
+	Failure int64 `json:"failure" yaml:"failure"`
+
+	// Number of reservations cancelled on user request
+	Cancelled int64 `json:"cancelled" yaml:"cancelled"`
+
+	// Number of reservations which are still processing
+	Pending int64 `json:"pending" yaml:"pending"`
+
+	// Number of instances launched by the reservations
+	Instances int64 `json:"instances" yaml:"instances"`
+
+	// Ratio of successful to finished reservations (0 to 1), cancelled ones are not counted
+	SuccessRate float64 `json:"success_rate" yaml:"success_rate"`
+}
+
+// See models.ReservationDailyStat
+type ReservationDailyStatResponse struct {
+	// Day the reservations were created
+	Day time.Time `json:"day" yaml:"day"`
+
+	// Provider of the reservations (aws, azure or gcp)
+	Provider string `json:"provider" yaml:"provider"`
+
+	// Result of the reservations: success, failure, cancelled or pending
+	Result string `json:"result" yaml:"result"`
+
+	// Number of the reservations
+	Count int64 `json:"count" yaml:"count"`
+}
+
+type ReservationStatsResponse struct {
+	// Number of days the statistics are computed for, including today
+	Days int `json:"days" yaml:"days"`
+
+	// Statistics of each provider, providers without reservations are omitted
+	Providers []*ReservationProviderStatResponse `json:"providers" yaml:"providers"`
+
+	// Number of reservations per day, provider and result, ordered by day
+	Daily []*ReservationDailyStatResponse `json:"daily" yaml:"daily"`
+}
+
+func (s *ReservationStatsResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewReservationStatsResponse(days int, providers []*models.ReservationProviderStat, daily []*models.ReservationDailyStat) *ReservationStatsResponse {
+	response := &ReservationStatsResponse{
+		Days:      days,
+		Providers: make([]*ReservationProviderStatResponse, len(providers)),
+		Daily:     make([]*ReservationDailyStatResponse, len(daily)),
+	}
+	for i, stat := range providers {
+		response.Providers[i] = &ReservationProviderStatResponse{
+			Provider:    stat.Provider.String(),
+			Total:       stat.Total,
+			Success:     stat.Success,
+			Failure:     stat.Failure,
+			Cancelled:   stat.Cancelled,
+			Pending:     stat.Pending,
+			Instances:   stat.Instances,
+			SuccessRate: stat.SuccessRate,
+		}
+	}
+	for i, stat := range daily {
+		response.Daily[i] = &ReservationDailyStatResponse{
+			Day:      stat.Day,
+			Provider: stat.Provider.String(),
+			Result:   stat.Result,
+			Count:    stat.Count,
+		}
+	}
+	return response
+}
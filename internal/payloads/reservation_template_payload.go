@@ -0,0 +1,232 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.ReservationTemplate
+type ReservationTemplateRequest struct {
+	// User-facing name, must be unique.
+	Name string `json:"name" yaml:"name"`
+
+	// Provider type: aws, azure or gcp.
+	Provider string `json:"provider" yaml:"provider"`
+
+	// Source ID.
+	SourceID string `json:"source_id,omitempty" yaml:"source_id,omitempty"`
+
+	// Image Builder UUID of the image or provider image ID.
+	ImageID string `json:"image_id,omitempty" yaml:"image_id,omitempty"`
+
+	// Instance type (AWS), instance size (Azure) or machine type (GCP).
+	InstanceType string `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+
+	// Region (AWS), location (Azure) or zone (GCP).
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Pubkey ID.
+	PubkeyID *int64 `json:"pubkey_id,omitempty" yaml:"pubkey_id,omitempty"`
+
+	// Arbitrary user-defined key-value pairs.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// See models.ReservationTemplate
+type ReservationTemplateResponse struct {
+	ID           int64             `json:"id" yaml:"id"`
+	Name         string            `json:"name" yaml:"name"`
+	Provider     string            `json:"provider" yaml:"provider"`
+	SourceID     string            `json:"source_id,omitempty" yaml:"source_id,omitempty"`
+	ImageID      string            `json:"image_id,omitempty" yaml:"image_id,omitempty"`
+	InstanceType string            `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+	Region       string            `json:"region,omitempty" yaml:"region,omitempty"`
+	PubkeyID     *int64            `json:"pubkey_id,omitempty" yaml:"pubkey_id,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	CreatedAt    time.Time         `json:"created_at" yaml:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" yaml:"updated_at"`
+}
+
+// TemplateReservationRequestPayload creates a reservation from a reservation template. All fields
+// except the template ID are optional and override the corresponding template field when set.
+type TemplateReservationRequestPayload struct {
+	// Reservation template ID. Required.
+	TemplateID int64 `json:"template_id" yaml:"template_id"`
+
+	// Name of the instance(s) (AWS, Azure) or name pattern (GCP).
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Source ID, overrides the template.
+	SourceID string `json:"source_id,omitempty" yaml:"source_id,omitempty"`
+
+	// Image ID, overrides the template.
+	ImageID string `json:"image_id,omitempty" yaml:"image_id,omitempty"`
+
+	// Instance type, instance size or machine type, overrides the template.
+	InstanceType string `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+
+	// Region, location or zone, overrides the template.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Pubkey ID, overrides the template.
+	PubkeyID int64 `json:"pubkey_id,omitempty" yaml:"pubkey_id,omitempty"`
+
+	// Amount of instances to provision, defaults to 1.
+	Amount int64 `json:"amount,omitempty" yaml:"amount,omitempty"`
+
+	// Immediately power off the system after initialization.
+	PowerOff bool `json:"poweroff,omitempty" yaml:"poweroff,omitempty"`
+
+	// Reject the reservation when the instance type has no GPU.
+	RequireGPU bool `json:"require_gpu,omitempty" yaml:"require_gpu,omitempty"`
+
+	// Tags of the cloud resources, merged with the template tags.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Validate the launch without creating the reservation.
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+func (p *ReservationTemplateRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	v.tags("tags", p.Tags)
+	return v.err()
+}
+
+func (p *ReservationTemplateResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func (p *TemplateReservationRequestPayload) Bind(_ *http.Request) error {
+	v := validator{}
+	v.tags("tags", p.Tags)
+	return v.err()
+}
+
+// NewModel returns a new template model, provider is not validated.
+func (p *ReservationTemplateRequest) NewModel() *models.ReservationTemplate {
+	return &models.ReservationTemplate{
+		Name:         p.Name,
+		Provider:     models.ProviderTypeFromString(p.Provider),
+		SourceID:     p.SourceID,
+		ImageID:      p.ImageID,
+		InstanceType: p.InstanceType,
+		Region:       p.Region,
+		PubkeyID:     p.PubkeyID,
+		Tags:         p.Tags,
+	}
+}
+
+func NewReservationTemplateResponse(template *models.ReservationTemplate) render.Renderer {
+	return &ReservationTemplateResponse{
+		ID:           template.ID,
+		Name:         template.Name,
+		Provider:     template.Provider.String(),
+		SourceID:     template.SourceID,
+		ImageID:      template.ImageID,
+		InstanceType: template.InstanceType,
+		Region:       template.Region,
+		PubkeyID:     template.PubkeyID,
+		Tags:         template.Tags,
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}
+}
+
+func NewReservationTemplateListResponse(templates []*models.ReservationTemplate) []render.Renderer {
+	list := make([]render.Renderer, len(templates))
+	for i, template := range templates {
+		list[i] = NewReservationTemplateResponse(template)
+	}
+	return list
+}
+
+// NewAWSReservationRequest merges template and request overrides into AWS reservation payload.
+func (p *TemplateReservationRequestPayload) NewAWSReservationRequest(template *models.ReservationTemplate) *AWSReservationRequestPayload {
+	return &AWSReservationRequestPayload{
+		PubkeyID:     p.pubkeyID(template),
+		SourceID:     override(template.SourceID, p.SourceID),
+		Region:       override(template.Region, p.Region),
+		Name:         p.Name,
+		InstanceType: override(template.InstanceType, p.InstanceType),
+		Amount:       int32(p.amount()),
+		ImageID:      override(template.ImageID, p.ImageID),
+		PowerOff:     p.PowerOff,
+		RequireGPU:   p.RequireGPU,
+		Tags:         p.tags(template),
+		DryRun:       p.DryRun,
+	}
+}
+
+// NewAzureReservationRequest merges template and request overrides into Azure reservation payload.
+func (p *TemplateReservationRequestPayload) NewAzureReservationRequest(template *models.ReservationTemplate) *AzureReservationRequestPayload {
+	return &AzureReservationRequestPayload{
+		PubkeyID:     p.pubkeyID(template),
+		SourceID:     override(template.SourceID, p.SourceID),
+		ImageID:      override(template.ImageID, p.ImageID),
+		Location:     override(template.Region, p.Region),
+		InstanceSize: override(template.InstanceType, p.InstanceType),
+		Amount:       p.amount(),
+		Name:         p.Name,
+		PowerOff:     p.PowerOff,
+		RequireGPU:   p.RequireGPU,
+		Tags:         p.tags(template),
+		DryRun:       p.DryRun,
+	}
+}
+
+// NewGCPReservationRequest merges template and request overrides into GCP reservation payload.
+func (p *TemplateReservationRequestPayload) NewGCPReservationRequest(template *models.ReservationTemplate) *GCPReservationRequestPayload {
+	return &GCPReservationRequestPayload{
+		PubkeyID:    p.pubkeyID(template),
+		SourceID:    override(template.SourceID, p.SourceID),
+		NamePattern: p.Name,
+		Zone:        override(template.Region, p.Region),
+		MachineType: override(template.InstanceType, p.InstanceType),
+		Amount:      p.amount(),
+		ImageID:     override(template.ImageID, p.ImageID),
+		PowerOff:    p.PowerOff,
+		RequireGPU:  p.RequireGPU,
+		Tags:        p.tags(template),
+		DryRun:      p.DryRun,
+	}
+}
+
+func (p *TemplateReservationRequestPayload) pubkeyID(template *models.ReservationTemplate) int64 {
+	if p.PubkeyID != 0 || template.PubkeyID == nil {
+		return p.PubkeyID
+	}
+	return *template.PubkeyID
+}
+
+func (p *TemplateReservationRequestPayload) amount() int64 {
+	if p.Amount <= 0 {
+		return 1
+	}
+	return p.Amount
+}
+
+// tags returns template tags with the request tags, request tags take precedence.
+func (p *TemplateReservationRequestPayload) tags(template *models.ReservationTemplate) map[string]string {
+	if len(template.Tags) == 0 && len(p.Tags) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(template.Tags)+len(p.Tags))
+	for key, value := range template.Tags {
+		result[key] = value
+	}
+	for key, value := range p.Tags {
+		result[key] = value
+	}
+	return result
+}
+
+func override(value, override string) string {
+	if override != "" {
+		return override
+	}
+	return value
+}
@@ -0,0 +1,61 @@
+package payloads
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateReservationOverrides(t *testing.T) {
+	pubkeyID := int64(3)
+	template := &models.ReservationTemplate{
+		Provider:     models.ProviderTypeGCP,
+		SourceID:     "1",
+		ImageID:      "image",
+		InstanceType: "n1-standard-1",
+		Region:       "us-east4-a",
+		PubkeyID:     &pubkeyID,
+	}
+
+	t.Run("template values", func(t *testing.T) {
+		request := &TemplateReservationRequestPayload{TemplateID: 1}
+		gcp := request.NewGCPReservationRequest(template)
+
+		assert.Equal(t, "1", gcp.SourceID)
+		assert.Equal(t, "image", gcp.ImageID)
+		assert.Equal(t, "n1-standard-1", gcp.MachineType)
+		assert.Equal(t, "us-east4-a", gcp.Zone)
+		assert.Equal(t, pubkeyID, gcp.PubkeyID)
+		assert.Equal(t, int64(1), gcp.Amount)
+	})
+
+	t.Run("overridden values", func(t *testing.T) {
+		request := &TemplateReservationRequestPayload{
+			TemplateID:   1,
+			InstanceType: "n1-standard-8",
+			Region:       "europe-west1-b",
+			PubkeyID:     5,
+			Amount:       3,
+		}
+		gcp := request.NewGCPReservationRequest(template)
+
+		assert.Equal(t, "image", gcp.ImageID)
+		assert.Equal(t, "n1-standard-8", gcp.MachineType)
+		assert.Equal(t, "europe-west1-b", gcp.Zone)
+		assert.Equal(t, int64(5), gcp.PubkeyID)
+		assert.Equal(t, int64(3), gcp.Amount)
+	})
+}
+
+func TestTemplateReservationTags(t *testing.T) {
+	template := &models.ReservationTemplate{
+		Provider: models.ProviderTypeAWS,
+		Tags:     map[string]string{"cost-center": "1234", "team": "a"},
+	}
+	request := &TemplateReservationRequestPayload{TemplateID: 1, Tags: map[string]string{"team": "b"}}
+	aws := request.NewAWSReservationRequest(template)
+
+	assert.Equal(t, map[string]string{"cost-center": "1234", "team": "b"}, aws.Tags)
+	assert.Equal(t, "a", template.Tags["team"], "template tags must not be modified")
+}
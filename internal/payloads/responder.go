@@ -0,0 +1,29 @@
+package payloads
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+	"github.com/go-chi/render"
+)
+
+// Versioned is implemented by payloads which have a different shape in newer API versions
+// (e.g. renamed fields or a different error format). Handlers are shared by all versions
+// and always render the v1 payload, conversion happens in Respond.
+type Versioned interface {
+	// ForVersion returns the payload in the shape of the API version.
+	ForVersion(apiVersion string) any
+}
+
+// Respond is the chi responder of the application, it must be set as render.Respond. It
+// converts versioned payloads into the shape of the API version of the request.
+func Respond(w http.ResponseWriter, r *http.Request, v any) {
+	if versioned, ok := v.(Versioned); ok {
+		v = versioned.ForVersion(version.APIVersion(r.Context()))
+	}
+	if problem, ok := v.(*ProblemDetails); ok {
+		writeProblem(w, r, problem)
+		return
+	}
+	render.DefaultResponder(w, r, v)
+}
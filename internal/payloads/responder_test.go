@@ -0,0 +1,78 @@
+package payloads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/version"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderVersioned(t *testing.T, apiVersion string, renderer render.Renderer) *httptest.ResponseRecorder {
+	t.Helper()
+
+	ctx := version.WithAPIVersion(context.Background(), apiVersion)
+	req, err := http.NewRequestWithContext(ctx, "GET", "/", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	require.NoError(t, renderer.Render(rr, req))
+	Respond(rr, req, renderer)
+	return rr
+}
+
+func TestRespondError(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("v1", func(t *testing.T) {
+		rr := renderVersioned(t, version.APIPathVersion, NewNotFoundError(ctx, "pubkey", errors.New("no rows")))
+
+		assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+		assert.Contains(t, body, "msg")
+		assert.NotContains(t, body, "title")
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		rr := renderVersioned(t, version.APIPathVersionV2, NewNotFoundError(ctx, "pubkey", errors.New("no rows")))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+		var problem ProblemDetails
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+		assert.Equal(t, "about:blank", problem.Type)
+		assert.Equal(t, "Not Found", problem.Title)
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.NotEmpty(t, problem.Detail)
+	})
+}
+
+type versionedItem struct{}
+
+func (versionedItem) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func (versionedItem) ForVersion(apiVersion string) any {
+	return map[string]string{"version": apiVersion}
+}
+
+func TestRespondList(t *testing.T) {
+	rr := renderVersioned(t, version.APIPathVersionV2, NewListResponse([]render.Renderer{versionedItem{}}))
+
+	var body struct {
+		Data []map[string]string `json:"data"`
+		Meta ListMeta            `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, version.APIPathVersionV2, body.Data[0]["version"])
+	assert.Equal(t, 1, body.Meta.Count)
+}
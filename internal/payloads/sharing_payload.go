@@ -0,0 +1,18 @@
+package payloads
+
+import "net/http"
+
+// SharingRequest shares a reservation or a pubkey with all users of the organization, or makes
+// it visible only to its owner again. Other users can read shared resources but not modify them.
+type SharingRequest struct {
+	// Share with all users of the organization. Required.
+	Shared *bool `json:"shared" yaml:"shared"`
+}
+
+func (p *SharingRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	if p.Shared == nil {
+		v.add("shared", ConstraintRequired, "shared is required")
+	}
+	return v.err()
+}
@@ -0,0 +1,59 @@
+package payloads
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// SSHConnectionResponse describes how to connect to an instance of a reservation.
+type SSHConnectionResponse struct {
+	// Instance ID which has been created on a cloud provider.
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
+
+	// Public IPv4 address of the instance, empty when the instance has no public address.
+	Host string `json:"host" yaml:"host"`
+
+	// User to log in as, depends on the provider and the image distribution.
+	User string `json:"user" yaml:"user"`
+
+	// Ready to paste SSH command, empty when the instance has no public address.
+	Command string `json:"command" yaml:"command"`
+
+	// Name of the public key deployed to the instance, empty when the key was deleted.
+	PubkeyName string `json:"pubkey_name,omitempty" yaml:"pubkey_name,omitempty"`
+
+	// SHA256 fingerprint of the public key deployed to the instance.
+	PubkeyFingerprint string `json:"pubkey_fingerprint,omitempty" yaml:"pubkey_fingerprint,omitempty"`
+}
+
+func (s *SSHConnectionResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// NewSSHConnectionResponse returns connection information of an instance, pubkey can be nil.
+func NewSSHConnectionResponse(instance *models.ReservationInstance, user string, pubkey *models.Pubkey) *SSHConnectionResponse {
+	response := &SSHConnectionResponse{
+		InstanceID: instance.InstanceID,
+		Host:       instance.Detail.PublicIPv4,
+		User:       user,
+	}
+	if response.Host != "" {
+		response.Command = fmt.Sprintf("ssh %s@%s", user, response.Host)
+	}
+	if pubkey != nil {
+		response.PubkeyName = pubkey.Name
+		response.PubkeyFingerprint = pubkey.Fingerprint
+	}
+	return response
+}
+
+func NewListSSHConnectionResponse(connections []*SSHConnectionResponse) []render.Renderer {
+	list := make([]render.Renderer, len(connections))
+	for i, connection := range connections {
+		list[i] = connection
+	}
+	return list
+}
@@ -0,0 +1,172 @@
+package payloads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// Validation constraints
+const (
+	ConstraintRequired = "required"
+	ConstraintMin      = "min"
+	ConstraintMax      = "max"
+	ConstraintOneOf    = "one_of"
+	ConstraintFormat   = "format"
+)
+
+// FieldViolation is a single invalid field of a request payload.
+type FieldViolation struct {
+	// JSON path of the field, e.g. "pubkey_id"
+	Field string `json:"field" yaml:"field"`
+
+	// Violated constraint: "required", "min", "one_of" or "format"
+	Constraint string `json:"constraint" yaml:"constraint"`
+
+	// User facing message
+	Message string `json:"message" yaml:"message"`
+}
+
+// ValidationError is returned from Bind functions of request payloads and lists all invalid
+// fields so UI can highlight them in forms.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		fields[i] = v.Field
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(fields, ", "))
+}
+
+// validator collects field violations of a request payload.
+type validator struct {
+	violations []FieldViolation
+}
+
+func (v *validator) add(field, constraint, message string) {
+	v.violations = append(v.violations, FieldViolation{Field: field, Constraint: constraint, Message: message})
+}
+
+func (v *validator) requireString(field, value string) {
+	if value == "" {
+		v.add(field, ConstraintRequired, fmt.Sprintf("%s is required", field))
+	}
+}
+
+func (v *validator) requireID(field string, value int64) {
+	if value == 0 {
+		v.add(field, ConstraintRequired, fmt.Sprintf("%s is required", field))
+	}
+}
+
+func (v *validator) atLeast(field string, value, minimum int64) {
+	if value < minimum {
+		v.add(field, ConstraintMin, fmt.Sprintf("%s must be at least %d", field, minimum))
+	}
+}
+
+func (v *validator) atMost(field string, value, maximum int64) {
+	if value > maximum {
+		v.add(field, ConstraintMax, fmt.Sprintf("%s must be at most %d", field, maximum))
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, ConstraintOneOf, fmt.Sprintf("%s must be one of: %s", field, strings.Join(allowed, ", ")))
+}
+
+// httpsURL requires an absolute HTTPS URL, plain HTTP is accepted only when allowHTTP is set.
+func (v *validator) httpsURL(field, value string, allowHTTP bool) {
+	u, err := url.Parse(value)
+	if err == nil && u.Host != "" && (u.Scheme == "https" || (allowHTTP && u.Scheme == "http")) {
+		return
+	}
+	v.add(field, ConstraintFormat, fmt.Sprintf("%s must be an absolute HTTPS URL", field))
+}
+
+// matches requires the value to match the regular expression, description completes the
+// "field must be" message.
+func (v *validator) matches(field, value string, re *regexp.Regexp, description string) {
+	if !re.MatchString(value) {
+		v.add(field, ConstraintFormat, fmt.Sprintf("%s must be %s", field, description))
+	}
+}
+
+// tagKey rejects blank keys and keys of standard tags set by the service.
+func (v *validator) tagKey(field, key string) {
+	if key == "" {
+		v.add(field, ConstraintFormat, fmt.Sprintf("%s must not contain blank keys", field))
+	} else if models.IsStandardTag(key) {
+		v.add(field, ConstraintFormat, fmt.Sprintf("%s must not contain %s, it is set by the service", field, key))
+	}
+}
+
+// tags validates keys of user tags of a reservation in alphabetical order.
+func (v *validator) tags(field string, tags map[string]string) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v.tagKey(field, key)
+	}
+}
+
+// err returns nil when there are no violations, the return type must be error interface.
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.violations}
+}
+
+func NewValidationError(ctx context.Context, message string, err *ValidationError) *ResponseError {
+	response := NewInvalidRequestError(ctx, message, err)
+	response.Fields = err.Violations
+	return response
+}
+
+// NewRequiredFieldError returns a validation error of a single required field, it is meant for
+// fields which are required only after account defaults were applied.
+func NewRequiredFieldError(ctx context.Context, message, field string) *ResponseError {
+	v := validator{}
+	v.add(field, ConstraintRequired, fmt.Sprintf("%s is required", field))
+	return NewValidationError(ctx, message, &ValidationError{Violations: v.violations})
+}
+
+// RequiredTagsError returns validation error listing required tags which are missing or blank,
+// nil is returned when all required tags are set.
+func RequiredTagsError(tags map[string]string, required []string) error {
+	v := validator{}
+	for _, key := range required {
+		if tags[key] == "" {
+			v.add("tags."+key, ConstraintRequired, fmt.Sprintf("tag %s is required by account settings", key))
+		}
+	}
+	return v.err()
+}
+
+// NewBindError returns a validation error with field violations when binding failed on
+// validation, or a generic invalid request error (e.g. malformed JSON).
+func NewBindError(ctx context.Context, message string, err error) *ResponseError {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return NewValidationError(ctx, message, validationErr)
+	}
+	return NewInvalidRequestError(ctx, message, err)
+}
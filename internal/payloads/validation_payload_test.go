@@ -0,0 +1,196 @@
+package payloads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSReservationRequestValidation(t *testing.T) {
+	p := &AWSReservationRequestPayload{SourceID: "1"}
+	err := p.Bind(nil)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "image_id", Constraint: ConstraintRequired, Message: "image_id is required"},
+		{Field: "amount", Constraint: ConstraintMin, Message: "amount must be at least 1"},
+	}, validationErr.Violations)
+	assert.Equal(t, "validation failed: image_id, amount", err.Error())
+
+	p = &AWSReservationRequestPayload{PubkeyID: 1, SourceID: "1", ImageID: "ami-1", Amount: 1}
+	require.NoError(t, p.Bind(nil))
+
+	// pubkey can be set by account settings
+	p = &AWSReservationRequestPayload{SourceID: "1", ImageID: "ami-1", Amount: 1}
+	require.NoError(t, p.Bind(nil))
+
+	p = &AWSReservationRequestPayload{SourceID: "1", ImageID: "ami-1", Amount: 1, Tenancy: "host", HostID: "h-0a5bd3c4e8f9d1e2f"}
+	require.NoError(t, p.Bind(nil))
+
+	p = &AWSReservationRequestPayload{SourceID: "1", ImageID: "ami-1", Amount: 1, Tenancy: "shared", HostID: "h-0a5bd3c4e8f9d1e2f"}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "tenancy", Constraint: ConstraintOneOf, Message: "tenancy must be one of: default, dedicated, host"},
+		{Field: "host_id", Constraint: ConstraintFormat, Message: "host_id requires host tenancy"},
+	}, validationErr.Violations)
+
+	p = &AWSReservationRequestPayload{SourceID: "1", ImageID: "ami-1", Amount: 1, InstanceTypes: []string{"c5.xlarge", "m5.xlarge"}}
+	require.NoError(t, p.Bind(nil))
+
+	p = &AWSReservationRequestPayload{SourceID: "1", ImageID: "ami-1", Amount: 1, InstanceTypes: []string{"c5.xlarge"}, InstanceType: "t3.small", LaunchTemplateID: "lt-1"}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Len(t, validationErr.Violations, 2)
+}
+
+func TestAzureReservationRequestValidation(t *testing.T) {
+	p := &AzureReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ResourceGroupStrategy: "existing", ResourceGroup: "test"}
+	require.NoError(t, p.Bind(nil))
+
+	p = &AzureReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ResourceGroupStrategy: "reservation", ResourceGroupTags: map[string]string{"team": "qe"}}
+	require.NoError(t, p.Bind(nil))
+
+	p = &AzureReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ResourceGroupStrategy: "existing", ResourceGroupTags: map[string]string{"team": "qe"}}
+	var validationErr *ValidationError
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "resource_group", Constraint: ConstraintRequired, Message: "resource_group is required"},
+		{Field: "resource_group_tags", Constraint: ConstraintFormat, Message: "resource_group_tags requires reservation resource_group_strategy"},
+	}, validationErr.Violations)
+
+	price := 0.05
+	p = &AzureReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, Priority: "spot", MaxPrice: &price, EvictionPolicy: "delete"}
+	require.NoError(t, p.Bind(nil))
+
+	price = 0
+	p = &AzureReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, Priority: "spot", MaxPrice: &price, EvictionPolicy: "stop"}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "eviction_policy", Constraint: ConstraintOneOf, Message: "eviction_policy must be one of: deallocate, delete"},
+		{Field: "max_price", Constraint: ConstraintMin, Message: "max_price must be -1 or greater than 0"},
+	}, validationErr.Violations)
+
+	p = &AzureReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, EvictionPolicy: "delete"}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, "eviction_policy", validationErr.Violations[0].Field)
+}
+
+func TestGCPReservationRequestValidation(t *testing.T) {
+	p := &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ProvisioningModel: "SPOT", TerminationAction: "DELETE"}
+	require.NoError(t, p.Bind(nil))
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ProvisioningModel: "SPOT", TerminationAction: "HIBERNATE"}
+	var validationErr *ValidationError
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "termination_action", Constraint: ConstraintOneOf, Message: "termination_action must be one of: STOP, DELETE"},
+	}, validationErr.Violations)
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, TerminationAction: "STOP"}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "termination_action", Constraint: ConstraintFormat, Message: "termination_action requires SPOT provisioning_model"},
+	}, validationErr.Violations)
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ServiceAccount: "app@project.iam.gserviceaccount.com", Scopes: []string{"https://www.googleapis.com/auth/devstorage.read_only"}}
+	require.NoError(t, p.Bind(nil))
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ServiceAccount: "none", Scopes: []string{"cloud-platform"}}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "scopes", Constraint: ConstraintFormat, Message: "scopes requires service_account"},
+		{Field: "scopes", Constraint: ConstraintFormat, Message: "scope cloud-platform must be a https://www.googleapis.com/auth/ URL"},
+	}, validationErr.Violations)
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, ServiceAccount: "app"}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, "service_account", validationErr.Violations[0].Field)
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, Network: "projects/host-project/global/networks/shared", Subnetwork: "projects/host-project/regions/us-east1/subnetworks/team-a"}
+	require.NoError(t, p.Bind(nil))
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, Network: "vpc", Subnetwork: "subnet"}
+	require.NoError(t, p.Bind(nil))
+
+	p = &GCPReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, Network: "regions/us-east1/networks/vpc", Subnetwork: "global/subnetworks/subnet"}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, []FieldViolation{
+		{Field: "network", Constraint: ConstraintFormat, Message: "network must be a network name or path"},
+		{Field: "subnetwork", Constraint: ConstraintFormat, Message: "subnetwork must be a subnetwork name or path"},
+	}, validationErr.Violations)
+}
+
+func TestPubkeyImportRequestValidation(t *testing.T) {
+	p := &PubkeyImportRequest{Site: "GitHub", Username: "user"}
+	require.NoError(t, p.Bind(nil))
+
+	p = &PubkeyImportRequest{Site: "bitbucket"}
+	var validationErr *ValidationError
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	require.Len(t, validationErr.Violations, 2)
+	assert.Equal(t, ConstraintOneOf, validationErr.Violations[0].Constraint)
+	assert.Equal(t, "username", validationErr.Violations[1].Field)
+}
+
+func TestWebhookRequestValidation(t *testing.T) {
+	p := &WebhookRequest{URL: "https://example.com/hook", Events: []string{"reservation.success"}}
+	require.NoError(t, p.Bind(nil))
+
+	p = &WebhookRequest{URL: "http://example.com/hook", Events: []string{"reservation.started"}}
+	var validationErr *ValidationError
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	require.Len(t, validationErr.Violations, 2)
+	assert.Equal(t, ConstraintFormat, validationErr.Violations[0].Constraint)
+	assert.Equal(t, ConstraintOneOf, validationErr.Violations[1].Constraint)
+
+	p = &WebhookRequest{}
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	assert.Equal(t, "validation failed: url, events", validationErr.Error())
+}
+
+func TestReservationTagsValidation(t *testing.T) {
+	p := &AzureReservationRequestPayload{SourceID: "1", ImageID: "image", Amount: 1, Tags: map[string]string{"team": "qe"}}
+	require.NoError(t, p.Bind(nil))
+
+	p.Tags = map[string]string{"managed-by": "me", "": "blank"}
+	var validationErr *ValidationError
+	require.ErrorAs(t, p.Bind(nil), &validationErr)
+	require.Len(t, validationErr.Violations, 2)
+	assert.Equal(t, "tags must not contain blank keys", validationErr.Violations[0].Message)
+	assert.Equal(t, "tags must not contain managed-by, it is set by the service", validationErr.Violations[1].Message)
+}
+
+func TestRequiredTagsError(t *testing.T) {
+	require.NoError(t, RequiredTagsError(map[string]string{"cost-center": "1"}, []string{"cost-center"}))
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, RequiredTagsError(map[string]string{"cost-center": ""}, []string{"cost-center", "team"}), &validationErr)
+	assert.Equal(t, []string{"tags.cost-center", "tags.team"},
+		[]string{validationErr.Violations[0].Field, validationErr.Violations[1].Field})
+}
+
+func TestNewRequiredFieldError(t *testing.T) {
+	response := NewRequiredFieldError(context.Background(), "AWS reservation", "pubkey_id")
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatusCode)
+	assert.Equal(t, []FieldViolation{
+		{Field: "pubkey_id", Constraint: ConstraintRequired, Message: "pubkey_id is required"},
+	}, response.Fields)
+}
+
+func TestNewBindError(t *testing.T) {
+	ctx := context.Background()
+
+	validationErr := &ValidationError{Violations: []FieldViolation{{Field: "name", Constraint: ConstraintRequired}}}
+	response := NewBindError(ctx, "create pubkey", fmt.Errorf("bind: %w", validationErr))
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatusCode)
+	assert.Equal(t, validationErr.Violations, response.Fields)
+
+	response = NewBindError(ctx, "create pubkey", errors.New("EOF"))
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatusCode)
+	assert.Empty(t, response.Fields)
+}
@@ -0,0 +1,129 @@
+package payloads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.Webhook
+type WebhookRequest struct {
+	// HTTPS URL deliveries are sent to.
+	URL string `json:"url" yaml:"url"`
+
+	// Optional secret used to sign deliveries, a random secret is generated when empty.
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// Subscribed events: reservation.success, reservation.failure or instance.terminated.
+	Events []string `json:"events" yaml:"events"`
+}
+
+// See models.Webhook
+type WebhookResponse struct {
+	ID     int64    `json:"id" yaml:"id"`
+	URL    string   `json:"url" yaml:"url"`
+	Events []string `json:"events" yaml:"events"`
+
+	// Secret is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// See models.WebhookDelivery
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id" yaml:"id"`
+	Event          string     `json:"event" yaml:"event"`
+	Status         string     `json:"status" yaml:"status"`
+	Attempts       int32      `json:"attempts" yaml:"attempts"`
+	ResponseStatus int32      `json:"response_status,omitempty" yaml:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty" yaml:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" yaml:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" yaml:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" yaml:"delivered_at,omitempty"`
+}
+
+func (p *WebhookRequest) Bind(_ *http.Request) error {
+	v := validator{}
+	v.requireString("url", p.URL)
+	if p.URL != "" {
+		v.httpsURL("url", p.URL, config.Webhooks.AllowHTTP)
+	}
+	if len(p.Events) == 0 {
+		v.add("events", ConstraintRequired, "events is required")
+	}
+	for _, event := range p.Events {
+		v.oneOf("events", event, models.WebhookEvents()...)
+	}
+	return v.err()
+}
+
+func (p *WebhookResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func (p *WebhookDeliveryResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// NewModel returns webhook model, the secret must be generated by the caller when empty.
+func (p *WebhookRequest) NewModel() *models.Webhook {
+	return &models.Webhook{
+		URL:    p.URL,
+		Secret: p.Secret,
+		Events: p.Events,
+	}
+}
+
+// NewWebhookResponse returns webhook without the secret.
+func NewWebhookResponse(webhook *models.Webhook) render.Renderer {
+	return &WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+// NewWebhookCreatedResponse returns webhook including the secret, it is only shown once.
+func NewWebhookCreatedResponse(webhook *models.Webhook) render.Renderer {
+	response := NewWebhookResponse(webhook).(*WebhookResponse)
+	response.Secret = webhook.Secret
+	return response
+}
+
+func NewWebhookListResponse(webhooks []*models.Webhook) []render.Renderer {
+	list := make([]render.Renderer, len(webhooks))
+	for i, webhook := range webhooks {
+		list[i] = NewWebhookResponse(webhook)
+	}
+	return list
+}
+
+func NewWebhookDeliveryResponse(delivery *models.WebhookDelivery) render.Renderer {
+	response := &WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		Event:          string(delivery.Event),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return response
+}
+
+func NewWebhookDeliveryListResponse(deliveries []*models.WebhookDelivery) []render.Renderer {
+	list := make([]render.Renderer, len(deliveries))
+	for i, delivery := range deliveries {
+		list[i] = NewWebhookDeliveryResponse(delivery)
+	}
+	return list
+}
@@ -2,6 +2,7 @@ package preload
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/middleware"
@@ -11,8 +12,10 @@ type instanceType struct {
 	filename string
 	path     string
 	etagName string
+	typesBuf []byte
 	tag      *middleware.ETag
 	typeInfo clients.InstanceTypeInfo
+	mu       sync.RWMutex
 }
 
 func (p *instanceType) Load() error {
@@ -33,6 +36,7 @@ func (p *instanceType) Load() error {
 		return fmt.Errorf("unable to load regional info %s: %w", p.path, err)
 	}
 
+	p.typesBuf = typesBuf
 	availBuf := clients.ConcatBuffers(fsTypes, p.path)
 	p.tag, err = middleware.GenerateETagFromBuffer(p.etagName, middleware.InstanceTypeExpiration, typesBuf, availBuf)
 	if err != nil {
@@ -41,8 +45,48 @@ func (p *instanceType) Load() error {
 	return nil
 }
 
-// ETagValue returns HTTP ETag information. It is calculated as a hash from source YAML files.
+// UpdateAvailability replaces embedded regional availability with data refreshed from provider
+// APIs. Instance types which are not registered (embedded) are skipped as there is no detailed
+// information available about them. ETag value is recalculated.
+func (p *instanceType) UpdateAvailability(rit *clients.RegionalTypeAvailability) error {
+	p.mu.RLock()
+	filtered := clients.NewRegionalInstanceTypes()
+	err := rit.Each(func(region, zone string, names []clients.InstanceTypeName) error {
+		known := make([]clients.InstanceTypeName, 0, len(names))
+		for _, name := range names {
+			if p.typeInfo.RegisteredTypes.Get(name) != nil {
+				known = append(known, name)
+			}
+		}
+		filtered.Set(region, zone, known)
+		return nil
+	})
+	p.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("unable to filter regional info %s: %w", p.path, err)
+	}
+
+	availBuf, err := filtered.Bytes()
+	if err != nil {
+		return fmt.Errorf("unable to serialize regional info %s: %w", p.path, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tag, err := middleware.RegenerateETagFromBuffer(p.tag, p.typesBuf, availBuf)
+	if err != nil {
+		return fmt.Errorf("unable to generate etag %s: %w", p.etagName, err)
+	}
+	p.typeInfo.RegionalAvailability = *filtered
+	p.tag = tag
+	return nil
+}
+
+// ETagValue returns HTTP ETag information. It is calculated as a hash from source YAML files
+// or refreshed availability data.
 func (p *instanceType) ETagValue() *middleware.ETag {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.tag
 }
 
@@ -53,14 +97,18 @@ func (p *instanceType) PrintRegisteredTypes(typeName string) {
 
 // PrintRegionalAvailability prints relevant data to standard output.
 func (p *instanceType) PrintRegionalAvailability(region, zone string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	str := p.typeInfo.RegionalAvailability.Sprint(region, zone)
 	fmt.Println(str)
 }
 
-// InstanceTypesForZone returns instance type info for particular zone. Can list supported, unsupported
-// or all types when nil is passed.
-func (p *instanceType) InstanceTypesForZone(region, zone string, supported *bool) ([]*clients.InstanceType, error) {
-	result, err := p.typeInfo.InstanceTypesForZone(region, zone, supported)
+// InstanceTypesForZone returns instance type info for particular zone narrowed down by the filter.
+// Lists all types when nil filter is passed.
+func (p *instanceType) InstanceTypesForZone(region, zone string, filter *clients.InstanceTypeFilter) ([]*clients.InstanceType, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	result, err := p.typeInfo.InstanceTypesForZone(region, zone, filter)
 	if err != nil {
 		return nil, fmt.Errorf("unable to list instance types for region and zone: %w", err)
 	}
@@ -72,18 +120,9 @@ func (p *instanceType) FindInstanceType(name clients.InstanceTypeName) *clients.
 	return p.typeInfo.RegisteredTypes.Get(name)
 }
 
-// ValidateRegion checks if a region is preloaded.
+// ValidateRegion checks if a region is preloaded or refreshed.
 func (p *instanceType) ValidateRegion(region string) bool {
-	dirEntries, err := fsTypes.ReadDir(p.path)
-	if err != nil {
-		panic(fmt.Errorf("unable to read availability dir: %w", err))
-	}
-
-	fullName := region + ".yaml"
-	for _, e := range dirEntries {
-		if e.Name() == fullName {
-			return true
-		}
-	}
-	return false
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.typeInfo.RegionalAvailability.Contains(region)
 }
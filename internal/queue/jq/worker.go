@@ -3,8 +3,10 @@ package jq
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
@@ -20,16 +22,67 @@ func getEnqueuer(_ context.Context) worker.JobEnqueuer {
 	return enqueuer
 }
 
-func init() {
-	queue.GetEnqueuer = getEnqueuer
-}
-
 func RegisterJobs(logger *zerolog.Logger) {
 	logger.Debug().Msg("Registering job queue handlers and interfaces")
 	workers.RegisterHandler(jobs.TypeNoop, jobs.HandleNoop, jobs.NoopJobArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceAws, jobs.HandleLaunchInstanceAWS, jobs.LaunchInstanceAWSTaskArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceAzure, jobs.HandleLaunchInstanceAzure, jobs.LaunchInstanceAzureTaskArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceGcp, jobs.HandleLaunchInstanceGCP, jobs.LaunchInstanceGCPTaskArgs{})
+	workers.RegisterHandler(jobs.TypeRefreshAvailability, jobs.HandleRefreshAvailability, jobs.RefreshAvailabilityTaskArgs{})
+	workers.RegisterHandler(jobs.TypeDeletePubkeyResource, jobs.HandleDeletePubkeyResource, jobs.DeletePubkeyResourceTaskArgs{})
+	workers.RegisterHandler(jobs.TypeNotifyPubkeyExpiry, jobs.HandleNotifyPubkeyExpiry, jobs.NotifyPubkeyExpiryTaskArgs{})
+	workers.RegisterHandler(jobs.TypeNotifyExpiryDigest, jobs.HandleNotifyExpiryDigest, jobs.NotifyExpiryDigestTaskArgs{})
+	workers.RegisterHandler(jobs.TypeProbeSSH, jobs.HandleProbeSSH, jobs.ProbeSSHTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionAws, jobs.HandleInstanceActionAWS, jobs.InstanceActionTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionAzure, jobs.HandleInstanceActionAzure, jobs.InstanceActionTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionGcp, jobs.HandleInstanceActionGCP, jobs.InstanceActionTaskArgs{})
+
+	// launch and instance action jobs are not idempotent and are never retried, failed instance
+	// actions are marked as failed and can be requested again
+	workers.RegisterRetryPolicy(jobs.TypeRefreshAvailability, worker.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: 10 * time.Minute})
+	workers.RegisterRetryPolicy(jobs.TypeDeletePubkeyResource, worker.RetryPolicy{MaxAttempts: 5, Backoff: 30 * time.Second, MaxBackoff: 10 * time.Minute})
+	workers.RegisterRetryPolicy(jobs.TypeNotifyPubkeyExpiry, worker.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: 10 * time.Minute})
+	workers.RegisterRetryPolicy(jobs.TypeNotifyExpiryDigest, worker.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: 10 * time.Minute})
+
+	// scheduled and bulk jobs must not starve reservation launches
+	workers.RegisterPriority(jobs.TypeRefreshAvailability, worker.PriorityBackground)
+	workers.RegisterPriority(jobs.TypeDeletePubkeyResource, worker.PriorityBackground)
+	workers.RegisterPriority(jobs.TypeNotifyPubkeyExpiry, worker.PriorityBackground)
+	workers.RegisterPriority(jobs.TypeNotifyExpiryDigest, worker.PriorityBackground)
+
+	// bulk launches of a single organization must not starve other organizations
+	workers.RegisterTenantLimit(config.Current().TenantConcurrency, jobs.TypeLaunchInstanceAws, jobs.TypeLaunchInstanceAzure, jobs.TypeLaunchInstanceGcp)
+	config.OnChange(func(previous, current *config.Runtime) {
+		if previous.TenantConcurrency != current.TenantConcurrency {
+			logger.Info().Msgf("Tenant concurrency changed to %d", current.TenantConcurrency)
+			workers.SetTenantLimit(current.TenantConcurrency)
+		}
+	})
+
+	// bump the version and add an upgrader when arguments change incompatibly, unversioned
+	// jobs enqueued before versioning was introduced have the layout of version 1
+	for _, jtype := range []worker.JobType{
+		jobs.TypeNoop,
+		jobs.TypeLaunchInstanceAws,
+		jobs.TypeLaunchInstanceAzure,
+		jobs.TypeLaunchInstanceGcp,
+		jobs.TypeRefreshAvailability,
+		jobs.TypeDeletePubkeyResource,
+		jobs.TypeNotifyPubkeyExpiry,
+		jobs.TypeNotifyExpiryDigest,
+		jobs.TypeProbeSSH,
+		jobs.TypeInstanceActionAws,
+		jobs.TypeInstanceActionAzure,
+		jobs.TypeInstanceActionGcp,
+	} {
+		workers.RegisterArgsSchema(jtype, worker.ArgsSchema{Version: 1})
+	}
+
+	workers.RegisterDeadLetterHandler(jobs.StoreFailedJob)
+	workers.RegisterExecutionRecorder(jobs.RecordJobExecution)
+
+	// running jobs of dead workers are enqueued again or failed by the scheduler job reaper
+	workers.RegisterHeartbeater(jobs.RunningJobHeartbeater{}, config.Worker.HeartbeatInterval)
 }
 
 func Initialize(_ context.Context, logger *zerolog.Logger) error {
@@ -44,7 +97,7 @@ func Initialize(_ context.Context, logger *zerolog.Logger) error {
 		wk, err := worker.NewRedisWorker(config.RedisHostAndPort(),
 			config.Application.Cache.Redis.User, config.Application.Cache.Redis.Password,
 			config.Application.Cache.Redis.DB, "provisioning-job-queue",
-			config.Worker.PollInterval, config.Worker.Concurrency)
+			config.Worker.PollInterval, config.Worker.Concurrency, config.Worker.BackgroundConcurrency)
 		if err != nil {
 			return fmt.Errorf("cannot initialize redis worker queue: %w", err)
 		}
@@ -54,13 +107,18 @@ func Initialize(_ context.Context, logger *zerolog.Logger) error {
 		panic("unknown WORKER_QUEUE setting, expected values: memory, redis, postgres")
 	}
 
+	// registered here and not in init so the test stub is not replaced when this package
+	// happens to be initialized later
+	queue.GetEnqueuer = getEnqueuer
 	return nil
 }
 
+// StartDequeueLoop starts processing of jobs. Jobs read data they or the API have just
+// written, all their reads are routed to the main database instead of the lagging replica.
 func StartDequeueLoop(ctx context.Context) {
 	logger := zerolog.Ctx(ctx)
 	logger.Debug().Msg("Starting dequeue loop")
-	workers.DequeueLoop(ctx)
+	workers.DequeueLoop(db.WithPrimary(ctx))
 }
 
 func StopDequeueLoop(ctx context.Context) {
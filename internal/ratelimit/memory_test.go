@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTake(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	ml := newMemoryLimiter()
+	ml.now = func() time.Time { return now }
+	ctx := context.Background()
+	limit := Limit{Rate: 0.5, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := ml.take(ctx, "org", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed, "burst request %d", i)
+	}
+
+	allowed, wait, err := ml.take(ctx, "org", limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 2*time.Second, wait)
+
+	allowed, _, _ = ml.take(ctx, "other", limit)
+	assert.True(t, allowed, "organizations have separate buckets")
+
+	now = now.Add(2 * time.Second)
+	allowed, _, _ = ml.take(ctx, "org", limit)
+	assert.True(t, allowed, "token refilled")
+}
+
+func TestMemoryCleanup(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	ml := newMemoryLimiter()
+	ml.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _, _ = ml.take(ctx, "idle", Limit{Rate: 1, Burst: 10})
+	_, _, _ = ml.take(ctx, "busy", Limit{Rate: 0.001, Burst: 10})
+
+	now = now.Add(2 * cleanupInterval)
+	_, _, _ = ml.take(ctx, "new", Limit{Rate: 1, Burst: 10})
+	assert.NotContains(t, ml.buckets, "idle", "full bucket removed")
+	assert.Contains(t, ml.buckets, "busy")
+}
@@ -0,0 +1,111 @@
+// Package ratelimit limits API requests of organizations via token buckets. Buckets are kept
+// in Redis when the application cache is Redis, so the limit is shared by all API processes,
+// otherwise or when Redis is not available they are kept in process memory.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Class is a group of endpoints sharing a limit.
+type Class string
+
+const (
+	// Read are GET and HEAD requests
+	Read Class = "read"
+
+	// Write are modifying requests
+	Write Class = "write"
+
+	// Launch are reservations, they also count as Write requests
+	Launch Class = "launch"
+)
+
+// Limit is a token bucket refilled at Rate tokens per second up to Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Disabled returns true when requests are not limited.
+func (l Limit) Disabled() bool {
+	return l.Rate <= 0
+}
+
+// LimitOf returns the configured limit of the class, limits are applied without restart.
+func LimitOf(class Class) Limit {
+	limits := config.Current().RateLimits
+	switch class {
+	case Read:
+		return Limit{Rate: float64(limits.ReadPerMinute) / 60, Burst: limits.ReadBurst}
+	case Write:
+		return Limit{Rate: float64(limits.WritePerMinute) / 60, Burst: limits.WriteBurst}
+	case Launch:
+		return Limit{Rate: float64(limits.LaunchPerMinute) / 60, Burst: limits.LaunchBurst}
+	default:
+		return Limit{}
+	}
+}
+
+// limiter takes a token of the bucket, the wait time until the next token is returned when
+// the bucket is empty.
+type limiter interface {
+	take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
+
+var (
+	// the limiter implementation, Redis or memory
+	backend limiter = newMemoryLimiter()
+
+	// fallback is used when Redis is not available
+	fallback = backend
+)
+
+// Initialize creates Redis limiter when Redis application cache is configured.
+func Initialize() {
+	if !config.RateLimit.Enabled {
+		return
+	}
+
+	if config.Application.Cache.Type == "redis" {
+		log.Logger.Info().Msg("Initializing redis rate limiter")
+		backend = &redisLimiter{client: redis.NewClient(&redis.Options{
+			Addr:     config.RedisHostAndPort(),
+			Username: config.Application.Cache.Redis.User,
+			Password: config.Application.Cache.Redis.Password,
+			DB:       config.Application.Cache.Redis.DB,
+		})}
+	} else {
+		log.Logger.Info().Msg("Initializing memory rate limiter")
+	}
+}
+
+// Allow takes a token of the organization bucket of the class. When the limit is reached,
+// false is returned together with the time after which the request can be repeated.
+func Allow(ctx context.Context, orgID string, class Class) (bool, time.Duration) {
+	limit := LimitOf(class)
+	if limit.Disabled() {
+		return true, 0
+	}
+
+	key := fmt.Sprintf("ratelimit:%s:%s", class, orgID)
+	allowed, wait, err := backend.take(ctx, key, limit)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Rate limiter not available, counting in process memory")
+		allowed, wait, _ = fallback.take(ctx, key, limit)
+	}
+	return allowed, wait
+}
+
+// RetryAfter returns the wait time in whole seconds for the Retry-After header.
+func RetryAfter(wait time.Duration) int {
+	return int(math.Max(1, math.Ceil(wait.Seconds())))
+}
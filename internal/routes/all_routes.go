@@ -5,8 +5,11 @@ import (
 	"net/http"
 
 	"github.com/RHEnVision/provisioning-backend/api"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/middleware"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
+	"github.com/RHEnVision/provisioning-backend/internal/ratelimit"
 	s "github.com/RHEnVision/provisioning-backend/internal/services"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -42,9 +45,13 @@ func MountRoot(r *chi.Mux) {
 	})
 }
 
-func MountAPI(r *chi.Mux) {
+// MountAPI mounts routes of the API version, handlers are shared by all versions and
+// payloads are converted into the shape of the version by payloads.Respond.
+func MountAPI(r *chi.Mux, apiVersion string) {
+	r.Use(middleware.APIVersion(apiVersion))
+
 	r.Route("/openapi.json", func(r chi.Router) {
-		r.Use(middleware.ETagMiddleware(api.ETagValue))
+		r.Use(middleware.ETagMiddleware(api.ETagValueFor(apiVersion)))
 		r.Get("/", api.ServeOpenAPISpec)
 	})
 
@@ -56,7 +63,10 @@ func MountAPI(r *chi.Mux) {
 		r.Use(render.SetContentType(render.ContentTypeJSON))
 
 		r.Use(middleware.EnforceIdentity)
+		r.Use(middleware.EnforcePrincipalPolicy(middleware.AllowConfiguredPrincipals))
 		r.Use(middleware.AccountMiddleware)
+		r.Use(middleware.RateLimit)
+		r.Use(middleware.Audit)
 
 		// OpenAPI documented and supported routes
 		r.Route("/sources", func(r chi.Router) {
@@ -73,28 +83,90 @@ func MountAPI(r *chi.Mux) {
 				r.Route("/validate_permissions", func(r chi.Router) {
 					r.Get("/", s.ValidatePermissions)
 				})
+				r.Post("/validate", s.ValidateSourcePermissions)
 			})
 		})
 
+		r.Route("/images", func(r chi.Router) {
+			r.Get("/", s.ListImages)
+		})
+
+		// Modifying routes require RBAC permissions, reading is allowed to the whole organization.
+		// Systems (cert-auth) can only read, service accounts are treated as users.
+		pubkeyWrite := middleware.EnforcePermission(clients.PubkeyWritePermission)
+		reservationWrite := middleware.EnforcePermission(clients.ReservationWritePermission)
+		settingsWrite := middleware.EnforcePermission(clients.SettingsWritePermission)
+		webhookWrite := middleware.EnforcePermission(clients.WebhookWritePermission)
+		launchLimit := middleware.RateLimitClass(ratelimit.Launch)
+		systemsReadOnly := middleware.EnforcePrincipalPolicy(middleware.ReadOnlyPrincipals(identity.SystemPrincipal))
+
 		r.Route("/pubkeys", func(r chi.Router) {
-			r.Post("/", s.CreatePubkey)
+			r.Use(systemsReadOnly)
+			r.With(pubkeyWrite).Post("/", s.CreatePubkey)
+			r.With(pubkeyWrite).Post("/import", s.ImportPubkeys)
 			r.Get("/", s.ListPubkeys)
 			r.Route("/{ID}", func(r chi.Router) {
 				r.Get("/", s.GetPubkey)
-				r.Delete("/", s.DeletePubkey)
+				r.With(pubkeyWrite).Delete("/", s.DeletePubkey)
+				r.Get("/usage", s.GetPubkeyUsage)
+				r.With(pubkeyWrite).Post("/restore", s.RestorePubkey)
+				r.With(pubkeyWrite).Put("/sharing", s.UpdatePubkeySharing)
+			})
+		})
+
+		r.Route("/reservation_templates", func(r chi.Router) {
+			r.Use(systemsReadOnly)
+			r.With(reservationWrite).Post("/", s.CreateReservationTemplate)
+			r.Get("/", s.ListReservationTemplates)
+			r.Route("/{ID}", func(r chi.Router) {
+				r.Get("/", s.GetReservationTemplate)
+				r.With(reservationWrite).Put("/", s.UpdateReservationTemplate)
+				r.With(reservationWrite).Delete("/", s.DeleteReservationTemplate)
+			})
+		})
+
+		// Account defaults and restrictions applied to new reservations.
+		r.Route("/settings", func(r chi.Router) {
+			r.Use(systemsReadOnly)
+			r.Get("/", s.GetAccountSettings)
+			r.With(settingsWrite).Put("/", s.UpdateAccountSettings)
+		})
+
+		// Endpoints receiving reservation lifecycle events and their delivery log.
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(systemsReadOnly)
+			r.With(webhookWrite).Post("/", s.CreateWebhook)
+			r.Get("/", s.ListWebhooks)
+			r.Route("/{ID}", func(r chi.Router) {
+				r.Get("/", s.GetWebhook)
+				r.With(webhookWrite).Delete("/", s.DeleteWebhook)
+				r.Get("/deliveries", s.ListWebhookDeliveries)
 			})
 		})
 
 		r.Route("/reservations", func(r chi.Router) {
+			r.Use(systemsReadOnly)
 			r.Get("/", s.ListReservations)
+			// Reservation from a template, provider is taken from the template
+			r.With(reservationWrite, launchLimit).Post("/", s.CreateTemplateReservation)
+			r.Get("/stats", s.GetReservationStats)
 			// Different types do have different payloads, therefore TYPE must be part of
 			// URL and not a URL (filter) parameter.
 			r.Route("/{TYPE}", func(r chi.Router) {
 				r.Get("/{ID}", s.GetReservationDetail)
-				r.Post("/", s.CreateReservation)
+				r.With(reservationWrite, launchLimit).Post("/", s.CreateReservation)
 			})
 			// Generic reservation detail request (no details provided)
 			r.Get("/{ID}", s.GetReservationDetail)
+			r.Get("/{ID}/instances", s.GetReservationInstances)
+			r.Get("/{ID}/instances/{INSTANCE_ID}/console", s.GetReservationInstanceConsole)
+			r.With(reservationWrite).Post("/{ID}/instances/{INSTANCE_ID}/actions", s.CreateReservationInstanceAction)
+			r.Get("/{ID}/actions", s.ListReservationInstanceActions)
+			r.Get("/{ID}/ssh", s.GetReservationSSH)
+			r.With(reservationWrite).Delete("/{ID}", s.DeleteReservation)
+			r.With(reservationWrite).Post("/{ID}/cancel", s.CancelReservation)
+			r.With(reservationWrite).Post("/{ID}/restore", s.RestoreReservation)
+			r.With(reservationWrite).Put("/{ID}/sharing", s.UpdateReservationSharing)
 		})
 
 		r.Route("/availability_status", func(r chi.Router) {
@@ -136,6 +208,35 @@ func MountAPI(r *chi.Mux) {
 			})
 		})
 
+		// Operator-only endpoints, undocumented on purpose.
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middleware.EnforcePrincipalPolicy(middleware.DenyPrincipals))
+			r.Use(middleware.EnforceAdmin)
+			// operators of admin organizations must also be granted access to the admin API
+			r.Use(middleware.EnforcePermission(clients.AdminReadPermission))
+
+			// modifying endpoints require also the write permission
+			adminWrite := middleware.EnforcePermission(clients.AdminWritePermission)
+
+			r.Route("/failed_jobs", func(r chi.Router) {
+				r.Get("/", s.ListFailedJobs)
+				r.With(adminWrite).Post("/{ID}/replay", s.ReplayFailedJob)
+			})
+
+			r.Get("/job_audit", s.ListJobAudit)
+			r.Get("/api_audit", s.ListAPIAudit)
+
+			r.Route("/log_level", func(r chi.Router) {
+				r.Get("/", s.GetLogLevel)
+				r.With(adminWrite).Put("/", s.SetLogLevel)
+			})
+
+			r.Route("/accounts/{ID}", func(r chi.Router) {
+				r.With(adminWrite).Post("/merge", s.MergeAccount)
+				r.With(adminWrite).Post("/rename", s.RenameAccount)
+			})
+		})
+
 		// We expose feature flags for image builder, this is undocumented since we
 		// want to push for the setup where we share the same unleash instance and this
 		// endpoint might not be needed anymore.
@@ -144,4 +245,26 @@ func MountAPI(r *chi.Mux) {
 			r.Head("/", s.FeatureFlagService)
 		})
 	})
+
+	// Support endpoints for Red Hat associates (Turnpike), undocumented on purpose. Associate
+	// identities carry no organization, therefore these are mounted outside of the account group.
+	r.Route("/internal/admin", func(r chi.Router) {
+		r.Use(render.SetContentType(render.ContentTypeJSON))
+
+		r.Use(middleware.EnforceIdentity)
+		r.Use(middleware.EnforceAssociate)
+		r.Use(middleware.AuditAll)
+
+		r.Route("/reservations", func(r chi.Router) {
+			r.Get("/", s.AdminListReservations)
+			r.Get("/{ID}", s.AdminGetReservation)
+		})
+
+		r.Route("/failed_jobs", func(r chi.Router) {
+			r.Get("/", s.ListFailedJobs)
+			r.Post("/{ID}/replay", s.ReplayFailedJob)
+		})
+
+		r.Get("/job_audit", s.ListJobAudit)
+	})
 }
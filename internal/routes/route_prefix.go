@@ -7,5 +7,10 @@ import (
 )
 
 func PathPrefix() string {
-	return fmt.Sprintf("/api/%s/%s", version.APIPathName, version.APIPathVersion)
+	return PathPrefixFor(version.APIPathVersion)
+}
+
+// PathPrefixFor returns route prefix of the API version.
+func PathPrefixFor(apiVersion string) string {
+	return fmt.Sprintf("/api/%s/%s", version.APIPathName, apiVersion)
 }
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsCfg "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// awsBackend reads secrets via the AWS Secrets Manager JSON API. Credentials are taken from
+// the default AWS chain (environment, web identity or instance role) because the service
+// account keys can be secrets themselves.
+type awsBackend struct {
+	region      string
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      httpClients.HttpRequestDoer
+}
+
+func newAWSBackend(ctx context.Context) (*awsBackend, error) {
+	cfg := config.Secrets.AWS
+	region := cfg.Region
+	if region == "" {
+		region = config.AWS.DefaultRegion
+	}
+
+	awsConfig, err := awsCfg.LoadDefaultConfig(ctx, awsCfg.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load AWS configuration: %s", ErrBackend, err.Error())
+	}
+
+	endpoint := fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	if cfg.Endpoint != "" {
+		endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	}
+
+	return &awsBackend{
+		region:      region,
+		endpoint:    endpoint,
+		credentials: awsConfig.Credentials,
+		signer:      v4.NewSigner(),
+		client:      httpClients.NewPlatformClient(ctx, "", ""),
+	}, nil
+}
+
+// read returns the secret string under the blank key and, when the secret is a JSON object,
+// its fields.
+func (b *awsBackend) read(ctx context.Context, secretID string) (map[string]string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackend, err.Error())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackend, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	creds, err := b.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to retrieve credentials: %s", ErrBackend, err.Error())
+	}
+	hash := sha256.Sum256(body)
+	err = b.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "secretsmanager", b.region, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to sign request: %s", ErrBackend, err.Error())
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackend, err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read secrets manager response: %s", ErrBackend, err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		if bytes.Contains(respBody, []byte("ResourceNotFoundException")) {
+			return nil, fmt.Errorf("%w: secret %s", ErrNotFound, secretID)
+		}
+		return nil, fmt.Errorf("%w: secrets manager returned %d: %s", ErrBackend, resp.StatusCode, respBody)
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode secrets manager response: %s", ErrBackend, err.Error())
+	}
+
+	values := map[string]string{"": result.SecretString}
+	var fields map[string]any
+	if json.Unmarshal([]byte(result.SecretString), &fields) == nil {
+		for key, value := range fields {
+			if s, ok := value.(string); ok {
+				values[key] = s
+			} else if encoded, err := json.Marshal(value); err == nil {
+				values[key] = string(encoded)
+			}
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,287 @@
+// Package secrets resolves sensitive configuration values from HashiCorp Vault or AWS Secrets
+// Manager. A value in the form "vault:<path>#<key>" or "awssm:<secret-id>[#<key>]" is replaced
+// with the secret during startup and resolved again periodically. Values of other forms are
+// used as they are.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrReference = errors.New("invalid secret reference")
+	ErrBackend   = errors.New("secrets backend error")
+	ErrNotFound  = errors.New("secret not found")
+)
+
+const (
+	vaultScheme = "vault"
+	awsScheme   = "awssm"
+)
+
+// Field is a sensitive configuration value which can be resolved from a secrets backend.
+type Field struct {
+	name   string
+	value  *string
+	decode func(string) (string, error)
+
+	// ref is nil when the value is not a reference
+	ref *reference
+
+	// resolved is the latest value of the secret, nil when not resolved
+	resolved atomic.Pointer[string]
+}
+
+var (
+	DatabasePassword     = &Field{name: "DATABASE_PASSWORD", value: &config.Database.Password}
+	AWSKey               = &Field{name: "AWS_KEY", value: &config.AWS.Key}
+	AWSSecret            = &Field{name: "AWS_SECRET", value: &config.AWS.Secret}
+	AWSSession           = &Field{name: "AWS_SESSION", value: &config.AWS.Session}
+	AWSGovCloudKey       = &Field{name: "AWS_GOVCLOUD_KEY", value: &config.AWS.GovCloud.Key}
+	AWSGovCloudSecret    = &Field{name: "AWS_GOVCLOUD_SECRET", value: &config.AWS.GovCloud.Secret}
+	AWSChinaKey          = &Field{name: "AWS_CHINA_KEY", value: &config.AWS.China.Key}
+	AWSChinaSecret       = &Field{name: "AWS_CHINA_SECRET", value: &config.AWS.China.Secret}
+	AzureClientID        = &Field{name: "AZURE_CLIENT_ID", value: &config.Azure.ClientID}
+	AzureClientSecret    = &Field{name: "AZURE_CLIENT_SECRET", value: &config.Azure.ClientSecret}
+	AzureGovClientID     = &Field{name: "AZURE_GOVERNMENT_CLIENT_ID", value: &config.Azure.Government.ClientID}
+	AzureGovClientSecret = &Field{name: "AZURE_GOVERNMENT_CLIENT_SECRET", value: &config.Azure.Government.ClientSecret}
+	AzureCNClientID      = &Field{name: "AZURE_CHINA_CLIENT_ID", value: &config.Azure.China.ClientID}
+	AzureCNClientSecret  = &Field{name: "AZURE_CHINA_CLIENT_SECRET", value: &config.Azure.China.ClientSecret}
+	GCPJSON              = &Field{name: "GCP_JSON", value: &config.GCP.JSON, decode: config.DecodeGCPJSON}
+	SourcesPassword      = &Field{name: "REST_ENDPOINTS_SOURCES_PASSWORD", value: &config.Sources.Password}
+	ImageBuilderPassword = &Field{name: "REST_ENDPOINTS_IMAGE_BUILDER_PASSWORD", value: &config.ImageBuilder.Password}
+	KafkaSASLPassword    = &Field{name: "KAFKA_SASL_PASSWORD", value: &config.Kafka.SASL.Password}
+	UnleashToken         = &Field{name: "UNLEASH_TOKEN", value: &config.Unleash.Token}
+)
+
+// fields are all values which can be resolved, logging settings are not supported because
+// logging is initialized first.
+var fields = []*Field{
+	DatabasePassword,
+	AWSKey,
+	AWSSecret,
+	AWSSession,
+	AWSGovCloudKey,
+	AWSGovCloudSecret,
+	AWSChinaKey,
+	AWSChinaSecret,
+	AzureClientID,
+	AzureClientSecret,
+	AzureGovClientID,
+	AzureGovClientSecret,
+	AzureCNClientID,
+	AzureCNClientSecret,
+	GCPJSON,
+	SourcesPassword,
+	ImageBuilderPassword,
+	KafkaSASLPassword,
+	UnleashToken,
+}
+
+// Value returns the latest resolved secret, or the configuration value when it is not a reference.
+// Clients should call it each time they are created to pick up rotated secrets.
+func (f *Field) Value() string {
+	if value := f.resolved.Load(); value != nil {
+		return *value
+	}
+	return *f.value
+}
+
+// Managed returns true when the value is resolved from a secrets backend.
+func (f *Field) Managed() bool {
+	return f.ref != nil
+}
+
+type reference struct {
+	scheme string
+	path   string
+	key    string
+}
+
+func (r reference) String() string {
+	return r.scheme + ":" + r.path + "#" + r.key
+}
+
+// parseReference parses a value in the form "scheme:path#key", the key is optional for
+// AWS Secrets Manager where the whole secret string is used when it is blank.
+func parseReference(value string) (*reference, error) {
+	scheme, rest, _ := strings.Cut(value, ":")
+	path, key := rest, ""
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		path, key = rest[:i], rest[i+1:]
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("%w: blank path in %s", ErrReference, value)
+	}
+	switch scheme {
+	case vaultScheme:
+		if key == "" {
+			return nil, fmt.Errorf("%w: vault reference %s must contain a key", ErrReference, value)
+		}
+	case awsScheme:
+	default:
+		return nil, fmt.Errorf("%w: unknown scheme %s", ErrReference, scheme)
+	}
+	return &reference{scheme: scheme, path: path, key: key}, nil
+}
+
+// backend reads key-value pairs of a secret
+type backend interface {
+	read(ctx context.Context, path string) (map[string]string, error)
+}
+
+// backends are created on first use, they are only accessed from Initialize and the refresh
+// goroutine
+var backends = map[string]backend{}
+
+func getBackend(ctx context.Context, scheme string) (backend, error) {
+	if b, ok := backends[scheme]; ok {
+		return b, nil
+	}
+
+	var b backend
+	var err error
+	switch scheme {
+	case vaultScheme:
+		b, err = newVaultBackend(ctx)
+	case awsScheme:
+		b, err = newAWSBackend(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	backends[scheme] = b
+	return b, nil
+}
+
+// resolve reads all referenced secrets, every secret is read once.
+func resolve(ctx context.Context, managed []*Field) (map[*Field]string, error) {
+	cache := make(map[string]map[string]string)
+	result := make(map[*Field]string, len(managed))
+
+	for _, f := range managed {
+		cacheKey := f.ref.scheme + ":" + f.ref.path
+		values, ok := cache[cacheKey]
+		if !ok {
+			b, err := getBackend(ctx, f.ref.scheme)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+			values, err = b.read(ctx, f.ref.path)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+			cache[cacheKey] = values
+		}
+
+		value, ok := values[f.ref.key]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w: key %s of %s", f.name, ErrNotFound, f.ref.key, f.ref.path)
+		}
+		if f.decode != nil {
+			var err error
+			value, err = f.decode(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+		result[f] = value
+	}
+
+	return result, nil
+}
+
+func managedFields() []*Field {
+	var managed []*Field
+	for _, f := range fields {
+		if f.Managed() {
+			managed = append(managed, f)
+		}
+	}
+	return managed
+}
+
+// Initialize resolves referenced secrets and stores them into the configuration. It must be
+// called right after logging is initialized, before any client reads the configuration.
+func Initialize(ctx context.Context) error {
+	for _, f := range fields {
+		if !config.IsSecretReference(*f.value) {
+			continue
+		}
+		ref, err := parseReference(*f.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		f.ref = ref
+	}
+
+	managed := managedFields()
+	if len(managed) == 0 {
+		return nil
+	}
+
+	values, err := resolve(ctx, managed)
+	if err != nil {
+		return fmt.Errorf("unable to resolve secrets: %w", err)
+	}
+	for f, value := range values {
+		value := value
+		zerolog.Ctx(ctx).Debug().Msgf("Resolved %s from %s", f.name, f.ref)
+		*f.value = value
+		f.resolved.Store(&value)
+	}
+	return nil
+}
+
+// StartRefresh resolves referenced secrets every SECRETS_REFRESH interval in the background,
+// rotated secrets are returned by Field.Value. Use context cancellation to stop it.
+func StartRefresh(ctx context.Context) {
+	managed := managedFields()
+	if config.Secrets.Refresh <= 0 || len(managed) == 0 {
+		zerolog.Ctx(ctx).Debug().Msg("Secrets refresh is disabled")
+		return
+	}
+
+	go refreshLoop(ctx, managed, config.Secrets.Refresh)
+}
+
+func refreshLoop(ctx context.Context, managed []*Field, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			refresh(ctx, managed)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh resolves secrets again, previous values are kept when a backend is not available.
+func refresh(ctx context.Context, managed []*Field) {
+	logger := zerolog.Ctx(ctx)
+	values, err := resolve(ctx, managed)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to refresh secrets, keeping previous values")
+		return
+	}
+
+	for f, value := range values {
+		value := value
+		if previous := f.resolved.Swap(&value); previous == nil || *previous != value {
+			logger.Info().Msgf("Secret %s was rotated", f.name)
+		}
+	}
+}
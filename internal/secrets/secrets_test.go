@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	ref, err := parseReference("vault:secret/data/provisioning#aws_key")
+	require.NoError(t, err)
+	require.Equal(t, reference{scheme: "vault", path: "secret/data/provisioning", key: "aws_key"}, *ref)
+
+	ref, err = parseReference("awssm:arn:aws:secretsmanager:us-east-1:123456789012:secret:db")
+	require.NoError(t, err)
+	require.Equal(t, "arn:aws:secretsmanager:us-east-1:123456789012:secret:db", ref.path)
+	require.Equal(t, "", ref.key)
+
+	_, err = parseReference("vault:secret/data/provisioning")
+	require.ErrorIs(t, err, ErrReference, "vault key is required")
+
+	_, err = parseReference("awssm:#key")
+	require.ErrorIs(t, err, ErrReference, "path is required")
+}
+
+func TestVaultRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/provisioning":
+			_, _ = io.WriteString(w, `{"data": {"data": {"password": "kv2", "port": 5432}, "metadata": {"version": 3}}}`)
+		case "/v1/kv/provisioning":
+			_, _ = io.WriteString(w, `{"data": {"password": "kv1"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := &vaultBackend{address: server.URL, token: "token", client: server.Client()}
+
+	values, err := b.read(context.Background(), "secret/data/provisioning")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"password": "kv2", "port": "5432"}, values)
+
+	values, err = b.read(context.Background(), "kv/provisioning")
+	require.NoError(t, err)
+	require.Equal(t, "kv1", values["password"])
+
+	_, err = b.read(context.Background(), "kv/missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAWSRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = io.WriteString(w, `{"Name": "provisioning", "SecretString": "{\"key\": \"AKIA\", \"secret\": \"s3cr3t\"}"}`)
+	}))
+	defer server.Close()
+
+	b := &awsBackend{
+		region:      "us-east-1",
+		endpoint:    server.URL,
+		credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("key", "secret", "")),
+		signer:      v4.NewSigner(),
+		client:      server.Client(),
+	}
+
+	values, err := b.read(context.Background(), "provisioning")
+	require.NoError(t, err)
+	require.Equal(t, "AKIA", values["key"])
+	require.Equal(t, "s3cr3t", values["secret"])
+	require.Equal(t, `{"key": "AKIA", "secret": "s3cr3t"}`, values[""], "whole secret string")
+}
+
+type stubBackend map[string]string
+
+func (s stubBackend) read(_ context.Context, _ string) (map[string]string, error) {
+	return s, nil
+}
+
+func TestRefresh(t *testing.T) {
+	stub := stubBackend{"password": "first"}
+	backends[vaultScheme] = stub
+	t.Cleanup(func() { delete(backends, vaultScheme) })
+
+	var value string
+	field := &Field{name: "TEST", value: &value, ref: &reference{scheme: vaultScheme, path: "secret", key: "password"}}
+
+	refresh(context.Background(), []*Field{field})
+	require.Equal(t, "first", field.Value())
+
+	stub["password"] = "second"
+	refresh(context.Background(), []*Field{field})
+	require.Equal(t, "second", field.Value())
+	require.Equal(t, "", value, "configuration is only changed during initialization")
+
+	delete(stub, "password")
+	refresh(context.Background(), []*Field{field})
+	require.Equal(t, "second", field.Value(), "previous value is kept")
+}
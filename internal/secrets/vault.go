@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+)
+
+// kubernetesTokenPath is the service account token used for Vault kubernetes authentication
+const kubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// vaultBackend reads secrets via the Vault HTTP API. Paths are API paths without the "v1/"
+// prefix, e.g. "secret/data/provisioning" for the KV version 2 engine mounted at "secret".
+type vaultBackend struct {
+	address   string
+	namespace string
+	role      string
+	client    httpClients.HttpRequestDoer
+
+	// token is the static token or the token from the last login, renewed after expiry
+	token       string
+	tokenExpiry time.Time
+}
+
+func newVaultBackend(ctx context.Context) (*vaultBackend, error) {
+	cfg := config.Secrets.Vault
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("%w: vault address is required", ErrBackend)
+	}
+	if cfg.Token == "" && cfg.Role == "" {
+		return nil, fmt.Errorf("%w: vault token or kubernetes role is required", ErrBackend)
+	}
+
+	return &vaultBackend{
+		address:   strings.TrimSuffix(cfg.Address, "/"),
+		namespace: cfg.Namespace,
+		role:      cfg.Role,
+		token:     cfg.Token,
+		client:    httpClients.NewPlatformClient(ctx, "", ""),
+	}, nil
+}
+
+type vaultResponse struct {
+	Data json.RawMessage `json:"data"`
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+	} `json:"auth"`
+}
+
+func (b *vaultBackend) do(ctx context.Context, method, path, token string, body []byte) (*vaultResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.address+"/v1/"+strings.TrimPrefix(path, "/"), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackend, err.Error())
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if b.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.namespace)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackend, err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read vault response: %s", ErrBackend, err.Error())
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: vault path %s", ErrNotFound, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: vault returned %d: %s", ErrBackend, resp.StatusCode, respBody)
+	}
+
+	var result vaultResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode vault response: %s", ErrBackend, err.Error())
+	}
+	return &result, nil
+}
+
+// login returns a valid token, the kubernetes authentication is performed when the static
+// token is not configured and the previous token expired.
+func (b *vaultBackend) login(ctx context.Context) (string, error) {
+	if b.role == "" || (b.token != "" && time.Now().Before(b.tokenExpiry)) {
+		return b.token, nil
+	}
+
+	jwt, err := os.ReadFile(kubernetesTokenPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to read service account token: %s", ErrBackend, err.Error())
+	}
+	body, err := json.Marshal(map[string]string{"role": b.role, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrBackend, err.Error())
+	}
+
+	resp, err := b.do(ctx, http.MethodPost, "auth/kubernetes/login", "", body)
+	if err != nil {
+		return "", err
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("%w: vault login returned no token", ErrBackend)
+	}
+
+	// renew a minute before the lease ends so secrets are never read with an expired token
+	b.token = resp.Auth.ClientToken
+	b.tokenExpiry = time.Now().Add(time.Duration(resp.Auth.LeaseDuration)*time.Second - time.Minute)
+	return b.token, nil
+}
+
+// read returns values of a secret of the KV engine, both versions are supported.
+func (b *vaultBackend) read(ctx context.Context, path string) (map[string]string, error) {
+	token, err := b.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.do(ctx, http.MethodGet, path, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("%w: unexpected vault data: %s", ErrBackend, err.Error())
+	}
+
+	// KV version 2 wraps values together with metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBackend, err.Error())
+		}
+		values[key] = string(encoded)
+	}
+	return values, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+// MergeAccount moves pubkeys, reservations and reservation templates of the account to the
+// target account, used when organization ID of a tenant changes and the new organization
+// already has an account. The source account is kept empty. Admin only.
+func MergeAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	payload := &payloads.AccountMergeRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "merge account", err))
+		return
+	}
+
+	audit := &models.AccountAudit{
+		AccountID:       payload.TargetAccountID,
+		SourceAccountID: id,
+		RequestedBy:     identity.OrgIdOrEmpty(r.Context()),
+	}
+	err = dao.GetAccountDao(r.Context()).UnscopedMerge(r.Context(), audit)
+	if err != nil {
+		renderAccountAuditError(w, r, err, fmt.Sprintf("merge account %d into %d", id, payload.TargetAccountID))
+		return
+	}
+	zerolog.Ctx(r.Context()).Warn().Int64("source_account_id", id).Int64("target_account_id", audit.AccountID).
+		Msgf("Account %d merged into %d by %s", id, audit.AccountID, audit.RequestedBy)
+
+	if err := render.Render(w, r, payloads.NewAccountAuditResponse(audit)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render account merge", err))
+	}
+}
+
+// RenameAccount changes organization ID of the account, used when organization ID of a tenant
+// changes and the new organization has no account yet. Admin only.
+func RenameAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	payload := &payloads.AccountRenameRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "rename account", err))
+		return
+	}
+
+	accDao := dao.GetAccountDao(r.Context())
+	account, err := accDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("get account with id %d", id))
+		return
+	}
+
+	audit := &models.AccountAudit{
+		AccountID:   id,
+		NewOrgID:    payload.OrgID,
+		RequestedBy: identity.OrgIdOrEmpty(r.Context()),
+	}
+	err = accDao.UnscopedRename(r.Context(), audit)
+	if err != nil {
+		renderAccountAuditError(w, r, err, fmt.Sprintf("rename account %d", id))
+		return
+	}
+	zerolog.Ctx(r.Context()).Warn().Int64("renamed_account_id", id).
+		Msgf("Account %d renamed from %s to %s by %s", id, audit.OldOrgID, audit.NewOrgID, audit.RequestedBy)
+
+	// identities of the old organization must not resolve to the account anymore
+	err = cache.Delete(r.Context(), account.OrgID+account.AccountNumber.String, &models.Account{})
+	if err != nil {
+		zerolog.Ctx(r.Context()).Warn().Err(err).Msg("Unable to delete renamed account from cache")
+	}
+
+	if err := render.Render(w, r, payloads.NewAccountAuditResponse(audit)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render account rename", err))
+	}
+}
+
+func renderAccountAuditError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, dao.ErrConflict) {
+		renderError(w, r, payloads.NewResponseError(r.Context(), http.StatusConflict, message, err))
+		return
+	}
+	renderNotFoundOrDAOError(w, r, err, message)
+}
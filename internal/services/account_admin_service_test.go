@@ -0,0 +1,91 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareAccountAdminContext(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	err := dao.GetAccountDao(ctx).Create(ctx, &models.Account{OrgID: "2"})
+	require.NoError(t, err)
+	return ctx
+}
+
+func TestMergeAccountHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctx := prepareAccountAdminContext(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 1), "POST", map[string]interface{}{"target_account_id": 2}, services.MergeAccount)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.AccountAuditResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+		assert.Equal(t, models.AccountAuditMerge, result.Operation)
+		assert.Equal(t, int64(1), result.SourceAccountID)
+		assert.Equal(t, int64(2), result.AccountID)
+		assert.Equal(t, "2", result.NewOrgID)
+		assert.Len(t, stubs.AccountAuditStubList(ctx), 1)
+	})
+
+	t.Run("into itself", func(t *testing.T) {
+		ctx := prepareAccountAdminContext(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 1), "POST", map[string]interface{}{"target_account_id": 1}, services.MergeAccount)
+		assert.Equal(t, http.StatusConflict, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx := prepareAccountAdminContext(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 1), "POST", map[string]interface{}{"target_account_id": 99}, services.MergeAccount)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("missing target", func(t *testing.T) {
+		ctx := prepareAccountAdminContext(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 1), "POST", map[string]interface{}{}, services.MergeAccount)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestRenameAccountHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctx := prepareAccountAdminContext(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 1), "POST", map[string]interface{}{"org_id": "3"}, services.RenameAccount)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.AccountAuditResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+		assert.Equal(t, models.AccountAuditRename, result.Operation)
+		assert.Equal(t, identity.DefaultOrgId, result.OldOrgID)
+		assert.Equal(t, "3", result.NewOrgID)
+
+		account, err := dao.GetAccountDao(ctx).GetById(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "3", account.OrgID)
+	})
+
+	t.Run("organization taken", func(t *testing.T) {
+		ctx := prepareAccountAdminContext(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 1), "POST", map[string]interface{}{"org_id": "2"}, services.RenameAccount)
+		assert.Equal(t, http.StatusConflict, rr.Code, "Handler returned wrong status code")
+	})
+}
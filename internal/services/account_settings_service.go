@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+func GetAccountSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := dao.GetAccountSettingsDao(r.Context()).Get(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "get account settings", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewAccountSettingsResponse(settings)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render account settings", err))
+	}
+}
+
+// UpdateAccountSettings replaces all settings of the account.
+func UpdateAccountSettings(w http.ResponseWriter, r *http.Request) {
+	payload := &payloads.AccountSettingsRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "update account settings", err))
+		return
+	}
+
+	settings := payload.NewModel()
+	if settings.PubkeyID != nil {
+		_, err := dao.GetPubkeyDao(r.Context()).GetById(r.Context(), *settings.PubkeyID)
+		if err != nil {
+			message := fmt.Sprintf("get pubkey with id %d", *settings.PubkeyID)
+			renderNotFoundOrDAOError(w, r, err, message)
+			return
+		}
+	}
+
+	err := dao.GetAccountSettingsDao(r.Context()).Upsert(r.Context(), settings)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "update account settings", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewAccountSettingsResponse(settings)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render account settings", err))
+	}
+}
+
+// applyAccountSettings rejects providers restricted by account settings and reservations without
+// required tags, and fills in the default region and pubkey when the request omits them. The pubkey
+// is required after defaults are applied, the region is validated by the caller.
+func applyAccountSettings(ctx context.Context, provider models.ProviderType, region *string, pubkeyID *int64, tags map[string]string) *payloads.ResponseError {
+	settings, err := dao.GetAccountSettingsDao(ctx).Get(ctx)
+	if err != nil {
+		return payloads.NewDAOError(ctx, "get account settings", err)
+	}
+
+	if !settings.AllowsProvider(provider) {
+		return payloads.NewProviderNotAllowedError(ctx, provider.String(), ProviderNotAllowedError)
+	}
+
+	if err := payloads.RequiredTagsError(tags, settings.RequiredTags); err != nil {
+		return payloads.NewBindError(ctx, fmt.Sprintf("%s reservation", provider), err)
+	}
+
+	if *region == "" {
+		*region = settings.DefaultRegion(provider)
+	}
+	if *pubkeyID == 0 && settings.PubkeyID != nil {
+		*pubkeyID = *settings.PubkeyID
+	}
+	if *pubkeyID == 0 {
+		return payloads.NewRequiredFieldError(ctx, fmt.Sprintf("%s reservation", provider), "pubkey_id")
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountSettingsHandlers(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithAccountSettingsDao(ctx)
+	pk := factories.NewPubkeyRSA()
+	require.NoError(t, stubs.AddPubkey(ctx, pk), "failed to generate pubkey")
+
+	t.Run("blank settings", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/settings", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.GetAccountSettings).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.AccountSettingsResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.Equal(t, "", result.AWSRegion)
+		assert.Nil(t, result.PubkeyID)
+		assert.Empty(t, result.AllowedProviders)
+	})
+
+	t.Run("update settings", func(t *testing.T) {
+		values := map[string]interface{}{
+			"aws_region":        "eu-west-1",
+			"pubkey_id":         pk.ID,
+			"allowed_providers": []string{"AWS", "gcp"},
+		}
+		rr := serveJSON(t, ctx, "PUT", values, services.UpdateAccountSettings)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.AccountSettingsResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.Equal(t, "eu-west-1", result.AWSRegion)
+		assert.Equal(t, pk.ID, *result.PubkeyID)
+		assert.Equal(t, []string{"aws", "gcp"}, result.AllowedProviders)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		values := map[string]interface{}{
+			"allowed_providers": []string{"openstack"},
+		}
+		rr := serveJSON(t, ctx, "PUT", values, services.UpdateAccountSettings)
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Contains(t, rr.Body.String(), "allowed_providers")
+	})
+
+	t.Run("unknown pubkey", func(t *testing.T) {
+		values := map[string]interface{}{
+			"pubkey_id": 999,
+		}
+		rr := serveJSON(t, ctx, "PUT", values, services.UpdateAccountSettings)
+		require.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+	})
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+var ErrAdminReservationFilter = errors.New("exactly one of account_id or org_id is required")
+
+// AdminGetReservation returns reservation of any account including deleted ones together with
+// its instances. Associates only.
+func AdminGetReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.UnscopedGetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation")
+		return
+	}
+
+	instances, err := rDao.UnscopedListInstances(r.Context(), reservation.ID)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservation instances", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewAdminReservationResponse(reservation, instances)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
+	}
+}
+
+// AdminListReservations returns reservations of an account identified by account_id or org_id,
+// newest first including deleted ones. Associates only.
+func AdminListReservations(w http.ResponseWriter, r *http.Request) {
+	accountId, err := ParseOptionalInt64(r.URL.Query().Get("account_id"))
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse account_id parameter", err))
+		return
+	}
+	orgId := r.URL.Query().Get("org_id")
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	switch {
+	case accountId != 0 && orgId == "":
+	case orgId != "" && accountId == 0:
+		account, err := dao.GetAccountDao(r.Context()).GetByOrgId(r.Context(), orgId)
+		if err != nil {
+			renderNotFoundOrDAOError(w, r, err, "get account by org_id")
+			return
+		}
+		accountId = account.ID
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid reservation filter", ErrAdminReservationFilter))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservations, err := rDao.UnscopedListByAccount(r.Context(), accountId, limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservations", err))
+		return
+	}
+	total, err := rDao.UnscopedCountByAccount(r.Context(), accountId)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count reservations", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewAdminReservationListResponse(reservations), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservations list", err))
+	}
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveAdminReservations(t *testing.T, ctx context.Context, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/internal/admin/reservations?"+query, nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(services.AdminListReservations).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAdminReservationHandlers(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	var reservations []*models.AWSReservation
+	for _, accountID := range []int64{1, 2} {
+		reservation := &models.AWSReservation{SourceID: "1", Detail: &models.AWSDetail{Region: "us-east-1"}}
+		reservation.AccountID = accountID
+		reservation.Provider = models.ProviderTypeAWS
+		reservation.Owner = "alice"
+		require.NoError(t, stubs.AddAWSReservation(ctx, reservation), "failed to create stub reservation")
+		reservations = append(reservations, reservation)
+	}
+	other := reservations[1]
+	require.NoError(t, dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{ReservationID: other.ID, InstanceID: "i-0a4caa2cf5b097ce1"}))
+
+	t.Run("reservation of another account", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(ctx, other.ID), "GET", nil, services.AdminGetReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.AdminReservationResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		assert.Equal(t, other.ID, response.ID)
+		assert.Equal(t, int64(2), response.AccountID)
+		assert.Equal(t, "alice", response.Owner)
+		require.Len(t, response.Instances, 1)
+		assert.Equal(t, "i-0a4caa2cf5b097ce1", response.Instances[0].InstanceID)
+	})
+
+	t.Run("unknown reservation", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(ctx, 999), "GET", nil, services.AdminGetReservation)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+
+	t.Run("by account", func(t *testing.T) {
+		rr := serveAdminReservations(t, ctx, "account_id=2")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		result, _ := decodeList[payloads.AdminReservationResponse](t, rr.Body)
+		require.Len(t, result, 1)
+		assert.Equal(t, other.ID, result[0].ID)
+	})
+
+	t.Run("by organization", func(t *testing.T) {
+		rr := serveAdminReservations(t, ctx, "org_id="+identity.DefaultOrgId)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		result, _ := decodeList[payloads.AdminReservationResponse](t, rr.Body)
+		require.Len(t, result, 1)
+		assert.Equal(t, reservations[0].ID, result[0].ID)
+	})
+
+	t.Run("missing filter", func(t *testing.T) {
+		rr := serveAdminReservations(t, ctx, "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+}
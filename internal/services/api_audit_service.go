@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+var ErrAPIAuditFilter = errors.New("account_id is required")
+
+// ListAPIAudit returns mutating API calls of an account, newest first. Admin only.
+func ListAPIAudit(w http.ResponseWriter, r *http.Request) {
+	accountId, err := ParseOptionalInt64(r.URL.Query().Get("account_id"))
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse account_id parameter", err))
+		return
+	}
+	if accountId == 0 {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid api audit filter", ErrAPIAuditFilter))
+		return
+	}
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	aaDao := dao.GetAPIAuditDao(r.Context())
+	audits, err := aaDao.UnscopedListByAccount(r.Context(), accountId, limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list api audit", err))
+		return
+	}
+	total, err := aaDao.UnscopedCountByAccount(r.Context(), accountId)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count api audit", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewAPIAuditListResponse(audits), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render api audit list", err))
+		return
+	}
+}
@@ -0,0 +1,59 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveAPIAudit(t *testing.T, ctx context.Context, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/admin/api_audit?"+query, nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(services.ListAPIAudit).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestListAPIAuditHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithAPIAuditDao(ctx)
+
+	aaDao := dao.GetAPIAuditDao(ctx)
+	for _, audit := range []*models.APIAudit{
+		{AccountID: 1, Method: "POST", Route: "/api/provisioning/v1/pubkeys", Status: 200},
+		{AccountID: 1, Method: "DELETE", Route: "/api/provisioning/v1/pubkeys/{ID}", ResourceID: "42", Status: 204},
+		{AccountID: 2, Method: "POST", Route: "/api/provisioning/v1/reservations/aws", Status: 200},
+	} {
+		require.NoError(t, aaDao.UnscopedCreate(ctx, audit))
+	}
+
+	t.Run("by account", func(t *testing.T) {
+		rr := serveAPIAudit(t, ctx, "account_id=1")
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		result, _ := decodeList[payloads.APIAuditResponse](t, rr.Body)
+		require.Len(t, result, 2)
+		assert.Equal(t, "DELETE", result[0].Method)
+		assert.Equal(t, "42", result[0].ResourceID)
+		assert.Equal(t, "POST", result[1].Method)
+	})
+
+	t.Run("missing account", func(t *testing.T) {
+		rr := serveAPIAudit(t, ctx, "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
-	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/go-chi/chi/v5"
@@ -15,12 +14,9 @@ import (
 func ValidatePermissions(w http.ResponseWriter, r *http.Request) {
 	logger := zerolog.Ctx(r.Context())
 	sourceId := chi.URLParam(r, "ID")
+	// blank region is the default region of the partition of the source
 	region := r.URL.Query().Get("region")
 
-	if region == "" {
-		region = config.AWS.DefaultRegion
-	}
-
 	// Get Sources client
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
@@ -1,16 +1,20 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/image_builder"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
@@ -18,49 +22,116 @@ import (
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
 	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
 )
 
 func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
-	logger := *zerolog.Ctx(r.Context())
-
-	var accountId int64 = identity.AccountId(r.Context())
-	var id identity.Principal = identity.Identity(r.Context())
-
 	payload := &payloads.AWSReservationRequestPayload{}
 	if err := render.Bind(r, payload); err != nil {
-		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "AWS reservation", err))
+		renderError(w, r, payloads.NewBindError(r.Context(), "AWS reservation", err))
 		return
 	}
 
+	createAWSReservation(w, r, payload)
+}
+
+// createAWSReservation validates the payload, creates the reservation and enqueues the launch job.
+func createAWSReservation(w http.ResponseWriter, r *http.Request, payload *payloads.AWSReservationRequestPayload) {
+	logger := *zerolog.Ctx(r.Context())
+
+	var accountId int64 = identity.AccountId(r.Context())
+	var id identity.Principal = identity.Identity(r.Context())
+
 	rDao := dao.GetReservationDao(r.Context())
 	pkDao := dao.GetPubkeyDao(r.Context())
 
+	if rErr := applyAccountSettings(r.Context(), models.ProviderTypeAWS, &payload.Region, &payload.PubkeyID, payload.Tags); rErr != nil {
+		renderError(w, r, rErr)
+		return
+	}
+
 	// Check for preloaded region
 	if payload.Region == "" {
 		payload.Region = "us-east-1"
 	}
-	if !preload.EC2InstanceType.ValidateRegion(payload.Region) {
+	// GovCloud and China regions are not preloaded, the region must be in the partition of the source
+	if !preload.EC2InstanceType.ValidateRegion(payload.Region) && !clients.IsAWSPartitionRegion(payload.Region) {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Unsupported region", UnsupportedRegionError))
 		return
 	}
 
+	// Fleet launches have a list of instance types, all of them are validated
+	instanceTypes := payload.InstanceTypes
+	if payload.InstanceType != "" {
+		instanceTypes = []string{payload.InstanceType}
+	}
+
 	// Either Launch Template or Instance Type must be set. Both can be set too, in that case, instance type overrides the launch template.
-	if payload.InstanceType == "" && payload.LaunchTemplateID == "" {
+	if len(instanceTypes) == 0 && payload.LaunchTemplateID == "" {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Both instance type and launch template are missing", BothTypeAndTemplateMissingError))
 		return
 	}
 
-	// Validate architecture match (hardcoded since image builder currently only supports x86_64). This can be only done
-	// when launch template is not set.
+	// Validate architecture match of image builder images (hardcoded since image builder currently only supports x86_64),
+	// direct AMIs are validated later against the first type. This can be only done when launch template is not set.
+	directAMI := strings.HasPrefix(payload.ImageID, "ami-")
+	var it *clients.InstanceType
 	if payload.LaunchTemplateID == "" {
 		supportedArch := "x86_64"
-		it := preload.EC2InstanceType.FindInstanceType(clients.InstanceTypeName(payload.InstanceType))
-		if it == nil {
-			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown type: %s", payload.InstanceType), UnknownInstanceTypeNameError))
+		for _, name := range instanceTypes {
+			typeInfo := preload.EC2InstanceType.FindInstanceType(clients.InstanceTypeName(name))
+			if typeInfo == nil {
+				renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown type: %s", name), UnknownInstanceTypeNameError))
+				return
+			}
+			if !directAMI && typeInfo.Architecture.String() != supportedArch {
+				renderError(w, r, payloads.NewWrongArchitectureUserError(r.Context(), ArchitectureMismatch))
+				return
+			}
+			if it != nil && typeInfo.Architecture != it.Architecture {
+				renderError(w, r, payloads.NewWrongArchitectureUserError(r.Context(), ArchitectureMismatch))
+				return
+			}
+			if payload.RequireGPU && typeInfo.GPUs == 0 {
+				renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("type %s has no GPU", name), GPURequiredError))
+				return
+			}
+			if it == nil {
+				it = typeInfo
+			}
+		}
+	}
+
+	// Fleet launches are estimated with the most expensive type
+	var hourlyCost float64
+	for _, name := range instanceTypes {
+		if cost := estimateHourlyCost(models.ProviderTypeAWS, name); cost > hourlyCost {
+			hourlyCost = cost
+		}
+	}
+	if spendErr := checkSpendCap(r.Context(), float64(payload.Amount)*hourlyCost); spendErr != nil {
+		renderError(w, r, spendErr)
+		return
+	}
+
+	// Images built by image builder are resolved to AMI in the region, when it is not available
+	// there the launch job clones the image first.
+	var ami, cloneComposeID string
+	if payload.ImageID != "" && !directAMI {
+		// Not prefixed with "ami-" therefore this must be a valid UUID
+		IBClient, ibErr := clients.GetImageBuilderClient(r.Context())
+		logger.Trace().Msg("Creating IB client")
+		if ibErr != nil {
+			renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
 			return
 		}
-		if it.Architecture.String() != supportedArch {
-			renderError(w, r, payloads.NewWrongArchitectureUserError(r.Context(), ArchitectureMismatch))
+
+		ami, ibErr = IBClient.GetAWSAmiInRegion(r.Context(), payload.ImageID, payload.Region)
+		if errors.Is(ibErr, httpClients.ImageNotInRegionErr) {
+			logger.Debug().Msgf("Image %s is not available in region %s, it will be cloned", payload.ImageID, payload.Region)
+			cloneComposeID = payload.ImageID
+		} else if ibErr != nil {
+			renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
 			return
 		}
 	}
@@ -69,9 +140,22 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		Region:           payload.Region,
 		LaunchTemplateID: payload.LaunchTemplateID,
 		InstanceType:     payload.InstanceType,
+		InstanceTypes:    payload.InstanceTypes,
 		Amount:           payload.Amount,
 		PowerOff:         payload.PowerOff,
+		Tenancy:          payload.Tenancy,
+		HostID:           payload.HostID,
+		Tags:             payload.Tags,
+	}
+	if detail.Tenancy == "" {
+		detail.Tenancy = models.AWSTenancyDefault
+	}
+	metadataOptions, metaErr := awsMetadataOptions(r.Context(), payload.MetadataOptions)
+	if metaErr != nil {
+		renderError(w, r, metaErr)
+		return
 	}
+	detail.MetadataOptions = metadataOptions
 	reservation := &models.AWSReservation{
 		PubkeyID: payload.PubkeyID,
 		SourceID: payload.SourceID,
@@ -83,6 +167,10 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	reservation.Provider = models.ProviderTypeAWS
 	reservation.Steps = 3
 	reservation.StepTitles = []string{"Ensure public key", "Launch instance(s)", "Fetch instance(s) description"}
+	if cloneComposeID != "" {
+		reservation.Steps = 4
+		reservation.StepTitles = append([]string{"Clone image to region"}, reservation.StepTitles...)
+	}
 	newName := config.Application.InstancePrefix + payload.Name
 	reservation.Detail.Name = &newName
 
@@ -96,13 +184,21 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
-	// create reservation in the database
-	err = rDao.CreateAWS(r.Context(), reservation)
-	if err != nil {
-		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
+	if pkErr := validatePubkey(r.Context(), pk, models.ProviderTypeAWS); pkErr != nil {
+		renderError(w, r, pkErr)
 		return
 	}
-	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
+
+	// create reservation in the database, dry run validates the launch only
+	if !payload.DryRun {
+		err = rDao.CreateAWS(r.Context(), reservation)
+		if err != nil {
+			renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
+			return
+		}
+		metrics.IncReservationsCreated(models.ProviderTypeAWS)
+		logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
+	}
 
 	// Get Sources client
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
@@ -123,26 +219,52 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var ami string
-	if reservation.ImageID == "" || strings.HasPrefix(reservation.ImageID, "ami-") {
-		// Direct AMI or no image were provided (launch template), no need to call image builder
-		ami = reservation.ImageID
-	} else {
-		// Not prefixed with "ami-" therefore this must be a valid UUID
-		// Get Image builder client
-		IBClient, ibErr := clients.GetImageBuilderClient(r.Context())
-		logger.Trace().Msg("Creating IB client")
-		if ibErr != nil {
-			renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
+	var ec2Client clients.EC2
+	if directAMI || len(instanceTypes) > 0 {
+		ec2Client, err = clients.GetEC2Client(r.Context(), authentication, payload.Region)
+		if err != nil {
+			renderError(w, r, payloads.NewClientError(r.Context(), err))
 			return
 		}
+	}
 
-		// Get AMI
-		ami, ibErr = IBClient.GetAWSAmi(r.Context(), reservation.ImageID)
-		if ibErr != nil {
-			renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
+	// Instance types must be offered in the region, image builder images are x86_64 only
+	var imageArch clients.ArchitectureType
+	if payload.ImageID != "" && !directAMI {
+		imageArch = clients.ArchitectureTypeX86_64
+	}
+	for _, name := range instanceTypes {
+		if typeErr := validateInstanceTypeOffering(r.Context(), ec2Client, clients.InstanceTypeName(name), payload.Region, imageArch); typeErr != nil {
+			renderError(w, r, typeErr)
+			return
+		}
+	}
+
+	if directAMI {
+		// Direct AMI, verify it exists in the account and region
+		if imageErr := validateImage(r.Context(), ec2Client, reservation.ImageID, it); imageErr != nil {
+			renderError(w, r, imageErr)
+			return
+		}
+		ami = reservation.ImageID
+	}
+
+	if payload.DryRun {
+		// the image is not available in the region until the launch job clones it
+		if cloneComposeID != "" {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("image %s is not available in region %s", cloneComposeID, payload.Region), DryRunImageCloneError))
 			return
 		}
+		if ec2Client == nil {
+			ec2Client, err = clients.GetEC2Client(r.Context(), authentication, payload.Region)
+			if err != nil {
+				renderError(w, r, payloads.NewClientError(r.Context(), err))
+				return
+			}
+		}
+		result, dryErr := jobs.DryRunLaunchAWS(r.Context(), ec2Client, reservation.Detail, ami)
+		renderDryRun(w, r, models.ProviderTypeAWS, result, dryErr)
+		return
 	}
 
 	launchJob := worker.Job{
@@ -156,6 +278,7 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 			SourceID:         reservation.SourceID,
 			Detail:           reservation.Detail,
 			AMI:              ami,
+			ComposeID:        cloneComposeID,
 			LaunchTemplateID: reservation.Detail.LaunchTemplateID,
 			ARN:              authentication,
 		},
@@ -173,3 +296,44 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render AWS reservation", err))
 	}
 }
+
+// validateInstanceTypeOffering checks the instance type is offered in the region and supports the
+// image architecture. Image architecture can be empty when it is not known or validated elsewhere.
+func validateInstanceTypeOffering(ctx context.Context, client clients.EC2, name clients.InstanceTypeName, region string, imageArch clients.ArchitectureType) *payloads.ResponseError {
+	archs, err := client.GetInstanceTypeArchitectures(ctx, name)
+	if err != nil {
+		if errors.Is(err, httpClients.InstanceTypeNotOfferedErr) {
+			return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("instance type %s is not offered in region %s", name, region), err)
+		}
+		return payloads.NewClientError(ctx, err)
+	}
+
+	if imageArch != "" && !slices.Contains(archs, imageArch) {
+		return payloads.NewWrongArchitectureUserError(ctx, ArchitectureMismatch)
+	}
+	return nil
+}
+
+// awsMetadataOptions applies requested instance metadata options over the secure defaults: IMDSv2
+// required and the configured hop limit. Opting out of IMDSv2 must be allowed for the organization.
+func awsMetadataOptions(ctx context.Context, requested *payloads.AWSMetadataOptionsPayload) (*models.AWSMetadataOptions, *payloads.ResponseError) {
+	options := &models.AWSMetadataOptions{
+		HTTPTokens: models.AWSHTTPTokensRequired,
+		HopLimit:   config.Application.AWSMetadata.HopLimit,
+	}
+	if requested == nil {
+		return options, nil
+	}
+
+	if requested.HTTPTokens == models.AWSHTTPTokensOptional {
+		if !config.IMDSv1Allowed(identity.Identity(ctx).Identity.OrgID) {
+			return nil, payloads.NewInvalidRequestError(ctx, "IMDSv1 (optional http_tokens) is not allowed for the organization", IMDSv1NotAllowedError)
+		}
+		options.HTTPTokens = models.AWSHTTPTokensOptional
+	}
+	if requested.HopLimit != 0 {
+		options.HopLimit = requested.HopLimit
+	}
+	options.Disabled = requested.Disabled
+	return options, nil
+}
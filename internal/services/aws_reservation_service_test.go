@@ -7,9 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	Clientstubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
@@ -24,8 +28,10 @@ func TestCreateAWSReservationHandler(t *testing.T) {
 	ctx = identity.WithTenant(t, ctx)
 	ctx = Clientstubs.WithSourcesClient(ctx)
 	ctx = Clientstubs.WithImageBuilderClient(ctx)
+	ctx = Clientstubs.WithEC2Client(ctx)
 	ctx = stubs.WithReservationDao(ctx)
 	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithAccountSettingsDao(ctx)
 	pk := factories.NewPubkeyRSA()
 	err := stubs.AddPubkey(ctx, pk)
 	require.NoError(t, err, "failed to generate pubkey")
@@ -82,4 +88,201 @@ func TestCreateAWSReservationHandler(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Unsupported region")
 		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
 	})
+
+	t.Run("failed reservation with expired pubkey", func(t *testing.T) {
+		config.Application.PubkeyExpiry.Enforce = true
+		t.Cleanup(func() { config.Application.PubkeyExpiry.Enforce = false })
+
+		expired := time.Now().Add(-time.Hour)
+		expiredPk := factories.NewPubkeyED25519()
+		expiredPk.ExpiresAt = &expired
+		err := stubs.AddPubkey(ctx, expiredPk)
+		require.NoError(t, err, "failed to generate pubkey")
+
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     expiredPk.ID,
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "expired")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation without pubkey", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+
+		assert.Contains(t, rr.Body.String(), "pubkey_id is required")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("successful reservation with account settings defaults", func(t *testing.T) {
+		settings := &models.AccountSettings{AWSRegion: "eu-west-1", PubkeyID: &pk.ID}
+		require.NoError(t, dao.GetAccountSettingsDao(ctx).Upsert(ctx, settings))
+
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		reservation, err := dao.GetReservationDao(ctx).GetAWSById(ctx, int64(stubs.AWSReservationStubCount(ctx)))
+		require.NoError(t, err)
+		assert.Equal(t, "eu-west-1", reservation.Detail.Region)
+		assert.Equal(t, pk.ID, reservation.PubkeyID)
+	})
+
+	t.Run("failed reservation with provider not allowed", func(t *testing.T) {
+		settings := &models.AccountSettings{AllowedProviders: []string{"azure"}}
+		require.NoError(t, dao.GetAccountSettingsDao(ctx).Upsert(ctx, settings))
+		t.Cleanup(func() { _ = dao.GetAccountSettingsDao(ctx).Upsert(ctx, &models.AccountSettings{}) })
+
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+
+		assert.Contains(t, rr.Body.String(), "Provider not allowed")
+		require.Equal(t, http.StatusForbidden, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with instance type not offered in region", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":          "1",
+			"image_id":           "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":             1,
+			"launch_template_id": "lt-8732678436272377",
+			"instance_type":      "m5.missing",
+			"pubkey_id":          pk.ID,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+
+		assert.Contains(t, rr.Body.String(), "not offered in region")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with instance type architecture mismatch", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":          "1",
+			"image_id":           "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":             1,
+			"launch_template_id": "lt-8732678436272377",
+			"instance_type":      "t4g.nano",
+			"pubkey_id":          pk.ID,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+
+		assert.Contains(t, rr.Body.String(), "architecture mismatch")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("successful reservation with host tenancy", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"tenancy":       "host",
+			"host_id":       "h-0a5bd3c4e8f9d1e2f",
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		reservation, err := dao.GetReservationDao(ctx).GetAWSById(ctx, int64(stubs.AWSReservationStubCount(ctx)))
+		require.NoError(t, err)
+		assert.Equal(t, models.AWSTenancyHost, reservation.Detail.Tenancy)
+		assert.Equal(t, "h-0a5bd3c4e8f9d1e2f", reservation.Detail.HostID)
+	})
+
+	t.Run("successful fleet reservation", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":      "1",
+			"image_id":       "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":         2,
+			"instance_types": []string{"m1.small", "t1.micro"},
+			"pubkey_id":      pk.ID,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		reservation, err := dao.GetReservationDao(ctx).GetAWSById(ctx, int64(stubs.AWSReservationStubCount(ctx)))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m1.small", "t1.micro"}, reservation.Detail.InstanceTypes)
+		assert.Empty(t, reservation.Detail.InstanceType)
+	})
+
+	t.Run("failed fleet reservation with architecture mismatch", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":      "1",
+			"image_id":       "ami-0c830793775595d4b",
+			"amount":         1,
+			"instance_types": []string{"m1.small", "t4g.nano"},
+			"pubkey_id":      pk.ID,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+
+		assert.Contains(t, rr.Body.String(), "architecture mismatch")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("successful reservation with secure metadata options", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":        "1",
+			"image_id":         "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":           1,
+			"instance_type":    "t1.micro",
+			"pubkey_id":        pk.ID,
+			"metadata_options": map[string]interface{}{"hop_limit": 2},
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		reservation, err := dao.GetReservationDao(ctx).GetAWSById(ctx, int64(stubs.AWSReservationStubCount(ctx)))
+		require.NoError(t, err)
+		require.NotNil(t, reservation.Detail.MetadataOptions)
+		assert.Equal(t, models.AWSHTTPTokensRequired, reservation.Detail.MetadataOptions.HTTPTokens)
+		assert.Equal(t, int32(2), reservation.Detail.MetadataOptions.HopLimit)
+	})
+
+	t.Run("failed reservation with IMDSv1 not allowed", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":        "1",
+			"image_id":         "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":           1,
+			"instance_type":    "t1.micro",
+			"pubkey_id":        pk.ID,
+			"metadata_options": map[string]interface{}{"http_tokens": "optional"},
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateAWSReservation)
+
+		assert.Contains(t, rr.Body.String(), "IMDSv1")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
 }
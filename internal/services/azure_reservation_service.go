@@ -1,18 +1,23 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
@@ -21,17 +26,27 @@ import (
 )
 
 func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
-	logger := *zerolog.Ctx(r.Context())
-
 	payload := &payloads.AzureReservationRequestPayload{}
 	if err := render.Bind(r, payload); err != nil {
-		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Azure reservation", err))
+		renderError(w, r, payloads.NewBindError(r.Context(), "Azure reservation", err))
 		return
 	}
 
+	createAzureReservation(w, r, payload)
+}
+
+// createAzureReservation validates the payload, creates the reservation and enqueues the launch job.
+func createAzureReservation(w http.ResponseWriter, r *http.Request, payload *payloads.AzureReservationRequestPayload) {
+	logger := *zerolog.Ctx(r.Context())
+
 	pkDao := dao.GetPubkeyDao(r.Context())
 	rDao := dao.GetReservationDao(r.Context())
 
+	if rErr := applyAccountSettings(r.Context(), models.ProviderTypeAzure, &payload.Location, &payload.PubkeyID, payload.Tags); rErr != nil {
+		renderError(w, r, rErr)
+		return
+	}
+
 	// Check for preloaded region
 	if payload.Location == "" {
 		payload.Location = "eastus_1"
@@ -51,6 +66,11 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
+	if pkErr := validatePubkey(r.Context(), pk, models.ProviderTypeAzure); pkErr != nil {
+		renderError(w, r, pkErr)
+		return
+	}
+
 	// Get Sources client
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
@@ -77,7 +97,22 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	it := preload.AzureInstanceType.FindInstanceType(clients.InstanceTypeName(payload.InstanceSize))
+	if it == nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown instance size: %s", payload.InstanceSize), UnknownInstanceTypeNameError))
+		return
+	}
+	if payload.RequireGPU && it.GPUs == 0 {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("instance size %s has no GPU", payload.InstanceSize), GPURequiredError))
+		return
+	}
+	if spendErr := checkSpendCap(r.Context(), float64(payload.Amount)*estimateHourlyCost(models.ProviderTypeAzure, payload.InstanceSize)); spendErr != nil {
+		renderError(w, r, spendErr)
+		return
+	}
+
 	var azureImageName string
+	directImage := false
 	// Azure image IDs are "free form", if it's a UUID we treat it like a compose ID
 	if _, pErr := uuid.Parse(payload.ImageID); pErr == nil {
 		// Composer-built image
@@ -95,16 +130,36 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		} else {
 			// Anything else is treated like a direct Azure image ID (e.g. from https://imagedirectory.cloud)
 			azureImageName = payload.ImageID
+			directImage = true
 		}
 	}
 
-	supportedArch := "x86_64"
-	it := preload.AzureInstanceType.FindInstanceType(clients.InstanceTypeName(payload.InstanceSize))
-	if it == nil {
-		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown instance size: %s", payload.InstanceSize), UnknownInstanceTypeNameError))
+	azureClient, err := clients.GetAzureClient(r.Context(), authentication)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	// fail fast instead of the deployment error several minutes after the launch
+	if quotaErr := validateVMSizeQuota(r.Context(), azureClient, payload.Location, it.Name, payload.Amount, payload.Priority == models.AzurePrioritySpot); quotaErr != nil {
+		renderError(w, r, quotaErr)
 		return
 	}
-	if it.Architecture.String() != supportedArch {
+
+	if payload.ResourceGroupStrategy == models.AzureResourceGroupExisting {
+		if rgErr := validateResourceGroup(r.Context(), azureClient, payload.ResourceGroup); rgErr != nil {
+			renderError(w, r, rgErr)
+			return
+		}
+	}
+
+	if directImage {
+		if imageErr := validateImage(r.Context(), azureClient, azureImageName, it); imageErr != nil {
+			renderError(w, r, imageErr)
+			return
+		}
+	} else if it.Architecture.String() != "x86_64" {
+		// Validate architecture match (hardcoded since image builder currently only supports x86_64)
 		renderError(w, r, payloads.NewWrongArchitectureUserError(r.Context(), ArchitectureMismatch))
 		return
 	}
@@ -116,6 +171,30 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		Amount:       payload.Amount,
 		PowerOff:     payload.PowerOff,
 		Name:         name,
+
+		ResourceGroupStrategy: payload.ResourceGroupStrategy,
+		ResourceGroup:         payload.ResourceGroup,
+		ResourceGroupTags:     payload.ResourceGroupTags,
+
+		Priority:       payload.Priority,
+		MaxPrice:       payload.MaxPrice,
+		EvictionPolicy: payload.EvictionPolicy,
+
+		Tags: payload.Tags,
+	}
+	if detail.ResourceGroupStrategy == "" {
+		detail.ResourceGroupStrategy = models.AzureResourceGroupShared
+	}
+	if detail.Priority == "" {
+		detail.Priority = models.AzurePriorityRegular
+	}
+	if detail.Priority == models.AzurePrioritySpot {
+		if detail.EvictionPolicy == "" {
+			detail.EvictionPolicy = models.AzureEvictionDeallocate
+		}
+		if detail.MaxPrice == nil {
+			detail.MaxPrice = ptr.To(float64(-1))
+		}
 	}
 	reservation := &models.AzureReservation{
 		PubkeyID: payload.PubkeyID,
@@ -126,12 +205,19 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	reservation.Steps = int32(len(jobs.LaunchInstanceAzureSteps))
 	reservation.StepTitles = jobs.LaunchInstanceAzureSteps
 
+	if payload.DryRun {
+		result, dryErr := jobs.DryRunLaunchAzure(r.Context(), azureClient, reservation.Detail, azureImageName, pk)
+		renderDryRun(w, r, models.ProviderTypeAzure, result, dryErr)
+		return
+	}
+
 	// create reservation in the database
 	err = rDao.CreateAzure(r.Context(), reservation)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "create Azure reservation", err))
 		return
 	}
+	metrics.IncReservationsCreated(models.ProviderTypeAzure)
 	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
 
 	launchJob := worker.Job{
@@ -160,3 +246,74 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render Azure reservation", err))
 	}
 }
+
+// validateResourceGroup checks an existing resource group is present in the subscription.
+func validateResourceGroup(ctx context.Context, client clients.Azure, name string) *payloads.ResponseError {
+	groups, err := client.ListResourceGroups(ctx)
+	if err != nil {
+		return payloads.NewClientError(ctx, err)
+	}
+	for _, group := range groups {
+		if strings.EqualFold(group, name) {
+			return nil
+		}
+	}
+	return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("resource group %s not found", name), ResourceGroupNotFoundError)
+}
+
+// validateVMSizeQuota checks the VM size can be launched in the location and the family and
+// regional vCPU quotas, or the spot vCPU quota, fit all instances. Location can contain the zone
+// suffix (eastus_1).
+func validateVMSizeQuota(ctx context.Context, client clients.Azure, location string, size clients.InstanceTypeName, amount int64, spot bool) *payloads.ResponseError {
+	region := strings.SplitN(location, "_", 2)[0]
+	quota, err := client.GetVMSizeQuota(ctx, region, size)
+	if err != nil {
+		if errors.Is(err, httpClients.VMSizeNotAvailableErr) {
+			return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("instance size %s is not available in location %s", size, region), err)
+		}
+		return payloads.NewClientError(ctx, err)
+	}
+
+	if quota.Restriction != "" {
+		return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("instance size %s is restricted in location %s (%s)", size, region, quota.Restriction), VMSizeRestrictedError)
+	}
+
+	needed := quota.VCPUs * amount
+	usages := []clients.AzureQuotaUsage{quota.FamilyUsage, quota.RegionalUsage}
+	if spot {
+		usages = []clients.AzureQuotaUsage{quota.SpotUsage}
+	}
+	for _, usage := range usages {
+		// quotas not reported by Azure are not checked
+		if usage.Limit == 0 {
+			continue
+		}
+		if usage.Available() < needed {
+			err = fmt.Errorf("%w: %s in %s needs %d, %d of %d available", QuotaExceededError, usage.Name, region, needed, usage.Available(), usage.Limit)
+			return payloads.NewQuotaExceededError(ctx, usage.Name, err)
+		}
+	}
+	return nil
+}
+
+// azureDeallocatedState is the power state of VMs evicted with the deallocate policy
+const azureDeallocatedState = "deallocated"
+
+// evictedInstances returns IDs of evicted spot VMs of a reservation from the live instance state,
+// VMs evicted with the delete policy no longer exist. Nil is returned when the state cannot be
+// queried, the error is logged when the response error is created and must not fail the
+// reservation detail.
+func evictedInstances(ctx context.Context, reservation *models.Reservation) []string {
+	instances, respErr := cachedReservationInstances(ctx, reservation)
+	if respErr != nil {
+		return nil
+	}
+
+	evicted := make([]string, 0)
+	for _, instance := range instances {
+		if instance.State == azureDeallocatedState || instance.State == clients.InstanceStateNotFound {
+			evicted = append(evicted, instance.ID)
+		}
+	}
+	return evicted
+}
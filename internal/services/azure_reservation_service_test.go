@@ -12,6 +12,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/stub"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
@@ -27,8 +28,10 @@ func TestCreateAzureReservationHandler(t *testing.T) {
 	ctx = identity.WithTenant(t, ctx)
 	ctx = Clientstubs.WithSourcesClient(ctx)
 	ctx = Clientstubs.WithImageBuilderClient(ctx)
+	ctx = Clientstubs.WithAzureClient(ctx)
 	ctx = stubs.WithReservationDao(ctx)
 	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithAccountSettingsDao(ctx)
 	ctx = stub.WithEnqueuer(ctx)
 	pk := factories.NewPubkeyRSA()
 	err := stubs.AddPubkey(ctx, pk)
@@ -93,4 +96,89 @@ func TestCreateAzureReservationHandler(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Unsupported location")
 		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
 	})
+
+	t.Run("direct image ID", func(t *testing.T) {
+		reserve := func(t *testing.T, imageID string) *httptest.ResponseRecorder {
+			t.Helper()
+			values := map[string]interface{}{
+				"source_id":     source.ID,
+				"image_id":      imageID,
+				"amount":        1,
+				"instance_size": "Basic_A0",
+				"pubkey_id":     pk.ID,
+			}
+			data, err := json.Marshal(values)
+			require.NoError(t, err, "unable to marshal values to json")
+
+			req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/azure", bytes.NewBuffer(data))
+			require.NoError(t, err, "failed to create request")
+			req.Header.Add("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(services.CreateAzureReservation)
+			handler.ServeHTTP(rr, req)
+			return rr
+		}
+
+		t.Run("architecture mismatch", func(t *testing.T) {
+			rr := reserve(t, "/subscriptions/4b9d213f/resourceGroups/images/providers/Microsoft.Compute/images/rhel-9-arm64")
+			assert.Contains(t, rr.Body.String(), "architecture mismatch")
+			require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		})
+
+		t.Run("missing image", func(t *testing.T) {
+			rr := reserve(t, "/subscriptions/4b9d213f/resourceGroups/images/providers/Microsoft.Compute/images/missing")
+			assert.Contains(t, rr.Body.String(), "not found")
+			require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		})
+	})
+	t.Run("missing existing resource group", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":               source.ID,
+			"image_id":                "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+			"amount":                  1,
+			"instance_size":           "Basic_A0",
+			"pubkey_id":               pk.ID,
+			"resource_group_strategy": "existing",
+			"resource_group":          "missing",
+		}
+		data, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/azure", bytes.NewBuffer(data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAzureReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "resource group missing not found")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+	t.Run("exhausted quota", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":     source.ID,
+			"image_id":      "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+			"amount":        6,
+			"instance_size": "Basic_A0",
+			"pubkey_id":     pk.ID,
+		}
+		data, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/azure", bytes.NewBuffer(data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAzureReservation)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		var response payloads.ResponseError
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		assert.Equal(t, "Basic A Family vCPUs", response.ExhaustedQuota)
+		assert.Contains(t, response.Error, "needs 12, 10 of 10 available")
+	})
 }
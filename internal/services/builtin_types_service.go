@@ -11,15 +11,15 @@ import (
 	"github.com/rs/zerolog"
 )
 
-type InstanceTypesForZoneFunc func(region, zone string, supported *bool) ([]*clients.InstanceType, error)
+type InstanceTypesForZoneFunc func(region, zone string, filter *clients.InstanceTypeFilter) ([]*clients.InstanceType, error)
 
 func ListBuiltinInstanceTypes(typeFunc InstanceTypesForZoneFunc) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		region := strings.ToLower(r.URL.Query().Get("region"))
 		zone := strings.ToLower(r.URL.Query().Get("zone"))
-		supported, err := ParseBool(r.URL.Query().Get("supported"))
-		if err != nil {
-			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "parameter 'supported' could not be parsed", err))
+		filter, errPayload := parseInstanceTypeFilter(r)
+		if errPayload != nil {
+			renderError(w, r, errPayload)
 			return
 		}
 
@@ -29,7 +29,7 @@ func ListBuiltinInstanceTypes(typeFunc InstanceTypesForZoneFunc) func(w http.Res
 		}
 
 		start := time.Now()
-		instances, err := typeFunc(region, zone, supported)
+		instances, err := typeFunc(region, zone, filter)
 		logger := zerolog.Ctx(r.Context())
 		logger.Trace().TimeDiff("duration", time.Now(), start).Msg("Listed instance types")
 		if err != nil {
@@ -37,7 +37,7 @@ func ListBuiltinInstanceTypes(typeFunc InstanceTypesForZoneFunc) func(w http.Res
 			return
 		}
 
-		if err := render.RenderList(w, r, payloads.NewListInstanceTypeResponse(instances)); err != nil {
+		if err := render.Render(w, r, payloads.NewListResponse(payloads.NewListInstanceTypeResponse(instances))); err != nil {
 			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render instance types list", err))
 			return
 		}
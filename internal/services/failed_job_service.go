@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+var ErrFailedJobReplayed = errors.New("failed job was already replayed")
+
+// ListFailedJobs returns jobs of all accounts which failed on the last attempt. Admin only.
+func ListFailedJobs(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	fjDao := dao.GetFailedJobDao(r.Context())
+
+	failedJobs, err := fjDao.UnscopedList(r.Context(), limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list failed jobs", err))
+		return
+	}
+	total, err := fjDao.UnscopedCount(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count failed jobs", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewFailedJobListResponse(failedJobs), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render failed jobs list", err))
+		return
+	}
+}
+
+// ReplayFailedJob enqueues a failed job again with a new job ID and attempts count reset.
+// Every failed job can be replayed only once, when the replay fails again it is stored as
+// a new failed job. Admin only.
+func ReplayFailedJob(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	fjDao := dao.GetFailedJobDao(r.Context())
+	failedJob, err := fjDao.UnscopedGetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get failed job with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	if failedJob.ReplayedAt != nil {
+		renderError(w, r, payloads.NewResponseError(r.Context(), http.StatusConflict, "failed job was already replayed", ErrFailedJobReplayed))
+		return
+	}
+
+	job, err := worker.DecodeJob(failedJob.Payload)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to decode failed job", err))
+		return
+	}
+	job.ID = uuid.Nil
+	job.Attempt = 0
+	job.MaxAttempts = 0
+
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), job)
+	if err != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+		return
+	}
+
+	err = fjDao.UnscopedMarkReplayed(r.Context(), id)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "mark failed job replayed", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewFailedJobReplayResponse(id, job.ID.String())); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render failed job replay", err))
+	}
+}
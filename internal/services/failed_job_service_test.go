@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEnqueuer struct {
+	enqueued []*worker.Job
+}
+
+func (e *recordingEnqueuer) Enqueue(_ context.Context, job *worker.Job) error {
+	job.ID = uuid.New()
+	e.enqueued = append(e.enqueued, job)
+	return nil
+}
+
+// prepareFailedJobContext replaces the global enqueuer since the job queue package initialized
+// by the services package takes precedence over the queue stub.
+func prepareFailedJobContext(t *testing.T) (context.Context, *recordingEnqueuer) {
+	t.Helper()
+
+	gob.Register(jobs.NoopJobArgs{})
+	recorder := &recordingEnqueuer{}
+	original := queue.GetEnqueuer
+	queue.GetEnqueuer = func(_ context.Context) worker.JobEnqueuer { return recorder }
+	t.Cleanup(func() { queue.GetEnqueuer = original })
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithFailedJobDao(ctx)
+	return ctx, recorder
+}
+
+func storeFailedNoopJob(t *testing.T, ctx context.Context) {
+	t.Helper()
+
+	job := &worker.Job{
+		ID:          uuid.New(),
+		Type:        jobs.TypeNoop,
+		AccountID:   1,
+		Args:        jobs.NoopJobArgs{ReservationID: 42},
+		Attempt:     3,
+		MaxAttempts: 3,
+	}
+	jobs.StoreFailedJob(ctx, job, errors.New("cloud outage"))
+	require.Equal(t, 1, stubs.FailedJobStubCount(ctx))
+}
+
+func TestListFailedJobsHandler(t *testing.T) {
+	ctx, _ := prepareFailedJobContext(t)
+	storeFailedNoopJob(t, ctx)
+
+	rr := serveJSON(t, ctx, "GET", nil, services.ListFailedJobs)
+	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+	result, _ := decodeList[payloads.FailedJobResponse](t, rr.Body)
+	require.Len(t, result, 1)
+	assert.Equal(t, jobs.TypeNoop.String(), result[0].JobType)
+	assert.Equal(t, "cloud outage", result[0].Error)
+	assert.Equal(t, 3, result[0].Attempts)
+	assert.JSONEq(t, `{"ReservationID":42,"Fail":false,"Sleep":0}`, string(result[0].Args))
+	assert.Nil(t, result[0].ReplayedAt)
+}
+
+func TestReplayFailedJobHandler(t *testing.T) {
+	t.Run("enqueues job again", func(t *testing.T) {
+		ctx, recorder := prepareFailedJobContext(t)
+		storeFailedNoopJob(t, ctx)
+
+		rr := serveJSON(t, withIDParam(ctx, 1), "POST", nil, services.ReplayFailedJob)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.FailedJobReplayResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+
+		enqueued := recorder.enqueued
+		require.Len(t, enqueued, 1)
+		assert.Equal(t, jobs.TypeNoop, enqueued[0].Type)
+		assert.Equal(t, int64(1), enqueued[0].AccountID)
+		assert.Equal(t, jobs.NoopJobArgs{ReservationID: 42}, enqueued[0].Args)
+		assert.Equal(t, 0, enqueued[0].Attempt, "attempts must be reset")
+		assert.Equal(t, enqueued[0].ID.String(), result.JobID)
+
+		rr = serveJSON(t, withIDParam(ctx, 1), "POST", nil, services.ReplayFailedJob)
+		assert.Equal(t, http.StatusConflict, rr.Code, "Replaying twice must be rejected")
+		assert.Len(t, recorder.enqueued, 1)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx, _ := prepareFailedJobContext(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 99), "POST", nil, services.ReplayFailedJob)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -16,7 +17,7 @@ func FeatureFlagService(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if config.FeatureEnabled(r.Context(), flag) {
+	if featureflags.EnabledByName(r.Context(), flag) {
 		writeOk(w, r)
 	} else {
 		writeUnauthorized(w, r)
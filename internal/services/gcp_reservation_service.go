@@ -1,14 +1,19 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
@@ -20,26 +25,63 @@ import (
 )
 
 func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
-	logger := *zerolog.Ctx(r.Context())
-
-	var accountId int64 = identity.AccountId(r.Context())
-	var id identity.Principal = identity.Identity(r.Context())
-
 	payload := &payloads.GCPReservationRequestPayload{}
 	if err := render.Bind(r, payload); err != nil {
-		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "GCP reservation", err))
+		renderError(w, r, payloads.NewBindError(r.Context(), "GCP reservation", err))
 		return
 	}
 
+	createGCPReservation(w, r, payload)
+}
+
+// createGCPReservation validates the payload, creates the reservation and enqueues the launch job.
+func createGCPReservation(w http.ResponseWriter, r *http.Request, payload *payloads.GCPReservationRequestPayload) {
+	logger := *zerolog.Ctx(r.Context())
+
+	var accountId int64 = identity.AccountId(r.Context())
+	var id identity.Principal = identity.Identity(r.Context())
+
 	rDao := dao.GetReservationDao(r.Context())
 	pkDao := dao.GetPubkeyDao(r.Context())
 
+	if rErr := applyAccountSettings(r.Context(), models.ProviderTypeGCP, &payload.Zone, &payload.PubkeyID, payload.Tags); rErr != nil {
+		renderError(w, r, rErr)
+		return
+	}
+
 	// Check for preloaded region
 	if !preload.GCPInstanceType.ValidateRegion(payload.Zone) {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Unsupported zone", UnsupportedRegionError))
 		return
 	}
 
+	if payload.RequireGPU {
+		it := preload.GCPInstanceType.FindInstanceType(clients.InstanceTypeName(payload.MachineType))
+		if it == nil {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown machine type: %s", payload.MachineType), UnknownInstanceTypeNameError))
+			return
+		}
+		if it.GPUs == 0 {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("machine type %s has no GPU", payload.MachineType), GPURequiredError))
+			return
+		}
+	}
+
+	if spendErr := checkSpendCap(r.Context(), float64(payload.Amount)*estimateHourlyCost(models.ProviderTypeGCP, payload.MachineType)); spendErr != nil {
+		renderError(w, r, spendErr)
+		return
+	}
+
+	network := payload.Network
+	if network != "" && !strings.Contains(network, "/") {
+		network = fmt.Sprintf("global/networks/%s", network)
+	}
+	subnetwork, subnetErr := gcpSubnetworkPath(r.Context(), payload.Zone, payload.Subnetwork)
+	if subnetErr != nil {
+		renderError(w, r, subnetErr)
+		return
+	}
+
 	resUUID := uuid.New().String()
 	detail := &models.GCPDetail{
 		NamePattern:        &payload.NamePattern,
@@ -49,6 +91,24 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		PowerOff:           payload.PowerOff,
 		UUID:               resUUID,
 		LaunchTemplateName: payload.LaunchTemplateName,
+		ProvisioningModel:  payload.ProvisioningModel,
+		TerminationAction:  payload.TerminationAction,
+		Scopes:             payload.Scopes,
+		Network:            network,
+		Subnetwork:         subnetwork,
+		Tags:               payload.Tags,
+	}
+	if payload.ServiceAccount != models.GCPServiceAccountNone {
+		detail.ServiceAccount = payload.ServiceAccount
+	}
+	if detail.ServiceAccount != "" && len(detail.Scopes) == 0 {
+		detail.Scopes = []string{models.GCPDefaultScope}
+	}
+	if detail.ProvisioningModel == "" {
+		detail.ProvisioningModel = models.GCPProvisioningStandard
+	}
+	if detail.ProvisioningModel == models.GCPProvisioningSpot && detail.TerminationAction == "" {
+		detail.TerminationAction = models.GCPTerminationStop
 	}
 	reservation := &models.GCPReservation{
 		PubkeyID: payload.PubkeyID,
@@ -72,13 +132,21 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
-	// create reservation in the database
-	err = rDao.CreateGCP(r.Context(), reservation)
-	if err != nil {
-		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
+	if pkErr := validatePubkey(r.Context(), pk, models.ProviderTypeGCP); pkErr != nil {
+		renderError(w, r, pkErr)
 		return
 	}
-	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
+
+	// create reservation in the database, dry run validates the launch only
+	if !payload.DryRun {
+		err = rDao.CreateGCP(r.Context(), reservation)
+		if err != nil {
+			renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
+			return
+		}
+		metrics.IncReservationsCreated(models.ProviderTypeGCP)
+		logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
+	}
 
 	// Get Sources client
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
@@ -99,6 +167,26 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if reservation.Detail.ServiceAccount != "" || reservation.Detail.Network != "" || reservation.Detail.Subnetwork != "" {
+		gcpClient, gcpErr := clients.GetGCPClient(r.Context(), authentication)
+		if gcpErr != nil {
+			renderError(w, r, payloads.NewClientError(r.Context(), gcpErr))
+			return
+		}
+
+		if reservation.Detail.ServiceAccount != "" {
+			if saErr := validateServiceAccount(r.Context(), gcpClient, reservation.Detail.ServiceAccount); saErr != nil {
+				renderError(w, r, saErr)
+				return
+			}
+		}
+
+		if netErr := validateNetwork(r.Context(), gcpClient, reservation.Detail.Network, reservation.Detail.Subnetwork); netErr != nil {
+			renderError(w, r, netErr)
+			return
+		}
+	}
+
 	// Get Image builder client
 	ibc, ibErr := clients.GetImageBuilderClient(r.Context())
 	logger.Trace().Msg("Creating IB client")
@@ -121,6 +209,30 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	} else {
 		// Treat HTTP(S) URLs like direct image ID (e.g. from https://imagedirectory.cloud)
 		name = payload.ImageID
+
+		gcpClient, gcpErr := clients.GetGCPClient(r.Context(), authentication)
+		if gcpErr != nil {
+			renderError(w, r, payloads.NewClientError(r.Context(), gcpErr))
+			return
+		}
+
+		// Machine type is not known when launch template is used
+		it := preload.GCPInstanceType.FindInstanceType(clients.InstanceTypeName(payload.MachineType))
+		if imageErr := validateImage(r.Context(), gcpClient, name, it); imageErr != nil {
+			renderError(w, r, imageErr)
+			return
+		}
+	}
+
+	if payload.DryRun {
+		gcpClient, gcpErr := clients.GetGCPClient(r.Context(), authentication)
+		if gcpErr != nil {
+			renderError(w, r, payloads.NewClientError(r.Context(), gcpErr))
+			return
+		}
+		result, dryErr := jobs.DryRunLaunchGCP(r.Context(), gcpClient, reservation.Detail, name, pk)
+		renderDryRun(w, r, models.ProviderTypeGCP, result, dryErr)
+		return
 	}
 
 	launchJob := worker.Job{
@@ -151,3 +263,54 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// validateServiceAccount checks the service account exists in the project and can be attached.
+func validateServiceAccount(ctx context.Context, client clients.GCP, email string) *payloads.ResponseError {
+	err := client.CheckServiceAccount(ctx, email)
+	if errors.Is(err, httpClients.ServiceAccountNotFoundErr) || errors.Is(err, httpClients.ServiceAccountDisabledErr) {
+		return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("service account %s cannot be attached", email), err)
+	}
+	if err != nil {
+		return payloads.NewClientError(ctx, err)
+	}
+	return nil
+}
+
+// gcpSubnetworkPath expands a subnetwork name to a path in the region of the zone, region of a
+// subnetwork path must be the region of the zone.
+func gcpSubnetworkPath(ctx context.Context, zone, subnetwork string) (string, *payloads.ResponseError) {
+	if subnetwork == "" {
+		return "", nil
+	}
+
+	region := zone
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+	if !strings.Contains(subnetwork, "/") {
+		return fmt.Sprintf("regions/%s/subnetworks/%s", region, subnetwork), nil
+	}
+
+	parts := strings.Split(subnetwork, "/")
+	if parts[len(parts)-3] != region {
+		return "", payloads.NewInvalidRequestError(ctx, fmt.Sprintf("subnetwork %s is not in region %s of the zone", subnetwork, region), SubnetworkRegionMismatchError)
+	}
+	return subnetwork, nil
+}
+
+// validateNetwork checks the network and the subnetwork exist, either can be empty.
+func validateNetwork(ctx context.Context, client clients.GCP, network, subnetwork string) *payloads.ResponseError {
+	if network == "" && subnetwork == "" {
+		return nil
+	}
+
+	err := client.CheckNetwork(ctx, network, subnetwork)
+	if errors.Is(err, httpClients.NetworkNotFoundErr) || errors.Is(err, httpClients.SubnetworkNotFoundErr) ||
+		errors.Is(err, httpClients.SubnetworkNetworkMismatchErr) || errors.Is(err, httpClients.UnparsableNetworkResourceErr) {
+		return payloads.NewInvalidRequestError(ctx, "invalid network or subnetwork", err)
+	}
+	if err != nil {
+		return payloads.NewClientError(ctx, err)
+	}
+	return nil
+}
@@ -9,8 +9,11 @@ import (
 	"testing"
 
 	Clientstubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
@@ -25,8 +28,10 @@ func TestCreateGCPReservationHandler(t *testing.T) {
 	ctx = identity.WithTenant(t, ctx)
 	ctx = Clientstubs.WithSourcesClient(ctx)
 	ctx = Clientstubs.WithImageBuilderClient(ctx)
+	ctx = Clientstubs.WithGCPCCustomerClient(ctx)
 	ctx = stubs.WithReservationDao(ctx)
 	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithAccountSettingsDao(ctx)
 	pk := factories.NewPubkeyRSA()
 	err := stubs.AddPubkey(ctx, pk)
 	require.NoError(t, err, "failed to generate pubkey")
@@ -85,4 +90,175 @@ func TestCreateGCPReservationHandler(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Unsupported zone")
 		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
 	})
+
+	t.Run("failed reservation with missing service account", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":       source.ID,
+			"image_id":        "80967e7f-efef-4eee-85b0-bd4cef4c455d",
+			"amount":          1,
+			"zone":            "us-central1-a",
+			"machine_type":    "n1-standard-1",
+			"pubkey_id":       pk.ID,
+			"service_account": "missing@example-project-id.iam.gserviceaccount.com",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(Clientstubs.WithGCPCCustomerClient(ctx), "POST", "/api/provisioning/reservations/gcp", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateGCPReservation)
+		handler.ServeHTTP(rr, req)
+		assert.Contains(t, rr.Body.String(), "cannot be attached")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with subnetwork in another region", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":    source.ID,
+			"image_id":     "80967e7f-efef-4eee-85b0-bd4cef4c455d",
+			"amount":       1,
+			"zone":         "us-central1-a",
+			"machine_type": "n1-standard-1",
+			"pubkey_id":    pk.ID,
+			"subnetwork":   "projects/host-project/regions/us-east1/subnetworks/shared",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(Clientstubs.WithGCPCCustomerClient(ctx), "POST", "/api/provisioning/reservations/gcp", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateGCPReservation)
+		handler.ServeHTTP(rr, req)
+		assert.Contains(t, rr.Body.String(), "is not in region us-central1")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with missing network", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":    source.ID,
+			"image_id":     "80967e7f-efef-4eee-85b0-bd4cef4c455d",
+			"amount":       1,
+			"zone":         "us-central1-a",
+			"machine_type": "n1-standard-1",
+			"pubkey_id":    pk.ID,
+			"network":      "missing",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(Clientstubs.WithGCPCCustomerClient(ctx), "POST", "/api/provisioning/reservations/gcp", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateGCPReservation)
+		handler.ServeHTTP(rr, req)
+		assert.Contains(t, rr.Body.String(), "invalid network or subnetwork")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation over spend cap", func(t *testing.T) {
+		rates := config.Application.Spend
+		defer func() { config.Application.Spend = rates }()
+		config.Application.Spend.VCPUHour = 0.03
+		config.Application.Spend.MemoryGiBHour = 0.004
+
+		settingsDao := dao.GetAccountSettingsDao(ctx)
+		require.NoError(t, settingsDao.Upsert(ctx, &models.AccountSettings{MaxHourlySpend: 0.1}))
+		defer func() { require.NoError(t, settingsDao.Upsert(ctx, &models.AccountSettings{})) }()
+
+		values := map[string]interface{}{
+			"source_id":    source.ID,
+			"image_id":     "80967e7f-efef-4eee-85b0-bd4cef4c455d",
+			"amount":       2,
+			"zone":         "us-central1-a",
+			"machine_type": "n1-standard-1",
+			"pubkey_id":    pk.ID,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateGCPReservation)
+		require.Equal(t, http.StatusForbidden, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.ResponseError
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		require.NotNil(t, result.SpendCap)
+		assert.Equal(t, 0.1, result.SpendCap.MaxHourlySpend)
+		assert.Greater(t, result.SpendCap.RunningHourlySpend, 0.0, "reservation created above should be counted")
+		assert.Greater(t, result.SpendCap.RequestedHourlySpend, 0.0)
+	})
+
+	t.Run("failed reservation without required tags", func(t *testing.T) {
+		settingsDao := dao.GetAccountSettingsDao(ctx)
+		require.NoError(t, settingsDao.Upsert(ctx, &models.AccountSettings{RequiredTags: []string{"cost-center"}}))
+		defer func() { require.NoError(t, settingsDao.Upsert(ctx, &models.AccountSettings{})) }()
+
+		values := map[string]interface{}{
+			"source_id":    source.ID,
+			"image_id":     "80967e7f-efef-4eee-85b0-bd4cef4c455d",
+			"amount":       1,
+			"zone":         "us-central1-a",
+			"machine_type": "n1-standard-1",
+			"pubkey_id":    pk.ID,
+			"tags":         map[string]string{"team": "qe"},
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateGCPReservation)
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.ResponseError
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		require.Len(t, result.Fields, 1)
+		assert.Equal(t, "tags.cost-center", result.Fields[0].Field)
+		assert.Equal(t, payloads.ConstraintRequired, result.Fields[0].Constraint)
+	})
+
+	t.Run("dry run reservation", func(t *testing.T) {
+		before := stubs.GCPReservationStubCount(ctx)
+		values := map[string]interface{}{
+			"source_id":    source.ID,
+			"image_id":     "80967e7f-efef-4eee-85b0-bd4cef4c455d",
+			"amount":       1,
+			"zone":         "us-central1-a",
+			"machine_type": "n1-standard-1",
+			"pubkey_id":    pk.ID,
+			"dry_run":      true,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateGCPReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.DryRunResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.True(t, result.Passed)
+		assert.Equal(t, "gcp", result.Provider)
+		assert.Equal(t, before, stubs.GCPReservationStubCount(ctx), "Reservation must not be created by dry run")
+	})
+
+	t.Run("dry run reservation rejected by provider", func(t *testing.T) {
+		values := map[string]interface{}{
+			"source_id":            source.ID,
+			"image_id":             "80967e7f-efef-4eee-85b0-bd4cef4c455d",
+			"amount":               1,
+			"zone":                 "us-central1-a",
+			"launch_template_name": "missing-template",
+			"pubkey_id":            pk.ID,
+			"dry_run":              true,
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateGCPReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.DryRunResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.False(t, result.Passed)
+		assert.Equal(t, "NOT_FOUND", result.Code)
+	})
 }
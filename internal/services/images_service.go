@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+// ListImages lists image builder images of the organization, optionally filtered by provider
+// and architecture. Image list is cached so the launch wizard can query it repeatedly.
+func ListImages(w http.ResponseWriter, r *http.Request) {
+	provider := r.URL.Query().Get("provider")
+	providerType := models.ProviderTypeFromString(provider)
+	if provider != "" && providerType == models.ProviderTypeUnknown {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown provider: %s", provider), clients.UnknownProviderErr))
+		return
+	}
+
+	var arch clients.ArchitectureType
+	if archParam := r.URL.Query().Get("architecture"); archParam != "" {
+		var err error
+		arch, err = clients.MapArchitectures(r.Context(), archParam)
+		if err != nil {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unsupported architecture", err))
+			return
+		}
+	}
+
+	images, err := listImages(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	result := make([]*clients.Image, 0, len(images))
+	for _, image := range images {
+		if provider != "" && image.Provider != providerType {
+			continue
+		}
+		if arch != "" && image.Architecture != arch {
+			continue
+		}
+		result = append(result, image)
+	}
+
+	if err := render.Render(w, r, payloads.NewListResponse(payloads.NewListImagesResponse(result))); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render images list", err))
+		return
+	}
+}
+
+func listImages(ctx context.Context) ([]*clients.Image, error) {
+	key := identity.Identity(ctx).Identity.OrgID
+	result := &clients.ImageList{}
+
+	err := cache.Find(ctx, key, result)
+	if errors.Is(err, cache.ErrNotFound) {
+		client, clientErr := clients.GetImageBuilderClient(ctx)
+		if clientErr != nil {
+			return nil, fmt.Errorf("unable to initialize image builder client: %w", clientErr)
+		}
+
+		result.Images, clientErr = client.ListImages(ctx)
+		if clientErr != nil {
+			return nil, fmt.Errorf("unable to list images: %w", clientErr)
+		}
+
+		clientErr = cache.Set(ctx, key, result)
+		if clientErr != nil {
+			return nil, fmt.Errorf("cache set error: %w", clientErr)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("cache find error: %w", err)
+	}
+
+	return result.Images, nil
+}
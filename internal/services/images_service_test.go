@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	clientStub "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListImagesHandler(t *testing.T) {
+	listImages := func(t *testing.T, query string) (int, []payloads.ImageResponse) {
+		t.Helper()
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = clientStub.WithImageBuilderClient(ctx)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/images"+query, nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(ListImages)
+		handler.ServeHTTP(rr, req)
+
+		var result struct {
+			Data []payloads.ImageResponse `json:"data"`
+		}
+		if rr.Code == http.StatusOK {
+			err = json.NewDecoder(rr.Body).Decode(&result)
+			require.NoError(t, err, "failed to decode response body")
+		}
+		return rr.Code, result.Data
+	}
+
+	t.Run("without filters", func(t *testing.T) {
+		code, data := listImages(t, "")
+		require.Equal(t, http.StatusOK, code, "Handler returned wrong status code")
+		assert.Len(t, data, 3)
+	})
+
+	t.Run("with provider", func(t *testing.T) {
+		code, data := listImages(t, "?provider=azure")
+		require.Equal(t, http.StatusOK, code, "Handler returned wrong status code")
+		require.Len(t, data, 1)
+		assert.Equal(t, "azure", data[0].Provider)
+	})
+
+	t.Run("with provider and architecture", func(t *testing.T) {
+		code, data := listImages(t, "?provider=aws&architecture=aarch64")
+		require.Equal(t, http.StatusOK, code, "Handler returned wrong status code")
+		require.Len(t, data, 1)
+		assert.Equal(t, "arm64", data[0].Architecture)
+	})
+
+	t.Run("with unknown provider", func(t *testing.T) {
+		code, _ := listImages(t, "?provider=unknown")
+		assert.Equal(t, http.StatusBadRequest, code, "Handler returned wrong status code")
+	})
+}
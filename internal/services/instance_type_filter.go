@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+)
+
+// parseInstanceTypeFilter reads optional query parameters min_vcpus, min_memory (MiB),
+// min_gpus, architecture and supported. Returns error payload ready for rendering.
+func parseInstanceTypeFilter(r *http.Request) (*clients.InstanceTypeFilter, *payloads.ResponseError) {
+	query := r.URL.Query()
+	filter := clients.InstanceTypeFilter{}
+
+	minVCPUs, err := ParseOptionalInt64(query.Get("min_vcpus"))
+	if err != nil || minVCPUs < 0 || minVCPUs > math.MaxInt32 {
+		return nil, payloads.NewInvalidRequestError(r.Context(), "parameter 'min_vcpus' could not be parsed", err)
+	}
+	filter.MinVCPUs = int32(minVCPUs)
+
+	filter.MinMemoryMiB, err = ParseOptionalInt64(query.Get("min_memory"))
+	if err != nil || filter.MinMemoryMiB < 0 {
+		return nil, payloads.NewInvalidRequestError(r.Context(), "parameter 'min_memory' could not be parsed", err)
+	}
+
+	minGPUs, err := ParseOptionalInt64(query.Get("min_gpus"))
+	if err != nil || minGPUs < 0 || minGPUs > math.MaxInt32 {
+		return nil, payloads.NewInvalidRequestError(r.Context(), "parameter 'min_gpus' could not be parsed", err)
+	}
+	filter.MinGPUs = int32(minGPUs)
+
+	if arch := query.Get("architecture"); arch != "" {
+		filter.Architecture, err = clients.MapArchitectures(r.Context(), arch)
+		if err != nil {
+			return nil, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("architecture '%s' is not supported", arch), err)
+		}
+	}
+
+	filter.Supported, err = ParseBool(query.Get("supported"))
+	if err != nil {
+		return nil, payloads.NewInvalidRequestError(r.Context(), "parameter 'supported' could not be parsed", err)
+	}
+
+	return &filter, nil
+}
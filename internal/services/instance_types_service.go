@@ -14,6 +14,13 @@ func ListInstanceTypes(w http.ResponseWriter, r *http.Request) {
 	region := r.URL.Query().Get("region")
 	if region == "" {
 		renderError(w, r, payloads.NewMissingRequestParameterError(r.Context(), "region parameter is missing"))
+		return
+	}
+
+	filter, errPayload := parseInstanceTypeFilter(r)
+	if errPayload != nil {
+		renderError(w, r, errPayload)
+		return
 	}
 
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
@@ -40,7 +47,7 @@ func ListInstanceTypes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := render.RenderList(w, r, payloads.NewListInstanceTypeResponse(instances)); err != nil {
+	if err := render.Render(w, r, payloads.NewListResponse(payloads.NewListInstanceTypeResponse(filter.Filter(instances)))); err != nil {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render instance types list", err))
 		return
 	}
@@ -2,7 +2,6 @@ package services_test
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -39,10 +38,7 @@ func TestListInstanceTypesHandler(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
 
-		var result []clients.InstanceType
-
-		err = json.NewDecoder(rr.Body).Decode(&result)
-		require.NoError(t, err, "failed to decode response body")
+		result, _ := decodeList[clients.InstanceType](t, rr.Body)
 
 		assert.Equal(t, 3, len(result), "expected three result in response json")
 		for _, it := range result {
@@ -52,6 +48,49 @@ func TestListInstanceTypesHandler(t *testing.T) {
 		assert.Contains(t, names, "c5.xlarge", "expected result to contain c5.xlarge instance type")
 	})
 
+	t.Run("with filters", func(t *testing.T) {
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = clientStub.WithSourcesClient(ctx)
+		ctx = clientStub.WithEC2Client(ctx)
+
+		rctx := chi.NewRouteContext()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "1")
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/1/instance_types", nil)
+		require.NoError(t, err, "failed to create request")
+		req.URL.RawQuery = url.Values{"region": {"us-east-1"}, "min_vcpus": {"4"}, "min_memory": {"10000"}, "architecture": {"x86_64"}}.Encode()
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListInstanceTypes)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		result, _ := decodeList[clients.InstanceType](t, rr.Body)
+
+		require.Equal(t, 1, len(result), "expected one result in response json")
+		assert.Equal(t, "a1.2xlarge", result[0].Name.String())
+	})
+
+	t.Run("with invalid filter", func(t *testing.T) {
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = clientStub.WithSourcesClient(ctx)
+		ctx = clientStub.WithEC2Client(ctx)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/1/instance_types", nil)
+		require.NoError(t, err, "failed to create request")
+		req.URL.RawQuery = url.Values{"region": {"us-east-1"}, "min_vcpus": {"many"}}.Encode()
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListInstanceTypes)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Contains(t, rr.Body.String(), "min_vcpus")
+	})
+
 	t.Run("without region", func(t *testing.T) {
 		ctx := stubs.WithAccountDaoOne(context.Background())
 		ctx = identity.WithTenant(t, ctx)
@@ -84,9 +123,30 @@ func TestListAzureBuiltinInstanceTypesHandler(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
 
-	var result []clients.InstanceType
-	err = json.NewDecoder(rr.Body).Decode(&result)
-	require.NoError(t, err, "failed to decode response body")
+	result, _ := decodeList[clients.InstanceType](t, rr.Body)
 
 	assert.Less(t, 1, len(result), "the instance types response is empty")
 }
+
+func TestListAWSBuiltinInstanceTypesHandlerFiltered(t *testing.T) {
+	ctx := context.Background()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/v1/instance_types/aws", nil)
+	require.NoError(t, err, "failed to create request")
+	req.URL.RawQuery = url.Values{"region": {"us-east-1"}, "min_vcpus": {"8"}, "architecture": {"arm64"}, "supported": {"true"}}.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.ListBuiltinInstanceTypes(preload.EC2InstanceType.InstanceTypesForZone))
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+	result, _ := decodeList[clients.InstanceType](t, rr.Body)
+
+	require.Less(t, 0, len(result), "the instance types response is empty")
+	for _, it := range result {
+		assert.GreaterOrEqual(t, it.VCPUs, int32(8))
+		assert.Equal(t, clients.ArchitectureTypeArm64, it.Architecture)
+		assert.True(t, it.Supported)
+	}
+}
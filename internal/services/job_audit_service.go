@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+var ErrJobAuditFilter = errors.New("exactly one of reservation_id or account_id is required")
+
+// ListJobAudit returns job attempts of a reservation or an account, newest first. Admin only.
+func ListJobAudit(w http.ResponseWriter, r *http.Request) {
+	reservationId, err := ParseOptionalInt64(r.URL.Query().Get("reservation_id"))
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse reservation_id parameter", err))
+		return
+	}
+	accountId, err := ParseOptionalInt64(r.URL.Query().Get("account_id"))
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse account_id parameter", err))
+		return
+	}
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	var audits []*models.JobAudit
+	var total int64
+	jaDao := dao.GetJobAuditDao(r.Context())
+	switch {
+	case reservationId != 0 && accountId == 0:
+		audits, err = jaDao.UnscopedListByReservation(r.Context(), reservationId, limit, offset)
+		if err == nil {
+			total, err = jaDao.UnscopedCountByReservation(r.Context(), reservationId)
+		}
+	case accountId != 0 && reservationId == 0:
+		audits, err = jaDao.UnscopedListByAccount(r.Context(), accountId, limit, offset)
+		if err == nil {
+			total, err = jaDao.UnscopedCountByAccount(r.Context(), accountId)
+		}
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid job audit filter", ErrJobAuditFilter))
+		return
+	}
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list job audit", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewJobAuditListResponse(audits), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render job audit list", err))
+		return
+	}
+}
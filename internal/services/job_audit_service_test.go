@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareJobAuditContext(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithJobAuditDao(ctx)
+
+	record := func(accountId, reservationId int64, err error) {
+		job := &worker.Job{
+			ID:          uuid.New(),
+			Type:        jobs.TypeNoop,
+			AccountID:   accountId,
+			Args:        jobs.NoopJobArgs{ReservationID: reservationId},
+			Attempt:     1,
+			MaxAttempts: 1,
+		}
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		jobs.RecordJobExecution(ctx, job, &worker.Execution{Started: time.Now(), Duration: time.Second, Result: result, Err: err})
+	}
+	record(1, 42, nil)
+	record(1, 42, errors.New("cloud outage"))
+	record(1, 43, nil)
+	record(2, 44, nil)
+	require.Equal(t, 4, stubs.JobAuditStubCount(ctx))
+
+	return ctx
+}
+
+func serveJobAudit(t *testing.T, ctx context.Context, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/admin/job_audit?"+query, nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(services.ListJobAudit).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestListJobAuditHandler(t *testing.T) {
+	ctx := prepareJobAuditContext(t)
+
+	t.Run("by reservation", func(t *testing.T) {
+		rr := serveJobAudit(t, ctx, "reservation_id=42")
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		result, _ := decodeList[payloads.JobAuditResponse](t, rr.Body)
+		require.Len(t, result, 2)
+		assert.Equal(t, "failure", result[0].Outcome)
+		assert.Equal(t, "cloud outage", result[0].Error)
+		assert.Equal(t, int64(1000), result[0].DurationMs)
+		assert.Equal(t, "success", result[1].Outcome)
+		assert.Len(t, result[1].ArgsHash, 64)
+	})
+
+	t.Run("by account", func(t *testing.T) {
+		rr := serveJobAudit(t, ctx, "account_id=1")
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		result, _ := decodeList[payloads.JobAuditResponse](t, rr.Body)
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("missing filter", func(t *testing.T) {
+		rr := serveJobAudit(t, ctx, "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("both filters", func(t *testing.T) {
+		rr := serveJobAudit(t, ctx, "account_id=1&reservation_id=42")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
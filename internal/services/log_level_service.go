@@ -0,0 +1,47 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+// GetLogLevel returns the log level of the process. Admin only.
+func GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	level, resetAt := logging.Level()
+	response := payloads.NewLogLevelResponse(level.String(), logging.ConfiguredLevel().String(), resetAt)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render log level", err))
+	}
+}
+
+// SetLogLevel changes the log level of the process for a limited time, other replicas are
+// not affected. Setting the configured level restores it immediately. Admin only.
+func SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	payload := &payloads.LogLevelRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "set log level", err))
+		return
+	}
+
+	level, err := zerolog.ParseLevel(payload.Level)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse level", err))
+		return
+	}
+	window := payload.Window
+	if window == 0 {
+		window = config.Logging.LevelWindow
+	}
+	if level == logging.ConfiguredLevel() {
+		logging.ResetLevel()
+	} else {
+		logging.SetLevel(level, window)
+	}
+
+	GetLogLevel(w, r)
+}
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveSetLogLevel(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), "PUT", "/api/provisioning/admin/log_level", bytes.NewBufferString(body))
+	require.NoError(t, err, "failed to create request")
+	req.Header.Add("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(services.SetLogLevel).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestSetLogLevelHandler(t *testing.T) {
+	level := config.Logging.Level
+	config.Logging.Level = "info"
+	t.Cleanup(func() {
+		logging.ResetLevel()
+		config.Logging.Level = level
+	})
+
+	t.Run("temporary change", func(t *testing.T) {
+		rr := serveSetLogLevel(t, `{"level":"trace","duration":"5m"}`)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.LogLevelResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+		assert.Equal(t, "trace", result.Level)
+		assert.Equal(t, "info", result.Configured)
+		assert.NotNil(t, result.ResetAt)
+	})
+
+	t.Run("configured level restores", func(t *testing.T) {
+		rr := serveSetLogLevel(t, `{"level":"info"}`)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.LogLevelResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+		assert.Nil(t, result.ResetAt)
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		rr := serveSetLogLevel(t, `{"level":"panic"}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		rr := serveSetLogLevel(t, `{"level":"debug","duration":"-1m"}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
@@ -37,6 +38,7 @@ func CreateNoopReservation(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "create noop reservation", err))
 		return
 	}
+	metrics.IncReservationsCreated(models.ProviderTypeNoop)
 	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
 
 	// create a new job
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -39,3 +41,40 @@ func ParseBool(str string) (*bool, error) {
 	}
 	return &b, nil
 }
+
+// ParseOptionalInt64 converts string into int64. Returns zero when string is empty.
+func ParseOptionalInt64(str string) (int64, error) {
+	if str == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing '%s' to int64: %w", str, err)
+	}
+	return i, nil
+}
+
+// maxListLimit is the maximum page size of paginated lists.
+const maxListLimit = 1000
+
+var ErrInvalidPage = errors.New("limit must be between 1 and 1000 and offset must not be negative")
+
+// ParsePage converts limit and offset query parameters of paginated lists. Limit defaults to
+// payloads.DefaultListLimit.
+func ParsePage(r *http.Request) (int64, int64, error) {
+	limit, err := ParseOptionalInt64(r.URL.Query().Get("limit"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit == 0 && r.URL.Query().Get("limit") == "" {
+		limit = payloads.DefaultListLimit
+	}
+	offset, err := ParseOptionalInt64(r.URL.Query().Get("offset"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit < 1 || limit > maxListLimit || offset < 0 {
+		return 0, 0, ErrInvalidPage
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeList decodes items and metadata of a list response envelope.
+func decodeList[T any](t *testing.T, body io.Reader) ([]T, payloads.ListMeta) {
+	t.Helper()
+
+	var envelope struct {
+		Data []T               `json:"data"`
+		Meta payloads.ListMeta `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(body).Decode(&envelope), "failed to decode response body")
+	return envelope.Data, envelope.Meta
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		limit  int64
+		offset int64
+		err    bool
+	}{
+		{"defaults", "", payloads.DefaultListLimit, 0, false},
+		{"both", "limit=10&offset=20", 10, 20, false},
+		{"zero limit", "limit=0", 0, 0, true},
+		{"too large limit", "limit=1001", 0, 0, true},
+		{"negative offset", "offset=-1", 0, 0, true},
+		{"not a number", "limit=ten", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/api/provisioning/v1/pubkeys?"+tt.query, nil)
+			require.NoError(t, err)
+
+			limit, offset, err := services.ParsePage(req)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.limit, limit)
+			assert.Equal(t, tt.offset, offset)
+		})
+	}
+}
@@ -4,30 +4,32 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/ssh"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
 	"github.com/rs/zerolog"
 )
 
-var ErrMissingNameOrBody = errors.New("name or body missing")
+var ErrPubkeyNotPublished = errors.New("pubkey not published by the user")
 
 func CreatePubkey(w http.ResponseWriter, r *http.Request) {
 	payload := &payloads.PubkeyRequest{}
 	if err := render.Bind(r, payload); err != nil {
-		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "create pubkey", err))
+		renderError(w, r, payloads.NewBindError(r.Context(), "create pubkey", err))
 		return
 	}
 
-	if payload.Name == "" || payload.Body == "" {
-		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), ErrMissingNameOrBody.Error(), ErrMissingNameOrBody))
-	}
-
 	pkDao := dao.GetPubkeyDao(r.Context())
 
 	pk := payload.NewModel()
@@ -47,15 +49,27 @@ func CreatePubkey(w http.ResponseWriter, r *http.Request) {
 }
 
 func ListPubkeys(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
 	pubkeyDao := dao.GetPubkeyDao(r.Context())
 
-	pubkeys, err := pubkeyDao.List(r.Context(), 100, 0)
+	pubkeys, err := pubkeyDao.List(r.Context(), limit, offset)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkeys", err))
 		return
 	}
+	total, err := pubkeyDao.Count(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count pubkeys", err))
+		return
+	}
 
-	if err := render.RenderList(w, r, payloads.NewPubkeyListResponse(pubkeys)); err != nil {
+	response := payloads.NewPageResponse(r, payloads.NewPubkeyListResponse(pubkeys), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkeys list", err))
 		return
 	}
@@ -82,6 +96,40 @@ func GetPubkey(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetPubkeyUsage returns clouds the pubkey was uploaded to and reservations which used it.
+func GetPubkeyUsage(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	pubkeyDao := dao.GetPubkeyDao(r.Context())
+
+	pubkey, err := pubkeyDao.GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get pubkey with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	resources, err := pubkeyDao.UnscopedListResourcesByPubkeyId(r.Context(), pubkey.ID)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkey resources", err))
+		return
+	}
+
+	reservations, err := dao.GetReservationDao(r.Context()).ListByPubkeyId(r.Context(), pubkey.ID)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkey reservations", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewPubkeyUsageResponse(pubkey, resources, reservations)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey usage", err))
+	}
+}
+
 func DeletePubkey(w http.ResponseWriter, r *http.Request) {
 	logger := zerolog.Ctx(r.Context())
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
@@ -96,6 +144,12 @@ func DeletePubkey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	cascade, err := ParseBool(r.URL.Query().Get("cascade"))
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse cascade parameter", err))
+		return
+	}
+
 	pubkeyDao := dao.GetPubkeyDao(r.Context())
 
 	pubkey, err := pubkeyDao.GetById(r.Context(), id)
@@ -104,6 +158,10 @@ func DeletePubkey(w http.ResponseWriter, r *http.Request) {
 		renderNotFoundOrDAOError(w, r, err, message)
 		return
 	}
+	if !pubkey.ModifiableBy(identity.Owner(r.Context())) {
+		renderError(w, r, payloads.NewNotOwnerError(r.Context(), fmt.Sprintf("pubkey %d", id), NotOwnerError))
+		return
+	}
 
 	resources, err := pubkeyDao.UnscopedListResourcesByPubkeyId(r.Context(), pubkey.ID)
 	if err != nil {
@@ -112,6 +170,11 @@ func DeletePubkey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if cascade != nil && *cascade && len(resources) > 0 {
+		deletePubkeyCascade(w, r, pubkey, resources)
+		return
+	}
+
 	for _, res := range resources {
 		if res.Provider == models.ProviderTypeAWS {
 			if res.Handle != "" {
@@ -151,3 +214,173 @@ func DeletePubkey(w http.ResponseWriter, r *http.Request) {
 
 	render.NoContent(w, r)
 }
+
+// RestorePubkey clears the deleted mark of a pubkey which was not purged yet. Key-pairs were
+// removed from clouds during the deletion, they are uploaded again on the next launch.
+func RestorePubkey(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	pubkeyDao := dao.GetPubkeyDao(r.Context())
+
+	err = pubkeyDao.Restore(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, dao.ErrAffectedMismatch) {
+			renderError(w, r, payloads.NewNotFoundError(r.Context(), "restore deleted pubkey", err))
+		} else if db.IsPostgresError(err, db.UniqueConstraintErrorCode) != nil {
+			renderError(w, r, payloads.PubkeyDuplicateError(r.Context(), "pubkey with such name or fingerprint already exists for this account", err))
+		} else {
+			renderError(w, r, payloads.NewDAOError(r.Context(), "restore pubkey", err))
+		}
+		return
+	}
+
+	// replica might not contain the change yet
+	pubkey, err := pubkeyDao.GetById(db.WithPrimary(r.Context()), id)
+	if err != nil {
+		message := fmt.Sprintf("get pubkey with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewPubkeyResponse(pubkey)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
+	}
+}
+
+// deletePubkeyCascade enqueues a job for every pubkey resource which removes the key-pair from
+// the cloud. The last job deletes the pubkey itself. Resources which could not be scheduled
+// are reported back and kept together with the pubkey, so the request can be repeated.
+func deletePubkeyCascade(w http.ResponseWriter, r *http.Request, pubkey *models.Pubkey, resources []*models.PubkeyResource) {
+	logger := zerolog.Ctx(r.Context())
+	response := &payloads.PubkeyDeleteResponse{
+		ID:        pubkey.ID,
+		Resources: make([]*payloads.PubkeyDeleteResourceResponse, 0, len(resources)),
+	}
+
+	for _, res := range resources {
+		result := &payloads.PubkeyDeleteResourceResponse{
+			ID:       res.ID,
+			Provider: res.Provider.String(),
+			SourceID: res.SourceID,
+			Region:   res.Region,
+			Status:   "enqueued",
+		}
+
+		deleteJob := worker.Job{
+			Type:      jobs.TypeDeletePubkeyResource,
+			Identity:  identity.Identity(r.Context()),
+			AccountID: identity.AccountId(r.Context()),
+			Args: jobs.DeletePubkeyResourceTaskArgs{
+				PubkeyID:   pubkey.ID,
+				ResourceID: res.ID,
+				Provider:   res.Provider,
+				SourceID:   res.SourceID,
+				Region:     res.Region,
+				Handle:     res.Handle,
+			},
+		}
+
+		err := queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &deleteJob)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("Unable to enqueue delete of pubkey resource %d", res.ID)
+			result.Status = "failed"
+			result.Error = err.Error()
+		}
+		response.Resources = append(response.Resources, result)
+	}
+
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey delete", err))
+	}
+}
+
+// ImportPubkeys fetches keys published by a GitHub or GitLab user and stores keys selected by
+// their fingerprints. When no fingerprint is selected, published keys are only returned.
+func ImportPubkeys(w http.ResponseWriter, r *http.Request) {
+	logger := zerolog.Ctx(r.Context())
+	payload := &payloads.PubkeyImportRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "import pubkeys", err))
+		return
+	}
+
+	site := clients.KeyHostSiteFromString(payload.Site)
+	if site == "" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "site must be github or gitlab", clients.UnknownKeyHostErr))
+		return
+	}
+
+	keyHostClient, err := clients.GetKeyHostClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	bodies, err := keyHostClient.ListUserKeys(r.Context(), site, payload.Username)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	response := &payloads.PubkeyImportResponse{Keys: make([]*payloads.PubkeyImportKeyResponse, 0, len(bodies))}
+	published := make(map[string]bool, len(bodies))
+	for _, body := range bodies {
+		fps, fpErr := ssh.GenerateOpenSSHFingerprints([]byte(body))
+		if fpErr != nil {
+			logger.Warn().Err(fpErr).Msgf("Skipping unparseable key published by %s user %s", site, payload.Username)
+			continue
+		}
+		published[fps.SHA256] = true
+		response.Keys = append(response.Keys, &payloads.PubkeyImportKeyResponse{
+			Body:        body,
+			Type:        fps.Type,
+			Fingerprint: fps.SHA256,
+		})
+	}
+
+	selected := make(map[string]bool, len(payload.Fingerprints))
+	for _, fp := range payload.Fingerprints {
+		if !published[fp] {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("key %s is not published by the user", fp), ErrPubkeyNotPublished))
+			return
+		}
+		selected[fp] = true
+	}
+
+	pkDao := dao.GetPubkeyDao(r.Context())
+	importedAt := time.Now()
+	for i, key := range response.Keys {
+		if !selected[key.Fingerprint] {
+			continue
+		}
+
+		pk := &models.Pubkey{
+			Name:           fmt.Sprintf("%s-%s-%d", site, payload.Username, i+1),
+			Body:           key.Body,
+			Origin:         site.String(),
+			OriginUsername: payload.Username,
+			ImportedAt:     &importedAt,
+		}
+		key.Name = pk.Name
+		createErr := pkDao.Create(r.Context(), pk)
+		if createErr != nil {
+			logger.Warn().Err(createErr).Msgf("Unable to import pubkey %s", key.Fingerprint)
+			if db.IsPostgresError(createErr, db.UniqueConstraintErrorCode) != nil {
+				key.Error = "pubkey with such name or fingerprint already exists for this account"
+			} else {
+				key.Error = "unable to store pubkey"
+			}
+			continue
+		}
+		key.ID = pk.ID
+		key.Imported = true
+	}
+
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render imported pubkeys", err))
+	}
+}
@@ -4,16 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
 
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
 	"github.com/stretchr/testify/assert"
@@ -43,12 +49,12 @@ func TestListPubkeysHandler(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
 
-	var pubkeys []models.Pubkey
-
-	err = json.NewDecoder(rr.Body).Decode(&pubkeys)
-	require.NoError(t, err, "failed to decode response body")
+	pubkeys, meta := decodeList[models.Pubkey](t, rr.Body)
 
 	assert.Equal(t, 2, len(pubkeys), "expected two pubkeys in response json")
+	assert.Equal(t, 2, meta.Count)
+	assert.Equal(t, int64(2), meta.Total)
+	assert.Empty(t, meta.Links.Next)
 }
 
 func TestCreatePubkeyHandler(t *testing.T) {
@@ -80,3 +86,168 @@ func TestCreatePubkeyHandler(t *testing.T) {
 	stubCount := stubs.PubkeyStubCount(ctx)
 	assert.Equal(t, 1, stubCount, "Pubkey has not been Created through DAO")
 }
+
+func TestCreatePubkeyHandlerValidation(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/pubkeys", bytes.NewBufferString(`{"name": "key"}`))
+	require.NoError(t, err, "failed to create request")
+	req.Header.Add("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.CreatePubkey)
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+
+	var result payloads.ResponseError
+	err = json.NewDecoder(rr.Body).Decode(&result)
+	require.NoError(t, err, "failed to decode response body")
+	require.Len(t, result.Fields, 1)
+	assert.Equal(t, "body", result.Fields[0].Field)
+	assert.Equal(t, payloads.ConstraintRequired, result.Fields[0].Constraint)
+
+	assert.Equal(t, 0, stubs.PubkeyStubCount(ctx), "Pubkey should not be created")
+}
+
+func TestImportPubkeysHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = clientStubs.WithKeyHostClient(ctx)
+
+	importKeys := func(t *testing.T, values map[string]interface{}) (*httptest.ResponseRecorder, *payloads.PubkeyImportResponse) {
+		t.Helper()
+		jsonData, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/pubkeys/import", bytes.NewBuffer(jsonData))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ImportPubkeys)
+		handler.ServeHTTP(rr, req)
+
+		result := &payloads.PubkeyImportResponse{}
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(result), "failed to decode response body")
+		}
+		return rr, result
+	}
+
+	t.Run("lists published keys", func(t *testing.T) {
+		rr, result := importKeys(t, map[string]interface{}{"site": "github", "username": "lzap"})
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		assert.Equal(t, 2, len(result.Keys))
+		assert.Equal(t, "ssh-ed25519", result.Keys[0].Type)
+		assert.False(t, result.Keys[0].Imported)
+		assert.Equal(t, 0, stubs.PubkeyStubCount(ctx), "No pubkey should be created")
+	})
+
+	t.Run("imports selected keys", func(t *testing.T) {
+		rr, result := importKeys(t, map[string]interface{}{
+			"site":         "github",
+			"username":     "lzap",
+			"fingerprints": []string{"gL/y6MvNmJ8jDXtsL/oMmK8jUuIefN39BBuvYw/Rndk="},
+		})
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		assert.True(t, result.Keys[0].Imported)
+		assert.Equal(t, "github-lzap-1", result.Keys[0].Name)
+		assert.False(t, result.Keys[1].Imported)
+		assert.Equal(t, 1, stubs.PubkeyStubCount(ctx), "Pubkey has not been created through DAO")
+	})
+
+	t.Run("unknown fingerprint", func(t *testing.T) {
+		rr, _ := importKeys(t, map[string]interface{}{
+			"site":         "gitlab",
+			"username":     "lzap",
+			"fingerprints": []string{"unknown"},
+		})
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("unknown site", func(t *testing.T) {
+		rr, _ := importKeys(t, map[string]interface{}{"site": "bitbucket", "username": "lzap"})
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestGetPubkeyUsageHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	err = dao.GetPubkeyDao(ctx).UnscopedCreateResource(ctx, &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeAWS,
+		SourceID: "1",
+		Handle:   "key-1",
+		Region:   "us-east-1",
+	})
+	require.NoError(t, err, "failed to add stubbed resource")
+
+	reservation := &models.GCPReservation{
+		PubkeyID: pk.ID,
+		SourceID: "2",
+		Detail:   &models.GCPDetail{Zone: "us-east4-a"},
+	}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeGCP
+	err = dao.GetReservationDao(ctx).CreateGCP(ctx, reservation)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	rctx := chi.NewRouteContext()
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	rctx.URLParams.Add("ID", strconv.FormatInt(pk.ID, 10))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("/api/provisioning/pubkeys/%d/usage", pk.ID), nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.GetPubkeyUsage)
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+	var usage payloads.PubkeyUsageResponse
+	err = json.NewDecoder(rr.Body).Decode(&usage)
+	require.NoError(t, err, "failed to decode response body")
+
+	assert.Equal(t, []string{"aws", "gcp"}, usage.Providers)
+	assert.Equal(t, []string{"1", "2"}, usage.Sources)
+	assert.Equal(t, []string{"us-east-1", "us-east4-a"}, usage.Regions)
+	assert.Equal(t, 1, len(usage.Resources))
+	assert.Equal(t, 1, len(usage.Reservations))
+}
+
+func TestRestorePubkeyHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	err = dao.GetPubkeyDao(ctx).Delete(ctx, pk.ID)
+	require.NoError(t, err, "failed to delete stubbed key")
+	require.Equal(t, 0, stubs.PubkeyStubCount(ctx))
+
+	rr := serveJSON(t, withIDParam(ctx, pk.ID), "POST", nil, services.RestorePubkey)
+	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+	assert.Equal(t, 1, stubs.PubkeyStubCount(ctx))
+
+	rr = serveJSON(t, withIDParam(ctx, pk.ID), "POST", nil, services.RestorePubkey)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+}
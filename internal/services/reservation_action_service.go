@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var (
+	ReservationNotLaunchedError   = errors.New("reservation did not finish successfully")
+	InstanceActionInProgressError = errors.New("instance has an unfinished action")
+)
+
+// CreateReservationInstanceAction enqueues a stop, start or reboot of an instance of a successfully
+// finished reservation. Only one action per instance can be pending or running at a time, the
+// action status is tracked and listed by ListReservationInstanceActions.
+func CreateReservationInstanceAction(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+	instanceParam, err := url.PathUnescape(chi.URLParam(r, "INSTANCE_ID"))
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse INSTANCE_ID parameter", err))
+		return
+	}
+
+	payload := &payloads.InstanceActionRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "instance action", err))
+		return
+	}
+
+	reservation, err := dao.GetReservationDao(r.Context()).GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation for instance action")
+		return
+	}
+	if !reservation.ModifiableBy(identity.Owner(r.Context())) {
+		renderError(w, r, payloads.NewNotOwnerError(r.Context(), fmt.Sprintf("reservation %d", id), NotOwnerError))
+		return
+	}
+	if !reservation.Success.Valid || !reservation.Success.Bool {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "instance actions require a successfully finished reservation", ReservationNotLaunchedError))
+		return
+	}
+
+	instanceID, respErr := findReservationInstance(r.Context(), reservation.ID, instanceParam)
+	if respErr != nil {
+		renderError(w, r, respErr)
+		return
+	}
+
+	action := &models.InstanceAction{
+		ReservationID: reservation.ID,
+		InstanceID:    instanceID,
+		Action:        models.InstanceActionType(payload.Action),
+	}
+	jobType, args, respErr := instanceActionArgs(r.Context(), reservation, action)
+	if respErr != nil {
+		renderError(w, r, respErr)
+		return
+	}
+
+	actionDao := dao.GetInstanceActionDao(r.Context())
+	err = actionDao.Create(r.Context(), action)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		message := fmt.Sprintf("instance %s has an unfinished action", instanceID)
+		renderError(w, r, payloads.NewResponseError(r.Context(), http.StatusConflict, message, InstanceActionInProgressError))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "create instance action", err))
+		return
+	}
+
+	args.ActionID = action.ID
+	job := worker.Job{
+		Type:      jobType,
+		Identity:  identity.Identity(r.Context()),
+		AccountID: identity.AccountId(r.Context()),
+		Args:      *args,
+	}
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &job)
+	if err != nil {
+		// the action would block further actions of the instance otherwise
+		_ = actionDao.UnscopedUpdateStatus(r.Context(), action.ID, models.InstanceActionFailed, err.Error())
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewInstanceActionResponse(action)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render instance action", err))
+	}
+}
+
+// instanceActionArgs returns the type and arguments of the provider job performing the action,
+// the action ID is set by the caller once the action is stored.
+//
+//nolint:exhaustive
+func instanceActionArgs(ctx context.Context, reservation *models.Reservation, action *models.InstanceAction) (worker.JobType, *jobs.InstanceActionTaskArgs, *payloads.ResponseError) {
+	rDao := dao.GetReservationDao(ctx)
+	args := &jobs.InstanceActionTaskArgs{
+		ReservationID: reservation.ID,
+		InstanceID:    action.InstanceID,
+		Action:        action.Action,
+	}
+
+	var jobType worker.JobType
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		awsReservation, err := rDao.GetAWSById(ctx, reservation.ID)
+		if err != nil {
+			return "", nil, payloads.NewDAOError(ctx, "get AWS reservation", err)
+		}
+		jobType = jobs.TypeInstanceActionAws
+		args.SourceID = awsReservation.SourceID
+		args.Location = awsReservation.Detail.Region
+	case models.ProviderTypeAzure:
+		azureReservation, err := rDao.GetAzureById(ctx, reservation.ID)
+		if err != nil {
+			return "", nil, payloads.NewDAOError(ctx, "get Azure reservation", err)
+		}
+		jobType = jobs.TypeInstanceActionAzure
+		args.SourceID = azureReservation.SourceID
+	case models.ProviderTypeGCP:
+		gcpReservation, err := rDao.GetGCPById(ctx, reservation.ID)
+		if err != nil {
+			return "", nil, payloads.NewDAOError(ctx, "get GCP reservation", err)
+		}
+		jobType = jobs.TypeInstanceActionGcp
+		args.SourceID = gcpReservation.SourceID
+		args.Location = gcpReservation.Detail.Zone
+	default:
+		return "", nil, payloads.NewInvalidRequestError(ctx, "provider does not support instance actions", ProviderTypeNotImplementedError)
+	}
+
+	return jobType, args, nil
+}
+
+// ListReservationInstanceActions returns actions of all instances of a reservation, newest first.
+func ListReservationInstanceActions(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	reservation, err := dao.GetReservationDao(r.Context()).GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation for instance actions")
+		return
+	}
+
+	actionDao := dao.GetInstanceActionDao(r.Context())
+	actions, err := actionDao.ListByReservation(r.Context(), reservation.ID, limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list instance actions", err))
+		return
+	}
+	total, err := actionDao.CountByReservation(r.Context(), reservation.ID)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count instance actions", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewInstanceActionListResponse(actions), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render instance actions list", err))
+	}
+}
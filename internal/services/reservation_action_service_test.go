@@ -0,0 +1,91 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateReservationInstanceAction(t *testing.T) {
+	recorder := &recordingEnqueuer{}
+	original := queue.GetEnqueuer
+	queue.GetEnqueuer = func(_ context.Context) worker.JobEnqueuer { return recorder }
+	t.Cleanup(func() { queue.GetEnqueuer = original })
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = stubs.WithInstanceActionDao(ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+
+	reservation := &models.AWSReservation{SourceID: "1", Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = identity2.AccountId(ctx)
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.Success = sql.NullBool{Bool: true, Valid: true}
+	require.NoError(t, stubs.AddAWSReservation(ctx, reservation), "failed to create stub reservation")
+	require.NoError(t, dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{ReservationID: reservation.ID, InstanceID: "i-0a4caa2cf5b097ce1"}))
+
+	t.Run("stop instance", func(t *testing.T) {
+		values := map[string]any{"action": "stop"}
+		rr := serveJSON(t, withInstanceParams(ctx, reservation.ID, "i-0a4caa2cf5b097ce1"), "POST", values, services.CreateReservationInstanceAction)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.InstanceActionResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		assert.Equal(t, "stop", response.Action)
+		assert.Equal(t, "pending", response.Status)
+
+		require.Len(t, recorder.enqueued, 1)
+		assert.Equal(t, jobs.TypeInstanceActionAws, recorder.enqueued[0].Type)
+		args, ok := recorder.enqueued[0].Args.(jobs.InstanceActionTaskArgs)
+		require.True(t, ok)
+		assert.Equal(t, response.ID, args.ActionID)
+		assert.Equal(t, "us-east-1", args.Location)
+	})
+
+	t.Run("unfinished action", func(t *testing.T) {
+		values := map[string]any{"action": "reboot"}
+		rr := serveJSON(t, withInstanceParams(ctx, reservation.ID, "i-0a4caa2cf5b097ce1"), "POST", values, services.CreateReservationInstanceAction)
+		assert.Equal(t, http.StatusConflict, rr.Code, "Wrong status code")
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		values := map[string]any{"action": "hibernate"}
+		rr := serveJSON(t, withInstanceParams(ctx, reservation.ID, "i-0a4caa2cf5b097ce1"), "POST", values, services.CreateReservationInstanceAction)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+
+	t.Run("instance of another reservation", func(t *testing.T) {
+		values := map[string]any{"action": "start"}
+		rr := serveJSON(t, withInstanceParams(ctx, reservation.ID, "i-other"), "POST", values, services.CreateReservationInstanceAction)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+
+	t.Run("list actions", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "GET", nil, services.ListReservationInstanceActions)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response struct {
+			Data []payloads.InstanceActionResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "i-0a4caa2cf5b097ce1", response.Data[0].InstanceID)
+	})
+}
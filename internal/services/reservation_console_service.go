@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var InstanceNotInReservationError = errors.New("instance does not belong to the reservation")
+
+// GetReservationInstanceConsole returns console output of an instance of a reservation queried
+// from the provider. Azure instances can be identified by the VM name instead of the URL encoded
+// resource ID.
+func GetReservationInstanceConsole(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+	instanceParam, err := url.PathUnescape(chi.URLParam(r, "INSTANCE_ID"))
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse INSTANCE_ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation instance console")
+		return
+	}
+
+	instanceID, respErr := findReservationInstance(r.Context(), reservation.ID, instanceParam)
+	if respErr != nil {
+		renderError(w, r, respErr)
+		return
+	}
+
+	output, respErr := instanceConsoleOutput(r.Context(), reservation, instanceID)
+	if respErr != nil {
+		renderError(w, r, respErr)
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewInstanceConsoleResponse(instanceID, output)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render instance console", err))
+	}
+}
+
+// findReservationInstance returns the instance ID of a reservation instance identified by the ID
+// or by the last segment of the ID (Azure VM name).
+func findReservationInstance(ctx context.Context, reservationID int64, instanceParam string) (string, *payloads.ResponseError) {
+	instances, err := dao.GetReservationDao(ctx).ListInstances(ctx, reservationID)
+	if err != nil {
+		return "", payloads.NewDAOError(ctx, "list reservation instances", err)
+	}
+	for _, instance := range instances {
+		if instance.InstanceID == instanceParam || path.Base(instance.InstanceID) == instanceParam {
+			return instance.InstanceID, nil
+		}
+	}
+	return "", payloads.NewNotFoundError(ctx, fmt.Sprintf("instance %s of reservation %d", instanceParam, reservationID), InstanceNotInReservationError)
+}
+
+// instanceConsoleOutput queries the provider for the console output of an instance: EC2 console
+// output, serial log of Azure boot diagnostics or the first GCP serial port.
+//
+//nolint:exhaustive
+func instanceConsoleOutput(ctx context.Context, reservation *models.Reservation, instanceID string) (*clients.ConsoleOutput, *payloads.ResponseError) {
+	rDao := dao.GetReservationDao(ctx)
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return nil, payloads.NewClientError(ctx, err)
+	}
+
+	var output *clients.ConsoleOutput
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		awsReservation, err := rDao.GetAWSById(ctx, reservation.ID)
+		if err != nil {
+			return nil, payloads.NewDAOError(ctx, "get AWS reservation", err)
+		}
+		authentication, err := sourcesClient.GetAuthentication(ctx, awsReservation.SourceID)
+		if err != nil {
+			return nil, payloads.NewClientError(ctx, err)
+		}
+		ec2Client, err := clients.GetEC2Client(ctx, authentication, awsReservation.Detail.Region)
+		if err != nil {
+			return nil, payloads.NewAWSError(ctx, "unable to get AWS EC2 client", err)
+		}
+		output, err = ec2Client.GetConsoleOutput(ctx, instanceID)
+		if err != nil {
+			return nil, consoleOutputError(ctx, instanceID, err, payloads.NewAWSError)
+		}
+	case models.ProviderTypeAzure:
+		azureReservation, err := rDao.GetAzureById(ctx, reservation.ID)
+		if err != nil {
+			return nil, payloads.NewDAOError(ctx, "get Azure reservation", err)
+		}
+		authentication, err := sourcesClient.GetAuthentication(ctx, azureReservation.SourceID)
+		if err != nil {
+			return nil, payloads.NewClientError(ctx, err)
+		}
+		azureClient, err := clients.GetAzureClient(ctx, authentication)
+		if err != nil {
+			return nil, payloads.NewAzureError(ctx, "unable to get Azure client", err)
+		}
+		output, err = azureClient.GetConsoleOutput(ctx, instanceID)
+		if errors.Is(err, httpClients.BootDiagnosticsDisabledErr) {
+			return nil, payloads.NewInvalidRequestError(ctx, "boot diagnostics are not enabled on the instance", err)
+		} else if err != nil {
+			return nil, consoleOutputError(ctx, instanceID, err, payloads.NewAzureError)
+		}
+	case models.ProviderTypeGCP:
+		gcpReservation, err := rDao.GetGCPById(ctx, reservation.ID)
+		if err != nil {
+			return nil, payloads.NewDAOError(ctx, "get GCP reservation", err)
+		}
+		authentication, err := sourcesClient.GetAuthentication(ctx, gcpReservation.SourceID)
+		if err != nil {
+			return nil, payloads.NewClientError(ctx, err)
+		}
+		gcpClient, err := clients.GetGCPClient(ctx, authentication)
+		if err != nil {
+			return nil, payloads.NewGCPError(ctx, "unable to get GCP client", err)
+		}
+		output, err = gcpClient.GetConsoleOutput(ctx, instanceID, gcpReservation.Detail.Zone)
+		if err != nil {
+			return nil, consoleOutputError(ctx, instanceID, err, payloads.NewGCPError)
+		}
+	default:
+		return nil, payloads.NewInvalidRequestError(ctx, "provider does not support instance console output", ProviderTypeNotImplementedError)
+	}
+	return output, nil
+}
+
+// consoleOutputError returns not found error for instances which no longer exist and the
+// provider error otherwise.
+func consoleOutputError(ctx context.Context, instanceID string, err error, providerError func(context.Context, string, error) *payloads.ResponseError) *payloads.ResponseError {
+	if httpClients.IsProviderNotFound(err) {
+		return payloads.NewNotFoundError(ctx, fmt.Sprintf("instance %s no longer exists", instanceID), err)
+	}
+	return providerError(ctx, "unable to get instance console output", err)
+}
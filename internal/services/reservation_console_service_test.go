@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withInstanceParams(ctx context.Context, id int64, instanceID string) context.Context {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("ID", strconv.FormatInt(id, 10))
+	rctx.URLParams.Add("INSTANCE_ID", instanceID)
+	return context.WithValue(ctx, chi.RouteCtxKey, rctx)
+}
+
+func TestGetReservationInstanceConsole(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+
+	reservation := &models.AWSReservation{SourceID: "1", Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = identity2.AccountId(ctx)
+	reservation.Provider = models.ProviderTypeAWS
+	require.NoError(t, stubs.AddAWSReservation(ctx, reservation), "failed to create stub reservation")
+	require.NoError(t, dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{ReservationID: reservation.ID, InstanceID: "i-0a4caa2cf5b097ce1"}))
+
+	t.Run("console output", func(t *testing.T) {
+		rr := serveJSON(t, withInstanceParams(ctx, reservation.ID, "i-0a4caa2cf5b097ce1"), "GET", nil, services.GetReservationInstanceConsole)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.InstanceConsoleResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		assert.Equal(t, "i-0a4caa2cf5b097ce1", response.InstanceID)
+		assert.Equal(t, "i-0a4caa2cf5b097ce1 login:", response.Output)
+	})
+
+	t.Run("instance of another reservation", func(t *testing.T) {
+		rr := serveJSON(t, withInstanceParams(ctx, reservation.ID, "i-other"), "GET", nil, services.GetReservationInstanceConsole)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+
+	t.Run("unknown reservation", func(t *testing.T) {
+		rr := serveJSON(t, withInstanceParams(ctx, 999, "i-0a4caa2cf5b097ce1"), "GET", nil, services.GetReservationInstanceConsole)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+}
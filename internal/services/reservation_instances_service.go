@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+// GetReservationInstances returns the current state of instances of a reservation queried from
+// the provider, results are cached for a short time.
+func GetReservationInstances(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation instances")
+		return
+	}
+
+	instances, respErr := cachedReservationInstances(r.Context(), reservation)
+	if respErr != nil {
+		renderError(w, r, respErr)
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewListResponse(payloads.NewListInstanceStateResponse(instances))); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation instances", err))
+	}
+}
+
+// cachedReservationInstances returns the current state of instances of a reservation from the
+// cache, the provider is queried when the state is not cached.
+func cachedReservationInstances(ctx context.Context, reservation *models.Reservation) ([]*clients.InstanceDescription, *payloads.ResponseError) {
+	// reservation IDs are unique across accounts and the reservation was found in the account,
+	// cache errors are not fatal as the state is always available from the provider
+	logger := zerolog.Ctx(ctx)
+	key := strconv.FormatInt(reservation.ID, 10)
+	result := &clients.InstanceDescriptionList{}
+	err := cache.Find(ctx, key, result)
+	if err == nil {
+		return result.Instances, nil
+	}
+	if !errors.Is(err, cache.ErrNotFound) {
+		logger.Warn().Err(err).Msg("Unable to find cached reservation instances")
+	}
+
+	var respErr *payloads.ResponseError
+	result.Instances, respErr = describeReservationInstances(ctx, reservation)
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	if config.Application.InstanceStateTTL > 0 {
+		err = cache.SetExpires(ctx, key, result, config.Application.InstanceStateTTL)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Unable to cache reservation instances")
+		}
+	}
+	return result.Instances, nil
+}
+
+// describeReservationInstances queries the provider for all instances stored for the reservation,
+// instances which no longer exist have the InstanceStateNotFound state.
+//
+//nolint:exhaustive
+func describeReservationInstances(ctx context.Context, reservation *models.Reservation) ([]*clients.InstanceDescription, *payloads.ResponseError) {
+	rDao := dao.GetReservationDao(ctx)
+	instances, err := rDao.ListInstances(ctx, reservation.ID)
+	if err != nil {
+		return nil, payloads.NewDAOError(ctx, "list reservation instances", err)
+	}
+	if len(instances) == 0 {
+		return []*clients.InstanceDescription{}, nil
+	}
+	ids := make([]string, len(instances))
+	for i, instance := range instances {
+		ids[i] = instance.InstanceID
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return nil, payloads.NewClientError(ctx, err)
+	}
+
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		awsReservation, err := rDao.GetAWSById(ctx, reservation.ID)
+		if err != nil {
+			return nil, payloads.NewDAOError(ctx, "get AWS reservation", err)
+		}
+		authentication, err := sourcesClient.GetAuthentication(ctx, awsReservation.SourceID)
+		if err != nil {
+			return nil, payloads.NewClientError(ctx, err)
+		}
+		ec2Client, err := clients.GetEC2Client(ctx, authentication, awsReservation.Detail.Region)
+		if err != nil {
+			return nil, payloads.NewAWSError(ctx, "unable to get AWS EC2 client", err)
+		}
+		result, err := describeEC2Instances(ctx, ec2Client, ids)
+		if err != nil {
+			return nil, payloads.NewAWSError(ctx, "unable to describe AWS EC2 instances", err)
+		}
+		return result, nil
+	case models.ProviderTypeAzure:
+		azureReservation, err := rDao.GetAzureById(ctx, reservation.ID)
+		if err != nil {
+			return nil, payloads.NewDAOError(ctx, "get Azure reservation", err)
+		}
+		authentication, err := sourcesClient.GetAuthentication(ctx, azureReservation.SourceID)
+		if err != nil {
+			return nil, payloads.NewClientError(ctx, err)
+		}
+		azureClient, err := clients.GetAzureClient(ctx, authentication)
+		if err != nil {
+			return nil, payloads.NewAzureError(ctx, "unable to get Azure client", err)
+		}
+		result, err := describeEach(ids, func(id string) (*clients.InstanceDescription, error) {
+			return azureClient.GetInstanceDescriptionByID(ctx, id)
+		})
+		if err != nil {
+			return nil, payloads.NewAzureError(ctx, "unable to describe Azure instances", err)
+		}
+		return result, nil
+	case models.ProviderTypeGCP:
+		gcpReservation, err := rDao.GetGCPById(ctx, reservation.ID)
+		if err != nil {
+			return nil, payloads.NewDAOError(ctx, "get GCP reservation", err)
+		}
+		authentication, err := sourcesClient.GetAuthentication(ctx, gcpReservation.SourceID)
+		if err != nil {
+			return nil, payloads.NewClientError(ctx, err)
+		}
+		gcpClient, err := clients.GetGCPClient(ctx, authentication)
+		if err != nil {
+			return nil, payloads.NewGCPError(ctx, "unable to get GCP client", err)
+		}
+		result, err := describeEach(ids, func(id string) (*clients.InstanceDescription, error) {
+			return gcpClient.GetInstanceDescriptionByID(ctx, id, gcpReservation.Detail.Zone)
+		})
+		if err != nil {
+			return nil, payloads.NewGCPError(ctx, "unable to describe GCP instances", err)
+		}
+		return result, nil
+	default:
+		return nil, payloads.NewInvalidRequestError(ctx, "provider does not support live instance state", ProviderTypeNotImplementedError)
+	}
+}
+
+// describeEC2Instances describes all instances in a single call, when some of them no longer
+// exist the call fails and instances are described one by one.
+func describeEC2Instances(ctx context.Context, ec2Client clients.EC2, ids []string) ([]*clients.InstanceDescription, error) {
+	described, err := ec2Client.DescribeInstanceDetails(ctx, ids)
+	if httpClients.IsProviderNotFound(err) {
+		return describeEach(ids, func(id string) (*clients.InstanceDescription, error) {
+			single, err := ec2Client.DescribeInstanceDetails(ctx, []string{id})
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+			if len(single) == 0 {
+				return nil, httpClients.NoReservationErr
+			}
+			return single[0], nil
+		})
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to describe instances: %w", err)
+	}
+
+	byId := make(map[string]*clients.InstanceDescription, len(described))
+	for _, description := range described {
+		byId[description.ID] = description
+	}
+	result := make([]*clients.InstanceDescription, len(ids))
+	for i, id := range ids {
+		if description, ok := byId[id]; ok {
+			result[i] = description
+		} else {
+			result[i] = &clients.InstanceDescription{ID: id, State: clients.InstanceStateNotFound}
+		}
+	}
+	return result, nil
+}
+
+// describeEach describes instances one by one, instances not found by the provider have the
+// InstanceStateNotFound state.
+func describeEach(ids []string, describe func(id string) (*clients.InstanceDescription, error)) ([]*clients.InstanceDescription, error) {
+	result := make([]*clients.InstanceDescription, len(ids))
+	for i, id := range ids {
+		description, err := describe(id)
+		if httpClients.IsProviderNotFound(err) || errors.Is(err, httpClients.NoReservationErr) {
+			description = &clients.InstanceDescription{ID: id, State: clients.InstanceStateNotFound}
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to describe instance %s: %w", id, err)
+		}
+		result[i] = description
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReservationInstances(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+
+	reservation := &models.AWSReservation{SourceID: "1", Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = identity2.AccountId(ctx)
+	reservation.Provider = models.ProviderTypeAWS
+	require.NoError(t, stubs.AddAWSReservation(ctx, reservation), "failed to create stub reservation")
+
+	type listResponse struct {
+		Data []payloads.InstanceStateResponse `json:"data"`
+	}
+
+	t.Run("no instances", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "GET", nil, services.GetReservationInstances)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response listResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		assert.Empty(t, response.Data)
+	})
+
+	t.Run("live state", func(t *testing.T) {
+		rDao := dao.GetReservationDao(ctx)
+		require.NoError(t, rDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: reservation.ID, InstanceID: "i-0a4caa2cf5b097ce1"}))
+		require.NoError(t, rDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: reservation.ID, InstanceID: "i-terminated"}))
+
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "GET", nil, services.GetReservationInstances)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response listResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, "i-0a4caa2cf5b097ce1", response.Data[0].InstanceID)
+		assert.Equal(t, "running", response.Data[0].State)
+		assert.Equal(t, "54.11.88.17", response.Data[0].PublicIPv4)
+		assert.Equal(t, "i-terminated", response.Data[1].InstanceID)
+		assert.Equal(t, clients.InstanceStateNotFound, response.Data[1].State)
+	})
+
+	t.Run("unknown reservation", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(ctx, 999), "GET", nil, services.GetReservationInstances)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var ErrUnknownSSHFormat = errors.New("format must be json or ssh_config")
+
+// GetReservationSSH returns SSH commands for all instances of a reservation, or an ssh_config
+// snippet to download when the format parameter is ssh_config.
+func GetReservationSSH(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "ssh_config" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unknown format", ErrUnknownSSHFormat))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation ssh")
+		return
+	}
+
+	connections, err := sshConnections(r.Context(), reservation)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation ssh")
+		return
+	}
+
+	if format == "ssh_config" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="reservation-%d.ssh_config"`, id))
+		render.PlainText(w, r, sshConfig(reservation.ID, connections))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewListResponse(payloads.NewListSSHConnectionResponse(connections))); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation ssh", err))
+	}
+}
+
+// sshConnections returns connection information of instances, the user is determined by the
+// provider and the image distribution when the image is an image builder compose.
+//
+//nolint:exhaustive
+func sshConnections(ctx context.Context, reservation *models.Reservation) ([]*payloads.SSHConnectionResponse, error) {
+	rDao := dao.GetReservationDao(ctx)
+	var pubkeyId int64
+	var distribution string
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		awsReservation, err := rDao.GetAWSById(ctx, reservation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get AWS reservation: %w", err)
+		}
+		pubkeyId = awsReservation.PubkeyID
+		distribution = imageDistribution(ctx, awsReservation.ImageID)
+	case models.ProviderTypeAzure:
+		azureReservation, err := rDao.GetAzureById(ctx, reservation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Azure reservation: %w", err)
+		}
+		pubkeyId = azureReservation.PubkeyID
+	case models.ProviderTypeGCP:
+		gcpReservation, err := rDao.GetGCPById(ctx, reservation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get GCP reservation: %w", err)
+		}
+		pubkeyId = gcpReservation.PubkeyID
+	}
+
+	instances, err := rDao.ListInstances(ctx, reservation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list reservation instances: %w", err)
+	}
+
+	// the pubkey could have been deleted after the launch
+	var pubkey *models.Pubkey
+	if pubkeyId != 0 {
+		pubkey, err = dao.GetPubkeyDao(ctx).GetById(ctx, pubkeyId)
+		if errors.Is(err, dao.ErrNoRows) {
+			pubkey = nil
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to get pubkey: %w", err)
+		}
+	}
+
+	user := models.SSHUser(reservation.Provider, distribution)
+	result := make([]*payloads.SSHConnectionResponse, len(instances))
+	for i, instance := range instances {
+		result[i] = payloads.NewSSHConnectionResponse(instance, user, pubkey)
+	}
+	return result, nil
+}
+
+// imageDistribution returns distribution of an image builder compose from the cached image list,
+// empty string is returned for other images or when the compose is not found.
+func imageDistribution(ctx context.Context, imageId string) string {
+	if _, err := uuid.Parse(imageId); err != nil {
+		return ""
+	}
+
+	images, err := listImages(ctx)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to list images for SSH user, using provider default")
+		return ""
+	}
+	for _, image := range images {
+		if image.ID == imageId {
+			return image.Distribution
+		}
+	}
+	return ""
+}
+
+// sshConfig returns an ssh_config snippet with a host entry for every instance with a public
+// address, instances are aliased by their IDs.
+func sshConfig(reservationId int64, connections []*payloads.SSHConnectionResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Reservation %d\n", reservationId)
+	for _, c := range connections {
+		b.WriteString("\n")
+		if c.Host == "" {
+			fmt.Fprintf(&b, "# Instance %s has no public address\n", c.InstanceID)
+			continue
+		}
+		if c.PubkeyName != "" {
+			fmt.Fprintf(&b, "# Public key %q (SHA256:%s)\n", c.PubkeyName, strings.TrimRight(c.PubkeyFingerprint, "="))
+		}
+		fmt.Fprintf(&b, "Host %s\n", c.InstanceID)
+		fmt.Fprintf(&b, "    HostName %s\n", c.Host)
+		fmt.Fprintf(&b, "    User %s\n", c.User)
+	}
+	return b.String()
+}
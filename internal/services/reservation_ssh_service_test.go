@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReservationSSH(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	pk := factories.NewPubkeyRSA()
+	require.NoError(t, stubs.AddPubkey(ctx, pk), "failed to add stubbed key")
+
+	reservation := &models.AWSReservation{
+		SourceID: "1",
+		PubkeyID: pk.ID,
+		ImageID:  "ami-0c830793775595d4b",
+		Detail:   &models.AWSDetail{Region: "us-east-1"},
+	}
+	reservation.AccountID = identity2.AccountId(ctx)
+	reservation.Provider = models.ProviderTypeAWS
+	require.NoError(t, stubs.AddAWSReservation(ctx, reservation), "failed to create stub reservation")
+
+	rDao := dao.GetReservationDao(ctx)
+	require.NoError(t, rDao.CreateInstance(ctx, &models.ReservationInstance{
+		ReservationID: reservation.ID,
+		InstanceID:    "i-0a4caa2cf5b097ce1",
+		Detail:        models.ReservationInstanceDetail{PublicIPv4: "54.11.88.17"},
+	}))
+	require.NoError(t, rDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: reservation.ID, InstanceID: "i-private"}))
+
+	t.Run("commands", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "GET", nil, services.GetReservationSSH)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response struct {
+			Data []payloads.SSHConnectionResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, "ssh ec2-user@54.11.88.17", response.Data[0].Command)
+		assert.Equal(t, models.AWSUser, response.Data[0].User)
+		assert.Equal(t, pk.Name, response.Data[0].PubkeyName)
+		assert.Equal(t, "i-private", response.Data[1].InstanceID)
+		assert.Empty(t, response.Data[1].Command)
+	})
+
+	t.Run("ssh_config", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(withIDParam(ctx, reservation.ID), "GET", "/api/provisioning/reservations/1/ssh?format=ssh_config", nil)
+		require.NoError(t, err, "failed to create request")
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.GetReservationSSH).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+		assert.Contains(t, rr.Body.String(), "Host i-0a4caa2cf5b097ce1\n    HostName 54.11.88.17\n    User ec2-user\n")
+		assert.Contains(t, rr.Body.String(), "# Instance i-private has no public address\n")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(withIDParam(ctx, reservation.ID), "GET", "/api/provisioning/reservations/1/ssh?format=putty", nil)
+		require.NoError(t, err, "failed to create request")
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.GetReservationSSH).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+
+	t.Run("unknown reservation", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(ctx, 999), "GET", nil, services.GetReservationSSH)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+}
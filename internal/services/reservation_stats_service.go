@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+const (
+	// defaultStatsDays is the default number of days reservation statistics are computed for.
+	defaultStatsDays = 28
+
+	// maxStatsDays is the maximum number of days reservation statistics can be computed for.
+	maxStatsDays = 365
+)
+
+var ErrInvalidStatsDays = errors.New("days must be between 1 and 365")
+
+// GetReservationStats returns aggregate statistics of reservations of the account created
+// within the last days (including today). Days defaults to 28.
+func GetReservationStats(w http.ResponseWriter, r *http.Request) {
+	days, err := ParseOptionalInt64(r.URL.Query().Get("days"))
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse days parameter", err))
+		return
+	}
+	if r.URL.Query().Get("days") == "" {
+		days = defaultStatsDays
+	}
+	if days < 1 || days > maxStatsDays {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid days parameter", ErrInvalidStatsDays))
+		return
+	}
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -int(days-1))
+
+	rDao := dao.GetReservationDao(r.Context())
+	providers, err := rDao.StatsByProvider(r.Context(), since)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "reservation stats by provider", err))
+		return
+	}
+	daily, err := rDao.DailyStats(r.Context(), since)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "daily reservation stats", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewReservationStatsResponse(int(days), providers, daily)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation stats", err))
+		return
+	}
+}
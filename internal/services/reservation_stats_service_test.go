@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReservationStatsHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	rDao := dao.GetReservationDao(ctx)
+	for _, success := range []sql.NullBool{{Bool: true, Valid: true}, {Bool: false, Valid: true}, {}} {
+		res := &models.AWSReservation{Reservation: models.Reservation{
+			AccountID: 1,
+			Provider:  models.ProviderTypeAWS,
+			CreatedAt: time.Now(),
+			Success:   success,
+		}}
+		require.NoError(t, rDao.CreateAWS(ctx, res), "failed to add stubbed reservation")
+	}
+	old := &models.GCPReservation{Reservation: models.Reservation{
+		AccountID: 1,
+		Provider:  models.ProviderTypeGCP,
+		CreatedAt: time.Now().AddDate(0, 0, -30),
+	}}
+	require.NoError(t, rDao.CreateGCP(ctx, old), "failed to add stubbed reservation")
+
+	getStats := func(t *testing.T, query string) (int, *payloads.ReservationStatsResponse) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/reservations/stats"+query, nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(GetReservationStats)
+		handler.ServeHTTP(rr, req)
+
+		var result payloads.ReservationStatsResponse
+		if rr.Code == http.StatusOK {
+			err = json.NewDecoder(rr.Body).Decode(&result)
+			require.NoError(t, err, "failed to decode response body")
+		}
+		return rr.Code, &result
+	}
+
+	t.Run("default days", func(t *testing.T) {
+		code, stats := getStats(t, "")
+		require.Equal(t, http.StatusOK, code, "Handler returned wrong status code")
+		assert.Equal(t, 28, stats.Days)
+		require.Len(t, stats.Providers, 1)
+		assert.Equal(t, "aws", stats.Providers[0].Provider)
+		assert.Equal(t, int64(3), stats.Providers[0].Total)
+		assert.Equal(t, int64(1), stats.Providers[0].Pending)
+		assert.InDelta(t, 0.5, stats.Providers[0].SuccessRate, 0.001)
+		assert.Len(t, stats.Daily, 3)
+	})
+
+	t.Run("longer period", func(t *testing.T) {
+		code, stats := getStats(t, "?days=60")
+		require.Equal(t, http.StatusOK, code, "Handler returned wrong status code")
+		assert.Len(t, stats.Providers, 2)
+	})
+
+	t.Run("invalid days", func(t *testing.T) {
+		code, _ := getStats(t, "?days=0")
+		assert.Equal(t, http.StatusBadRequest, code, "Handler returned wrong status code")
+	})
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+var (
+	ErrMissingTemplateName = errors.New("reservation template name missing")
+	ErrMissingTemplateID   = errors.New("reservation template id missing")
+)
+
+func CreateReservationTemplate(w http.ResponseWriter, r *http.Request) {
+	payload := &payloads.ReservationTemplateRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "create reservation template", err))
+		return
+	}
+
+	template := payload.NewModel()
+	if rErr := validateReservationTemplate(r, template); rErr != nil {
+		renderError(w, r, rErr)
+		return
+	}
+
+	err := dao.GetReservationTemplateDao(r.Context()).Create(r.Context(), template)
+	if err != nil {
+		renderTemplateDAOError(w, r, err, "create reservation template")
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewReservationTemplateResponse(template)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation template", err))
+	}
+}
+
+func ListReservationTemplates(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	templateDao := dao.GetReservationTemplateDao(r.Context())
+
+	templates, err := templateDao.List(r.Context(), limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservation templates", err))
+		return
+	}
+	total, err := templateDao.Count(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count reservation templates", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewReservationTemplateListResponse(templates), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation templates list", err))
+		return
+	}
+}
+
+func GetReservationTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	template, err := dao.GetReservationTemplateDao(r.Context()).GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get reservation template with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewReservationTemplateResponse(template)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation template", err))
+	}
+}
+
+// UpdateReservationTemplate replaces all fields of an existing template.
+func UpdateReservationTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	payload := &payloads.ReservationTemplateRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "update reservation template", err))
+		return
+	}
+
+	templateDao := dao.GetReservationTemplateDao(r.Context())
+	existing, err := templateDao.GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get reservation template with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	template := payload.NewModel()
+	template.ID = existing.ID
+	template.AccountID = existing.AccountID
+	template.CreatedAt = existing.CreatedAt
+	if rErr := validateReservationTemplate(r, template); rErr != nil {
+		renderError(w, r, rErr)
+		return
+	}
+
+	err = templateDao.Update(r.Context(), template)
+	if err != nil {
+		renderTemplateDAOError(w, r, err, "update reservation template")
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewReservationTemplateResponse(template)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation template", err))
+	}
+}
+
+func DeleteReservationTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	templateDao := dao.GetReservationTemplateDao(r.Context())
+	template, err := templateDao.GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get reservation template with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	err = templateDao.Delete(r.Context(), template.ID)
+	if err != nil {
+		message := fmt.Sprintf("reservation template with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// CreateTemplateReservation creates a reservation from a reservation template. Fields of the
+// request override template fields, the merged payload is then processed exactly like a
+// provider-specific reservation request.
+func CreateTemplateReservation(w http.ResponseWriter, r *http.Request) {
+	if !featureflags.Enabled(r.Context(), featureflags.Launch) {
+		writeUnauthorized(w, r)
+		return
+	}
+
+	payload := &payloads.TemplateReservationRequestPayload{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "template reservation", err))
+		return
+	}
+
+	if payload.TemplateID == 0 {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), ErrMissingTemplateID.Error(), ErrMissingTemplateID))
+		return
+	}
+
+	template, err := dao.GetReservationTemplateDao(r.Context()).GetById(r.Context(), payload.TemplateID)
+	if err != nil {
+		message := fmt.Sprintf("get reservation template with id %d", payload.TemplateID)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	switch template.Provider {
+	case models.ProviderTypeNoop:
+		CreateNoopReservation(w, r)
+	case models.ProviderTypeAWS:
+		createAWSReservation(w, r, payload.NewAWSReservationRequest(template))
+	case models.ProviderTypeAzure:
+		if featureflags.Enabled(r.Context(), featureflags.Azure) {
+			createAzureReservation(w, r, payload.NewAzureReservationRequest(template))
+		} else {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "azure reservation is not implemented", ProviderTypeNotImplementedError))
+		}
+	case models.ProviderTypeGCP:
+		if featureflags.Enabled(r.Context(), featureflags.GCP) {
+			createGCPReservation(w, r, payload.NewGCPReservationRequest(template))
+		} else {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "gcp reservation is not enabled", ProviderTypeNotImplementedError))
+		}
+	case models.ProviderTypeUnknown:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", UnknownProviderTypeError))
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", UnknownProviderTypeError))
+	}
+}
+
+// validateReservationTemplate checks required fields and that the pubkey belongs to the account.
+func validateReservationTemplate(r *http.Request, template *models.ReservationTemplate) *payloads.ResponseError {
+	if template.Name == "" {
+		return payloads.NewInvalidRequestError(r.Context(), ErrMissingTemplateName.Error(), ErrMissingTemplateName)
+	}
+
+	if template.Provider == models.ProviderTypeUnknown {
+		return payloads.NewInvalidRequestError(r.Context(), "provider is not supported", UnknownProviderTypeError)
+	}
+
+	if template.PubkeyID != nil {
+		_, err := dao.GetPubkeyDao(r.Context()).GetById(r.Context(), *template.PubkeyID)
+		if err != nil {
+			message := fmt.Sprintf("get pubkey with id %d", *template.PubkeyID)
+			if errors.Is(err, dao.ErrNoRows) {
+				return payloads.NewNotFoundError(r.Context(), message, err)
+			}
+			return payloads.NewDAOError(r.Context(), message, err)
+		}
+	}
+
+	return nil
+}
+
+func renderTemplateDAOError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if db.IsPostgresError(err, db.UniqueConstraintErrorCode) != nil {
+		renderError(w, r, payloads.NewResponseError(r.Context(), http.StatusUnprocessableEntity, "reservation template with such name already exists for this account", err))
+	} else {
+		renderError(w, r, payloads.NewDAOError(r.Context(), message, err))
+	}
+}
@@ -0,0 +1,174 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareTemplateContext(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithAccountSettingsDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = stubs.WithReservationTemplateDao(ctx)
+	return ctx
+}
+
+func withIDParam(ctx context.Context, id int64) context.Context {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("ID", strconv.FormatInt(id, 10))
+	return context.WithValue(ctx, chi.RouteCtxKey, rctx)
+}
+
+func serveJSON(t *testing.T, ctx context.Context, method string, values map[string]interface{}, handlerFunc http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	jsonData, err := json.Marshal(values)
+	require.NoError(t, err, "unable to marshal values to json")
+
+	req, err := http.NewRequestWithContext(ctx, method, "/api/provisioning/reservation_templates", bytes.NewBuffer(jsonData))
+	require.NoError(t, err, "failed to create request")
+	req.Header.Add("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handlerFunc.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCreateReservationTemplateHandler(t *testing.T) {
+	ctx := prepareTemplateContext(t)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	t.Run("creates template", func(t *testing.T) {
+		rr := serveJSON(t, ctx, "POST", map[string]interface{}{
+			"name":          "small",
+			"provider":      "aws",
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"instance_type": "t1.micro",
+			"region":        "us-east-1",
+			"pubkey_id":     pk.ID,
+			"tags":          map[string]string{"team": "qe"},
+		}, services.CreateReservationTemplate)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.ReservationTemplateResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, "aws", result.Provider)
+		assert.Equal(t, pk.ID, *result.PubkeyID)
+		assert.Equal(t, map[string]string{"team": "qe"}, result.Tags)
+		assert.Equal(t, 1, stubs.ReservationTemplateStubCount(ctx))
+	})
+
+	t.Run("rejects unknown provider", func(t *testing.T) {
+		rr := serveJSON(t, ctx, "POST", map[string]interface{}{
+			"name":     "unknown",
+			"provider": "oracle",
+		}, services.CreateReservationTemplate)
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("rejects missing pubkey", func(t *testing.T) {
+		rr := serveJSON(t, ctx, "POST", map[string]interface{}{
+			"name":      "missing",
+			"provider":  "gcp",
+			"pubkey_id": 999,
+		}, services.CreateReservationTemplate)
+		require.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestUpdateAndDeleteReservationTemplateHandler(t *testing.T) {
+	ctx := prepareTemplateContext(t)
+	template := &models.ReservationTemplate{Name: "small", Provider: models.ProviderTypeGCP, Region: "us-east4-a"}
+	err := stubs.AddReservationTemplate(ctx, template)
+	require.NoError(t, err, "failed to add stubbed template")
+	ctx = withIDParam(ctx, template.ID)
+
+	rr := serveJSON(t, ctx, "PUT", map[string]interface{}{
+		"name":          "large",
+		"provider":      "gcp",
+		"instance_type": "n1-standard-8",
+	}, services.UpdateReservationTemplate)
+	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+	var result payloads.ReservationTemplateResponse
+	err = json.NewDecoder(rr.Body).Decode(&result)
+	require.NoError(t, err, "failed to decode response body")
+	assert.Equal(t, template.ID, result.ID)
+	assert.Equal(t, "large", result.Name)
+	assert.Equal(t, "n1-standard-8", result.InstanceType)
+	assert.Empty(t, result.Region)
+
+	rr = serveJSON(t, ctx, "DELETE", nil, services.DeleteReservationTemplate)
+	require.Equal(t, http.StatusNoContent, rr.Code, "Handler returned wrong status code")
+	assert.Equal(t, 0, stubs.ReservationTemplateStubCount(ctx))
+
+	rr = serveJSON(t, ctx, "GET", nil, services.GetReservationTemplate)
+	require.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+}
+
+func TestCreateTemplateReservationHandler(t *testing.T) {
+	ctx := prepareTemplateContext(t)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithImageBuilderClient(ctx)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	template := &models.ReservationTemplate{
+		Name:         "small",
+		Provider:     models.ProviderTypeAWS,
+		SourceID:     "1",
+		ImageID:      "2bc640f6-927a-404a-9594-5b2da7e06608",
+		InstanceType: "t1.micro",
+		PubkeyID:     &pk.ID,
+	}
+	err = stubs.AddReservationTemplate(ctx, template)
+	require.NoError(t, err, "failed to add stubbed template")
+
+	t.Run("overrides template fields", func(t *testing.T) {
+		rr := serveJSON(t, ctx, "POST", map[string]interface{}{
+			"template_id": template.ID,
+			"region":      "blank",
+		}, services.CreateTemplateReservation)
+
+		assert.Contains(t, rr.Body.String(), "Unsupported region")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("fails with unknown template", func(t *testing.T) {
+		rr := serveJSON(t, ctx, "POST", map[string]interface{}{
+			"template_id": 999,
+		}, services.CreateTemplateReservation)
+		require.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("fails without template", func(t *testing.T) {
+		rr := serveJSON(t, ctx, "POST", map[string]interface{}{}, services.CreateTemplateReservation)
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
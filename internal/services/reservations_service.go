@@ -1,12 +1,18 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/featureflags"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/go-chi/chi/v5"
@@ -19,14 +25,76 @@ var (
 	ProviderTypeNotImplementedError = errors.New("provider type not yet implemented")
 	UnknownInstanceTypeNameError    = errors.New("unknown instance type")
 	ArchitectureMismatch            = errors.New("instance type and image architecture mismatch")
+	GPURequiredError                = errors.New("instance type has no GPU")
 	BothTypeAndTemplateMissingError = errors.New("instance type or launch template not set")
 	UnsupportedRegionError          = errors.New("unknown region/location/zone")
+	ProviderNotAllowedError         = errors.New("provider not allowed by account settings")
+	ReservationFinishedError        = errors.New("reservation already finished")
+	ReservationRunningError         = errors.New("reservation still running")
+	IMDSv1NotAllowedError           = errors.New("IMDSv1 not allowed for the organization")
+	ResourceGroupNotFoundError      = errors.New("resource group not found")
+	VMSizeRestrictedError           = errors.New("VM size restricted in the location")
+	QuotaExceededError              = errors.New("not enough vCPU quota")
+	SubnetworkRegionMismatchError   = errors.New("subnetwork not in the region of the zone")
+	SpendCapExceededError           = errors.New("estimated hourly spend exceeds account spend cap")
+	DryRunImageCloneError           = errors.New("dry run not supported for images cloned to the region")
 )
 
+// validatePubkey checks the pubkey can be used for a new reservation: key type must be supported
+// by the provider and the key must not be expired when the organization enforces pubkey expiry.
+func validatePubkey(ctx context.Context, pk *models.Pubkey, provider models.ProviderType) *payloads.ResponseError {
+	if err := pk.CompatibleWith(provider); err != nil {
+		return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("pubkey %d of type %s is not supported", pk.ID, pk.Type), err)
+	}
+
+	if pk.Expired(time.Now()) && config.PubkeyExpiryEnforced(identity.Identity(ctx).Identity.OrgID) {
+		return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("pubkey %d expired at %s", pk.ID, pk.ExpiresAt.Format(time.RFC3339)), models.ErrPubkeyExpired)
+	}
+
+	return nil
+}
+
+// imageArchitectureGetter is implemented by all cloud provider clients.
+type imageArchitectureGetter interface {
+	GetImageArchitecture(ctx context.Context, imageID string) (clients.ArchitectureType, error)
+}
+
+// validateImage checks a direct provider image ID (not an image builder compose) exists in the
+// cloud provider account and matches architecture of the instance type. Instance type can be
+// nil when it is not known (launch template), images of unknown architecture are not compared.
+func validateImage(ctx context.Context, client imageArchitectureGetter, imageID string, it *clients.InstanceType) *payloads.ResponseError {
+	arch, err := client.GetImageArchitecture(ctx, imageID)
+	if err != nil {
+		if errors.Is(err, clients.NotFoundErr) {
+			return payloads.NewInvalidRequestError(ctx, fmt.Sprintf("image %s not found", imageID), err)
+		}
+		return payloads.NewClientError(ctx, err)
+	}
+
+	if it != nil && arch != "" && it.Architecture != arch {
+		return payloads.NewWrongArchitectureUserError(ctx, ArchitectureMismatch)
+	}
+	return nil
+}
+
+// renderDryRun renders result of a reservation dry run. Launch rejected by the cloud provider is
+// a result of the dry run, only failures to perform it are errors.
+func renderDryRun(w http.ResponseWriter, r *http.Request, provider models.ProviderType, result *clients.DryRunResult, err error) {
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	if renderErr := render.Render(w, r, payloads.NewDryRunResponse(provider, result)); renderErr != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render dry run", renderErr))
+	}
+}
+
 // CreateReservation dispatches requests to type provider specific handlers
 func CreateReservation(w http.ResponseWriter, r *http.Request) {
-	if !config.LaunchEnabled(r.Context()) {
+	if !featureflags.Enabled(r.Context(), featureflags.Launch) {
 		writeUnauthorized(w, r)
+		return
 	}
 
 	pType := models.ProviderTypeFromString(chi.URLParam(r, "TYPE"))
@@ -36,13 +104,17 @@ func CreateReservation(w http.ResponseWriter, r *http.Request) {
 	case models.ProviderTypeAWS:
 		CreateAWSReservation(w, r)
 	case models.ProviderTypeAzure:
-		if config.FeatureEnabled(r.Context(), "azure") {
+		if featureflags.Enabled(r.Context(), featureflags.Azure) {
 			CreateAzureReservation(w, r)
 		} else {
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "azure reservation is not implemented", ProviderTypeNotImplementedError))
 		}
 	case models.ProviderTypeGCP:
-		CreateGCPReservation(w, r)
+		if featureflags.Enabled(r.Context(), featureflags.GCP) {
+			CreateGCPReservation(w, r)
+		} else {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "gcp reservation is not enabled", ProviderTypeNotImplementedError))
+		}
 	case models.ProviderTypeUnknown:
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", UnknownProviderTypeError))
 	default:
@@ -51,15 +123,27 @@ func CreateReservation(w http.ResponseWriter, r *http.Request) {
 }
 
 func ListReservations(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
 	rDao := dao.GetReservationDao(r.Context())
 
-	reservations, err := rDao.List(r.Context(), 100, 0)
+	reservations, err := rDao.List(r.Context(), limit, offset)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservations", err))
 		return
 	}
+	total, err := rDao.Count(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count reservations", err))
+		return
+	}
 
-	if err := render.RenderList(w, r, payloads.NewReservationListResponse(reservations)); err != nil {
+	response := payloads.NewPageResponse(r, payloads.NewReservationListResponse(reservations), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservations list", err))
 		return
 	}
@@ -126,7 +210,11 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if err := render.Render(w, r, payloads.NewAzureReservationResponse(reservationAzure, instances)); err != nil {
+		response := payloads.NewAzureReservationResponse(reservationAzure, instances)
+		if reservationAzure.Detail.Priority == models.AzurePrioritySpot && reservation.Success.Bool && len(instances) > 0 {
+			response = payloads.NewAzureSpotReservationResponse(reservationAzure, instances, evictedInstances(r.Context(), reservation))
+		}
+		if err := render.Render(w, r, response); err != nil {
 			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
 		}
 	case models.ProviderTypeGCP:
@@ -151,3 +239,113 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ProviderTypeNotImplementedError))
 	}
 }
+
+// CancelReservation requests cancellation of an unfinished reservation. Launch jobs check
+// the flag before each step and during long cloud calls, instances launched before the job
+// was aborted are not terminated.
+func CancelReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation to cancel")
+		return
+	}
+	if !reservation.ModifiableBy(identity.Owner(r.Context())) {
+		renderError(w, r, payloads.NewNotOwnerError(r.Context(), fmt.Sprintf("reservation %d", id), NotOwnerError))
+		return
+	}
+
+	if reservation.FinishedAt.Valid {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "reservation cannot be cancelled", ReservationFinishedError))
+		return
+	}
+
+	err = rDao.RequestCancel(r.Context(), id)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		// finished in the meantime
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "reservation cannot be cancelled", ReservationFinishedError))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "request reservation cancel", err))
+		return
+	}
+
+	reservation.CancelRequested = true
+	if err := render.Render(w, r, payloads.NewReservationResponse(reservation)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
+	}
+}
+
+// DeleteReservation marks a finished reservation as deleted, it can be restored until it is
+// purged after the retention period. Running reservations must be cancelled first.
+func DeleteReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation to delete")
+		return
+	}
+	if !reservation.ModifiableBy(identity.Owner(r.Context())) {
+		renderError(w, r, payloads.NewNotOwnerError(r.Context(), fmt.Sprintf("reservation %d", id), NotOwnerError))
+		return
+	}
+
+	if !reservation.FinishedAt.Valid {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "reservation cannot be deleted", ReservationRunningError))
+		return
+	}
+
+	err = rDao.SoftDelete(r.Context(), id)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		// deleted in the meantime
+		renderError(w, r, payloads.NewNotFoundError(r.Context(), "delete reservation", err))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "delete reservation", err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// RestoreReservation clears the deleted mark of a reservation which was not purged yet.
+func RestoreReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	err = rDao.Restore(r.Context(), id)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		renderError(w, r, payloads.NewNotFoundError(r.Context(), "restore deleted reservation", err))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "restore reservation", err))
+		return
+	}
+
+	// replica might not contain the change yet
+	reservation, err := rDao.GetById(db.WithPrimary(r.Context()), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get restored reservation")
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewReservationResponse(reservation)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
+	}
+}
@@ -2,11 +2,16 @@ package services_test
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -71,4 +76,142 @@ func TestGetReservationDetail(t *testing.T) {
 
 		assert.Equal(t, int(models.ProviderTypeAWS), response.Provider, "expected provider to be AWS in parsed json")
 	})
+
+	t.Run("Azure spot reservation", func(t *testing.T) {
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = stubs.WithReservationDao(ctx)
+		ctx = clientStubs.WithSourcesClient(ctx)
+		ctx = clientStubs.WithAzureClient(ctx)
+		source, err := clientStubs.AddSource(ctx, models.ProviderTypeAzure)
+		require.NoError(t, err, "failed to generate Azure source")
+
+		azureClient, err := clients.GetAzureClient(ctx, clients.NewAuthentication("subUUID", models.ProviderTypeAzure))
+		require.NoError(t, err, "failed to get Azure client")
+		vms, err := azureClient.CreateVMs(ctx, clients.AzureInstanceParams{Location: "eastus"}, 1, "redhat-vm")
+		require.NoError(t, err, "failed to create stubbed VM")
+
+		reservation := &models.AzureReservation{
+			SourceID: source.ID,
+			Detail: &models.AzureDetail{
+				Location:       "eastus_1",
+				InstanceSize:   "Basic_A0",
+				Amount:         1,
+				Priority:       models.AzurePrioritySpot,
+				EvictionPolicy: models.AzureEvictionDeallocate,
+			},
+		}
+		reservation.AccountID = identity2.AccountId(ctx)
+		reservation.Provider = models.ProviderTypeAzure
+		reservation.Success = sql.NullBool{Bool: true, Valid: true}
+		rDao := dao.GetReservationDao(ctx)
+		require.NoError(t, rDao.CreateAzure(ctx, reservation), "failed to create stub reservation")
+		require.NoError(t, rDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: reservation.ID, InstanceID: vms[0].ID}))
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("TYPE", "azure")
+		rctx.URLParams.Add("ID", strconv.FormatInt(reservation.ID, 10))
+		rr := serveJSON(t, context.WithValue(ctx, chi.RouteCtxKey, rctx), "GET", nil, services.GetReservationDetail)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.AzureReservationResponsePayload
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		assert.Equal(t, models.AzurePrioritySpot, response.Priority)
+		assert.Equal(t, models.AzureEvictionStatusNone, response.EvictionStatus)
+		assert.Empty(t, response.EvictedInstances)
+	})
+}
+
+func TestCancelReservation(t *testing.T) {
+	prepare := func(t *testing.T) (context.Context, *models.AWSReservation) {
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = stubs.WithReservationDao(ctx)
+
+		reservation := &models.AWSReservation{Detail: &models.AWSDetail{Region: "us-east-1"}}
+		reservation.AccountID = identity2.AccountId(ctx)
+		reservation.Provider = models.ProviderTypeAWS
+		err := stubs.AddAWSReservation(ctx, reservation)
+		require.NoError(t, err, "failed to create stub reservation")
+		return ctx, reservation
+	}
+
+	t.Run("running reservation", func(t *testing.T) {
+		ctx, reservation := prepare(t)
+
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "POST", nil, services.CancelReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationResponsePayload
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.True(t, response.CancelRequested)
+		assert.True(t, reservation.CancelRequested)
+	})
+
+	t.Run("finished reservation", func(t *testing.T) {
+		ctx, reservation := prepare(t)
+		reservation.FinishedAt.Valid = true
+
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "POST", nil, services.CancelReservation)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+		assert.False(t, reservation.CancelRequested)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx, _ := prepare(t)
+
+		rr := serveJSON(t, withIDParam(ctx, 99), "POST", nil, services.CancelReservation)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+}
+
+func TestDeleteReservation(t *testing.T) {
+	prepare := func(t *testing.T) (context.Context, *models.AWSReservation) {
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = stubs.WithReservationDao(ctx)
+
+		reservation := &models.AWSReservation{Detail: &models.AWSDetail{Region: "us-east-1"}}
+		reservation.AccountID = identity2.AccountId(ctx)
+		reservation.Provider = models.ProviderTypeAWS
+		err := stubs.AddAWSReservation(ctx, reservation)
+		require.NoError(t, err, "failed to create stub reservation")
+		return ctx, reservation
+	}
+
+	t.Run("finished reservation", func(t *testing.T) {
+		ctx, reservation := prepare(t)
+		reservation.FinishedAt.Valid = true
+
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "DELETE", nil, services.DeleteReservation)
+		require.Equal(t, http.StatusNoContent, rr.Code, "Wrong status code")
+		assert.NotNil(t, reservation.DeletedAt)
+
+		rr = serveJSON(t, withIDParam(ctx, reservation.ID), "GET", nil, services.GetReservationDetail)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+
+	t.Run("running reservation", func(t *testing.T) {
+		ctx, reservation := prepare(t)
+
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "DELETE", nil, services.DeleteReservation)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+		assert.Nil(t, reservation.DeletedAt)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		ctx, reservation := prepare(t)
+		reservation.FinishedAt.Valid = true
+
+		rr := serveJSON(t, withIDParam(ctx, reservation.ID), "DELETE", nil, services.DeleteReservation)
+		require.Equal(t, http.StatusNoContent, rr.Code, "Wrong status code")
+
+		rr = serveJSON(t, withIDParam(ctx, reservation.ID), "POST", nil, services.RestoreReservation)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+		assert.Nil(t, reservation.DeletedAt)
+
+		rr = serveJSON(t, withIDParam(ctx, reservation.ID), "POST", nil, services.RestoreReservation)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
 }
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+// NotOwnerError is returned for modifications of reservations and pubkeys shared by another user,
+// shared resources are read-only for other users of the organization.
+var NotOwnerError = errors.New("owned by another user")
+
+// UpdateReservationSharing shares a reservation with all users of the organization or makes it
+// visible only to its owner again.
+func UpdateReservationSharing(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	payload := &payloads.SharingRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "update reservation sharing", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation to share")
+		return
+	}
+	if !reservation.ModifiableBy(identity.Owner(r.Context())) {
+		renderError(w, r, payloads.NewNotOwnerError(r.Context(), fmt.Sprintf("reservation %d", id), NotOwnerError))
+		return
+	}
+
+	err = rDao.UpdateShared(r.Context(), id, *payload.Shared)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		// deleted in the meantime
+		renderError(w, r, payloads.NewNotFoundError(r.Context(), "share reservation", err))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "share reservation", err))
+		return
+	}
+
+	reservation.Shared = *payload.Shared
+	if err := render.Render(w, r, payloads.NewReservationResponse(reservation)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
+	}
+}
+
+// UpdatePubkeySharing shares a pubkey with all users of the organization or makes it visible
+// only to its owner again. Other users can launch reservations with shared pubkeys.
+func UpdatePubkeySharing(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	payload := &payloads.SharingRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "update pubkey sharing", err))
+		return
+	}
+
+	pubkeyDao := dao.GetPubkeyDao(r.Context())
+	pubkey, err := pubkeyDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("get pubkey with id %d", id))
+		return
+	}
+	if !pubkey.ModifiableBy(identity.Owner(r.Context())) {
+		renderError(w, r, payloads.NewNotOwnerError(r.Context(), fmt.Sprintf("pubkey %d", id), NotOwnerError))
+		return
+	}
+
+	err = pubkeyDao.UpdateShared(r.Context(), id, *payload.Shared)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		// deleted in the meantime
+		renderError(w, r, payloads.NewNotFoundError(r.Context(), "share pubkey", err))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "share pubkey", err))
+		return
+	}
+
+	pubkey.Shared = *payload.Shared
+	if err := render.Render(w, r, payloads.NewPubkeyResponse(pubkey)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
+	}
+}
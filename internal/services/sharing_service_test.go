@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateReservationSharing(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	alice := identity.WithUser(t, ctx, "alice")
+	bob := identity.WithUser(t, ctx, "bob")
+
+	reservation := &models.AWSReservation{SourceID: "1", Detail: &models.AWSDetail{Region: "us-east-1"}}
+	reservation.AccountID = identity2.AccountId(ctx)
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.Owner = "alice"
+	require.NoError(t, stubs.AddAWSReservation(ctx, reservation), "failed to create stub reservation")
+
+	t.Run("not visible to other users", func(t *testing.T) {
+		_, err := dao.GetReservationDao(bob).GetById(bob, reservation.ID)
+		assert.ErrorIs(t, err, dao.ErrNoRows)
+	})
+
+	t.Run("shared by the owner", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(alice, reservation.ID), "PUT", map[string]any{"shared": true}, services.UpdateReservationSharing)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationResponsePayload
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response), "failed to decode response body")
+		assert.True(t, response.Shared)
+		assert.Equal(t, "alice", response.Owner)
+	})
+
+	t.Run("read-only for other users", func(t *testing.T) {
+		shared, err := dao.GetReservationDao(bob).GetById(bob, reservation.ID)
+		require.NoError(t, err)
+		assert.True(t, shared.Shared)
+
+		rr := serveJSON(t, withIDParam(bob, reservation.ID), "PUT", map[string]any{"shared": false}, services.UpdateReservationSharing)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "Wrong status code")
+
+		rr = serveJSON(t, withIDParam(bob, reservation.ID), "POST", nil, services.CancelReservation)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "Wrong status code")
+	})
+
+	t.Run("missing flag", func(t *testing.T) {
+		rr := serveJSON(t, withIDParam(alice, reservation.ID), "PUT", map[string]any{}, services.UpdateReservationSharing)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+}
+
+func TestUpdatePubkeySharing(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	alice := identity.WithUser(t, ctx, "alice")
+	bob := identity.WithUser(t, ctx, "bob")
+
+	pubkey := &models.Pubkey{
+		Name: factories.SeqNameWithPrefix("pubkey"),
+		Body: factories.GenerateRSAPubKey(t),
+	}
+	require.NoError(t, stubs.AddPubkey(alice, pubkey), "failed to add stubbed key")
+	require.Equal(t, "alice", pubkey.Owner)
+
+	count, err := dao.GetPubkeyDao(bob).Count(bob)
+	require.NoError(t, err)
+	assert.Zero(t, count, "private pubkey listed to another user")
+
+	rr := serveJSON(t, withIDParam(bob, pubkey.ID), "PUT", map[string]any{"shared": true}, services.UpdatePubkeySharing)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+
+	rr = serveJSON(t, withIDParam(alice, pubkey.ID), "PUT", map[string]any{"shared": true}, services.UpdatePubkeySharing)
+	require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+	count, err = dao.GetPubkeyDao(bob).Count(bob)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "shared pubkey not listed to another user")
+
+	rr = serveJSON(t, withIDParam(bob, pubkey.ID), "PUT", map[string]any{"shared": false}, services.UpdatePubkeySharing)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "Wrong status code")
+}
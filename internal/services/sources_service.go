@@ -8,6 +8,7 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/go-chi/chi/v5"
@@ -41,7 +42,7 @@ func ListSources(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := render.RenderList(w, r, payloads.NewListSourcesResponse(sourcesList)); err != nil {
+	if err := render.Render(w, r, payloads.NewListResponse(payloads.NewListSourcesResponse(sourcesList))); err != nil {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render sources list", err))
 		return
 	}
@@ -127,19 +128,14 @@ func getAWSAccountDetails(ctx context.Context, sourceId string, authentication *
 
 	err := cache.Find(ctx, sourceId, result)
 	if errors.Is(err, cache.ErrNotFound) {
-		ec2Client, clientErr := clients.GetEC2Client(ctx, authentication, "")
-		if clientErr != nil {
-			return nil, fmt.Errorf("unable to initialize AWS client: %w", clientErr)
-		}
-
-		result.AccountID, clientErr = ec2Client.GetAccountId(ctx)
-		if clientErr != nil {
-			return nil, fmt.Errorf("unable to get account id: %w", clientErr)
+		result.AccountID, err = resolveAWSAccountId(ctx, sourceId, authentication)
+		if err != nil {
+			return nil, err
 		}
 
-		clientErr = cache.SetForever(ctx, sourceId, result)
-		if clientErr != nil {
-			return nil, fmt.Errorf("cache set error: %w", clientErr)
+		err = cache.SetForever(ctx, sourceId, result)
+		if err != nil {
+			return nil, fmt.Errorf("cache set error: %w", err)
 		}
 	} else if err != nil {
 		return nil, fmt.Errorf("cache find error: %w", err)
@@ -148,6 +144,38 @@ func getAWSAccountDetails(ctx context.Context, sourceId string, authentication *
 	return result, nil
 }
 
+// resolveAWSAccountId returns the account ID stored for the source, STS is only called when
+// the source was never resolved or its ARN has changed since then.
+func resolveAWSAccountId(ctx context.Context, sourceId string, authentication *clients.Authentication) (string, error) {
+	sourceIdentityDao := dao.GetSourceIdentityDao(ctx)
+	stored, err := sourceIdentityDao.GetBySourceId(ctx, sourceId)
+	if err == nil && stored.Authentication == authentication.Payload {
+		return stored.ProviderAccountID, nil
+	} else if err != nil && !errors.Is(err, dao.ErrNoRows) {
+		return "", fmt.Errorf("unable to get source identity: %w", err)
+	}
+
+	ec2Client, err := clients.GetEC2Client(ctx, authentication, "")
+	if err != nil {
+		return "", fmt.Errorf("unable to initialize AWS client: %w", err)
+	}
+
+	accountId, err := ec2Client.GetAccountId(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to get account id: %w", err)
+	}
+
+	err = sourceIdentityDao.Upsert(ctx, &models.SourceIdentity{
+		SourceID:          sourceId,
+		Authentication:    authentication.Payload,
+		ProviderAccountID: accountId,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to store source identity: %w", err)
+	}
+	return accountId, nil
+}
+
 func getAzureAccountDetails(ctx context.Context, sourceId string, authentication *clients.Authentication) (*clients.AccountDetailsAzure, error) {
 	var tenantId clients.AzureTenantId
 
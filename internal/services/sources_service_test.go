@@ -37,12 +37,14 @@ func TestListSourcesHandler(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
 
-		var result []sources.Source
+		var result struct {
+			Data []sources.Source `json:"data"`
+		}
 
 		err = json.NewDecoder(rr.Body).Decode(&result)
 		require.NoError(t, err, "failed to decode response body")
 
-		assert.Equal(t, 2, len(result), "expected two result in response json")
+		assert.Equal(t, 2, len(result.Data), "expected two result in response json")
 	})
 
 	t.Run("with provider", func(t *testing.T) {
@@ -59,12 +61,15 @@ func TestListSourcesHandler(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
 
-		var result []payloads.SourceResponse
-
+		var result struct {
+			Data []payloads.SourceResponse `json:"data"`
+			Meta payloads.ListMeta         `json:"meta"`
+		}
 		err = json.NewDecoder(rr.Body).Decode(&result)
 		require.NoError(t, err, "failed to decode response body")
 
-		assert.Equal(t, 2, len(result), "expected two result in response json")
+		assert.Equal(t, 2, len(result.Data), "expected two result in response json")
+		assert.Equal(t, 2, result.Meta.Count)
 	})
 
 	t.Run("with invalid provider", func(t *testing.T) {
@@ -114,3 +119,32 @@ func TestGetAzureSourceDetails(t *testing.T) {
 		assert.Equal(t, 3, len(result.AzureInfo.ResourceGroups), "expected three resource groups in response json")
 	})
 }
+
+func TestResolveAWSAccountId(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithSourceIdentityDao(ctx)
+	ctx = clientStub.WithEC2Client(ctx)
+	ec2Client, err := clients.GetEC2Client(ctx, nil, "")
+	require.NoError(t, err)
+
+	auth := clients.NewAuthentication("arn:aws:iam::230214684733:role/Test", models.ProviderTypeAWS)
+
+	t.Run("resolved once", func(t *testing.T) {
+		accountId, err := resolveAWSAccountId(ctx, "1", auth)
+		require.NoError(t, err)
+		assert.Equal(t, "230214684733", accountId)
+
+		accountId, err = resolveAWSAccountId(ctx, "1", auth)
+		require.NoError(t, err)
+		assert.Equal(t, "230214684733", accountId)
+		assert.Equal(t, 1, ec2Client.(*clientStub.EC2ClientStub).AccountIdCalls, "stored identity should be used")
+	})
+
+	t.Run("resolved again when arn changes", func(t *testing.T) {
+		changed := clients.NewAuthentication("arn:aws:iam::230214684733:role/Other", models.ProviderTypeAWS)
+		_, err := resolveAWSAccountId(ctx, "1", changed)
+		require.NoError(t, err)
+		assert.Equal(t, 2, ec2Client.(*clientStub.EC2ClientStub).AccountIdCalls)
+	})
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ValidateSourcePermissions runs the provider permission checklist with the source credentials
+// and returns whether each permission is granted, so the role can be fixed before a launch.
+func ValidateSourcePermissions(w http.ResponseWriter, r *http.Request) {
+	sourceId := chi.URLParam(r, "ID")
+
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	auth, err := sourcesClient.GetAuthentication(r.Context(), sourceId)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	var checks []clients.PermissionCheck
+	switch auth.Type() {
+	case models.ProviderTypeAWS:
+		ec2Client, clientErr := clients.GetEC2Client(r.Context(), auth, "")
+		if clientErr != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS client", clientErr))
+			return
+		}
+		checks, err = ec2Client.ValidatePermissions(r.Context(), auth)
+		if err != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to validate AWS permissions", err))
+			return
+		}
+	case models.ProviderTypeGCP:
+		gcpClient, clientErr := clients.GetGCPClient(r.Context(), auth)
+		if clientErr != nil {
+			renderError(w, r, payloads.NewGCPError(r.Context(), "unable to get GCP client", clientErr))
+			return
+		}
+		checks, err = gcpClient.ValidatePermissions(r.Context())
+		if err != nil {
+			renderError(w, r, payloads.NewGCPError(r.Context(), "unable to validate GCP permissions", err))
+			return
+		}
+	case models.ProviderTypeAzure:
+		azureClient, clientErr := clients.GetAzureClient(r.Context(), auth)
+		if clientErr != nil {
+			renderError(w, r, payloads.NewAzureError(r.Context(), "unable to get Azure client", clientErr))
+			return
+		}
+		checks, err = azureClient.ValidatePermissions(r.Context())
+		if err != nil {
+			renderError(w, r, payloads.NewAzureError(r.Context(), "unable to validate Azure permissions", err))
+			return
+		}
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unknown sources provider", UnknownProviderFromSourcesErr))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewPermissionReportResponse(auth.Type().String(), checks)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render permission report", err))
+	}
+}
@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSourcePermissions(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+	ctx = clientStubs.WithGCPCCustomerClient(ctx)
+
+	withSourceParam := func(id string) context.Context {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("ID", id)
+		return context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	}
+
+	t.Run("aws", func(t *testing.T) {
+		source, err := clientStubs.AddSource(ctx, models.ProviderTypeAWS)
+		require.NoError(t, err, "failed to add AWS source")
+
+		rr := serveJSON(t, withSourceParam(source.ID), "POST", nil, services.ValidateSourcePermissions)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var report payloads.PermissionReportResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&report), "failed to decode response body")
+		assert.False(t, report.Valid)
+		assert.Equal(t, "aws", report.Provider)
+		assert.Equal(t, []payloads.PermissionCheckResponse{
+			{Permission: "ec2:RunInstances", Allowed: true},
+			{Permission: "ec2:CreateLaunchTemplate", Allowed: false},
+		}, report.Permissions)
+	})
+
+	t.Run("gcp", func(t *testing.T) {
+		source, err := clientStubs.AddSource(ctx, models.ProviderTypeGCP)
+		require.NoError(t, err, "failed to add GCP source")
+
+		rr := serveJSON(t, withSourceParam(source.ID), "POST", nil, services.ValidateSourcePermissions)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var report payloads.PermissionReportResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&report), "failed to decode response body")
+		assert.True(t, report.Valid)
+		assert.Equal(t, "gcp", report.Provider)
+	})
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/preload"
+	"github.com/rs/zerolog"
+)
+
+// estimateHourlyCost returns estimated price of an instance of the type in US dollars per hour
+// computed from its vCPUs, memory and GPUs. Unknown types (e.g. launch templates) cost zero.
+func estimateHourlyCost(provider models.ProviderType, instanceType string) float64 {
+	var it *clients.InstanceType
+	name := clients.InstanceTypeName(instanceType)
+	switch provider {
+	case models.ProviderTypeAWS:
+		it = preload.EC2InstanceType.FindInstanceType(name)
+	case models.ProviderTypeAzure:
+		it = preload.AzureInstanceType.FindInstanceType(name)
+	case models.ProviderTypeGCP:
+		it = preload.GCPInstanceType.FindInstanceType(name)
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+	}
+	if it == nil {
+		return 0
+	}
+
+	rates := config.Application.Spend
+	return float64(it.VCPUs)*rates.VCPUHour +
+		float64(it.MemoryMiB)/1024*rates.MemoryGiBHour +
+		float64(it.GPUs)*rates.GPUHour
+}
+
+// checkSpendCap rejects a reservation when its estimated hourly cost together with the cost of
+// instances of pending and successful reservations of the account exceeds the spend cap from
+// account settings.
+func checkSpendCap(ctx context.Context, requested float64) *payloads.ResponseError {
+	settings, err := dao.GetAccountSettingsDao(ctx).Get(ctx)
+	if err != nil {
+		return payloads.NewDAOError(ctx, "get account settings", err)
+	}
+	if settings.MaxHourlySpend <= 0 {
+		return nil
+	}
+
+	running, err := dao.GetReservationDao(ctx).ListRunningInstanceTypes(ctx)
+	if err != nil {
+		return payloads.NewDAOError(ctx, "list running instance types", err)
+	}
+	var runningCost float64
+	for _, r := range running {
+		runningCost += float64(r.Amount) * estimateHourlyCost(r.Provider, r.InstanceType)
+	}
+
+	zerolog.Ctx(ctx).Debug().Msgf("Estimated hourly spend %.2f running and %.2f requested, cap %.2f",
+		runningCost, requested, settings.MaxHourlySpend)
+	if runningCost+requested > settings.MaxHourlySpend {
+		return payloads.NewSpendCapExceededError(ctx, &payloads.SpendCapViolation{
+			MaxHourlySpend:       settings.MaxHourlySpend,
+			RunningHourlySpend:   runningCost,
+			RequestedHourlySpend: requested,
+		}, SpendCapExceededError)
+	}
+	return nil
+}
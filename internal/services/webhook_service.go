@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+)
+
+// Length of generated webhook secrets in bytes.
+const webhookSecretLength = 32
+
+// CreateWebhook registers a webhook, the secret is returned only in this response.
+func CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	payload := &payloads.WebhookRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewBindError(r.Context(), "create webhook", err))
+		return
+	}
+
+	webhook := payload.NewModel()
+	if webhook.Secret == "" {
+		secret := make([]byte, webhookSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			renderError(w, r, payloads.NewResponseError(r.Context(), http.StatusInternalServerError, "unable to generate webhook secret", err))
+			return
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	err := dao.GetWebhookDao(r.Context()).Create(r.Context(), webhook)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "create webhook", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewWebhookCreatedResponse(webhook)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render webhook", err))
+	}
+}
+
+func ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	webhookDao := dao.GetWebhookDao(r.Context())
+
+	webhooks, err := webhookDao.List(r.Context(), limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list webhooks", err))
+		return
+	}
+	total, err := webhookDao.Count(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count webhooks", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewWebhookListResponse(webhooks), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render webhooks list", err))
+	}
+}
+
+func GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	webhook, err := dao.GetWebhookDao(r.Context()).GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get webhook with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewWebhookResponse(webhook)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render webhook", err))
+	}
+}
+
+// DeleteWebhook removes a webhook together with its delivery log, pending deliveries are
+// not sent.
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	webhookDao := dao.GetWebhookDao(r.Context())
+	webhook, err := webhookDao.GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get webhook with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	err = webhookDao.Delete(r.Context(), webhook.ID)
+	if err != nil {
+		message := fmt.Sprintf("webhook with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook, newest first.
+func ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+	limit, offset, err := ParsePage(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unable to parse limit or offset parameter", err))
+		return
+	}
+
+	webhookDao := dao.GetWebhookDao(r.Context())
+	webhook, err := webhookDao.GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get webhook with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	deliveries, err := webhookDao.ListDeliveries(r.Context(), webhook.ID, limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list webhook deliveries", err))
+		return
+	}
+	total, err := webhookDao.CountDeliveries(r.Context(), webhook.ID)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count webhook deliveries", err))
+		return
+	}
+
+	response := payloads.NewPageResponse(r, payloads.NewWebhookDeliveryListResponse(deliveries), total, limit, offset)
+	if err := render.Render(w, r, response); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render webhook deliveries list", err))
+	}
+}
@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	identity2 "github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookHandlers(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithWebhookDao(ctx)
+	var created payloads.WebhookResponse
+
+	t.Run("create", func(t *testing.T) {
+		values := map[string]interface{}{
+			"url":    "https://example.com/hook",
+			"events": []string{"reservation.success", "instance.terminated"},
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateWebhook)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&created), "failed to decode response body")
+		assert.Equal(t, "https://example.com/hook", created.URL)
+		assert.Len(t, created.Secret, 64, "generated secret is returned on create")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		values := map[string]interface{}{
+			"url":    "ftp://example.com",
+			"events": []string{"reservation.success"},
+		}
+		rr := serveJSON(t, ctx, "POST", values, services.CreateWebhook)
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Contains(t, rr.Body.String(), "format")
+	})
+
+	t.Run("get hides secret", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(withIDParam(ctx, created.ID), "GET", "/api/provisioning/webhooks/1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.GetWebhook).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		assert.NotContains(t, rr.Body.String(), "secret")
+	})
+
+	t.Run("deliveries", func(t *testing.T) {
+		_, err := dao.GetWebhookDao(ctx).UnscopedCreateDeliveries(ctx, identity2.AccountId(ctx), models.WebhookEventInstanceTerminated, []byte(`{}`))
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(withIDParam(ctx, created.ID), "GET", "/api/provisioning/webhooks/1/deliveries", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.ListWebhookDeliveries).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result struct {
+			Data []payloads.WebhookDeliveryResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "instance.terminated", result.Data[0].Event)
+		assert.Equal(t, "pending", result.Data[0].Status)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(withIDParam(ctx, created.ID), "DELETE", "/api/provisioning/webhooks/1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.DeleteWebhook).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusNoContent, rr.Code, "Handler returned wrong status code")
+		assert.Empty(t, stubs.WebhookDeliveries(ctx))
+	})
+}
@@ -10,10 +10,24 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
+// Supported public key types as reported by OpenSSH.
+const (
+	KeyTypeRSA      = "ssh-rsa"
+	KeyTypeED25519  = "ssh-ed25519"
+	KeyTypeECDSA256 = "ecdsa-sha2-nistp256"
+	KeyTypeECDSA384 = "ecdsa-sha2-nistp384"
+	KeyTypeECDSA521 = "ecdsa-sha2-nistp521"
+)
+
+// IsECDSA returns true for all ECDSA key types (NIST P-256, P-384 and P-521).
+func IsECDSA(keyType string) bool {
+	return keyType == KeyTypeECDSA256 || keyType == KeyTypeECDSA384 || keyType == KeyTypeECDSA521
+}
+
 // OpenSSHFingerprints is the de-facto standard OpenSSH fingerprints for SSH public keys:
-// SHA256 (used for ED type keys) and MD5 (used for RSA keys). Fingerprints are returned as
+// SHA256 and MD5, both are calculated for every key type. Fingerprints are returned as
 // string encoded into base64 or hex respectively. Additionally, type and comment are also
-// returned. Type as one of the: "ssh-ed25519" or "ssh-rsa".
+// returned. Type as one of the: "ssh-ed25519", "ssh-rsa" or "ecdsa-sha2-nistpXXX".
 type OpenSSHFingerprints struct {
 	Type    string
 	SHA256  string
@@ -59,6 +73,8 @@ func GenerateOpenSSHFingerprints(pubkeyBody []byte) (OpenSSHFingerprints, error)
 }
 
 // GenerateAWSFingerprint parses a public key and returns AWS PEM fingerprint used for RSA keys.
+// It works for ED25519 and ECDSA keys too, but AWS uses SHA256 fingerprint for ED25519 keys
+// and does not support ECDSA keys at all.
 // MD5 fingerprint stored as hexadecimal with colons without any prefix from key in PEM format.
 // This format is specific to AWS. To generate such fingerprint:
 //
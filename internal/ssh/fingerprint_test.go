@@ -20,6 +20,7 @@ func TestFingerprintPEMGeneration(t *testing.T) {
 	tests := []test{
 		{"ed25519", factories.NewPubkeyED25519(), "e3:8d:76:a4:f2:78:29:f5:6d:0b:95:5c:e9:80:47:85"},
 		{"rsa", factories.NewPubkeyRSA(), "c4:ba:72:45:16:a9:2c:39:c3:99:8d:e7:16:01:9c:77"},
+		{"ecdsa", factories.NewPubkeyECDSA(), "7b:f6:53:4e:ee:82:43:36:25:79:f2:66:c5:62:7b:2e"},
 	}
 
 	for _, td := range tests {
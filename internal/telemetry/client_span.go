@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of client call spans.
+const (
+	ProviderKey  = attribute.Key("provisioning.provider")
+	OperationKey = attribute.Key("provisioning.operation")
+	RegionKey    = attribute.Key("provisioning.region")
+	ResultKey    = attribute.Key("provisioning.result")
+)
+
+// ClientSpan is a span of a single call of a cloud or platform client (e.g. "aws" or "sources").
+// The result attribute is set when the span ends: "error" when error status was set, "success"
+// otherwise.
+type ClientSpan struct {
+	trace.Span
+	failed bool
+}
+
+type clientCallCtxKeyType int
+
+const clientCallCtxKey clientCallCtxKeyType = iota
+
+// clientCall is the provider and the operation of a client span stored in the context.
+type clientCall struct {
+	provider  string
+	operation string
+}
+
+// ClientCall returns provider and operation of the client span started in the context, empty
+// strings are returned outside of client spans. Outbound HTTP requests are labelled with them.
+func ClientCall(ctx context.Context) (provider, operation string) {
+	call, ok := ctx.Value(clientCallCtxKey).(clientCall)
+	if !ok {
+		return "", ""
+	}
+	return call.provider, call.operation
+}
+
+// StartClientSpan starts a client span named after the operation with provider, operation and
+// region attributes. Region is omitted when empty. Provider and operation are also stored in
+// the returned context (see ClientCall).
+func StartClientSpan(ctx context.Context, tracerName, provider, operation, region string) (context.Context, *ClientSpan) {
+	attrs := []attribute.KeyValue{ProviderKey.String(provider), OperationKey.String(operation)}
+	if region != "" {
+		attrs = append(attrs, RegionKey.String(region))
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	ctx = context.WithValue(ctx, clientCallCtxKey, clientCall{provider: provider, operation: operation})
+	return ctx, &ClientSpan{Span: span}
+}
+
+// SetStatus sets the span status, error status marks the call as failed.
+func (s *ClientSpan) SetStatus(code codes.Code, description string) {
+	if code == codes.Error {
+		s.failed = true
+	}
+	s.Span.SetStatus(code, description)
+}
+
+// Fail records the error and sets the error status.
+func (s *ClientSpan) Fail(err error) {
+	s.Span.RecordError(err)
+	s.SetStatus(codes.Error, err.Error())
+}
+
+// End sets the result attribute and ends the span.
+func (s *ClientSpan) End(options ...trace.SpanEndOption) {
+	result := "success"
+	if s.failed {
+		result = "error"
+	}
+	s.Span.SetAttributes(ResultKey.String(result))
+	s.Span.End(options...)
+}
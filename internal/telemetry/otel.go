@@ -11,6 +11,7 @@ import (
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
@@ -37,7 +38,14 @@ func Initialize(rootLogger *zerolog.Logger) *Telemetry {
 	logger := rootLogger.With().Bool("otel", true).Logger()
 
 	var exporterOption trace.TracerProviderOption
-	if config.Telemetry.Jaeger.Enabled {
+	if config.Telemetry.OTLP.Enabled {
+		// production use case: export to OTLP collector (Jaeger/Tempo), batching
+		exporter, err := otlptracegrpc.New(context.Background(), otlpOptions()...)
+		if err != nil {
+			panic(err)
+		}
+		exporterOption = trace.WithBatcher(exporter)
+	} else if config.Telemetry.Jaeger.Enabled {
 		// production use case: full exporting, batching
 		exporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(config.Telemetry.Jaeger.Endpoint)))
 		if err != nil {
@@ -60,9 +68,14 @@ func Initialize(rootLogger *zerolog.Logger) *Telemetry {
 		semconv.ServiceVersionKey.String(version.OpenTelemetryVersion),
 	)
 
+	// traces started by the application are sampled by ratio, traces continued from
+	// an incoming W3C Trace Context keep the decision of the caller
+	sampler := trace.ParentBased(trace.TraceIDRatioBased(config.Telemetry.SampleRatio))
+
 	tp := trace.NewTracerProvider(
 		exporterOption,
 		trace.WithResource(res),
+		trace.WithSampler(sampler),
 	)
 
 	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
@@ -71,6 +84,17 @@ func Initialize(rootLogger *zerolog.Logger) *Telemetry {
 	return &Telemetry{tracerProvider: tp, propagator: propagator}
 }
 
+func otlpOptions() []otlptracegrpc.Option {
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Telemetry.OTLP.Endpoint)}
+	if config.Telemetry.OTLP.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	if len(config.Telemetry.OTLP.Headers) > 0 {
+		options = append(options, otlptracegrpc.WithHeaders(config.Telemetry.OTLP.Headers))
+	}
+	return options
+}
+
 func (t *Telemetry) Close(_ context.Context) {
 	if t.tracerProvider == nil {
 		return
@@ -35,6 +35,24 @@ func WithCustomIdentity(t *testing.T, ctx context.Context, orgId string, account
 	return context.WithValue(ctx, rhidentity.Key, newIdentity(orgId, accountNumber))
 }
 
+// WithUser returns context copy with identity of a user of the default organization, the
+// account of the context is kept.
+func WithUser(t *testing.T, ctx context.Context, username string) context.Context {
+	id := newIdentity(DefaultOrgId, ptr.To(DefaultAccountNumber))
+	id.Identity.Type = string(identity.UserPrincipal)
+	id.Identity.User.Username = username
+	return context.WithValue(ctx, rhidentity.Key, id)
+}
+
+// WithServiceAccount returns context copy with identity of a service account of the default
+// organization, the account of the context is kept.
+func WithServiceAccount(t *testing.T, ctx context.Context, clientId string) context.Context {
+	id := newIdentity(DefaultOrgId, ptr.To(DefaultAccountNumber))
+	id.Identity.Type = string(identity.ServiceAccountPrincipal)
+	ctx = context.WithValue(ctx, rhidentity.Key, id)
+	return identity.WithMachine(ctx, &identity.MachinePrincipal{Type: identity.ServiceAccountPrincipal, ClientID: clientId})
+}
+
 func WithTenant(t *testing.T, ctx context.Context) context.Context {
 	return WithTenantOrgId(t, ctx, DefaultOrgId)
 }
@@ -9,7 +9,7 @@ import (
 )
 
 func InitDbEnvironment(ctx context.Context) {
-	err := db.Initialize(context.Background(), "integration")
+	err := db.Initialize(context.Background(), "integration", "")
 	if err != nil {
 		panic(fmt.Errorf("cannot connect to database: %w (integration schema)", err))
 	}
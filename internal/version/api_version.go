@@ -0,0 +1,24 @@
+package version
+
+import "context"
+
+type apiVersionCtxKeyType int
+
+const apiVersionCtxKey apiVersionCtxKeyType = iota
+
+// APIVersions are all API versions served, handlers are shared and payloads are converted
+// into the shape of the requested version during rendering.
+var APIVersions = []string{APIPathVersion, APIPathVersionV2}
+
+// WithAPIVersion returns a context with the API version of the request.
+func WithAPIVersion(ctx context.Context, apiVersion string) context.Context {
+	return context.WithValue(ctx, apiVersionCtxKey, apiVersion)
+}
+
+// APIVersion returns the API version of the request, APIPathVersion when not set.
+func APIVersion(ctx context.Context) string {
+	if v, ok := ctx.Value(apiVersionCtxKey).(string); ok {
+		return v
+	}
+	return APIPathVersion
+}
@@ -29,6 +29,9 @@ const (
 	// APIPathVersion is the name used in main route API prefix
 	APIPathVersion = "v1"
 
+	// APIPathVersionV2 is the name used in route API prefix of the second API version
+	APIPathVersionV2 = "v2"
+
 	// OpenTelemetryVersion is used for all OpenTelemetry tracing
 	OpenTelemetryVersion = "1.0.0"
 
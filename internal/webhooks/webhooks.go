@@ -0,0 +1,211 @@
+// Package webhooks delivers reservation lifecycle events to HTTPS endpoints registered by
+// organizations. Events are stored in the outbox under an internal topic together with the
+// reservation change, the outbox relay fans them out into deliveries of subscribed webhooks
+// and workers send the deliveries with retries.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Topic is an outbox topic of webhook events, messages are never sent to Kafka.
+const Topic = "provisioning.internal.webhooks"
+
+const (
+	accountHeader = "account-id"
+	eventHeader   = "event"
+)
+
+// Delivery request headers.
+const (
+	EventHeader     = "X-Provisioning-Event"
+	DeliveryHeader  = "X-Provisioning-Delivery"
+	TimestampHeader = "X-Provisioning-Timestamp"
+	SignatureHeader = "X-Provisioning-Signature"
+)
+
+var (
+	ErrMissingHeader    = errors.New("missing webhook message header")
+	ErrUnexpectedStatus = errors.New("unexpected webhook response status")
+	ErrForbiddenAddress = errors.New("webhook address is not public")
+)
+
+// Event is the payload of a delivery.
+type Event struct {
+	// Event ID, the same for deliveries of the event to multiple webhooks.
+	ID string `json:"id"`
+
+	// Event type.
+	Event models.WebhookEvent `json:"event"`
+
+	// Time of the event.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Event details, see ReservationData.
+	Data interface{} `json:"data"`
+}
+
+// ReservationData are details of reservation and instance events.
+type ReservationData struct {
+	ReservationID int64    `json:"reservation_id"`
+	Provider      string   `json:"provider"`
+	Instances     []string `json:"instances,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// EventMessage returns an outbox message of the event of an account. The message is stored
+// together with the change which caused the event.
+func EventMessage(accountId int64, event models.WebhookEvent, data interface{}) (*models.OutboxMessage, error) {
+	payload, err := json.Marshal(Event{
+		ID:        uuid.New().String(),
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal webhook event: %w", err)
+	}
+
+	return &models.OutboxMessage{
+		Topic: Topic,
+		Key:   []byte(strconv.FormatInt(accountId, 10)),
+		Value: payload,
+		Headers: []models.OutboxHeader{
+			{Key: accountHeader, Value: strconv.FormatInt(accountId, 10)},
+			{Key: eventHeader, Value: string(event)},
+		},
+	}, nil
+}
+
+// Fanout creates deliveries of an outbox event message for all subscribed webhooks.
+func Fanout(ctx context.Context, m *models.OutboxMessage) error {
+	var accountId int64
+	var event string
+	for _, h := range m.Headers {
+		switch h.Key {
+		case accountHeader:
+			id, err := strconv.ParseInt(h.Value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("unable to parse webhook account id: %w", err)
+			}
+			accountId = id
+		case eventHeader:
+			event = h.Value
+		}
+	}
+	if accountId == 0 || event == "" {
+		return ErrMissingHeader
+	}
+
+	_, err := dao.GetWebhookDao(ctx).UnscopedCreateDeliveries(ctx, accountId, models.WebhookEvent(event), m.Value)
+	if err != nil {
+		return fmt.Errorf("unable to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// Sign returns the signature of a delivery: hex encoded HMAC-SHA256 of the timestamp and the
+// body joined by a dot, keyed by the webhook secret. Receivers should reject old timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// carrierGradeNAT is the shared address space (RFC 6598) often used by cluster networks.
+var carrierGradeNAT = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewClient returns HTTP client for deliveries. Webhook URLs are supplied by customers, the
+// client is not a platform client: it refuses to connect to non-public addresses, does not
+// follow redirects, ignores proxy settings and sends no trace or identity headers.
+func NewClient(_ context.Context) httpClients.HttpRequestDoer {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: dialControl,
+	}
+
+	//nolint:forcetypeassert
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialControl is called with the resolved address of every connection, checking it after
+// resolution prevents DNS rebinding to internal addresses.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !PublicAddress(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// PublicAddress returns false for loopback, private, link-local (including the cloud metadata
+// service), shared, multicast and unspecified addresses.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !carrierGradeNAT.Contains(addr)
+}
+
+// Deliver sends a signed delivery to the webhook and returns the response status, zero when
+// no response was received. Any status other than 2xx is an error.
+func Deliver(ctx context.Context, client httpClients.HttpRequestDoer, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.Webhooks.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("unable to create webhook request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("unable to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,128 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/RHEnVision/provisioning-backend/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	signature := webhooks.Sign("secret", 1700000000, []byte(`{}`))
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.Equal(t, signature, webhooks.Sign("secret", 1700000000, []byte(`{}`)))
+	assert.NotEqual(t, signature, webhooks.Sign("other", 1700000000, []byte(`{}`)))
+	assert.NotEqual(t, signature, webhooks.Sign("secret", 1700000001, []byte(`{}`)))
+}
+
+func TestFanoutAndDeliver(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithWebhookDao(ctx)
+	original := config.Webhooks.Timeout
+	config.Webhooks.Timeout = time.Second
+	t.Cleanup(func() { config.Webhooks.Timeout = original })
+
+	var received *http.Request
+	var body []byte
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	webhook := &models.Webhook{
+		URL:    server.URL,
+		Secret: "s3cr3t",
+		Events: []string{string(models.WebhookEventReservationFailure)},
+	}
+	require.NoError(t, dao.GetWebhookDao(ctx).Create(ctx, webhook))
+
+	data := webhooks.ReservationData{ReservationID: 42, Provider: "aws", Error: "boom"}
+	success, err := webhooks.EventMessage(webhook.AccountID, models.WebhookEventReservationSuccess, data)
+	require.NoError(t, err)
+	failure, err := webhooks.EventMessage(webhook.AccountID, models.WebhookEventReservationFailure, data)
+	require.NoError(t, err)
+	assert.Equal(t, webhooks.Topic, failure.Topic)
+
+	require.NoError(t, webhooks.Fanout(ctx, success))
+	require.NoError(t, webhooks.Fanout(ctx, failure))
+	deliveries := stubs.WebhookDeliveries(ctx)
+	require.Len(t, deliveries, 1, "only subscribed events are delivered")
+
+	t.Run("delivered", func(t *testing.T) {
+		code, err := webhooks.Deliver(ctx, http.DefaultClient, webhook, deliveries[0])
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		assert.Equal(t, "reservation.failure", received.Header.Get(webhooks.EventHeader))
+		assert.Equal(t, strconv.FormatInt(deliveries[0].ID, 10), received.Header.Get(webhooks.DeliveryHeader))
+		timestamp, err := strconv.ParseInt(received.Header.Get(webhooks.TimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, webhooks.Sign("s3cr3t", timestamp, body), received.Header.Get(webhooks.SignatureHeader))
+
+		var event webhooks.Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, models.WebhookEventReservationFailure, event.Event)
+		assert.NotEmpty(t, event.ID)
+		assert.Equal(t, map[string]interface{}{"reservation_id": float64(42), "provider": "aws", "error": "boom"}, event.Data)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		code, err := webhooks.Deliver(ctx, http.DefaultClient, webhook, deliveries[0])
+		require.ErrorIs(t, err, webhooks.ErrUnexpectedStatus)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("internal address", func(t *testing.T) {
+		status = http.StatusNoContent
+		received = nil
+		code, err := webhooks.Deliver(ctx, webhooks.NewClient(ctx), webhook, deliveries[0])
+		require.ErrorIs(t, err, webhooks.ErrForbiddenAddress)
+		assert.Equal(t, 0, code)
+		assert.Nil(t, received, "loopback server must not be reached")
+	})
+}
+
+func TestPublicAddress(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"8.8.8.8":          true,
+		"2001:4860::8888":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.0.0.1":         false,
+		"192.168.1.1":      false,
+		"100.64.0.1":       false,
+		"169.254.169.254":  false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+		"0.0.0.0":          false,
+	} {
+		assert.Equal(t, public, webhooks.PublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestFanoutMissingHeader(t *testing.T) {
+	err := webhooks.Fanout(context.Background(), &models.OutboxMessage{Topic: webhooks.Topic, Value: []byte(`{}`)})
+	require.ErrorIs(t, err, webhooks.ErrMissingHeader)
+}
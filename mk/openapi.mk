@@ -8,5 +8,5 @@ generate-spec: ## Generate OpenAPI spec
 .PHONY: validate-spec
 validate-spec: ## Compare OpenAPI spec with git
 	$(GO) run ./cmd/spec $(shell head -n1 ./cmd/spec/VERSION)
-	git diff --exit-code api/openapi.gen.json
+	git diff --exit-code api/openapi.gen.json api/openapi.v2.gen.json
 
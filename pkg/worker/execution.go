@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Execution is a single attempt of a job.
+type Execution struct {
+	// Time when the handler was called.
+	Started time.Time
+
+	// Duration of the handler.
+	Duration time.Duration
+
+	// Result of the attempt (success/retry/failure/timeout), same as in job attempt metrics.
+	Result string
+
+	// Error returned by the handler or nil.
+	Err error
+}
+
+// ExecutionRecorder is called after every attempt of a job, for example to keep an audit log.
+type ExecutionRecorder func(ctx context.Context, job *Job, execution *Execution)
+
+// attemptResult returns result of an attempt, the handler context is used to detect timeouts.
+func attemptResult(ctx context.Context, job *Job, err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case !job.LastAttempt():
+		return "retry"
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "failure"
+	}
+}
+
+// withExecutionRecorder returns a handler which passes every attempt to the recorder. The
+// parent context is passed to the recorder, the handler context can be already cancelled.
+func withExecutionRecorder(parent context.Context, handler JobHandler, recorder ExecutionRecorder) JobHandler {
+	if recorder == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, job *Job) error {
+		started := time.Now()
+		err := handler(ctx, job)
+		recorder(parent, job, &Execution{
+			Started:  started,
+			Duration: time.Since(started),
+			Result:   attemptResult(ctx, job, err),
+			Err:      err,
+		})
+		return err
+	}
+}
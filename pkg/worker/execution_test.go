@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithExecutionRecorder(t *testing.T) {
+	errJob := errors.New("job failed")
+
+	tests := []struct {
+		name     string
+		job      *Job
+		err      error
+		expected string
+	}{
+		{"success", &Job{Attempt: 1, MaxAttempts: 1}, nil, "success"},
+		{"retry", &Job{Attempt: 1, MaxAttempts: 3}, errJob, "retry"},
+		{"failure", &Job{Attempt: 3, MaxAttempts: 3}, errJob, "failure"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executions []*Execution
+			handler := withExecutionRecorder(context.Background(), func(_ context.Context, _ *Job) error {
+				return tt.err
+			}, func(_ context.Context, _ *Job, execution *Execution) {
+				executions = append(executions, execution)
+			})
+
+			err := handler(context.Background(), tt.job)
+			require.ErrorIs(t, err, tt.err)
+			require.Len(t, executions, 1)
+			assert.Equal(t, tt.expected, executions[0].Result)
+			assert.Equal(t, tt.err, executions[0].Err)
+			assert.False(t, executions[0].Started.IsZero())
+		})
+	}
+}
+
+func TestWithExecutionRecorderTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	var result string
+	handler := withExecutionRecorder(context.Background(), func(ctx context.Context, _ *Job) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, func(_ context.Context, _ *Job, execution *Execution) {
+		result = execution.Result
+	})
+
+	require.Error(t, handler(ctx, &Job{Attempt: 1, MaxAttempts: 1}))
+	assert.Equal(t, "timeout", result)
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Heartbeater records running jobs, so jobs of workers which died mid-execution can be
+// detected by missing heartbeats and enqueued again. Errors are logged, they do not affect
+// job processing.
+type Heartbeater interface {
+	// Started is called before the job handler.
+	Started(ctx context.Context, job *Job) error
+
+	// Beat is called periodically while the job handler runs.
+	Beat(ctx context.Context, job *Job) error
+
+	// Finished is called after the job handler returns, including retries being scheduled.
+	Finished(ctx context.Context, job *Job) error
+}
+
+// startHeartbeat calls Started and starts a goroutine calling Beat every interval. The returned
+// function stops the goroutine and calls Finished, it must be called when the job is done.
+func startHeartbeat(ctx context.Context, hb Heartbeater, interval time.Duration, job *Job) func() {
+	if hb == nil || interval <= 0 {
+		return func() {}
+	}
+
+	logger := zerolog.Ctx(ctx)
+	if err := hb.Started(ctx, job); err != nil {
+		logger.Warn().Err(err).Msg("Unable to record running job")
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		defer recoverAndLog(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := hb.Beat(ctx, job); err != nil {
+					logger.Warn().Err(err).Msg("Unable to record job heartbeat")
+				}
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		if err := hb.Finished(ctx, job); err != nil {
+			logger.Warn().Err(err).Msg("Unable to record finished job")
+		}
+	}
+}
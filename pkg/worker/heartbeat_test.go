@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingHeartbeater struct {
+	mu                       sync.Mutex
+	started, beats, finished int
+}
+
+func (h *countingHeartbeater) Started(_ context.Context, _ *Job) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.started++
+	return nil
+}
+
+func (h *countingHeartbeater) Beat(_ context.Context, _ *Job) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.beats++
+	return nil
+}
+
+func (h *countingHeartbeater) Finished(_ context.Context, _ *Job) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.finished++
+	return nil
+}
+
+func TestStartHeartbeat(t *testing.T) {
+	hb := &countingHeartbeater{}
+	stop := startHeartbeat(context.Background(), hb, time.Millisecond, &Job{Type: "test"})
+	assert.Eventually(t, func() bool {
+		hb.mu.Lock()
+		defer hb.mu.Unlock()
+		return hb.beats > 0
+	}, time.Second, time.Millisecond)
+	stop()
+
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	assert.Equal(t, 1, hb.started)
+	assert.Equal(t, 1, hb.finished)
+}
+
+func TestStartHeartbeatDisabled(t *testing.T) {
+	hb := &countingHeartbeater{}
+	stop := startHeartbeat(context.Background(), hb, 0, &Job{Type: "test"})
+	stop()
+
+	assert.Equal(t, 0, hb.started)
+	assert.Equal(t, 0, hb.finished)
+}
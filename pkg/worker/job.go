@@ -1,11 +1,19 @@
 package worker
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/google/uuid"
 )
@@ -17,7 +25,9 @@ func init() {
 
 type JobType string
 
-type JobHandler func(ctx context.Context, job *Job)
+// JobHandler processes a job, returned error is retried according to the retry policy of
+// the job type.
+type JobHandler func(ctx context.Context, job *Job) error
 
 type Job struct {
 	// Random UUID for logging and tracing. It is generated randomly by Enqueue function when blank.
@@ -32,11 +42,41 @@ type Job struct {
 	// Associated identity
 	Identity identity.Principal
 
+	// Machine principal (system or service account) of the enqueuing request, it determines
+	// ownership of resources. Set by Enqueue when blank.
+	Machine *identity.MachinePrincipal
+
 	// Job arguments.
 	Args any
+
+	// Version of job arguments, set by Enqueue from the registered ArgsSchema when blank.
+	ArgsVersion int
+
+	// Attempt number starting from 1, incremented for every retry.
+	Attempt int
+
+	// Maximum number of attempts of the job type, set by the worker before the job is handled.
+	MaxAttempts int
+
+	// W3C trace context (traceparent) of the enqueuing request, set by Enqueue when blank.
+	TraceContext map[string]string
+}
+
+// LastAttempt returns true when the job will not be retried on failure.
+func (j *Job) LastAttempt() bool {
+	return j.Attempt >= j.MaxAttempts
 }
 
-var HandlerNotFoundErr = errors.New("handler not registered")
+// DeadLetterHandler receives jobs which failed permanently together with the last error.
+type DeadLetterHandler func(ctx context.Context, job *Job, jobErr error)
+
+var (
+	HandlerNotFoundErr    = errors.New("handler not registered")
+	ErrInvalidConcurrency = errors.New("concurrency must be at least 1")
+)
+
+// ErrJobTimeout wraps errors of jobs which did not finish within the job type timeout.
+var ErrJobTimeout = errors.New("job timed out")
 
 // JobEnqueuer sends Job messages into worker queue.
 type JobEnqueuer interface {
@@ -49,6 +89,31 @@ type JobWorker interface {
 	// RegisterHandler registers an event listener for a particular type with an associated handler.
 	RegisterHandler(JobType, JobHandler, any)
 
+	// RegisterRetryPolicy sets retry policy for a particular type, see NoRetry for the default.
+	RegisterRetryPolicy(JobType, RetryPolicy)
+
+	// RegisterDeadLetterHandler sets a function called for jobs which failed on the last attempt.
+	RegisterDeadLetterHandler(DeadLetterHandler)
+
+	// RegisterPriority sets queue priority for a particular type, see PriorityInteractive for the default.
+	RegisterPriority(JobType, Priority)
+
+	// RegisterTenantLimit limits number of concurrently running jobs of given types per tenant,
+	// excess jobs wait until a job of the same tenant finishes.
+	RegisterTenantLimit(int, ...JobType)
+
+	// SetTenantLimit changes the limit of types registered via RegisterTenantLimit at runtime.
+	SetTenantLimit(int)
+
+	// RegisterArgsSchema sets the current version of arguments of a particular type, see ArgsSchema.
+	RegisterArgsSchema(JobType, ArgsSchema)
+
+	// RegisterExecutionRecorder sets a function called after every attempt of a job.
+	RegisterExecutionRecorder(ExecutionRecorder)
+
+	// RegisterHeartbeater sets a tracker of running jobs called every interval while a job runs.
+	RegisterHeartbeater(Heartbeater, time.Duration)
+
 	// DequeueLoop starts one or more goroutines to dispatch incoming jobs.
 	DequeueLoop(ctx context.Context)
 
@@ -59,6 +124,29 @@ type JobWorker interface {
 	Stats(ctx context.Context) (Stats, error)
 }
 
+// EncodeJob serializes a job including its arguments, argument types must be registered
+// via RegisterHandler first.
+func EncodeJob(job *Job) ([]byte, error) {
+	var buffer bytes.Buffer
+	enc := gob.NewEncoder(&buffer)
+	err := enc.Encode(&job)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode args: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// DecodeJob deserializes a job encoded by EncodeJob.
+func DecodeJob(payload []byte) (*Job, error) {
+	var job Job
+	dec := gob.NewDecoder(bytes.NewReader(payload))
+	err := dec.Decode(&job)
+	if err != nil {
+		return &job, fmt.Errorf("unable to decode job: %w", err)
+	}
+	return &job, nil
+}
+
 func (jt JobType) String() string {
 	return string(jt)
 }
@@ -72,16 +160,47 @@ type Stats struct {
 	InFlight int64
 }
 
+// injectTraceContext stores trace context of the enqueuing request into the job.
+func injectTraceContext(ctx context.Context, job *Job) {
+	if len(job.TraceContext) > 0 {
+		return
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		job.TraceContext = carrier
+	}
+}
+
+// injectMachine stores machine principal of the enqueuing request into the job.
+func injectMachine(ctx context.Context, job *Job) {
+	if job.Machine == nil {
+		job.Machine = identity.Machine(ctx)
+	}
+}
+
 func contextLogger(ctx context.Context, job *Job) context.Context {
 	accountId := job.AccountID
 	id := job.Identity
-	logger := zerolog.Ctx(ctx).With().
+	logCtx := zerolog.Ctx(ctx).With().
 		Str("job_id", job.ID.String()).
 		Int64("account_id", accountId).
 		Str("account_number", id.Identity.AccountNumber).
-		Str("org_id", id.Identity.OrgID).Logger()
+		Str("org_id", id.Identity.OrgID)
+
+	// spans of the job are linked to the enqueuing request
+	if len(job.TraceContext) > 0 {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(job.TraceContext))
+		if traceId := trace.SpanContextFromContext(ctx).TraceID(); traceId.IsValid() {
+			ctx = logging.WithTraceId(ctx, traceId.String())
+			logCtx = logCtx.Str("trace_id", traceId.String())
+		}
+	}
+
+	logger := logCtx.Logger()
 	newContext := logger.WithContext(ctx)
 	newContext = identity.WithIdentity(newContext, id)
+	newContext = identity.WithMachine(newContext, job.Machine)
 	newContext = identity.WithAccountId(newContext, accountId)
 
 	return newContext
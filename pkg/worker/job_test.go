@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestJobTraceContext(t *testing.T) {
+	saved := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(saved)
+
+	traceId, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanId, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId, TraceFlags: trace.FlagsSampled})
+
+	job := &Job{Type: "test"}
+	injectTraceContext(trace.ContextWithSpanContext(context.Background(), sc), job)
+	require.NotEmpty(t, job.TraceContext)
+
+	payload, err := EncodeJob(job)
+	require.NoError(t, err)
+	decoded, err := DecodeJob(payload)
+	require.NoError(t, err)
+
+	ctx := contextLogger(context.Background(), decoded)
+	assert.Equal(t, traceId, trace.SpanContextFromContext(ctx).TraceID())
+	assert.Equal(t, traceId.String(), logging.TraceId(ctx))
+}
+
+func TestJobWithoutTraceContext(t *testing.T) {
+	job := &Job{Type: "test"}
+	injectTraceContext(context.Background(), job)
+	assert.Empty(t, job.TraceContext)
+
+	ctx := contextLogger(context.Background(), job)
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestJobMachine(t *testing.T) {
+	machine := &identity.MachinePrincipal{Type: identity.ServiceAccountPrincipal, ClientID: "client-1"}
+	job := &Job{Type: "test"}
+	injectMachine(identity.WithMachine(context.Background(), machine), job)
+
+	payload, err := EncodeJob(job)
+	require.NoError(t, err)
+	decoded, err := DecodeJob(payload)
+	require.NoError(t, err)
+
+	ctx := contextLogger(context.Background(), decoded)
+	assert.Equal(t, "client-1", identity.Owner(ctx))
+}
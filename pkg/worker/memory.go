@@ -2,7 +2,9 @@ package worker
 
 import (
 	"context"
+	"encoding/gob"
 	"fmt"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/google/uuid"
@@ -10,19 +12,59 @@ import (
 )
 
 type MemoryWorker struct {
-	handlers map[JobType]JobHandler
-	todo     chan *Job
+	handlers   map[JobType]JobHandler
+	policies   map[JobType]RetryPolicy
+	schemas    map[JobType]ArgsSchema
+	deadLetter DeadLetterHandler
+	recorder   ExecutionRecorder
+	todo       chan *Job
 }
 
 func NewMemoryClient() *MemoryWorker {
 	return &MemoryWorker{
 		handlers: make(map[JobType]JobHandler),
+		policies: make(map[JobType]RetryPolicy),
+		schemas:  make(map[JobType]ArgsSchema),
 		todo:     make(chan *Job),
 	}
 }
 
-func (w *MemoryWorker) RegisterHandler(jtype JobType, handler JobHandler, _ any) {
+func (w *MemoryWorker) RegisterHandler(jtype JobType, handler JobHandler, args any) {
 	w.handlers[jtype] = handler
+	// jobs are not encoded in memory, but dead-lettered jobs are
+	gob.Register(args)
+}
+
+func (w *MemoryWorker) RegisterRetryPolicy(jtype JobType, policy RetryPolicy) {
+	w.policies[jtype] = policy
+}
+
+func (w *MemoryWorker) RegisterDeadLetterHandler(handler DeadLetterHandler) {
+	w.deadLetter = handler
+}
+
+func (w *MemoryWorker) RegisterExecutionRecorder(recorder ExecutionRecorder) {
+	w.recorder = recorder
+}
+
+func (w *MemoryWorker) RegisterArgsSchema(jtype JobType, schema ArgsSchema) {
+	w.schemas[jtype] = schema
+}
+
+// RegisterPriority is a no-op, memory worker processes all jobs in a single goroutine.
+func (w *MemoryWorker) RegisterPriority(_ JobType, _ Priority) {
+}
+
+// RegisterTenantLimit is a no-op, memory worker processes all jobs in a single goroutine.
+func (w *MemoryWorker) RegisterTenantLimit(_ int, _ ...JobType) {
+}
+
+// SetTenantLimit is a no-op, memory worker processes all jobs in a single goroutine.
+func (w *MemoryWorker) SetTenantLimit(_ int) {
+}
+
+// RegisterHeartbeater is a no-op, jobs of memory worker are lost when the process dies.
+func (w *MemoryWorker) RegisterHeartbeater(_ Heartbeater, _ time.Duration) {
 }
 
 func (w *MemoryWorker) Enqueue(ctx context.Context, job *Job) error {
@@ -35,10 +77,32 @@ func (w *MemoryWorker) Enqueue(ctx context.Context, job *Job) error {
 		}
 	}
 
+	if job.ArgsVersion == 0 {
+		job.ArgsVersion = w.schemas[job.Type].Version
+	}
+	injectTraceContext(ctx, job)
+	injectMachine(ctx, job)
+
 	w.todo <- job
 	return nil
 }
 
+// schedule enqueues the job after the delay, retries are lost when the worker is stopped.
+func (w *MemoryWorker) schedule(ctx context.Context, job *Job, delay time.Duration) error {
+	go func() {
+		defer recoverAndLog(ctx)
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			w.todo <- job
+		case <-ctx.Done():
+		}
+	}()
+	return nil
+}
+
 func (w *MemoryWorker) Stop(_ context.Context) {
 	close(w.todo)
 }
@@ -57,14 +121,22 @@ func (w *MemoryWorker) dequeueLoop(ctx context.Context) {
 func (w *MemoryWorker) processJob(ctx context.Context, job *Job) {
 	if h, ok := w.handlers[job.Type]; ok {
 		ctx = contextLogger(ctx, job)
-		cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
+		cCtx, cFunc := context.WithTimeout(ctx, config.WorkerTimeout(job.Type.String()))
 		defer cFunc()
-		h(cCtx, job)
+		handler := withExecutionRecorder(ctx, withArgsUpgrade(h, w.schemas[job.Type]), w.recorder)
+		handleJob(ctx, cCtx, handler, job, w.policy(job.Type), w.schedule, w.deadLetter)
 	} else {
 		zerolog.Ctx(ctx).Warn().Msgf("Memory worker handler not found for job type: %s", job.Type)
 	}
 }
 
+func (w *MemoryWorker) policy(jtype JobType) RetryPolicy {
+	if p, ok := w.policies[jtype]; ok {
+		return p
+	}
+	return NoRetry
+}
+
 func (w *MemoryWorker) Stats(_ context.Context) (Stats, error) {
 	return Stats{}, nil
 }
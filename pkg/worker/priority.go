@@ -0,0 +1,24 @@
+package worker
+
+// Priority is a tier of the job queue. Each tier is processed by its own pool of workers so
+// bulk background jobs cannot starve jobs triggered by users.
+type Priority int
+
+const (
+	// PriorityInteractive is the default tier for user-triggered jobs like launches.
+	PriorityInteractive Priority = iota
+
+	// PriorityBackground is the tier for scheduled and bulk jobs.
+	PriorityBackground
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBackground:
+		return "background"
+	default:
+		return "unknown"
+	}
+}
@@ -1,13 +1,12 @@
 package worker
 
 import (
-	"bytes"
 	"context"
 	"encoding/gob"
 	"errors"
 	"fmt"
 	"runtime/debug"
-	"strings"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,40 +25,85 @@ type RedisWorker struct {
 	// handler functions
 	handlers map[JobType]JobHandler
 
-	// queue for all jobs
-	queueName string
+	// retry policies
+	policies map[JobType]RetryPolicy
+
+	// called for jobs which failed permanently
+	deadLetter DeadLetterHandler
+
+	// called after every attempt
+	recorder ExecutionRecorder
+
+	// running jobs tracker and its heartbeat interval
+	heartbeater       Heartbeater
+	heartbeatInterval time.Duration
+
+	// job arguments versions, version zero when not registered
+	schemas map[JobType]ArgsSchema
+
+	// concurrency limit of job types per tenant, no limit when not registered
+	tenantLimit tenantLimit
+
+	// job type priorities, PriorityInteractive when not registered
+	priorities map[JobType]Priority
+
+	// queues indexed by priority
+	tiers []*redisTier
 
 	// close channel
 	closeCh chan interface{}
 
 	// polling and wait groups
 	pollInterval time.Duration
-	concurrency  int
 	loopWG       sync.WaitGroup
 
 	// number of in-flight jobs (must be use via atomic functions)
 	inFlight int64
 }
 
+// redisTier is a queue of a single priority processed by its own polling goroutines.
+type redisTier struct {
+	priority Priority
+
+	// queue for all jobs of the priority
+	queueName string
+
+	// sorted set of jobs scheduled for later (retries), scored by unix time in milliseconds
+	delayedName string
+
+	// number of polling goroutines
+	concurrency int
+}
+
 var _ JobWorker = &RedisWorker{}
 
-// NewRedisWorker creates new worker that keeps all jobs in a single queue (list), starts N polling
-// goroutines which fetch jobs from the queue and process them in the same goroutine. Use the
-// Stats function to track number of in-flight jobs.
-func NewRedisWorker(address, username, password string, db int, queueName string, pollInterval time.Duration, concurrency int) (*RedisWorker, error) {
+// NewRedisWorker creates new worker that keeps jobs in one queue (list) per priority, starts N
+// polling goroutines for interactive and M for background jobs which fetch jobs from the queue
+// and process them in the same goroutine. Use the Stats function to track number of in-flight jobs.
+func NewRedisWorker(address, username, password string, db int, queueName string, pollInterval time.Duration, concurrency, backgroundConcurrency int) (*RedisWorker, error) {
+	if concurrency < 1 || backgroundConcurrency < 1 {
+		return nil, fmt.Errorf("%w: concurrency %d, background concurrency %d", ErrInvalidConcurrency, concurrency, backgroundConcurrency)
+	}
+
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     address,
 		Username: username,
 		Password: password,
 		DB:       db,
-		PoolSize: concurrency + 2, // number of polling goroutines + room for Stats call
+		PoolSize: concurrency + backgroundConcurrency + 2, // number of polling goroutines + room for Stats call
 	})
 	return &RedisWorker{
-		handlers:     make(map[JobType]JobHandler),
-		client:       rdb,
-		queueName:    queueName,
+		handlers:   make(map[JobType]JobHandler),
+		policies:   make(map[JobType]RetryPolicy),
+		priorities: make(map[JobType]Priority),
+		schemas:    make(map[JobType]ArgsSchema),
+		client:     rdb,
+		tiers: []*redisTier{
+			// the interactive queue keeps the original names so no jobs are lost during upgrade
+			{priority: PriorityInteractive, queueName: queueName, delayedName: queueName + "-delayed", concurrency: concurrency},
+			{priority: PriorityBackground, queueName: queueName + "-background", delayedName: queueName + "-background-delayed", concurrency: backgroundConcurrency},
+		},
 		pollInterval: pollInterval,
-		concurrency:  concurrency,
 		closeCh:      make(chan interface{}),
 	}, nil
 }
@@ -69,6 +113,45 @@ func (w *RedisWorker) RegisterHandler(jtype JobType, handler JobHandler, args an
 	gob.Register(args)
 }
 
+func (w *RedisWorker) RegisterRetryPolicy(jtype JobType, policy RetryPolicy) {
+	w.policies[jtype] = policy
+}
+
+func (w *RedisWorker) RegisterDeadLetterHandler(handler DeadLetterHandler) {
+	w.deadLetter = handler
+}
+
+func (w *RedisWorker) RegisterPriority(jtype JobType, priority Priority) {
+	w.priorities[jtype] = priority
+}
+
+func (w *RedisWorker) RegisterExecutionRecorder(recorder ExecutionRecorder) {
+	w.recorder = recorder
+}
+
+func (w *RedisWorker) RegisterArgsSchema(jtype JobType, schema ArgsSchema) {
+	w.schemas[jtype] = schema
+}
+
+func (w *RedisWorker) RegisterHeartbeater(hb Heartbeater, interval time.Duration) {
+	w.heartbeater = hb
+	w.heartbeatInterval = interval
+}
+
+func (w *RedisWorker) tier(jtype JobType) *redisTier {
+	if p, ok := w.priorities[jtype]; ok && int(p) < len(w.tiers) {
+		return w.tiers[p]
+	}
+	return w.tiers[PriorityInteractive]
+}
+
+func (w *RedisWorker) policy(jtype JobType) RetryPolicy {
+	if p, ok := w.policies[jtype]; ok {
+		return p
+	}
+	return NoRetry
+}
+
 func loggerWithJob(ctx context.Context, job *Job) *zerolog.Logger {
 	logger := zerolog.Ctx(ctx).With().
 		Str("job_id", job.ID.String()).
@@ -86,17 +169,21 @@ func (w *RedisWorker) Enqueue(ctx context.Context, job *Job) error {
 		}
 	}
 
+	if job.ArgsVersion == 0 {
+		job.ArgsVersion = w.schemas[job.Type].Version
+	}
+	injectTraceContext(ctx, job)
+	injectMachine(ctx, job)
+
 	logger := loggerWithJob(ctx, job)
 	logger.Info().Msgf("Enqueuing job type %s via Redis", job.Type)
 
-	var buffer bytes.Buffer
-	enc := gob.NewEncoder(&buffer)
-	err = enc.Encode(&job)
+	buffer, err := EncodeJob(job)
 	if err != nil {
-		return fmt.Errorf("unable to encode args: %w", err)
+		return err
 	}
 
-	cmd := w.client.LPush(ctx, w.queueName, buffer.Bytes())
+	cmd := w.client.LPush(ctx, w.tier(job.Type).queueName, buffer)
 	if cmd.Err() != nil {
 		logger.Error().Err(err).Msg("Unable to push job into Redis")
 		return fmt.Errorf("unable to push job into Redis: %w", cmd.Err())
@@ -110,6 +197,44 @@ func (w *RedisWorker) Enqueue(ctx context.Context, job *Job) error {
 	return nil
 }
 
+// schedule stores the job in the delayed set, it is moved into the queue by pollers once due.
+func (w *RedisWorker) schedule(ctx context.Context, job *Job, delay time.Duration) error {
+	buffer, err := EncodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	due := time.Now().Add(delay).UnixMilli()
+	err = w.client.ZAdd(ctx, w.tier(job.Type).delayedName, redis.Z{Score: float64(due), Member: buffer}).Err()
+	if err != nil {
+		return fmt.Errorf("unable to schedule job in Redis: %w", err)
+	}
+	loggerWithJob(ctx, job).Info().Msgf("Scheduled job attempt %d in %s", job.Attempt, delay)
+	return nil
+}
+
+// promoteDelayed moves due jobs from the delayed set into the queue. Only the poller which
+// removes the member from the set pushes it, so each job is enqueued exactly once.
+func (w *RedisWorker) promoteDelayed(ctx context.Context, tier *redisTier) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	due, err := w.client.ZRangeByScore(ctx, tier.delayedName, &redis.ZRangeBy{Min: "-inf", Max: now, Count: 100}).Result()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to read delayed jobs from Redis")
+		return
+	}
+
+	for _, member := range due {
+		removed, err := w.client.ZRem(ctx, tier.delayedName, member).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+		err = w.client.LPush(ctx, tier.queueName, member).Err()
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to push delayed job into Redis, job is lost")
+		}
+	}
+}
+
 func (w *RedisWorker) Stop(ctx context.Context) {
 	logger := zerolog.Ctx(ctx)
 	close(w.closeCh)
@@ -120,22 +245,25 @@ func (w *RedisWorker) Stop(ctx context.Context) {
 
 func (w *RedisWorker) DequeueLoop(ctx context.Context) {
 	logger := zerolog.Ctx(ctx)
-	logger.Info().Msgf("Starting Redis dequeuer with %d polling goroutines", w.concurrency)
-	for i := 1; i <= w.concurrency; i++ {
-		w.loopWG.Add(1)
-		go w.dequeueLoop(ctx, i, w.concurrency)
+	for _, tier := range w.tiers {
+		logger.Info().Msgf("Starting Redis %s dequeuer with %d polling goroutines", tier.priority, tier.concurrency)
+		for i := 1; i <= tier.concurrency; i++ {
+			w.loopWG.Add(1)
+			go w.dequeueLoop(ctx, tier, i)
+		}
 	}
 }
 
-func (w *RedisWorker) dequeueLoop(ctx context.Context, i, total int) {
+func (w *RedisWorker) dequeueLoop(ctx context.Context, tier *redisTier, i int) {
 	defer w.loopWG.Done()
-	logger := zerolog.Ctx(ctx)
+	logger := zerolog.Ctx(ctx).With().Str("priority", tier.priority.String()).Logger()
+	ctx = logger.WithContext(ctx)
 
 	// do not crash the program on fatal errors
 	debug.SetPanicOnFault(true)
 
 	// spread polling intervals
-	delayMs := (int(w.pollInterval.Milliseconds()) / total) * (i - 1)
+	delayMs := (int(w.pollInterval.Milliseconds()) / tier.concurrency) * (i - 1)
 	logger.Debug().Msgf("Worker start delay %dms", delayMs)
 	time.Sleep(time.Duration(delayMs) * time.Millisecond)
 
@@ -148,7 +276,7 @@ func (w *RedisWorker) dequeueLoop(ctx context.Context, i, total int) {
 			logger.Info().Msg("Shutting down a Redis poller (cancel)")
 			return
 		default:
-			w.fetchJob(ctx)
+			w.fetchJob(ctx, tier)
 		}
 	}
 }
@@ -165,10 +293,14 @@ func recoverAndLog(ctx context.Context) {
 	}
 }
 
-func (w *RedisWorker) fetchJob(ctx context.Context) {
+func (w *RedisWorker) fetchJob(ctx context.Context, tier *redisTier) {
 	defer recoverAndLog(ctx)
 
-	res, err := w.client.BLPop(ctx, w.pollInterval, w.queueName).Result()
+	w.promoteDelayed(ctx, tier)
+	if tier.priority == PriorityInteractive {
+		w.promoteWaitingTenants(ctx)
+	}
+	res, err := w.client.BLPop(ctx, w.pollInterval, tier.queueName).Result()
 
 	if errors.Is(err, redis.Nil) {
 		// timeout occurred
@@ -179,16 +311,21 @@ func (w *RedisWorker) fetchJob(ctx context.Context) {
 		return
 	}
 
-	var job Job
-	dec := gob.NewDecoder(strings.NewReader(res[1]))
-	err = dec.Decode(&job)
-	logger := loggerWithJob(ctx, &job)
+	job, err := DecodeJob([]byte(res[1]))
+	logger := loggerWithJob(ctx, job)
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to unmarshal job payload, skipping")
 	}
 
+	if tenant, ok := w.tenantLimited(job); ok {
+		if !w.acquireTenantSlot(ctx, tenant, job, []byte(res[1])) {
+			return
+		}
+		defer w.releaseTenantSlot(ctx, tenant, job)
+	}
+
 	atomic.AddInt64(&w.inFlight, 1)
-	w.processJob(ctx, &job)
+	w.processJob(ctx, job)
 }
 
 func (w *RedisWorker) processJob(ctx context.Context, job *Job) {
@@ -200,15 +337,18 @@ func (w *RedisWorker) processJob(ctx context.Context, job *Job) {
 	logger.Info().Msg("Dequeued job from Redis")
 	ctx = contextLogger(ctx, job)
 	if h, ok := w.handlers[job.Type]; ok {
-		cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
+		cCtx, cFunc := context.WithTimeout(ctx, config.WorkerTimeout(job.Type.String()))
 		defer func() {
 			if c := cCtx.Err(); c != nil {
 				zerolog.Ctx(ctx).Error().Err(c).Msg("Job was either cancelled or timeout occured")
 			}
 			cFunc()
 		}()
+		stopHeartbeat := startHeartbeat(ctx, w.heartbeater, w.heartbeatInterval, job)
+		defer stopHeartbeat()
 		metrics.ObserveBackgroundJobDuration(job.Type.String(), func() {
-			h(cCtx, job)
+			handler := withExecutionRecorder(ctx, withArgsUpgrade(h, w.schemas[job.Type]), w.recorder)
+			handleJob(ctx, cCtx, handler, job, w.policy(job.Type), w.schedule, w.deadLetter)
 		})
 	} else {
 		// handler not found
@@ -217,9 +357,13 @@ func (w *RedisWorker) processJob(ctx context.Context, job *Job) {
 }
 
 func (w *RedisWorker) Stats(ctx context.Context) (Stats, error) {
-	count, err := w.client.LLen(ctx, w.queueName).Result()
-	if err != nil {
-		return Stats{}, fmt.Errorf("unable to get queue len: %w", err)
+	var count int64
+	for _, tier := range w.tiers {
+		length, err := w.client.LLen(ctx, tier.queueName).Result()
+		if err != nil {
+			return Stats{}, fmt.Errorf("unable to get queue len: %w", err)
+		}
+		count += length
 	}
 
 	return Stats{
@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisWorkerTiers(t *testing.T) {
+	w, err := NewRedisWorker("localhost:6379", "", "", 0, "queue", time.Second, 3, 1)
+	require.NoError(t, err)
+
+	w.RegisterPriority("refresh", PriorityBackground)
+
+	assert.Equal(t, "queue", w.tier("launch").queueName)
+	assert.Equal(t, "queue-delayed", w.tier("launch").delayedName)
+	assert.Equal(t, "queue-background", w.tier("refresh").queueName)
+	assert.Equal(t, "queue-background-delayed", w.tier("refresh").delayedName)
+	assert.Equal(t, 3, w.tier("launch").concurrency)
+	assert.Equal(t, 1, w.tier("refresh").concurrency)
+}
+
+func TestRedisWorkerInvalidConcurrency(t *testing.T) {
+	_, err := NewRedisWorker("localhost:6379", "", "", 0, "queue", time.Second, 3, 0)
+	require.ErrorIs(t, err, ErrInvalidConcurrency)
+}
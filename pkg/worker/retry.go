@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+// RetryPolicy declares how many times a failed job of a particular type is attempted and how
+// long to wait between attempts. The delay doubles with every attempt up to MaxBackoff.
+type RetryPolicy struct {
+	// Maximum number of attempts including the first one, values lower than 2 disable retries.
+	MaxAttempts int
+
+	// Delay before the second attempt.
+	Backoff time.Duration
+
+	// Maximum delay between attempts, zero means no limit.
+	MaxBackoff time.Duration
+}
+
+// NoRetry is the default policy for job types without an explicit policy.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// Delay returns the backoff before the next attempt after the given (failed) attempt.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// scheduleFunc enqueues a job which will be processed after the delay.
+type scheduleFunc func(ctx context.Context, job *Job, delay time.Duration) error
+
+// handleJob calls the handler and schedules another attempt when it returns an error and the
+// retry policy allows it. Jobs failed on the last attempt are passed to the dead-letter handler
+// when set. The parent context is used for scheduling and dead-lettering, the handler context
+// can be already cancelled at that point.
+func handleJob(parent, ctx context.Context, handler JobHandler, job *Job, policy RetryPolicy, schedule scheduleFunc, deadLetter DeadLetterHandler) {
+	if job.Attempt < 1 {
+		job.Attempt = 1
+	}
+	job.MaxAttempts = policy.maxAttempts()
+
+	err := handler(ctx, job)
+	if err == nil {
+		metrics.IncBackgroundJobAttempt(job.Type.String(), "success")
+		return
+	}
+
+	result := "failure"
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result = "timeout"
+		if !errors.Is(err, ErrJobTimeout) {
+			err = fmt.Errorf("%w: %s", ErrJobTimeout, err.Error())
+		}
+	}
+
+	logger := zerolog.Ctx(ctx)
+	if job.LastAttempt() {
+		metrics.IncBackgroundJobAttempt(job.Type.String(), result)
+		if job.MaxAttempts > 1 {
+			logger.Error().Err(err).Msgf("Job failed after %d attempts", job.Attempt)
+		}
+		if deadLetter != nil {
+			deadLetter(parent, job, err)
+		}
+		return
+	}
+
+	metrics.IncBackgroundJobAttempt(job.Type.String(), "retry")
+	next := *job
+	next.Attempt++
+	delay := policy.Delay(job.Attempt)
+	logger.Warn().Err(err).Msgf("Job attempt %d of %d failed, retrying in %s", job.Attempt, job.MaxAttempts, delay)
+	err = schedule(parent, &next, delay)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to schedule job retry")
+	}
+}
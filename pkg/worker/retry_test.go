@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errJob = errors.New("job error")
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, Backoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, 2*time.Second, policy.Delay(2))
+	assert.Equal(t, 4*time.Second, policy.Delay(3))
+	assert.Equal(t, 5*time.Second, policy.Delay(4))
+	assert.Equal(t, 5*time.Second, policy.Delay(40))
+}
+
+func TestHandleJobRetries(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Second}
+
+	var scheduled []*Job
+	var delays []time.Duration
+	schedule := func(_ context.Context, job *Job, delay time.Duration) error {
+		scheduled = append(scheduled, job)
+		delays = append(delays, delay)
+		return nil
+	}
+
+	var lastAttempts []bool
+	failing := func(_ context.Context, job *Job) error {
+		lastAttempts = append(lastAttempts, job.LastAttempt())
+		return errJob
+	}
+
+	job := &Job{Type: "test"}
+	handleJob(ctx, ctx, failing, job, policy, schedule, nil)
+	require.Len(t, scheduled, 1)
+	assert.Equal(t, 2, scheduled[0].Attempt)
+
+	handleJob(ctx, ctx, failing, scheduled[0], policy, schedule, nil)
+	require.Len(t, scheduled, 2)
+	assert.Equal(t, 3, scheduled[1].Attempt)
+
+	handleJob(ctx, ctx, failing, scheduled[1], policy, schedule, nil)
+	assert.Len(t, scheduled, 2, "last attempt must not be scheduled")
+
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, delays)
+	assert.Equal(t, []bool{false, false, true}, lastAttempts)
+}
+
+func TestHandleJobNoRetry(t *testing.T) {
+	ctx := context.Background()
+	schedule := func(_ context.Context, _ *Job, _ time.Duration) error {
+		t.Fatal("job must not be scheduled")
+		return nil
+	}
+
+	var lastAttempt bool
+	handler := func(_ context.Context, job *Job) error {
+		lastAttempt = job.LastAttempt()
+		return errJob
+	}
+
+	handleJob(ctx, ctx, handler, &Job{Type: "test"}, NoRetry, schedule, nil)
+	assert.True(t, lastAttempt)
+}
+
+func TestHandleJobDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 2, Backoff: time.Second}
+
+	var scheduled []*Job
+	schedule := func(_ context.Context, job *Job, _ time.Duration) error {
+		scheduled = append(scheduled, job)
+		return nil
+	}
+
+	var deadJobs []*Job
+	var deadErrs []error
+	deadLetter := func(_ context.Context, job *Job, jobErr error) {
+		deadJobs = append(deadJobs, job)
+		deadErrs = append(deadErrs, jobErr)
+	}
+
+	handler := func(_ context.Context, _ *Job) error {
+		return errJob
+	}
+
+	handleJob(ctx, ctx, handler, &Job{Type: "test"}, policy, schedule, deadLetter)
+	require.Len(t, scheduled, 1)
+	assert.Empty(t, deadJobs, "job with remaining attempts must not be dead-lettered")
+
+	handleJob(ctx, ctx, handler, scheduled[0], policy, schedule, deadLetter)
+	require.Len(t, deadJobs, 1)
+	assert.Equal(t, 2, deadJobs[0].Attempt)
+	assert.ErrorIs(t, deadErrs[0], errJob)
+}
+
+func TestEncodeDecodeJob(t *testing.T) {
+	job := &Job{Type: "test", AccountID: 13, Attempt: 2, MaxAttempts: 3}
+
+	payload, err := EncodeJob(job)
+	require.NoError(t, err)
+
+	decoded, err := DecodeJob(payload)
+	require.NoError(t, err)
+	assert.Equal(t, job, decoded)
+}
+
+func TestHandleJobTimeout(t *testing.T) {
+	parent := context.Background()
+	ctx, cancel := context.WithTimeout(parent, time.Millisecond)
+	defer cancel()
+
+	var deadErr error
+	deadLetter := func(_ context.Context, _ *Job, jobErr error) {
+		deadErr = jobErr
+	}
+
+	handler := func(ctx context.Context, _ *Job) error {
+		<-ctx.Done()
+		return errJob
+	}
+
+	handleJob(parent, ctx, handler, &Job{Type: "test"}, NoRetry, nil, deadLetter)
+	require.Error(t, deadErr)
+	assert.ErrorIs(t, deadErr, ErrJobTimeout)
+	assert.Contains(t, deadErr.Error(), errJob.Error())
+}
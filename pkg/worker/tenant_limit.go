@@ -0,0 +1,213 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// tenantLimit limits number of concurrently running jobs of a group of types per tenant
+// (organization) across all worker processes. Excess jobs wait in a per-tenant queue and are
+// pushed back into the main queue when a running job of the tenant finishes.
+type tenantLimit struct {
+	// limit can be changed at runtime, zero or negative value disables it
+	limit atomic.Int64
+	types map[JobType]struct{}
+}
+
+// acquireTenantScript removes expired slots of jobs of dead workers and either takes a slot for
+// the job or parks the job payload in the tenant waiting queue. Promoted jobs already hold their
+// slot. The set expires together with the slot which expires last.
+//
+// KEYS: running set, waiting list, waiting tenants set
+// ARGV: now (ms), slot expiry (ms), job id, limit, payload, tenant
+var acquireTenantScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if not redis.call('ZSCORE', KEYS[1], ARGV[3]) and redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[4]) then
+	redis.call('RPUSH', KEYS[2], ARGV[5])
+	redis.call('SADD', KEYS[3], ARGV[6])
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[3])
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', KEYS[1], last[2])
+return 1
+`)
+
+// promoteTenantScript pops the first waiting job of the tenant when there is a free slot and
+// takes the slot for it, so the job cannot be overtaken and parked again at the end of the
+// waiting queue. Nothing is popped when the first waiting job is not the expected one.
+//
+// KEYS: running set, waiting list, waiting tenants set
+// ARGV: now (ms), limit, tenant, payload, job id, slot expiry (ms)
+var promoteTenantScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+if redis.call('LINDEX', KEYS[2], 0) ~= ARGV[4] then
+	return 0
+end
+redis.call('LPOP', KEYS[2])
+if redis.call('LLEN', KEYS[2]) == 0 then
+	redis.call('SREM', KEYS[3], ARGV[3])
+end
+redis.call('ZADD', KEYS[1], ARGV[6], ARGV[5])
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', KEYS[1], last[2])
+return 1
+`)
+
+// jobTenant returns organization ID of the job, account ID when organization is not known or
+// empty string for jobs without tenant which are never limited.
+func jobTenant(job *Job) string {
+	if job.Identity.Identity.OrgID != "" {
+		return job.Identity.Identity.OrgID
+	}
+	if job.AccountID != 0 {
+		return "account-" + strconv.FormatInt(job.AccountID, 10)
+	}
+	return ""
+}
+
+func (w *RedisWorker) RegisterTenantLimit(limit int, jtypes ...JobType) {
+	w.tenantLimit.types = make(map[JobType]struct{}, len(jtypes))
+	for _, jtype := range jtypes {
+		w.tenantLimit.types[jtype] = struct{}{}
+	}
+	w.SetTenantLimit(limit)
+}
+
+// SetTenantLimit changes the limit of registered job types at runtime. Waiting jobs are promoted
+// when the limit is raised or disabled.
+func (w *RedisWorker) SetTenantLimit(limit int) {
+	w.tenantLimit.limit.Store(int64(limit))
+}
+
+// slotLimit returns the current limit, jobs still waiting after the limit was disabled are
+// promoted without limit.
+func (w *RedisWorker) slotLimit() int64 {
+	if limit := w.tenantLimit.limit.Load(); limit > 0 {
+		return limit
+	}
+	return math.MaxInt32
+}
+
+func (w *RedisWorker) tenantLimited(job *Job) (string, bool) {
+	if w.tenantLimit.limit.Load() < 1 {
+		return "", false
+	}
+	if _, ok := w.tenantLimit.types[job.Type]; !ok {
+		return "", false
+	}
+	tenant := jobTenant(job)
+	return tenant, tenant != ""
+}
+
+func (w *RedisWorker) tenantRunningKey(tenant string) string {
+	return w.tiers[PriorityInteractive].queueName + "-tenant-running:" + tenant
+}
+
+func (w *RedisWorker) tenantWaitingKey(tenant string) string {
+	return w.tiers[PriorityInteractive].queueName + "-tenant-waiting:" + tenant
+}
+
+func (w *RedisWorker) tenantsWaitingKey() string {
+	return w.tiers[PriorityInteractive].queueName + "-tenants-waiting"
+}
+
+// slotExpiry returns time when the slot of a job of a dead worker is freed.
+func slotExpiry(now time.Time, job *Job) time.Time {
+	return now.Add(config.WorkerTimeout(job.Type.String()) + time.Minute)
+}
+
+// acquireTenantSlot returns true when the job can run, otherwise the job was parked in the
+// tenant waiting queue. Slots of jobs of dead workers expire after the job timeout. Redis
+// errors are logged and the job runs.
+func (w *RedisWorker) acquireTenantSlot(ctx context.Context, tenant string, job *Job, payload []byte) bool {
+	now := time.Now()
+	keys := []string{w.tenantRunningKey(tenant), w.tenantWaitingKey(tenant), w.tenantsWaitingKey()}
+
+	limit := w.slotLimit()
+	acquired, err := acquireTenantScript.Run(ctx, w.client, keys,
+		now.UnixMilli(), slotExpiry(now, job).UnixMilli(), job.ID.String(), limit, payload, tenant).Int()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to acquire tenant job slot, running job anyway")
+		return true
+	}
+	if acquired == 0 {
+		loggerWithJob(ctx, job).Info().Str("tenant", tenant).Msgf("Tenant reached %d concurrent jobs, job is waiting", limit)
+		return false
+	}
+	return true
+}
+
+// releaseTenantSlot frees the slot of a finished job and enqueues a waiting job of the tenant.
+func (w *RedisWorker) releaseTenantSlot(ctx context.Context, tenant string, job *Job) {
+	err := w.client.ZRem(ctx, w.tenantRunningKey(tenant), job.ID.String()).Err()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to release tenant job slot")
+	}
+	w.promoteTenant(ctx, tenant)
+}
+
+// promoteTenant pushes the first waiting job of the tenant into the queue when the tenant has
+// a free slot. The slot is taken on behalf of the promoted job.
+func (w *RedisWorker) promoteTenant(ctx context.Context, tenant string) {
+	keys := []string{w.tenantRunningKey(tenant), w.tenantWaitingKey(tenant), w.tenantsWaitingKey()}
+	payload, err := w.client.LIndex(ctx, w.tenantWaitingKey(tenant), 0).Result()
+	if errors.Is(err, redis.Nil) {
+		w.client.SRem(ctx, w.tenantsWaitingKey(), tenant)
+		return
+	} else if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to read waiting tenant job")
+		return
+	}
+
+	job, err := DecodeJob([]byte(payload))
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to decode waiting tenant job, job is lost")
+		w.client.LRem(ctx, w.tenantWaitingKey(tenant), 1, payload)
+		return
+	}
+
+	now := time.Now()
+	promoted, err := promoteTenantScript.Run(ctx, w.client, keys,
+		now.UnixMilli(), w.slotLimit(), tenant, payload, job.ID.String(), slotExpiry(now, job).UnixMilli()).Int()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to promote waiting tenant job")
+		return
+	}
+	if promoted == 0 {
+		return
+	}
+
+	err = w.client.LPush(ctx, w.tier(job.Type).queueName, payload).Err()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to push waiting tenant job into Redis, job is lost")
+	}
+}
+
+// promoteWaitingTenants enqueues waiting jobs of tenants with free slots, for example when
+// slots of jobs of dead workers expired or the limit was changed.
+func (w *RedisWorker) promoteWaitingTenants(ctx context.Context) {
+	if len(w.tenantLimit.types) == 0 {
+		return
+	}
+
+	tenants, err := w.client.SMembers(ctx, w.tenantsWaitingKey()).Result()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to read waiting tenants from Redis")
+		return
+	}
+	for _, tenant := range tenants {
+		w.promoteTenant(ctx, tenant)
+	}
+}
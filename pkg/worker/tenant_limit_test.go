@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	rhidentity "github.com/redhatinsights/platform-go-middlewares/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobTenant(t *testing.T) {
+	job := &Job{AccountID: 13}
+	assert.Equal(t, "account-13", jobTenant(job))
+
+	job.Identity = identity.Principal{Identity: rhidentity.Identity{OrgID: "org1"}}
+	assert.Equal(t, "org1", jobTenant(job))
+
+	assert.Equal(t, "", jobTenant(&Job{}))
+}
+
+func TestRedisWorkerTenantLimited(t *testing.T) {
+	w, err := NewRedisWorker("localhost:6379", "", "", 0, "queue", time.Second, 3, 1)
+	require.NoError(t, err)
+
+	_, ok := w.tenantLimited(&Job{Type: "launch", AccountID: 1})
+	assert.False(t, ok, "no limit registered")
+
+	w.RegisterTenantLimit(0, "launch")
+	_, ok = w.tenantLimited(&Job{Type: "launch", AccountID: 1})
+	assert.False(t, ok, "zero limit disables")
+
+	w.RegisterTenantLimit(2, "launch")
+	tenant, ok := w.tenantLimited(&Job{Type: "launch", AccountID: 1})
+	assert.True(t, ok)
+	assert.Equal(t, "account-1", tenant)
+	assert.Equal(t, "queue-tenant-running:account-1", w.tenantRunningKey(tenant))
+
+	_, ok = w.tenantLimited(&Job{Type: "refresh", AccountID: 1})
+	assert.False(t, ok, "other job types are not limited")
+
+	_, ok = w.tenantLimited(&Job{Type: "launch"})
+	assert.False(t, ok, "jobs without tenant are not limited")
+
+	w.SetTenantLimit(0)
+	_, ok = w.tenantLimited(&Job{Type: "launch", AccountID: 1})
+	assert.False(t, ok, "limit disabled at runtime")
+	assert.EqualValues(t, math.MaxInt32, w.slotLimit(), "waiting jobs are promoted without limit")
+
+	w.SetTenantLimit(5)
+	_, ok = w.tenantLimited(&Job{Type: "launch", AccountID: 1})
+	assert.True(t, ok, "limit enabled at runtime")
+	assert.EqualValues(t, 5, w.slotLimit())
+}
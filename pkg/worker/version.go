@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+// ErrArgsVersion is returned for jobs with arguments of a version newer than the worker knows,
+// e.g. during a rolling deployment. Such jobs are retried according to the retry policy.
+var ErrArgsVersion = errors.New("unsupported job arguments version")
+
+// ArgsUpgrader converts arguments of the given version into arguments of the next version.
+type ArgsUpgrader func(args any, fromVersion int) (any, error)
+
+// ArgsSchema declares the current version of arguments of a job type. Jobs are stamped with
+// the version when enqueued and arguments of older versions are converted one version at a
+// time before the handler is called. Jobs enqueued before versioning have version zero.
+//
+// Arguments are encoded via gob which tolerates added and removed fields. When a field changes
+// its type, the old struct must stay registered under the original name via gob.RegisterName
+// so old payloads can be decoded and converted by the upgrader.
+type ArgsSchema struct {
+	// Current version of arguments.
+	Version int
+
+	// Conversion from older versions, can be nil when no conversion is needed.
+	Upgrade ArgsUpgrader
+}
+
+// upgradeArgs converts job arguments to the current version of the schema.
+func upgradeArgs(ctx context.Context, job *Job, schema ArgsSchema) error {
+	if job.ArgsVersion == schema.Version {
+		return nil
+	}
+
+	if job.ArgsVersion > schema.Version {
+		metrics.IncBackgroundJobArgsVersionMismatch(job.Type.String(), "unsupported")
+		return fmt.Errorf("%w: job version %d, worker version %d", ErrArgsVersion, job.ArgsVersion, schema.Version)
+	}
+
+	zerolog.Ctx(ctx).Info().Msgf("Upgrading job arguments from version %d to %d", job.ArgsVersion, schema.Version)
+	if schema.Upgrade != nil {
+		for v := job.ArgsVersion; v < schema.Version; v++ {
+			args, err := schema.Upgrade(job.Args, v)
+			if err != nil {
+				metrics.IncBackgroundJobArgsVersionMismatch(job.Type.String(), "failure")
+				return fmt.Errorf("unable to upgrade job arguments from version %d: %w", v, err)
+			}
+			job.Args = args
+		}
+	}
+	job.ArgsVersion = schema.Version
+	metrics.IncBackgroundJobArgsVersionMismatch(job.Type.String(), "upgraded")
+	return nil
+}
+
+// withArgsUpgrade returns a handler which converts job arguments before calling the handler.
+func withArgsUpgrade(handler JobHandler, schema ArgsSchema) JobHandler {
+	return func(ctx context.Context, job *Job) error {
+		if err := upgradeArgs(ctx, job, schema); err != nil {
+			return err
+		}
+		return handler(ctx, job)
+	}
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upgradeTestArgs appends the version converted from to a string argument.
+func upgradeTestArgs(args any, fromVersion int) (any, error) {
+	return fmt.Sprintf("%s-v%d", args.(string), fromVersion), nil
+}
+
+func TestUpgradeArgs(t *testing.T) {
+	job := &Job{Type: "test", Args: "args", ArgsVersion: 0}
+	err := upgradeArgs(context.Background(), job, ArgsSchema{Version: 2, Upgrade: upgradeTestArgs})
+	require.NoError(t, err)
+	assert.Equal(t, "args-v0-v1", job.Args)
+	assert.Equal(t, 2, job.ArgsVersion)
+}
+
+func TestUpgradeArgsCurrent(t *testing.T) {
+	job := &Job{Type: "test", Args: "args", ArgsVersion: 2}
+	err := upgradeArgs(context.Background(), job, ArgsSchema{Version: 2, Upgrade: upgradeTestArgs})
+	require.NoError(t, err)
+	assert.Equal(t, "args", job.Args)
+}
+
+func TestUpgradeArgsWithoutUpgrader(t *testing.T) {
+	job := &Job{Type: "test", Args: "args"}
+	err := upgradeArgs(context.Background(), job, ArgsSchema{Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "args", job.Args)
+	assert.Equal(t, 1, job.ArgsVersion)
+}
+
+func TestUpgradeArgsNewer(t *testing.T) {
+	job := &Job{Type: "test", Args: "args", ArgsVersion: 3}
+	err := upgradeArgs(context.Background(), job, ArgsSchema{Version: 2, Upgrade: upgradeTestArgs})
+	require.ErrorIs(t, err, ErrArgsVersion)
+}
+
+func TestWithArgsUpgrade(t *testing.T) {
+	var handled any
+	handler := withArgsUpgrade(func(_ context.Context, job *Job) error {
+		handled = job.Args
+		return nil
+	}, ArgsSchema{Version: 1, Upgrade: upgradeTestArgs})
+
+	require.NoError(t, handler(context.Background(), &Job{Type: "test", Args: "args"}))
+	assert.Equal(t, "args-v0", handled)
+}
+
+func TestMemoryWorkerEnqueueVersion(t *testing.T) {
+	w := NewMemoryClient()
+	w.RegisterArgsSchema("test", ArgsSchema{Version: 3})
+
+	go func() {
+		assert.NoError(t, w.Enqueue(context.Background(), &Job{Type: "test"}))
+	}()
+	job := <-w.todo
+	assert.Equal(t, 3, job.ArgsVersion)
+}
@@ -11,6 +11,7 @@ echo "Creating topics..."
 $BASEDIR/kafka/bin/kafka-topics.sh --create --topic platform.provisioning.internal.availability-check --bootstrap-server $KAFKA_HOST &
 $BASEDIR/kafka/bin/kafka-topics.sh --create --topic platform.sources.status --bootstrap-server $KAFKA_HOST &
 $BASEDIR/kafka/bin/kafka-topics.sh --create --topic platform.notifications.ingress --bootstrap-server $KAFKA_HOST &
+$BASEDIR/kafka/bin/kafka-topics.sh --create --topic platform.provisioning.audit --bootstrap-server $KAFKA_HOST &
 
 echo "Starting Kafka..."
 $BASEDIR/kafka/bin/kafka-server-start.sh $BASEDIR/kafka/config/kraft/server.properties